@@ -0,0 +1,221 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Morning missed-dose callouts
+const (
+	MedCalloutDays   = 7 // look-back window ending yesterday
+	MedCalloutMisses = 2 // misses in the window that earn a callout
+)
+
+// MedResult is whether one med/protocol task was done on a day
+type MedResult struct {
+	Name  string `json:"name"`
+	Taken bool   `json:"taken"`
+}
+
+// medLogDay is one evening's stored med results
+type medLogDay struct {
+	Date    string
+	Results []MedResult
+}
+
+// MedStats is one medication's adherence over a period
+type MedStats struct {
+	Name    string  `json:"name"`
+	Taken   int     `json:"taken"`
+	Missed  int     `json:"missed"`
+	Percent float64 `json:"percent"`
+	Streak  int     `json:"streak"` // doses taken in a row up to the period's end
+}
+
+// MedMisses is a med missed repeatedly over the last MedCalloutDays
+type MedMisses struct {
+	Name   string `json:"name"`
+	Missed int    `json:"missed"`
+	Days   int    `json:"days"`
+}
+
+// createMedLogTable adds the per-day med results to the history store. Results
+// are one JSON list per date so they can be sealed like the briefing JSON.
+func createMedLogTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS med_log (
+			date TEXT PRIMARY KEY,
+			results TEXT NOT NULL
+		)
+	`)
+	return err
+}
+
+// medResults turns the evening's protocol lists into results; a name both
+// completed and missed (an overdue copy) counts as taken
+func medResults(p ProtocolsData) []MedResult {
+	taken := map[string]bool{}
+	for _, name := range p.Missed {
+		taken[name] = false
+	}
+	for _, name := range p.Completed {
+		taken[name] = true
+	}
+	results := make([]MedResult, 0, len(taken))
+	for name, t := range taken {
+		results = append(results, MedResult{Name: name, Taken: t})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+// saveMedLog stores a day's results, replacing any earlier run for the date
+func saveMedLog(db *sql.DB, date string, results []MedResult) error {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO med_log (date, results) VALUES (?, ?)`, date, string(sealAtRest(data)))
+	if err != nil {
+		return fmt.Errorf("med log save error: %w", err)
+	}
+	return nil
+}
+
+// recordMedLog stores the evening's protocol results in the history store;
+// days without any med tasks aren't recorded
+func recordMedLog(date string, p ProtocolsData) error {
+	results := medResults(p)
+	if len(results) == 0 {
+		return nil
+	}
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return saveMedLog(db, date, results)
+}
+
+// queryMedLog returns the stored days up to and including to, oldest first
+func queryMedLog(db *sql.DB, to string) ([]medLogDay, error) {
+	rows, err := db.Query(`SELECT date, results FROM med_log WHERE date <= ? ORDER BY date`, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []medLogDay
+	for rows.Next() {
+		var day medLogDay
+		var stored string
+		if err := rows.Scan(&day.Date, &stored); err != nil {
+			return nil, err
+		}
+		data, err := openAtRest([]byte(stored))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &day.Results); err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
+
+// medStatsFor totals each med's results from..to, sorted by name. Streaks
+// count back from to through all earlier days, skipping days the med wasn't due.
+func medStatsFor(days []medLogDay, from, to string) []MedStats {
+	byName := map[string]*MedStats{}
+	streakDone := map[string]bool{}
+	for i := len(days) - 1; i >= 0; i-- {
+		day := days[i]
+		if day.Date > to {
+			continue
+		}
+		for _, r := range day.Results {
+			s := byName[r.Name]
+			if s == nil {
+				if day.Date < from {
+					continue // not due during the period
+				}
+				s = &MedStats{Name: r.Name}
+				byName[r.Name] = s
+			}
+			if day.Date >= from {
+				if r.Taken {
+					s.Taken++
+				} else {
+					s.Missed++
+				}
+			}
+			if !streakDone[r.Name] {
+				if r.Taken {
+					s.Streak++
+				} else {
+					streakDone[r.Name] = true
+				}
+			}
+		}
+	}
+
+	stats := make([]MedStats, 0, len(byName))
+	for _, s := range byName {
+		s.Percent = roundTo(float64(s.Taken)/float64(s.Taken+s.Missed)*100, 1)
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// medMissesFor lists meds missed at least MedCalloutMisses times in the
+// MedCalloutDays ending on date
+func medMissesFor(days []medLogDay, date string) []MedMisses {
+	var misses []MedMisses
+	for _, s := range medStatsFor(days, addDays(date, -(MedCalloutDays-1)), date) {
+		if s.Missed >= MedCalloutMisses {
+			misses = append(misses, MedMisses{Name: s.Name, Missed: s.Missed, Days: MedCalloutDays})
+		}
+	}
+	return misses
+}
+
+// loadMedLog reads the stored med results up to to, returning none without
+// error when no history has been recorded yet
+func loadMedLog(to string) ([]medLogDay, error) {
+	if _, err := os.Stat(getHistoryDBPath()); err != nil {
+		return nil, nil
+	}
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return queryMedLog(db, to)
+}
+
+// getMedCallouts flags meds missed repeatedly in the week before today and
+// works out the configured stock left after the logged doses
+func getMedCallouts(b *MorningBriefing, today string) {
+	days, err := loadMedLog(yesterday(today))
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("med log error: %v", err))
+		return
+	}
+	b.Meds.MissedRecently = medMissesFor(days, yesterday(today))
+	b.Meds.Inventory = inventoryFor(settings.Inventory, days)
+}
+
+// medMissesLine is a callout, e.g. "You've missed PrEP 2 of the last 7 days"
+func medMissesLine(m MedMisses) string {
+	return fmt.Sprintf("You've missed %s %d of the last %d days", m.Name, m.Missed, m.Days)
+}
+
+// medStatsLine formats one med's week, e.g. "PrEP 71% (5/7), streak 3"
+func medStatsLine(s MedStats) string {
+	return fmt.Sprintf("%s %.0f%% (%d/%d), streak %d", s.Name, s.Percent, s.Taken, s.Taken+s.Missed, s.Streak)
+}
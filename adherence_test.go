@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== MED LOG TESTS ====================
+
+func TestMedResults(t *testing.T) {
+	got := medResults(ProtocolsData{Completed: []string{"Vitamin D", "PrEP"}, Missed: []string{"PrEP", "Nexium"}})
+	want := []MedResult{{"Nexium", false}, {"PrEP", true}, {"Vitamin D", true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("medResults() = %+v, want %+v", got, want)
+	}
+	if got := medResults(ProtocolsData{}); len(got) != 0 {
+		t.Errorf("medResults() without tasks = %+v, want none", got)
+	}
+}
+
+func TestMedLogRoundTrip(t *testing.T) {
+	enableTestEncryption(t)
+	db := newTestHistoryDB(t)
+	if err := saveMedLog(db, "2024-01-14", []MedResult{{"PrEP", false}}); err != nil {
+		t.Fatal(err)
+	}
+	// A later run for the same date replaces the earlier one
+	if err := saveMedLog(db, "2024-01-14", []MedResult{{"PrEP", true}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveMedLog(db, "2024-01-15", []MedResult{{"PrEP", true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored string
+	db.QueryRow(`SELECT results FROM med_log WHERE date = '2024-01-14'`).Scan(&stored)
+	if strings.Contains(stored, "PrEP") {
+		t.Errorf("med log stored in plaintext: %s", stored)
+	}
+
+	days, err := queryMedLog(db, "2024-01-14")
+	if err != nil {
+		t.Fatalf("queryMedLog() error: %v", err)
+	}
+	want := []medLogDay{{"2024-01-14", []MedResult{{"PrEP", true}}}}
+	if !reflect.DeepEqual(days, want) {
+		t.Errorf("queryMedLog() = %+v, want %+v", days, want)
+	}
+
+	if _, err := pruneHistory(db, "2024-01-15", false); err != nil {
+		t.Fatal(err)
+	}
+	if days, _ := queryMedLog(db, "2024-12-31"); len(days) != 1 || days[0].Date != "2024-01-15" {
+		t.Errorf("med log after prune = %+v, want only 2024-01-15", days)
+	}
+}
+
+func TestMedStatsFor(t *testing.T) {
+	days := []medLogDay{
+		{"2024-01-05", []MedResult{{"PrEP", true}, {"TB-500", true}}},
+		{"2024-01-08", []MedResult{{"PrEP", true}, {"TB-500", true}}},
+		{"2024-01-09", []MedResult{{"PrEP", false}}},
+		{"2024-01-10", []MedResult{{"PrEP", true}}},
+		{"2024-01-11", []MedResult{{"PrEP", true}, {"TB-500", true}}},
+		{"2024-01-12", []MedResult{{"PrEP", false}}},
+		{"2024-01-13", []MedResult{{"PrEP", true}}},
+		{"2024-01-14", []MedResult{{"PrEP", true}, {"Nexium", true}}},
+		{"2024-01-15", []MedResult{{"PrEP", false}}},
+	}
+
+	// TB-500 is only due some days, and its streak reaches back before the week;
+	// the 01-15 miss is after the period
+	got := medStatsFor(days, "2024-01-08", "2024-01-14")
+	want := []MedStats{
+		{Name: "Nexium", Taken: 1, Percent: 100, Streak: 1},
+		{Name: "PrEP", Taken: 5, Missed: 2, Percent: 71.4, Streak: 2},
+		{Name: "TB-500", Taken: 2, Percent: 100, Streak: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("medStatsFor() = %+v, want %+v", got, want)
+	}
+	if got := medStatsLine(want[1]); got != "PrEP 71% (5/7), streak 2" {
+		t.Errorf("medStatsLine() = %q", got)
+	}
+
+	misses := medMissesFor(days, "2024-01-15")
+	if want := []MedMisses{{"PrEP", 3, 7}}; !reflect.DeepEqual(misses, want) {
+		t.Errorf("medMissesFor() = %+v, want %+v", misses, want)
+	}
+	if misses := medMissesFor(days, "2024-01-11"); misses != nil {
+		t.Errorf("medMissesFor() with one miss = %+v, want none", misses)
+	}
+}
+
+func TestMedCallouts(t *testing.T) {
+	t.Setenv("BRIEFING_DATA_DIR", t.TempDir())
+
+	// No history yet is not an error
+	b := &MorningBriefing{}
+	getMedCallouts(b, "2024-01-15")
+	if b.Meds.MissedRecently != nil || b.Errors != nil {
+		t.Errorf("callouts without history = %+v, %v", b.Meds.MissedRecently, b.Errors)
+	}
+
+	for date, p := range map[string]ProtocolsData{
+		"2024-01-12": {Missed: []string{"PrEP"}},
+		"2024-01-13": {Completed: []string{"PrEP"}},
+		"2024-01-14": {Missed: []string{"PrEP"}},
+		"2024-01-15": {Missed: []string{"PrEP"}},
+	} {
+		if err := recordMedLog(date, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Today's evening hasn't happened yet, so 01-15 is left out
+	getMedCallouts(b, "2024-01-15")
+	line := "You've missed PrEP 2 of the last 7 days"
+	if len(b.Meds.MissedRecently) != 1 || medMissesLine(b.Meds.MissedRecently[0]) != line {
+		t.Fatalf("MissedRecently = %+v, want %q", b.Meds.MissedRecently, line)
+	}
+	if md := MorningMarkdown(*b); !strings.Contains(md, "- **"+line+"**\n") {
+		t.Errorf("markdown missing callout:\n%s", md)
+	}
+	if text := MorningText(*b, textStyle{}); !strings.Contains(text, "  none due\n  "+line+"\n") {
+		t.Errorf("text missing callout:\n%s", text)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// US AQI categories
+const (
+	AirGood          = "GOOD"           // 0-50
+	AirModerate      = "MODERATE"       // 51-100
+	AirSensitive     = "SENSITIVE"      // 101-150, unhealthy for sensitive groups
+	AirUnhealthy     = "UNHEALTHY"      // 151-200
+	AirVeryUnhealthy = "VERY UNHEALTHY" // 201-300
+	AirHazardous     = "HAZARDOUS"      // 301+
+)
+
+// iqairPollutants names IQAir's main pollutant codes
+var iqairPollutants = map[string]string{
+	"p2": "PM2.5", "p1": "PM10", "o3": "ozone", "n2": "NO2", "s2": "SO2", "co": "CO",
+}
+
+// AirQualityData is the current US AQI near weather.location
+type AirQualityData struct {
+	AQI            int    `json:"aqi"`
+	MainPollutant  string `json:"main_pollutant,omitempty"` // e.g. PM2.5
+	City           string `json:"city,omitempty"`           // the nearest monitored city
+	Classification string `json:"classification"`           // GOOD, MODERATE, SENSITIVE, UNHEALTHY, VERY UNHEALTHY, HAZARDOUS
+}
+
+// validateAirQuality checks the threshold when an API key is set
+func validateAirQuality(cfg AirQualityConfig) error {
+	if cfg.APIKey == "" {
+		return nil
+	}
+	if cfg.UnhealthyAQI <= 0 || cfg.UnhealthyAQI > 500 {
+		return errors.New("air_quality.unhealthy_aqi must be between 1 and 500")
+	}
+	return nil
+}
+
+// getAirQualityData fetches the AQI from IQAir when air_quality.api_key and
+// weather.location are set
+func getAirQualityData(b *MorningBriefing, today string) {
+	cfg := settings.AirQuality
+	if cfg.APIKey == "" || settings.Weather.Location == "" {
+		return
+	}
+	aq, err := fetchAirQuality(cfg, settings.Weather.Location)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("air quality error: %v", err))
+		return
+	}
+	b.AirQuality = aq
+	if b.Weather != nil && airUnhealthy(aq, cfg) {
+		b.Weather.Training = WeatherIndoor // whatever the forecast
+	}
+}
+
+// fetchAirQuality asks IQAir for the nearest city's current pollution
+func fetchAirQuality(cfg AirQualityConfig, location string) (*AirQualityData, error) {
+	client := &http.Client{Timeout: travelTimeout}
+	lat, lon, err := geocode(client, settings.Travel, location)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Status string `json:"status"`
+		Data   struct {
+			City    string `json:"city"`
+			Current struct {
+				Pollution struct {
+					AQIUS  *int   `json:"aqius"`
+					MainUS string `json:"mainus"`
+				} `json:"pollution"`
+			} `json:"current"`
+		} `json:"data"`
+	}
+	u := strings.TrimRight(cfg.URL, "/") + "/nearest_city?" + url.Values{
+		"lat": {fmt.Sprintf("%f", lat)}, "lon": {fmt.Sprintf("%f", lon)}, "key": {cfg.APIKey},
+	}.Encode()
+	if err := travelGet(client, u, &resp); err != nil {
+		return nil, fmt.Errorf("iqair: %w", err)
+	}
+	p := resp.Data.Current.Pollution
+	if resp.Status != "success" || p.AQIUS == nil {
+		return nil, fmt.Errorf("iqair: no reading (%s)", resp.Status)
+	}
+	main := iqairPollutants[p.MainUS]
+	if main == "" {
+		main = p.MainUS
+	}
+	return &AirQualityData{AQI: *p.AQIUS, MainPollutant: main, City: resp.Data.City, Classification: classifyAQI(*p.AQIUS)}, nil
+}
+
+// classifyAQI maps a US AQI to its category
+func classifyAQI(aqi int) string {
+	switch {
+	case aqi <= 50:
+		return AirGood
+	case aqi <= 100:
+		return AirModerate
+	case aqi <= 150:
+		return AirSensitive
+	case aqi <= 200:
+		return AirUnhealthy
+	case aqi <= 300:
+		return AirVeryUnhealthy
+	}
+	return AirHazardous
+}
+
+// airUnhealthy reports whether the AQI reaches air_quality.unhealthy_aqi
+func airUnhealthy(aq *AirQualityData, cfg AirQualityConfig) bool {
+	return aq != nil && aq.AQI >= cfg.UnhealthyAQI
+}
+
+// airQualityLine summarizes the reading, e.g. "AQI 172, mostly PM2.5 (Bangkok)"
+func airQualityLine(aq *AirQualityData) string {
+	line := fmt.Sprintf("AQI %d", aq.AQI)
+	if aq.MainPollutant != "" {
+		line += ", mostly " + aq.MainPollutant
+	}
+	if aq.City != "" {
+		line += " (" + aq.City + ")"
+	}
+	return line
+}
+
+// airQualityNote is appended to the recommendation when the air is unhealthy
+func airQualityNote(aq *AirQualityData, cfg AirQualityConfig) string {
+	if !airUnhealthy(aq, cfg) {
+		return ""
+	}
+	return fmt.Sprintf(" Air quality is %s (AQI %d): train indoors and wear an N95 mask outside.", strings.ToLower(aq.Classification), aq.AQI)
+}
+
+// indoorNote is the one reason to train indoors today: unhealthy air, else
+// high pollen, so both never say it twice
+func indoorNote(aq *AirQualityData, p *PollenData) string {
+	if note := airQualityNote(aq, settings.AirQuality); note != "" {
+		return note
+	}
+	return pollenNote(p)
+}
+
+// outdoorNote is where to train today, unhealthy air then high pollen
+// overriding the weather, and when to wear sunscreen
+func outdoorNote(w *WeatherData, aq *AirQualityData, p *PollenData) string {
+	if note := indoorNote(aq, p); note != "" {
+		return note + sunNote(w)
+	}
+	return weatherNote(w, settings.Weather) + sunNote(w)
+}
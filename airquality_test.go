@@ -0,0 +1,140 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== AIR QUALITY TESTS ====================
+
+// fakeIQAir serves the nearest city's pollution for key k
+func fakeIQAir(t *testing.T, aqi string) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/nearest_city" || q.Get("key") != "k" || q.Get("lat") != "13.750000" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status": "fail", "data": {"message": "incorrect_api_key"}}`))
+			return
+		}
+		w.Write([]byte(`{"status": "success", "data": {"city": "Bangkok", "current": {"pollution": {"aqius": ` + aqi + `, "mainus": "p2"}}}}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetAirQualityData(t *testing.T) {
+	ts := fakeIQAir(t, "172")
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Weather.Location = "13.75,100.5"
+	settings.AirQuality.APIKey = "k"
+	settings.AirQuality.URL = ts.URL
+
+	b := &MorningBriefing{Weather: &WeatherData{HighC: 30, Training: WeatherOutdoor}}
+	getAirQualityData(b, "2024-01-15")
+	want := AirQualityData{AQI: 172, MainPollutant: "PM2.5", City: "Bangkok", Classification: AirUnhealthy}
+	if b.AirQuality == nil || *b.AirQuality != want || len(b.Errors) != 0 {
+		t.Fatalf("air quality = %+v, errors %v, want %+v", b.AirQuality, b.Errors, want)
+	}
+	if b.Weather.Training != WeatherIndoor {
+		t.Errorf("weather training = %s, want indoor with unhealthy air", b.Weather.Training)
+	}
+
+	// A bad key is reported without the key
+	settings.AirQuality.APIKey = "secret-key"
+	b = &MorningBriefing{}
+	getAirQualityData(b, "2024-01-15")
+	if b.AirQuality != nil || len(b.Errors) != 1 || !strings.HasPrefix(b.Errors[0], "air quality error: iqair: status 401") || strings.Contains(b.Errors[0], "secret-key") {
+		t.Errorf("air quality = %+v, errors %v", b.AirQuality, b.Errors)
+	}
+
+	// Off without a location
+	settings.Weather.Location = ""
+	b = &MorningBriefing{}
+	getAirQualityData(b, "2024-01-15")
+	if b.AirQuality != nil || len(b.Errors) != 0 {
+		t.Errorf("air quality without a location = %+v, %v", b.AirQuality, b.Errors)
+	}
+}
+
+func TestClassifyAQI(t *testing.T) {
+	tests := map[int]string{12: AirGood, 50: AirGood, 51: AirModerate, 120: AirSensitive, 151: AirUnhealthy, 250: AirVeryUnhealthy, 420: AirHazardous}
+	for aqi, want := range tests {
+		if got := classifyAQI(aqi); got != want {
+			t.Errorf("classifyAQI(%d) = %s, want %s", aqi, got, want)
+		}
+	}
+}
+
+func TestOutdoorNote(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings = DefaultConfig() // unhealthy from AQI 151
+	fine := &WeatherData{Conditions: "clear", HighC: 28, RainChance: 0}
+	tests := []struct {
+		name     string
+		air      *AirQualityData
+		expected string
+	}{
+		{"no reading", nil, " Good weather for an outdoor run."},
+		{"moderate", &AirQualityData{AQI: 80, Classification: AirModerate}, " Good weather for an outdoor run."},
+		{"unhealthy", &AirQualityData{AQI: 172, Classification: AirUnhealthy}, " Air quality is unhealthy (AQI 172): train indoors and wear an N95 mask outside."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outdoorNote(fine, tt.air, nil); got != tt.expected {
+				t.Errorf("outdoorNote() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAirQualityPoorRecovery(t *testing.T) {
+	b := &MorningBriefing{
+		Vitals:     VitalsData{HRV: ptr(30.0), HRVBaseline: ptr(50.0)},
+		AirQuality: &AirQualityData{AQI: 230, Classification: AirVeryUnhealthy},
+	}
+	classify(b)
+	if b.Classification.RecoveryStatus != "POOR" || !strings.HasSuffix(b.Classification.Recommendation, " Air quality is very unhealthy (AQI 230): train indoors and wear an N95 mask outside.") {
+		t.Errorf("recovery %s, recommendation = %q", b.Classification.RecoveryStatus, b.Classification.Recommendation)
+	}
+}
+
+func TestValidateAirQuality(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         AirQualityConfig
+		expectError bool
+	}{
+		{"off", DefaultConfig().AirQuality, false},
+		{"default threshold", AirQualityConfig{APIKey: "k", UnhealthyAQI: 151}, false},
+		{"zero threshold", AirQualityConfig{APIKey: "k"}, true},
+		{"over 500", AirQualityConfig{APIKey: "k", UnhealthyAQI: 600}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAirQuality(tt.cfg); (err != nil) != tt.expectError {
+				t.Errorf("validateAirQuality() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestAirQualityRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-01-15", AirQuality: &AirQualityData{AQI: 172, MainPollutant: "PM2.5", City: "Bangkok", Classification: AirUnhealthy}}
+	line := "AQI 172, mostly PM2.5 (Bangkok)"
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Air Quality (UNHEALTHY)\n\n"+line+"\n") {
+		t.Errorf("markdown missing air quality:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Air  UNHEALTHY  "+line+"\n") {
+		t.Errorf("text missing air quality:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Air quality: "+line+" (UNHEALTHY)\n") {
+		t.Errorf("prompt missing air quality (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["air.aqi"] != 172.0 || vars["air_quality"] != AirUnhealthy {
+		t.Errorf("air vars = %v, %v", vars["air.aqi"], vars["air_quality"])
+	}
+}
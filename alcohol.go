@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Where yesterday's alcohol was recorded
+const (
+	AlcoholSourceHealth  = "health"  // number_of_alcoholic_beverages in the health DB
+	AlcoholSourceTodoist = "todoist" // a task carrying one of the alcohol labels
+)
+
+// AlcoholData notes drinking the day before the morning briefing
+type AlcoholData struct {
+	Drinks  float64  `json:"drinks,omitempty"` // 0 when only a Todoist marker was found
+	Sources []string `json:"sources"`
+}
+
+// noteAlcohol records drinks from source on the briefing
+func noteAlcohol(b *MorningBriefing, drinks float64, source string) {
+	if b.Alcohol == nil {
+		b.Alcohol = &AlcoholData{}
+	}
+	b.Alcohol.Drinks += drinks
+	if !slices.Contains(b.Alcohol.Sources, source) {
+		b.Alcohol.Sources = append(b.Alcohol.Sources, source)
+	}
+}
+
+// isAlcoholTask reports whether a Todoist task carries one of the configured alcohol labels
+func isAlcoholTask(labels []string) bool {
+	return slices.ContainsFunc(labels, func(l string) bool { return slices.Contains(settings.AlcoholLabels, l) })
+}
+
+// alcoholRecovery caps recovery at OK after drinking: HRV can look fine
+// while sleep quality and recovery are still impaired
+func alcoholRecovery(status string, a *AlcoholData) string {
+	if a != nil && status == "GOOD" {
+		return "OK"
+	}
+	return status
+}
+
+// alcoholNote is appended to the recommendation after a day with alcohol
+func alcoholNote(a *AlcoholData, recovery string) string {
+	switch {
+	case a == nil:
+		return ""
+	case recovery == "POOR":
+		return " Alcohol last night explains part of the HRV dip, keep training light and hydrate."
+	}
+	return " Alcohol last night, expect a lower HRV and keep training intensity down today."
+}
+
+// alcoholValue formats the note, e.g. "3 drinks yesterday" or "yesterday (todoist)"
+func alcoholValue(a *AlcoholData) string {
+	if a.Drinks > 0 {
+		return fmt.Sprintf("%.0f drinks yesterday", a.Drinks)
+	}
+	return fmt.Sprintf("yesterday (%s)", a.Sources[0])
+}
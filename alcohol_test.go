@@ -0,0 +1,82 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ==================== ALCOHOL TESTS ====================
+
+func TestAlcoholFromDB(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('number_of_alcoholic_beverages', '2024-01-14 19:00:00 +0700', 2, 'count', 'Health'),
+		('number_of_alcoholic_beverages', '2024-01-14 21:30:00 +0700', 1, 'count', 'Health'),
+		('number_of_alcoholic_beverages', '2024-01-15 19:00:00 +0700', 4, 'count', 'Health')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only yesterday counts; HRV that would be GOOD is capped at OK
+	b := &MorningBriefing{TargetDate: "2024-01-15", Vitals: VitalsData{HRV: ptr(55)}}
+	fillMorningHealthFromDB(b, db, "2024-01-15", nil)
+	if b.Alcohol == nil || b.Alcohol.Drinks != 3 || !slices.Equal(b.Alcohol.Sources, []string{AlcoholSourceHealth}) {
+		t.Fatalf("Alcohol = %+v, want 3 drinks from health", b.Alcohol)
+	}
+	b.Sleep = SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true}
+	classify(b)
+	if b.Classification.RecoveryStatus != "OK" {
+		t.Errorf("RecoveryStatus = %s, want OK after alcohol", b.Classification.RecoveryStatus)
+	}
+	want := "Well rested. Attack the day. Alcohol last night, expect a lower HRV and keep training intensity down today."
+	if b.Classification.Recommendation != want {
+		t.Errorf("Recommendation = %q, want %q", b.Classification.Recommendation, want)
+	}
+
+	if md := MorningMarkdown(*b); !strings.Contains(md, "| Alcohol | 3 drinks yesterday |") {
+		t.Errorf("markdown missing alcohol row:\n%s", md)
+	}
+	if vars := morningRuleVars(*b); vars["alcohol"] != true || vars["alcohol.drinks"] != 3.0 {
+		t.Errorf("rule vars = %v, %v; want true, 3", vars["alcohol"], vars["alcohol.drinks"])
+	}
+}
+
+func TestAlcoholPoorRecovery(t *testing.T) {
+	b := &MorningBriefing{Vitals: VitalsData{HRV: ptr(18)}}
+	noteAlcohol(b, 0, AlcoholSourceTodoist)
+	classify(b)
+	if b.Classification.RecoveryStatus != "POOR" {
+		t.Errorf("RecoveryStatus = %s, want POOR unchanged", b.Classification.RecoveryStatus)
+	}
+	if !strings.Contains(b.Classification.Recommendation, "Alcohol last night explains part of the HRV dip") {
+		t.Errorf("Recommendation = %q, want the alcohol explanation", b.Classification.Recommendation)
+	}
+	if text := MorningText(*b, textStyle{}); !strings.Contains(text, "Alcohol  yesterday (todoist)\n") {
+		t.Errorf("text missing alcohol line:\n%s", text)
+	}
+}
+
+func TestNoteAlcohol(t *testing.T) {
+	b := &MorningBriefing{}
+	noteAlcohol(b, 2, AlcoholSourceHealth)
+	noteAlcohol(b, 0, AlcoholSourceTodoist)
+	noteAlcohol(b, 1, AlcoholSourceHealth)
+	if b.Alcohol.Drinks != 3 || !slices.Equal(b.Alcohol.Sources, []string{AlcoholSourceHealth, AlcoholSourceTodoist}) {
+		t.Errorf("Alcohol = %+v, want 3 drinks from health and todoist", b.Alcohol)
+	}
+	if got := alcoholRecovery("GOOD", nil); got != "GOOD" {
+		t.Errorf("alcoholRecovery() without alcohol = %s, want GOOD", got)
+	}
+}
+
+func TestIsAlcoholTask(t *testing.T) {
+	if !isAlcoholTask([]string{"social", "🍷"}) {
+		t.Error("isAlcoholTask() = false for the default label")
+	}
+	if isAlcoholTask([]string{"💊Meds"}) {
+		t.Error("isAlcoholTask() = true for a med label")
+	}
+}
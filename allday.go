@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AllDayEvent is an event without a start time on today: a birthday,
+// deadline, day off, or public holiday
+type AllDayEvent struct {
+	Summary    string `json:"summary"`
+	Source     string `json:"source"`
+	Until      string `json:"until,omitempty"`       // last day of an event spanning several
+	RaisesLoad bool   `json:"raises_load,omitempty"` // matches all_day.load_keywords
+}
+
+// validateAllDay checks the keywords are non-empty
+func validateAllDay(cfg AllDayConfig) error {
+	for i, k := range cfg.LoadKeywords {
+		if strings.TrimSpace(k) == "" {
+			return fmt.Errorf("all_day.load_keywords[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// allDayEvent converts e when it is an all-day event covering today. Google
+// gives the end date as the day after the last one.
+func allDayEvent(e GogCalendarEvent, today, source string, cfg AllDayConfig) (AllDayEvent, bool) {
+	start := e.Start.Date
+	if start == "" || start > today {
+		return AllDayEvent{}, false
+	}
+	last := start
+	if e.End.Date > start {
+		last = addDays(e.End.Date, -1)
+	}
+	if last < today {
+		return AllDayEvent{}, false
+	}
+	event := AllDayEvent{Summary: e.Summary, Source: source}
+	if last > today {
+		event.Until = last
+	}
+	summary := strings.ToLower(e.Summary)
+	for _, k := range cfg.LoadKeywords {
+		if strings.Contains(summary, strings.ToLower(k)) {
+			event.RaisesLoad = true
+			break
+		}
+	}
+	return event, true
+}
+
+// allDayLoad raises the morning load a step when an all-day event like a
+// deadline hangs over the day, however empty the calendar looks
+func allDayLoad(load string, events []AllDayEvent) string {
+	for _, e := range events {
+		if e.RaisesLoad {
+			return raiseLoad(load)
+		}
+	}
+	return load
+}
+
+// allDayLine formats an event, e.g. "Offsite (until Fri Jan 19)"
+func allDayLine(e AllDayEvent) string {
+	if e.Until == "" {
+		return e.Summary
+	}
+	if t, err := time.Parse("2006-01-02", e.Until); err == nil {
+		return e.Summary + " (until " + t.Format("Mon Jan 2") + ")"
+	}
+	return e.Summary
+}
+
+// allDayLines lists the events
+func allDayLines(events []AllDayEvent) []string {
+	var lines []string
+	for _, e := range events {
+		lines = append(lines, allDayLine(e))
+	}
+	return lines
+}
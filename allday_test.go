@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// ==================== ALL-DAY EVENT TESTS ====================
+
+func allDayGog(t *testing.T, data string) GogCalendarEvent {
+	var e GogCalendarEvent
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestAllDayEvent(t *testing.T) {
+	cfg := DefaultConfig().AllDay
+	tests := []struct {
+		name     string
+		data     string
+		expected *AllDayEvent
+	}{
+		{"single day", `{"summary": "Ana's birthday", "start": {"date": "2024-01-15"}, "end": {"date": "2024-01-16"}}`,
+			&AllDayEvent{Summary: "Ana's birthday", Source: "personal"}},
+		{"keyword", `{"summary": "Grant DEADLINE", "start": {"date": "2024-01-15"}, "end": {"date": "2024-01-16"}}`,
+			&AllDayEvent{Summary: "Grant DEADLINE", Source: "personal", RaisesLoad: true}},
+		{"spanning today", `{"summary": "PTO", "start": {"date": "2024-01-12"}, "end": {"date": "2024-01-20"}}`,
+			&AllDayEvent{Summary: "PTO", Source: "personal", Until: "2024-01-19"}},
+		{"ending today", `{"summary": "Conference", "start": {"date": "2024-01-13"}, "end": {"date": "2024-01-16"}}`,
+			&AllDayEvent{Summary: "Conference", Source: "personal"}},
+		{"without an end", `{"summary": "Holiday", "start": {"date": "2024-01-15"}}`,
+			&AllDayEvent{Summary: "Holiday", Source: "personal"}},
+		{"ended yesterday", `{"summary": "Trip", "start": {"date": "2024-01-12"}, "end": {"date": "2024-01-15"}}`, nil},
+		{"tomorrow", `{"summary": "Holiday", "start": {"date": "2024-01-16"}, "end": {"date": "2024-01-17"}}`, nil},
+		{"timed", `{"summary": "Standup", "start": {"dateTime": "2024-01-15T09:00:00Z"}}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := allDayEvent(allDayGog(t, tt.data), "2024-01-15", "personal", cfg)
+			if ok != (tt.expected != nil) {
+				t.Fatalf("allDayEvent() ok = %v, want %v", ok, tt.expected != nil)
+			}
+			if ok && got != *tt.expected {
+				t.Errorf("allDayEvent() = %+v, want %+v", got, *tt.expected)
+			}
+		})
+	}
+}
+
+func TestAllDayLoad(t *testing.T) {
+	deadline := []AllDayEvent{{Summary: "Birthday"}, {Summary: "Tax deadline", RaisesLoad: true}}
+	tests := []struct {
+		name     string
+		morning  int
+		overdue  int
+		events   []AllDayEvent
+		expected string
+	}{
+		{"no events", 0, 0, nil, "CLEAR"},
+		{"birthday only", 0, 0, []AllDayEvent{{Summary: "Birthday"}}, "CLEAR"},
+		{"deadline on a clear morning", 0, 0, deadline, "LIGHT"},
+		{"deadline on a light morning", 2, 0, deadline, "PACKED"},
+		{"deadline and task debt", 0, TaskDebtOverdue, deadline, "PACKED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &MorningBriefing{
+				Sleep:    SleepData{TotalHours: ptr(7.5), DataAvailable: true},
+				Calendar: CalendarData{MorningCount: tt.morning, AllDayEvents: tt.events},
+				Tasks:    overdueTasksFor(overdueTasks(tt.overdue), "2024-01-15"),
+			}
+			classify(b)
+			if b.Classification.MorningLoad != tt.expected {
+				t.Errorf("morning_load = %s, want %s", b.Classification.MorningLoad, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAllDayRendering(t *testing.T) {
+	m := MorningBriefing{Calendar: CalendarData{AllDayEvents: []AllDayEvent{
+		{Summary: "Ana's birthday", Source: "personal"},
+		{Summary: "Offsite", Source: "work", Until: "2024-01-19"},
+	}}}
+	if md := MorningMarkdown(m); !strings.Contains(md, "## All Day\n\n- Ana's birthday\n- Offsite (until Fri Jan 19)\n") {
+		t.Errorf("markdown missing all-day events:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "  Ana's birthday\n  Offsite (until Fri Jan 19)\n") {
+		t.Errorf("text missing all-day events:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "All day: Ana's birthday; Offsite (until Fri Jan 19)\n") {
+		t.Errorf("prompt missing all-day events (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["calendar.all_day_count"] != 2.0 {
+		t.Errorf("calendar.all_day_count = %v", vars["calendar.all_day_count"])
+	}
+	if r := RedactMorningBriefing(m); r.Calendar.AllDayEvents[0].Summary == "Ana's birthday" || r.Calendar.AllDayEvents[1].Until != "2024-01-19" {
+		t.Errorf("redacted all-day events = %+v", r.Calendar.AllDayEvents)
+	}
+}
+
+func TestValidateAllDay(t *testing.T) {
+	if err := validateAllDay(DefaultConfig().AllDay); err != nil {
+		t.Errorf("default config error: %v", err)
+	}
+	if err := validateAllDay(AllDayConfig{LoadKeywords: []string{"deadline", " "}}); err == nil {
+		t.Error("expected an error for a blank keyword")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Commits in the archive repo use a fixed identity so they work without git config
+const (
+	archiveAuthorName  = "morning-briefing"
+	archiveAuthorEmail = "briefing@localhost"
+)
+
+// getArchiveDir returns the archive repo location (archive.dir overrides)
+func getArchiveDir(cfg ArchiveConfig) string {
+	if cfg.Dir != "" {
+		return cfg.Dir
+	}
+	return filepath.Join(getDataDir(), "archive")
+}
+
+// archivePath returns the dated path of a briefing inside the archive,
+// without extension: YYYY/MM/YYYY-MM-DD-mode
+func archivePath(date, mode string) string {
+	year, month := date, date
+	if len(date) >= 7 {
+		year, month = date[:4], date[5:7]
+	}
+	return filepath.Join(year, month, date+"-"+mode)
+}
+
+// archiveBriefing writes a briefing as JSON and Markdown into the archive
+// repo and commits it. Re-running with identical output makes no commit.
+// With encryption enabled both files are sealed, since anything committed
+// can't be scrubbed from the repo's history later.
+func archiveBriefing(cfg ArchiveConfig, mode, date string, briefing any, markdown string) error {
+	dir := getArchiveDir(cfg)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("archive dir error: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if err := runGit(dir, "init", "-q"); err != nil {
+			return err
+		}
+	}
+
+	base := archivePath(date, mode)
+	if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(base)), 0o700); err != nil {
+		return fmt.Errorf("archive dir error: %w", err)
+	}
+	data, _ := json.MarshalIndent(briefing, "", "  ")
+	files := map[string][]byte{
+		base + ".json": append(data, '\n'),
+		base + ".md":   []byte(markdown),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if atRest != nil {
+			// Sealing is randomized, so keep an unchanged file as it is
+			if old, err := os.ReadFile(path); err == nil {
+				if plain, err := openAtRest(old); err == nil && bytes.Equal(plain, content) {
+					continue
+				}
+			}
+			content = sealAtRest(content)
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return fmt.Errorf("archive write error: %w", err)
+		}
+	}
+
+	if err := runGit(dir, "add", "--", base+".json", base+".md"); err != nil {
+		return err
+	}
+	// diff --quiet exits 0 when nothing is staged
+	if runGit(dir, "diff", "--cached", "--quiet") == nil {
+		return nil
+	}
+	return runGit(dir, "commit", "-q", "-m", fmt.Sprintf("%s %s", mode, date))
+}
+
+// runGit runs a git command in dir
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+archiveAuthorName,
+		"GIT_AUTHOR_EMAIL="+archiveAuthorEmail,
+		"GIT_COMMITTER_NAME="+archiveAuthorName,
+		"GIT_COMMITTER_EMAIL="+archiveAuthorEmail,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("archive git %s error: %v: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
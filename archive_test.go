@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// ==================== GIT ARCHIVE TESTS ====================
+
+func TestArchivePath(t *testing.T) {
+	got := archivePath("2024-01-15", "morning")
+	want := filepath.Join("2024", "01", "2024-01-15-morning")
+	if got != want {
+		t.Errorf("archivePath() = %q, want %q", got, want)
+	}
+}
+
+func TestArchiveBriefing(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := filepath.Join(t.TempDir(), "archive")
+	cfg := ArchiveConfig{Enabled: true, Dir: dir}
+
+	commits := func() int {
+		out, err := exec.Command("git", "-C", dir, "rev-list", "--count", "HEAD").Output()
+		if err != nil {
+			t.Fatalf("rev-list: %v", err)
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+		return n
+	}
+
+	b := MorningBriefing{TargetDate: "2024-01-15", Classification: Classification{Recommendation: "Well rested."}}
+	if err := archiveBriefing(cfg, "morning", b.TargetDate, b, MorningMarkdown(b)); err != nil {
+		t.Fatalf("archiveBriefing() error: %v", err)
+	}
+	if commits() != 1 {
+		t.Fatalf("commits = %d, want 1", commits())
+	}
+	for _, ext := range []string{".json", ".md"} {
+		if _, err := os.Stat(filepath.Join(dir, "2024", "01", "2024-01-15-morning"+ext)); err != nil {
+			t.Errorf("missing archived %s: %v", ext, err)
+		}
+	}
+
+	// Identical output doesn't create an empty commit
+	if err := archiveBriefing(cfg, "morning", b.TargetDate, b, MorningMarkdown(b)); err != nil {
+		t.Fatalf("archiveBriefing() rerun error: %v", err)
+	}
+	if commits() != 1 {
+		t.Errorf("commits after identical rerun = %d, want 1", commits())
+	}
+
+	b.Classification.Recommendation = "Take it easy."
+	if err := archiveBriefing(cfg, "morning", b.TargetDate, b, MorningMarkdown(b)); err != nil {
+		t.Fatalf("archiveBriefing() update error: %v", err)
+	}
+	if commits() != 2 {
+		t.Errorf("commits after change = %d, want 2", commits())
+	}
+}
+
+func TestArchiveBriefingEncrypted(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	enableTestEncryption(t)
+	dir := filepath.Join(t.TempDir(), "archive")
+	cfg := ArchiveConfig{Enabled: true, Dir: dir}
+
+	b := MorningBriefing{TargetDate: "2024-01-15", Meds: MedsData{DueToday: []MedTask{{Name: "Sertraline"}}}}
+	md := MorningMarkdown(b)
+	if err := archiveBriefing(cfg, "morning", b.TargetDate, b, md); err != nil {
+		t.Fatalf("archiveBriefing() error: %v", err)
+	}
+	for _, ext := range []string{".json", ".md"} {
+		data, err := os.ReadFile(filepath.Join(dir, "2024", "01", "2024-01-15-morning"+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), sealedPrefix) || strings.Contains(string(data), "Sertraline") {
+			t.Errorf("archived %s is not sealed: %.60s", ext, data)
+		}
+		plain, err := openAtRest(data)
+		if err != nil || !strings.Contains(string(plain), "Sertraline") {
+			t.Errorf("archived %s doesn't open (%v): %s", ext, err, plain)
+		}
+	}
+
+	// Sealing is randomized, but identical output still makes no commit
+	if err := archiveBriefing(cfg, "morning", b.TargetDate, b, md); err != nil {
+		t.Fatalf("archiveBriefing() rerun error: %v", err)
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-list", "--count", "HEAD").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "1" {
+		t.Errorf("commits after identical rerun = %s (%v), want 1", out, err)
+	}
+}
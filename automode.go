@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// ResolveMode turns "auto" into morning, midday, or evening by local time of
+// day using the configured cutoffs. Other modes are returned unchanged.
+func ResolveMode(mode string, now time.Time, cfg AutoModeConfig) (string, error) {
+	if mode != "auto" {
+		return mode, nil
+	}
+
+	midday, evening, err := autoModeCutoffs(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+	switch {
+	case minutes < midday:
+		return "morning", nil
+	case minutes < evening:
+		return "midday", nil
+	default:
+		return "evening", nil
+	}
+}
+
+// autoModeCutoffs validates the configured cutoffs, in minutes after midnight
+func autoModeCutoffs(cfg AutoModeConfig) (midday, evening int, err error) {
+	midday, err = parseClock(cfg.MiddayFrom)
+	if err != nil {
+		return 0, 0, fmt.Errorf("auto_mode.midday_from: %w", err)
+	}
+	evening, err = parseClock(cfg.EveningFrom)
+	if err != nil {
+		return 0, 0, fmt.Errorf("auto_mode.evening_from: %w", err)
+	}
+	if midday >= evening {
+		return 0, 0, fmt.Errorf("auto_mode.midday_from (%s) must be before evening_from (%s)", cfg.MiddayFrom, cfg.EveningFrom)
+	}
+	return midday, evening, nil
+}
+
+// parseClock converts HH:MM into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
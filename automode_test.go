@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ==================== AUTO MODE TESTS ====================
+
+func TestResolveMode(t *testing.T) {
+	cfg := DefaultConfig().AutoMode // 11:00 midday, 17:00 evening
+
+	tests := []struct {
+		mode     string
+		clock    string
+		expected string
+	}{
+		{"auto", "06:30", "morning"},
+		{"auto", "10:59", "morning"},
+		{"auto", "11:00", "midday"},
+		{"auto", "16:59", "midday"},
+		{"auto", "17:00", "evening"},
+		{"auto", "23:45", "evening"},
+		{"auto", "00:15", "morning"},
+		{"weekly", "12:00", "weekly"},
+		{"morning", "21:00", "morning"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+tt.clock, func(t *testing.T) {
+			now, _ := time.Parse("2006-01-02 15:04", "2024-01-15 "+tt.clock)
+			got, err := ResolveMode(tt.mode, now, cfg)
+			if err != nil {
+				t.Fatalf("ResolveMode() error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("ResolveMode() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAutoModeCutoffsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  AutoModeConfig
+	}{
+		{"bad midday", AutoModeConfig{MiddayFrom: "noon", EveningFrom: "17:00"}},
+		{"bad evening", AutoModeConfig{MiddayFrom: "11:00", EveningFrom: "25:00"}},
+		{"out of order", AutoModeConfig{MiddayFrom: "18:00", EveningFrom: "17:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := autoModeCutoffs(tt.cfg); err == nil {
+				t.Error("autoModeCutoffs() expected error, got nil")
+			}
+			if _, err := ResolveMode("auto", time.Now(), tt.cfg); err == nil {
+				t.Error("ResolveMode() expected error, got nil")
+			}
+		})
+	}
+}
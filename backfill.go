@@ -0,0 +1,163 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// BackfillStats summarizes a backfill run
+type BackfillStats struct {
+	Days        int
+	MorningRows int
+	EveningRows int
+	EmptyDays   int
+}
+
+// RunBackfillCommand handles `briefing backfill --from YYYY-MM-DD [--to YYYY-MM-DD] [--force]`
+func RunBackfillCommand(args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	from := fs.String("from", "", "First date to backfill (YYYY-MM-DD)")
+	to := fs.String("to", yesterday(time.Now().Format("2006-01-02")), "Last date to backfill (YYYY-MM-DD)")
+	force := fs.Bool("force", false, "Overwrite rows already in the history store")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" {
+		return errors.New("usage: briefing backfill --from YYYY-MM-DD [--to YYYY-MM-DD] [--force]")
+	}
+	if err := validateDateRange(*from, *to); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+
+	metricsDB, err := sql.Open("sqlite", getHealthDBPath())
+	if err != nil {
+		return fmt.Errorf("sqlite open error: %w", err)
+	}
+	defer metricsDB.Close()
+
+	historyDB, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return err
+	}
+	defer historyDB.Close()
+
+	stats, err := backfillHistory(metricsDB, historyDB, *from, *to, *force, time.Now())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Backfilled %d days: %d morning rows, %d evening rows, %d days without data\n",
+		stats.Days, stats.MorningRows, stats.EveningRows, stats.EmptyDays)
+	return nil
+}
+
+// validateDateRange checks both dates parse and from <= to
+func validateDateRange(from, to string) error {
+	if _, err := time.Parse("2006-01-02", from); err != nil {
+		return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", from)
+	}
+	if _, err := time.Parse("2006-01-02", to); err != nil {
+		return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", to)
+	}
+	if from > to {
+		return fmt.Errorf("--from %s is after --to %s", from, to)
+	}
+	return nil
+}
+
+// backfillHistory recomputes morning and evening summaries for each date in range
+// from the metrics DB and stores them in the history store
+func backfillHistory(metricsDB, historyDB *sql.DB, from, to string, replace bool, now time.Time) (BackfillStats, error) {
+	var stats BackfillStats
+
+	tags, err := listTags(historyDB, addDays(from, -HRVBaselineDays), to)
+	if err != nil {
+		return stats, err
+	}
+
+	for date := from; date <= to; date = addDays(date, 1) {
+		stats.Days++
+
+		morning, morningOK := buildHistoricalMorning(metricsDB, date, now, tags)
+		evening, eveningOK := buildHistoricalEvening(metricsDB, date, now)
+		if !morningOK && !eveningOK {
+			stats.EmptyDays++
+			continue
+		}
+
+		if morningOK {
+			written, err := saveHistoryRecord(historyDB, morningHistoryRecord(morning, HistorySourceBackfill), replace)
+			if err != nil {
+				return stats, err
+			}
+			if written {
+				stats.MorningRows++
+			}
+		}
+		if eveningOK {
+			written, err := saveHistoryRecord(historyDB, eveningHistoryRecord(evening, HistorySourceBackfill), replace)
+			if err != nil {
+				return stats, err
+			}
+			if written {
+				stats.EveningRows++
+			}
+		}
+	}
+	return stats, nil
+}
+
+// buildHistoricalMorning recomputes the health portion of a morning briefing for a past date
+// Calendar, meds, and training are not available historically. Returns false when no health data exists.
+func buildHistoricalMorning(db *sql.DB, date string, now time.Time, tags []LifeTag) (MorningBriefing, bool) {
+	b := MorningBriefing{
+		GeneratedAt: now.Format(time.RFC3339),
+		TargetDate:  date,
+	}
+
+	if total, err := queryLatestValue(db, "sleep_total", date); err == nil && total != nil {
+		b.Sleep.TotalHours = total
+		b.Sleep.DataAvailable = true
+		b.Sleep.IsCurrentDay = true
+		b.Sleep.DataDate = date
+	}
+	if rhr, err := queryLatestValue(db, "resting_heart_rate", date); err == nil && rhr != nil {
+		b.Vitals.RestingHR = rhr
+	}
+	if spo2, err := queryLatestValue(db, "blood_oxygen_saturation", date); err == nil && spo2 != nil {
+		b.Vitals.SpO2 = spo2
+	}
+	fillMorningHealthFromDB(&b, db, date, tags)
+
+	if b.Sleep.TotalHours == nil && b.Vitals.HRV == nil && b.Vitals.RestingHR == nil {
+		return b, false
+	}
+
+	classify(&b)
+	return b, true
+}
+
+// buildHistoricalEvening recomputes the health portion of an evening briefing for a past date
+// Returns false when nothing was logged that day.
+func buildHistoricalEvening(db *sql.DB, date string, now time.Time) (EveningBriefing, bool) {
+	b := newEveningBriefing(now, date)
+	fillEveningHealthFromDB(&b, db, date, yesterday(date))
+
+	if b.Energy.ConsumedKcal == 0 && b.Energy.ActiveKcal == 0 && b.Activity.Steps == 0 {
+		return b, false
+	}
+
+	calculateEveningHydration(&b)
+	return b, true
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ==================== BACKFILL TESTS ====================
+
+func TestBackfillHistory(t *testing.T) {
+	metricsDB := newTestMetricsDB(t)
+	historyDB := newTestHistoryDB(t)
+
+	_, err := metricsDB.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit) VALUES
+		('sleep_total', '2024-01-14 00:00:00 +0700', 7.2, 'hr'),
+		('sleep_deep', '2024-01-14 00:00:00 +0700', 1.1, 'hr'),
+		('heart_rate_variability', '2024-01-14 06:00:00 +0700', 48, 'ms'),
+		('dietary_energy', '2024-01-14 12:30:00 +0700', 1900, 'kcal'),
+		('active_energy', '2024-01-14 18:00:00 +0700', 500, 'kcal'),
+		('sleep_total', '2024-01-16 00:00:00 +0700', 4.5, 'hr')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	stats, err := backfillHistory(metricsDB, historyDB, "2024-01-14", "2024-01-16", false, now)
+	if err != nil {
+		t.Fatalf("backfillHistory() error: %v", err)
+	}
+
+	expected := BackfillStats{Days: 3, MorningRows: 2, EveningRows: 1, EmptyDays: 1}
+	if stats != expected {
+		t.Errorf("stats = %+v, want %+v", stats, expected)
+	}
+
+	var quality, source string
+	historyDB.QueryRow(`SELECT sleep_quality, source FROM briefings WHERE date = '2024-01-16' AND mode = 'morning'`).Scan(&quality, &source)
+	if quality != "POOR" {
+		t.Errorf("2024-01-16 sleep_quality = %q, want %q", quality, "POOR")
+	}
+	if source != HistorySourceBackfill {
+		t.Errorf("source = %q, want %q", source, HistorySourceBackfill)
+	}
+
+	var consumed float64
+	historyDB.QueryRow(`SELECT consumed_kcal FROM briefings WHERE date = '2024-01-14' AND mode = 'evening'`).Scan(&consumed)
+	if consumed != 1900 {
+		t.Errorf("2024-01-14 consumed_kcal = %v, want 1900", consumed)
+	}
+
+	// Re-running skips existing rows unless forced
+	stats, _ = backfillHistory(metricsDB, historyDB, "2024-01-14", "2024-01-16", false, now)
+	if stats.MorningRows != 0 || stats.EveningRows != 0 {
+		t.Errorf("second run wrote %+v, want no rows", stats)
+	}
+	stats, _ = backfillHistory(metricsDB, historyDB, "2024-01-14", "2024-01-16", true, now)
+	if stats.MorningRows != 2 || stats.EveningRows != 1 {
+		t.Errorf("forced run wrote %+v, want 2 morning and 1 evening rows", stats)
+	}
+}
+
+func TestValidateDateRange(t *testing.T) {
+	tests := []struct {
+		from, to    string
+		expectError bool
+	}{
+		{"2023-01-01", "2023-12-31", false},
+		{"2023-01-01", "2023-01-01", false},
+		{"2023-12-31", "2023-01-01", true},
+		{"01/01/2023", "2023-12-31", true},
+		{"2023-01-01", "tomorrow", true},
+	}
+
+	for _, tt := range tests {
+		err := validateDateRange(tt.from, tt.to)
+		if (err != nil) != tt.expectError {
+			t.Errorf("validateDateRange(%q, %q) error = %v, expectError %v", tt.from, tt.to, err, tt.expectError)
+		}
+	}
+}
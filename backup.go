@@ -0,0 +1,250 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Archive layout: data/<path under the data dir>, config/config.json
+const (
+	backupDataPrefix = "data/"
+	backupConfigName = "config/config.json"
+)
+
+// RunBackupCommand handles `briefing backup --to path.tar.gz`
+func RunBackupCommand(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	to := fs.String("to", "", "Archive path to write (.tar.gz)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *to == "" {
+		return errors.New("usage: briefing backup --to path.tar.gz")
+	}
+
+	f, err := os.OpenFile(*to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("backup create error: %w", err)
+	}
+
+	n, err := createBackup(f, getDataDir(), getConfigPath())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*to)
+		return err
+	}
+
+	fmt.Printf("Backed up %d files to %s\n", n, *to)
+	return nil
+}
+
+// RunRestoreCommand handles `briefing restore --from path.tar.gz [--force]`
+func RunRestoreCommand(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	from := fs.String("from", "", "Archive path to restore (.tar.gz)")
+	force := fs.Bool("force", false, "Overwrite existing files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" {
+		return errors.New("usage: briefing restore --from path.tar.gz [--force]")
+	}
+
+	f, err := os.Open(*from)
+	if err != nil {
+		return fmt.Errorf("restore open error: %w", err)
+	}
+	defer f.Close()
+
+	n, err := restoreBackup(f, getDataDir(), getConfigPath(), *force)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %d files from %s\n", n, *from)
+	return nil
+}
+
+// createBackup writes the data dir and config file as a gzipped tarball
+// Returns the number of files archived
+func createBackup(w io.Writer, dataDir, configPath string) (int, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	count := 0
+
+	err := filepath.WalkDir(dataDir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == dataDir {
+			return fs.SkipDir // Nothing stored yet
+		}
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dataDir, p)
+		if err != nil {
+			return err
+		}
+		count++
+		return addFileToTar(tw, p, backupDataPrefix+filepath.ToSlash(rel))
+	})
+	if err != nil {
+		return count, fmt.Errorf("backup data error: %w", err)
+	}
+
+	if _, err := os.Stat(configPath); err == nil {
+		if err := addFileToTar(tw, configPath, backupConfigName); err != nil {
+			return count, fmt.Errorf("backup config error: %w", err)
+		}
+		count++
+	}
+
+	if err := tw.Close(); err != nil {
+		return count, err
+	}
+	return count, gz.Close()
+}
+
+func addFileToTar(tw *tar.Writer, src, name string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(info.Mode().Perm()),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// stagedFile is a restored file written beside its destination, waiting to replace it
+type stagedFile struct {
+	tmp, dest string
+}
+
+// restoreBackup extracts an archive created by createBackup
+// Existing files are left alone unless force is set. Every entry is checked
+// and staged before any is moved into place, so a conflict or bad entry
+// leaves the data dir as it was. Returns the number of files restored.
+func restoreBackup(r io.Reader, dataDir, configPath string, force bool) (int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, fmt.Errorf("restore read error: %w", err)
+	}
+	defer gz.Close()
+
+	var staged []stagedFile
+	// Renamed files are gone by now; this only clears up after a failure
+	defer func() {
+		for _, s := range staged {
+			os.Remove(s.tmp)
+		}
+	}()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("restore read error: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		dest, err := backupDestination(hdr.Name, dataDir, configPath)
+		if err != nil {
+			return 0, err
+		}
+
+		if !force {
+			if _, err := os.Stat(dest); err == nil {
+				return 0, fmt.Errorf("restore would overwrite %s (use --force)", dest)
+			}
+		}
+
+		tmp, err := stageRestoredFile(dest, tr, os.FileMode(hdr.Mode).Perm())
+		if err != nil {
+			return 0, fmt.Errorf("restore write error (%s): %w", dest, err)
+		}
+		staged = append(staged, stagedFile{tmp, dest})
+	}
+
+	for i, s := range staged {
+		if err := os.Rename(s.tmp, s.dest); err != nil {
+			return i, fmt.Errorf("restore write error (%s): %w", s.dest, err)
+		}
+	}
+	return len(staged), nil
+}
+
+// backupDestination maps an archive entry to its location on disk
+// Rejects entries that would escape the data dir
+func backupDestination(name, dataDir, configPath string) (string, error) {
+	if name == backupConfigName {
+		return configPath, nil
+	}
+	rel, ok := strings.CutPrefix(name, backupDataPrefix)
+	if !ok {
+		return "", fmt.Errorf("unexpected archive entry %q", name)
+	}
+	clean := path.Clean(rel)
+	if clean == "." || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("unsafe archive entry %q", name)
+	}
+	return filepath.Join(dataDir, filepath.FromSlash(clean)), nil
+}
+
+// stageRestoredFile writes r to a temp file in dest's directory, so the
+// final rename stays on one filesystem, and returns its path
+func stageRestoredFile(dest string, r io.Reader, mode os.FileMode) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".restore-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ==================== BACKUP/RESTORE TESTS ====================
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	dataDir := filepath.Join(src, "data")
+	configPath := filepath.Join(src, "config.json")
+
+	files := map[string]string{
+		"history.db":          "sqlite bytes",
+		"cache/calendar.json": `{"events":[]}`,
+	}
+	for name, content := range files {
+		p := filepath.Join(dataDir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(configPath, []byte(`{"mqtt":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	n, err := createBackup(&buf, dataDir, configPath)
+	if err != nil {
+		t.Fatalf("createBackup() error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("createBackup() archived %d files, want 3", n)
+	}
+
+	dst := t.TempDir()
+	newDataDir := filepath.Join(dst, "data")
+	newConfigPath := filepath.Join(dst, "conf", "config.json")
+	n, err = restoreBackup(bytes.NewReader(buf.Bytes()), newDataDir, newConfigPath, false)
+	if err != nil {
+		t.Fatalf("restoreBackup() error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("restoreBackup() restored %d files, want 3", n)
+	}
+
+	for name, content := range files {
+		got, err := os.ReadFile(filepath.Join(newDataDir, name))
+		if err != nil {
+			t.Errorf("restored %s missing: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("restored %s = %q, want %q", name, got, content)
+		}
+	}
+	if got, _ := os.ReadFile(newConfigPath); string(got) != `{"mqtt":{}}` {
+		t.Errorf("restored config = %q", got)
+	}
+
+	// Restoring again without force must not clobber existing state
+	if _, err := restoreBackup(bytes.NewReader(buf.Bytes()), newDataDir, newConfigPath, false); err == nil {
+		t.Error("restoreBackup() over existing files expected error, got nil")
+	}
+	if _, err := restoreBackup(bytes.NewReader(buf.Bytes()), newDataDir, newConfigPath, true); err != nil {
+		t.Errorf("restoreBackup(force) error: %v", err)
+	}
+}
+
+func TestBackupMissingDataDir(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	n, err := createBackup(&buf, filepath.Join(dir, "nope"), filepath.Join(dir, "nope.json"))
+	if err != nil {
+		t.Fatalf("createBackup() error: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("createBackup() archived %d files, want 0", n)
+	}
+}
+
+func TestRestoreRejectsUnsafePaths(t *testing.T) {
+	for _, name := range []string{"data/../../etc/passwd", "data/..", "other/file", "/abs"} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gz)
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: 1, Typeflag: tar.TypeReg})
+			tw.Write([]byte("x"))
+			tw.Close()
+			gz.Close()
+
+			dir := t.TempDir()
+			_, err := restoreBackup(&buf, filepath.Join(dir, "data"), filepath.Join(dir, "config.json"), true)
+			if err == nil {
+				t.Errorf("restoreBackup(%q) expected error, got nil", name)
+			}
+		})
+	}
+}
+
+func TestRestoreConflictWritesNothing(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{backupDataPrefix + "cache/new.json", backupDataPrefix + "history.db"} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: 3, Typeflag: tar.TypeReg})
+		tw.Write([]byte("new"))
+	}
+	tw.Close()
+	gz.Close()
+
+	dataDir := filepath.Join(t.TempDir(), "data")
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "history.db"), []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// history.db comes second: the entry before it must not be restored either
+	n, err := restoreBackup(bytes.NewReader(buf.Bytes()), dataDir, filepath.Join(dataDir, "config.json"), false)
+	if err == nil || n != 0 {
+		t.Fatalf("restoreBackup() = %d, %v; want 0 and an overwrite error", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "cache", "new.json")); !os.IsNotExist(err) {
+		t.Errorf("cache/new.json was restored before the conflict: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dataDir, "history.db")); string(got) != "old" {
+		t.Errorf("history.db = %q, want it untouched", got)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dataDir, "cache"))
+	if len(entries) != 0 {
+		t.Errorf("staged files left behind: %v", entries)
+	}
+
+	// With force both are written
+	n, err = restoreBackup(bytes.NewReader(buf.Bytes()), dataDir, filepath.Join(dataDir, "config.json"), true)
+	if err != nil || n != 2 {
+		t.Fatalf("restoreBackup(force) = %d, %v", n, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dataDir, "history.db")); string(got) != "new" {
+		t.Errorf("history.db = %q after force", got)
+	}
+	if info, err := os.Stat(filepath.Join(dataDir, "history.db")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("history.db mode = %v, %v", info, err)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Blood pressure windows, in days ending on the briefing date
+const (
+	BPMaxAgeDays       = 3  // older readings aren't shown as this morning's
+	BPRecentDays       = 7  // recent mean for the trend
+	BPPriorDays        = 30 // earlier mean the recent one is compared with
+	BPTrendMinReadings = 2  // readings needed in each window for a trend
+	BPTrendMmHg        = 5  // systolic mean change that counts as RISING/FALLING
+)
+
+// Blood pressure flags added to VitalsData.Flags
+const (
+	FlagBPHigh = "BP_HIGH"
+	FlagBPLow  = "BP_LOW"
+)
+
+// BloodPressureData is the latest reading, its range check, and the trend
+type BloodPressureData struct {
+	Systolic  float64 `json:"systolic"`
+	Diastolic float64 `json:"diastolic"`
+	Date      string  `json:"date"`            // day of the reading
+	Status    string  `json:"status"`          // NORMAL, HIGH, LOW against the configured range
+	Trend     string  `json:"trend,omitempty"` // RISING, STABLE, FALLING
+}
+
+// bpReading is one paired systolic/diastolic measurement
+type bpReading struct {
+	Date      string
+	Systolic  float64
+	Diastolic float64
+}
+
+// validateBloodPressure checks the configured ranges
+func validateBloodPressure(cfg BloodPressureConfig) error {
+	if cfg.SystolicMin <= 0 || cfg.DiastolicMin <= 0 || cfg.SystolicMin >= cfg.SystolicMax || cfg.DiastolicMin >= cfg.DiastolicMax {
+		return errors.New("blood_pressure: each min must be positive and below its max")
+	}
+	return nil
+}
+
+// queryBPReadings returns readings from..to (inclusive) oldest first, pairing
+// systolic and diastolic rows logged at the same time
+func queryBPReadings(db *sql.DB, from, to string) ([]bpReading, error) {
+	rows, err := db.Query(`
+		SELECT substr(local_ts(s.timestamp), 1, 10), s.value, d.value
+		FROM metrics s
+		JOIN metrics d ON d.metric_name = 'blood_pressure_diastolic' AND d.timestamp = s.timestamp
+		WHERE s.metric_name = 'blood_pressure_systolic'
+		AND local_ts(s.timestamp) >= ? AND local_ts(s.timestamp) < ?
+		ORDER BY local_ts(s.timestamp)
+	`, from, addDays(to, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readings []bpReading
+	for rows.Next() {
+		var r bpReading
+		if err := rows.Scan(&r.Date, &r.Systolic, &r.Diastolic); err != nil {
+			return nil, err
+		}
+		readings = append(readings, r)
+	}
+	return readings, rows.Err()
+}
+
+// bloodPressureFor picks the latest reading within BPMaxAgeDays of date,
+// checks it against cfg, and compares the recent systolic mean with the
+// prior one. Nil when there is no recent reading.
+func bloodPressureFor(readings []bpReading, date string, cfg BloodPressureConfig) *BloodPressureData {
+	if len(readings) == 0 {
+		return nil
+	}
+	latest := readings[len(readings)-1]
+	if latest.Date < addDays(date, -(BPMaxAgeDays-1)) {
+		return nil
+	}
+
+	bp := &BloodPressureData{Systolic: latest.Systolic, Diastolic: latest.Diastolic, Date: latest.Date, Status: "NORMAL"}
+	switch {
+	case latest.Systolic > cfg.SystolicMax || latest.Diastolic > cfg.DiastolicMax:
+		bp.Status = "HIGH"
+	case latest.Systolic < cfg.SystolicMin || latest.Diastolic < cfg.DiastolicMin:
+		bp.Status = "LOW"
+	}
+
+	recentFrom := addDays(date, -(BPRecentDays - 1))
+	priorFrom := addDays(recentFrom, -BPPriorDays)
+	var recent, prior []float64
+	for _, r := range readings {
+		switch {
+		case r.Date >= recentFrom:
+			recent = append(recent, r.Systolic)
+		case r.Date >= priorFrom:
+			prior = append(prior, r.Systolic)
+		}
+	}
+	if len(recent) >= BPTrendMinReadings && len(prior) >= BPTrendMinReadings {
+		change := mean(recent) - mean(prior)
+		switch {
+		case change >= BPTrendMmHg:
+			bp.Trend = "RISING"
+		case change <= -BPTrendMmHg:
+			bp.Trend = "FALLING"
+		default:
+			bp.Trend = "STABLE"
+		}
+	}
+	return bp
+}
+
+// mean averages values, rounded to 0.1
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return math.Round(sum/float64(len(values))*10) / 10
+}
+
+// applyBloodPressure sets the reading and flags it when outside the range
+func applyBloodPressure(v *VitalsData, bp *BloodPressureData) {
+	v.BloodPressure = bp
+	if bp == nil {
+		return
+	}
+	switch bp.Status {
+	case "HIGH":
+		v.Flags = append(v.Flags, FlagBPHigh)
+	case "LOW":
+		v.Flags = append(v.Flags, FlagBPLow)
+	}
+}
+
+// bpValue formats the reading, e.g. "128/82 mmHg (RISING)"
+func bpValue(bp *BloodPressureData) string {
+	if bp == nil {
+		return "–"
+	}
+	value := fmt.Sprintf("%.0f/%.0f mmHg", bp.Systolic, bp.Diastolic)
+	if bp.Trend != "" {
+		value += " (" + bp.Trend + ")"
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ==================== BLOOD PRESSURE TESTS ====================
+
+func TestQueryBPReadings(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('blood_pressure_systolic', '2024-01-14 07:00:00 +0700', 124, 'mmHg', 'Health'),
+		('blood_pressure_diastolic', '2024-01-14 07:00:00 +0700', 80, 'mmHg', 'Health'),
+		('blood_pressure_systolic', '2024-01-15 07:05:00 +0700', 131, 'mmHg', 'Health'),
+		('blood_pressure_diastolic', '2024-01-15 07:05:00 +0700', 84, 'mmHg', 'Health'),
+		('blood_pressure_systolic', '2024-01-15 20:00:00 +0700', 140, 'mmHg', 'Health'),
+		('blood_pressure_systolic', '2024-01-16 07:00:00 +0700', 120, 'mmHg', 'Health'),
+		('blood_pressure_diastolic', '2024-01-16 07:00:00 +0700', 78, 'mmHg', 'Health')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The evening systolic has no diastolic partner; 01-16 is after the range
+	readings, err := queryBPReadings(db, "2024-01-01", "2024-01-15")
+	if err != nil {
+		t.Fatalf("queryBPReadings() error: %v", err)
+	}
+	want := []bpReading{{"2024-01-14", 124, 80}, {"2024-01-15", 131, 84}}
+	if !slices.Equal(readings, want) {
+		t.Errorf("readings = %+v, want %+v", readings, want)
+	}
+}
+
+func TestBloodPressureFor(t *testing.T) {
+	cfg := DefaultConfig().BloodPressure
+	prior := []bpReading{{"2024-01-01", 118, 76}, {"2024-01-03", 120, 78}}
+
+	tests := []struct {
+		name       string
+		readings   []bpReading
+		wantStatus string
+		wantTrend  string
+		wantNil    bool
+	}{
+		{"no readings", nil, "", "", true},
+		{"only stale readings", []bpReading{{"2024-01-12", 120, 80}}, "", "", true},
+		{"normal, too few for a trend", []bpReading{{"2024-01-15", 122, 80}}, "NORMAL", "", false},
+		{"high diastolic", []bpReading{{"2024-01-14", 125, 88}}, "HIGH", "", false},
+		{"low systolic", []bpReading{{"2024-01-15", 85, 65}}, "LOW", "", false},
+		{"rising", append(slices.Clone(prior), bpReading{"2024-01-12", 126, 80}, bpReading{"2024-01-15", 128, 82}), "NORMAL", "RISING", false},
+		{"stable", append(slices.Clone(prior), bpReading{"2024-01-12", 121, 80}, bpReading{"2024-01-15", 119, 78}), "NORMAL", "STABLE", false},
+		{"falling", append(slices.Clone(prior), bpReading{"2024-01-12", 112, 72}, bpReading{"2024-01-15", 110, 70}), "NORMAL", "FALLING", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp := bloodPressureFor(tt.readings, "2024-01-15", cfg)
+			if tt.wantNil {
+				if bp != nil {
+					t.Errorf("bloodPressureFor() = %+v, want nil", bp)
+				}
+				return
+			}
+			if bp == nil || bp.Status != tt.wantStatus || bp.Trend != tt.wantTrend {
+				t.Errorf("bloodPressureFor() = %+v, want status %q trend %q", bp, tt.wantStatus, tt.wantTrend)
+			}
+		})
+	}
+}
+
+func TestApplyBloodPressure(t *testing.T) {
+	var v VitalsData
+	applyBloodPressure(&v, &BloodPressureData{Systolic: 142, Diastolic: 90, Date: "2024-01-15", Status: "HIGH", Trend: "RISING"})
+	if !slices.Contains(v.Flags, FlagBPHigh) {
+		t.Errorf("Flags = %v, want %s", v.Flags, FlagBPHigh)
+	}
+	m := MorningBriefing{Vitals: v}
+	if got := MorningText(m, textStyle{}); !strings.Contains(got, "BP       142/90 mmHg (RISING)  HIGH\n") {
+		t.Errorf("text missing BP line:\n%s", got)
+	}
+
+	applyBloodPressure(&v, nil)
+	if v.BloodPressure != nil {
+		t.Error("BloodPressure set without a reading")
+	}
+}
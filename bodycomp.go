@@ -0,0 +1,175 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+)
+
+// Body composition estimate settings
+const (
+	KcalPerKgFat         = 7700 // energy in a kilogram of body fat
+	BalanceDays          = 7    // days of intake averaged into the current deficit
+	CompositionMinChange = 0.1  // kg/week of weight change worth attributing
+)
+
+// BodyComposition trends body fat and lean mass and estimates where the
+// week's weight change came from
+type BodyComposition struct {
+	BodyFatPct         *float64 `json:"body_fat_pct,omitempty"`             // 7-day EMA
+	BodyFatChangePts   *float64 `json:"body_fat_change_per_week,omitempty"` // percentage points
+	LeanMassKg         *float64 `json:"lean_mass_kg,omitempty"`             // 7-day EMA
+	LeanChangePerWeek  *float64 `json:"lean_change_per_week_kg,omitempty"`
+	AvgBalanceKcal     *float64 `json:"avg_balance_kcal,omitempty"` // intake minus BMR + active, last 7 logged days
+	FatChangePerWeekKg *float64 `json:"fat_change_per_week_kg,omitempty"`
+	EstimateFrom       string   `json:"estimate_from,omitempty"` // lean_mass, body_fat, energy_balance
+	ChangeFrom         string   `json:"change_from,omitempty"`   // FAT, LEAN, MIXED
+}
+
+// queryAverageBalance averages intake minus (bmr + active energy) over the
+// days ending on date that have intake logged; nil when none do
+func queryAverageBalance(db *sql.DB, date string, days, bmr int) (*float64, error) {
+	sum, counted := 0.0, 0
+	for i := 0; i < days; i++ {
+		day := addDays(date, -i)
+		intake, err := queryDayTotal(db, "dietary_energy", day)
+		if err != nil {
+			return nil, err
+		}
+		if intake == 0 {
+			continue
+		}
+		active, err := queryDayTotal(db, "active_energy", day)
+		if err != nil {
+			return nil, err
+		}
+		sum += intake - float64(bmr) - active
+		counted++
+	}
+	if counted == 0 {
+		return nil, nil
+	}
+	avg := math.Round(sum / float64(counted))
+	return &avg, nil
+}
+
+// bodyCompositionFor trends the body fat and lean mass readings and splits
+// the weekly weight change into fat and the rest: from measured lean mass
+// when available, then body fat %, then the energy balance. Nil when there
+// is nothing to report.
+func bodyCompositionFor(weight, bodyFat, lean []dailyValue, balance *float64) *BodyComposition {
+	c := &BodyComposition{AvgBalanceKcal: balance}
+	var fatNow, fatPrior, leanChange *float64
+	if len(bodyFat) > 0 {
+		latest, prior := weeklyTrend(bodyFat)
+		pct := roundTo(latest, 1)
+		c.BodyFatPct, fatNow, fatPrior = &pct, &latest, prior
+		if prior != nil {
+			change := roundTo(latest-*prior, 2)
+			c.BodyFatChangePts = &change
+		}
+	}
+	if len(lean) > 0 {
+		latest, prior := weeklyTrend(lean)
+		kg := roundTo(latest, 1)
+		c.LeanMassKg = &kg
+		if prior != nil {
+			change := latest - *prior
+			rounded := roundTo(change, 2)
+			c.LeanChangePerWeek, leanChange = &rounded, &change
+		}
+	}
+
+	if len(weight) > 0 {
+		trend, prior := weeklyTrend(weight)
+		if prior != nil {
+			change := trend - *prior
+			var fat float64
+			switch {
+			case leanChange != nil:
+				fat, c.EstimateFrom = change-*leanChange, "lean_mass"
+			case fatPrior != nil:
+				fat, c.EstimateFrom = trend**fatNow/100-*prior**fatPrior/100, "body_fat"
+			case balance != nil:
+				fat, c.EstimateFrom = *balance*7/KcalPerKgFat, "energy_balance"
+			}
+			if c.EstimateFrom != "" {
+				rounded := roundTo(fat, 2)
+				c.FatChangePerWeekKg = &rounded
+				c.ChangeFrom = changeFrom(roundTo(change, 2), fat)
+			}
+		}
+	}
+
+	if c.BodyFatPct == nil && c.LeanMassKg == nil && c.FatChangePerWeekKg == nil {
+		return nil
+	}
+	return c
+}
+
+// changeFrom attributes a weight change to FAT (three quarters or more of
+// it), LEAN (a quarter or less, covering water and glycogen too), or MIXED
+func changeFrom(weightChange, fatChange float64) string {
+	if math.Abs(weightChange) < CompositionMinChange {
+		return ""
+	}
+	share := fatChange / weightChange
+	switch {
+	case share >= 0.75:
+		return "FAT"
+	case share <= 0.25:
+		return "LEAN"
+	}
+	return "MIXED"
+}
+
+// queryBodyComposition loads weight, body fat and lean mass over the weight
+// lookback ending on date, and the energy balance against bmr of the days before it
+func queryBodyComposition(db *sql.DB, date string, bmr int) (*BodyComposition, error) {
+	from := addDays(date, -(WeightLookbackDays - 1))
+	series := make([][]dailyValue, 3)
+	for i, metric := range []string{"weight_body_mass", "body_fat_percentage", "lean_body_mass"} {
+		values, err := queryDailyValues(db, metric, from, date)
+		if err != nil {
+			return nil, err
+		}
+		series[i] = values
+	}
+	balance, err := queryAverageBalance(db, addDays(date, -1), BalanceDays, bmr)
+	if err != nil {
+		return nil, err
+	}
+	return bodyCompositionFor(series[0], series[1], series[2], balance), nil
+}
+
+// estimateSources names EstimateFrom for display
+var estimateSources = map[string]string{"lean_mass": "lean mass", "body_fat": "body fat %", "energy_balance": "energy balance"}
+
+// compositionLine describes where the change came from, e.g.
+// "mostly fat (fat -0.42 of -0.50 kg/week, from lean mass)"; empty without an estimate
+func compositionLine(w *WeightData) string {
+	if w == nil || w.Composition == nil || w.Composition.ChangeFrom == "" || w.ChangePerWeekKg == nil {
+		return ""
+	}
+	c := w.Composition
+	what := map[string]string{"FAT": "mostly fat", "LEAN": "mostly lean mass or water", "MIXED": "fat and lean mass"}[c.ChangeFrom]
+	return fmt.Sprintf("%s (fat %+.2f of %+.2f kg/week, from %s)", what, *c.FatChangePerWeekKg, *w.ChangePerWeekKg, estimateSources[c.EstimateFrom])
+}
+
+// bodyFatValue formats body fat with its weekly change, e.g. "22.4% (-0.15 pts/week)"
+func bodyFatValue(c *BodyComposition) string {
+	value := fmt.Sprintf("%.1f%%", *c.BodyFatPct)
+	if c.BodyFatChangePts != nil {
+		value += fmt.Sprintf(" (%+.2f pts/week)", *c.BodyFatChangePts)
+	}
+	return value
+}
+
+// leanMassValue formats lean mass with its weekly change, e.g. "58.1 kg (+0.05 kg/week)"
+func leanMassValue(c *BodyComposition) string {
+	value := fmt.Sprintf("%.1f kg", *c.LeanMassKg)
+	if c.LeanChangePerWeek != nil {
+		value += fmt.Sprintf(" (%+.2f kg/week)", *c.LeanChangePerWeek)
+	}
+	return value
+}
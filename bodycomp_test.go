@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== BODY COMPOSITION TESTS ====================
+
+func TestChangeFrom(t *testing.T) {
+	tests := []struct {
+		weight, fat float64
+		want        string
+	}{
+		{-0.5, -0.45, "FAT"},
+		{-0.5, -0.6, "FAT"},
+		{-0.5, -0.25, "MIXED"},
+		{-0.5, -0.1, "LEAN"},
+		{-0.5, 0.1, "LEAN"},
+		{0.4, 0.35, "FAT"},
+		{-0.05, -0.05, ""},
+	}
+	for _, tt := range tests {
+		if got := changeFrom(tt.weight, tt.fat); got != tt.want {
+			t.Errorf("changeFrom(%v, %v) = %q, want %q", tt.weight, tt.fat, got, tt.want)
+		}
+	}
+}
+
+// series is days daily readings from start, changing by step each day
+func series(start string, days int, first, step float64) []dailyValue {
+	var values []dailyValue
+	for i := range days {
+		values = append(values, dailyValue{addDays(start, i), first + step*float64(i)})
+	}
+	return values
+}
+
+func TestBodyCompositionFor(t *testing.T) {
+	weight := series("2024-01-01", 15, 82, -0.1)
+
+	// Steady lean mass puts the whole loss on fat
+	c := bodyCompositionFor(weight, nil, series("2024-01-01", 15, 60, 0), nil)
+	if c == nil || c.EstimateFrom != "lean_mass" || c.ChangeFrom != "FAT" || *c.LeanChangePerWeek != 0 {
+		t.Errorf("lean-based estimate = %+v, want FAT from lean_mass", c)
+	}
+
+	// Body fat % falling slower than weight leaves part of the loss lean
+	c = bodyCompositionFor(weight, series("2024-01-01", 15, 25, -0.03), nil, nil)
+	if c == nil || c.EstimateFrom != "body_fat" || c.ChangeFrom != "MIXED" || *c.BodyFatChangePts >= 0 {
+		t.Errorf("body-fat-based estimate = %+v, want MIXED from body_fat", c)
+	}
+
+	// A 500 kcal daily deficit is about 0.45 kg of fat a week
+	c = bodyCompositionFor(weight, nil, nil, ptr(-500))
+	if c == nil || c.EstimateFrom != "energy_balance" || *c.FatChangePerWeekKg != -0.45 || c.ChangeFrom != "MIXED" {
+		t.Errorf("balance-based estimate = %+v, want -0.45 kg fat, MIXED", c)
+	}
+
+	if c := bodyCompositionFor(weight[:3], nil, nil, ptr(-500)); c != nil {
+		t.Errorf("bodyCompositionFor() without a weekly change = %+v, want nil", c)
+	}
+}
+
+func TestQueryAverageBalance(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('dietary_energy', '2024-01-13 12:00:00 +0700', 1500, 'kcal', 'briefing'),
+		('active_energy', '2024-01-13 12:00:00 +0700', 364, 'kcal', 'Apple Watch'),
+		('dietary_energy', '2024-01-14 12:00:00 +0700', 2000, 'kcal', 'briefing'),
+		('active_energy', '2024-01-14 12:00:00 +0700', 400, 'kcal', 'Apple Watch'),
+		('active_energy', '2024-01-12 12:00:00 +0700', 800, 'kcal', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 01-12 has no intake logged and is skipped
+	avg, err := queryAverageBalance(db, "2024-01-14", BalanceDays, 1636)
+	if err != nil {
+		t.Fatalf("queryAverageBalance() error: %v", err)
+	}
+	if avg == nil || *avg != -268 {
+		t.Errorf("queryAverageBalance() = %v, want -268", avg)
+	}
+	if avg, err := queryAverageBalance(db, "2024-02-01", BalanceDays, 1636); err != nil || avg != nil {
+		t.Errorf("queryAverageBalance() without intake = %v, %v; want nil", avg, err)
+	}
+}
+
+func TestEveningBodyComposition(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('weight_body_mass', '2024-01-01 07:00:00 +0700', 82, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-08 07:00:00 +0700', 81.2, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-15 07:00:00 +0700', 80.4, 'kg', 'Scale'),
+		('lean_body_mass', '2024-01-01 07:00:00 +0700', 60, 'kg', 'Scale'),
+		('lean_body_mass', '2024-01-08 07:00:00 +0700', 60, 'kg', 'Scale'),
+		('lean_body_mass', '2024-01-15 07:00:00 +0700', 60, 'kg', 'Scale'),
+		('body_fat_percentage', '2024-01-15 07:00:00 +0700', 25.4, '%', 'Scale')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	c := e.Weight.Composition
+	if c == nil || c.ChangeFrom != "FAT" || *c.BodyFatPct != 25.4 || *c.LeanMassKg != 60 {
+		t.Fatalf("Composition = %+v, want FAT with 25.4%% body fat and 60 kg lean", c)
+	}
+
+	md := EveningMarkdown(e)
+	for _, want := range []string{"- Body fat: 25.4%\n", "- Lean mass: 60.0 kg (+0.00 kg/week)\n", "- Change from: mostly fat (fat "} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if text := EveningText(e, textStyle{}); !strings.Contains(text, "from lean mass)\n") {
+		t.Errorf("text missing composition line:\n%s", text)
+	}
+	if vars := eveningRuleVars(e); vars["weight.change_from"] != "FAT" || vars["weight.lean_kg"] != 60.0 {
+		t.Errorf("rule vars = %v, %v; want FAT, 60", vars["weight.change_from"], vars["weight.lean_kg"])
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// BudgetHistoryWeeks is how many past same weekdays project the rest of
+// today's active energy
+const BudgetHistoryWeeks = 4
+
+// queryHourlyTotals sums metric per hour of date; ok is false when the day has no rows
+func queryHourlyTotals(db *sql.DB, metric, date string) (hours [24]float64, ok bool, err error) {
+	rows, err := db.Query(hourlyTotalsSQL(metric), metric, date)
+	if err != nil {
+		return hours, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hour string
+		var total float64
+		if err := rows.Scan(&hour, &total); err != nil {
+			return hours, false, err
+		}
+		if h, err := strconv.Atoi(hour); err == nil && h >= 0 && h < 24 {
+			hours[h] += total
+			ok = true
+		}
+	}
+	return hours, ok, rows.Err()
+}
+
+// restOfDay is what hours holds after clock, the current hour pro-rated
+func restOfDay(hours [24]float64, clock time.Time) float64 {
+	h := clock.Hour()
+	rest := hours[h] * float64(60-clock.Minute()) / 60
+	for _, v := range hours[h+1:] {
+		rest += v
+	}
+	return rest
+}
+
+// queryProjectedActive averages the active energy burned after now's time
+// of day on the same weekday over the last BudgetHistoryWeeks; nil when none
+// of those days has data
+func queryProjectedActive(db *sql.DB, now time.Time) (*float64, error) {
+	sum, counted := 0.0, 0
+	for w := 1; w <= BudgetHistoryWeeks; w++ {
+		hours, ok, err := queryHourlyTotals(db, "active_energy", now.AddDate(0, 0, -7*w).Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sum += restOfDay(hours, now)
+			counted++
+		}
+	}
+	if counted == 0 {
+		return nil, nil
+	}
+	avg := math.Round(sum / float64(counted))
+	return &avg, nil
+}
+
+// fillCalorieBudget projects the day's burn (BMR, active so far, and the
+// projected rest) and sets what is left to eat for maintenance and for the
+// goal deficit. The projection only applies while generatedAt is on the
+// briefing's date.
+func fillCalorieBudget(e *EnergyData, db *sql.DB, date, generatedAt string) error {
+	if now, err := time.Parse(time.RFC3339, generatedAt); err == nil && now.Format("2006-01-02") == date {
+		projected, err := queryProjectedActive(db, now)
+		if err != nil {
+			return err
+		}
+		if projected != nil {
+			e.ProjectedActiveKcal = *projected
+		}
+	}
+	burn := float64(e.BMRKcal) + e.ActiveKcal + e.ProjectedActiveKcal
+	e.RemainingKcalToMaintenance = int(math.Round(burn - e.ConsumedKcal))
+	e.RemainingKcalToGoalDeficit = e.RemainingKcalToMaintenance - settings.User.GoalDeficitKcal
+	return nil
+}
+
+// budgetValue formats what is left, e.g. "650 kcal to maintenance, 150 kcal to a 500 kcal deficit"
+func budgetValue(toMaintenance, toGoal int) string {
+	return fmt.Sprintf("%d kcal to maintenance, %d kcal to a %d kcal deficit", toMaintenance, toGoal, settings.User.GoalDeficitKcal)
+}
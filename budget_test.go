@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== CALORIE BUDGET TESTS ====================
+
+func TestRestOfDay(t *testing.T) {
+	var hours [24]float64
+	hours[17], hours[20], hours[9] = 200, 100, 500
+	clock := time.Date(2024, 1, 15, 17, 30, 0, 0, time.UTC)
+	if got := restOfDay(hours, clock); got != 200 {
+		t.Errorf("restOfDay() = %v, want 200 (half of 17:00 plus 20:00)", got)
+	}
+}
+
+func TestQueryProjectedActive(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('active_energy', '2024-01-08 09:00:00 +0700', 500, 'kcal', 'Apple Watch'),
+		('active_energy', '2024-01-08 17:10:00 +0700', 200, 'kcal', 'Apple Watch'),
+		('active_energy', '2024-01-08 17:20:00 +0700', 150, 'kcal', 'iPhone'),
+		('active_energy', '2024-01-08 20:00:00 +0700', 100, 'kcal', 'Apple Watch'),
+		('active_energy', '2024-01-01 19:00:00 +0700', 300, 'kcal', 'Apple Watch'),
+		('active_energy', '2024-01-14 19:00:00 +0700', 900, 'kcal', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mondays only; the iPhone's overlapping 17:00 hour is deduplicated
+	now, _ := time.Parse(time.RFC3339, "2024-01-15T17:30:00+07:00")
+	projected, err := queryProjectedActive(db, now)
+	if err != nil {
+		t.Fatalf("queryProjectedActive() error: %v", err)
+	}
+	if projected == nil || *projected != 250 {
+		t.Errorf("queryProjectedActive() = %v, want 250", projected)
+	}
+
+	e := EnergyData{BMRKcal: 1636, ActiveKcal: 400, ConsumedKcal: 1500}
+	if err := fillCalorieBudget(&e, db, "2024-01-15", "2024-01-15T17:30:00+07:00"); err != nil {
+		t.Fatalf("fillCalorieBudget() error: %v", err)
+	}
+	if e.ProjectedActiveKcal != 250 || e.RemainingKcalToMaintenance != 786 || e.RemainingKcalToGoalDeficit != 286 {
+		t.Errorf("budget = %+v, want 250 projected, 786 to maintenance, 286 to the deficit", e)
+	}
+
+	// A past day projects nothing more
+	e = EnergyData{BMRKcal: 1636, ActiveKcal: 400, ConsumedKcal: 1500}
+	if err := fillCalorieBudget(&e, db, "2024-01-15", "2024-01-16T08:00:00+07:00"); err != nil || e.ProjectedActiveKcal != 0 || e.RemainingKcalToMaintenance != 536 {
+		t.Errorf("budget for a past day = %+v, %v; want 536 to maintenance", e, err)
+	}
+}
+
+func TestCalorieBudgetRendering(t *testing.T) {
+	m := MiddayBriefing{TargetDate: "2024-01-15", Energy: MiddayEnergy{RemainingKcalToMaintenance: 786, RemainingKcalToGoalDeficit: 286}}
+	want := "786 kcal to maintenance, 286 kcal to a 500 kcal deficit"
+	if md := MiddayMarkdown(m); !strings.Contains(md, "Room left today: "+want+"\n") {
+		t.Errorf("midday markdown missing budget:\n%s", md)
+	}
+	if text := MiddayText(m, textStyle{}); !strings.Contains(text, "Left     "+want+"\n") {
+		t.Errorf("midday text missing budget:\n%s", text)
+	}
+	if vars := middayRuleVars(m); vars["energy.remaining_goal"] != 286.0 {
+		t.Errorf("energy.remaining_goal = %v, want 286", vars["energy.remaining_goal"])
+	}
+
+	e := EveningBriefing{TargetDate: "2024-01-15", Energy: EnergyData{RemainingKcalToMaintenance: 786, RemainingKcalToGoalDeficit: 286}}
+	if md := EveningMarkdown(e); !strings.Contains(md, "Room left today: "+want+"\n") {
+		t.Errorf("evening markdown missing budget:\n%s", md)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// briefingCache regenerates a briefing at most once per TTL
+type briefingCache[T any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	build   func(now time.Time) T
+	value   T
+	builtAt time.Time
+}
+
+func newBriefingCache[T any](ttl time.Duration, build func(now time.Time) T) *briefingCache[T] {
+	return &briefingCache[T]{ttl: ttl, build: build}
+}
+
+// Get returns the cached briefing, rebuilding it when older than the TTL
+func (c *briefingCache[T]) Get(now time.Time) T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.builtAt.IsZero() || now.Sub(c.builtAt) >= c.ttl {
+		c.value = c.build(now)
+		c.builtAt = now
+	}
+	return c.value
+}
+
+// Refresh rebuilds the briefing now regardless of its age
+func (c *briefingCache[T]) Refresh(now time.Time) T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.value = c.build(now)
+	c.builtAt = now
+	return c.value
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ==================== BRIEFING CACHE TESTS ====================
+
+func TestBriefingCacheTTL(t *testing.T) {
+	builds := 0
+	c := newBriefingCache(5*time.Minute, func(now time.Time) int {
+		builds++
+		return builds
+	})
+
+	start := time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC)
+	if v := c.Get(start); v != 1 {
+		t.Errorf("first Get() = %d, want 1", v)
+	}
+	if v := c.Get(start.Add(4 * time.Minute)); v != 1 {
+		t.Errorf("Get() within TTL = %d, want cached 1", v)
+	}
+	if v := c.Get(start.Add(5 * time.Minute)); v != 2 {
+		t.Errorf("Get() after TTL = %d, want rebuilt 2", v)
+	}
+	if builds != 2 {
+		t.Errorf("builds = %d, want 2", builds)
+	}
+}
+
+func TestBriefingCacheRefresh(t *testing.T) {
+	builds := 0
+	c := newBriefingCache(5*time.Minute, func(now time.Time) int {
+		builds++
+		return builds
+	})
+
+	start := time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC)
+	c.Get(start)
+	if v := c.Refresh(start.Add(time.Minute)); v != 2 {
+		t.Errorf("Refresh() = %d, want rebuilt 2", v)
+	}
+	// The refreshed value restarts the TTL
+	if v := c.Get(start.Add(5 * time.Minute)); v != 2 {
+		t.Errorf("Get() after Refresh() = %d, want cached 2", v)
+	}
+}
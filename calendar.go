@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// calendarTimeout bounds each Google API request
+const calendarTimeout = 15 * time.Second
+
+// calendarPageSize is the most events the Calendar API returns per page
+const calendarPageSize = 250
+
+// meetingHosts are the video call services whose links count as join URLs,
+// subdomains included (company.zoom.us, company.webex.com)
+var meetingHosts = []string{
+	"zoom.us", "meet.google.com", "teams.microsoft.com", "teams.live.com",
+	"webex.com", "whereby.com", "meet.jit.si", "chime.aws", "gotomeeting.com",
+}
+
+// urlPattern matches web links in free text, stopping at HTML delimiters
+var urlPattern = regexp.MustCompile(`https?://[^\s"'<>()]+`)
+
+// CalendarAttendee is someone else invited to an event
+type CalendarAttendee struct {
+	Email    string `json:"email"`
+	Name     string `json:"name,omitempty"`
+	Response string `json:"response,omitempty"` // accepted, tentative, declined, needsAction
+}
+
+// calendarClient reads one account's primary calendar from the Calendar API
+type calendarClient struct {
+	base  string
+	token string // OAuth access token
+	http  *http.Client
+}
+
+// calendarPage is one page of an events listing
+type calendarPage struct {
+	Items         []GogCalendarEvent `json:"items"`
+	NextPageToken string             `json:"nextPageToken"`
+}
+
+// newCalendarClient exchanges the account's refresh token for an access
+// token, returning nil when it has none and gog should be used
+func newCalendarClient(account CalendarAccount, cfg GoogleConfig) (*calendarClient, error) {
+	h := &http.Client{Timeout: calendarTimeout}
+	token, err := googleAccessToken(h, account, cfg)
+	if token == "" || err != nil {
+		return nil, err
+	}
+	return &calendarClient{base: strings.TrimRight(cfg.CalendarURL, "/"), token: token, http: h}, nil
+}
+
+// googleAccessToken exchanges the account's refresh token, from the config or
+// the keychain, for an access token; empty when it has none
+func googleAccessToken(h *http.Client, account CalendarAccount, cfg GoogleConfig) (string, error) {
+	refresh := account.RefreshToken
+	if refresh == "" && account.KeychainService != "" {
+		var err error
+		if refresh, err = keychainSecret(account.KeychainService); err != nil {
+			return "", fmt.Errorf("keychain lookup for %q failed: %w", account.KeychainService, err)
+		}
+	}
+	if refresh == "" {
+		return "", nil
+	}
+
+	resp, err := h.PostForm(cfg.TokenURL, url.Values{
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"refresh_token": {refresh},
+		"grant_type":    {"refresh_token"},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("google token refresh status %d", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("google token refresh: invalid response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("google token refresh: no access token")
+	}
+	return token.AccessToken, nil
+}
+
+// fetchCalendar reads the account's events through the Calendar API when it
+// has a refresh token and gog otherwise, falling back to gog when the API fails
+func fetchCalendar(account CalendarAccount, date string, days int, dump string) (calendarFetch, error) {
+	c, err := newCalendarClient(account, settings.Google)
+	if c != nil {
+		var events []GogCalendarEvent
+		if events, err = c.eventsOn(date, days); err == nil {
+			return calendarFetch{From: date, Days: days, Events: events}, nil
+		}
+	}
+	events, gogErr := gogEvents(account.Account, dump)
+	if gogErr == nil {
+		return calendarFetch{Events: events}, nil
+	}
+	if err != nil {
+		return calendarFetch{}, fmt.Errorf("%w (gog fallback: %v)", err, gogErr)
+	}
+	return calendarFetch{}, gogErr
+}
+
+// gogEvents runs the gog CLI and parses its event listing
+func gogEvents(account, dump string) ([]GogCalendarEvent, error) {
+	output, err := exec.Command("gog", "calendar", "events", "--account="+account, "--json").Output()
+	if err != nil {
+		return nil, err
+	}
+	dumpRaw(dump, output)
+
+	var resp GogCalendarResponse
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+	return resp.Events, nil
+}
+
+// eventsOn lists the primary calendar's events over days from date,
+// recurring ones expanded, following every page
+func (c *calendarClient) eventsOn(date string, days int) ([]GogCalendarEvent, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{
+		"timeMin":      {day.Format(time.RFC3339)},
+		"timeMax":      {day.AddDate(0, 0, days).Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"orderBy":      {"startTime"},
+		"maxResults":   {fmt.Sprint(calendarPageSize)},
+	}
+	var events []GogCalendarEvent
+	for {
+		var page calendarPage
+		if err := c.get("/calendars/primary/events?"+params.Encode(), &page); err != nil {
+			return nil, err
+		}
+		events = append(events, page.Items...)
+		if page.NextPageToken == "" {
+			return events, nil
+		}
+		params.Set("pageToken", page.NextPageToken)
+	}
+}
+
+func (c *calendarClient) get(path string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("calendar API status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("calendar API: invalid response: %w", err)
+	}
+	return nil
+}
+
+// calendarEvent converts an event starting at t, keeping its end, your RSVP,
+// the other attendees (rooms left out) and their count, the location, and the
+// video call link
+func calendarEvent(e GogCalendarEvent, t time.Time, source string) CalendarEvent {
+	event := CalendarEvent{
+		Time:     t.Format("15:04"),
+		Summary:  e.Summary,
+		Source:   source,
+		Location: e.Location,
+		JoinURL:  joinURL(e),
+	}
+	if event.Location == event.JoinURL {
+		event.Location = "" // a call link put in the location field
+	}
+	if end, err := localTime(e.End.DateTime); err == nil {
+		end = end.In(t.Location())
+		event.EndTime = end.Format("15:04")
+		if end.Format("2006-01-02") != t.Format("2006-01-02") {
+			event.EndTime = "24:00"
+		}
+	}
+	for _, a := range e.Attendees {
+		switch {
+		case a.Self:
+			event.Response = a.ResponseStatus
+		case !a.Resource:
+			event.Attendees = append(event.Attendees, CalendarAttendee{Email: a.Email, Name: a.DisplayName, Response: a.ResponseStatus})
+		}
+	}
+	event.AttendeeCount, event.OneOnOne = meetingSize(event)
+	return event
+}
+
+// ongoingFrom reports whether an event that started at start, before today,
+// is still running at today's midnight, returning that midnight and its last
+// day when that is after today
+func ongoingFrom(e GogCalendarEvent, start time.Time, today string) (time.Time, string, bool) {
+	day, err := time.ParseInLocation("2006-01-02", today, time.Local)
+	if err != nil || !start.Before(day) {
+		return time.Time{}, "", false
+	}
+	end, err := localTime(e.End.DateTime)
+	if err != nil || !end.After(day) {
+		return time.Time{}, "", false
+	}
+	until := ""
+	if last := end.Add(-time.Minute).Format("2006-01-02"); last > today {
+		until = last
+	}
+	return day, until, true
+}
+
+// joinURL finds an event's video call link: the conferencing data's video
+// entry, then the Meet link, then a meeting link in the location or description
+func joinURL(e GogCalendarEvent) string {
+	if e.ConferenceData != nil {
+		for _, p := range e.ConferenceData.EntryPoints {
+			if p.EntryPointType == "video" && p.URI != "" {
+				return p.URI
+			}
+		}
+	}
+	if e.HangoutLink != "" {
+		return e.HangoutLink
+	}
+	for _, text := range []string{e.Location, e.Description} {
+		for _, raw := range urlPattern.FindAllString(text, -1) {
+			u, err := url.Parse(raw)
+			if err != nil {
+				continue
+			}
+			host := strings.ToLower(u.Hostname())
+			for _, h := range meetingHosts {
+				if host == h || strings.HasSuffix(host, "."+h) {
+					return raw
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// ongoingDetail says how long an ongoing event runs: "ongoing until 17:00"
+// today, or "ongoing until Wed Jan 17"
+func ongoingDetail(e CalendarEvent) string {
+	if t, err := time.Parse("2006-01-02", e.Until); err == nil {
+		return "ongoing until " + t.Format("Mon Jan 2")
+	}
+	if e.EndTime != "" && e.EndTime != "24:00" {
+		return "ongoing until " + e.EndTime
+	}
+	return "ongoing"
+}
+
+// eventSummary is the summary with what the event needs from you, e.g.
+// "Design review (Room 4, leave by 09:20, tentative)"
+func eventSummary(e CalendarEvent) string {
+	var details []string
+	if e.Location != "" {
+		details = append(details, e.Location)
+	}
+	if e.JoinURL != "" {
+		details = append(details, "video call")
+	}
+	if d := meetingDetail(e); d != "" {
+		details = append(details, d)
+	}
+	if e.LeaveBy != "" {
+		details = append(details, "leave by "+e.LeaveBy)
+	}
+	if e.Ongoing {
+		details = append(details, ongoingDetail(e))
+	}
+	switch e.Response {
+	case "tentative", "declined":
+		details = append(details, e.Response)
+	case "needsAction":
+		details = append(details, "not answered")
+	}
+	if len(details) == 0 {
+		return e.Summary
+	}
+	return e.Summary + " (" + strings.Join(details, ", ") + ")"
+}
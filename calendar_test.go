@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== CALENDAR CLIENT TESTS ====================
+
+// fakeGoogleAPI serves a token refresh for refresh token "r" and two pages
+// of events from 2024-01-15 to 19 for access token "a"
+func fakeGoogleAPI(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			if r.PostForm.Get("refresh_token") != "r" || r.PostForm.Get("client_id") != "id" || r.PostForm.Get("grant_type") != "refresh_token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token": "a", "expires_in": 3599}`))
+		case "/calendars/primary/events":
+			if r.Header.Get("Authorization") != "Bearer a" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			q := r.URL.Query()
+			if q.Get("singleEvents") != "true" || !strings.HasPrefix(q.Get("timeMin"), "2024-01-15T00:00:00") || !strings.HasPrefix(q.Get("timeMax"), "2024-01-19T00:00:00") {
+				t.Errorf("events query = %s", r.URL.RawQuery)
+			}
+			if q.Get("pageToken") == "" {
+				w.Write([]byte(`{"items": [{"summary": "Standup", "start": {"dateTime": "2024-01-15T09:00:00Z"}}], "nextPageToken": "p2"}`))
+				return
+			}
+			w.Write([]byte(`{"items": [{"summary": "Review", "start": {"dateTime": "2024-01-15T14:00:00Z"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	resetCalendarCache()
+	t.Cleanup(resetCalendarCache)
+	return ts
+}
+
+// fakeGog puts a gog on PATH that prints output
+func fakeGog(t *testing.T, output string) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s' '" + output + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, "gog"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	resetCalendarCache()
+	t.Cleanup(resetCalendarCache)
+}
+
+func TestCalendarEventsAPI(t *testing.T) {
+	ts := fakeGoogleAPI(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Google = GoogleConfig{ClientID: "id", ClientSecret: "s", TokenURL: ts.URL + "/token", CalendarURL: ts.URL + "/"}
+
+	events, err := calendarEvents(CalendarAccount{Account: "me@example.com", Source: "work", RefreshToken: "r"}, "2024-01-15", 4, "calendar-work")
+	if err != nil {
+		t.Fatalf("calendarEvents() error: %v", err)
+	}
+	if len(events) != 2 || events[0].Summary != "Standup" || events[1].Summary != "Review" {
+		t.Errorf("calendarEvents() = %+v, want both pages", events)
+	}
+}
+
+func TestCalendarEventsFallsBackToGog(t *testing.T) {
+	ts := fakeGoogleAPI(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Google = GoogleConfig{ClientID: "id", ClientSecret: "s", TokenURL: ts.URL + "/token", CalendarURL: ts.URL}
+	fakeGog(t, `{"events": [{"summary": "Gym", "start": {"dateTime": "2024-01-15T07:00:00Z"}}]}`)
+
+	// Without a token gog is used directly, and a rejected token falls back to it
+	for _, account := range []CalendarAccount{{Account: "me@example.com"}, {Account: "me@example.com", RefreshToken: "expired"}} {
+		resetCalendarCache()
+		events, err := calendarEvents(account, "2024-01-15", 1, "calendar-personal")
+		if err != nil || len(events) != 1 || events[0].Summary != "Gym" {
+			t.Errorf("calendarEvents(%+v) = %+v, %v", account, events, err)
+		}
+	}
+
+	// With both down, the error names both
+	t.Setenv("PATH", t.TempDir())
+	resetCalendarCache()
+	_, err := calendarEvents(CalendarAccount{Account: "me@example.com", RefreshToken: "expired"}, "2024-01-15", 1, "calendar-personal")
+	if err == nil || !strings.Contains(err.Error(), "google token refresh status 400") || !strings.Contains(err.Error(), "gog fallback") {
+		t.Errorf("calendarEvents() with both failing error = %v", err)
+	}
+}
+
+func TestNewCalendarClientWithoutToken(t *testing.T) {
+	c, err := newCalendarClient(CalendarAccount{Account: "me@example.com"}, DefaultConfig().Google)
+	if c != nil || err != nil {
+		t.Errorf("newCalendarClient() without a token = %v, %v, want gog", c, err)
+	}
+}
+
+func TestCalendarEvent(t *testing.T) {
+	var e GogCalendarEvent
+	data := `{
+		"summary": "Design review",
+		"end": {"dateTime": "2024-01-15T12:15:00+01:00"},
+		"location": "Room 4",
+		"attendees": [
+			{"email": "me@example.com", "self": true, "responseStatus": "tentative"},
+			{"email": "ana@example.com", "displayName": "Ana", "responseStatus": "accepted"},
+			{"email": "room4@resource.calendar.google.com", "resource": true, "responseStatus": "accepted"}
+		],
+		"hangoutLink": "https://meet.google.com/old",
+		"conferenceData": {"entryPoints": [
+			{"entryPointType": "phone", "uri": "tel:+1-555-0100"},
+			{"entryPointType": "video", "uri": "https://zoom.us/j/1"}
+		]}
+	}`
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	got := calendarEvent(e, at, "work")
+	want := CalendarEvent{
+		Time:          "10:30",
+		EndTime:       "11:15",
+		Summary:       "Design review",
+		Source:        "work",
+		Location:      "Room 4",
+		Response:      "tentative",
+		Attendees:     []CalendarAttendee{{Email: "ana@example.com", Name: "Ana", Response: "accepted"}},
+		AttendeeCount: 2,
+		OneOnOne:      true,
+		JoinURL:       "https://zoom.us/j/1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("calendarEvent() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCalendarEventPastMidnight(t *testing.T) {
+	var e GogCalendarEvent
+	e.End.DateTime = "2024-01-16T01:00:00Z"
+	if got := calendarEvent(e, time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC), "").EndTime; got != "24:00" {
+		t.Errorf("EndTime = %q, want 24:00", got)
+	}
+}
+
+func TestEventSummary(t *testing.T) {
+	tests := []struct {
+		event    CalendarEvent
+		expected string
+	}{
+		{CalendarEvent{Summary: "Focus"}, "Focus"},
+		{CalendarEvent{Summary: "Focus", Response: "accepted"}, "Focus"},
+		{CalendarEvent{Summary: "Lunch", Location: "Cafe"}, "Lunch (Cafe)"},
+		{CalendarEvent{Summary: "Sync", JoinURL: "https://meet.google.com/x", Response: "needsAction"}, "Sync (video call, not answered)"},
+		{CalendarEvent{Summary: "Review", Location: "Room 4", JoinURL: "https://zoom.us/j/1", Response: "declined"}, "Review (Room 4, video call, declined)"},
+		{CalendarEvent{Summary: "Offsite", Ongoing: true, EndTime: "12:00"}, "Offsite (ongoing until 12:00)"},
+		{CalendarEvent{Summary: "Conference", Ongoing: true, EndTime: "24:00", Until: "2024-01-17"}, "Conference (ongoing until Wed Jan 17)"},
+	}
+	for _, tt := range tests {
+		if got := eventSummary(tt.event); got != tt.expected {
+			t.Errorf("eventSummary(%+v) = %q, want %q", tt.event, got, tt.expected)
+		}
+	}
+}
+
+func TestOngoingFrom(t *testing.T) {
+	inZone(t, "UTC")
+	start := time.Date(2024, 1, 14, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		end   string
+		ok    bool
+		until string
+	}{
+		{"ends today", "2024-01-15T12:00:00Z", true, ""},
+		{"ends at midnight tonight", "2024-01-16T00:00:00Z", true, ""},
+		{"runs past today", "2024-01-17T18:00:00Z", true, "2024-01-17"},
+		{"ended at midnight", "2024-01-15T00:00:00Z", false, ""},
+		{"ended yesterday", "2024-01-14T17:00:00Z", false, ""},
+		{"no end", "", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e GogCalendarEvent
+			e.End.DateTime = tt.end
+			day, until, ok := ongoingFrom(e, start, "2024-01-15")
+			if ok != tt.ok || until != tt.until {
+				t.Errorf("ongoingFrom() = %v, %q, %v, want %q, %v", day, until, ok, tt.until, tt.ok)
+			}
+			if ok && day.Format(time.RFC3339) != "2024-01-15T00:00:00Z" {
+				t.Errorf("ongoingFrom() start = %v, want today's midnight", day)
+			}
+		})
+	}
+}
+
+func TestMorningOngoingEvents(t *testing.T) {
+	inZone(t, "UTC")
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Calendars = []CalendarAccount{{Account: "me@example.com", Source: "work"}}
+	fakeGog(t, `{"events": [
+		{"summary": "Conference", "location": "Expo Hall", "start": {"dateTime": "2024-01-14T09:00:00Z"}, "end": {"dateTime": "2024-01-17T17:00:00Z"}},
+		{"summary": "Night shift", "start": {"dateTime": "2024-01-14T22:00:00Z"}, "end": {"dateTime": "2024-01-15T06:00:00Z"}},
+		{"summary": "Last week", "start": {"dateTime": "2024-01-08T09:00:00Z"}, "end": {"dateTime": "2024-01-09T09:00:00Z"}},
+		{"summary": "Standup", "start": {"dateTime": "2024-01-15T09:00:00Z"}, "end": {"dateTime": "2024-01-15T09:30:00Z"}}
+	]}`)
+
+	b := &MorningBriefing{TargetDate: "2024-01-15"}
+	getCalendarData(b, "2024-01-15")
+	if len(b.Calendar.MorningEvents) != 3 {
+		t.Fatalf("morning events = %+v, errors %v", b.Calendar.MorningEvents, b.Errors)
+	}
+	conf := b.Calendar.MorningEvents[0]
+	if !conf.Ongoing || conf.Time != "00:00" || conf.Until != "2024-01-17" {
+		t.Errorf("conference = %+v, want ongoing from midnight until the 17th", conf)
+	}
+	if shift := b.Calendar.MorningEvents[1]; !shift.Ongoing || shift.EndTime != "06:00" || shift.Until != "" {
+		t.Errorf("night shift = %+v, want ongoing until 06:00", shift)
+	}
+	if b.Calendar.MorningCount != 1 || b.Calendar.FirstEventTime != "09:00" {
+		t.Errorf("count = %d, first = %q, want only the standup", b.Calendar.MorningCount, b.Calendar.FirstEventTime)
+	}
+	if len(b.Calendar.Conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none with ongoing events", b.Calendar.Conflicts)
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"none", `{"summary": "Lunch", "location": "Cafe", "description": "See https://example.com/menu"}`, ""},
+		{"conference data", `{"hangoutLink": "https://meet.google.com/abc", "conferenceData": {"entryPoints": [{"entryPointType": "video", "uri": "https://zoom.us/j/1"}]}}`, "https://zoom.us/j/1"},
+		{"meet link", `{"hangoutLink": "https://meet.google.com/abc", "conferenceData": {"entryPoints": [{"entryPointType": "phone", "uri": "tel:+1"}]}}`, "https://meet.google.com/abc"},
+		{"location", `{"location": "https://acme.zoom.us/j/123?pwd=x"}`, "https://acme.zoom.us/j/123?pwd=x"},
+		{"html description", `{"description": "Agenda: <a href=\"https://docs.example.com/x\">doc</a><br>Join: <a href=\"https://teams.microsoft.com/l/meetup-join/19%3a\">here</a>"}`, "https://teams.microsoft.com/l/meetup-join/19%3a"},
+		{"lookalike host", `{"description": "https://notzoom.us/j/1 https://zoom.us.evil.example/j/1"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e GogCalendarEvent
+			if err := json.Unmarshal([]byte(tt.data), &e); err != nil {
+				t.Fatal(err)
+			}
+			if got := joinURL(e); got != tt.expected {
+				t.Errorf("joinURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalendarEventLinkInLocation(t *testing.T) {
+	var e GogCalendarEvent
+	e.Location = "https://meet.google.com/abc"
+	got := calendarEvent(e, time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC), "work")
+	if got.Location != "" || got.JoinURL != "https://meet.google.com/abc" {
+		t.Errorf("calendarEvent() location = %q, join_url = %q, want the link moved to join_url", got.Location, got.JoinURL)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// calendarRunTTL is how long an account's events are reused in-process: long
+// enough to cover one run, short enough that the server and daemon see changes
+const calendarRunTTL = time.Minute
+
+// calendarFetch is one account's events over days from From; gog's listing,
+// which isn't limited to a window, has no From
+type calendarFetch struct {
+	From      string             `json:"from,omitempty"`
+	Days      int                `json:"days,omitempty"`
+	Events    []GogCalendarEvent `json:"events"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// calendarCache holds each account's latest fetch for the run
+var calendarCache = struct {
+	sync.Mutex
+	fetches map[string]calendarFetch
+}{fetches: map[string]calendarFetch{}}
+
+// resetCalendarCache forgets every in-process fetch
+func resetCalendarCache() {
+	calendarCache.Lock()
+	defer calendarCache.Unlock()
+	calendarCache.fetches = map[string]calendarFetch{}
+}
+
+// validateCalendarCache checks the on-disk TTL
+func validateCalendarCache(cfg CalendarCacheConfig) error {
+	if cfg.TTLMinutes < 0 {
+		return errors.New("calendar_cache.ttl_minutes must not be negative")
+	}
+	return nil
+}
+
+// covers reports whether the fetch, taken at most ttl before now, holds the
+// events over days from date
+func (f calendarFetch) covers(date string, days int, now time.Time, ttl time.Duration) bool {
+	if now.Sub(f.FetchedAt) >= ttl {
+		return false
+	}
+	return f.From == "" || f.From <= date && addDays(date, days) <= addDays(f.From, f.Days)
+}
+
+// calendarEvents lists the account's events over days starting at date (local
+// time), fetching each account once per run and, with calendar_cache.ttl_minutes
+// set, once per TTL across runs. A reused fetch may span more days, so callers
+// filter by date. The lock is held across the fetch so concurrent callers wait
+// for it rather than repeat it.
+func calendarEvents(account CalendarAccount, date string, days int, dump string) ([]GogCalendarEvent, error) {
+	calendarCache.Lock()
+	defer calendarCache.Unlock()
+
+	now := time.Now()
+	if f, ok := calendarCache.fetches[account.Account]; ok && f.covers(date, days, now, calendarRunTTL) {
+		return f.Events, nil
+	}
+	ttl := time.Duration(settings.CalendarCache.TTLMinutes) * time.Minute
+	if ttl > 0 {
+		if f, ok := readCalendarCache(account.Account); ok && f.covers(date, days, now, ttl) {
+			calendarCache.fetches[account.Account] = f
+			return f.Events, nil
+		}
+	}
+
+	f, err := fetchCalendar(account, date, days, dump)
+	if err != nil {
+		return nil, err
+	}
+	f.FetchedAt = now
+	calendarCache.fetches[account.Account] = f
+	if ttl > 0 {
+		writeCalendarCache(account.Account, f)
+	}
+	return f.Events, nil
+}
+
+// calendarCachePath is where an account's fetch is kept, named by a hash so
+// the address doesn't appear on disk
+func calendarCachePath(account string) string {
+	sum := sha256.Sum256([]byte(account))
+	return filepath.Join(getDataDir(), "calendar-cache", hex.EncodeToString(sum[:8])+".json")
+}
+
+// readCalendarCache loads an account's fetch from disk; a missing or
+// unreadable file is a miss
+func readCalendarCache(account string) (calendarFetch, bool) {
+	data, err := os.ReadFile(calendarCachePath(account))
+	if err != nil {
+		return calendarFetch{}, false
+	}
+	if data, err = openAtRest(data); err != nil {
+		return calendarFetch{}, false
+	}
+	var f calendarFetch
+	if err := json.Unmarshal(data, &f); err != nil {
+		return calendarFetch{}, false
+	}
+	return f, true
+}
+
+// writeCalendarCache saves an account's fetch, encrypted when encryption is
+// on; failures only cost a refetch next time
+func writeCalendarCache(account string, f calendarFetch) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return
+	}
+	path := calendarCachePath(account)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	os.WriteFile(path, sealAtRest(data), 0o600)
+}
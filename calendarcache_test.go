@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== CALENDAR CACHE TESTS ====================
+
+// countingGog puts a gog on PATH that prints output and records each call in
+// the returned file
+func countingGog(t *testing.T, output string) string {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho x >> '" + calls + "'\nprintf '%s' '" + output + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, "gog"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	resetCalendarCache()
+	t.Cleanup(resetCalendarCache)
+	return calls
+}
+
+func gogCalls(t *testing.T, calls string) int {
+	data, err := os.ReadFile(calls)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "x")
+}
+
+func TestCalendarFetchCovers(t *testing.T) {
+	now := time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC)
+	window := calendarFetch{From: "2024-01-10", Days: 9, FetchedAt: now} // through the 18th
+	tests := []struct {
+		name  string
+		fetch calendarFetch
+		date  string
+		days  int
+		at    time.Time
+		want  bool
+	}{
+		{"inside", window, "2024-01-16", 1, now, true},
+		{"whole window", window, "2024-01-10", 9, now, true},
+		{"runs past the end", window, "2024-01-18", 2, now, false},
+		{"starts before", window, "2024-01-09", 2, now, false},
+		{"expired", window, "2024-01-16", 1, now.Add(calendarRunTTL), false},
+		{"gog listing", calendarFetch{FetchedAt: now}, "2024-02-01", 1, now, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fetch.covers(tt.date, tt.days, tt.at, calendarRunTTL); got != tt.want {
+				t.Errorf("covers(%s, %d) = %v, want %v", tt.date, tt.days, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarEventsFetchedOncePerRun(t *testing.T) {
+	calls := countingGog(t, `{"events": [{"summary": "Gym", "start": {"dateTime": "2024-01-16T07:00:00Z"}}]}`)
+	work := CalendarAccount{Account: "work@example.com", Source: "work"}
+
+	// The morning's window and the evening's look at tomorrow share one fetch
+	for _, fetch := range []struct {
+		date string
+		days int
+	}{{"2024-01-10", 9}, {"2024-01-16", 1}} {
+		events, err := calendarEvents(work, fetch.date, fetch.days, "calendar-work")
+		if err != nil || len(events) != 1 {
+			t.Fatalf("calendarEvents() = %+v, %v", events, err)
+		}
+	}
+	if n := gogCalls(t, calls); n != 1 {
+		t.Errorf("gog ran %d times, want once", n)
+	}
+
+	// Each account is fetched separately
+	if _, err := calendarEvents(CalendarAccount{Account: "me@example.com"}, "2024-01-16", 1, "calendar-personal"); err != nil {
+		t.Fatal(err)
+	}
+	if n := gogCalls(t, calls); n != 2 {
+		t.Errorf("gog ran %d times, want once per account", n)
+	}
+}
+
+func TestCalendarEventsErrorsNotCached(t *testing.T) {
+	countingGog(t, `not json`)
+	account := CalendarAccount{Account: "me@example.com"}
+	if _, err := calendarEvents(account, "2024-01-15", 1, ""); err == nil {
+		t.Fatal("calendarEvents() with bad output should fail")
+	}
+	calendarCache.Lock()
+	_, cached := calendarCache.fetches[account.Account]
+	calendarCache.Unlock()
+	if cached {
+		t.Error("a failed fetch was cached")
+	}
+}
+
+func TestCalendarCacheOnDisk(t *testing.T) {
+	t.Setenv("BRIEFING_DATA_DIR", t.TempDir())
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.CalendarCache.TTLMinutes = 30
+	calls := countingGog(t, `{"events": [{"summary": "Gym", "start": {"dateTime": "2024-01-15T07:00:00Z"}}]}`)
+	account := CalendarAccount{Account: "me@example.com"}
+
+	if _, err := calendarEvents(account, "2024-01-15", 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(calendarCachePath(account.Account)); err != nil || strings.Contains(string(data), "me@example.com") {
+		t.Fatalf("cache file = %s, %v", data, err)
+	}
+
+	// A later run within the TTL reads the file instead of running gog
+	resetCalendarCache()
+	events, err := calendarEvents(account, "2024-01-15", 1, "")
+	if err != nil || len(events) != 1 || events[0].Summary != "Gym" {
+		t.Errorf("calendarEvents() from disk = %+v, %v", events, err)
+	}
+	if n := gogCalls(t, calls); n != 1 {
+		t.Errorf("gog ran %d times, want once", n)
+	}
+
+	// Past the TTL it fetches again
+	f, _ := readCalendarCache(account.Account)
+	f.FetchedAt = time.Now().Add(-time.Hour)
+	writeCalendarCache(account.Account, f)
+	resetCalendarCache()
+	if _, err := calendarEvents(account, "2024-01-15", 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := gogCalls(t, calls); n != 2 {
+		t.Errorf("gog ran %d times, want a refetch after the TTL", n)
+	}
+}
+
+func TestValidateCalendarCache(t *testing.T) {
+	if err := validateCalendarCache(DefaultConfig().CalendarCache); err != nil {
+		t.Errorf("default calendar_cache: %v", err)
+	}
+	if err := validateCalendarCache(CalendarCacheConfig{TTLMinutes: -1}); err == nil {
+		t.Error("negative ttl_minutes should be rejected")
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+	"math"
+)
+
+// line draws a straight line of the given thickness (Bresenham)
+func (c *monoCanvas) line(x0, y0, x1, y1, thickness int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.fillRect(x0-thickness/2, y0-thickness/2, thickness, thickness)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// sparkline plots values (NaN for days without data) across a w x h box,
+// scaled to the series' own min and max
+func (c *monoCanvas) sparkline(x, y, w, h, thickness int, values []float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if math.IsInf(lo, 1) {
+		return
+	}
+
+	step := 0.0
+	if len(values) > 1 {
+		step = float64(w-thickness) / float64(len(values)-1)
+	}
+	point := func(i int, v float64) (int, int) {
+		frac := 0.5 // flat series sit mid-height
+		if hi > lo {
+			frac = (v - lo) / (hi - lo)
+		}
+		return x + thickness/2 + int(float64(i)*step), y + h - thickness/2 - 1 - int(frac*float64(h-thickness))
+	}
+
+	prev := -1
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		px, py := point(i, v)
+		if prev >= 0 {
+			qx, qy := point(prev, values[prev])
+			c.line(qx, qy, px, py, thickness)
+		}
+		c.fillRect(px-thickness, py-thickness, 2*thickness+1, 2*thickness+1)
+		prev = i
+	}
+}
+
+// ring draws a progress ring centered on (cx, cy), filled clockwise from
+// 12 o'clock to fraction (clamped to 0-1), with the unfilled part outlined
+func (c *monoCanvas) ring(cx, cy, radius, thickness int, fraction float64) {
+	fraction = math.Max(0, math.Min(1, fraction))
+	inner := float64(radius - thickness)
+	for py := cy - radius; py <= cy+radius; py++ {
+		for px := cx - radius; px <= cx+radius; px++ {
+			dx, dy := float64(px-cx), float64(py-cy)
+			d := math.Hypot(dx, dy)
+			if d > float64(radius) || d < inner {
+				continue
+			}
+			angle := math.Atan2(dx, -dy)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			onEdge := d > float64(radius)-1 || d < inner+1
+			if angle/(2*math.Pi) < fraction || onEdge {
+				c.img.SetColorIndex(px, py, 1)
+			}
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// weekSeries spreads daily values over the report's seven days, NaN where missing
+func weekSeries(weekStart string, values []dailyValue) []float64 {
+	series := make([]float64, 7)
+	for i := range series {
+		series[i] = math.NaN()
+	}
+	byDate := map[string]float64{}
+	for _, v := range values {
+		byDate[v.Date] = v.Value
+	}
+	for i := range series {
+		if v, ok := byDate[addDays(weekStart, i)]; ok {
+			series[i] = v
+		}
+	}
+	return series
+}
+
+// RenderWeeklyCardPNG draws the weekly review as a shareable monochrome card:
+// sleep, HRV, and weight sparklines on the left, workouts and med adherence on the right
+func RenderWeeklyCardPNG(w io.Writer, r WeeklyReport, width, height int) error {
+	c := newMonoCanvas(width, height)
+
+	titleScale := max(1, height/160)
+	bodyScale := max(1, height/240)
+	lineHeight := (glyphHeight + 4) * bodyScale
+	margin := max(8, width/40)
+	leftWidth := (width - 3*margin) * 3 / 5
+	rightWidth := width - 3*margin - leftWidth
+
+	// Header
+	y := margin
+	c.text(margin, y, titleScale, "MY WEEK")
+	dates := r.WeekStart + " - " + r.WeekEnd
+	c.text(width-margin-textWidth(dates, bodyScale), y+(titleScale-bodyScale)*glyphHeight, bodyScale, dates)
+	y += (glyphHeight + 3) * titleScale
+	c.fillRect(margin, y, width-2*margin, max(1, bodyScale))
+	y += lineHeight
+	top := y
+
+	// Left column: one labelled sparkline per trend
+	charts := []struct {
+		label  string
+		format string
+		values []dailyValue
+	}{
+		{"SLEEP", "%.1fH", r.Trends.SleepHours},
+		{"HRV", "%.0fMS", r.Trends.HRV},
+		{"WEIGHT", "%.1fKG", r.Trends.WeightKg},
+	}
+	rowHeight := (height - top - margin) / len(charts)
+	chartHeight := rowHeight - lineHeight - lineHeight/2
+	for i, ch := range charts {
+		rowTop := top + i*rowHeight
+		label := ch.label + " NO DATA"
+		if len(ch.values) > 0 {
+			sum := 0.0
+			for _, v := range ch.values {
+				sum += v.Value
+			}
+			label = ch.label + " AVG " + fmt.Sprintf(ch.format, sum/float64(len(ch.values)))
+		}
+		c.text(margin, rowTop, bodyScale, label)
+		if chartHeight > 4 {
+			c.sparkline(margin, rowTop+lineHeight, leftWidth, chartHeight, max(1, bodyScale), weekSeries(r.WeekStart, ch.values))
+		}
+	}
+
+	// Right column: workout count and adherence ring
+	x := 2*margin + leftWidth
+	y = top
+	c.text(x, y, bodyScale, "WORKOUTS")
+	y += lineHeight
+	workouts := "-"
+	if r.Workouts != nil {
+		workouts = fmt.Sprintf("%d", *r.Workouts)
+	}
+	bigScale := titleScale * 2
+	c.text(x, y, bigScale, workouts)
+	y += (glyphHeight + 3) * bigScale
+
+	c.text(x, y, bodyScale, "MEDS TAKEN")
+	y += lineHeight
+	radius := min(rightWidth, height-margin-y) / 2
+	if radius > 4 {
+		cx, cy := x+rightWidth/2, y+radius
+		label := "-"
+		if r.Adherence != nil {
+			c.ring(cx, cy, radius, max(2, radius/4), r.Adherence.Percent/100)
+			label = fmt.Sprintf("%.0f%%", r.Adherence.Percent)
+		} else {
+			c.ring(cx, cy, radius, max(2, radius/4), 0)
+		}
+		c.text(cx-textWidth(label, bodyScale)/2, cy-glyphHeight*bodyScale/2, bodyScale, label)
+	}
+
+	return png.Encode(w, c.img)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// ==================== WEEKLY CARD TESTS ====================
+
+// blackPixels counts set pixels in a rectangle of the canvas
+func blackPixels(c *monoCanvas, r image.Rectangle) int {
+	n := 0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if c.img.ColorIndexAt(x, y) == 1 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestLine(t *testing.T) {
+	c := newMonoCanvas(20, 20)
+	c.line(2, 3, 12, 3, 1)
+	if got := blackPixels(c, c.img.Bounds()); got != 11 {
+		t.Errorf("horizontal line pixels = %d, want 11", got)
+	}
+
+	c = newMonoCanvas(20, 20)
+	c.line(15, 15, 0, 0, 1)
+	for i := 0; i <= 15; i++ {
+		if c.img.ColorIndexAt(i, i) != 1 {
+			t.Errorf("diagonal missing pixel (%d,%d)", i, i)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	c := newMonoCanvas(100, 40)
+	c.sparkline(0, 0, 100, 40, 1, []float64{1, math.NaN(), 3})
+
+	// Lowest value sits at the bottom-left, highest at the top-right
+	if c.img.ColorIndexAt(0, 39) != 1 {
+		t.Error("expected the first (lowest) point at the bottom-left")
+	}
+	if c.img.ColorIndexAt(99, 0) != 1 {
+		t.Error("expected the last (highest) point at the top-right")
+	}
+
+	// A series with no data draws nothing
+	c = newMonoCanvas(100, 40)
+	c.sparkline(0, 0, 100, 40, 1, []float64{math.NaN(), math.NaN()})
+	if got := blackPixels(c, c.img.Bounds()); got != 0 {
+		t.Errorf("empty sparkline drew %d pixels", got)
+	}
+}
+
+func TestRing(t *testing.T) {
+	fill := func(fraction float64) (left, right int) {
+		c := newMonoCanvas(101, 101)
+		c.ring(50, 50, 40, 10, fraction)
+		return blackPixels(c, image.Rect(0, 0, 50, 101)), blackPixels(c, image.Rect(51, 0, 101, 101))
+	}
+
+	emptyLeft, emptyRight := fill(0)
+	halfLeft, halfRight := fill(0.5)
+	fullLeft, fullRight := fill(1)
+
+	// Filling runs clockwise from 12 o'clock, so half covers the right side only
+	if halfRight <= emptyRight*2 {
+		t.Errorf("half ring right side = %d pixels, want well above outline-only %d", halfRight, emptyRight)
+	}
+	if halfLeft != emptyLeft {
+		t.Errorf("half ring left side = %d pixels, want outline-only %d", halfLeft, emptyLeft)
+	}
+	if fullLeft != halfRight || fullRight != halfRight {
+		t.Errorf("full ring = %d/%d pixels, want %d on both sides", fullLeft, fullRight, halfRight)
+	}
+}
+
+func TestWeekSeries(t *testing.T) {
+	got := weekSeries("2024-01-09", []dailyValue{{"2024-01-09", 7}, {"2024-01-12", 6.5}, {"2024-01-20", 9}})
+	if len(got) != 7 {
+		t.Fatalf("len = %d, want 7", len(got))
+	}
+	if got[0] != 7 || got[3] != 6.5 {
+		t.Errorf("series = %v, want 7 at day 0 and 6.5 at day 3", got)
+	}
+	for _, i := range []int{1, 2, 4, 5, 6} {
+		if !math.IsNaN(got[i]) {
+			t.Errorf("series[%d] = %v, want NaN", i, got[i])
+		}
+	}
+}
+
+func TestRenderWeeklyCardPNG(t *testing.T) {
+	workouts := 4
+	r := WeeklyReport{
+		WeekStart: "2024-01-09",
+		WeekEnd:   "2024-01-15",
+		Trends: WeeklyTrends{
+			SleepHours: []dailyValue{{"2024-01-09", 7.2}, {"2024-01-10", 6.1}, {"2024-01-11", 7.8}},
+			HRV:        []dailyValue{{"2024-01-09", 42}, {"2024-01-15", 51}},
+		},
+		Workouts:  &workouts,
+		Adherence: &MedAdherence{Days: 7, Taken: 13, Missed: 1, Percent: 92.9},
+	}
+
+	for _, size := range []image.Point{{800, 480}, {1080, 1080}, {200, 120}} {
+		var buf bytes.Buffer
+		if err := RenderWeeklyCardPNG(&buf, r, size.X, size.Y); err != nil {
+			t.Fatalf("RenderWeeklyCardPNG(%v) error: %v", size, err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("Failed to decode PNG: %v", err)
+		}
+		if img.Bounds().Size() != size {
+			t.Errorf("Bounds = %v, want %v", img.Bounds(), size)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// VO2max trend settings
+const (
+	VO2MaxTrendDays    = 90  // look-back window for the trend
+	VO2MaxTrendMinDays = 30  // the oldest reading must be at least this old
+	VO2MaxTrendDelta   = 1.0 // mL/kg/min change that counts as RISING/FALLING
+)
+
+// Heart-rate zone settings
+const (
+	HRZoneDays         = 7 // zone minutes cover the last week
+	HRSampleGapMinutes = 5 // longest gap one heart_rate sample is counted for
+)
+
+// hrZoneFloors are the lower bounds of Z1-Z5 as a share of max HR
+var hrZoneFloors = [5]float64{0.5, 0.6, 0.7, 0.8, 0.9}
+
+// HRZones is the week's workout minutes in each heart-rate zone
+type HRZones struct {
+	Z1       float64 `json:"z1_min"` // 50-60% of max HR
+	Z2       float64 `json:"z2_min"` // 60-70%
+	Z3       float64 `json:"z3_min"` // 70-80%
+	Z4       float64 `json:"z4_min"` // 80-90%
+	Z5       float64 `json:"z5_min"` // 90%+
+	Workouts int     `json:"workouts"`
+	MaxHR    int     `json:"max_hr"`
+}
+
+// workoutWindow is one Apple workout's start and end
+type workoutWindow struct {
+	Start, End string
+}
+
+// hrSample is one heart_rate reading inside a workout
+type hrSample struct {
+	At  time.Time
+	BPM float64
+}
+
+// VO2MaxData is the latest cardio fitness estimate and how it has moved
+type VO2MaxData struct {
+	Value  float64  `json:"value"` // mL/kg/min
+	Date   string   `json:"date"`
+	Change *float64 `json:"change_90d,omitempty"` // latest minus the oldest reading in the window
+	Trend  string   `json:"trend,omitempty"`      // RISING, STABLE, FALLING
+}
+
+// vo2MaxFor takes the latest reading and compares it with the oldest in the
+// window; the trend needs VO2MaxTrendMinDays between them. Nil without readings.
+func vo2MaxFor(values []dailyValue) *VO2MaxData {
+	if len(values) == 0 {
+		return nil
+	}
+	latest := values[len(values)-1]
+	v := &VO2MaxData{Value: math.Round(latest.Value*10) / 10, Date: latest.Date}
+	oldest := values[0]
+	if oldest.Date > addDays(latest.Date, -VO2MaxTrendMinDays) {
+		return v
+	}
+	change := math.Round((latest.Value-oldest.Value)*10) / 10
+	v.Change = &change
+	switch {
+	case change >= VO2MaxTrendDelta:
+		v.Trend = "RISING"
+	case change <= -VO2MaxTrendDelta:
+		v.Trend = "FALLING"
+	default:
+		v.Trend = "STABLE"
+	}
+	return v
+}
+
+// vo2MaxNote is appended to the recommendation when cardio fitness has moved
+func vo2MaxNote(v *VO2MaxData) string {
+	if v == nil || v.Change == nil {
+		return ""
+	}
+	switch v.Trend {
+	case "RISING":
+		return fmt.Sprintf(" VO2max is up %.1f over 90 days, cardio is paying off.", *v.Change)
+	case "FALLING":
+		return fmt.Sprintf(" VO2max is down %.1f over 90 days, worth adding some cardio.", -*v.Change)
+	}
+	return ""
+}
+
+// vo2MaxValue formats the estimate, e.g. "44.2 (RISING, +1.8 over 90 days)"
+func vo2MaxValue(v *VO2MaxData) string {
+	if v == nil {
+		return "–"
+	}
+	value := fmt.Sprintf("%.1f", v.Value)
+	if v.Change != nil {
+		value += fmt.Sprintf(" (%s, %+.1f over 90 days)", v.Trend, *v.Change)
+	}
+	return value
+}
+
+// queryWorkoutWindows returns the Apple workouts that started from..to
+// (inclusive), their start and end taken from each workout row's raw_json
+func queryWorkoutWindows(db *sql.DB, from, to string) ([]workoutWindow, error) {
+	rows, err := db.Query(`
+		SELECT json_extract(raw_json, '$.start'), json_extract(raw_json, '$.end')
+		FROM metrics
+		WHERE metric_name = 'workout'
+		AND local_ts(timestamp) >= ? AND local_ts(timestamp) < ?
+		AND json_valid(raw_json)
+		ORDER BY local_ts(timestamp)
+	`, from, addDays(to, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []workoutWindow
+	for rows.Next() {
+		var start, end sql.NullString
+		if err := rows.Scan(&start, &end); err != nil {
+			return nil, err
+		}
+		if start.Valid && end.Valid {
+			windows = append(windows, workoutWindow{start.String, end.String})
+		}
+	}
+	return windows, rows.Err()
+}
+
+// queryHRSamples returns the heart_rate readings within a workout, oldest first
+func queryHRSamples(db *sql.DB, w workoutWindow) ([]hrSample, error) {
+	rows, err := db.Query(`
+		SELECT timestamp, value
+		FROM metrics
+		WHERE metric_name = 'heart_rate'
+		AND timestamp >= ? AND timestamp <= ?
+		ORDER BY timestamp
+	`, w.Start, w.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []hrSample
+	for rows.Next() {
+		var at string
+		var s hrSample
+		if err := rows.Scan(&at, &s.BPM); err != nil {
+			return nil, err
+		}
+		if s.At, err = time.Parse(sleepSessionLayout, at); err != nil {
+			continue
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+// queryHRZones adds up zone minutes over the HRZoneDays ending on date;
+// nil when there were no workouts with heart-rate samples
+func queryHRZones(db *sql.DB, date string, maxHR int) (*HRZones, error) {
+	windows, err := queryWorkoutWindows(db, addDays(date, -(HRZoneDays-1)), date)
+	if err != nil {
+		return nil, err
+	}
+	zones := &HRZones{MaxHR: maxHR}
+	for _, w := range windows {
+		samples, err := queryHRSamples(db, w)
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) == 0 {
+			continue
+		}
+		addZoneMinutes(zones, samples, maxHR)
+		zones.Workouts++
+	}
+	if zones.Workouts == 0 {
+		return nil, nil
+	}
+	return zones, nil
+}
+
+// addZoneMinutes credits each sample's zone with the time until the next
+// sample, capped at HRSampleGapMinutes
+func addZoneMinutes(z *HRZones, samples []hrSample, maxHR int) {
+	minutes := [5]*float64{&z.Z1, &z.Z2, &z.Z3, &z.Z4, &z.Z5}
+	for i := 0; i+1 < len(samples); i++ {
+		zone := hrZone(samples[i].BPM, maxHR)
+		if zone == 0 {
+			continue
+		}
+		gap := min(samples[i+1].At.Sub(samples[i].At).Minutes(), HRSampleGapMinutes)
+		*minutes[zone-1] = math.Round((*minutes[zone-1]+gap)*10) / 10
+	}
+}
+
+// hrZone is the 1-5 zone for a heart rate, 0 below Z1
+func hrZone(bpm float64, maxHR int) int {
+	zone := 0
+	for i, floor := range hrZoneFloors {
+		if bpm >= floor*float64(maxHR) {
+			zone = i + 1
+		}
+	}
+	return zone
+}
+
+// Zone2Progress is the week's Zone 2 minutes against the weekly target
+type Zone2Progress struct {
+	Minutes       float64 `json:"minutes"`
+	TargetMinutes int     `json:"target_minutes"`
+	Pct           int     `json:"pct"`
+}
+
+// zone2ProgressFor compares Zone 2 minutes with target; nil without zone
+// data or when no target is set
+func zone2ProgressFor(z *HRZones, target int) *Zone2Progress {
+	if z == nil || target <= 0 {
+		return nil
+	}
+	return &Zone2Progress{Minutes: z.Z2, TargetMinutes: target, Pct: int(math.Round(z.Z2 / float64(target) * 100))}
+}
+
+// zoneNote is appended to the recommendation when the week's workouts had
+// no Zone 2 or fell short of the target
+func zoneNote(z *HRZones, target int) string {
+	switch {
+	case z == nil:
+		return ""
+	case z.Z2 == 0:
+		return " No Zone 2 this week, an easy cardio session would round things out."
+	case target > 0 && z.Z2 < float64(target):
+		return fmt.Sprintf(" Zone 2 is at %.0f of %d min this week, an easy cardio session would close the gap.", z.Z2, target)
+	}
+	return ""
+}
+
+// zone2Value formats progress, e.g. "45 / 150 min (30%)"
+func zone2Value(p *Zone2Progress) string {
+	if p == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%.0f / %d min (%d%%)", p.Minutes, p.TargetMinutes, p.Pct)
+}
+
+// zonesValue formats the zone split, e.g. "Z1 20, Z2 45, Z3 30, Z4 10, Z5 2 min"
+func zonesValue(z *HRZones) string {
+	if z == nil {
+		return "–"
+	}
+	return fmt.Sprintf("Z1 %.0f, Z2 %.0f, Z3 %.0f, Z4 %.0f, Z5 %.0f min", z.Z1, z.Z2, z.Z3, z.Z4, z.Z5)
+}
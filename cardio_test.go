@@ -0,0 +1,186 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ==================== VO2MAX TESTS ====================
+
+func TestVO2MaxFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []dailyValue
+		wantTrend  string
+		wantChange *float64
+		wantNil    bool
+	}{
+		{"no readings", nil, "", nil, true},
+		{"too recent for a trend", []dailyValue{{"2024-01-01", 42}, {"2024-01-15", 44}}, "", nil, false},
+		{"rising", []dailyValue{{"2023-11-01", 42.1}, {"2024-01-15", 43.9}}, "RISING", ptr(1.8), false},
+		{"stable", []dailyValue{{"2023-11-01", 42.1}, {"2023-12-01", 45}, {"2024-01-15", 42.6}}, "STABLE", ptr(0.5), false},
+		{"falling", []dailyValue{{"2023-11-01", 44}, {"2024-01-15", 42.5}}, "FALLING", ptr(-1.5), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := vo2MaxFor(tt.values)
+			if tt.wantNil {
+				if v != nil {
+					t.Errorf("vo2MaxFor() = %+v, want nil", v)
+				}
+				return
+			}
+			if v == nil || v.Date != "2024-01-15" || v.Trend != tt.wantTrend {
+				t.Fatalf("vo2MaxFor() = %+v, want latest 2024-01-15 trend %q", v, tt.wantTrend)
+			}
+			if (v.Change == nil) != (tt.wantChange == nil) || (v.Change != nil && *v.Change != *tt.wantChange) {
+				t.Errorf("Change = %v, want %v", v.Change, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestVO2MaxFromDB(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('vo2_max', '2023-09-01 10:00:00 +0700', 30, 'ml/(kg*min)', 'Apple Watch'),
+		('vo2_max', '2023-11-01 10:00:00 +0700', 42, 'ml/(kg*min)', 'Apple Watch'),
+		('vo2_max', '2024-01-14 10:00:00 +0700', 44, 'ml/(kg*min)', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The September reading is outside the 90-day window
+	b := &MorningBriefing{TargetDate: "2024-01-15"}
+	fillMorningHealthFromDB(b, db, "2024-01-15", nil)
+	v := b.Training.VO2Max
+	if v == nil || v.Value != 44 || v.Change == nil || *v.Change != 2 || v.Trend != "RISING" {
+		t.Fatalf("VO2Max = %+v, want 44 rising by 2", v)
+	}
+
+	b.Sleep = SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true}
+	classify(b)
+	want := "Well rested. Attack the day. VO2max is up 2.0 over 90 days, cardio is paying off."
+	if b.Classification.Recommendation != want {
+		t.Errorf("Recommendation = %q, want %q", b.Classification.Recommendation, want)
+	}
+	if md := MorningMarkdown(*b); !strings.Contains(md, "- VO2max: 44.0 (RISING, +2.0 over 90 days)") {
+		t.Errorf("markdown missing VO2max:\n%s", md)
+	}
+	if vars := morningRuleVars(*b); vars["vo2_max"] != 44.0 || vars["vo2_max_trend"] != "RISING" {
+		t.Errorf("rule vars = %v, %v; want 44, RISING", vars["vo2_max"], vars["vo2_max_trend"])
+	}
+}
+
+func TestVO2MaxNote(t *testing.T) {
+	if got := vo2MaxNote(&VO2MaxData{Value: 41, Change: ptr(-1.5), Trend: "FALLING"}); got != " VO2max is down 1.5 over 90 days, worth adding some cardio." {
+		t.Errorf("vo2MaxNote(falling) = %q", got)
+	}
+	if got := vo2MaxNote(&VO2MaxData{Value: 41, Change: ptr(0.4), Trend: "STABLE"}); got != "" {
+		t.Errorf("vo2MaxNote(stable) = %q, want empty", got)
+	}
+	if got := vo2MaxNote(nil); got != "" {
+		t.Errorf("vo2MaxNote(nil) = %q, want empty", got)
+	}
+}
+
+// ==================== HR ZONE TESTS ====================
+
+func TestHRZone(t *testing.T) {
+	tests := map[float64]int{80: 0, 90: 1, 110: 2, 130: 3, 150: 4, 162: 5, 190: 5}
+	for bpm, want := range tests {
+		if got := hrZone(bpm, 180); got != want {
+			t.Errorf("hrZone(%v, 180) = %d, want %d", bpm, got, want)
+		}
+	}
+}
+
+func TestQueryHRZones(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source, raw_json) VALUES
+		('workout', '2024-01-05 07:00:00 +0700', 30, 'min', 'Apple Watch', '{"start": "2024-01-05 07:00:00 +0700", "end": "2024-01-05 07:30:00 +0700"}'),
+		('workout', '2024-01-14 07:00:00 +0700', 30, 'min', 'Apple Watch', '{"start": "2024-01-14 07:00:00 +0700", "end": "2024-01-14 07:30:00 +0700"}'),
+		('workout', '2024-01-15 18:00:00 +0700', 45, 'min', 'Apple Watch', '{"start": "2024-01-15 18:00:00 +0700", "end": "2024-01-15 18:45:00 +0700"}'),
+		('heart_rate', '2024-01-05 07:10:00 +0700', 115, 'bpm', 'Apple Watch', NULL),
+		('heart_rate', '2024-01-05 07:20:00 +0700', 115, 'bpm', 'Apple Watch', NULL),
+		('heart_rate', '2024-01-14 06:55:00 +0700', 150, 'bpm', 'Apple Watch', NULL),
+		('heart_rate', '2024-01-14 07:00:00 +0700', 120, 'bpm', 'Apple Watch', NULL),
+		('heart_rate', '2024-01-14 07:02:00 +0700', 135, 'bpm', 'Apple Watch', NULL),
+		('heart_rate', '2024-01-14 07:04:00 +0700', 170, 'bpm', 'Apple Watch', NULL),
+		('heart_rate', '2024-01-14 07:20:00 +0700', 100, 'bpm', 'Apple Watch', NULL)
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The 01-05 workout is outside the week, the 06:55 sample is before the
+	// workout, the 07:04 gap is capped at 5 minutes, and the 18:00 workout has no samples
+	zones, err := queryHRZones(db, "2024-01-15", 180)
+	if err != nil {
+		t.Fatalf("queryHRZones() error: %v", err)
+	}
+	want := &HRZones{Z2: 2, Z3: 2, Z5: 5, Workouts: 1, MaxHR: 180}
+	if zones == nil || *zones != *want {
+		t.Errorf("queryHRZones() = %+v, want %+v", zones, want)
+	}
+
+	if zones, err := queryHRZones(db, "2024-02-01", 180); err != nil || zones != nil {
+		t.Errorf("queryHRZones() with no workouts = %+v, %v; want nil", zones, err)
+	}
+}
+
+func TestZoneNote(t *testing.T) {
+	b := &MorningBriefing{
+		Sleep:    SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true},
+		Training: TrainingData{HRZones: &HRZones{Z3: 40, Z4: 20, Workouts: 2, MaxHR: 180}},
+	}
+	classify(b)
+	if want := "Well rested. Attack the day. No Zone 2 this week, an easy cardio session would round things out."; b.Classification.Recommendation != want {
+		t.Errorf("Recommendation = %q, want %q", b.Classification.Recommendation, want)
+	}
+	if got := zoneNote(&HRZones{Z2: 160, Workouts: 3}, 150); got != "" {
+		t.Errorf("zoneNote() past the target = %q, want empty", got)
+	}
+	if got := zoneNote(&HRZones{Z2: 12, Workouts: 1}, 0); got != "" {
+		t.Errorf("zoneNote() without a target = %q, want empty", got)
+	}
+	if text := MorningText(*b, textStyle{}); !strings.Contains(text, "HR zones: Z1 0, Z2 0, Z3 40, Z4 20, Z5 0 min\n") {
+		t.Errorf("text missing HR zones:\n%s", text)
+	}
+}
+
+func TestMaxHeartRate(t *testing.T) {
+	if got := (UserConfig{Age: 40}).MaxHeartRate(); got != 180 {
+		t.Errorf("MaxHeartRate() = %d, want 180 from age", got)
+	}
+	if got := (UserConfig{Age: 40, MaxHR: 192}).MaxHeartRate(); got != 192 {
+		t.Errorf("MaxHeartRate() = %d, want configured 192", got)
+	}
+}
+
+// ==================== ZONE 2 TARGET TESTS ====================
+
+func TestZone2Progress(t *testing.T) {
+	p := zone2ProgressFor(&HRZones{Z2: 45, Workouts: 2}, 150)
+	if p == nil || *p != (Zone2Progress{Minutes: 45, TargetMinutes: 150, Pct: 30}) {
+		t.Errorf("zone2ProgressFor() = %+v, want 45/150 at 30%%", p)
+	}
+	if p := zone2ProgressFor(&HRZones{Z2: 45}, 0); p != nil {
+		t.Errorf("zone2ProgressFor() without a target = %+v, want nil", p)
+	}
+	if p := zone2ProgressFor(nil, 150); p != nil {
+		t.Errorf("zone2ProgressFor() without zones = %+v, want nil", p)
+	}
+
+	want := " Zone 2 is at 45 of 150 min this week, an easy cardio session would close the gap."
+	if got := zoneNote(&HRZones{Z2: 45, Workouts: 2}, 150); got != want {
+		t.Errorf("zoneNote() behind = %q, want %q", got, want)
+	}
+	m := MorningBriefing{Training: TrainingData{Zone2: zone2ProgressFor(&HRZones{Z2: 45}, 150)}}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Zone 2: 45 / 150 min (30%)\n") {
+		t.Errorf("text missing Zone 2 progress:\n%s", text)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Med task categories
+const (
+	MedCategoryMedication = "medication"
+	MedCategorySupplement = "supplement"
+	MedCategoryInjection  = "injection"
+)
+
+// medCategories lists the categories in the order they're summarized
+var medCategories = []string{MedCategoryMedication, MedCategorySupplement, MedCategoryInjection}
+
+// MedCategoriesConfig maps Todoist labels to med categories. A task matching
+// none is a medication, so an unlabelled prescription is never downplayed.
+type MedCategoriesConfig struct {
+	Medications []string `json:"medications"`
+	Supplements []string `json:"supplements"`
+	Injections  []string `json:"injections"`
+}
+
+// MedCategoryData is one category's share of the day's med tasks
+type MedCategoryData struct {
+	Due       []string `json:"due,omitempty"`     // morning
+	Overdue   []string `json:"overdue,omitempty"` // morning
+	Completed []string `json:"completed,omitempty"`
+	Missed    []string `json:"missed,omitempty"` // evening
+}
+
+// MedCategories buckets med tasks by category
+type MedCategories map[string]*MedCategoryData
+
+// bucket returns the category's tasks, adding it on first use
+func (c *MedCategories) bucket(category string) *MedCategoryData {
+	if *c == nil {
+		*c = MedCategories{}
+	}
+	if (*c)[category] == nil {
+		(*c)[category] = &MedCategoryData{}
+	}
+	return (*c)[category]
+}
+
+// medCategory picks the task's category from its labels; injections win over
+// medications, which win over supplements, when a task carries several
+func medCategory(cfg MedCategoriesConfig, task TodoistTask) string {
+	has := func(labels []string) bool {
+		return slices.ContainsFunc(task.Labels, func(l string) bool { return slices.Contains(labels, l) })
+	}
+	switch {
+	case has(cfg.Injections):
+		return MedCategoryInjection
+	case has(cfg.Medications):
+		return MedCategoryMedication
+	case has(cfg.Supplements):
+		return MedCategorySupplement
+	}
+	return MedCategoryMedication
+}
+
+// medCategoriesLine summarizes each category, e.g.
+// "medication: 1 overdue, 2 due; supplement: 1 missed, 3 done"
+func medCategoriesLine(c MedCategories) string {
+	var parts []string
+	for _, name := range medCategories {
+		d := c[name]
+		if d == nil {
+			continue
+		}
+		var counts []string
+		for _, n := range []struct {
+			label string
+			tasks []string
+		}{{"overdue", d.Overdue}, {"due", d.Due}, {"missed", d.Missed}, {"done", d.Completed}} {
+			if len(n.tasks) > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", len(n.tasks), n.label))
+			}
+		}
+		parts = append(parts, name+": "+strings.Join(counts, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// of returns the category's tasks, empty when it has none
+func (c MedCategories) of(category string) MedCategoryData {
+	if d := c[category]; d != nil {
+		return *d
+	}
+	return MedCategoryData{}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== MED CATEGORY TESTS ====================
+
+func TestMedCategory(t *testing.T) {
+	cfg := MedCategoriesConfig{
+		Medications: []string{"Rx"},
+		Supplements: []string{"🧴Supps"},
+		Injections:  []string{"💉"},
+	}
+	tests := []struct {
+		name   string
+		labels []string
+		want   string
+	}{
+		{"medication label", []string{"💊Meds", "Rx"}, MedCategoryMedication},
+		{"supplement label", []string{"💊Meds", "🧴Supps"}, MedCategorySupplement},
+		{"injection label", []string{"💉"}, MedCategoryInjection},
+		{"injection wins", []string{"🧴Supps", "💉"}, MedCategoryInjection},
+		{"medication wins over supplement", []string{"🧴Supps", "Rx"}, MedCategoryMedication},
+		{"unmapped defaults to medication", []string{"💊Meds"}, MedCategoryMedication},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := medCategory(cfg, TodoistTask{Content: "x", Labels: tt.labels}); got != tt.want {
+				t.Errorf("medCategory(%v) = %q, want %q", tt.labels, got, tt.want)
+			}
+		})
+	}
+	if got := medCategory(DefaultConfig().MedCategories, TodoistTask{Labels: []string{"💉"}}); got != MedCategoryInjection {
+		t.Errorf("default 💉 category = %q, want injection", got)
+	}
+}
+
+func TestMedCategories(t *testing.T) {
+	var c MedCategories
+	c.bucket(MedCategorySupplement).Missed = append(c.bucket(MedCategorySupplement).Missed, "Creatine")
+	c.bucket(MedCategoryMedication).Overdue = []string{"Nexium"}
+	c.bucket(MedCategoryMedication).Due = []string{"PrEP", "Vitamin D"}
+	c.bucket(MedCategorySupplement).Completed = []string{"Fish oil"}
+
+	if got := c.of(MedCategorySupplement); !reflect.DeepEqual(got, MedCategoryData{Completed: []string{"Fish oil"}, Missed: []string{"Creatine"}}) {
+		t.Errorf("supplements = %+v", got)
+	}
+	if got := c.of(MedCategoryInjection); !reflect.DeepEqual(got, MedCategoryData{}) {
+		t.Errorf("injections = %+v, want empty", got)
+	}
+	if got := medCategoriesLine(c); got != "medication: 1 overdue, 2 due; supplement: 1 missed, 1 done" {
+		t.Errorf("medCategoriesLine() = %q", got)
+	}
+	if got := medCategoriesLine(nil); got != "" {
+		t.Errorf("medCategoriesLine(nil) = %q, want empty", got)
+	}
+}
+
+func TestMedCategoryRulesAndPrompt(t *testing.T) {
+	var meds MedCategories
+	meds.bucket(MedCategoryInjection).Overdue = []string{"TB-500"}
+	m := MorningBriefing{Meds: MedsData{Overdue: []MedTask{{Name: "TB-500", Category: MedCategoryInjection}}, Categories: meds}}
+	vars := morningRuleVars(m)
+	if vars["meds.overdue.injection"] != 1.0 || vars["meds.overdue.medication"] != 0.0 {
+		t.Errorf("morning category vars = %v, %v", vars["meds.overdue.injection"], vars["meds.overdue.medication"])
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "By kind: injection: 1 overdue\n") {
+		t.Errorf("morning prompt missing categories (%v):\n%s", err, prompt)
+	}
+
+	var protocols MedCategories
+	protocols.bucket(MedCategorySupplement).Missed = []string{"Creatine"}
+	e := EveningBriefing{Protocols: ProtocolsData{Missed: []string{"Creatine"}, Categories: protocols}}
+	vars = eveningRuleVars(e)
+	if vars["protocols.missed.supplement"] != 1.0 || vars["protocols.missed.medication"] != 0.0 {
+		t.Errorf("evening category vars = %v, %v", vars["protocols.missed.supplement"], vars["protocols.missed.medication"])
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "evening", e); err != nil || !strings.Contains(prompt, "By kind: supplement: 1 missed") {
+		t.Errorf("evening prompt missing categories (%v):\n%s", err, prompt)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RunCheckCommand handles `briefing check [--dry-run]`
+// Evaluates alert rules against the latest health data and notifies new
+// matches, without building or delivering a briefing. Meant for hourly cron.
+func RunCheckCommand(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "Print matching rules without notifying")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+	if len(cfg.Rules) == 0 {
+		return errors.New("no rules configured (add a \"rules\" section to the config)")
+	}
+
+	db, err := sql.Open("sqlite", getHealthDBPath())
+	if err != nil {
+		return fmt.Errorf("sqlite open error: %w", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	tags, err := loadTags(addDays(today, -HRVBaselineDays), today)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	morning, _ := buildHistoricalMorning(db, today, now, tags)
+	evening, _ := buildHistoricalEvening(db, today, now)
+
+	alerts, errs := EvaluateRules(cfg.Rules, checkRuleVars(morning, evening))
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	statePath := getAlertStatePath()
+	state, err := loadAlertState(statePath)
+	if err != nil {
+		return fmt.Errorf("alert state error: %w", err)
+	}
+	fresh := newAlerts(state, today, alerts)
+
+	for _, a := range fresh {
+		fmt.Printf("ALERT [%s] %s: %s\n", a.Severity, a.Rule, a.When)
+	}
+	if *dryRun {
+		return nil
+	}
+
+	for _, err := range dispatchAlerts(cfg, "check", today, now.Format(time.RFC3339), fresh) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return saveAlertState(statePath, today, state, fresh)
+}
+
+// checkRuleVars merges morning and evening variables for a check run.
+// Calendar, meds, and training aren't fetched by check, so their
+// variables are left out rather than reported as zero.
+func checkRuleVars(morning MorningBriefing, evening EveningBriefing) map[string]any {
+	vars := morningRuleVars(morning)
+	for k, v := range eveningRuleVars(evening) {
+		if v != nil {
+			vars[k] = v
+		}
+	}
+	for k := range vars {
+		if strings.HasPrefix(k, "calendar.") || strings.HasPrefix(k, "meds.") || strings.HasPrefix(k, "training.") {
+			delete(vars, k)
+		}
+	}
+	return vars
+}
+
+// AlertState records which rules already notified on a date so hourly
+// checks report each match once per day
+type AlertState struct {
+	Date     string   `json:"date"`
+	Notified []string `json:"notified"`
+}
+
+// getAlertStatePath returns where check remembers today's notified rules
+func getAlertStatePath() string {
+	return filepath.Join(getDataDir(), "alert-state.json")
+}
+
+func loadAlertState(path string) (AlertState, error) {
+	var state AlertState
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// newAlerts drops alerts already notified today
+func newAlerts(state AlertState, date string, alerts []Alert) []Alert {
+	if state.Date != date {
+		return alerts
+	}
+	var fresh []Alert
+	for _, a := range alerts {
+		if !slices.Contains(state.Notified, a.Rule) {
+			fresh = append(fresh, a)
+		}
+	}
+	return fresh
+}
+
+// saveAlertState adds notified rules to today's state, starting over on a new day
+func saveAlertState(path, date string, prev AlertState, notified []Alert) error {
+	next := AlertState{Date: date}
+	if prev.Date == date {
+		next.Notified = prev.Notified
+	}
+	for _, a := range notified {
+		next.Notified = append(next.Notified, a.Rule)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, _ := json.MarshalIndent(next, "", "  ")
+	return os.WriteFile(path, data, 0o600)
+}
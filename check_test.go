@@ -0,0 +1,90 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// ==================== CHECK VARIABLE TESTS ====================
+
+func TestCheckRuleVars(t *testing.T) {
+	morning := MorningBriefing{
+		Sleep:  SleepData{TotalHours: ptr(5.5)},
+		Vitals: VitalsData{HRV: ptr(42.0)},
+	}
+	evening := EveningBriefing{}
+	evening.Protein.RemainingG = 60
+
+	vars := checkRuleVars(morning, evening)
+
+	// Evening's zero HRV (missing) must not clobber the morning value
+	if vars["hrv"] != 42.0 {
+		t.Errorf("hrv = %v, want 42", vars["hrv"])
+	}
+	if vars["sleep.total"] != 5.5 {
+		t.Errorf("sleep.total = %v, want 5.5", vars["sleep.total"])
+	}
+	if vars["protein.remaining"] != 60.0 {
+		t.Errorf("protein.remaining = %v, want 60", vars["protein.remaining"])
+	}
+	// Sources check doesn't fetch are absent, not zero
+	for _, k := range []string{"meds.overdue", "calendar.morning_count", "training.days_since_last"} {
+		if _, ok := vars[k]; ok {
+			t.Errorf("vars[%q] present, want absent", k)
+		}
+	}
+}
+
+// ==================== ALERT STATE TESTS ====================
+
+func TestNewAlerts(t *testing.T) {
+	alerts := []Alert{{Rule: "low hrv"}, {Rule: "short sleep"}}
+
+	tests := []struct {
+		name  string
+		state AlertState
+		want  int
+	}{
+		{"no state", AlertState{}, 2},
+		{"already notified today", AlertState{Date: "2024-01-15", Notified: []string{"low hrv"}}, 1},
+		{"notified yesterday", AlertState{Date: "2024-01-14", Notified: []string{"low hrv", "short sleep"}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newAlerts(tt.state, "2024-01-15", alerts); len(got) != tt.want {
+				t.Errorf("len(newAlerts()) = %d, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "alert-state.json")
+
+	state, err := loadAlertState(path)
+	if err != nil {
+		t.Fatalf("loadAlertState() missing file error: %v", err)
+	}
+
+	if err := saveAlertState(path, "2024-01-15", state, []Alert{{Rule: "low hrv"}}); err != nil {
+		t.Fatal(err)
+	}
+	state, _ = loadAlertState(path)
+	if err := saveAlertState(path, "2024-01-15", state, []Alert{{Rule: "short sleep"}}); err != nil {
+		t.Fatal(err)
+	}
+	state, _ = loadAlertState(path)
+	if len(state.Notified) != 2 {
+		t.Errorf("Notified = %v, want both rules", state.Notified)
+	}
+
+	// A new day starts over
+	if err := saveAlertState(path, "2024-01-16", state, nil); err != nil {
+		t.Fatal(err)
+	}
+	state, _ = loadAlertState(path)
+	if state.Date != "2024-01-16" || len(state.Notified) != 0 {
+		t.Errorf("state = %+v, want empty 2024-01-16", state)
+	}
+}
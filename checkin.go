@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Subjective check-in scale
+const (
+	CheckinMin = 1
+	CheckinMax = 10
+)
+
+// CheckinData is the latest subjective check-in for a day (1-10 each)
+type CheckinData struct {
+	Mood       *float64 `json:"mood,omitempty"`
+	Energy     *float64 `json:"energy,omitempty"`
+	Soreness   *float64 `json:"soreness,omitempty"` // 10 is very sore
+	SleepFeel  *float64 `json:"sleep_feel,omitempty"`
+	Motivation *float64 `json:"motivation,omitempty"`
+}
+
+// checkinField maps one check-in answer to its metric row
+type checkinField struct {
+	flag   string
+	metric string
+	prompt string
+	field  func(*CheckinData) **float64
+}
+
+var (
+	fieldMood       = checkinField{"mood", "mood", "Mood", func(c *CheckinData) **float64 { return &c.Mood }}
+	fieldEnergy     = checkinField{"energy", "energy_level", "Energy", func(c *CheckinData) **float64 { return &c.Energy }}
+	fieldSoreness   = checkinField{"soreness", "soreness", "Soreness", func(c *CheckinData) **float64 { return &c.Soreness }}
+	fieldSleepFeel  = checkinField{"sleep-feel", "sleep_feel", "How rested do you feel", func(c *CheckinData) **float64 { return &c.SleepFeel }}
+	fieldMotivation = checkinField{"motivation", "motivation", "Motivation", func(c *CheckinData) **float64 { return &c.Motivation }}
+)
+
+// checkinFields are every subjective score; soreness is inverted when scored
+var checkinFields = []checkinField{fieldMood, fieldEnergy, fieldSoreness, fieldSleepFeel, fieldMotivation}
+
+// checkinPromptFields are asked by `briefing checkin` when run without flags
+var checkinPromptFields = []checkinField{fieldMood, fieldEnergy, fieldSoreness}
+
+// RunCheckinCommand handles `briefing checkin [--mood N] [--energy N] [--soreness N] [--sleep-feel N] [--motivation N] [--at HH:MM]`
+// With no scores given on a terminal it prompts for each one.
+func RunCheckinCommand(args []string) error {
+	fs := flag.NewFlagSet("checkin", flag.ContinueOnError)
+	scores := make([]*int, len(checkinFields))
+	for i, f := range checkinFields {
+		scores[i] = fs.Int(f.flag, 0, fmt.Sprintf("%s, %d-%d", f.prompt, CheckinMin, CheckinMax))
+	}
+	at := fs.String("at", "", "Check-in time as HH:MM today (default now)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var c CheckinData
+	given := false
+	for i, f := range checkinFields {
+		if *scores[i] == 0 {
+			continue
+		}
+		if err := validateCheckinScore(f.flag, *scores[i]); err != nil {
+			return err
+		}
+		v := float64(*scores[i])
+		*f.field(&c) = &v
+		given = true
+	}
+
+	if !given {
+		if !isTerminal(os.Stdin) {
+			return errors.New("usage: briefing checkin --mood N --energy N --soreness N (1-10), or run in a terminal to be prompted")
+		}
+		var err error
+		c, err = promptCheckin(os.Stdin, os.Stdout, checkinPromptFields)
+		if err != nil {
+			return err
+		}
+		if formatCheckin(c) == "" {
+			return errors.New("nothing to record")
+		}
+	}
+
+	// The config sets the zone --at is read in
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+	checkinTime, err := parseMealTime(*at, time.Now())
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite", getHealthDBPath())
+	if err != nil {
+		return fmt.Errorf("sqlite open error: %w", err)
+	}
+	defer db.Close()
+
+	if err := insertCheckinMetrics(db, checkinTime, c); err != nil {
+		return err
+	}
+	fmt.Printf("Checked in at %s: %s\n", checkinTime.Format("15:04"), formatCheckin(c))
+	return nil
+}
+
+func validateCheckinScore(name string, v int) error {
+	if v < CheckinMin || v > CheckinMax {
+		return fmt.Errorf("--%s must be %d-%d, got %d", name, CheckinMin, CheckinMax, v)
+	}
+	return nil
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// promptCheckin asks for each score in turn; blank answers are skipped
+// and invalid ones re-asked
+func promptCheckin(in io.Reader, out io.Writer, fields []checkinField) (CheckinData, error) {
+	var c CheckinData
+	scanner := bufio.NewScanner(in)
+	for _, f := range fields {
+		for {
+			fmt.Fprintf(out, "%s (%d-%d, blank to skip): ", f.prompt, CheckinMin, CheckinMax)
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return c, err
+				}
+				return c, nil // EOF keeps what was answered
+			}
+			answer := strings.TrimSpace(scanner.Text())
+			if answer == "" {
+				break
+			}
+			n, err := strconv.Atoi(answer)
+			if err == nil {
+				err = validateCheckinScore(f.flag, n)
+			}
+			if err != nil {
+				fmt.Fprintf(out, "Please enter a whole number from %d to %d.\n", CheckinMin, CheckinMax)
+				continue
+			}
+			v := float64(n)
+			*f.field(&c) = &v
+			break
+		}
+	}
+	return c, nil
+}
+
+func formatCheckin(c CheckinData) string {
+	var parts []string
+	for _, f := range checkinFields {
+		if v := *f.field(&c); v != nil {
+			parts = append(parts, fmt.Sprintf("%s %.0f", f.flag, *v))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// insertCheckinMetrics writes the answered scores as metric rows, a second
+// check-in at the same time going in the next free second (see insertMealMetrics)
+func insertCheckinMetrics(db *sql.DB, at time.Time, c CheckinData) error {
+	metrics := make([]string, len(checkinFields))
+	for i, f := range checkinFields {
+		metrics[i] = f.metric
+	}
+	at, err := freeMetricSecond(db, at, metrics...)
+	if err != nil {
+		return err
+	}
+	for _, f := range checkinFields {
+		v := *f.field(&c)
+		if v == nil {
+			continue
+		}
+		if err := appendMetric(db, f.metric, at, *v, "score"); err != nil {
+			return fmt.Errorf("%s insert error: %w", f.metric, err)
+		}
+	}
+	return nil
+}
+
+// queryCheckin returns the latest check-in scores recorded on a date, nil if none
+func queryCheckin(db *sql.DB, date string) (*CheckinData, error) {
+	var c CheckinData
+	found := false
+	for _, f := range checkinFields {
+		v, err := queryLatestValue(db, f.metric, date)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			*f.field(&c) = v
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// subjectiveScore maps a check-in to 0-100 (soreness inverted), nil if empty
+func subjectiveScore(c *CheckinData) *float64 {
+	if c == nil {
+		return nil
+	}
+	scale := func(v float64) float64 { return (v - CheckinMin) / (CheckinMax - CheckinMin) * 100 }
+
+	var parts []float64
+	for _, f := range checkinFields {
+		v := *f.field(c)
+		if v == nil {
+			continue
+		}
+		if f.metric == fieldSoreness.metric {
+			parts = append(parts, 100-scale(*v))
+		} else {
+			parts = append(parts, scale(*v))
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	total := 0.0
+	for _, p := range parts {
+		total += p
+	}
+	score := clampScore(total / float64(len(parts)))
+	return &score
+}
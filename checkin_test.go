@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== CHECK-IN TESTS ====================
+
+func TestPromptCheckin(t *testing.T) {
+	var out strings.Builder
+	// Mood answered after one bad try, energy skipped, soreness answered
+	c, err := promptCheckin(strings.NewReader("eleven\n7\n\n3\n"), &out, checkinPromptFields)
+	if err != nil {
+		t.Fatalf("promptCheckin() error: %v", err)
+	}
+	if c.Mood == nil || *c.Mood != 7 {
+		t.Errorf("Mood = %v, want 7", c.Mood)
+	}
+	if c.Energy != nil {
+		t.Errorf("Energy = %v, want nil (skipped)", *c.Energy)
+	}
+	if c.Soreness == nil || *c.Soreness != 3 {
+		t.Errorf("Soreness = %v, want 3", c.Soreness)
+	}
+	if strings.Count(out.String(), "Mood (1-10") != 2 {
+		t.Errorf("expected mood to be asked twice, got:\n%s", out.String())
+	}
+
+	// EOF part way through keeps earlier answers
+	c, err = promptCheckin(strings.NewReader("5\n"), &out, checkinPromptFields)
+	if err != nil {
+		t.Fatalf("promptCheckin() error: %v", err)
+	}
+	if c.Mood == nil || *c.Mood != 5 || c.Energy != nil {
+		t.Errorf("after EOF = %+v, want mood 5 only", c)
+	}
+}
+
+func TestInsertAndQueryCheckin(t *testing.T) {
+	db := newTestMetricsDB(t)
+	tz := time.FixedZone("ICT", 7*3600)
+
+	early := time.Date(2024, 1, 15, 7, 0, 0, 0, tz)
+	late := time.Date(2024, 1, 15, 9, 0, 0, 0, tz)
+	if err := insertCheckinMetrics(db, early, CheckinData{Mood: ptr(4), Soreness: ptr(8)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := insertCheckinMetrics(db, late, CheckinData{Mood: ptr(6)}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := queryCheckin(db, "2024-01-15")
+	if err != nil {
+		t.Fatalf("queryCheckin() error: %v", err)
+	}
+	if c == nil {
+		t.Fatal("queryCheckin() = nil")
+	}
+	// Latest answer per score wins
+	if *c.Mood != 6 || c.Energy != nil || *c.Soreness != 8 {
+		t.Errorf("checkin = mood %v energy %v soreness %v, want 6/nil/8", *c.Mood, c.Energy, *c.Soreness)
+	}
+
+	var source string
+	if err := db.QueryRow(`SELECT source FROM metrics WHERE metric_name = 'mood' LIMIT 1`).Scan(&source); err != nil {
+		t.Fatal(err)
+	}
+	if source != metricSource {
+		t.Errorf("source = %q, want %q", source, metricSource)
+	}
+
+	c, err = queryCheckin(db, "2024-01-16")
+	if err != nil || c != nil {
+		t.Errorf("queryCheckin() on empty day = %v, %v; want nil, nil", c, err)
+	}
+}
+
+func TestSubjectiveScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		checkin  *CheckinData
+		expected *float64
+	}{
+		{"nil", nil, nil},
+		{"empty", &CheckinData{}, nil},
+		{"best", &CheckinData{Mood: ptr(10), Energy: ptr(10), Soreness: ptr(1)}, ptr(100)},
+		{"worst", &CheckinData{Mood: ptr(1), Energy: ptr(1), Soreness: ptr(10)}, ptr(0)},
+		{"soreness inverted", &CheckinData{Soreness: ptr(4)}, ptr(200.0 / 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := subjectiveScore(tt.checkin)
+			if tt.expected == nil {
+				if got != nil {
+					t.Errorf("subjectiveScore() = %v, want nil", *got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.expected {
+				t.Errorf("subjectiveScore() = %v, want %v", got, *tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunCheckinCommandValidation(t *testing.T) {
+	if err := RunCheckinCommand([]string{"--mood", "11"}); err == nil {
+		t.Error("expected error for out-of-range mood")
+	}
+	if err := RunCheckinCommand([]string{"--energy", "5", "--at", "7am"}); err == nil {
+		t.Error("expected error for bad --at")
+	}
+}
+
+func TestRunCheckinCommandTimezone(t *testing.T) {
+	db := useTestHealthDB(t, "Asia/Tokyo")
+	time.Local = time.UTC
+
+	if err := RunCheckinCommand([]string{"--mood", "7", "--at", "08:00"}); err != nil {
+		t.Fatalf("RunCheckinCommand() error: %v", err)
+	}
+	var ts string
+	if err := db.QueryRow(`SELECT timestamp FROM metrics WHERE metric_name = 'mood'`).Scan(&ts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(ts, " 08:00:00 +0900") {
+		t.Errorf("check-in timestamp = %q, want 08:00 in the configured zone", ts)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Change is one metric's movement since the previous day's stored briefing
+type Change struct {
+	Metric    string  `json:"metric"` // JSON path in the briefing, e.g. vitals.hrv_ms
+	Label     string  `json:"label"`
+	Today     float64 `json:"today"`
+	Yesterday float64 `json:"yesterday"`
+	Delta     float64 `json:"delta"`
+	Trend     string  `json:"trend"`   // better, worse, same, or changed when neither direction is better
+	Summary   string  `json:"summary"` // e.g. "HRV +8 ms"
+}
+
+// compareMetric is a briefing field compared day over day. Count fields are
+// compared by array length, with null (nothing overdue yet) counting as 0.
+type compareMetric struct {
+	path          string
+	label         string
+	unit          string
+	decimals      int
+	count         bool
+	better        int  // +1 when higher is better, -1 when lower is, 0 when neither
+	zeroIsMissing bool // evening recovery values are 0 when there was no reading
+}
+
+var morningCompareMetrics = []compareMetric{
+	{path: "sleep.total_hours", label: "Sleep", unit: "h", decimals: 1, better: 1},
+	{path: "sleep.deep_hours", label: "Deep sleep", unit: "h", decimals: 1, better: 1},
+	{path: "vitals.hrv_ms", label: "HRV", unit: "ms", better: 1},
+	{path: "vitals.resting_hr_bpm", label: "Resting HR", unit: "bpm", better: -1},
+	{path: "classification.readiness_score", label: "Readiness", better: 1},
+	{path: "meds.overdue", label: "Overdue meds", better: -1, count: true},
+}
+
+var eveningCompareMetrics = []compareMetric{
+	{path: "energy.deficit_or_surplus_kcal", label: "Energy balance", unit: "kcal"},
+	{path: "energy.consumed_kcal", label: "Eaten", unit: "kcal"},
+	{path: "protein.consumed_g", label: "Protein", unit: "g", better: 1},
+	{path: "hydration.consumed_ml", label: "Water", unit: "ml", better: 1},
+	{path: "activity.steps", label: "Steps", better: 1},
+	{path: "protocols.missed", label: "Missed protocols", better: -1, count: true},
+	{path: "recovery.hrv_ms", label: "HRV", unit: "ms", better: 1, zeroIsMissing: true},
+}
+
+// compareValue reads a metric from decoded briefing JSON
+func compareValue(doc any, m compareMetric) (float64, bool) {
+	v, ok := lookupJSONPath(doc, m.path)
+	if m.count {
+		items, _ := v.([]any)
+		return float64(len(items)), true
+	}
+	if !ok {
+		return 0, false
+	}
+	n, ok := v.(float64)
+	if !ok || (m.zeroIsMissing && n == 0) {
+		return 0, false
+	}
+	return n, true
+}
+
+// compareBriefings diffs two briefings' JSON over metrics, skipping metrics
+// missing from either day
+func compareBriefings(today, yesterday []byte, metrics []compareMetric) ([]Change, error) {
+	var curr, prev any
+	if err := json.Unmarshal(today, &curr); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(yesterday, &prev); err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	for _, m := range metrics {
+		t, okT := compareValue(curr, m)
+		y, okY := compareValue(prev, m)
+		if !okT || !okY {
+			continue
+		}
+		scale := math.Pow(10, float64(m.decimals))
+		delta := math.Round((t-y)*scale) / scale
+
+		trend := "same"
+		switch {
+		case delta == 0:
+		case m.better == 0:
+			trend = "changed"
+		case (delta > 0) == (m.better > 0):
+			trend = "better"
+		default:
+			trend = "worse"
+		}
+
+		summary := fmt.Sprintf("%s %+.*f", m.label, m.decimals, delta)
+		if delta == 0 {
+			summary = m.label + " unchanged"
+		} else if m.unit != "" {
+			summary += " " + m.unit
+		}
+		changes = append(changes, Change{
+			Metric:    m.path,
+			Label:     m.label,
+			Today:     t,
+			Yesterday: y,
+			Delta:     delta,
+			Trend:     trend,
+			Summary:   summary,
+		})
+	}
+	return changes, nil
+}
+
+// compareWithYesterday loads the previous day's stored briefing for mode and
+// diffs the given briefing against it
+func compareWithYesterday(db *sql.DB, mode, date string, briefing any, metrics []compareMetric) ([]Change, error) {
+	prev, err := loadHistoryJSON(db, yesterday(date), mode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no %s briefing stored for %s", mode, yesterday(date))
+	}
+	if err != nil {
+		return nil, err
+	}
+	curr, _ := json.Marshal(briefing)
+	return compareBriefings(curr, prev, metrics)
+}
+
+// compareFromHistory opens the history store and compares against yesterday
+func compareFromHistory(mode, date string, briefing any, metrics []compareMetric) ([]Change, error) {
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return compareWithYesterday(db, mode, date, briefing, metrics)
+}
+
+// changeSummaries lists the changes that moved, e.g. "HRV +8 ms (better)"
+func changeSummaries(changes []Change) []string {
+	var lines []string
+	for _, c := range changes {
+		if c.Trend == "same" {
+			continue
+		}
+		line := c.Summary
+		if c.Trend != "changed" {
+			line += " (" + c.Trend + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// changesLine joins the moved changes for one-line renderers
+func changesLine(changes []Change) string {
+	return strings.Join(changeSummaries(changes), ", ")
+}
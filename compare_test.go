@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== COMPARE TESTS ====================
+
+func TestCompareBriefings(t *testing.T) {
+	yesterday := MorningBriefing{
+		TargetDate:     "2024-01-14",
+		Sleep:          SleepData{TotalHours: ptr(7.9), DeepHours: ptr(1.5)},
+		Vitals:         VitalsData{HRV: ptr(40), RestingHR: ptr(55)},
+		Classification: Classification{ReadinessScore: intPtr(80)},
+	}
+	today := MorningBriefing{
+		TargetDate:     "2024-01-15",
+		Sleep:          SleepData{TotalHours: ptr(6.7)},
+		Vitals:         VitalsData{HRV: ptr(48), RestingHR: ptr(55)},
+		Classification: Classification{ReadinessScore: intPtr(72)},
+		Meds:           MedsData{Overdue: []MedTask{{Name: "Vitamin D"}}},
+	}
+	curr, _ := json.Marshal(today)
+	prev, _ := json.Marshal(yesterday)
+
+	changes, err := compareBriefings(curr, prev, morningCompareMetrics)
+	if err != nil {
+		t.Fatalf("compareBriefings() error: %v", err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Summary+" "+c.Trend)
+	}
+	// Deep sleep is missing today so it is skipped; nothing overdue yesterday counts as 0
+	want := []string{
+		"Sleep -1.2 h worse",
+		"HRV +8 ms better",
+		"Resting HR unchanged same",
+		"Readiness -8 worse",
+		"Overdue meds +1 worse",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+	if changes[0].Today != 6.7 || changes[0].Yesterday != 7.9 || changes[0].Delta != -1.2 {
+		t.Errorf("sleep change = %+v, want 6.7 vs 7.9 rounded to -1.2", changes[0])
+	}
+}
+
+func TestCompareBriefingsEvening(t *testing.T) {
+	yesterday := EveningBriefing{
+		Energy:    EnergyData{DeficitOrSurplusKcal: -200},
+		Activity:  ActivityData{Steps: 9000},
+		Protocols: ProtocolsData{Missed: []string{"Stretch", "Journal"}},
+		Recovery:  RecoveryData{HRVMS: 42},
+	}
+	today := EveningBriefing{
+		Energy:    EnergyData{DeficitOrSurplusKcal: 150},
+		Activity:  ActivityData{Steps: 12000},
+		Protocols: ProtocolsData{Missed: []string{}},
+	}
+	curr, _ := json.Marshal(today)
+	prev, _ := json.Marshal(yesterday)
+
+	changes, err := compareBriefings(curr, prev, eveningCompareMetrics)
+	if err != nil {
+		t.Fatalf("compareBriefings() error: %v", err)
+	}
+	got := changesLine(changes)
+	// Energy balance has no better direction; HRV 0 means no reading today
+	want := "Energy balance +350 kcal, Steps +3000 (better), Missed protocols -2 (better)"
+	if got != want {
+		t.Errorf("changesLine() = %q, want %q", got, want)
+	}
+	for _, c := range changes {
+		if c.Metric == "recovery.hrv_ms" {
+			t.Errorf("HRV compared without a reading today: %+v", c)
+		}
+	}
+}
+
+func TestCompareBriefingsInvalidJSON(t *testing.T) {
+	if _, err := compareBriefings([]byte("{"), []byte("{}"), morningCompareMetrics); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestCompareWithYesterday(t *testing.T) {
+	db := newTestHistoryDB(t)
+	today := MorningBriefing{TargetDate: "2024-01-15", Vitals: VitalsData{HRV: ptr(50)}}
+
+	_, err := compareWithYesterday(db, "morning", today.TargetDate, today, morningCompareMetrics)
+	if err == nil || !strings.Contains(err.Error(), "no morning briefing stored for 2024-01-14") {
+		t.Errorf("error = %v, want no stored briefing for 2024-01-14", err)
+	}
+
+	prev := MorningBriefing{TargetDate: "2024-01-14", GeneratedAt: "2024-01-14T07:30:00+07:00", Vitals: VitalsData{HRV: ptr(42)}}
+	if _, err := saveHistoryRecord(db, morningHistoryRecord(prev, HistorySourceLive), false); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := compareWithYesterday(db, "morning", today.TargetDate, today, morningCompareMetrics)
+	if err != nil {
+		t.Fatalf("compareWithYesterday() error: %v", err)
+	}
+	if got := changesLine(changes); got != "HRV +8 ms (better)" {
+		t.Errorf("changesLine() = %q, want HRV +8 ms (better)", got)
+	}
+}
+
+func TestChangeRendering(t *testing.T) {
+	m := MorningBriefing{
+		TargetDate: "2024-01-15",
+		Changes: []Change{
+			{Label: "HRV", Trend: "better", Summary: "HRV +8 ms"},
+			{Label: "Sleep", Trend: "worse", Summary: "Sleep -1.2 h"},
+			{Label: "Resting HR", Trend: "same", Summary: "Resting HR unchanged"},
+		},
+	}
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Since Yesterday\n\n- HRV +8 ms (better)\n- Sleep -1.2 h (worse)\n") {
+		t.Errorf("markdown missing changes section:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Since yesterday: HRV +8 ms (better), Sleep -1.2 h (worse)") {
+		t.Errorf("text missing changes line:\n%s", text)
+	}
+
+	sections := pageChanges(m.Changes)
+	if len(sections) != 1 || len(sections[0].Items) != 2 || !sections[0].Items[1].Alert {
+		t.Errorf("pageChanges() = %+v, want two items with the worse one flagged", sections)
+	}
+	if pageChanges(nil) != nil {
+		t.Error("pageChanges(nil) should add no section without --compare")
+	}
+	if md := MorningMarkdown(MorningBriefing{}); strings.Contains(md, "Since Yesterday") {
+		t.Error("markdown shows changes without --compare")
+	}
+}
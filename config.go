@@ -0,0 +1,713 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Config holds optional settings loaded from the config file
+type Config struct {
+	HealthDB       string                `json:"health_db"` // empty uses ~/.health-ingest/health.db
+	Timezone       string                `json:"timezone"`  // IANA zone for dates and times, e.g. Europe/Berlin; empty uses the system zone
+	Calendars      []CalendarAccount     `json:"calendars"`
+	MedLabels      []string              `json:"med_labels"`     // Todoist labels marking med/protocol tasks
+	MedPatterns    []string              `json:"med_patterns"`   // regexes matched against task labels and content
+	MedProjects    []string              `json:"med_projects"`   // Todoist project IDs holding med/protocol tasks
+	MedCategories  MedCategoriesConfig   `json:"med_categories"` // labels sorting med tasks into medications, supplements, injections
+	AlcoholLabels  []string              `json:"alcohol_labels"` // Todoist labels marking a day with alcohol
+	User           UserConfig            `json:"user"`
+	Sources        SourcesConfig         `json:"sources"`
+	MQTT           MQTTConfig            `json:"mqtt"`
+	Webhooks       []WebhookConfig       `json:"webhooks"`
+	Rules          []RuleConfig          `json:"rules"`
+	Retention      RetentionConfig       `json:"retention"`
+	Encryption     EncryptionConfig      `json:"encryption"`
+	AutoMode       AutoModeConfig        `json:"auto_mode"`
+	Archive        ArchiveConfig         `json:"archive"`
+	Questionnaire  QuestionnaireConfig   `json:"questionnaire"`
+	DerivedMetrics DerivedMetricsConfig  `json:"derived_metrics"`
+	Delivery       DeliveryConfig        `json:"delivery"`
+	Schedule       ScheduleConfig        `json:"schedule"`
+	Narrate        NarrateConfig         `json:"narrate"`
+	Prompt         PromptConfig          `json:"prompt"`
+	BloodPressure  BloodPressureConfig   `json:"blood_pressure"`
+	Rings          RingsConfig           `json:"rings"`
+	Micronutrients []MicronutrientConfig `json:"micronutrients"`
+	Inventory      []InventoryItem       `json:"inventory"` // med stock for refill reminders
+	Cycles         []CycleConfig         `json:"cycles"`    // compound on/off cycles and titrations
+	Supplements    SupplementsConfig     `json:"supplements"`
+	Focus          FocusConfig           `json:"focus"`
+	Todoist        TodoistConfig         `json:"todoist"`
+	Google         GoogleConfig          `json:"google"`
+	CalendarCache  CalendarCacheConfig   `json:"calendar_cache"`
+	AllDay         AllDayConfig          `json:"all_day"`
+	TrainingHours  TrainingHoursConfig   `json:"training_hours"`
+	WorkHours      WorkHoursConfig       `json:"work_hours"`
+	EatingWindow   EatingWindowConfig    `json:"eating_window"`
+	Travel         TravelConfig          `json:"travel"`
+	Weather        WeatherConfig         `json:"weather"`
+	AirQuality     AirQualityConfig      `json:"air_quality"`
+	Pollen         PollenConfig          `json:"pollen"`
+	GitHub         GitHubConfig          `json:"github"`
+	Mail           MailConfig            `json:"mail"`
+	Slack          SlackUnreadConfig     `json:"slack"`
+	News           NewsConfig            `json:"news"`
+	Markets        MarketsConfig         `json:"markets"`
+	OnCall         OnCallConfig          `json:"oncall"`
+	Trips          TripsConfig           `json:"trips"`
+}
+
+// CalendarAccount is a Google Calendar account; Source labels its events.
+// With a refresh token the Calendar API is read directly, otherwise gog.
+type CalendarAccount struct {
+	Account         string `json:"account"`
+	Source          string `json:"source"` // personal, work, ...
+	RefreshToken    string `json:"refresh_token"`
+	KeychainService string `json:"keychain_service"` // keychain entry holding the refresh token when refresh_token is empty
+}
+
+// UserConfig holds the personal stats behind the energy, protein, and water targets
+type UserConfig struct {
+	Age              int     `json:"age"`
+	WeightKg         float64 `json:"weight_kg"`
+	HeightCm         float64 `json:"height_cm"`
+	Male             bool    `json:"male"`
+	ProteinGPerKg    float64 `json:"protein_g_per_kg"` // protein target per kg of current weight
+	ProteinTargetG   int     `json:"protein_target_g"` // fixed target, 0 derives it from weight
+	WaterTargetMl    int     `json:"water_target_ml"`
+	SleepTargetHours float64 `json:"sleep_target_hours"`
+	MaxHR            int     `json:"max_hr"`           // 0 estimates it as 220 - age
+	Zone2TargetMin   int     `json:"zone2_target_min"` // weekly Zone 2 minutes, 0 turns the target off
+	StepGoal         int     `json:"step_goal"`
+	SedentarySteps   int     `json:"sedentary_steps"`   // trailing daily average that flags SEDENTARY
+	GoalWeightKg     float64 `json:"goal_weight_kg"`    // 0 turns goal tracking off
+	GoalDeficitKcal  int     `json:"goal_deficit_kcal"` // daily deficit the remaining calorie budget aims for
+}
+
+// ProteinTarget is the daily protein target: the fixed protein_target_g when
+// set, otherwise protein_g_per_kg times weightKg (weight_kg when 0)
+func (u UserConfig) ProteinTarget(weightKg float64) int {
+	if u.ProteinTargetG > 0 {
+		return u.ProteinTargetG
+	}
+	if weightKg <= 0 {
+		weightKg = u.WeightKg
+	}
+	return int(math.Round(u.ProteinGPerKg * weightKg))
+}
+
+// BMRKcal is the user's Mifflin-St Jeor basal metabolic rate
+func (u UserConfig) BMRKcal() int {
+	return CalculateBMR(u.WeightKg, u.HeightCm, u.Age, u.Male)
+}
+
+// SourcesConfig turns off individual morning data sources
+type SourcesConfig struct {
+	Disabled []string `json:"disabled"` // health-ingest, health-db, calendar, todoist, focus, hevy, weather, air-quality, pollen, github, gmail, slack, news, markets, oncall
+}
+
+type MQTTConfig struct {
+	Broker      string `json:"broker"` // host:port, empty disables publishing
+	ClientID    string `json:"client_id"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	TopicPrefix string `json:"topic_prefix"`
+	Retain      bool   `json:"retain"`
+}
+
+type WebhookConfig struct {
+	URL      string   `json:"url"`
+	Triggers []string `json:"triggers"` // "field=VALUE", empty uses DefaultWebhookTriggers
+}
+
+// RetentionConfig limits how long local data is kept (0 keeps forever)
+type RetentionConfig struct {
+	HistoryDays int `json:"history_days"` // history.db rows by briefing date
+	DumpDays    int `json:"dump_days"`    // raw payload dumps by file age
+}
+
+// EncryptionConfig enables sealing sensitive local data (history JSON, raw dumps)
+type EncryptionConfig struct {
+	Enabled         bool   `json:"enabled"`
+	KeychainService string `json:"keychain_service"` // keychain entry holding the key
+}
+
+// AutoModeConfig sets the local times at which auto mode switches briefings
+type AutoModeConfig struct {
+	MiddayFrom  string `json:"midday_from"`  // HH:MM, morning before this
+	EveningFrom string `json:"evening_from"` // HH:MM, midday before this
+}
+
+// ArchiveConfig commits each briefing (JSON + Markdown) to a local git repo
+type ArchiveConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"` // repo path, empty uses <data dir>/archive
+}
+
+// QuestionnaireConfig asks the morning readiness questions on the first terminal run of the day
+type QuestionnaireConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// DerivedMetricsConfig writes computed values (readiness, sleep score, sleep debt, TDEE) back to health.db
+type DerivedMetricsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// DeliveryConfig sends rendered briefings to email, chat, and push channels
+type DeliveryConfig struct {
+	Modes    map[string][]string `json:"modes"` // mode -> channels used when --deliver isn't given
+	Email    EmailConfig         `json:"email"`
+	Telegram TelegramConfig      `json:"telegram"`
+	Slack    SlackConfig         `json:"slack"`
+	Discord  DiscordConfig       `json:"discord"`
+	Ntfy     NtfyConfig          `json:"ntfy"`
+	Pushover PushoverConfig      `json:"pushover"`
+}
+
+// EmailConfig is the SMTP account briefings are mailed from
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"` // 587 (STARTTLS) by default, 465 for implicit TLS
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// TelegramConfig is the bot and chat briefings are posted to
+type TelegramConfig struct {
+	BotToken string `json:"bot_token"`
+	ChatID   string `json:"chat_id"` // numeric id (as a string) or @channelname
+	Format   string `json:"format"`  // text (default) or markdown
+}
+
+// SlackConfig is the incoming webhook briefings are posted to
+type SlackConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// DiscordConfig is the channel webhook briefings are posted to
+type DiscordConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// NtfyConfig is the ntfy topic push notifications are published to
+type NtfyConfig struct {
+	Server string `json:"server"` // https://ntfy.sh by default
+	Topic  string `json:"topic"`
+	Token  string `json:"token"` // access token for protected topics
+}
+
+// PushoverConfig is the Pushover application and user push notifications go to
+type PushoverConfig struct {
+	Token string `json:"token"` // application API token
+	User  string `json:"user"`  // user or group key
+}
+
+// ScheduleConfig holds the cron expressions `briefing daemon` runs each mode on; empty skips the mode
+type ScheduleConfig struct {
+	Morning string `json:"morning"` // e.g. "30 6 * * *"
+	Midday  string `json:"midday"`
+	Evening string `json:"evening"`
+}
+
+// NarrateConfig is the LLM backend that writes the morning narrative
+type NarrateConfig struct {
+	Enabled bool   `json:"enabled"` // narrate every morning run, not just with --narrate
+	Backend string `json:"backend"` // ollama (default) or openai
+	URL     string `json:"url"`     // base URL, e.g. http://localhost:11434 or https://api.openai.com
+	Model   string `json:"model"`
+	APIKey  string `json:"api_key"`
+}
+
+// PromptConfig tunes --format=prompt
+type PromptConfig struct {
+	MaxTokens int               `json:"max_tokens"` // estimated budget, PromptDefaultMaxTokens when 0
+	Templates map[string]string `json:"templates"`  // mode -> text/template file defining "system" and "user"
+}
+
+// BloodPressureConfig is the range readings are checked against (mmHg)
+type BloodPressureConfig struct {
+	SystolicMin  float64 `json:"systolic_min"`
+	SystolicMax  float64 `json:"systolic_max"`
+	DiastolicMin float64 `json:"diastolic_min"`
+	DiastolicMax float64 `json:"diastolic_max"`
+}
+
+// RingsConfig holds the Apple activity ring goals
+type RingsConfig struct {
+	MoveKcal    int `json:"move_kcal"`
+	ExerciseMin int `json:"exercise_min"`
+	StandHours  int `json:"stand_hours"`
+}
+
+// TodoistConfig is the Todoist API account; with no token the td CLI is used,
+// and td stays the fallback when the API fails
+type TodoistConfig struct {
+	APIToken        string `json:"api_token"`
+	KeychainService string `json:"keychain_service"` // keychain entry holding the token when api_token is empty
+	URL             string `json:"url"`              // API base
+}
+
+// GoogleConfig is the OAuth client the calendar accounts' refresh tokens were issued to
+type GoogleConfig struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	TokenURL     string `json:"token_url"`
+	CalendarURL  string `json:"calendar_url"` // Calendar API base
+	GmailURL     string `json:"gmail_url"`    // Gmail API base
+}
+
+// CalendarCacheConfig keeps fetched calendar events on disk between runs
+type CalendarCacheConfig struct {
+	TTLMinutes int `json:"ttl_minutes"` // 0 fetches every run
+}
+
+// AllDayConfig sets which all-day events weigh on the morning
+type AllDayConfig struct {
+	LoadKeywords []string `json:"load_keywords"` // case-insensitive summary substrings that raise the morning load
+}
+
+// TrainingHoursConfig is the window the morning's best workout slot is found in
+type TrainingHoursConfig struct {
+	Start      string `json:"start"`       // HH:MM
+	End        string `json:"end"`         // HH:MM
+	MinMinutes int    `json:"min_minutes"` // shorter free blocks aren't suggested
+}
+
+// WorkHoursConfig is the window the morning's focus time is counted in
+type WorkHoursConfig struct {
+	Start      string  `json:"start"`       // HH:MM
+	End        string  `json:"end"`         // HH:MM
+	MinMinutes int     `json:"min_minutes"` // shorter gaps between events don't count
+	LowHours   float64 `json:"low_hours"`   // less focus time than this is called out
+}
+
+// EatingWindowConfig is the time-restricted eating window the weekly report
+// checks each day's meals against
+type EatingWindowConfig struct {
+	Start string `json:"start"` // HH:MM, first meal no earlier
+	End   string `json:"end"`   // HH:MM, last meal no later
+}
+
+// TravelConfig routes from home to in-person events; empty Home turns it off
+type TravelConfig struct {
+	Home          string `json:"home"`           // address, or "lat,lon"
+	Provider      string `json:"provider"`       // osrm (default) or google
+	Mode          string `json:"mode"`           // driving (default), or transit with google
+	WarnBefore    string `json:"warn_before"`    // HH:MM; earlier departures are flagged
+	BufferMinutes int    `json:"buffer_minutes"` // added to the travel time
+	OSRMURL       string `json:"osrm_url"`
+	GeocodeURL    string `json:"geocode_url"` // Nominatim
+	GoogleAPIKey  string `json:"google_api_key"`
+	GoogleURL     string `json:"google_url"` // Maps API base
+}
+
+// WeatherConfig is where today's forecast is for; empty Location turns it off
+type WeatherConfig struct {
+	Location   string  `json:"location"`    // address, or "lat,lon"
+	URL        string  `json:"url"`         // Open-Meteo API base
+	RainChance int     `json:"rain_chance"` // %, at or above which training moves indoors
+	HotC       float64 `json:"hot_c"`       // highs at or above this move training indoors
+	ColdC      float64 `json:"cold_c"`      // and highs at or below this
+}
+
+// AirQualityConfig reads the AQI at weather.location from IQAir; empty APIKey
+// turns it off
+type AirQualityConfig struct {
+	APIKey       string `json:"api_key"`
+	URL          string `json:"url"`           // IQAir API base
+	UnhealthyAQI int    `json:"unhealthy_aqi"` // US AQI at or above which training moves indoors
+}
+
+// PollenConfig reads the pollen forecast at weather.location from Open-Meteo
+type PollenConfig struct {
+	Enabled bool     `json:"enabled"`
+	URL     string   `json:"url"`   // Open-Meteo air quality API base
+	Types   []string `json:"types"` // the ones you react to; empty means all
+}
+
+// GitHubConfig lists pull requests for the morning Work section; empty Token
+// turns it off
+type GitHubConfig struct {
+	Token    string `json:"token"`     // personal access token with repo read access
+	URL      string `json:"url"`       // API base, for GitHub Enterprise
+	MaxItems int    `json:"max_items"` // per list
+}
+
+// MailConfig summarizes important and starred unread Gmail in the calendar
+// accounts that have a refresh token
+type MailConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Since    string `json:"since"`     // HH:MM yesterday; mail received earlier is left out
+	MaxItems int    `json:"max_items"` // messages listed; the rest are only counted
+	Redact   bool   `json:"redact"`    // hash senders and subjects in every output
+}
+
+// SlackUnreadConfig lists the Slack workspaces whose unread DMs and mentions
+// are summarized; it is separate from delivery.slack, which posts briefings
+type SlackUnreadConfig struct {
+	Workspaces   []SlackWorkspace `json:"workspaces"`
+	URL          string           `json:"url"`           // Web API base
+	WarnMentions int              `json:"warn_mentions"` // unread mentions in one channel that make the recommendation; 0 never
+}
+
+// SlackWorkspace is a workspace read with a user token (xoxp-)
+type SlackWorkspace struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+// NewsConfig picks the headlines for the morning News section
+type NewsConfig struct {
+	Feeds    []NewsFeed `json:"feeds"`
+	MaxItems int        `json:"max_items"` // headlines in all
+	PerFeed  int        `json:"per_feed"`  // from each feed, unless it sets its own max_items
+}
+
+// NewsFeed is an RSS or Atom feed; Name labels its headlines
+type NewsFeed struct {
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	MaxItems int      `json:"max_items"` // 0 uses news.per_feed
+	Include  []string `json:"include"`   // keep only titles containing one of these
+	Exclude  []string `json:"exclude"`   // drop titles containing any of these
+}
+
+// MarketsConfig is the watchlist for the morning Markets section, left out
+// unless Enabled
+type MarketsConfig struct {
+	Enabled   bool     `json:"enabled"`
+	Watchlist []string `json:"watchlist"` // Yahoo Finance symbols: AAPL, ^GSPC, BTC-USD
+	URL       string   `json:"url"`       // quotes API base
+}
+
+// OnCallConfig reads your on-call schedules from PagerDuty or Opsgenie; empty
+// APIKey turns it off
+type OnCallConfig struct {
+	Provider  string   `json:"provider"` // pagerduty (default) or opsgenie
+	APIKey    string   `json:"api_key"`
+	URL       string   `json:"url"`       // API base; the provider's by default
+	User      string   `json:"user"`      // PagerDuty user ID, or Opsgenie username
+	Schedules []string `json:"schedules"` // PagerDuty schedule IDs to add, or the Opsgenie schedule names to read
+}
+
+// TripsConfig sets how far ahead flights and hotel stays are looked for
+type TripsConfig struct {
+	LookaheadDays int               `json:"lookahead_days"` // days after today the morning lists trips for
+	Airports      map[string]string `json:"airports"`       // IATA code to IANA zone, added to the built-in list
+}
+
+// FocusConfig picks the Todoist tasks for the morning Focus section
+type FocusConfig struct {
+	Filter   string `json:"filter"` // Todoist filter query
+	MaxItems int    `json:"max_items"`
+}
+
+// RuleConfig is a user-defined alert evaluated on every run
+type RuleConfig struct {
+	Name     string `json:"name"`
+	When     string `json:"when"`     // expression, e.g. "hrv < hrv_baseline * 0.7 and sleep.total < 6"
+	Notify   string `json:"notify"`   // webhook, mqtt; empty only lists the alert in the briefing
+	Severity string `json:"severity"` // low, medium (default), high
+}
+
+// DefaultConfig returns the settings used when no config file exists
+func DefaultConfig() Config {
+	return Config{
+		MedLabels:     []string{"💊Meds", "💉"},
+		MedCategories: MedCategoriesConfig{Injections: []string{"💉"}},
+		AlcoholLabels: []string{"🍷"},
+		User: UserConfig{
+			Age:              UserAge,
+			WeightKg:         UserWeightKg,
+			HeightCm:         UserHeightCm,
+			Male:             UserIsMale,
+			ProteinGPerKg:    UserProteinGPerKg,
+			WaterTargetMl:    UserWaterTargetMl,
+			SleepTargetHours: UserSleepTargetHours,
+			Zone2TargetMin:   UserZone2TargetMin,
+			StepGoal:         UserStepGoal,
+			SedentarySteps:   UserSedentarySteps,
+			GoalDeficitKcal:  UserGoalDeficitKcal,
+		},
+		MQTT: MQTTConfig{
+			ClientID:    "briefing",
+			TopicPrefix: "briefing",
+			Retain:      true,
+		},
+		Retention: RetentionConfig{
+			HistoryDays: 730,
+			DumpDays:    14,
+		},
+		Encryption: EncryptionConfig{
+			KeychainService: "morning-briefing",
+		},
+		AutoMode: AutoModeConfig{
+			MiddayFrom:  "11:00",
+			EveningFrom: "17:00",
+		},
+		Narrate: NarrateConfig{
+			Backend: NarrateOllama,
+			URL:     "http://localhost:11434",
+		},
+		BloodPressure: BloodPressureConfig{
+			SystolicMin:  90,
+			SystolicMax:  130,
+			DiastolicMin: 60,
+			DiastolicMax: 85,
+		},
+		Rings: RingsConfig{
+			MoveKcal:    500,
+			ExerciseMin: 30,
+			StandHours:  12,
+		},
+		Micronutrients: defaultMicronutrients,
+		Todoist:        TodoistConfig{URL: "https://api.todoist.com/api/v1"},
+		Google: GoogleConfig{
+			TokenURL:    "https://oauth2.googleapis.com/token",
+			CalendarURL: "https://www.googleapis.com/calendar/v3",
+			GmailURL:    "https://gmail.googleapis.com/gmail/v1",
+		},
+		AllDay: AllDayConfig{LoadKeywords: []string{"deadline"}},
+		TrainingHours: TrainingHoursConfig{
+			Start:      "06:00",
+			End:        "21:00",
+			MinMinutes: 45,
+		},
+		WorkHours: WorkHoursConfig{
+			Start:      "09:00",
+			End:        "17:00",
+			MinMinutes: 30,
+			LowHours:   2,
+		},
+		EatingWindow: EatingWindowConfig{Start: "12:00", End: "20:00"}, // 16:8
+		Travel: TravelConfig{
+			Provider:      RouteOSRM,
+			Mode:          TravelDriving,
+			WarnBefore:    "08:00",
+			BufferMinutes: 10,
+			OSRMURL:       "https://router.project-osrm.org",
+			GeocodeURL:    "https://nominatim.openstreetmap.org",
+			GoogleURL:     "https://maps.googleapis.com/maps/api",
+		},
+		Weather: WeatherConfig{
+			URL:        "https://api.open-meteo.com/v1",
+			RainChance: 50,
+			HotC:       32,
+			ColdC:      0,
+		},
+		AirQuality: AirQualityConfig{
+			URL:          "https://api.airvisual.com/v2",
+			UnhealthyAQI: 151,
+		},
+		Pollen:  PollenConfig{URL: "https://air-quality-api.open-meteo.com/v1"},
+		GitHub:  GitHubConfig{URL: "https://api.github.com", MaxItems: 10},
+		Mail:    MailConfig{Since: "18:00", MaxItems: 5},
+		Slack:   SlackUnreadConfig{URL: "https://slack.com/api", WarnMentions: 5},
+		News:    NewsConfig{MaxItems: 10, PerFeed: 3},
+		Markets: MarketsConfig{URL: "https://query1.finance.yahoo.com"},
+		OnCall:  OnCallConfig{Provider: OnCallPagerDuty},
+		Trips:   TripsConfig{LookaheadDays: 3},
+		Focus: FocusConfig{
+			Filter:   "(today | overdue) & (p1 | p2)",
+			MaxItems: 5,
+		},
+		Supplements: SupplementsConfig{
+			Wake:            "07:00",
+			FirstMeal:       "12:00",
+			Dinner:          "19:00",
+			Bedtime:         "23:00",
+			WorkoutKeywords: []string{"gym", "workout", "training"},
+		},
+		Delivery: DeliveryConfig{
+			Email: EmailConfig{Port: 587},
+			Ntfy:  NtfyConfig{Server: "https://ntfy.sh"},
+		},
+	}
+}
+
+// getConfigPath returns the config file location (BRIEFING_CONFIG overrides)
+func getConfigPath() string {
+	if path := os.Getenv("BRIEFING_CONFIG"); path != "" {
+		return path
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "morning-briefing", "config.json")
+}
+
+// getDataDir returns the directory holding briefing's own state
+// (history, caches). BRIEFING_DATA_DIR overrides.
+func getDataDir() string {
+	if dir := os.Getenv("BRIEFING_DATA_DIR"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".morning-briefing")
+}
+
+// LoadConfig reads the config file on top of defaults
+// A missing file is not an error
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("config read error: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("config parse error (%s): %w", path, err)
+	}
+	if err := validateRules(cfg.Rules); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateTimezone(cfg.Timezone); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if _, _, err := autoModeCutoffs(cfg.AutoMode); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateProfile(cfg); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateMedMatching(cfg); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateSources(cfg.Sources); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateDelivery(cfg.Delivery); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateSchedule(cfg.Schedule); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateNarrate(cfg.Narrate); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validatePrompt(cfg.Prompt); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateBloodPressure(cfg.BloodPressure); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateRings(cfg.Rings); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateMicronutrients(cfg.Micronutrients); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateInventory(cfg.Inventory); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateCycles(cfg.Cycles); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateSupplements(cfg.Supplements); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateTravel(cfg.Travel); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateTrips(cfg.Trips); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateWeather(cfg.Weather); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateAirQuality(cfg.AirQuality); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validatePollen(cfg.Pollen); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateGitHub(cfg.GitHub); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateMail(cfg.Mail); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateSlackUnread(cfg.Slack); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateNews(cfg.News); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateMarkets(cfg.Markets); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateOnCall(cfg.OnCall); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateCalendarCache(cfg.CalendarCache); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateTrainingHours(cfg.TrainingHours); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateWorkHours(cfg.WorkHours); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateEatingWindow(cfg.EatingWindow); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateAllDay(cfg.AllDay); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	if err := validateFocus(cfg.Focus); err != nil {
+		return cfg, fmt.Errorf("config error (%s): %w", path, err)
+	}
+	return cfg, nil
+}
+
+// MaxHeartRate is the configured max HR, or 220 - age when unset
+func (u UserConfig) MaxHeartRate() int {
+	if u.MaxHR > 0 {
+		return u.MaxHR
+	}
+	return 220 - u.Age
+}
+
+// validateProfile checks the calendar accounts and user stats
+func validateProfile(cfg Config) error {
+	for i, c := range cfg.Calendars {
+		if c.Account == "" || c.Source == "" {
+			return fmt.Errorf("calendars[%d]: account and source are required", i)
+		}
+		if (c.RefreshToken != "" || c.KeychainService != "") && (cfg.Google.ClientID == "" || cfg.Google.ClientSecret == "") {
+			return fmt.Errorf("calendars[%d]: google.client_id and google.client_secret are required for a refresh token", i)
+		}
+	}
+	u := cfg.User
+	if u.Age <= 0 || u.WeightKg <= 0 || u.HeightCm <= 0 || u.ProteinGPerKg <= 0 || u.WaterTargetMl <= 0 || u.SleepTargetHours <= 0 || u.StepGoal <= 0 || u.SedentarySteps <= 0 {
+		return errors.New("user: age, weight_kg, height_cm, protein_g_per_kg, water_target_ml, sleep_target_hours, step_goal, and sedentary_steps must be positive")
+	}
+	if u.ProteinTargetG < 0 || u.MaxHR < 0 || u.Zone2TargetMin < 0 || u.GoalWeightKg < 0 || u.GoalDeficitKcal < 0 {
+		return errors.New("user: protein_target_g, max_hr, zone2_target_min, goal_weight_kg, and goal_deficit_kcal must not be negative")
+	}
+	return nil
+}
+
+// settings is the config the briefing builders read accounts, med labels,
+// user stats, and the health DB path from; loadConfig replaces it
+var settings = DefaultConfig()
+
+// loadConfig reads the config file and makes it the active settings
+func loadConfig() (Config, error) {
+	cfg, err := LoadConfig(getConfigPath())
+	if err != nil {
+		return cfg, err
+	}
+	settings = cfg
+	return cfg, applyTimezone(cfg.Timezone)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ==================== CONFIG LOADING TESTS ====================
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if cfg.MQTT.Broker != "" {
+		t.Errorf("MQTT.Broker = %q, want empty (publishing disabled)", cfg.MQTT.Broker)
+	}
+	if cfg.MQTT.TopicPrefix != "briefing" {
+		t.Errorf("MQTT.TopicPrefix = %q, want %q", cfg.MQTT.TopicPrefix, "briefing")
+	}
+}
+
+func TestLoadConfigOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"mqtt": {"broker": "homeassistant.local:1883", "username": "ha"}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if cfg.MQTT.Broker != "homeassistant.local:1883" {
+		t.Errorf("MQTT.Broker = %q, want %q", cfg.MQTT.Broker, "homeassistant.local:1883")
+	}
+	if cfg.MQTT.Username != "ha" {
+		t.Errorf("MQTT.Username = %q, want %q", cfg.MQTT.Username, "ha")
+	}
+	// Unset fields keep their defaults
+	if cfg.MQTT.TopicPrefix != "briefing" {
+		t.Errorf("MQTT.TopicPrefix = %q, want %q", cfg.MQTT.TopicPrefix, "briefing")
+	}
+}
+
+func TestLoadConfigInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{mqtt: "), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig() expected error for invalid JSON, got nil")
+	}
+}
+
+func TestLoadConfigProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"health_db": "/data/health.db",
+		"calendars": [{"account": "me@example.com", "source": "personal"}],
+		"med_labels": ["meds"],
+		"user": {"age": 30, "weight_kg": 60}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if cfg.HealthDB != "/data/health.db" {
+		t.Errorf("HealthDB = %q", cfg.HealthDB)
+	}
+	if len(cfg.Calendars) != 1 || cfg.Calendars[0].Account != "me@example.com" || cfg.Calendars[0].Source != "personal" {
+		t.Errorf("Calendars = %+v", cfg.Calendars)
+	}
+	if len(cfg.MedLabels) != 1 || cfg.MedLabels[0] != "meds" {
+		t.Errorf("MedLabels = %v, want [meds]", cfg.MedLabels)
+	}
+	// Unset user stats keep their defaults
+	if cfg.User.Age != 30 || cfg.User.WeightKg != 60 || cfg.User.HeightCm != UserHeightCm || cfg.User.ProteinGPerKg != UserProteinGPerKg || cfg.User.SleepTargetHours != UserSleepTargetHours {
+		t.Errorf("User = %+v", cfg.User)
+	}
+}
+
+func TestLoadConfigProfileValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"calendar without account", `{"calendars": [{"source": "work"}]}`},
+		{"calendar without source", `{"calendars": [{"account": "me@example.com"}]}`},
+		{"calendar token without an OAuth client", `{"calendars": [{"account": "me@example.com", "source": "work", "refresh_token": "r"}]}`},
+		{"zero weight", `{"user": {"weight_kg": 0}}`},
+		{"negative protein target", `{"user": {"protein_target_g": -1}}`},
+		{"zero protein per kg", `{"user": {"protein_g_per_kg": 0}}`},
+		{"zero sleep target", `{"user": {"sleep_target_hours": 0}}`},
+		{"negative max HR", `{"user": {"max_hr": -1}}`},
+		{"negative goal weight", `{"user": {"goal_weight_kg": -75}}`},
+		{"zero step goal", `{"user": {"step_goal": 0}}`},
+		{"blood pressure min above max", `{"blood_pressure": {"systolic_min": 140}}`},
+		{"zero stand goal", `{"rings": {"stand_hours": 0}}`},
+		{"unknown timezone", `{"timezone": "Mars/Olympus_Mons"}`},
+		{"airport in an unknown zone", `{"trips": {"airports": {"KBV": "Asia/Krabi"}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(path); err == nil {
+				t.Error("LoadConfig() = nil error, want validation error")
+			}
+		})
+	}
+}
+
+func TestLoadConfigDeliveryAndScheduleValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"delivery to unknown mode", `{"delivery": {"modes": {"weekly": ["email"]}}}`},
+		{"email without host", `{"delivery": {"modes": {"morning": ["email"]}}}`},
+		{"bad cron expression", `{"schedule": {"morning": "30 6 * *"}}`},
+		{"cron value out of range", `{"schedule": {"evening": "0 24 * * *"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(path); err == nil {
+				t.Error("LoadConfig() = nil error, want validation error")
+			}
+		})
+	}
+}
+
+func TestDefaultUserBMR(t *testing.T) {
+	if got := DefaultConfig().User.BMRKcal(); got != UserBMRKcal {
+		t.Errorf("default BMRKcal() = %d, want %d", got, UserBMRKcal)
+	}
+}
+
+func TestProteinTarget(t *testing.T) {
+	u := UserConfig{WeightKg: 73, ProteinGPerKg: 2.0}
+	if got := u.ProteinTarget(80.4); got != 161 {
+		t.Errorf("ProteinTarget(80.4) = %d, want 161", got)
+	}
+	if got := u.ProteinTarget(0); got != 146 {
+		t.Errorf("ProteinTarget(0) = %d, want 146 from weight_kg", got)
+	}
+	u.ProteinTargetG = 152
+	if got := u.ProteinTarget(80.4); got != 152 {
+		t.Errorf("ProteinTarget() with a fixed target = %d, want 152", got)
+	}
+}
+
+func TestGetHealthDBPathOverride(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+
+	settings.HealthDB = "/data/health.db"
+	if got := getHealthDBPath(); got != "/data/health.db" {
+		t.Errorf("getHealthDBPath() = %q, want config override", got)
+	}
+	settings.HealthDB = ""
+	if got := getHealthDBPath(); filepath.Base(got) != "health.db" || filepath.Base(filepath.Dir(got)) != ".health-ingest" {
+		t.Errorf("getHealthDBPath() = %q, want ~/.health-ingest/health.db", got)
+	}
+}
+
+func TestIsMedTask(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.MedLabels = []string{"💊Meds", "supplements"}
+	settings.MedPatterns = []string{`^rx-`, `(?i)\bcreatine\b`}
+	settings.MedProjects = []string{"2203306141"}
+
+	tests := []struct {
+		task TodoistTask
+		want bool
+	}{
+		{TodoistTask{}, false},
+		{TodoistTask{Labels: []string{"errands"}}, false},
+		{TodoistTask{Labels: []string{"errands", "supplements"}}, true},
+		{TodoistTask{Labels: []string{"💊Meds"}}, true},
+		{TodoistTask{Labels: []string{"💉"}}, false}, // default label replaced by the config
+		{TodoistTask{Labels: []string{"rx-daily"}}, true},
+		{TodoistTask{Content: "Creatine 5g"}, true},
+		{TodoistTask{Content: "Call pharmacy", ProjectID: "2203306141"}, true},
+		{TodoistTask{Content: "Call pharmacy", ProjectID: "999"}, false},
+	}
+	for _, tt := range tests {
+		if got := isMedTask(tt.task); got != tt.want {
+			t.Errorf("isMedTask(%+v) = %v, want %v", tt.task, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigMedPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"med_patterns": ["(?i)nexium", "[unclosed"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "med_patterns[1]") {
+		t.Errorf("LoadConfig() error = %v, want med_patterns[1] rejected", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EventConflict is a pair of events that overlap
+type EventConflict struct {
+	Start  string          `json:"start"` // when the overlap begins
+	End    string          `json:"end"`
+	Events []CalendarEvent `json:"events"` // the pair, earlier first
+}
+
+// eventConflicts finds every overlapping pair among events. Declined events,
+// ones without an end time, and ongoing ones from before today (a conference,
+// a trip) are left out.
+func eventConflicts(events []CalendarEvent) []EventConflict {
+	var timed []CalendarEvent
+	for _, e := range events {
+		if e.EndTime != "" && e.Response != "declined" && !e.Ongoing {
+			timed = append(timed, e)
+		}
+	}
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].Time < timed[j].Time })
+
+	var conflicts []EventConflict
+	for i, a := range timed {
+		for _, b := range timed[i+1:] {
+			if b.Time >= a.EndTime {
+				break
+			}
+			conflicts = append(conflicts, EventConflict{Start: b.Time, End: min(a.EndTime, b.EndTime), Events: []CalendarEvent{a, b}})
+		}
+	}
+	return conflicts
+}
+
+// conflictLine formats a conflict, e.g.
+// "10:00-10:30: Standup (work) overlaps Dentist (personal)"
+func conflictLine(c EventConflict) string {
+	a, b := c.Events[0], c.Events[1]
+	return fmt.Sprintf("%s-%s: %s (%s) overlaps %s (%s)", c.Start, c.End, a.Summary, a.Source, b.Summary, b.Source)
+}
+
+// conflictLines lists the conflicts
+func conflictLines(conflicts []EventConflict) []string {
+	var lines []string
+	for _, c := range conflicts {
+		lines = append(lines, conflictLine(c))
+	}
+	return lines
+}
+
+// conflictNote is appended to the recommendation when events clash
+func conflictNote(conflicts []EventConflict) string {
+	switch len(conflicts) {
+	case 0:
+		return ""
+	case 1:
+		a, b := conflicts[0].Events[0], conflicts[0].Events[1]
+		return fmt.Sprintf(" %s and %s overlap at %s: resolve it before 9am.", a.Summary, b.Summary, conflicts[0].Start)
+	}
+	return fmt.Sprintf(" %d calendar conflicts: resolve them before 9am.", len(conflicts))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== CALENDAR CONFLICT TESTS ====================
+
+func TestEventConflicts(t *testing.T) {
+	standup := CalendarEvent{Time: "09:00", EndTime: "09:30", Summary: "Standup", Source: "work"}
+	dentist := CalendarEvent{Time: "09:15", EndTime: "10:00", Summary: "Dentist", Source: "personal"}
+	review := CalendarEvent{Time: "09:45", EndTime: "11:00", Summary: "Review", Source: "work"}
+	lunch := CalendarEvent{Time: "12:00", EndTime: "13:00", Summary: "Lunch", Source: "personal"}
+	tests := []struct {
+		name     string
+		events   []CalendarEvent
+		expected []EventConflict
+	}{
+		{"none", []CalendarEvent{standup, lunch}, nil},
+		{"back to back", []CalendarEvent{standup, {Time: "09:30", EndTime: "10:00", Summary: "1:1"}}, nil},
+		{"overlap across accounts, out of order", []CalendarEvent{dentist, lunch, standup}, []EventConflict{
+			{Start: "09:15", End: "09:30", Events: []CalendarEvent{standup, dentist}},
+		}},
+		{"chain", []CalendarEvent{standup, dentist, review}, []EventConflict{
+			{Start: "09:15", End: "09:30", Events: []CalendarEvent{standup, dentist}},
+			{Start: "09:45", End: "10:00", Events: []CalendarEvent{dentist, review}},
+		}},
+		{"nested", []CalendarEvent{{Time: "09:00", EndTime: "12:00", Summary: "Offsite"}, lunch}, nil},
+		{"contained", []CalendarEvent{{Time: "09:00", EndTime: "13:00", Summary: "Offsite"}, lunch}, []EventConflict{
+			{Start: "12:00", End: "13:00", Events: []CalendarEvent{{Time: "09:00", EndTime: "13:00", Summary: "Offsite"}, lunch}},
+		}},
+		{"declined", []CalendarEvent{standup, {Time: "09:00", EndTime: "10:00", Summary: "Dentist", Response: "declined"}}, nil},
+		{"no end time", []CalendarEvent{standup, {Time: "09:00", Summary: "Dentist"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eventConflicts(tt.events); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("eventConflicts() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConflictNote(t *testing.T) {
+	c := EventConflict{Start: "09:15", End: "09:30", Events: []CalendarEvent{{Summary: "Standup"}, {Summary: "Dentist"}}}
+	tests := []struct {
+		conflicts []EventConflict
+		expected  string
+	}{
+		{nil, ""},
+		{[]EventConflict{c}, " Standup and Dentist overlap at 09:15: resolve it before 9am."},
+		{[]EventConflict{c, c}, " 2 calendar conflicts: resolve them before 9am."},
+	}
+	for _, tt := range tests {
+		if got := conflictNote(tt.conflicts); got != tt.expected {
+			t.Errorf("conflictNote(%d) = %q, want %q", len(tt.conflicts), got, tt.expected)
+		}
+	}
+}
+
+func TestConflictRendering(t *testing.T) {
+	standup := CalendarEvent{Time: "09:00", EndTime: "09:30", Summary: "Standup", Source: "work"}
+	dentist := CalendarEvent{Time: "09:15", EndTime: "10:00", Summary: "Dentist", Source: "personal"}
+	m := MorningBriefing{
+		Sleep:    SleepData{TotalHours: ptr(7.5), DataAvailable: true},
+		Calendar: CalendarData{MorningEvents: []CalendarEvent{standup, dentist}, MorningCount: 2},
+	}
+	m.Calendar.Conflicts = eventConflicts(m.Calendar.MorningEvents)
+	classify(&m)
+	if !strings.HasSuffix(m.Classification.Recommendation, " Standup and Dentist overlap at 09:15: resolve it before 9am.") {
+		t.Errorf("recommendation = %q", m.Classification.Recommendation)
+	}
+
+	line := "09:15-09:30: Standup (work) overlaps Dentist (personal)"
+	if md := MorningMarkdown(m); !strings.Contains(md, "Conflicts:\n\n- "+line+"\n") {
+		t.Errorf("markdown missing conflict:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "  conflict "+line+"\n") {
+		t.Errorf("text missing conflict:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Conflicts:\n- "+line+"\n") {
+		t.Errorf("prompt missing conflict (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["calendar.conflicts"] != 1.0 {
+		t.Errorf("calendar.conflicts = %v", vars["calendar.conflicts"])
+	}
+	if r := RedactMorningBriefing(m); r.Calendar.Conflicts[0].Events[0].Summary == "Standup" || m.Calendar.Conflicts[0].Events[0].Summary != "Standup" {
+		t.Errorf("redacted conflicts = %+v", r.Calendar.Conflicts)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"database/sql"
+	"math"
+	"time"
+)
+
+// Sleep consistency settings
+const (
+	SleepConsistencyNights    = 14  // nights including last night
+	SleepConsistencyMinNights = 5   // fewer sessions than this leave it unset
+	SleepConsistentSDMinutes  = 45  // mean of bedtime and wake SD at or below this is CONSISTENT
+	SleepConsistencyZeroSD    = 120 // mean SD in minutes that scores 0
+)
+
+// sleepSessionLayout is how Health Auto Export writes sleepStart/sleepEnd
+const sleepSessionLayout = "2006-01-02 15:04:05 -0700"
+
+// sleepSession is one night's sleep start and end
+type sleepSession struct {
+	Start time.Time
+	End   time.Time
+}
+
+// querySleepSessions reads the sleep window behind each night's sleep_total
+// (its raw_json's sleepStart/sleepEnd), keeping the longest record per wake
+// day so overlapping devices count once
+func querySleepSessions(db *sql.DB, date string, nights int) ([]sleepSession, error) {
+	rows, err := db.Query(`
+		SELECT substr(local_ts(timestamp), 1, 10), value,
+			json_extract(raw_json, '$.sleepStart'), json_extract(raw_json, '$.sleepEnd')
+		FROM metrics
+		WHERE metric_name = 'sleep_total'
+		AND local_ts(timestamp) >= ? AND local_ts(timestamp) < ?
+		AND json_valid(raw_json)
+		ORDER BY local_ts(timestamp)
+	`, addDays(date, -(nights-1)), addDays(date, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	longest := map[string]float64{}
+	byDay := map[string]sleepSession{}
+	var days []string
+	for rows.Next() {
+		var day string
+		var hours float64
+		var start, end sql.NullString
+		if err := rows.Scan(&day, &hours, &start, &end); err != nil {
+			return nil, err
+		}
+		s, errS := time.Parse(sleepSessionLayout, start.String)
+		e, errE := time.Parse(sleepSessionLayout, end.String)
+		if errS != nil || errE != nil {
+			continue
+		}
+		if prev, ok := longest[day]; ok && prev >= hours {
+			continue
+		}
+		if _, ok := longest[day]; !ok {
+			days = append(days, day)
+		}
+		longest[day] = hours
+		byDay[day] = sleepSession{Start: s.In(time.Local), End: e.In(time.Local)}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sessions := make([]sleepSession, len(days))
+	for i, day := range days {
+		sessions[i] = byDay[day]
+	}
+	return sessions, nil
+}
+
+// clockMinutes is a time of day in minutes from noon, so bedtimes either
+// side of midnight (23:30, 00:30) sit next to each other
+func clockMinutes(t time.Time) float64 {
+	m := float64(t.Hour()*60 + t.Minute())
+	if m < 12*60 {
+		m += 24 * 60
+	}
+	return m - 12*60
+}
+
+// stdDev is the population standard deviation
+func stdDev(values []float64) float64 {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
+
+// applySleepConsistency sets the bedtime and wake-time spread, the 0-100
+// score, and CONSISTENT/IRREGULAR; too few sessions leave them unset
+func applySleepConsistency(s *SleepData, sessions []sleepSession) {
+	if len(sessions) < SleepConsistencyMinNights {
+		return
+	}
+	bed := make([]float64, len(sessions))
+	wake := make([]float64, len(sessions))
+	for i, session := range sessions {
+		bed[i] = clockMinutes(session.Start)
+		// Wake times sit in the morning, so measure them from midnight
+		wake[i] = float64(session.End.Hour()*60 + session.End.Minute())
+	}
+	bedSD := math.Round(stdDev(bed))
+	wakeSD := math.Round(stdDev(wake))
+	meanSD := (bedSD + wakeSD) / 2
+	score := int(math.Round(clampScore(100 - meanSD/SleepConsistencyZeroSD*100)))
+
+	s.BedtimeSDMinutes = &bedSD
+	s.WakeSDMinutes = &wakeSD
+	s.ConsistencyScore = &score
+	s.Consistency = "CONSISTENT"
+	if meanSD > SleepConsistentSDMinutes {
+		s.Consistency = "IRREGULAR"
+	}
+}
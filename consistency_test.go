@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== SLEEP CONSISTENCY TESTS ====================
+
+func TestQuerySleepSessions(t *testing.T) {
+	db := newTestMetricsDB(t)
+	rows := []struct {
+		timestamp, source string
+		hours             float64
+		rawJSON           string
+	}{
+		{"2024-01-14 00:00:00 +0700", "iPhone", 6.5, `{"sleepStart": "2024-01-13 23:30:00 +0700", "sleepEnd": "2024-01-14 06:30:00 +0700"}`},
+		{"2024-01-15 00:00:00 +0700", "iPhone", 6.0, `{"sleepStart": "2024-01-15 00:10:00 +0700", "sleepEnd": "2024-01-15 06:20:00 +0700"}`},
+		// The Watch tracked more of the same night; it wins over the iPhone
+		{"2024-01-15 00:00:01 +0700", "Apple Watch", 7.0, `{"sleepStart": "2024-01-14 23:15:00 +0700", "sleepEnd": "2024-01-15 06:30:00 +0700"}`},
+		{"2024-01-16 00:00:00 +0700", "iPhone", 7.0, `{"totalSleep": 7}`},
+		{"2023-12-01 00:00:00 +0700", "iPhone", 8.0, `{"sleepStart": "2023-11-30 22:00:00 +0700", "sleepEnd": "2023-12-01 06:00:00 +0700"}`},
+	}
+	for _, r := range rows {
+		if _, err := db.Exec(`INSERT INTO metrics (metric_name, timestamp, value, unit, source, raw_json) VALUES ('sleep_total', ?, ?, 'hr', ?, ?)`,
+			r.timestamp, r.hours, r.source, r.rawJSON); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 2024-01-16 has no session window and 2023-12-01 is outside the 14 nights
+	sessions, err := querySleepSessions(db, "2024-01-16", SleepConsistencyNights)
+	if err != nil {
+		t.Fatalf("querySleepSessions() error: %v", err)
+	}
+	var got []string
+	for _, s := range sessions {
+		got = append(got, s.Start.Format("01-02 15:04")+" → "+s.End.Format("15:04"))
+	}
+	if want := "01-13 23:30 → 06:30, 01-14 23:15 → 06:30"; strings.Join(got, ", ") != want {
+		t.Errorf("sessions = %q, want %q", strings.Join(got, ", "), want)
+	}
+}
+
+// nights builds sessions from HH:MM bedtimes and wake times
+func nights(t *testing.T, times ...string) []sleepSession {
+	t.Helper()
+	var sessions []sleepSession
+	for i := 0; i < len(times); i += 2 {
+		start, err := time.Parse("15:04", times[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		end, err := time.Parse("15:04", times[i+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, sleepSession{Start: start, End: end})
+	}
+	return sessions
+}
+
+func TestApplySleepConsistency(t *testing.T) {
+	tests := []struct {
+		name      string
+		sessions  []sleepSession
+		wantBed   float64
+		wantWake  float64
+		wantScore int
+		want      string
+	}{
+		{
+			"same times every night",
+			nights(t, "23:00", "07:00", "23:00", "07:00", "23:00", "07:00", "23:00", "07:00", "23:00", "07:00"),
+			0, 0, 100, "CONSISTENT",
+		},
+		{
+			// Bedtimes straddle midnight: 23:30 and 00:30 are an hour apart, not 23
+			"bedtimes either side of midnight",
+			nights(t, "23:30", "07:00", "00:30", "07:00", "23:30", "07:00", "00:30", "07:00", "23:30", "07:00", "00:30", "07:00"),
+			30, 0, 88, "CONSISTENT",
+		},
+		{
+			"irregular schedule",
+			nights(t, "22:00", "05:00", "02:00", "10:00", "22:00", "05:00", "02:00", "10:00", "22:00", "05:00", "02:00", "10:00"),
+			120, 150, 0, "IRREGULAR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s SleepData
+			applySleepConsistency(&s, tt.sessions)
+			if s.ConsistencyScore == nil {
+				t.Fatal("ConsistencyScore = nil")
+			}
+			if *s.BedtimeSDMinutes != tt.wantBed || *s.WakeSDMinutes != tt.wantWake || *s.ConsistencyScore != tt.wantScore || s.Consistency != tt.want {
+				t.Errorf("got bed ±%v, wake ±%v, score %d, %s; want ±%v, ±%v, %d, %s",
+					*s.BedtimeSDMinutes, *s.WakeSDMinutes, *s.ConsistencyScore, s.Consistency, tt.wantBed, tt.wantWake, tt.wantScore, tt.want)
+			}
+		})
+	}
+
+	var s SleepData
+	applySleepConsistency(&s, nights(t, "23:00", "07:00", "23:00", "07:00"))
+	if s.ConsistencyScore != nil || s.Consistency != "" {
+		t.Errorf("with 2 nights got %+v, want unset", s)
+	}
+}
+
+func TestConsistencyRendering(t *testing.T) {
+	m := MorningBriefing{Sleep: SleepData{BedtimeSDMinutes: ptr(25), WakeSDMinutes: ptr(18), ConsistencyScore: intPtr(82), Consistency: "CONSISTENT"}}
+	want := "82 (bed ±25 min, wake ±18 min)"
+	if got := MorningText(m, textStyle{}); !strings.Contains(got, fmt.Sprintf("Rhythm   %s  CONSISTENT", want)) {
+		t.Errorf("text missing rhythm line:\n%s", got)
+	}
+	if got := MorningMarkdown(m); !strings.Contains(got, "| Consistency | "+want+" | CONSISTENT |") {
+		t.Errorf("markdown missing consistency row:\n%s", got)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ==================== CRON TESTS ====================
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"30 6 * *",
+		"30 6 * * * *",
+		"60 6 * * *",
+		"30 24 * * *",
+		"30 6 0 * *",
+		"30 6 * 13 *",
+		"30 6 * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	}
+	for _, expr := range tests {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) = nil error, want error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Monday 2024-01-15 06:00
+	from := time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"30 6 * * *", from, time.Date(2024, 1, 15, 6, 30, 0, 0, time.UTC)},
+		// Strictly after: at 06:30 the next run is tomorrow
+		{"30 6 * * *", from.Add(30 * time.Minute), time.Date(2024, 1, 16, 6, 30, 0, 0, time.UTC)},
+		{"30 6 * * *", from.Add(30*time.Minute + 20*time.Second), time.Date(2024, 1, 16, 6, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", from.Add(time.Minute), time.Date(2024, 1, 15, 6, 15, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", from, time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", from.Add(4 * time.Hour), time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC)},
+		// Weekends only: Saturday the 20th
+		{"0 8 * * 6,0", from, time.Date(2024, 1, 20, 8, 0, 0, 0, time.UTC)},
+		// 7 is Sunday too
+		{"0 8 * * 7", from, time.Date(2024, 1, 21, 8, 0, 0, 0, time.UTC)},
+		{"0 21 * * 1-5", from, time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 1st or any Friday
+		{"0 7 1 * 5", from, time.Date(2024, 1, 19, 7, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", from, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", from, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Never matches
+		{"0 0 30 2 *", from, time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) error: %v", tt.expr, err)
+		}
+		if got := c.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("ICT", 7*3600)
+	c, _ := ParseCron("30 6 * * *")
+	got := c.Next(time.Date(2024, 1, 15, 5, 0, 0, 0, loc))
+	if got.Location() != loc || got.Hour() != 6 || got.Minute() != 30 {
+		t.Errorf("Next() = %s, want 06:30 ICT", got)
+	}
+}
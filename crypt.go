@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// sealedPrefix marks data encrypted at rest; anything without it is plaintext,
+// so stores written before encryption was enabled stay readable
+const sealedPrefix = "enc:v1:"
+
+const keychainAccount = "briefing"
+
+// atRest seals the history store's briefing JSON and raw dumps.
+// nil means encryption is disabled. Set once at startup by setupEncryption.
+var atRest cipher.AEAD
+
+// setupEncryption loads the key and enables sealing when configured
+func setupEncryption(cfg EncryptionConfig) error {
+	if !cfg.Enabled {
+		atRest = nil
+		return nil
+	}
+	secret, err := loadEncryptionKey(cfg.KeychainService)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return err
+	}
+	atRest = aead
+	return nil
+}
+
+// newAEAD derives an AES-256-GCM cipher from a key string
+func newAEAD(secret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// loadEncryptionKey reads the key from BRIEFING_KEY or the OS keychain
+func loadEncryptionKey(service string) (string, error) {
+	if key := os.Getenv("BRIEFING_KEY"); key != "" {
+		return key, nil
+	}
+
+	key, err := keychainSecret(service)
+	switch {
+	case errors.Is(err, errNoKeychain):
+		return "", fmt.Errorf("encryption key error: no keychain support on %s (set BRIEFING_KEY)", runtime.GOOS)
+	case err != nil:
+		return "", fmt.Errorf("encryption key error: keychain lookup for %q failed (run `briefing keygen` or set BRIEFING_KEY): %w", service, err)
+	case key == "":
+		return "", fmt.Errorf("encryption key error: keychain entry %q is empty", service)
+	}
+	return key, nil
+}
+
+// errNoKeychain is returned on platforms without a supported keychain
+var errNoKeychain = errors.New("no keychain support")
+
+// keychainSecret reads service's entry for keychainAccount from the OS keychain
+func keychainSecret(service string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", keychainAccount, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "account", keychainAccount)
+	default:
+		return "", errNoKeychain
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// sealWith encrypts data as sealedPrefix + base64(nonce || ciphertext)
+func sealWith(aead cipher.AEAD, data []byte) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	sealed := aead.Seal(nonce, nonce, data, nil)
+	return append([]byte(sealedPrefix), base64.StdEncoding.EncodeToString(sealed)...)
+}
+
+// openWith decrypts sealed data and passes plaintext through unchanged
+func openWith(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(sealedPrefix)) {
+		return data, nil
+	}
+	if aead == nil {
+		return nil, errors.New("data is encrypted but no key is configured")
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(data[len(sealedPrefix):]))
+	if err != nil || len(raw) < aead.NonceSize() {
+		return nil, errors.New("decrypt error: malformed data")
+	}
+	plain, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("decrypt error: wrong key or corrupted data")
+	}
+	return plain, nil
+}
+
+// sealAtRest encrypts data when encryption is enabled
+func sealAtRest(data []byte) []byte {
+	if atRest == nil {
+		return data
+	}
+	return sealWith(atRest, data)
+}
+
+// openAtRest decrypts data written by sealAtRest
+func openAtRest(data []byte) ([]byte, error) {
+	return openWith(atRest, data)
+}
+
+// RunKeygenCommand handles `briefing keygen [--print]`
+// Generates a random key and stores it in the OS keychain (or prints it for BRIEFING_KEY).
+func RunKeygenCommand(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	printKey := fs.Bool("print", false, "Print the key instead of storing it in the keychain")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	key := base64.StdEncoding.EncodeToString(buf)
+
+	if *printKey {
+		fmt.Println(key)
+		return nil
+	}
+
+	service := cfg.Encryption.KeychainService
+	if existing, err := loadEncryptionKey(service); err == nil && existing != "" {
+		return fmt.Errorf("a key already exists for %q; replacing it would make encrypted data unreadable", service)
+	}
+
+	cmd, err := keychainStoreCommand(runtime.GOOS, service, key)
+	if err != nil {
+		return err
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("keychain store error: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	// security -i doesn't reliably fail when its command does, so read the key back
+	if stored, err := keychainSecret(service); err != nil || stored != key {
+		return fmt.Errorf("keychain store error: key not stored: %s", strings.TrimSpace(string(output)))
+	}
+
+	fmt.Printf("Stored a new key in the keychain as %q. Keep a copy somewhere safe: without it encrypted data cannot be recovered.\n", service)
+	return nil
+}
+
+// keychainStoreCommand returns the command saving key as service's entry.
+// The key goes in on stdin, never as an argument, so other users can't
+// read it from the process list while it runs.
+func keychainStoreCommand(goos, service, key string) (*exec.Cmd, error) {
+	switch goos {
+	case "darwin":
+		// -i reads the command itself from stdin
+		if strings.ContainsAny(service, "\"\\\n") {
+			return nil, fmt.Errorf("keychain service %q can't contain quotes, backslashes, or newlines", service)
+		}
+		cmd := exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -s \"%s\" -a %s -w %s\n", service, keychainAccount, key))
+		return cmd, nil
+	case "linux":
+		cmd := exec.Command("secret-tool", "store", "--label=morning-briefing", "service", service, "account", keychainAccount)
+		cmd.Stdin = strings.NewReader(key)
+		return cmd, nil
+	}
+	return nil, fmt.Errorf("no keychain support on %s; use --print and set BRIEFING_KEY", goos)
+}
+
+// RunDecryptCommand handles `briefing decrypt FILE`, printing a sealed dump as plaintext
+func RunDecryptCommand(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: briefing decrypt FILE")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	secret, err := loadEncryptionKey(cfg.Encryption.KeychainService)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	plain, err := openWith(aead, data)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(plain)
+	return err
+}
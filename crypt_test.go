@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// enableTestEncryption turns on at-rest sealing with a fixed key for one test
+func enableTestEncryption(t *testing.T) {
+	t.Helper()
+	t.Setenv("BRIEFING_KEY", "test-key")
+	if err := setupEncryption(EncryptionConfig{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { atRest = nil })
+}
+
+// ==================== SEAL/OPEN TESTS ====================
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	aead, err := newAEAD("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := []byte(`{"meds":{"due_today":[{"name":"Sertraline"}]}}`)
+
+	sealed := sealWith(aead, plain)
+	if !bytes.HasPrefix(sealed, []byte(sealedPrefix)) || bytes.Contains(sealed, []byte("Sertraline")) {
+		t.Fatalf("sealWith() = %q, want opaque sealed data", sealed)
+	}
+	if bytes.Equal(sealWith(aead, plain), sealed) {
+		t.Error("sealWith() reused a nonce")
+	}
+
+	got, err := openWith(aead, sealed)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("openWith() = %q, %v; want original", got, err)
+	}
+}
+
+func TestOpenWithErrors(t *testing.T) {
+	right, _ := newAEAD("right")
+	wrong, _ := newAEAD("wrong")
+	sealed := sealWith(right, []byte("secret"))
+
+	if _, err := openWith(wrong, sealed); err == nil {
+		t.Error("openWith() wrong key expected error, got nil")
+	}
+	if _, err := openWith(nil, sealed); err == nil {
+		t.Error("openWith() without key expected error, got nil")
+	}
+	if _, err := openWith(right, []byte(sealedPrefix+"!!!")); err == nil {
+		t.Error("openWith() malformed data expected error, got nil")
+	}
+
+	// Plaintext written before encryption was enabled passes through
+	got, err := openWith(right, []byte(`{"a":1}`))
+	if err != nil || string(got) != `{"a":1}` {
+		t.Errorf("openWith() plaintext = %q, %v", got, err)
+	}
+}
+
+func TestSetupEncryptionDisabled(t *testing.T) {
+	t.Cleanup(func() { atRest = nil })
+	if err := setupEncryption(EncryptionConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sealAtRest([]byte("x")); string(got) != "x" {
+		t.Errorf("sealAtRest() disabled = %q, want plaintext", got)
+	}
+}
+
+// ==================== ENCRYPTED STORES TESTS ====================
+
+func TestHistoryJSONEncrypted(t *testing.T) {
+	enableTestEncryption(t)
+	db := newTestHistoryDB(t)
+
+	b := MorningBriefing{TargetDate: "2024-01-15", Meds: MedsData{DueToday: []MedTask{{Name: "Sertraline"}}}}
+	if _, err := saveHistoryRecord(db, morningHistoryRecord(b, HistorySourceLive), false); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored string
+	db.QueryRow(`SELECT json FROM briefings`).Scan(&stored)
+	if !strings.HasPrefix(stored, sealedPrefix) || strings.Contains(stored, "Sertraline") {
+		t.Errorf("stored json = %q, want sealed", stored)
+	}
+
+	plain, err := loadHistoryJSON(db, "2024-01-15", "morning")
+	if err != nil {
+		t.Fatalf("loadHistoryJSON() error: %v", err)
+	}
+	if !strings.Contains(string(plain), "Sertraline") {
+		t.Errorf("loadHistoryJSON() = %s, want decrypted briefing", plain)
+	}
+}
+
+func TestDumpRawEncrypted(t *testing.T) {
+	enableTestEncryption(t)
+	t.Setenv("BRIEFING_DATA_DIR", t.TempDir())
+	t.Setenv("BRIEFING_DUMP", "1")
+
+	dumpRaw("todoist-today", []byte(`{"content":"Sertraline"}`))
+
+	matches, _ := filepath.Glob(filepath.Join(getDumpDir(), "*.json.enc"))
+	if len(matches) != 1 {
+		t.Fatalf("sealed dumps = %v, want 1", matches)
+	}
+	data, _ := os.ReadFile(matches[0])
+	if bytes.Contains(data, []byte("Sertraline")) {
+		t.Error("dump written in plaintext")
+	}
+	plain, err := openAtRest(data)
+	if err != nil || !bytes.Contains(plain, []byte("Sertraline")) {
+		t.Errorf("openAtRest() = %q, %v", plain, err)
+	}
+}
+
+func TestKeychainStoreCommandKeepsKeyOffArgs(t *testing.T) {
+	const key = "c2VjcmV0LWtleS1ieXRlcw=="
+	for _, goos := range []string{"darwin", "linux"} {
+		t.Run(goos, func(t *testing.T) {
+			cmd, err := keychainStoreCommand(goos, "morning-briefing", key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if slices.ContainsFunc(cmd.Args, func(a string) bool { return strings.Contains(a, key) }) {
+				t.Errorf("key on the command line: %q", cmd.Args)
+			}
+			stdin, _ := io.ReadAll(cmd.Stdin)
+			if !strings.Contains(string(stdin), key) {
+				t.Errorf("stdin = %q, want the key", stdin)
+			}
+		})
+	}
+	if cmd, _ := keychainStoreCommand("darwin", "morning-briefing", key); cmd != nil {
+		stdin, _ := io.ReadAll(cmd.Stdin)
+		if want := `add-generic-password -s "morning-briefing" -a briefing -w ` + key + "\n"; string(stdin) != want {
+			t.Errorf("security -i input = %q, want %q", stdin, want)
+		}
+	}
+	if _, err := keychainStoreCommand("darwin", `bad"service`, key); err == nil {
+		t.Error("expected an error for a quoted service name")
+	}
+	if _, err := keychainStoreCommand("windows", "morning-briefing", key); err == nil {
+		t.Error("expected an error without keychain support")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// CycleChangeSoonDays highlights a phase change this many days out or closer
+const CycleChangeSoonDays = 3
+
+// CycleNotStarted is the phase of a cycle whose start is still ahead
+const CycleNotStarted = "not started"
+
+// CycleConfig describes a compound's on/off cycle or titration schedule.
+// Phases run in order from start; a repeating cycle starts over after the
+// last, otherwise a last phase with no length continues indefinitely.
+type CycleConfig struct {
+	Name   string       `json:"name"`
+	Start  string       `json:"start"` // YYYY-MM-DD, day 1 of the first phase
+	Phases []CyclePhase `json:"phases"`
+	Repeat bool         `json:"repeat"`
+}
+
+// CyclePhase is one stretch of a cycle, weeks*7 + days long
+type CyclePhase struct {
+	Name  string `json:"name"` // on, off, 0.25 mg, ...
+	Weeks int    `json:"weeks"`
+	Days  int    `json:"days"`
+	Dose  string `json:"dose"` // free text, e.g. "2.5 mg twice weekly"
+}
+
+// CycleStatus is where a cycle stands on a date
+type CycleStatus struct {
+	Name         string `json:"name"`
+	Phase        string `json:"phase"`
+	Dose         string `json:"dose,omitempty"`
+	CycleDay     int    `json:"cycle_day"`  // day within the current round of phases
+	PhaseDay     int    `json:"phase_day"`  // day within the current phase
+	PhaseDays    int    `json:"phase_days"` // 0 when open-ended
+	NextPhase    string `json:"next_phase,omitempty"`
+	NextChange   string `json:"next_change,omitempty"` // date the next phase starts
+	DaysToChange int    `json:"days_to_change,omitempty"`
+}
+
+// length is the phase's length in days, 0 when open-ended
+func (p CyclePhase) length() int {
+	return p.Weeks*7 + p.Days
+}
+
+// validateCycles checks each cycle has a start date and phases with lengths;
+// only the last phase of a non-repeating cycle may be open-ended
+func validateCycles(cycles []CycleConfig) error {
+	for i, c := range cycles {
+		if c.Name == "" || len(c.Phases) == 0 {
+			return fmt.Errorf("cycles[%d]: name and phases are required", i)
+		}
+		if _, err := time.Parse("2006-01-02", c.Start); err != nil {
+			return fmt.Errorf("cycles[%d]: start must be YYYY-MM-DD", i)
+		}
+		for j, p := range c.Phases {
+			if p.Name == "" || p.Weeks < 0 || p.Days < 0 {
+				return fmt.Errorf("cycles[%d].phases[%d]: name is required and weeks and days must not be negative", i, j)
+			}
+			openEnded := p.length() == 0
+			if openEnded && (c.Repeat || j < len(c.Phases)-1) {
+				return fmt.Errorf("cycles[%d].phases[%d]: only the last phase of a non-repeating cycle may have no length", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// cycleStatusFor places date within the cycle; nil once a non-repeating
+// cycle has run its course
+func cycleStatusFor(c CycleConfig, date string) *CycleStatus {
+	elapsed := daysBetween(c.Start, date)
+	if elapsed < 0 {
+		first := c.Phases[0]
+		return &CycleStatus{Name: c.Name, Phase: CycleNotStarted, NextPhase: first.Name, NextChange: c.Start, DaysToChange: -elapsed}
+	}
+
+	round := 0
+	for _, p := range c.Phases {
+		round += p.length()
+	}
+	if c.Repeat {
+		elapsed %= round
+	}
+
+	offset := 0
+	for i, p := range c.Phases {
+		length := p.length()
+		if length > 0 && elapsed >= offset+length {
+			offset += length
+			continue
+		}
+		s := &CycleStatus{
+			Name:      c.Name,
+			Phase:     p.Name,
+			Dose:      p.Dose,
+			CycleDay:  elapsed + 1,
+			PhaseDay:  elapsed - offset + 1,
+			PhaseDays: length,
+		}
+		if length == 0 {
+			return s
+		}
+		next := i + 1
+		if next == len(c.Phases) {
+			if !c.Repeat {
+				return s
+			}
+			next = 0
+		}
+		s.NextPhase = c.Phases[next].Name
+		s.DaysToChange = length - s.PhaseDay + 1
+		s.NextChange = addDays(date, s.DaysToChange)
+		return s
+	}
+	return nil
+}
+
+// cyclesFor returns the status of every configured cycle on date
+func cyclesFor(cycles []CycleConfig, date string) []CycleStatus {
+	var statuses []CycleStatus
+	for _, c := range cycles {
+		if s := cycleStatusFor(c, date); s != nil {
+			statuses = append(statuses, *s)
+		}
+	}
+	return statuses
+}
+
+// changingSoon reports whether the next phase starts within CycleChangeSoonDays
+func (s CycleStatus) changingSoon() bool {
+	return s.NextPhase != "" && s.DaysToChange <= CycleChangeSoonDays
+}
+
+// cycleLine formats a cycle, e.g. "TB-500: on (2.5 mg twice weekly), day 38 of 42, off in 5 days (2024-02-12)"
+func cycleLine(s CycleStatus) string {
+	if s.Phase == CycleNotStarted {
+		return fmt.Sprintf("%s: starts %s %s (%s)", s.Name, s.NextPhase, inDays(s.DaysToChange), s.NextChange)
+	}
+	line := s.Name + ": " + s.Phase
+	if s.Dose != "" {
+		line += " (" + s.Dose + ")"
+	}
+	line += fmt.Sprintf(", day %d", s.PhaseDay)
+	if s.PhaseDays > 0 {
+		line += fmt.Sprintf(" of %d", s.PhaseDays)
+	}
+	if s.NextPhase != "" {
+		line += fmt.Sprintf(", %s %s (%s)", s.NextPhase, inDays(s.DaysToChange), s.NextChange)
+	}
+	return line
+}
+
+// inDays phrases a day count ahead, "tomorrow" or "in 5 days"
+func inDays(n int) string {
+	if n == 1 {
+		return "tomorrow"
+	}
+	return fmt.Sprintf("in %d days", n)
+}
+
+// cyclesChangingSoon counts cycles whose next phase starts within CycleChangeSoonDays
+func cyclesChangingSoon(statuses []CycleStatus) int {
+	n := 0
+	for _, s := range statuses {
+		if s.changingSoon() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== CYCLE TESTS ====================
+
+var tb500Cycle = CycleConfig{
+	Name:   "TB-500",
+	Start:  "2024-01-01",
+	Repeat: true,
+	Phases: []CyclePhase{{Name: "on", Weeks: 6, Dose: "2.5 mg twice weekly"}, {Name: "off", Weeks: 4}},
+}
+
+var titration = CycleConfig{
+	Name:   "Semaglutide",
+	Start:  "2024-01-01",
+	Phases: []CyclePhase{{Name: "0.25 mg", Weeks: 4}, {Name: "0.5 mg", Weeks: 4}, {Name: "1 mg"}},
+}
+
+func TestValidateCycles(t *testing.T) {
+	tests := []struct {
+		name        string
+		cycles      []CycleConfig
+		expectError bool
+	}{
+		{"none", nil, false},
+		{"repeating", []CycleConfig{tb500Cycle}, false},
+		{"titration", []CycleConfig{titration}, false},
+		{"bad start", []CycleConfig{{Name: "BPC-157", Start: "soon", Phases: []CyclePhase{{Name: "on", Weeks: 4}}}}, true},
+		{"no phases", []CycleConfig{{Name: "BPC-157", Start: "2024-01-01"}}, true},
+		{"open-ended in a repeat", []CycleConfig{{Name: "BPC-157", Start: "2024-01-01", Repeat: true, Phases: []CyclePhase{{Name: "on"}}}}, true},
+		{"open-ended before the end", []CycleConfig{{Name: "BPC-157", Start: "2024-01-01", Phases: []CyclePhase{{Name: "on"}, {Name: "off", Days: 3}}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCycles(tt.cycles)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateCycles() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestCycleStatusFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		cycle CycleConfig
+		date  string
+		want  *CycleStatus
+	}{
+		{"first day", tb500Cycle, "2024-01-01", &CycleStatus{Name: "TB-500", Phase: "on", Dose: "2.5 mg twice weekly", CycleDay: 1, PhaseDay: 1, PhaseDays: 42, NextPhase: "off", NextChange: "2024-02-12", DaysToChange: 42}},
+		{"last on day", tb500Cycle, "2024-02-11", &CycleStatus{Name: "TB-500", Phase: "on", Dose: "2.5 mg twice weekly", CycleDay: 42, PhaseDay: 42, PhaseDays: 42, NextPhase: "off", NextChange: "2024-02-12", DaysToChange: 1}},
+		{"off", tb500Cycle, "2024-02-12", &CycleStatus{Name: "TB-500", Phase: "off", CycleDay: 43, PhaseDay: 1, PhaseDays: 28, NextPhase: "on", NextChange: "2024-03-11", DaysToChange: 28}},
+		{"second round", tb500Cycle, "2024-03-12", &CycleStatus{Name: "TB-500", Phase: "on", Dose: "2.5 mg twice weekly", CycleDay: 2, PhaseDay: 2, PhaseDays: 42, NextPhase: "off", NextChange: "2024-04-22", DaysToChange: 41}},
+		{"not started", tb500Cycle, "2023-12-29", &CycleStatus{Name: "TB-500", Phase: CycleNotStarted, NextPhase: "on", NextChange: "2024-01-01", DaysToChange: 3}},
+		{"titration step", titration, "2024-02-05", &CycleStatus{Name: "Semaglutide", Phase: "0.5 mg", CycleDay: 36, PhaseDay: 8, PhaseDays: 28, NextPhase: "1 mg", NextChange: "2024-02-26", DaysToChange: 21}},
+		{"maintenance", titration, "2024-06-01", &CycleStatus{Name: "Semaglutide", Phase: "1 mg", CycleDay: 153, PhaseDay: 97}},
+		{"finished", CycleConfig{Name: "BPC-157", Start: "2024-01-01", Phases: []CyclePhase{{Name: "on", Weeks: 4}}}, "2024-02-01", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cycleStatusFor(tt.cycle, tt.date)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("cycleStatusFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCycleLine(t *testing.T) {
+	tests := []struct {
+		status CycleStatus
+		want   string
+	}{
+		{*cycleStatusFor(tb500Cycle, "2024-02-07"), "TB-500: on (2.5 mg twice weekly), day 38 of 42, off in 5 days (2024-02-12)"},
+		{*cycleStatusFor(tb500Cycle, "2024-02-11"), "TB-500: on (2.5 mg twice weekly), day 42 of 42, off tomorrow (2024-02-12)"},
+		{*cycleStatusFor(tb500Cycle, "2023-12-29"), "TB-500: starts on in 3 days (2024-01-01)"},
+		{*cycleStatusFor(titration, "2024-06-01"), "Semaglutide: 1 mg, day 97"},
+	}
+	for _, tt := range tests {
+		if got := cycleLine(tt.status); got != tt.want {
+			t.Errorf("cycleLine() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCycleRendering(t *testing.T) {
+	cycles := cyclesFor([]CycleConfig{tb500Cycle, titration}, "2024-02-09")
+	if len(cycles) != 2 || !cycles[0].changingSoon() || cycles[1].changingSoon() {
+		t.Fatalf("cyclesFor() = %+v, want TB-500 changing soon", cycles)
+	}
+	soon := "TB-500: on (2.5 mg twice weekly), day 40 of 42, off in 3 days (2024-02-12)"
+
+	m := MorningBriefing{Meds: MedsData{Cycles: cycles}}
+	if md := MorningMarkdown(m); !strings.Contains(md, "- **"+soon+"**\n- Semaglutide: 0.5 mg, day 12 of 28") {
+		t.Errorf("morning markdown missing cycles:\n%s", md)
+	}
+	if vars := morningRuleVars(m); vars["cycles.changing_soon"] != 1.0 {
+		t.Errorf("cycles.changing_soon = %v, want 1", vars["cycles.changing_soon"])
+	}
+
+	e := newEveningBriefing(time.Now(), "2024-02-09")
+	e.Protocols.Cycles = cycles
+	if md := EveningMarkdown(e); !strings.Contains(md, "\nCycles:\n\n- **"+soon+"**\n") {
+		t.Errorf("evening markdown missing cycles:\n%s", md)
+	}
+	if text := EveningText(e, textStyle{}); !strings.Contains(text, "Cycles\n  "+soon+"\n") {
+		t.Errorf("evening text missing cycles:\n%s", text)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// daemonCatchUp is how late a run may start, e.g. after the machine wakes
+// from sleep; runs missed by more are skipped
+const daemonCatchUp = time.Hour
+
+// daemonPoll caps each sleep so wall-clock jumps (sleep, DST) are noticed
+const daemonPoll = time.Minute
+
+// daemonJob is one scheduled mode
+type daemonJob struct {
+	mode     string
+	schedule CronSchedule
+	next     time.Time
+}
+
+// scheduleJobs parses the configured cron expressions in mode order
+func scheduleJobs(cfg ScheduleConfig, now time.Time) ([]*daemonJob, error) {
+	var jobs []*daemonJob
+	for _, s := range []struct{ mode, expr string }{
+		{"morning", cfg.Morning},
+		{"midday", cfg.Midday},
+		{"evening", cfg.Evening},
+	} {
+		if s.expr == "" {
+			continue
+		}
+		sched, err := ParseCron(s.expr)
+		if err != nil {
+			return nil, fmt.Errorf("schedule.%s: %w", s.mode, err)
+		}
+		next := sched.Next(now)
+		if next.IsZero() {
+			return nil, fmt.Errorf("schedule.%s: %q never matches", s.mode, s.expr)
+		}
+		jobs = append(jobs, &daemonJob{mode: s.mode, schedule: sched, next: next})
+	}
+	return jobs, nil
+}
+
+// validateSchedule checks the cron expressions without running anything
+func validateSchedule(cfg ScheduleConfig) error {
+	_, err := scheduleJobs(cfg, time.Now())
+	return err
+}
+
+// dueJobs returns the jobs whose time has come, and advances every due job
+// to its next run. Runs more than daemonCatchUp late are dropped.
+func dueJobs(jobs []*daemonJob, now time.Time) (run, skipped []string) {
+	for _, j := range jobs {
+		if now.Before(j.next) {
+			continue
+		}
+		if now.Sub(j.next) > daemonCatchUp {
+			skipped = append(skipped, j.mode)
+		} else {
+			run = append(run, j.mode)
+		}
+		j.next = j.schedule.Next(now)
+	}
+	return run, skipped
+}
+
+// untilNext returns how long to sleep before checking jobs again
+func untilNext(jobs []*daemonJob, now time.Time) time.Duration {
+	wait := daemonPoll
+	for _, j := range jobs {
+		if d := j.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	return max(wait, 0)
+}
+
+// RunDaemonCommand handles `briefing daemon`: it stays running and generates
+// each scheduled briefing, dispatching it to MQTT, webhooks, alerts, and the
+// mode's delivery channels as a normal run would, without printing it
+func RunDaemonCommand(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+	jobs, err := scheduleJobs(cfg.Schedule, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		return errors.New("nothing scheduled: set schedule.morning, schedule.midday, or schedule.evening in the config")
+	}
+
+	go pruneLoop(cfg.Retention)
+
+	for _, j := range jobs {
+		log.Printf("%s briefing scheduled for %s", j.mode, j.next.Format(time.RFC1123))
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	opts := RunOptions{Format: FormatNone}
+	for {
+		select {
+		case sig := <-stop:
+			log.Printf("daemon stopping (%s)", sig)
+			return nil
+		case <-time.After(untilNext(jobs, time.Now())):
+		}
+
+		run, skipped := dueJobs(jobs, time.Now())
+		for _, mode := range skipped {
+			log.Printf("skipped %s briefing: more than %s late", mode, daemonCatchUp)
+		}
+		for _, mode := range run {
+			log.Printf("running %s briefing", mode)
+			switch mode {
+			case "morning":
+				RunMorningBriefing(cfg, opts)
+			case "midday":
+				RunMiddayBriefing(cfg, opts)
+			case "evening":
+				RunEveningBriefing(cfg, opts)
+			}
+		}
+	}
+}
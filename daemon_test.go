@@ -0,0 +1,76 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// ==================== DAEMON TESTS ====================
+
+func TestScheduleJobs(t *testing.T) {
+	now := time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC)
+	jobs, err := scheduleJobs(ScheduleConfig{Morning: "30 6 * * *", Evening: "0 21 * * *"}, now)
+	if err != nil {
+		t.Fatalf("scheduleJobs() error: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].mode != "morning" || jobs[1].mode != "evening" {
+		t.Fatalf("jobs = %+v, want morning and evening", jobs)
+	}
+	if want := time.Date(2024, 1, 16, 6, 30, 0, 0, time.UTC); !jobs[0].next.Equal(want) {
+		t.Errorf("morning next = %s, want %s", jobs[0].next, want)
+	}
+
+	if _, err := scheduleJobs(ScheduleConfig{Midday: "bogus"}, now); err == nil {
+		t.Error("scheduleJobs() with bad expression = nil error")
+	}
+	if _, err := scheduleJobs(ScheduleConfig{Morning: "0 0 31 2 *"}, now); err == nil {
+		t.Error("scheduleJobs() with impossible date = nil error")
+	}
+	if jobs, err := scheduleJobs(ScheduleConfig{}, now); err != nil || len(jobs) != 0 {
+		t.Errorf("scheduleJobs(empty) = %v, %v; want no jobs", jobs, err)
+	}
+}
+
+func TestDueJobs(t *testing.T) {
+	start := time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC)
+	jobs, _ := scheduleJobs(ScheduleConfig{Morning: "30 6 * * *", Evening: "0 21 * * *"}, start)
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		wantRun     []string
+		wantSkipped []string
+	}{
+		{"before morning", start.Add(29 * time.Minute), nil, nil},
+		{"morning due", start.Add(30 * time.Minute), []string{"morning"}, nil},
+		{"morning already ran", start.Add(31 * time.Minute), nil, nil},
+		// Woke from sleep at 21:40: evening is 40 minutes late, still run
+		{"late evening", start.Add(15*time.Hour + 40*time.Minute), []string{"evening"}, nil},
+		// Asleep until 08:00 the next day: morning missed by 90 minutes
+		{"missed morning", start.Add(26 * time.Hour), nil, []string{"morning"}},
+	}
+	for _, tt := range tests {
+		run, skipped := dueJobs(jobs, tt.now)
+		if !slices.Equal(run, tt.wantRun) || !slices.Equal(skipped, tt.wantSkipped) {
+			t.Errorf("%s: dueJobs() = %v, %v; want %v, %v", tt.name, run, skipped, tt.wantRun, tt.wantSkipped)
+		}
+	}
+	if want := time.Date(2024, 1, 17, 6, 30, 0, 0, time.UTC); !jobs[0].next.Equal(want) {
+		t.Errorf("morning next after skip = %s, want %s", jobs[0].next, want)
+	}
+}
+
+func TestUntilNext(t *testing.T) {
+	now := time.Date(2024, 1, 15, 6, 29, 30, 0, time.UTC)
+	jobs, _ := scheduleJobs(ScheduleConfig{Morning: "30 6 * * *"}, now)
+	if got := untilNext(jobs, now); got != 30*time.Second {
+		t.Errorf("untilNext() = %s, want 30s", got)
+	}
+	if got := untilNext(jobs, now.Add(-time.Hour)); got != daemonPoll {
+		t.Errorf("untilNext() far from a run = %s, want %s", got, daemonPoll)
+	}
+	if got := untilNext(jobs, now.Add(time.Minute)); got != 0 {
+		t.Errorf("untilNext() past due = %s, want 0", got)
+	}
+}
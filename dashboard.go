@@ -0,0 +1,75 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Dashboard chart window
+const (
+	DashboardDefaultDays = 30
+	DashboardMaxDays     = 365
+)
+
+//go:embed dashboard/index.html
+var dashboardFS embed.FS
+
+func (s *server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	page, err := dashboardFS.ReadFile("dashboard/index.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+func (s *server) handleBriefing(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.morning.Get(time.Now()))
+}
+
+// handleRegenerate rebuilds today's briefing, bypassing the cache
+func (s *server) handleRegenerate(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.morning.Refresh(time.Now()))
+}
+
+// handleHistory returns per-day history for the last ?days= days (default 30)
+func (s *server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	days := DashboardDefaultDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > DashboardMaxDays {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be 1-" + strconv.Itoa(DashboardMaxDays)})
+			return
+		}
+		days = n
+	}
+
+	to := time.Now().Format("2006-01-02")
+	history, err := s.loadHistoryDays(addDays(to, -(days-1)), to)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if history == nil {
+		history = []HistoryDay{}
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// loadHistoryDays reads the history store, returning nothing when it doesn't exist yet
+func (s *server) loadHistoryDays(from, to string) ([]HistoryDay, error) {
+	if _, err := os.Stat(s.historyPath); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	db, err := openHistoryDB(s.historyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return queryHistoryDays(db, from, to)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== DASHBOARD TESTS ====================
+
+func TestHandleDashboard(t *testing.T) {
+	s := newTestServer(MorningBriefing{})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want text/html", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "Regenerate now") {
+		t.Error("dashboard page missing regenerate button")
+	}
+
+	// Only the root serves the page
+	req = httptest.NewRequest(http.MethodGet, "/nope", nil)
+	rec = httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /nope status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleRegenerate(t *testing.T) {
+	builds := 0
+	s := &server{
+		cfg: DefaultConfig(),
+		morning: newBriefingCache(time.Hour, func(time.Time) MorningBriefing {
+			builds++
+			return MorningBriefing{Classification: Classification{ReadinessScore: intPtr(builds)}}
+		}),
+	}
+
+	get := func(method, path string) MorningBriefing {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.routes().ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s status = %d", method, path, rec.Code)
+		}
+		var b MorningBriefing
+		if err := json.Unmarshal(rec.Body.Bytes(), &b); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	get(http.MethodGet, "/api/briefing")
+	if b := get(http.MethodGet, "/api/briefing"); *b.Classification.ReadinessScore != 1 {
+		t.Errorf("cached briefing build = %d, want 1", *b.Classification.ReadinessScore)
+	}
+	if b := get(http.MethodPost, "/api/regenerate"); *b.Classification.ReadinessScore != 2 {
+		t.Errorf("regenerated briefing build = %d, want 2", *b.Classification.ReadinessScore)
+	}
+	if b := get(http.MethodGet, "/api/briefing"); *b.Classification.ReadinessScore != 2 {
+		t.Errorf("briefing after regenerate build = %d, want 2", *b.Classification.ReadinessScore)
+	}
+}
+
+func TestHandleHistory(t *testing.T) {
+	s := newTestServer(MorningBriefing{})
+	s.historyPath = filepath.Join(t.TempDir(), "history.db")
+
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code, strings.TrimSpace(rec.Body.String())
+	}
+
+	// No history store yet
+	if code, body := get("/api/history"); code != http.StatusOK || body != "[]" {
+		t.Errorf("empty history = %d %s, want 200 []", code, body)
+	}
+
+	db, err := openHistoryDB(s.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().Format("2006-01-02")
+	for _, date := range []string{addDays(today, -40), addDays(today, -1)} {
+		b := MorningBriefing{TargetDate: date, Sleep: SleepData{TotalHours: ptr(7)}}
+		if _, err := saveHistoryRecord(db, morningHistoryRecord(b, HistorySourceLive), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	code, body := get("/api/history")
+	var days []HistoryDay
+	if err := json.Unmarshal([]byte(body), &days); err != nil || code != http.StatusOK {
+		t.Fatalf("history = %d %s", code, body)
+	}
+	if len(days) != 1 || days[0].Date != addDays(today, -1) {
+		t.Errorf("30-day history = %+v, want only yesterday", days)
+	}
+
+	for _, days := range []string{"0", "x", "1000"} {
+		if code, _ := get("/api/history?days=" + days); code != http.StatusBadRequest {
+			t.Errorf("days=%s status = %d, want %d", days, code, http.StatusBadRequest)
+		}
+	}
+}
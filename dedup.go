@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// When iPhone and Watch both write a metric, health.db holds overlapping
+// samples from each device. These tables decide how queries resolve them.
+
+// metricSourcePriority lists preferred sources per metric, best first.
+// Entries match as case-insensitive substrings of the metrics.source column;
+// a day's rows come only from the best-ranked source that has data.
+var metricSourcePriority = map[string][]string{
+	"heart_rate":              {"Watch"},
+	"resting_heart_rate":      {"Watch"},
+	"heart_rate_variability":  {"Watch"},
+	"blood_oxygen_saturation": {"Watch"},
+	"respiratory_rate":        {"Watch"},
+}
+
+// intervalUnionMetrics are counters recorded by several devices at once.
+// Day totals take, for each hour, the largest per-source sum instead of
+// adding every source together.
+var intervalUnionMetrics = map[string]bool{
+	"steps":         true,
+	"active_energy": true,
+	"stand_hours":   true,
+}
+
+// sourceRankSQL returns a SQL expression ranking a row's source for metric
+// (0 is best) and its bind arguments. Metrics without a priority rank 0.
+func sourceRankSQL(metric string) (string, []any) {
+	prefs := metricSourcePriority[metric]
+	if len(prefs) == 0 {
+		return "0", nil
+	}
+
+	var b strings.Builder
+	var args []any
+	b.WriteString("CASE")
+	for i, p := range prefs {
+		fmt.Fprintf(&b, " WHEN source LIKE ? THEN %d", i)
+		args = append(args, "%"+p+"%")
+	}
+	fmt.Fprintf(&b, " ELSE %d END", len(prefs))
+	return b.String(), args
+}
+
+// dayTotalSQL returns the query summing a metric for a date, deduplicating
+// overlapping devices for interval-union metrics. Binds metric name, date.
+func dayTotalSQL(metric string) string {
+	if !intervalUnionMetrics[metric] {
+		return `
+			SELECT COALESCE(SUM(value), 0) FROM metrics
+			WHERE metric_name = ?
+			AND local_ts(timestamp) LIKE ? || '%'
+		`
+	}
+	return `
+		SELECT COALESCE(SUM(hourly), 0) FROM (
+			SELECT MAX(total) AS hourly FROM (
+				SELECT substr(local_ts(timestamp), 1, 13) AS hour, COALESCE(source, '') AS src, SUM(value) AS total
+				FROM metrics
+				WHERE metric_name = ?
+				AND local_ts(timestamp) LIKE ? || '%'
+				GROUP BY hour, src
+			)
+			GROUP BY hour
+		)
+	`
+}
+
+// hourlyTotalsSQL returns the query summing a metric per hour ("00".."23")
+// of a date, with the same deduplication as dayTotalSQL. Binds metric name, date.
+func hourlyTotalsSQL(metric string) string {
+	if !intervalUnionMetrics[metric] {
+		return `
+			SELECT substr(local_ts(timestamp), 12, 2) AS hour, SUM(value) FROM metrics
+			WHERE metric_name = ?
+			AND local_ts(timestamp) LIKE ? || '%'
+			GROUP BY hour
+		`
+	}
+	return `
+		SELECT hour, MAX(total) FROM (
+			SELECT substr(local_ts(timestamp), 12, 2) AS hour, COALESCE(source, '') AS src, SUM(value) AS total
+			FROM metrics
+			WHERE metric_name = ?
+			AND local_ts(timestamp) LIKE ? || '%'
+			GROUP BY hour, src
+		)
+		GROUP BY hour
+	`
+}
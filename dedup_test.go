@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+)
+
+// ==================== MULTI-DEVICE DEDUP TESTS ====================
+
+func TestQueryDayTotalUnionsOverlappingDevices(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('steps', '2024-01-15 08:05:00 +0700', 1200, 'count', 'Jai''s Apple Watch'),
+		('steps', '2024-01-15 08:40:00 +0700', 300, 'count', 'Jai''s Apple Watch'),
+		('steps', '2024-01-15 08:10:00 +0700', 1400, 'count', 'Jai''s iPhone'),
+		('steps', '2024-01-15 10:00:00 +0700', 900, 'count', 'Jai''s iPhone'),
+		('dietary_energy', '2024-01-15 12:00:00 +0700', 600, 'kcal', 'MyFitnessPal'),
+		('dietary_energy', '2024-01-15 12:30:00 +0700', 200, 'kcal', 'briefing')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 08h: max(Watch 1500, iPhone 1400) + 10h: iPhone 900
+	steps, err := queryDayTotal(db, "steps", "2024-01-15")
+	if err != nil {
+		t.Fatalf("queryDayTotal() error: %v", err)
+	}
+	if steps != 2400 {
+		t.Errorf("steps = %v, want 2400", steps)
+	}
+
+	// Logged intake from different sources is additive, not duplicated
+	kcal, err := queryDayTotal(db, "dietary_energy", "2024-01-15")
+	if err != nil {
+		t.Fatalf("queryDayTotal() error: %v", err)
+	}
+	if kcal != 800 {
+		t.Errorf("dietary_energy = %v, want 800", kcal)
+	}
+}
+
+func TestQueryPrefersWatchForHeartMetrics(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('resting_heart_rate', '2024-01-15 06:00:00 +0700', 52, 'bpm', 'Jai''s Apple Watch'),
+		('resting_heart_rate', '2024-01-15 09:00:00 +0700', 61, 'bpm', 'Jai''s iPhone'),
+		('heart_rate_variability', '2024-01-15 05:00:00 +0700', 40, 'ms', 'Jai''s Apple Watch'),
+		('heart_rate_variability', '2024-01-15 06:00:00 +0700', 50, 'ms', 'Jai''s Apple Watch'),
+		('heart_rate_variability', '2024-01-15 07:00:00 +0700', 90, 'ms', 'Oura'),
+		('heart_rate_variability', '2024-01-14 07:00:00 +0700', 70, 'ms', 'Oura')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Latest overall is the iPhone reading, but the Watch wins
+	rhr, err := queryLatestValue(db, "resting_heart_rate", "2024-01-15")
+	if err != nil || rhr == nil || *rhr != 52 {
+		t.Errorf("resting HR = %v (err %v), want 52", rhr, err)
+	}
+
+	hrv, err := queryAverageHRV(db, "2024-01-15")
+	if err != nil || hrv == nil || *hrv != 45 {
+		t.Errorf("HRV = %v (err %v), want 45", hrv, err)
+	}
+
+	// Falls back to other sources on days without Watch data
+	hrv, err = queryAverageHRV(db, "2024-01-14")
+	if err != nil || hrv == nil || *hrv != 70 {
+		t.Errorf("HRV without Watch = %v (err %v), want 70", hrv, err)
+	}
+
+	// Baseline picks the preferred source per day: (45 + 70) / 2
+	baseline, err := queryHRVBaseline(db, "2024-01-16", 30, nil)
+	if err != nil || baseline == nil || *baseline != 57.5 {
+		t.Errorf("baseline = %v (err %v), want 57.5", baseline, err)
+	}
+}
+
+func TestSourceRankSQL(t *testing.T) {
+	rank, args := sourceRankSQL("steps")
+	if rank != "0" || len(args) != 0 {
+		t.Errorf("sourceRankSQL(steps) = %q, %v; want unranked", rank, args)
+	}
+
+	rank, args = sourceRankSQL("heart_rate")
+	if rank != "CASE WHEN source LIKE ? THEN 0 ELSE 1 END" {
+		t.Errorf("sourceRankSQL(heart_rate) = %q", rank)
+	}
+	if len(args) != 1 || args[0] != "%Watch%" {
+		t.Errorf("args = %v, want [%%Watch%%]", args)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Delivery channels for --deliver and delivery.modes
+const (
+	DeliverEmail    = "email"
+	DeliverTelegram = "telegram"
+	DeliverSlack    = "slack"
+	DeliverDiscord  = "discord"
+	DeliverNtfy     = "ntfy"
+	DeliverPushover = "pushover"
+)
+
+var deliveryChannels = []string{DeliverEmail, DeliverTelegram, DeliverSlack, DeliverDiscord, DeliverNtfy, DeliverPushover}
+
+// deliveryModes are the briefings that can be delivered
+var deliveryModes = []string{"morning", "midday", "evening"}
+
+// Delivery is a briefing rendered for every channel
+type Delivery struct {
+	Mode     string
+	Date     string
+	Subject  string
+	Text     string // plain terminal text, no color
+	Markdown string
+	HTML     string
+	Page     briefingPage // structured layout for Block Kit and embeds
+}
+
+// ParseDeliver splits a comma-separated --deliver list
+func ParseDeliver(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var channels []string
+	for _, ch := range strings.Split(s, ",") {
+		ch = strings.TrimSpace(ch)
+		if !slices.Contains(deliveryChannels, ch) {
+			return nil, fmt.Errorf("unknown delivery channel %q (expected: %s)", ch, strings.Join(deliveryChannels, ", "))
+		}
+		if !slices.Contains(channels, ch) {
+			channels = append(channels, ch)
+		}
+	}
+	return channels, nil
+}
+
+// validateDelivery checks the per-mode channel lists and each used channel's settings
+func validateDelivery(cfg DeliveryConfig) error {
+	for mode, channels := range cfg.Modes {
+		if !slices.Contains(deliveryModes, mode) {
+			return fmt.Errorf("delivery.modes: unknown mode %q (expected: %s)", mode, strings.Join(deliveryModes, ", "))
+		}
+		for _, ch := range channels {
+			if !slices.Contains(deliveryChannels, ch) {
+				return fmt.Errorf("delivery.modes.%s: unknown channel %q (expected: %s)", mode, ch, strings.Join(deliveryChannels, ", "))
+			}
+			if err := validateChannel(cfg, ch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateChannel checks that a channel has the settings it needs
+func validateChannel(cfg DeliveryConfig, channel string) error {
+	switch channel {
+	case DeliverEmail:
+		return validateEmail(cfg.Email)
+	case DeliverTelegram:
+		return validateTelegram(cfg.Telegram)
+	case DeliverSlack:
+		return validateSlack(cfg.Slack)
+	case DeliverDiscord:
+		return validateDiscord(cfg.Discord)
+	case DeliverNtfy:
+		return validateNtfy(cfg.Ntfy)
+	case DeliverPushover:
+		return validatePushover(cfg.Pushover)
+	}
+	return nil
+}
+
+// deliveryChannelsFor returns the --deliver channels, or the configured ones for mode
+func deliveryChannelsFor(cfg DeliveryConfig, mode string, flagged []string) []string {
+	if len(flagged) > 0 {
+		return flagged
+	}
+	return cfg.Modes[mode]
+}
+
+// deliver sends d over each channel, collecting failures
+func deliver(cfg DeliveryConfig, channels []string, d Delivery) []error {
+	var errs []error
+	for _, ch := range channels {
+		var err error
+		switch ch {
+		case DeliverEmail:
+			err = sendEmail(cfg.Email, d)
+		case DeliverTelegram:
+			err = sendTelegram(cfg.Telegram, d)
+		case DeliverSlack:
+			err = sendSlack(cfg.Slack, d)
+		case DeliverDiscord:
+			err = sendDiscord(cfg.Discord, d)
+		case DeliverNtfy:
+			err = sendNtfy(cfg.Ntfy, d)
+		case DeliverPushover:
+			err = sendPushover(cfg.Pushover, d)
+		default:
+			err = errors.New("unknown channel")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s delivery error: %w", ch, err))
+		}
+	}
+	return errs
+}
+
+// postDelivery posts v as JSON to a chat webhook
+func postDelivery(endpoint string, v any) error {
+	body, _ := json.Marshal(v)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doDelivery(req)
+}
+
+// doDelivery sends req and treats non-2xx responses as errors. Webhook URLs
+// and topics embed their secret, so unlike postJSON errors leave the URL out.
+func doDelivery(req *http.Request) error {
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func morningDelivery(b MorningBriefing) Delivery {
+	subject := "Morning briefing " + b.TargetDate
+	if b.Classification.ReadinessScore != nil {
+		subject += fmt.Sprintf(": readiness %d", *b.Classification.ReadinessScore)
+	}
+	page := morningPage(b)
+	return Delivery{
+		Mode:     "morning",
+		Date:     b.TargetDate,
+		Subject:  subject,
+		Text:     MorningText(b, textStyle{}),
+		Markdown: MorningMarkdown(b),
+		HTML:     renderHTMLPage(page),
+		Page:     page,
+	}
+}
+
+func middayDelivery(b MiddayBriefing) Delivery {
+	page := middayPage(b)
+	return Delivery{
+		Mode:     "midday",
+		Date:     b.TargetDate,
+		Subject:  "Midday check-in " + b.TargetDate,
+		Text:     MiddayText(b, textStyle{}),
+		Markdown: MiddayMarkdown(b),
+		HTML:     renderHTMLPage(page),
+		Page:     page,
+	}
+}
+
+func eveningDelivery(b EveningBriefing) Delivery {
+	page := eveningPage(b)
+	return Delivery{
+		Mode:     "evening",
+		Date:     b.TargetDate,
+		Subject:  fmt.Sprintf("Evening wrap-up %s: %+d kcal", b.TargetDate, b.Energy.DeficitOrSurplusKcal),
+		Text:     EveningText(b, textStyle{}),
+		Markdown: EveningMarkdown(b),
+		HTML:     renderHTMLPage(page),
+		Page:     page,
+	}
+}
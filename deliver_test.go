@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ==================== DELIVERY TESTS ====================
+
+func TestParseDeliver(t *testing.T) {
+	tests := []struct {
+		input       string
+		want        []string
+		expectError bool
+	}{
+		{"", nil, false},
+		{"email", []string{"email"}, false},
+		{"email, email", []string{"email"}, false},
+		{"email,telegram", []string{"email", "telegram"}, false},
+		{"fax", nil, true},
+		{"email,", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDeliver(tt.input)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("ParseDeliver(%q) expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("ParseDeliver(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidateDelivery(t *testing.T) {
+	email := EmailConfig{Host: "smtp.example.com", Port: 587, From: "briefing@example.com", To: []string{"me@example.com"}}
+	tests := []struct {
+		name        string
+		cfg         DeliveryConfig
+		expectError bool
+	}{
+		{"empty", DeliveryConfig{}, false},
+		{"morning email", DeliveryConfig{Modes: map[string][]string{"morning": {"email"}}, Email: email}, false},
+		{"unknown mode", DeliveryConfig{Modes: map[string][]string{"weekly": {"email"}}, Email: email}, true},
+		{"unknown channel", DeliveryConfig{Modes: map[string][]string{"morning": {"fax"}}}, true},
+		{"email without host", DeliveryConfig{Modes: map[string][]string{"evening": {"email"}}}, true},
+		{"slack without webhook", DeliveryConfig{Modes: map[string][]string{"midday": {"slack"}}}, true},
+		{"discord without webhook", DeliveryConfig{Modes: map[string][]string{"evening": {"discord"}}}, true},
+		{"ntfy topic", DeliveryConfig{Modes: map[string][]string{"morning": {"ntfy"}}, Ntfy: NtfyConfig{Topic: "briefing"}}, false},
+		{"pushover without user", DeliveryConfig{Modes: map[string][]string{"morning": {"pushover"}}, Pushover: PushoverConfig{Token: "app"}}, true},
+		{"telegram without chat", DeliveryConfig{Modes: map[string][]string{"morning": {"telegram"}}, Telegram: TelegramConfig{BotToken: "123:abc"}}, true},
+		// Unused channels aren't checked
+		{"email configured but unused", DeliveryConfig{Email: EmailConfig{Host: "smtp.example.com"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDelivery(tt.cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateDelivery() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestDeliveryChannelsFor(t *testing.T) {
+	cfg := DeliveryConfig{Modes: map[string][]string{"morning": {"email"}}}
+	if got := deliveryChannelsFor(cfg, "morning", nil); !slices.Equal(got, []string{"email"}) {
+		t.Errorf("configured morning channels = %v, want [email]", got)
+	}
+	if got := deliveryChannelsFor(cfg, "evening", nil); len(got) != 0 {
+		t.Errorf("evening channels = %v, want none", got)
+	}
+	if got := deliveryChannelsFor(DeliveryConfig{}, "evening", []string{"email"}); !slices.Equal(got, []string{"email"}) {
+		t.Errorf("--deliver channels = %v, want [email]", got)
+	}
+}
+
+func TestDeliverReportsChannelErrors(t *testing.T) {
+	errs := deliver(DeliveryConfig{}, []string{DeliverEmail}, Delivery{Subject: "x"})
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "email delivery error:") {
+		t.Errorf("deliver() errors = %v, want one email delivery error", errs)
+	}
+	if errs := deliver(DeliveryConfig{}, nil, Delivery{}); len(errs) != 0 {
+		t.Errorf("deliver() with no channels = %v, want none", errs)
+	}
+}
+
+func TestMorningDelivery(t *testing.T) {
+	d := morningDelivery(MorningBriefing{TargetDate: "2024-01-15", Classification: Classification{ReadinessScore: intPtr(72)}})
+	if d.Subject != "Morning briefing 2024-01-15: readiness 72" {
+		t.Errorf("Subject = %q", d.Subject)
+	}
+	if !strings.Contains(d.Text, "Morning 2024-01-15") || !strings.Contains(d.Markdown, "# Morning 2024-01-15") || !strings.Contains(d.HTML, "<html>") {
+		t.Errorf("delivery renderings incomplete: %+v", d)
+	}
+	if strings.Contains(d.Text, "\033[") {
+		t.Error("delivery text contains ANSI escapes")
+	}
+
+	e := eveningDelivery(EveningBriefing{TargetDate: "2024-01-15", Energy: EnergyData{DeficitOrSurplusKcal: -420}})
+	if e.Subject != "Evening wrap-up 2024-01-15: -420 kcal" {
+		t.Errorf("evening Subject = %q", e.Subject)
+	}
+}
+
+func TestPostDeliveryHidesURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	err := postDelivery(ts.URL+"/services/T000/B000/secret", map[string]string{})
+	if err == nil || !strings.Contains(err.Error(), "status 403: invalid_token") {
+		t.Fatalf("postDelivery() error = %v, want status and body", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the webhook URL: %v", err)
+	}
+
+	err = postDelivery("http://127.0.0.1:1/services/secret", nil)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("postDelivery() to closed port = %v, want error without URL", err)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Sleep debt windows: nights including last night
+const (
+	SleepDebtNights     = 7
+	SleepDebtLongNights = 14
+)
+
+// SleepDebtNoticeHours is the weekly debt the recommendation starts mentioning
+const SleepDebtNoticeHours = 3.0
+
+// Derived metric names written back to health.db under the briefing source
+const (
+	DerivedReadinessScore = "readiness_score"
+	DerivedSleepScore     = "sleep_score"
+	DerivedSleepDebt      = "sleep_debt"
+	DerivedTDEEEstimate   = "tdee_estimate"
+)
+
+// derivedMetric is one computed value to persist for a date
+type derivedMetric struct {
+	name  string
+	value float64
+	unit  string
+}
+
+// CalculateSleepDebt sums each night's shortfall against target; surplus
+// nights don't pay debt back
+func CalculateSleepDebt(nights []dailyValue, target float64) float64 {
+	debt := 0.0
+	for _, n := range nights {
+		if n.Value < target {
+			debt += target - n.Value
+		}
+	}
+	return debt
+}
+
+// querySleepDebt returns the sleep debt against target over the given number
+// of nights ending on date, nil when none of those nights have data
+func querySleepDebt(db *sql.DB, date string, nights int, target float64) (*float64, error) {
+	values, err := queryDailyValues(db, "sleep_total", addDays(date, -(nights-1)), date)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	debt := math.Round(CalculateSleepDebt(values, target)*10) / 10
+	return &debt, nil
+}
+
+// sleepDebtNote is the recommendation's sleep debt sentence, empty below SleepDebtNoticeHours
+func sleepDebtNote(s SleepData) string {
+	if s.SleepDebtHours == nil || *s.SleepDebtHours < SleepDebtNoticeHours {
+		return ""
+	}
+	return fmt.Sprintf(" You're %.0fh behind on sleep this week.", *s.SleepDebtHours)
+}
+
+// morningDerivedMetrics lists the computed morning values that are available
+func morningDerivedMetrics(b MorningBriefing) []derivedMetric {
+	var metrics []derivedMetric
+	if b.Classification.ReadinessScore != nil {
+		metrics = append(metrics, derivedMetric{DerivedReadinessScore, float64(*b.Classification.ReadinessScore), "score"})
+	}
+	if score := CalculateSleepScore(b.Sleep); score != nil {
+		metrics = append(metrics, derivedMetric{DerivedSleepScore, *score, "score"})
+	}
+	if b.Sleep.SleepDebtHours != nil {
+		metrics = append(metrics, derivedMetric{DerivedSleepDebt, *b.Sleep.SleepDebtHours, "hr"})
+	}
+	return metrics
+}
+
+// eveningDerivedMetrics lists the computed evening values; TDEE is skipped
+// when no active energy was recorded, since it would just be the BMR
+func eveningDerivedMetrics(b EveningBriefing) []derivedMetric {
+	if b.Energy.ActiveKcal <= 0 {
+		return nil
+	}
+	return []derivedMetric{{DerivedTDEEEstimate, b.Energy.TotalBurnedKcal, "kcal"}}
+}
+
+// writeDerivedMetrics stores metrics at local midnight of date, so re-running
+// a briefing for the same day replaces its earlier values
+func writeDerivedMetrics(date string, metrics []derivedMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	at, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return fmt.Errorf("derived metrics date error: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", getHealthDBPath())
+	if err != nil {
+		return fmt.Errorf("sqlite open error: %w", err)
+	}
+	defer db.Close()
+
+	return insertDerivedMetrics(db, at, metrics)
+}
+
+func insertDerivedMetrics(db *sql.DB, at time.Time, metrics []derivedMetric) error {
+	for _, m := range metrics {
+		if err := insertMetric(db, m.name, at, m.value, m.unit); err != nil {
+			return fmt.Errorf("%s insert error: %w", m.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ==================== DERIVED METRICS TESTS ====================
+
+func TestCalculateSleepDebt(t *testing.T) {
+	tests := []struct {
+		name   string
+		nights []dailyValue
+		want   float64
+	}{
+		{"no nights", nil, 0},
+		{"all on target", []dailyValue{{"2024-01-01", 8}, {"2024-01-02", 8.5}}, 0},
+		{"surplus doesn't repay", []dailyValue{{"2024-01-01", 6}, {"2024-01-02", 10}, {"2024-01-03", 7.5}}, 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateSleepDebt(tt.nights, 8); got != tt.want {
+				t.Errorf("CalculateSleepDebt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuerySleepDebt(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('sleep_total', '2024-01-08 06:00:00 +0700', 4, 'hr', 'Apple Watch'),
+		('sleep_total', '2024-01-09 06:00:00 +0700', 7, 'hr', 'Apple Watch'),
+		('sleep_total', '2024-01-15 06:00:00 +0700', 6.5, 'hr', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2024-01-08 falls outside the seven nights ending 2024-01-15
+	debt, err := querySleepDebt(db, "2024-01-15", SleepDebtNights, 8)
+	if err != nil {
+		t.Fatalf("querySleepDebt() error: %v", err)
+	}
+	if debt == nil || *debt != 2.5 {
+		t.Errorf("querySleepDebt() = %v, want 2.5", debt)
+	}
+
+	// Fourteen nights against a 7.5h target: 3.5 + 0.5 + 1
+	debt, err = querySleepDebt(db, "2024-01-15", SleepDebtLongNights, 7.5)
+	if err != nil {
+		t.Fatalf("querySleepDebt() error: %v", err)
+	}
+	if debt == nil || *debt != 5 {
+		t.Errorf("querySleepDebt() over 14 nights = %v, want 5", debt)
+	}
+
+	debt, err = querySleepDebt(db, "2024-03-01", SleepDebtNights, 8)
+	if err != nil || debt != nil {
+		t.Errorf("querySleepDebt() without data = %v, %v, want nil", debt, err)
+	}
+}
+
+func TestSleepDebtRecommendation(t *testing.T) {
+	tests := []struct {
+		name     string
+		debt     *float64
+		hrv      *float64
+		wantNote bool
+	}{
+		{"no debt data", nil, nil, false},
+		{"small debt", ptr(2.9), nil, false},
+		{"a night behind", ptr(6.2), nil, true},
+		{"poor recovery", ptr(4), ptr(15), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &MorningBriefing{
+				Sleep:  SleepData{TotalHours: ptr(7.5), SleepDebtHours: tt.debt, DataAvailable: true, IsCurrentDay: true},
+				Vitals: VitalsData{HRV: tt.hrv},
+			}
+			classify(b)
+			if got := contains(b.Classification.Recommendation, "behind on sleep this week"); got != tt.wantNote {
+				t.Errorf("Recommendation = %q, want sleep debt note %v", b.Classification.Recommendation, tt.wantNote)
+			}
+		})
+	}
+
+	b := &MorningBriefing{Sleep: SleepData{TotalHours: ptr(7.5), SleepDebtHours: ptr(6.2), DataAvailable: true, IsCurrentDay: true}}
+	classify(b)
+	if want := "Well rested. Attack the day. You're 6h behind on sleep this week."; b.Classification.Recommendation != want {
+		t.Errorf("Recommendation = %q, want %q", b.Classification.Recommendation, want)
+	}
+}
+
+func TestMorningDerivedMetrics(t *testing.T) {
+	b := MorningBriefing{
+		Sleep:          SleepData{TotalHours: ptr(6), SleepDebtHours: ptr(3.5), DataAvailable: true, IsCurrentDay: true},
+		Classification: Classification{ReadinessScore: intPtr(72)},
+	}
+	got := morningDerivedMetrics(b)
+	want := []derivedMetric{
+		{DerivedReadinessScore, 72, "score"},
+		{DerivedSleepScore, 75, "score"},
+		{DerivedSleepDebt, 3.5, "hr"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("morningDerivedMetrics() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("metric %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := morningDerivedMetrics(MorningBriefing{}); len(got) != 0 {
+		t.Errorf("morningDerivedMetrics() without data = %+v, want none", got)
+	}
+}
+
+func TestEveningDerivedMetrics(t *testing.T) {
+	b := newEveningBriefing(time.Now(), "2024-01-15")
+	if got := eveningDerivedMetrics(b); len(got) != 0 {
+		t.Errorf("eveningDerivedMetrics() without active energy = %+v, want none", got)
+	}
+
+	b.Energy.ActiveKcal = 500
+	b.Energy.TotalBurnedKcal = float64(b.Energy.BMRKcal) + 500
+	got := eveningDerivedMetrics(b)
+	if len(got) != 1 || got[0].name != DerivedTDEEEstimate || got[0].value != float64(UserBMRKcal+500) {
+		t.Errorf("eveningDerivedMetrics() = %+v, want TDEE %d", got, UserBMRKcal+500)
+	}
+}
+
+func TestInsertDerivedMetricsReplaces(t *testing.T) {
+	db := newTestMetricsDB(t)
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)
+
+	for _, score := range []float64{60, 75} {
+		if err := insertDerivedMetrics(db, at, []derivedMetric{{DerivedReadinessScore, score, "score"}}); err != nil {
+			t.Fatalf("insertDerivedMetrics() error: %v", err)
+		}
+	}
+
+	var count int
+	var value float64
+	var source string
+	db.QueryRow(`SELECT COUNT(*), MAX(value), MAX(source) FROM metrics WHERE metric_name = ?`, DerivedReadinessScore).Scan(&count, &value, &source)
+	if count != 1 || value != 75 || source != metricSource {
+		t.Errorf("rows = %d, value = %v, source = %q; want 1 row of 75 from %q", count, value, source, metricSource)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Discord embed limits
+const (
+	discordMaxEmbeds      = 10
+	discordMaxFields      = 25
+	discordMaxDescription = 4096
+	discordMaxFieldValue  = 1024
+	discordMaxMessage     = 6000 // characters across all of a message's embeds
+)
+
+// discordNeutralColor is the sidebar for sections without a classification
+const discordNeutralColor = 0x6e6e73
+
+// statusRank orders classifications so an embed takes its worst one
+var statusRank = map[string]int{
+	"GOOD": 1, "CLEAR": 1, "CONSISTENT": 1, "NORMAL": 1, "ON PACE": 1, "AT GOAL": 1,
+	"OK": 2, "LIGHT": 2, "ELEVATED": 2, "IRREGULAR": 2, "LOW": 2, "SEDENTARY": 2, "BEHIND PACE": 2, "MODERATE": 2, "SENSITIVE": 2,
+	"POOR": 3, "PACKED": 3, "HIGH": 3, "UNHEALTHY": 3, "VERY UNHEALTHY": 3, "HAZARDOUS": 3,
+}
+
+type discordMessage struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func validateDiscord(cfg DiscordConfig) error {
+	if cfg.WebhookURL == "" {
+		return errors.New("delivery.discord: webhook_url is required")
+	}
+	return nil
+}
+
+// worstStatus returns the lowest classification among statuses, "" if none is known
+func worstStatus(statuses ...string) string {
+	worst := ""
+	for _, s := range statuses {
+		if statusRank[s] > statusRank[worst] {
+			worst = s
+		}
+	}
+	return worst
+}
+
+// discordColor maps a classification to the same palette as the HTML email
+func discordColor(status string) int {
+	if c, ok := statusColors[status]; ok {
+		if v, err := strconv.ParseInt(strings.TrimPrefix(c, "#"), 16, 32); err == nil {
+			return int(v)
+		}
+	}
+	return discordNeutralColor
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-len("…")] + "…"
+}
+
+// discordEmbeds lays out the page as one embed per section, each sidebar
+// colored by the section's worst classification (green GOOD, red POOR) or red
+// when it has flagged items. The first embed carries the title and recommendation, colored by the worst
+// classification overall.
+func discordEmbeds(p briefingPage) []discordEmbed {
+	var all []string
+	var sections []discordEmbed
+	for _, sec := range p.Sections {
+		statuses := []string{sec.Status}
+		e := discordEmbed{Title: sec.Title}
+		if sec.Status != "" {
+			e.Title += " · " + sec.Status
+		}
+		for _, r := range sec.Rows {
+			value := r.Value
+			if r.Status != "" {
+				value += " (" + r.Status + ")"
+			}
+			statuses = append(statuses, r.Status)
+			if len(e.Fields) < discordMaxFields {
+				e.Fields = append(e.Fields, discordField{Name: r.Label, Value: truncate(value, discordMaxFieldValue), Inline: true})
+			}
+		}
+		var lines []string
+		flagged := false
+		for _, item := range sec.Items {
+			if item.Alert {
+				flagged = true
+				lines = append(lines, "⚠️ **"+item.Text+"**")
+			} else {
+				lines = append(lines, "• "+item.Text)
+			}
+		}
+		if len(sec.Rows) == 0 && len(sec.Items) == 0 && sec.Empty != "" {
+			lines = append(lines, "*"+sec.Empty+"*")
+		}
+		e.Description = truncate(strings.Join(lines, "\n"), discordMaxDescription)
+
+		// Flagged items (overdue meds, high alerts) turn the sidebar red
+		worst := worstStatus(statuses...)
+		if flagged {
+			worst = "POOR"
+		}
+		e.Color = discordColor(worst)
+		all = append(all, statuses...)
+		sections = append(sections, e)
+	}
+
+	head := discordEmbed{Title: p.Title, Description: truncate(p.Lead, discordMaxDescription), Color: discordColor(worstStatus(all...))}
+	return append([]discordEmbed{head}, sections...)
+}
+
+// discordEmbedSize is what an embed counts toward discordMaxMessage
+func discordEmbedSize(e discordEmbed) int {
+	n := len(e.Title) + len(e.Description)
+	for _, f := range e.Fields {
+		n += len(f.Name) + len(f.Value)
+	}
+	return n
+}
+
+// discordMessages packs embeds in order into as few messages as Discord
+// accepts (10 embeds and 6000 characters each), so the last sections (meds,
+// alerts) are posted after the others rather than dropped. An embed too big
+// for a message on its own loses fields and then description.
+func discordMessages(embeds []discordEmbed) []discordMessage {
+	var msgs []discordMessage
+	size := 0
+	for _, e := range embeds {
+		for discordEmbedSize(e) > discordMaxMessage && len(e.Fields) > 0 {
+			e.Fields = e.Fields[:len(e.Fields)-1]
+		}
+		if over := discordEmbedSize(e) - discordMaxMessage; over > 0 {
+			e.Description = truncate(e.Description, max(len("…"), len(e.Description)-over))
+		}
+		n := discordEmbedSize(e)
+		if len(msgs) == 0 || len(msgs[len(msgs)-1].Embeds) == discordMaxEmbeds || size+n > discordMaxMessage {
+			msgs = append(msgs, discordMessage{})
+			size = 0
+		}
+		last := &msgs[len(msgs)-1]
+		last.Embeds = append(last.Embeds, e)
+		size += n
+	}
+	return msgs
+}
+
+// sendDiscord posts the briefing to a Discord channel webhook, in several
+// messages when it is over Discord's limits for one
+func sendDiscord(cfg DiscordConfig, d Delivery) error {
+	if err := validateDiscord(cfg); err != nil {
+		return err
+	}
+	for _, msg := range discordMessages(discordEmbeds(d.Page)) {
+		if err := postDelivery(cfg.WebhookURL, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== DISCORD TESTS ====================
+
+func TestWorstStatus(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     string
+	}{
+		{nil, ""},
+		{[]string{"", "UNKNOWN"}, ""},
+		{[]string{"GOOD", "OK"}, "OK"},
+		{[]string{"GOOD", "POOR", "OK"}, "POOR"},
+		{[]string{"CLEAR", "UNKNOWN"}, "CLEAR"},
+	}
+	for _, tt := range tests {
+		if got := worstStatus(tt.statuses...); got != tt.want {
+			t.Errorf("worstStatus(%v) = %q, want %q", tt.statuses, got, tt.want)
+		}
+	}
+}
+
+func TestDiscordColor(t *testing.T) {
+	tests := []struct {
+		status string
+		want   int
+	}{
+		{"GOOD", 0x2e9d4f},
+		{"OK", 0xc98a00},
+		{"POOR", 0xd1342f},
+		{"PACKED", 0xd1342f},
+		{"", discordNeutralColor},
+		{"UNKNOWN", discordNeutralColor},
+	}
+	for _, tt := range tests {
+		if got := discordColor(tt.status); got != tt.want {
+			t.Errorf("discordColor(%q) = %#x, want %#x", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestDiscordEmbeds(t *testing.T) {
+	p := briefingPage{
+		Title: "Morning 2024-01-15",
+		Lead:  "Take it easy",
+		Sections: []pageSection{
+			{Title: "Recovery", Rows: []pageRow{
+				{Label: "Sleep", Value: "7.5 h", Status: "GOOD"},
+				{Label: "HRV", Value: "30 ms", Status: "POOR"},
+			}},
+			{Title: "Today", Status: "CLEAR", Empty: "Nothing scheduled"},
+			{Title: "Meds", Items: []pageItem{{Text: "Vitamin D (overdue)", Alert: true}}},
+			{Title: "Training", Rows: []pageRow{{Label: "This week", Value: "3"}}},
+		},
+	}
+	embeds := discordEmbeds(p)
+	if len(embeds) != 5 {
+		t.Fatalf("got %d embeds, want title plus one per section", len(embeds))
+	}
+
+	tests := []struct {
+		i     int
+		title string
+		color int
+	}{
+		{0, "Morning 2024-01-15", 0xd1342f}, // worst classification overall
+		{1, "Recovery", 0xd1342f},
+		{2, "Today · CLEAR", 0x2e9d4f},
+		{3, "Meds", 0xd1342f}, // overdue
+		{4, "Training", discordNeutralColor},
+	}
+	for _, tt := range tests {
+		if embeds[tt.i].Title != tt.title || embeds[tt.i].Color != tt.color {
+			t.Errorf("embed %d = %q %#x, want %q %#x", tt.i, embeds[tt.i].Title, embeds[tt.i].Color, tt.title, tt.color)
+		}
+	}
+	if embeds[0].Description != "Take it easy" {
+		t.Errorf("lead = %q", embeds[0].Description)
+	}
+	if f := embeds[1].Fields[1]; f.Name != "HRV" || f.Value != "30 ms (POOR)" || !f.Inline {
+		t.Errorf("HRV field = %+v", f)
+	}
+	if embeds[2].Description != "*Nothing scheduled*" {
+		t.Errorf("empty section description = %q", embeds[2].Description)
+	}
+}
+
+func TestDiscordMessages(t *testing.T) {
+	long := strings.Repeat("x", 2500)
+	tests := []struct {
+		name   string
+		embeds []discordEmbed
+		want   []int // embeds per message
+	}{
+		{"one", []discordEmbed{{Title: "a"}}, []int{1}},
+		{"embed count", make([]discordEmbed, 13), []int{10, 3}},
+		{"characters", []discordEmbed{{Description: long}, {Description: long}, {Description: long}}, []int{2, 1}},
+		{"oversized embed", []discordEmbed{{Title: "a"}, {Description: long + long + long}}, []int{1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, msg := range discordMessages(tt.embeds) {
+				got = append(got, len(msg.Embeds))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("embeds per message = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscordMessagesFullPage(t *testing.T) {
+	b := filledMorning()
+	b.Meds.Overdue = append(b.Meds.Overdue, MedTask{Name: "Nexium"})
+	p := morningPage(b)
+
+	var titles []string
+	for _, msg := range discordMessages(discordEmbeds(p)) {
+		size := 0
+		for _, e := range msg.Embeds {
+			size += discordEmbedSize(e)
+			titles = append(titles, e.Title)
+		}
+		if len(msg.Embeds) > discordMaxEmbeds || size > discordMaxMessage {
+			t.Errorf("message has %d embeds and %d characters, over Discord's limits", len(msg.Embeds), size)
+		}
+	}
+	if len(titles) != len(p.Sections)+1 {
+		t.Fatalf("posted %d embeds, want title plus all %d sections", len(titles), len(p.Sections))
+	}
+	for i, sec := range p.Sections {
+		if !strings.HasPrefix(titles[i+1], sec.Title) {
+			t.Errorf("embed %d = %q, want section %q", i+1, titles[i+1], sec.Title)
+		}
+	}
+}
+
+func TestSendDiscord(t *testing.T) {
+	var got discordMessage
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	d := eveningDelivery(EveningBriefing{TargetDate: "2024-01-15"})
+	if err := sendDiscord(DiscordConfig{WebhookURL: ts.URL}, d); err != nil {
+		t.Fatalf("sendDiscord() error: %v", err)
+	}
+	if len(got.Embeds) == 0 || got.Embeds[0].Title != "Evening 2024-01-15" {
+		t.Errorf("message = %+v", got)
+	}
+	if err := sendDiscord(DiscordConfig{}, d); err == nil {
+		t.Error("sendDiscord() without webhook_url = nil, want error")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// RunDoneCommand handles `briefing done NAME`: it completes the open med task
+// due today (or overdue) whose name matches, then prints what is still due
+func RunDoneCommand(args []string) error {
+	fs := flag.NewFlagSet("done", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || strings.TrimSpace(fs.Arg(0)) == "" {
+		return errors.New(`usage: briefing done "MED NAME"`)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	// Reading the list back can dump raw td output
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	tasks, err := todoistToday(today)
+	if err != nil {
+		return fmt.Errorf("todoist error: %w", err)
+	}
+	task, err := matchMedTask(tasks, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := todoistComplete(task.ID); err != nil {
+		return fmt.Errorf("todoist complete error: %w", err)
+	}
+	fmt.Printf("Completed %s\n", task.Content)
+
+	// Read the list back so the status reflects Todoist, not a guess
+	b := MorningBriefing{TargetDate: today}
+	getMedsData(&b, today)
+	var out strings.Builder
+	s := textStyle{color: useColor(os.Stdout)}
+	fmt.Fprintf(&out, "\n%s\n", s.heading("Meds"))
+	textMeds(&out, s, b.Meds)
+	fmt.Print(out.String())
+	for _, e := range b.Errors {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", e)
+	}
+	return nil
+}
+
+// matchMedTask finds the open med task named by query, case-insensitively.
+// An exact name wins; otherwise the query must be part of exactly one name.
+func matchMedTask(tasks []TodoistTask, query string) (TodoistTask, error) {
+	want := strings.ToLower(strings.TrimSpace(query))
+	var partial []TodoistTask
+	for _, t := range tasks {
+		if t.IsCompleted || !isMedTask(t) {
+			continue
+		}
+		name := strings.ToLower(t.Content)
+		if name == want {
+			return t, nil
+		}
+		if strings.Contains(name, want) {
+			partial = append(partial, t)
+		}
+	}
+	switch len(partial) {
+	case 0:
+		return TodoistTask{}, fmt.Errorf("no open med task matches %q", query)
+	case 1:
+		return partial[0], nil
+	}
+	names := make([]string, len(partial))
+	for i, t := range partial {
+		names[i] = t.Content
+	}
+	return TodoistTask{}, fmt.Errorf("%q matches several med tasks (%s); be more specific", query, strings.Join(names, ", "))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== DONE COMMAND TESTS ====================
+
+func TestMatchMedTask(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.MedLabels = []string{"💊Meds"}
+
+	tasks := []TodoistTask{
+		{ID: "1", Content: "Nexium 40mg", Labels: []string{"💊Meds"}},
+		{ID: "2", Content: "Vitamin D", Labels: []string{"💊Meds"}},
+		{ID: "3", Content: "Vitamin D + K2", Labels: []string{"💊Meds"}},
+		{ID: "4", Content: "PrEP", Labels: []string{"💊Meds"}, IsCompleted: true},
+		{ID: "5", Content: "Buy Nexium", Labels: []string{"errands"}},
+		{ID: "6", Content: "Magnesium", Labels: []string{"💊Meds"}},
+		{ID: "7", Content: "Magnesium glycinate", Labels: []string{"💊Meds"}},
+	}
+	tests := []struct {
+		query   string
+		wantID  string
+		wantErr string
+	}{
+		{"nexium", "1", ""},         // partial, and the errand isn't a med task
+		{"Vitamin D", "2", ""},      // exact beats the longer partial match
+		{"  magnesium ", "6", ""},   // trimmed, case-insensitive exact
+		{"vitamin", "", "several"},  // ambiguous
+		{"PrEP", "", "no open med"}, // already completed
+		{"Creatine", "", "no open med"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := matchMedTask(tasks, tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("matchMedTask(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.ID != tt.wantID {
+				t.Errorf("matchMedTask(%q) = %+v, %v, want task %s", tt.query, got, err, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestRunDoneCommandUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {""}, {"Nexium", "PrEP"}} {
+		if err := RunDoneCommand(args); err == nil || !strings.Contains(err.Error(), "usage") {
+			t.Errorf("RunDoneCommand(%q) error = %v, want usage", args, err)
+		}
+	}
+}
+
+func TestRunDoneCommandSealsDumps(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	fakeTD(t, `{"results": [{"id": "1", "content": "Nexium", "labels": ["💊Meds"], "due": {"date": "`+today+`"}}]}`)
+	saved := settings
+	t.Cleanup(func() { settings = saved; atRest = nil })
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(config, []byte(`{"med_labels": ["💊Meds"], "encryption": {"enabled": true}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BRIEFING_CONFIG", config)
+	t.Setenv("BRIEFING_KEY", "test-key")
+	t.Setenv("BRIEFING_DATA_DIR", dir)
+	t.Setenv("BRIEFING_DUMP", "1")
+
+	if err := RunDoneCommand([]string{"nexium"}); err != nil {
+		t.Fatalf("RunDoneCommand() error: %v", err)
+	}
+	dumps, _ := os.ReadDir(getDumpDir())
+	if len(dumps) == 0 {
+		t.Fatal("no dumps written")
+	}
+	for _, d := range dumps {
+		data, _ := os.ReadFile(filepath.Join(getDumpDir(), d.Name()))
+		if !strings.HasSuffix(d.Name(), ".json.enc") || strings.Contains(string(data), "Nexium") {
+			t.Errorf("dump %s is not sealed", d.Name())
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// getDumpDir returns where raw source payloads are written for debugging
+func getDumpDir() string {
+	return filepath.Join(getDataDir(), "dumps")
+}
+
+// dumpRaw saves a source tool's raw output when BRIEFING_DUMP is set, so a
+// wrong briefing can be traced back to what the tool actually returned.
+// Dumps are sealed when encryption is enabled (read them with `briefing decrypt`).
+// Failures are ignored: dumps must never break a briefing.
+func dumpRaw(name string, data []byte) {
+	if os.Getenv("BRIEFING_DUMP") == "" {
+		return
+	}
+	dir := getDumpDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return
+	}
+	ext := ".json"
+	if atRest != nil {
+		ext = ".json.enc"
+	}
+	f, err := os.CreateTemp(dir, fmt.Sprintf("%s-%s-*%s", time.Now().Format("20060102-150405"), name, ext))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(sealAtRest(data))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// ==================== RAW DUMP TESTS ====================
+
+func TestDumpRaw(t *testing.T) {
+	t.Setenv("BRIEFING_DATA_DIR", t.TempDir())
+
+	t.Setenv("BRIEFING_DUMP", "")
+	dumpRaw("todoist-today", []byte(`{"results": []}`))
+	if _, err := os.Stat(getDumpDir()); !os.IsNotExist(err) {
+		t.Error("dumpRaw() wrote without BRIEFING_DUMP set")
+	}
+
+	t.Setenv("BRIEFING_DUMP", "1")
+	dumpRaw("calendar-work", []byte(`{"events": []}`))
+	dumpRaw("calendar-work", []byte(`{"events": [1]}`))
+
+	entries, err := os.ReadDir(getDumpDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("dump files = %d, want 2 (same-second dumps must not collide)", len(entries))
+	}
+	if !strings.Contains(entries[0].Name(), "-calendar-work-") || !strings.HasSuffix(entries[0].Name(), ".json") {
+		t.Errorf("dump name = %q", entries[0].Name())
+	}
+}
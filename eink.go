@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Default canvas for 7.5" e-ink panels (TRMNL, Inkplate 10)
+const (
+	EinkDefaultWidth  = 800
+	EinkDefaultHeight = 480
+)
+
+// monoCanvas is a 1-bit drawing surface (index 0 white, index 1 black)
+type monoCanvas struct {
+	img *image.Paletted
+}
+
+func newMonoCanvas(width, height int) *monoCanvas {
+	palette := color.Palette{color.White, color.Black}
+	return &monoCanvas{img: image.NewPaletted(image.Rect(0, 0, width, height), palette)}
+}
+
+func (c *monoCanvas) fillRect(x, y, w, h int) {
+	for py := y; py < y+h; py++ {
+		for px := x; px < x+w; px++ {
+			c.img.SetColorIndex(px, py, 1)
+		}
+	}
+}
+
+func (c *monoCanvas) strokeRect(x, y, w, h int) {
+	c.fillRect(x, y, w, 1)
+	c.fillRect(x, y+h-1, w, 1)
+	c.fillRect(x, y, 1, h)
+	c.fillRect(x+w-1, y, 1, h)
+}
+
+// bar draws an outlined bar filled to fraction (clamped to 0-1)
+func (c *monoCanvas) bar(x, y, w, h int, fraction float64) {
+	c.strokeRect(x, y, w, h)
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	c.fillRect(x+2, y+2, int(float64(w-4)*fraction), h-4)
+}
+
+// text draws s with the embedded font and returns the drawn width
+func (c *monoCanvas) text(x, y, scale int, s string) int {
+	cx := x
+	for _, r := range s {
+		g := glyphFor(r)
+		for row := 0; row < glyphHeight; row++ {
+			for col := 0; col < glyphWidth; col++ {
+				if g[row][col] == '#' {
+					c.fillRect(cx+col*scale, y+row*scale, scale, scale)
+				}
+			}
+		}
+		cx += (glyphWidth + 1) * scale
+	}
+	return cx - x
+}
+
+// textWidth returns the pixel width of s at the given scale
+func textWidth(s string, scale int) int {
+	return len([]rune(s)) * (glyphWidth + 1) * scale
+}
+
+// charsThatFit returns how many glyphs fit in width pixels, at least one so
+// a narrow column still shows an ellipsis
+func charsThatFit(width, scale int) int {
+	return max(1, width/((glyphWidth+1)*scale))
+}
+
+// wrapText splits s into lines of at most maxChars runes on word boundaries
+func wrapText(s string, maxChars int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		switch {
+		case line == "":
+			line = word
+		case len([]rune(line))+1+len([]rune(word)) <= maxChars:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	for i, l := range lines {
+		lines[i] = truncateRunes(l, maxChars)
+	}
+	return lines
+}
+
+// ParseSize parses a WIDTHxHEIGHT canvas size
+func ParseSize(size string) (int, int, error) {
+	w, h, ok := strings.Cut(strings.ToLower(size), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid size %q (expected WIDTHxHEIGHT)", size)
+	}
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(h)
+	if err1 != nil || err2 != nil || width < 200 || height < 120 {
+		return 0, 0, fmt.Errorf("invalid size %q (expected WIDTHxHEIGHT, at least 200x120)", size)
+	}
+	return width, height, nil
+}
+
+// RenderEinkPNG draws the morning briefing as a monochrome PNG
+func RenderEinkPNG(w io.Writer, b MorningBriefing, width, height int) error {
+	c := newMonoCanvas(width, height)
+
+	// Scale the font with the panel so 800x480 gets 3x titles and 2x body
+	// text, capped by the width so tall, narrow panels keep readable columns
+	titleScale := max(1, min(height/160, width/266))
+	bodyScale := max(1, min(height/240, width/400))
+	lineHeight := (glyphHeight + 4) * bodyScale
+	margin := max(8, width/40)
+	colWidth := (width - 3*margin) / 2
+	barHeight := 4 * bodyScale
+
+	// Header
+	y := margin
+	c.text(margin, y, titleScale, "GOOD MORNING")
+	c.text(width-margin-textWidth(b.TargetDate, titleScale), y, titleScale, b.TargetDate)
+	y += (glyphHeight + 3) * titleScale
+	c.fillRect(margin, y, width-2*margin, max(1, bodyScale))
+	y += lineHeight
+	top := y
+
+	// Left column: sleep and recovery
+	x := margin
+	sleepLine := "SLEEP " + b.Classification.SleepQuality
+	if b.Sleep.TotalHours != nil {
+		sleepLine = fmt.Sprintf("SLEEP %.1fH %s", *b.Sleep.TotalHours, b.Classification.SleepQuality)
+	}
+	c.text(x, y, bodyScale, sleepLine)
+	y += lineHeight
+	if b.Sleep.TotalHours != nil {
+		c.bar(x, y, colWidth, barHeight*2, *b.Sleep.TotalHours/ReadinessSleepTargetHours)
+		y += barHeight*2 + lineHeight/2
+	}
+
+	stages := []struct {
+		label string
+		value *float64
+		full  float64
+	}{
+		{"DEEP", b.Sleep.DeepHours, 2},
+		{"REM", b.Sleep.REMHours, 2},
+		{"CORE", b.Sleep.CoreHours, 5},
+	}
+	labelWidth := textWidth("CORE 0.0H ", bodyScale)
+	for _, st := range stages {
+		if st.value == nil {
+			continue
+		}
+		c.text(x, y, bodyScale, fmt.Sprintf("%s %.1fH", st.label, *st.value))
+		c.bar(x+labelWidth, y, colWidth-labelWidth, glyphHeight*bodyScale, *st.value/st.full)
+		y += lineHeight
+	}
+	y += lineHeight / 2
+
+	if score := b.Classification.ReadinessScore; score != nil {
+		c.text(x, y, bodyScale, fmt.Sprintf("READINESS %d", *score))
+		y += lineHeight
+		c.bar(x, y, colWidth, barHeight*2, float64(*score)/100)
+		y += barHeight*2 + lineHeight/2
+	}
+
+	var vitals []string
+	if b.Vitals.HRV != nil {
+		vitals = append(vitals, fmt.Sprintf("HRV %.0fMS", *b.Vitals.HRV))
+	}
+	if b.Vitals.RestingHR != nil {
+		vitals = append(vitals, fmt.Sprintf("RHR %.0f", *b.Vitals.RestingHR))
+	}
+	if len(vitals) > 0 {
+		c.text(x, y, bodyScale, strings.Join(vitals, "  "))
+		y += lineHeight
+	}
+	c.text(x, y, bodyScale, "RECOVERY "+b.Classification.RecoveryStatus)
+	y += lineHeight
+	leftBottom := y
+
+	// Right column: agenda and meds
+	x = 2*margin + colWidth
+	y = top
+	maxChars := charsThatFit(colWidth, bodyScale)
+	c.text(x, y, bodyScale, "AGENDA "+b.Classification.MorningLoad)
+	y += lineHeight
+	events := append(append([]CalendarEvent{}, b.Calendar.MorningEvents...), b.Calendar.AfternoonEvents...)
+	if len(events) == 0 {
+		c.text(x, y, bodyScale, "NOTHING SCHEDULED")
+		y += lineHeight
+	}
+	for i, e := range events {
+		if i == 6 {
+			c.text(x, y, bodyScale, fmt.Sprintf("+%d MORE", len(events)-i))
+			y += lineHeight
+			break
+		}
+		c.text(x, y, bodyScale, truncateRunes(e.Time+" "+e.Summary, maxChars))
+		y += lineHeight
+	}
+	y += lineHeight / 2
+
+	c.text(x, y, bodyScale, fmt.Sprintf("MEDS %d DUE %d OVERDUE", len(b.Meds.DueToday), len(b.Meds.Overdue)))
+	y += lineHeight
+	for i, m := range append(append([]MedTask{}, b.Meds.Overdue...), b.Meds.DueToday...) {
+		if i == 4 {
+			break
+		}
+		c.text(x, y, bodyScale, truncateRunes("- "+m.Name, maxChars))
+		y += lineHeight
+	}
+
+	// Footer: recommendation across the full width
+	y = max(y, leftBottom) + lineHeight/2
+	c.fillRect(margin, y, width-2*margin, max(1, bodyScale))
+	y += lineHeight / 2
+	for _, line := range wrapText(b.Classification.Recommendation, charsThatFit(width-2*margin, bodyScale)) {
+		c.text(margin, y, bodyScale, line)
+		y += lineHeight
+	}
+
+	return png.Encode(w, c.img)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+)
+
+// ==================== E-INK RENDERING TESTS ====================
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input       string
+		width       int
+		height      int
+		expectError bool
+	}{
+		{"800x480", 800, 480, false},
+		{"1200X825", 1200, 825, false},
+		{"800", 0, 0, true},
+		{"axb", 0, 0, true},
+		{"100x50", 0, 0, true}, // Too small to lay out
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			w, h, err := ParseSize(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseSize(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSize(%q) unexpected error: %v", tt.input, err)
+			}
+			if w != tt.width || h != tt.height {
+				t.Errorf("ParseSize(%q) = %dx%d, want %dx%d", tt.input, w, h, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("Rough night + packed morning. Prioritize must-dos.", 20)
+	expected := []string{"Rough night + packed", "morning. Prioritize", "must-dos."}
+	if len(lines) != len(expected) {
+		t.Fatalf("wrapText() = %q, want %q", lines, expected)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("wrapText()[%d] = %q, want %q", i, lines[i], expected[i])
+		}
+	}
+}
+
+func TestRenderEinkPNG(t *testing.T) {
+	b := MorningBriefing{
+		TargetDate: "2024-01-15",
+		Sleep: SleepData{
+			TotalHours:    ptr(7.5),
+			DeepHours:     ptr(1.2),
+			REMHours:      ptr(1.8),
+			DataAvailable: true,
+			IsCurrentDay:  true,
+		},
+		Vitals:   VitalsData{HRV: ptr(45), RestingHR: ptr(52)},
+		Calendar: CalendarData{MorningEvents: []CalendarEvent{{Time: "09:00", Summary: "Team standup"}}},
+		Meds:     MedsData{DueToday: []MedTask{{Name: "Nexium"}}},
+		Classification: Classification{
+			SleepQuality:   "GOOD",
+			MorningLoad:    "LIGHT",
+			RecoveryStatus: "GOOD",
+			ReadinessScore: intPtr(92),
+			Recommendation: "Well rested. Attack the day.",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderEinkPNG(&buf, b, 800, 480); err != nil {
+		t.Fatalf("RenderEinkPNG() error: %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 800, 480) {
+		t.Errorf("Bounds = %v, want 800x480", img.Bounds())
+	}
+
+	paletted, ok := img.(*image.Paletted)
+	if !ok {
+		t.Fatalf("image type = %T, want *image.Paletted", img)
+	}
+	if len(paletted.Palette) != 2 {
+		t.Errorf("palette size = %d, want 2 (monochrome)", len(paletted.Palette))
+	}
+
+	black := 0
+	for _, px := range paletted.Pix {
+		if px == 1 {
+			black++
+		}
+	}
+	if black == 0 {
+		t.Error("rendered image is blank")
+	}
+}
+
+func TestRenderEinkPNGTallNarrow(t *testing.T) {
+	b := MorningBriefing{
+		Calendar: CalendarData{MorningEvents: []CalendarEvent{{Time: "09:00", Summary: "Quarterly planning with the leadership team"}}},
+		Meds:     MedsData{DueToday: []MedTask{{Name: "Nexium"}}},
+		Classification: Classification{
+			MorningLoad:    "LIGHT",
+			RecoveryStatus: "GOOD",
+			Recommendation: "Well rested. Attack the day.",
+		},
+	}
+	for _, size := range [][2]int{{200, 4800}, {200, 120}} {
+		if err := RenderEinkPNG(io.Discard, b, size[0], size[1]); err != nil {
+			t.Errorf("RenderEinkPNG(%dx%d) error: %v", size[0], size[1], err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpImplicitTLSPort is the submissions port, which starts TLS before SMTP
+const smtpImplicitTLSPort = 465
+
+// statusColors tint classifications in the HTML email
+var statusColors = map[string]string{
+	"GOOD": "#2e9d4f", "CLEAR": "#2e9d4f", "CONSISTENT": "#2e9d4f", "NORMAL": "#2e9d4f", "ON PACE": "#2e9d4f", "AT GOAL": "#2e9d4f",
+	"OK": "#c98a00", "LIGHT": "#c98a00", "ELEVATED": "#c98a00", "IRREGULAR": "#c98a00", "LOW": "#c98a00", "SEDENTARY": "#c98a00", "BEHIND PACE": "#c98a00",
+	"MODERATE": "#c98a00", "SENSITIVE": "#c98a00",
+	"POOR": "#d1342f", "PACKED": "#d1342f", "HIGH": "#d1342f", "UNHEALTHY": "#d1342f", "VERY UNHEALTHY": "#d1342f", "HAZARDOUS": "#d1342f",
+}
+
+// Inline styles only: most mail clients drop <style> blocks
+var htmlEmailTemplate = template.Must(template.New("email").Funcs(template.FuncMap{
+	"statusColor": func(s string) string {
+		if c, ok := statusColors[s]; ok {
+			return c
+		}
+		return "#6e6e73"
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>{{.Title}}</title></head>
+<body style="margin:0;padding:0;background:#f5f5f7;font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;color:#1d1d1f;">
+<div style="max-width:600px;margin:0 auto;padding:16px;">
+<h1 style="font-size:22px;margin:8px 0;">{{.Title}}</h1>
+{{if .Lead}}<p style="font-size:16px;margin:0 0 16px;">{{.Lead}}</p>{{end}}
+{{range .Sections}}<div style="background:#fff;border-radius:10px;padding:12px 16px;margin-bottom:12px;">
+<h2 style="font-size:13px;text-transform:uppercase;letter-spacing:.05em;color:#6e6e73;margin:0 0 8px;">{{.Title}}{{if .Status}} <span style="color:{{statusColor .Status}};">{{.Status}}</span>{{end}}</h2>
+{{if .Rows}}<table role="presentation" style="width:100%;border-collapse:collapse;font-size:15px;">
+{{range .Rows}}<tr><td style="padding:4px 0;color:#6e6e73;">{{.Label}}</td><td style="padding:4px 0;text-align:right;">{{.Value}}{{if .Status}} <span style="color:{{statusColor .Status}};font-weight:600;">{{.Status}}</span>{{end}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Items}}<ul style="margin:0;padding-left:18px;font-size:15px;">
+{{range .Items}}<li style="padding:2px 0;{{if .Alert}}color:#d1342f;{{end}}">{{.Text}}</li>
+{{end}}</ul>{{else if not .Rows}}<p style="margin:0;color:#6e6e73;">{{.Empty}}</p>{{end}}
+</div>
+{{end}}</div>
+</body></html>
+`))
+
+func renderHTMLPage(p briefingPage) string {
+	var buf bytes.Buffer
+	if err := htmlEmailTemplate.Execute(&buf, p); err != nil {
+		return "<pre>" + template.HTMLEscapeString(err.Error()) + "</pre>"
+	}
+	return buf.String()
+}
+
+// MorningHTML renders the morning briefing as a responsive HTML email
+func MorningHTML(m MorningBriefing) string {
+	return renderHTMLPage(morningPage(m))
+}
+
+// MiddayHTML renders the midday check-in as a responsive HTML email
+func MiddayHTML(m MiddayBriefing) string {
+	return renderHTMLPage(middayPage(m))
+}
+
+// EveningHTML renders the evening wrap-up as a responsive HTML email
+func EveningHTML(e EveningBriefing) string {
+	return renderHTMLPage(eveningPage(e))
+}
+
+// validateEmail checks the settings needed to send
+func validateEmail(cfg EmailConfig) error {
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return errors.New("delivery.email: host, from, and to are required")
+	}
+	return nil
+}
+
+// buildEmailMessage builds a multipart/alternative message with text and HTML parts
+func buildEmailMessage(from string, to []string, subject, text, html string, now time.Time) []byte {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		w.Write([]byte(strings.ReplaceAll(part.content, "\n", "\r\n")))
+	}
+	mw.Close()
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes()
+}
+
+// sendEmail delivers the briefing over SMTP: STARTTLS when the server offers
+// it, or implicit TLS on port 465
+func sendEmail(cfg EmailConfig, d Delivery) error {
+	if err := validateEmail(cfg); err != nil {
+		return err
+	}
+	msg := buildEmailMessage(cfg.From, cfg.To, d.Subject, d.Text, d.HTML, time.Now())
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	if cfg.Port != smtpImplicitTLSPort {
+		return smtp.SendMail(addr, auth, cfg.From, cfg.To, msg)
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: webhookTimeout}, "tcp", addr, &tls.Config{ServerName: cfg.Host})
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, rcpt := range cfg.To {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
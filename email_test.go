@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== HTML EMAIL TESTS ====================
+
+func TestMorningHTML(t *testing.T) {
+	b := MorningBriefing{
+		TargetDate: "2024-01-15",
+		Sleep:      SleepData{TotalHours: ptr(5.5)},
+		Calendar:   CalendarData{MorningEvents: []CalendarEvent{{Time: "09:00", Summary: "<script>Standup</script>"}}},
+		Meds:       MedsData{Overdue: []MedTask{{Name: "Vitamin D"}}},
+		Classification: Classification{
+			SleepQuality:   "POOR",
+			ReadinessScore: intPtr(55),
+			Recommendation: "Take it easy",
+		},
+	}
+	html := MorningHTML(b)
+	for _, want := range []string{
+		"<title>Morning 2024-01-15</title>",
+		"Take it easy",
+		"5.5 h",
+		`<span style="color:#d1342f;font-weight:600;">POOR</span>`,
+		"09:00 &lt;script&gt;Standup&lt;/script&gt;",
+		`color:#d1342f;">Vitamin D (overdue)</li>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("event summary was not escaped")
+	}
+}
+
+func TestEveningAndMiddayHTML(t *testing.T) {
+	e := EveningHTML(EveningBriefing{TargetDate: "2024-01-15", Energy: EnergyData{DeficitOrSurplusKcal: -300, Status: "deficit"}})
+	if !strings.Contains(e, "-300 kcal (deficit)") || !strings.Contains(e, ">None</p>") {
+		t.Errorf("evening HTML missing balance or empty missed list:\n%s", e)
+	}
+	m := MiddayHTML(MiddayBriefing{TargetDate: "2024-01-15", Steps: 3100})
+	if !strings.Contains(m, "3100") || !strings.Contains(m, "Nothing scheduled") {
+		t.Errorf("midday HTML missing steps or empty agenda:\n%s", m)
+	}
+}
+
+func TestBuildEmailMessage(t *testing.T) {
+	now := time.Date(2024, 1, 15, 6, 30, 0, 0, time.UTC)
+	raw := buildEmailMessage("briefing@example.com", []string{"me@example.com", "you@example.com"}, "Morning briefing 2024-01-15: readiness 72", "plain body", "<p>html body</p>", now)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage() error: %v", err)
+	}
+	if got := msg.Header.Get("To"); got != "me@example.com, you@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Morning briefing 2024-01-15: readiness 72" {
+		t.Errorf("Subject = %q", subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, %v", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(p)
+		parts = append(parts, p.Header.Get("Content-Type")+": "+string(body))
+	}
+	want := []string{"text/plain; charset=utf-8: plain body", "text/html; charset=utf-8: <p>html body</p>"}
+	if len(parts) != 2 || parts[0] != want[0] || parts[1] != want[1] {
+		t.Errorf("parts = %q, want %q", parts, want)
+	}
+}
+
+// fakeSMTPServer accepts one message over plain SMTP and returns what it received
+func fakeSMTPServer(t *testing.T) (port int, received <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+
+		var transcript strings.Builder
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			transcript.WriteString(strings.TrimSpace(line) + "\n")
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 fake")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					transcript.WriteString(data)
+				}
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				ch <- transcript.String()
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestSendEmail(t *testing.T) {
+	port, received := fakeSMTPServer(t)
+	cfg := EmailConfig{Host: "127.0.0.1", Port: port, From: "briefing@example.com", To: []string{"me@example.com"}}
+
+	d := morningDelivery(MorningBriefing{TargetDate: "2024-01-15"})
+	if err := sendEmail(cfg, d); err != nil {
+		t.Fatalf("sendEmail() error: %v", err)
+	}
+
+	select {
+	case transcript := <-received:
+		for _, want := range []string{"MAIL FROM:<briefing@example.com>", "RCPT TO:<me@example.com>", "Subject: Morning briefing 2024-01-15", "text/html"} {
+			if !strings.Contains(transcript, want) {
+				t.Errorf("transcript missing %q:\n%s", want, transcript)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server received nothing")
+	}
+}
+
+func TestSendEmailRequiresSettings(t *testing.T) {
+	if err := sendEmail(EmailConfig{Port: 587}, Delivery{}); err == nil {
+		t.Error("sendEmail() without host = nil, want error")
+	}
+	if err := sendEmail(EmailConfig{Host: "127.0.0.1", Port: 1, From: "a@example.com"}, Delivery{}); err == nil || !strings.Contains(err.Error(), "required") {
+		t.Errorf("sendEmail() without recipients = %v, want settings error", err)
+	}
+}
@@ -5,55 +5,84 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 )
 
-// User stats for calculations
+// Default user stats, overridden by the user section of the config
 const (
-	UserAge             = 41
-	UserWeightKg        = 73.0
-	UserHeightCm        = 177.0
-	UserIsMale          = true
-	UserBMRKcal         = 1636 // Mifflin-St Jeor result
-	UserProteinTargetG  = 152
+	UserAge              = 41
+	UserWeightKg         = 73.0
+	UserHeightCm         = 177.0
+	UserIsMale           = true
+	UserBMRKcal          = 1636 // Mifflin-St Jeor result
+	UserProteinGPerKg    = 2.0  // protein target per kg of current weight
+	UserWaterTargetMl    = 2500 // ~35 ml/kg baseline before sweat adjustments
+	UserSleepTargetHours = 8.0  // nightly sleep the debt is counted against
+	UserZone2TargetMin   = 150  // weekly Zone 2 cardio minutes
+	UserStepGoal         = 10000
+	UserSedentarySteps   = 5000 // trailing daily average below this flags SEDENTARY
+	UserGoalDeficitKcal  = 500  // daily deficit the remaining calorie budget aims for
 )
 
 // EveningBriefing is the output structure for evening wrap-up
 type EveningBriefing struct {
-	Mode        string        `json:"mode"`
-	GeneratedAt string        `json:"generated_at"`
-	TargetDate  string        `json:"target_date"`
-	Energy      EnergyData    `json:"energy"`
-	Protein     ProteinData   `json:"protein"`
-	Activity    ActivityData  `json:"activity"`
-	Recovery    RecoveryData  `json:"recovery"`
-	Protocols   ProtocolsData `json:"protocols"`
-	Tomorrow    TomorrowData  `json:"tomorrow"`
-	Errors      []string      `json:"errors,omitempty"`
+	Mode           string              `json:"mode"`
+	GeneratedAt    string              `json:"generated_at"`
+	TargetDate     string              `json:"target_date"`
+	Energy         EnergyData          `json:"energy"`
+	Protein        ProteinData         `json:"protein"`
+	Hydration      HydrationData       `json:"hydration"`
+	Activity       ActivityData        `json:"activity"`
+	Rings          ActivityRings       `json:"rings"`
+	Weight         *WeightData         `json:"weight,omitempty"`
+	Eating         *EatingWindow       `json:"eating_window,omitempty"`
+	Meals          []Meal              `json:"meals,omitempty"`
+	LateKcalPct    *int                `json:"late_kcal_pct,omitempty"` // share of calories in meals from 20:00
+	Micronutrients []MicronutrientData `json:"micronutrients,omitempty"`
+	Recovery       RecoveryData        `json:"recovery"`
+	Protocols      ProtocolsData       `json:"protocols"`
+	Tomorrow       TomorrowData        `json:"tomorrow"`
+	Travel         *TravelData         `json:"travel,omitempty"`  // flights and hotel stays starting tomorrow
+	Changes        []Change            `json:"changes,omitempty"` // vs yesterday's stored wrap-up (--compare)
+	Alerts         []Alert             `json:"alerts,omitempty"`
+	Errors         []string            `json:"errors,omitempty"`
 }
 
 type EnergyData struct {
-	DeficitOrSurplusKcal int     `json:"deficit_or_surplus_kcal"`
-	Status               string  `json:"status"` // "deficit", "surplus", "maintenance"
-	BMRKcal              int     `json:"bmr_kcal"`
-	ActiveKcal           float64 `json:"active_kcal"`
-	TotalBurnedKcal      float64 `json:"total_burned_kcal"`
-	ConsumedKcal         float64 `json:"consumed_kcal"`
+	DeficitOrSurplusKcal       int             `json:"deficit_or_surplus_kcal"`
+	Status                     string          `json:"status"`              // "deficit", "surplus", "maintenance"
+	BMRKcal                    int             `json:"bmr_kcal"`            // resting burn: adaptive baseline or Mifflin-St Jeor
+	BMRSource                  string          `json:"bmr_source"`          // adaptive, mifflin_st_jeor
+	TDEEKcal                   *int            `json:"tdee_kcal,omitempty"` // adaptive estimate over the last 4 weeks
+	ActiveKcal                 float64         `json:"active_kcal"`
+	TotalBurnedKcal            float64         `json:"total_burned_kcal"`
+	ConsumedKcal               float64         `json:"consumed_kcal"`
+	ProjectedActiveKcal        float64         `json:"projected_active_kcal"`          // rest of today, from the same weekday over the last 4 weeks
+	RemainingKcalToMaintenance int             `json:"remaining_kcal_to_maintenance"`  // projected burn minus consumed
+	RemainingKcalToGoalDeficit int             `json:"remaining_kcal_to_goal_deficit"` // the same, less user.goal_deficit_kcal
+	Goal                       *GoalWeightData `json:"goal,omitempty"`
 }
 
 type ProteinData struct {
 	ConsumedG  float64 `json:"consumed_g"`
 	TargetG    int     `json:"target_g"`
+	WeightKg   float64 `json:"weight_kg,omitempty"` // weight the target was derived from, unset for a fixed target
 	RemainingG float64 `json:"remaining_g"`
 	OnTrack    bool    `json:"on_track"`
 }
 
 type ActivityData struct {
-	Steps      int          `json:"steps"`
-	Workout    *WorkoutInfo `json:"workout,omitempty"`
-	StandHours int          `json:"stand_hours"`
+	Steps       int          `json:"steps"`
+	StepGoal    int          `json:"step_goal"`
+	StepGoalPct int          `json:"step_goal_pct"`
+	Steps7dAvg  int          `json:"steps_7d_avg"` // the 7 days before today, 0 without data
+	Workout     *WorkoutInfo `json:"workout,omitempty"`
+	StandHours  int          `json:"stand_hours"`
+	Flags       []string     `json:"flags,omitempty"` // SEDENTARY
 }
 
 type WorkoutInfo struct {
@@ -75,8 +104,10 @@ type SleepInfo struct {
 }
 
 type ProtocolsData struct {
-	Completed []string `json:"completed"`
-	Missed    []string `json:"missed"`
+	Completed  []string      `json:"completed"`
+	Missed     []string      `json:"missed"`
+	Cycles     []CycleStatus `json:"cycles,omitempty"`
+	Categories MedCategories `json:"categories,omitempty"` // completed and missed by medication, supplement, injection
 }
 
 type TomorrowData struct {
@@ -135,32 +166,152 @@ func CalculateProteinStatus(consumed, target float64) (float64, bool) {
 	return remaining, onTrack
 }
 
+// Briefing modes accepted by --mode
+var validModes = []string{"auto", "morning", "midday", "evening", "weekly"}
+
 // ParseMode determines the briefing mode from CLI flags
-func ParseMode(morning, evening bool) (string, error) {
-	if morning && evening {
-		return "", errors.New("cannot specify both --morning and --evening")
+// With no flags the mode is "auto" (resolved by time of day via ResolveMode).
+func ParseMode(mode string, morning, evening, weekly bool) (string, error) {
+	selected := 0
+	for _, f := range []bool{mode != "", morning, evening, weekly} {
+		if f {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return "", errors.New("cannot specify more than one of --mode, --morning, --evening, --weekly")
 	}
-	if evening {
+	switch {
+	case mode != "":
+		if !slices.Contains(validModes, mode) {
+			return "", fmt.Errorf("unknown mode %q (expected: %s)", mode, strings.Join(validModes, ", "))
+		}
+		return mode, nil
+	case morning:
+		return "morning", nil
+	case evening:
 		return "evening", nil
+	case weekly:
+		return "weekly", nil
+	}
+	return "auto", nil
+}
+
+// RunEveningBriefing prints the evening wrap-up and publishes it if MQTT is configured
+func RunEveningBriefing(cfg Config, opts RunOptions) {
+	briefing := BuildEveningBriefing(time.Now())
+
+	alerts, errs := EvaluateRules(cfg.Rules, eveningRuleVars(briefing))
+	briefing.Alerts = alerts
+	for _, err := range errs {
+		briefing.Errors = append(briefing.Errors, fmt.Sprintf("rule error: %v", err))
+	}
+
+	if opts.Compare {
+		changes, err := compareFromHistory("evening", briefing.TargetDate, briefing, eveningCompareMetrics)
+		if err != nil {
+			briefing.Errors = append(briefing.Errors, fmt.Sprintf("compare error: %v", err))
+		}
+		briefing.Changes = changes
+	}
+
+	printed := briefing
+	if opts.Redact {
+		printed = RedactEveningBriefing(briefing)
+	}
+	switch opts.Format {
+	case FormatNone:
+	case "markdown":
+		fmt.Print(EveningMarkdown(printed))
+	case "text":
+		fmt.Print(EveningText(printed, textStyle{color: useColor(os.Stdout)}))
+	case "prompt":
+		printPrompt(cfg.Prompt, "evening", printed)
+	default:
+		output, _ := json.MarshalIndent(printed, "", "  ")
+		fmt.Println(string(output))
+	}
+
+	if cfg.MQTT.Broker != "" {
+		payload, _ := json.Marshal(briefing)
+		if err := PublishMQTT(cfg.MQTT, eveningMQTTMessages(cfg.MQTT.TopicPrefix, briefing, payload)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	for _, err := range dispatchAlerts(cfg, "evening", briefing.TargetDate, briefing.GeneratedAt, briefing.Alerts) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, err := range deliver(cfg.Delivery, deliveryChannelsFor(cfg.Delivery, "evening", opts.Deliver), eveningDelivery(printed)) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if err := recordHistory(eveningHistoryRecord(briefing, HistorySourceLive)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := recordMedLog(briefing.TargetDate, briefing.Protocols); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if cfg.DerivedMetrics.Enabled {
+		if err := writeDerivedMetrics(briefing.TargetDate, eveningDerivedMetrics(briefing)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if cfg.Archive.Enabled {
+		if err := archiveBriefing(cfg.Archive, "evening", briefing.TargetDate, briefing, EveningMarkdown(briefing)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 	}
-	return "morning", nil
 }
 
-// RunEveningBriefing generates the evening wrap-up output
-func RunEveningBriefing() {
-	now := time.Now()
+// BuildEveningBriefing generates the evening wrap-up
+func BuildEveningBriefing(now time.Time) EveningBriefing {
 	today := now.Format("2006-01-02")
 	yesterdayDate := yesterday(today)
 
-	briefing := EveningBriefing{
+	briefing := newEveningBriefing(now, today)
+
+	// Get data from health-ingest SQLite
+	getEveningHealthData(&briefing, today, yesterdayDate)
+
+	// Get today's workout from Hevy
+	getEveningWorkoutData(&briefing, today)
+
+	// Adjust hydration target for workout, active energy, and heat
+	getEveningWeather(&briefing, today)
+	calculateEveningHydration(&briefing)
+	calculateEveningMicronutrients(&briefing)
+
+	// Get protocol completion from Todoist
+	getEveningProtocolData(&briefing, today)
+	briefing.Protocols.Cycles = cyclesFor(settings.Cycles, today)
+
+	// Get tomorrow's preview
+	getTomorrowData(&briefing, today)
+
+	return briefing
+}
+
+// newEveningBriefing returns an evening briefing with targets and empty lists set
+func newEveningBriefing(now time.Time, date string) EveningBriefing {
+	return EveningBriefing{
 		Mode:        "evening",
 		GeneratedAt: now.Format(time.RFC3339),
-		TargetDate:  today,
+		TargetDate:  date,
 		Energy: EnergyData{
-			BMRKcal: UserBMRKcal,
+			BMRKcal:   settings.User.BMRKcal(),
+			BMRSource: BMRSourceFormula,
 		},
 		Protein: ProteinData{
-			TargetG: UserProteinTargetG,
+			TargetG: settings.User.ProteinTarget(0),
+		},
+		Hydration: HydrationData{
+			BaseTargetMl: settings.User.WaterTargetMl,
+		},
+		Activity: ActivityData{
+			StepGoal: settings.User.StepGoal,
 		},
 		Protocols: ProtocolsData{
 			Completed: []string{},
@@ -170,22 +321,6 @@ func RunEveningBriefing() {
 			MedsDue: []string{},
 		},
 	}
-
-	// Get data from health-ingest SQLite
-	getEveningHealthData(&briefing, today, yesterdayDate)
-
-	// Get today's workout from Hevy
-	getEveningWorkoutData(&briefing, today)
-
-	// Get protocol completion from Todoist
-	getEveningProtocolData(&briefing, today)
-
-	// Get tomorrow's preview
-	getTomorrowData(&briefing, today)
-
-	// Output JSON
-	output, _ := json.MarshalIndent(briefing, "", "  ")
-	fmt.Println(string(output))
 }
 
 func getEveningHealthData(b *EveningBriefing, today, yesterday string) {
@@ -197,6 +332,11 @@ func getEveningHealthData(b *EveningBriefing, today, yesterday string) {
 	}
 	defer db.Close()
 
+	fillEveningHealthFromDB(b, db, today, yesterday)
+}
+
+// fillEveningHealthFromDB reads the day's energy, nutrition, activity, and recovery metrics
+func fillEveningHealthFromDB(b *EveningBriefing, db *sql.DB, today, yesterday string) {
 	// Get active energy for today
 	activeEnergy, err := queryDayTotal(db, "active_energy", today)
 	if err != nil {
@@ -213,10 +353,35 @@ func getEveningHealthData(b *EveningBriefing, today, yesterday string) {
 		b.Energy.ConsumedKcal = consumedEnergy
 	}
 
+	// Replace the formula BMR with the adaptive baseline when history allows
+	tdee, err := queryAdaptiveTDEE(db, yesterday)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("adaptive TDEE query error: %v", err))
+	} else {
+		b.Energy.BMRKcal, b.Energy.BMRSource = adaptiveBMR(tdee, settings.User.BMRKcal())
+		if b.Energy.BMRSource == BMRSourceAdaptive {
+			b.Energy.TDEEKcal = &tdee.TDEE
+		}
+	}
+
 	// Calculate energy balance
 	b.Energy.TotalBurnedKcal = float64(b.Energy.BMRKcal) + b.Energy.ActiveKcal
 	b.Energy.DeficitOrSurplusKcal, b.Energy.Status = CalculateEnergyBalance(
 		b.Energy.BMRKcal, b.Energy.ActiveKcal, b.Energy.ConsumedKcal)
+	if err := fillCalorieBudget(&b.Energy, db, today, b.GeneratedAt); err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("active energy projection error: %v", err))
+	}
+
+	// Scale the protein target to the latest weigh-in
+	if settings.User.ProteinTargetG == 0 {
+		current, err := queryCurrentWeight(db, today)
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("weight query error: %v", err))
+		} else if current != nil {
+			b.Protein.TargetG = settings.User.ProteinTarget(*current)
+			b.Protein.WeightKg = *current
+		}
+	}
 
 	// Get protein for today
 	protein, err := queryDayTotal(db, "protein", today)
@@ -227,12 +392,52 @@ func getEveningHealthData(b *EveningBriefing, today, yesterday string) {
 		b.Protein.RemainingG, b.Protein.OnTrack = CalculateProteinStatus(protein, float64(b.Protein.TargetG))
 	}
 
+	eating, err := queryEatingWindow(db, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("eating window query error: %v", err))
+	} else {
+		b.Eating = eating
+	}
+
+	meals, err := queryMeals(db, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("meals query error: %v", err))
+	} else {
+		b.Meals, b.LateKcalPct = meals, lateKcalPct(meals)
+	}
+
+	micros, err := queryMicronutrients(db, settings.Micronutrients, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("micronutrients query error: %v", err))
+	} else {
+		b.Micronutrients = micros
+	}
+
+	// Get water intake for today
+	water, err := queryDayTotal(db, "dietary_water", today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("dietary_water query error: %v", err))
+	} else {
+		b.Hydration.ConsumedMl = water
+	}
+
 	// Get steps for today
 	steps, err := queryDayTotal(db, "steps", today)
 	if err != nil {
 		b.Errors = append(b.Errors, fmt.Sprintf("steps query error: %v", err))
 	} else {
 		b.Activity.Steps = int(steps)
+		b.Activity.StepGoalPct = stepGoalPct(b.Activity.Steps, b.Activity.StepGoal)
+	}
+
+	stepsAvg, err := queryStepsAverage(db, yesterday, StepsAverageDays)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("steps average query error: %v", err))
+	} else if stepsAvg != nil {
+		b.Activity.Steps7dAvg = int(*stepsAvg)
+		if sedentary(stepsAvg, settings.User.SedentarySteps) {
+			b.Activity.Flags = append(b.Activity.Flags, FlagSedentary)
+		}
 	}
 
 	// Get stand hours for today
@@ -243,6 +448,12 @@ func getEveningHealthData(b *EveningBriefing, today, yesterday string) {
 		b.Activity.StandHours = int(standHours)
 	}
 
+	exerciseMin, err := queryDayTotal(db, "exercise_minutes", today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("exercise_minutes query error: %v", err))
+	}
+	b.Rings = activityRingsFor(b.Energy.ActiveKcal, exerciseMin, b.Activity.StandHours, settings.Rings)
+
 	// Get HRV for today
 	hrvToday, err := queryAverageHRV(db, today)
 	if err == nil && hrvToday != nil {
@@ -271,29 +482,54 @@ func getEveningHealthData(b *EveningBriefing, today, yesterday string) {
 	if err == nil && sleepDeep != nil {
 		b.Recovery.SleepLastNight.DeepHrs = *sleepDeep
 	}
+
+	weight, err := queryWeight(db, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("weight query error: %v", err))
+	} else {
+		b.Weight = weight
+	}
+	if b.Weight != nil {
+		comp, err := queryBodyComposition(db, today, b.Energy.BMRKcal)
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("body composition query error: %v", err))
+		} else {
+			b.Weight.Composition = comp
+		}
+	}
+	if b.Weight != nil && settings.User.GoalWeightKg > 0 {
+		balance, err := queryAverageBalance(db, yesterday, BalanceDays, b.Energy.BMRKcal)
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("goal weight query error: %v", err))
+		} else {
+			b.Energy.Goal = goalWeightFor(b.Weight, settings.User.GoalWeightKg, balance, today)
+		}
+	}
 }
 
+// queryDayTotal sums a metric for a date (see dayTotalSQL for multi-device dedup)
 func queryDayTotal(db *sql.DB, metricName, date string) (float64, error) {
-	query := `
-		SELECT COALESCE(SUM(value), 0) FROM metrics 
-		WHERE metric_name = ? 
-		AND timestamp LIKE ? || '%'
-	`
 	var total float64
-	err := db.QueryRow(query, metricName, date).Scan(&total)
+	err := db.QueryRow(dayTotalSQL(metricName), metricName, date).Scan(&total)
 	return total, err
 }
 
+// queryLatestValue returns the latest value for a date from the preferred source
 func queryLatestValue(db *sql.DB, metricName, date string) (*float64, error) {
+	order := "local_ts(timestamp) DESC"
+	rank, rankArgs := sourceRankSQL(metricName)
+	if len(rankArgs) > 0 {
+		order = rank + ", " + order
+	}
 	query := `
 		SELECT value FROM metrics 
 		WHERE metric_name = ? 
-		AND timestamp LIKE ? || '%'
-		ORDER BY timestamp DESC 
+		AND local_ts(timestamp) LIKE ? || '%'
+		ORDER BY ` + order + ` 
 		LIMIT 1
 	`
 	var value sql.NullFloat64
-	err := db.QueryRow(query, metricName, date).Scan(&value)
+	err := db.QueryRow(query, append([]any{metricName, date}, rankArgs...)...).Scan(&value)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -314,6 +550,7 @@ func getEveningWorkoutData(b *EveningBriefing, today string) {
 		b.Activity.Workout = &WorkoutInfo{Done: false}
 		return
 	}
+	dumpRaw("hevy-workouts", output)
 
 	var workouts []HevyWorkout
 	if err := json.Unmarshal(output, &workouts); err != nil {
@@ -325,7 +562,7 @@ func getEveningWorkoutData(b *EveningBriefing, today string) {
 	// Check if any workout is from today
 	b.Activity.Workout = &WorkoutInfo{Done: false}
 	for _, w := range workouts {
-		if strings.HasPrefix(w.StartTime, today) {
+		if t, err := localTime(w.StartTime); err == nil && t.Format("2006-01-02") == today {
 			b.Activity.Workout = &WorkoutInfo{
 				Done:     true,
 				Title:    w.Title,
@@ -337,38 +574,26 @@ func getEveningWorkoutData(b *EveningBriefing, today string) {
 }
 
 func getEveningProtocolData(b *EveningBriefing, today string) {
-	cmd := exec.Command("td", "today", "--json")
-	output, err := cmd.Output()
+	tasks, err := todoistToday(today)
 	if err != nil {
 		b.Errors = append(b.Errors, fmt.Sprintf("todoist error: %v", err))
 		return
 	}
 
-	var resp TodoistResponse
-	if err := json.Unmarshal(output, &resp); err != nil {
-		b.Errors = append(b.Errors, fmt.Sprintf("todoist JSON parse error: %v", err))
-		return
-	}
-
-	for _, task := range resp.Results {
-		// Check if it's a med/protocol task
-		isMed := false
-		for _, label := range task.Labels {
-			if label == "💊Meds" || label == "💉" {
-				isMed = true
-				break
-			}
-		}
-		if !isMed {
+	for _, task := range tasks {
+		if !isMedTask(task) {
 			continue
 		}
 
+		bucket := b.Protocols.Categories.bucket(medCategory(settings.MedCategories, task))
 		if task.IsCompleted {
 			b.Protocols.Completed = append(b.Protocols.Completed, task.Content)
+			bucket.Completed = append(bucket.Completed, task.Content)
 		} else {
 			// Check if overdue or just not done yet today
 			if task.Due != nil && task.Due.Date <= today {
 				b.Protocols.Missed = append(b.Protocols.Missed, task.Content)
+				bucket.Missed = append(bucket.Missed, task.Content)
 			}
 		}
 	}
@@ -385,9 +610,14 @@ func getTomorrowData(b *EveningBriefing, today string) {
 }
 
 func getTomorrowCalendar(b *EveningBriefing, tomorrow string) {
-	// Personal calendar
-	events := getCalendarEventsForDate(b, tomorrow, "jai@govindani.com")
-	events = append(events, getCalendarEventsForDate(b, tomorrow, "jai.g@ewa-services.com")...)
+	var events []calendarEventWithTime
+	for _, c := range settings.Calendars {
+		events = append(events, getCalendarEventsForDate(b, tomorrow, c)...)
+	}
+	if b.Travel != nil {
+		sortTrips(b.Travel.Trips)
+		b.Travel.PackingReminder = true // the trip starts tomorrow: pack tonight
+	}
 
 	if len(events) == 0 {
 		return
@@ -422,40 +652,34 @@ type calendarEventWithTime struct {
 	parsedTime time.Time
 }
 
-func getCalendarEventsForDate(b *EveningBriefing, date, account string) []calendarEventWithTime {
-	cmd := exec.Command("gog", "calendar", "events", "--account="+account, "--json")
-	output, err := cmd.Output()
+func getCalendarEventsForDate(b *EveningBriefing, date string, account CalendarAccount) []calendarEventWithTime {
+	fetched, err := calendarEvents(account, date, 1, "calendar-tomorrow")
 	if err != nil {
 		return nil
 	}
 
-	var resp GogCalendarResponse
-	if err := json.Unmarshal(output, &resp); err != nil {
-		return nil
-	}
-
 	var events []calendarEventWithTime
-	for _, e := range resp.Events {
+	for _, e := range fetched {
+		if trip, ok := detectTrip(e, account.Source, settings.Trips); ok && trip.Date == date {
+			if b.Travel == nil {
+				b.Travel = &TravelData{}
+			}
+			b.Travel.Trips = append(b.Travel.Trips, trip)
+		}
+
 		startTime := e.Start.DateTime
 		if startTime == "" {
 			continue // Skip all-day events
 		}
 
-		if !strings.HasPrefix(startTime, date) {
-			continue
-		}
-
-		t, err := time.Parse(time.RFC3339, startTime)
-		if err != nil {
+		t, err := localTime(startTime)
+		if err != nil || t.Format("2006-01-02") != date {
 			continue
 		}
 
 		events = append(events, calendarEventWithTime{
-			CalendarEvent: CalendarEvent{
-				Time:    t.Format("15:04"),
-				Summary: e.Summary,
-			},
-			parsedTime: t,
+			CalendarEvent: calendarEvent(e, t, ""),
+			parsedTime:    t,
 		})
 	}
 
@@ -464,27 +688,13 @@ func getCalendarEventsForDate(b *EveningBriefing, date, account string) []calend
 
 func getTomorrowMeds(b *EveningBriefing, tomorrow string) {
 	// Query Todoist for tomorrow's meds
-	cmd := exec.Command("td", "filter", fmt.Sprintf("due: %s", tomorrow), "--json")
-	output, err := cmd.Output()
+	tasks, err := todoistFilter(fmt.Sprintf("due: %s", tomorrow), "todoist-tomorrow")
 	if err != nil {
-		// Try alternative: list upcoming
-		return
-	}
-
-	var resp TodoistResponse
-	if err := json.Unmarshal(output, &resp); err != nil {
 		return
 	}
 
-	for _, task := range resp.Results {
-		isMed := false
-		for _, label := range task.Labels {
-			if label == "💊Meds" || label == "💉" {
-				isMed = true
-				break
-			}
-		}
-		if isMed {
+	for _, task := range tasks {
+		if isMedTask(task) {
 			b.Tomorrow.MedsDue = append(b.Tomorrow.MedsDue, task.Content)
 		}
 	}
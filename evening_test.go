@@ -176,23 +176,81 @@ func TestCalculateProteinRemaining(t *testing.T) {
 	}
 }
 
+func TestEveningProteinTargetFromWeight(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('weight_body_mass', '2024-01-10 07:00:00 +0700', 81, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-14 07:00:00 +0700', 80.4, 'kg', 'Scale'),
+		('protein', '2024-01-15 12:00:00 +0700', 100, 'g', 'briefing')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2.0 g/kg of the latest 80.4 kg weigh-in
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if e.Protein.TargetG != 161 || e.Protein.WeightKg != 80.4 || e.Protein.RemainingG != 61 {
+		t.Errorf("Protein = %+v, want a 161 g target from 80.4 kg", e.Protein)
+	}
+
+	// A fixed target overrides the weight
+	settings.User.ProteinTargetG = 152
+	e = newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if e.Protein.TargetG != 152 || e.Protein.WeightKg != 0 {
+		t.Errorf("Protein = %+v, want the fixed 152 g", e.Protein)
+	}
+}
+
 // ==================== CLI FLAG PARSING TESTS ====================
 
 func TestParseMode(t *testing.T) {
 	tests := []struct {
 		name         string
+		mode         string
 		morning      bool
 		evening      bool
+		weekly       bool
 		expectedMode string
 		expectError  bool
 	}{
 		{
-			name:         "No flags (default morning)",
+			name:         "No flags (default auto)",
 			morning:      false,
 			evening:      false,
-			expectedMode: "morning",
+			expectedMode: "auto",
 			expectError:  false,
 		},
+		{
+			name:         "Explicit --mode=midday",
+			mode:         "midday",
+			expectedMode: "midday",
+			expectError:  false,
+		},
+		{
+			name:         "Explicit --mode=auto",
+			mode:         "auto",
+			expectedMode: "auto",
+			expectError:  false,
+		},
+		{
+			name:         "Unknown --mode (error)",
+			mode:         "brunch",
+			expectedMode: "",
+			expectError:  true,
+		},
+		{
+			name:         "--mode with --evening (error)",
+			mode:         "morning",
+			evening:      true,
+			expectedMode: "",
+			expectError:  true,
+		},
 		{
 			name:         "Explicit morning",
 			morning:      true,
@@ -207,6 +265,14 @@ func TestParseMode(t *testing.T) {
 			expectedMode: "evening",
 			expectError:  false,
 		},
+		{
+			name:         "Explicit weekly",
+			morning:      false,
+			evening:      false,
+			weekly:       true,
+			expectedMode: "weekly",
+			expectError:  false,
+		},
 		{
 			name:         "Both flags (error)",
 			morning:      true,
@@ -214,11 +280,19 @@ func TestParseMode(t *testing.T) {
 			expectedMode: "",
 			expectError:  true,
 		},
+		{
+			name:         "Evening and weekly (error)",
+			morning:      false,
+			evening:      true,
+			weekly:       true,
+			expectedMode: "",
+			expectError:  true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mode, err := ParseMode(tt.morning, tt.evening)
+			mode, err := ParseMode(tt.mode, tt.morning, tt.evening, tt.weekly)
 			if tt.expectError {
 				if err == nil {
 					t.Errorf("ParseMode() expected error, got nil")
@@ -392,8 +466,8 @@ func TestUserStatsConstants(t *testing.T) {
 	if UserIsMale != true {
 		t.Error("UserIsMale = false, want true")
 	}
-	if UserProteinTargetG != 152 {
-		t.Errorf("UserProteinTargetG = %d, want %d", UserProteinTargetG, 152)
+	if UserProteinGPerKg != 2.0 {
+		t.Errorf("UserProteinGPerKg = %.1f, want %.1f", UserProteinGPerKg, 2.0)
 	}
 
 	// Verify BMR calculation matches expected
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// ==================== EXPRESSION TESTS ====================
+
+func TestEvalBool(t *testing.T) {
+	vars := map[string]any{
+		"hrv":             30.0,
+		"hrv_baseline":    50.0,
+		"sleep.total":     5.5,
+		"recovery_status": "POOR",
+		"meds.overdue":    0.0,
+		"workout.done":    true,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"hrv < hrv_baseline * 0.7 and sleep.total < 6", true},
+		{"hrv < hrv_baseline * 0.5", false},
+		{"hrv >= 30", true},
+		{"hrv > 30", false},
+		{"hrv <= 30 && sleep.total != 5.5", false},
+		{"recovery_status == \"POOR\"", true},
+		{"recovery_status == 'GOOD' or meds.overdue == 0", true},
+		{"not (meds.overdue == 0)", false},
+		{"!workout.done", false},
+		{"workout.done == true", true},
+		{"(hrv + 10) / 2 == 20", true},
+		{"-hrv < -20", true},
+		{"1 + 2 * 3 == 7", true},
+		{"sleep.total - 0.5 == 5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			node, err := ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpr() error: %v", err)
+			}
+			got, err := EvalBool(node, vars)
+			if err != nil {
+				t.Fatalf("EvalBool() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EvalBool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"hrv <",
+		"(hrv < 5",
+		"hrv < 5)",
+		"hrv < 'POOR",
+		"hrv ^ 2",
+		"and hrv",
+		"hrv 5",
+	}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			if _, err := ParseExpr(src); err == nil {
+				t.Errorf("ParseExpr(%q) expected error, got nil", src)
+			}
+		})
+	}
+}
+
+func TestEvalBoolMissingVariable(t *testing.T) {
+	node, err := ParseExpr("hrv < hrv_baseline * 0.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]any{"hrv": 30.0, "hrv_baseline": nil}
+	if _, err := EvalBool(node, vars); !errors.Is(err, errMissingVariable) {
+		t.Errorf("EvalBool() error = %v, want errMissingVariable", err)
+	}
+}
+
+func TestEvalBoolShortCircuit(t *testing.T) {
+	// The missing right-hand side is never evaluated
+	node, err := ParseExpr("hrv > 100 and missing < 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := EvalBool(node, map[string]any{"hrv": 30.0})
+	if err != nil || got {
+		t.Errorf("EvalBool() = %v, %v; want false, nil", got, err)
+	}
+}
+
+func TestEvalBoolTypeErrors(t *testing.T) {
+	vars := map[string]any{"hrv": 30.0, "recovery_status": "POOR"}
+	tests := []string{
+		"hrv",
+		"recovery_status < 5",
+		"hrv and true",
+		"not hrv",
+		"hrv / 0 > 1",
+	}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			node, err := ParseExpr(src)
+			if err != nil {
+				t.Fatalf("ParseExpr() error: %v", err)
+			}
+			if _, err := EvalBool(node, vars); err == nil {
+				t.Errorf("EvalBool(%q) expected error, got nil", src)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// FastingLookbackDays is how far back the morning looks for the last meal
+const FastingLookbackDays = 2
+
+// FastingData is the time since the last logged meal, for the morning briefing
+type FastingData struct {
+	LastMealAt string  `json:"last_meal_at"` // YYYY-MM-DD HH:MM
+	Hours      float64 `json:"fasting_hours"`
+}
+
+// EatingWindow is the span of the day's dietary_energy entries
+type EatingWindow struct {
+	FirstMeal string  `json:"first_meal"` // HH:MM
+	LastMeal  string  `json:"last_meal"`  // HH:MM, when the window closed
+	Hours     float64 `json:"hours"`
+}
+
+// queryFasting finds the latest dietary_energy entry at or before now in the
+// FastingLookbackDays before it; nil when there is none
+func queryFasting(db *sql.DB, now time.Time) (*FastingData, error) {
+	today := now.Format("2006-01-02")
+	rows, err := db.Query(`
+		SELECT timestamp
+		FROM metrics
+		WHERE metric_name = 'dietary_energy' AND value > 0
+		AND local_ts(timestamp) >= ? AND local_ts(timestamp) < ?
+	`, addDays(today, -FastingLookbackDays), addDays(today, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var last time.Time
+	for rows.Next() {
+		var ts string
+		if err := rows.Scan(&ts); err != nil {
+			return nil, err
+		}
+		at, err := time.Parse(sleepSessionLayout, ts)
+		if err != nil || at.After(now) {
+			continue
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+	if err := rows.Err(); err != nil || last.IsZero() {
+		return nil, err
+	}
+	return &FastingData{
+		LastMealAt: last.In(time.Local).Format(glucoseTimeLayout),
+		Hours:      math.Round(now.Sub(last).Hours()*10) / 10,
+	}, nil
+}
+
+// queryEatingWindow spans the first to the last dietary_energy entry on date;
+// nil when nothing was logged
+func queryEatingWindow(db *sql.DB, date string) (*EatingWindow, error) {
+	var first, last sql.NullString
+	err := db.QueryRow(`
+		SELECT MIN(substr(local_ts(timestamp), 1, 16)), MAX(substr(local_ts(timestamp), 1, 16))
+		FROM metrics
+		WHERE metric_name = 'dietary_energy' AND value > 0
+		AND local_ts(timestamp) LIKE ? || '%'
+	`, date).Scan(&first, &last)
+	if err != nil || !first.Valid {
+		return nil, err
+	}
+	return &EatingWindow{
+		FirstMeal: first.String[11:],
+		LastMeal:  last.String[11:],
+		Hours:     math.Round(minutesBetween(first.String, last.String)/6) / 10,
+	}, nil
+}
+
+// fastingValue formats the fast, e.g. "13.5 h since 20:15"
+func fastingValue(f *FastingData) string {
+	return fmt.Sprintf("%.1f h since %s", f.Hours, f.LastMealAt[11:])
+}
+
+// eatingWindowValue formats the window, e.g. "closed at 19:40 (08:10-19:40, 11.5 h)"
+func eatingWindowValue(w *EatingWindow) string {
+	return fmt.Sprintf("closed at %s (%s-%s, %.1f h)", w.LastMeal, w.FirstMeal, w.LastMeal, w.Hours)
+}
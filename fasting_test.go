@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== FASTING TESTS ====================
+
+func TestQueryFasting(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('dietary_energy', '2024-01-14 12:30:00 +0700', 650, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-14 20:15:00 +0700', 700, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-14 21:00:00 +0700', 0, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-15 12:00:00 +0700', 500, 'kcal', 'briefing')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The zero-kcal row and today's lunch (after now) are skipped
+	b := &MorningBriefing{TargetDate: "2024-01-15", GeneratedAt: "2024-01-15T07:45:00+07:00"}
+	fillMorningHealthFromDB(b, db, "2024-01-15", nil)
+	if b.Fasting == nil || *b.Fasting != (FastingData{LastMealAt: "2024-01-14 20:15", Hours: 11.5}) {
+		t.Fatalf("Fasting = %+v, want 11.5 h since 2024-01-14 20:15", b.Fasting)
+	}
+	if md := MorningMarkdown(*b); !strings.Contains(md, "| Fasting | 11.5 h since 20:15 |") {
+		t.Errorf("markdown missing fasting row:\n%s", md)
+	}
+	if vars := morningRuleVars(*b); vars["fasting_hours"] != 11.5 {
+		t.Errorf("fasting_hours = %v, want 11.5", vars["fasting_hours"])
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-01-20T07:45:00+07:00")
+	if f, err := queryFasting(db, now); err != nil || f != nil {
+		t.Errorf("queryFasting() past the lookback = %+v, %v; want nil", f, err)
+	}
+}
+
+func TestQueryEatingWindow(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('dietary_energy', '2024-01-14 22:00:00 +0700', 300, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-15 08:10:00 +0700', 400, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-15 13:00:00 +0700', 650, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-15 19:40:00 +0700', 800, 'kcal', 'briefing')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if e.Eating == nil || *e.Eating != (EatingWindow{FirstMeal: "08:10", LastMeal: "19:40", Hours: 11.5}) {
+		t.Fatalf("Eating = %+v, want 08:10-19:40", e.Eating)
+	}
+	if md := EveningMarkdown(e); !strings.Contains(md, "Eating window closed at 19:40 (08:10-19:40, 11.5 h)\n") {
+		t.Errorf("markdown missing eating window:\n%s", md)
+	}
+	if text := EveningText(e, textStyle{}); !strings.Contains(text, "Window   closed at 19:40") {
+		t.Errorf("text missing eating window:\n%s", text)
+	}
+
+	if w, err := queryEatingWindow(db, "2024-01-16"); err != nil || w != nil {
+		t.Errorf("queryEatingWindow() with nothing logged = %+v, %v; want nil", w, err)
+	}
+}
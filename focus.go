@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// FocusData is today's priority tasks for the morning Focus section
+type FocusData struct {
+	Tasks []FocusTask `json:"tasks"`
+	More  int         `json:"more,omitempty"` // matching tasks left out by focus.max_items
+}
+
+// FocusTask is one priority task
+type FocusTask struct {
+	Name     string `json:"name"`
+	Priority string `json:"priority"` // p1-p4
+	DueDate  string `json:"due_date,omitempty"`
+	Overdue  bool   `json:"overdue,omitempty"`
+}
+
+// validateFocus checks the filter and cap
+func validateFocus(cfg FocusConfig) error {
+	if cfg.Filter == "" {
+		return errors.New("focus.filter must not be empty")
+	}
+	if cfg.MaxItems <= 0 {
+		return errors.New("focus.max_items must be positive")
+	}
+	return nil
+}
+
+// todoistPriority converts the API's priority (4 is urgent) to the p1-p4 shown in the app
+func todoistPriority(p int) string {
+	if p < 1 || p > 4 {
+		p = 1
+	}
+	return fmt.Sprintf("p%d", 5-p)
+}
+
+// getFocusData lists the tasks matching focus.filter, leaving out meds
+func getFocusData(b *MorningBriefing, today string) {
+	tasks, err := todoistFilter(settings.Focus.Filter, "todoist-focus")
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("focus error: %v", err))
+		return
+	}
+	b.Focus = focusFor(tasks, today, settings.Focus.MaxItems)
+}
+
+// focusFor orders open non-med tasks by priority, then overdue first, then
+// due date, keeping the first max; nil when none match
+func focusFor(tasks []TodoistTask, today string, max int) *FocusData {
+	var focus []FocusTask
+	for _, t := range tasks {
+		if t.IsCompleted || isMedTask(t) {
+			continue
+		}
+		f := FocusTask{Name: t.Content, Priority: todoistPriority(t.Priority)}
+		if t.Due != nil {
+			f.DueDate = t.Due.Date
+			f.Overdue = t.Due.Date < today
+		}
+		focus = append(focus, f)
+	}
+	if len(focus) == 0 {
+		return nil
+	}
+	sort.SliceStable(focus, func(i, j int) bool {
+		a, b := focus[i], focus[j]
+		switch {
+		case a.Priority != b.Priority:
+			return a.Priority < b.Priority
+		case a.Overdue != b.Overdue:
+			return a.Overdue
+		}
+		return a.DueDate < b.DueDate
+	})
+	d := &FocusData{Tasks: focus}
+	if len(focus) > max {
+		d.Tasks, d.More = focus[:max], len(focus)-max
+	}
+	return d
+}
+
+// focusLine formats a task, e.g. "p1 Send the contract (overdue)"
+func focusLine(t FocusTask) string {
+	line := t.Priority + " " + t.Name
+	if t.Overdue {
+		line += " (overdue)"
+	}
+	return line
+}
+
+// focusLines lists the tasks, ending with how many more were left out
+func focusLines(f *FocusData) []string {
+	if f == nil {
+		return nil
+	}
+	var lines []string
+	for _, t := range f.Tasks {
+		lines = append(lines, focusLine(t))
+	}
+	if f.More > 0 {
+		lines = append(lines, fmt.Sprintf("+%d more", f.More))
+	}
+	return lines
+}
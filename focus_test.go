@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== FOCUS TESTS ====================
+
+func TestValidateFocus(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         FocusConfig
+		expectError bool
+	}{
+		{"defaults", DefaultConfig().Focus, false},
+		{"empty filter", FocusConfig{MaxItems: 5}, true},
+		{"no items", FocusConfig{Filter: "today & p1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFocus(tt.cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateFocus() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestTodoistPriority(t *testing.T) {
+	for api, want := range map[int]string{4: "p1", 3: "p2", 2: "p3", 1: "p4", 0: "p4"} {
+		if got := todoistPriority(api); got != want {
+			t.Errorf("todoistPriority(%d) = %q, want %q", api, got, want)
+		}
+	}
+}
+
+func TestFocusFor(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.MedLabels = []string{"💊Meds"}
+
+	due := func(date string) *TodoistDue { return &TodoistDue{Date: date} }
+	tasks := []TodoistTask{
+		{Content: "Review PR", Priority: 3, Due: due("2024-01-15")},
+		{Content: "Nexium", Priority: 4, Labels: []string{"💊Meds"}, Due: due("2024-01-15")},
+		{Content: "Send contract", Priority: 4, Due: due("2024-01-15")},
+		{Content: "File taxes", Priority: 4, Due: due("2024-01-12")},
+		{Content: "Book flights", Priority: 3, Due: due("2024-01-14")},
+		{Content: "Done already", Priority: 4, IsCompleted: true},
+	}
+
+	got := focusFor(tasks, "2024-01-15", 3)
+	want := &FocusData{Tasks: []FocusTask{
+		{Name: "File taxes", Priority: "p1", DueDate: "2024-01-12", Overdue: true},
+		{Name: "Send contract", Priority: "p1", DueDate: "2024-01-15"},
+		{Name: "Book flights", Priority: "p2", DueDate: "2024-01-14", Overdue: true},
+	}, More: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("focusFor() = %+v, want %+v", got, want)
+	}
+	if lines := focusLines(got); !reflect.DeepEqual(lines, []string{"p1 File taxes (overdue)", "p1 Send contract", "p2 Book flights (overdue)", "+1 more"}) {
+		t.Errorf("focusLines() = %q", lines)
+	}
+	if got := focusFor(tasks[1:2], "2024-01-15", 3); got != nil {
+		t.Errorf("focusFor() with only meds = %+v, want nil", got)
+	}
+}
+
+func TestFocusRendering(t *testing.T) {
+	m := MorningBriefing{Focus: &FocusData{Tasks: []FocusTask{{Name: "File taxes", Priority: "p1", Overdue: true}}, More: 2}}
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Focus\n\n- p1 File taxes (overdue)\n- +2 more\n\n## Meds") {
+		t.Errorf("markdown missing focus:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Focus\n  p1 File taxes (overdue)\n  +2 more\n") {
+		t.Errorf("text missing focus:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Priority tasks:\n- p1 File taxes (overdue)\n") {
+		t.Errorf("prompt missing focus (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["focus.count"] != 3.0 {
+		t.Errorf("focus.count = %v, want 3", vars["focus.count"])
+	}
+	if md := MorningMarkdown(MorningBriefing{}); strings.Contains(md, "Focus") {
+		t.Errorf("markdown shows focus without tasks:\n%s", md)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// validateWorkHours checks the window, minimum block, and low threshold
+func validateWorkHours(cfg WorkHoursConfig) error {
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return fmt.Errorf("work_hours.start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return fmt.Errorf("work_hours.end: %w", err)
+	}
+	if end <= start {
+		return errors.New("work_hours.end must be after start")
+	}
+	if cfg.MinMinutes < 0 {
+		return errors.New("work_hours.min_minutes must not be negative")
+	}
+	if cfg.LowHours < 0 {
+		return errors.New("work_hours.low_hours must not be negative")
+	}
+	return nil
+}
+
+// focusHours totals the free time within the work hours around today's
+// events, leaving out gaps shorter than cfg.MinMinutes, to the nearest tenth
+// of an hour
+func focusHours(events []CalendarEvent, cfg WorkHoursConfig) float64 {
+	from, _ := parseClock(cfg.Start)
+	until, _ := parseClock(cfg.End)
+	minutes := 0
+	for _, f := range freeBlocks(events, from, until) {
+		if f.end-f.start >= cfg.MinMinutes {
+			minutes += f.end - f.start
+		}
+	}
+	return math.Round(float64(minutes)/6) / 10
+}
+
+// focusTimeValue formats hours of focus time, e.g. "1.5h"
+func focusTimeValue(hours float64) string {
+	return strconv.FormatFloat(hours, 'f', -1, 64) + "h"
+}
+
+// focusTimeLine is shown under the calendar, e.g.
+// "Focus time: 1.5h between 09:00 and 17:00"
+func focusTimeLine(hours *float64, cfg WorkHoursConfig) string {
+	if hours == nil {
+		return ""
+	}
+	return fmt.Sprintf("Focus time: %s between %s and %s", focusTimeValue(*hours), cfg.Start, cfg.End)
+}
+
+// lowFocusTime reports whether the day has less focus time than
+// work_hours.low_hours
+func lowFocusTime(hours *float64, cfg WorkHoursConfig) bool {
+	return hours != nil && *hours < cfg.LowHours
+}
+
+// focusTimeNote is appended to the recommendation when focus time is short
+func focusTimeNote(hours *float64, cfg WorkHoursConfig) string {
+	switch {
+	case !lowFocusTime(hours, cfg):
+		return ""
+	case *hours == 0:
+		return " No focus time between meetings today."
+	}
+	return fmt.Sprintf(" Only %s of focus time today — protect it.", focusTimeValue(*hours))
+}
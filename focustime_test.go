@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ==================== FOCUS TIME TESTS ====================
+
+func TestFocusHours(t *testing.T) {
+	cfg := DefaultConfig().WorkHours // 09:00-17:00, 30 min gaps
+	ev := func(start, end string) CalendarEvent { return CalendarEvent{Time: start, EndTime: end} }
+	tests := []struct {
+		name     string
+		events   []CalendarEvent
+		expected float64
+	}{
+		{"empty day", nil, 8},
+		{"meetings through the day", []CalendarEvent{ev("09:00", "10:00"), ev("11:30", "13:00"), ev("14:00", "16:30")}, 3},
+		{"short gaps don't count", []CalendarEvent{ev("09:00", "10:00"), ev("10:15", "12:00"), ev("12:20", "15:30")}, 1.5},
+		{"outside work hours", []CalendarEvent{ev("07:00", "09:30"), ev("16:00", "19:00")}, 6.5},
+		{"declined events are free", []CalendarEvent{{Time: "09:00", EndTime: "17:00", Response: "declined"}}, 8},
+		{"rounded to a tenth", []CalendarEvent{ev("09:00", "16:10")}, 0.8},
+		{"fully booked", []CalendarEvent{ev("08:00", "18:00")}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := focusHours(tt.events, cfg); got != tt.expected {
+				t.Errorf("focusHours() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateWorkHours(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         WorkHoursConfig
+		expectError bool
+	}{
+		{"default", DefaultConfig().WorkHours, false},
+		{"no minimum", WorkHoursConfig{Start: "09:00", End: "17:00"}, false},
+		{"bad end", WorkHoursConfig{Start: "09:00", End: "5pm"}, true},
+		{"end before start", WorkHoursConfig{Start: "17:00", End: "09:00"}, true},
+		{"negative minimum", WorkHoursConfig{Start: "09:00", End: "17:00", MinMinutes: -1}, true},
+		{"negative low hours", WorkHoursConfig{Start: "09:00", End: "17:00", LowHours: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWorkHours(tt.cfg); (err != nil) != tt.expectError {
+				t.Errorf("validateWorkHours() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestFocusTimeNote(t *testing.T) {
+	cfg := DefaultConfig().WorkHours // low under 2h
+	tests := []struct {
+		hours    *float64
+		expected string
+	}{
+		{nil, ""},
+		{ptr(3.0), ""},
+		{ptr(2.0), ""},
+		{ptr(1.5), " Only 1.5h of focus time today — protect it."},
+		{ptr(0.0), " No focus time between meetings today."},
+	}
+	for _, tt := range tests {
+		if got := focusTimeNote(tt.hours, cfg); got != tt.expected {
+			t.Errorf("focusTimeNote(%v) = %q, want %q", tt.hours, got, tt.expected)
+		}
+	}
+}
+
+func TestMorningFocusTime(t *testing.T) {
+	inZone(t, "UTC")
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Calendars = []CalendarAccount{{Account: "me@example.com", Source: "work"}}
+	fakeGog(t, `{"events": [
+		{"summary": "Planning", "start": {"dateTime": "2024-01-15T09:00:00Z"}, "end": {"dateTime": "2024-01-15T12:00:00Z"}},
+		{"summary": "Lunch", "start": {"dateTime": "2024-01-15T12:15:00Z"}, "end": {"dateTime": "2024-01-15T13:00:00Z"}},
+		{"summary": "Review", "start": {"dateTime": "2024-01-15T14:30:00Z"}, "end": {"dateTime": "2024-01-15T17:00:00Z"}}
+	]}`)
+
+	b := &MorningBriefing{TargetDate: "2024-01-15", Sleep: SleepData{TotalHours: ptr(7.5), DataAvailable: true}}
+	getCalendarData(b, "2024-01-15")
+	if b.Calendar.FocusHours == nil || *b.Calendar.FocusHours != 1.5 {
+		t.Fatalf("focus hours = %v, errors %v", b.Calendar.FocusHours, b.Errors)
+	}
+	classify(b)
+	if !strings.HasSuffix(b.Classification.Recommendation, " Only 1.5h of focus time today — protect it.") {
+		t.Errorf("recommendation = %q", b.Classification.Recommendation)
+	}
+
+	m := *b
+	line := "Focus time: 1.5h between 09:00 and 17:00"
+	if md := MorningMarkdown(m); !strings.Contains(md, "\n"+line+"\n") {
+		t.Errorf("markdown missing focus time:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "  "+line+"\n") {
+		t.Errorf("text missing focus time:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Focus time: 1.5h within work hours\n") {
+		t.Errorf("prompt missing focus time (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["calendar.focus_hours"] != 1.5 {
+		t.Errorf("calendar.focus_hours = %v", vars["calendar.focus_hours"])
+	}
+}
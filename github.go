@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// githubTimeout bounds each GitHub API request
+const githubTimeout = 15 * time.Second
+
+// Searches for the Work section; @me is the token's user
+const (
+	githubReviewQuery  = "is:pr is:open archived:false review-requested:@me"
+	githubFailingQuery = "is:pr is:open archived:false author:@me status:failure"
+)
+
+// WorkData is the pull requests waiting on you this morning
+type WorkData struct {
+	ReviewRequests []PullRequest `json:"review_requests"`        // asking for your review, most recently updated first
+	FailingChecks  []PullRequest `json:"failing_checks"`         // yours, with a failing check
+	MoreReviews    int           `json:"more_reviews,omitempty"` // left out by github.max_items
+	MoreFailing    int           `json:"more_failing,omitempty"`
+}
+
+// PullRequest is one open pull request
+type PullRequest struct {
+	Repo   string `json:"repo"` // owner/name
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	URL    string `json:"url"`
+	Draft  bool   `json:"draft,omitempty"`
+}
+
+// validateGitHub checks the cap when a token is set
+func validateGitHub(cfg GitHubConfig) error {
+	if cfg.Token == "" {
+		return nil
+	}
+	if cfg.MaxItems <= 0 {
+		return errors.New("github.max_items must be positive")
+	}
+	return nil
+}
+
+// getWorkData lists the pull requests awaiting your review and your own
+// with failing checks when github.token is set
+func getWorkData(b *MorningBriefing, today string) {
+	cfg := settings.GitHub
+	if cfg.Token == "" {
+		return
+	}
+	client := &http.Client{Timeout: githubTimeout}
+	w := &WorkData{}
+	var err error
+	if w.ReviewRequests, w.MoreReviews, err = githubSearch(client, cfg, githubReviewQuery); err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("github error: %v", err))
+		return
+	}
+	if w.FailingChecks, w.MoreFailing, err = githubSearch(client, cfg, githubFailingQuery); err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("github error: %v", err))
+		return
+	}
+	b.Work = w
+}
+
+// githubSearch runs an issue search, returning up to github.max_items pull
+// requests and how many more matched
+func githubSearch(client *http.Client, cfg GitHubConfig, query string) ([]PullRequest, int, error) {
+	var resp struct {
+		TotalCount int `json:"total_count"`
+		Items      []struct {
+			Number        int    `json:"number"`
+			Title         string `json:"title"`
+			HTMLURL       string `json:"html_url"`
+			RepositoryURL string `json:"repository_url"` // .../repos/owner/name
+			Draft         bool   `json:"draft"`
+			User          struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		} `json:"items"`
+	}
+	u := strings.TrimRight(cfg.URL, "/") + "/search/issues?" + url.Values{
+		"q": {query}, "sort": {"updated"}, "order": {"desc"}, "per_page": {fmt.Sprint(cfg.MaxItems)},
+	}.Encode()
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return nil, 0, fmt.Errorf("github API status %d", res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, 0, fmt.Errorf("github API: invalid response: %w", err)
+	}
+
+	prs := []PullRequest{}
+	for _, item := range resp.Items {
+		if len(prs) == cfg.MaxItems {
+			break
+		}
+		_, repo, _ := strings.Cut(item.RepositoryURL, "/repos/")
+		prs = append(prs, PullRequest{Repo: repo, Number: item.Number, Title: item.Title, Author: item.User.Login, URL: item.HTMLURL, Draft: item.Draft})
+	}
+	return prs, max(resp.TotalCount-len(prs), 0), nil
+}
+
+// pullRequestLine formats a pull request, e.g. "jai/briefing#42 Add pollen (alice)"
+func pullRequestLine(pr PullRequest) string {
+	line := fmt.Sprintf("%s#%d %s", pr.Repo, pr.Number, pr.Title)
+	if pr.Draft {
+		line += " [draft]"
+	}
+	if pr.Author != "" {
+		line += " (" + pr.Author + ")"
+	}
+	return line
+}
+
+// reviewLines lists the review requests, ending with how many more were left out
+func reviewLines(w *WorkData) []string {
+	if w == nil {
+		return nil
+	}
+	return pullRequestLines(w.ReviewRequests, w.MoreReviews)
+}
+
+// failingLines lists your pull requests with failing checks
+func failingLines(w *WorkData) []string {
+	if w == nil {
+		return nil
+	}
+	return pullRequestLines(w.FailingChecks, w.MoreFailing)
+}
+
+func pullRequestLines(prs []PullRequest, more int) []string {
+	var lines []string
+	for _, pr := range prs {
+		lines = append(lines, pullRequestLine(pr))
+	}
+	if more > 0 {
+		lines = append(lines, fmt.Sprintf("+%d more", more))
+	}
+	return lines
+}
+
+// workLine sums up the section, e.g. "3 reviews requested, 1 PR failing checks"
+func workLine(w *WorkData) string {
+	reviews := len(w.ReviewRequests) + w.MoreReviews
+	failing := len(w.FailingChecks) + w.MoreFailing
+	reviewNoun, failingNoun := "reviews", "PRs"
+	if reviews == 1 {
+		reviewNoun = "review"
+	}
+	if failing == 1 {
+		failingNoun = "PR"
+	}
+	return fmt.Sprintf("%d %s requested, %d %s failing checks", reviews, reviewNoun, failing, failingNoun)
+}
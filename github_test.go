@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== GITHUB TESTS ====================
+
+// fakeGitHub answers the review and failing-checks searches for token t
+func fakeGitHub(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/issues" || r.Header.Get("Authorization") != "Bearer t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("per_page") != "2" {
+			t.Errorf("per_page = %s, want github.max_items", r.URL.Query().Get("per_page"))
+		}
+		switch r.URL.Query().Get("q") {
+		case githubReviewQuery:
+			w.Write([]byte(`{"total_count": 3, "items": [
+				{"number": 42, "title": "Add pollen", "html_url": "https://github.com/jai/briefing/pull/42", "repository_url": "https://api.github.com/repos/jai/briefing", "user": {"login": "alice"}},
+				{"number": 7, "title": "Bump deps", "html_url": "https://github.com/acme/api/pull/7", "repository_url": "https://api.github.com/repos/acme/api", "draft": true, "user": {"login": "bob"}}]}`))
+		case githubFailingQuery:
+			w.Write([]byte(`{"total_count": 1, "items": [
+				{"number": 9, "title": "Fix login", "html_url": "https://github.com/acme/web/pull/9", "repository_url": "https://api.github.com/repos/acme/web", "user": {"login": "jai"}}]}`))
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetWorkData(t *testing.T) {
+	ts := fakeGitHub(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.GitHub = GitHubConfig{Token: "t", URL: ts.URL + "/", MaxItems: 2}
+
+	b := &MorningBriefing{}
+	getWorkData(b, "2024-01-15")
+	if b.Work == nil || len(b.Errors) != 0 {
+		t.Fatalf("work = %+v, errors %v", b.Work, b.Errors)
+	}
+	want := []string{"jai/briefing#42 Add pollen (alice)", "acme/api#7 Bump deps [draft] (bob)", "+1 more"}
+	if got := reviewLines(b.Work); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("reviewLines() = %q, want %q", got, want)
+	}
+	if got := failingLines(b.Work); len(got) != 1 || got[0] != "acme/web#9 Fix login (jai)" {
+		t.Errorf("failingLines() = %q", got)
+	}
+	if got := workLine(b.Work); got != "3 reviews requested, 1 PR failing checks" {
+		t.Errorf("workLine() = %q", got)
+	}
+
+	// A bad token is reported
+	settings.GitHub.Token = "wrong"
+	b = &MorningBriefing{}
+	getWorkData(b, "2024-01-15")
+	if b.Work != nil || len(b.Errors) != 1 || b.Errors[0] != "github error: github API status 401" {
+		t.Errorf("work = %+v, errors %v", b.Work, b.Errors)
+	}
+
+	// Off without a token
+	settings.GitHub.Token = ""
+	b = &MorningBriefing{}
+	getWorkData(b, "2024-01-15")
+	if b.Work != nil || len(b.Errors) != 0 {
+		t.Errorf("work without a token = %+v, %v", b.Work, b.Errors)
+	}
+}
+
+func TestValidateGitHub(t *testing.T) {
+	if err := validateGitHub(DefaultConfig().GitHub); err != nil {
+		t.Errorf("default: %v", err)
+	}
+	if err := validateGitHub(GitHubConfig{Token: "t", MaxItems: 0}); err == nil {
+		t.Error("expected an error for max_items 0")
+	}
+}
+
+func TestWorkRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-01-15", Work: &WorkData{
+		ReviewRequests: []PullRequest{{Repo: "jai/briefing", Number: 42, Title: "Add pollen", Author: "alice", URL: "https://github.com/jai/briefing/pull/42"}},
+		FailingChecks:  []PullRequest{},
+	}}
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Work\n\n1 review requested, 0 PRs failing checks\n\nReview requested:\n\n- jai/briefing#42 Add pollen (alice)\n") || strings.Contains(md, "Failing checks:") {
+		t.Errorf("markdown missing work:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Work  1 review requested, 0 PRs failing checks\n  review jai/briefing#42 Add pollen (alice)\n") {
+		t.Errorf("text missing work:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Work: 1 review requested, 0 PRs failing checks\n- review jai/briefing#42 Add pollen (alice)\n") {
+		t.Errorf("prompt missing work (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["github.review_requests"] != 1.0 || vars["github.failing_checks"] != 0.0 {
+		t.Errorf("github vars = %v, %v", vars["github.review_requests"], vars["github.failing_checks"])
+	}
+
+	r := RedactMorningBriefing(m)
+	out, _ := json.Marshal(r.Work)
+	for _, secret := range []string{"pollen", "alice", "github.com", "jai/briefing"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("redacted work still contains %q: %s", secret, out)
+		}
+	}
+	if r.Work.ReviewRequests[0].Number != 42 || m.Work.ReviewRequests[0].Title != "Add pollen" {
+		t.Errorf("redacted work = %s, original %+v", out, m.Work)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+// Glance summary limits to keep the payload well under 1KB
+const (
+	glanceSummaryMaxLen = 40
+)
+
+// Glance is a tiny payload for watch complications and home-screen widgets
+type Glance struct {
+	GeneratedAt    string `json:"generated_at"`
+	ReadinessScore *int   `json:"readiness"`
+	SleepQuality   string `json:"sleep"`
+	RecoveryStatus string `json:"recovery"`
+	FirstEvent     string `json:"first_event"` // "09:00 Team standup"
+	MedsDue        int    `json:"meds_due"`
+	MedsOverdue    int    `json:"meds_overdue"`
+}
+
+// NewGlance condenses a morning briefing into a glance payload
+func NewGlance(b MorningBriefing) Glance {
+	g := Glance{
+		GeneratedAt:    b.GeneratedAt,
+		ReadinessScore: b.Classification.ReadinessScore,
+		SleepQuality:   b.Classification.SleepQuality,
+		RecoveryStatus: b.Classification.RecoveryStatus,
+		MedsDue:        len(b.Meds.DueToday),
+		MedsOverdue:    len(b.Meds.Overdue),
+	}
+
+	if first := firstEventOfDay(b.Calendar); first != nil {
+		g.FirstEvent = first.Time + " " + truncateRunes(first.Summary, glanceSummaryMaxLen)
+	}
+	return g
+}
+
+// firstEventOfDay returns the earliest morning or afternoon event
+func firstEventOfDay(c CalendarData) *CalendarEvent {
+	var first *CalendarEvent
+	for _, events := range [][]CalendarEvent{c.MorningEvents, c.AfternoonEvents} {
+		for i := range events {
+			if first == nil || events[i].Time < first.Time {
+				first = &events[i]
+			}
+		}
+	}
+	return first
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with an
+// ellipsis; nothing fits when n is 0 or less
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// ==================== GLANCE PAYLOAD TESTS ====================
+
+func TestNewGlanceFirstEvent(t *testing.T) {
+	b := MorningBriefing{
+		Calendar: CalendarData{
+			MorningEvents: []CalendarEvent{
+				{Time: "10:30", Summary: "Client call"},
+				{Time: "08:00", Summary: "Workout"},
+			},
+			AfternoonEvents: []CalendarEvent{{Time: "14:00", Summary: "Review"}},
+		},
+	}
+
+	if g := NewGlance(b); g.FirstEvent != "08:00 Workout" {
+		t.Errorf("FirstEvent = %q, want %q", g.FirstEvent, "08:00 Workout")
+	}
+
+	// Afternoon-only day still has a first event
+	b.Calendar.MorningEvents = nil
+	if g := NewGlance(b); g.FirstEvent != "14:00 Review" {
+		t.Errorf("FirstEvent = %q, want %q", g.FirstEvent, "14:00 Review")
+	}
+}
+
+func TestNewGlanceSizeBound(t *testing.T) {
+	b := MorningBriefing{
+		GeneratedAt: "2024-01-15T07:30:00+07:00",
+		Calendar: CalendarData{
+			MorningEvents: []CalendarEvent{{Time: "09:00", Summary: strings.Repeat("Quarterly planning ", 100)}},
+		},
+		Meds: MedsData{DueToday: make([]MedTask, 50), Overdue: make([]MedTask, 50)},
+		Classification: Classification{
+			SleepQuality:   "UNKNOWN",
+			RecoveryStatus: "UNKNOWN",
+			ReadinessScore: intPtr(100),
+		},
+	}
+
+	output, err := json.Marshal(NewGlance(b))
+	if err != nil {
+		t.Fatalf("Failed to marshal Glance: %v", err)
+	}
+	if len(output) > 1024 {
+		t.Errorf("glance payload = %d bytes, want <= 1024", len(output))
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		input    string
+		n        int
+		expected string
+	}{
+		{"Standup", 10, "Standup"},
+		{"Quarterly planning", 10, "Quarterly…"},
+		{"☕️ coffee chat", 3, "☕️…"},
+		{"Standup", 1, "…"},
+		{"Standup", 0, ""},
+		{"Standup", -1, ""},
+	}
+
+	for _, tt := range tests {
+		if result := truncateRunes(tt.input, tt.n); result != tt.expected {
+			t.Errorf("truncateRunes(%q, %d) = %q, want %q", tt.input, tt.n, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Glucose thresholds in mg/dL and clock times on the briefing day
+const (
+	GlucoseRangeLow       = 70  // time-in-range floor
+	GlucoseRangeHigh      = 180 // time-in-range ceiling
+	GlucoseSpikeMgDl      = 30  // rise after a meal that counts as a spike
+	GlucoseSpikeMinutes   = 120 // how long after a meal the peak is looked for
+	GlucoseMealGapMinutes = 30  // meal rows closer than this are one meal
+	GlucoseOvernightEnd   = "06:00"
+	GlucoseFastingEnd     = "08:00" // latest fasting reading, unless a meal comes first
+	glucoseMmolToMgDl     = 18.0
+)
+
+const glucoseTimeLayout = "2006-01-02 15:04"
+
+// GlucoseData summarizes CGM readings: last night, this morning, and how
+// yesterday's meals landed
+type GlucoseData struct {
+	OvernightAvg *float64       `json:"overnight_avg_mg_dl,omitempty"` // midnight to 06:00
+	Fasting      *float64       `json:"fasting_mg_dl,omitempty"`       // last reading before 08:00 or the first meal
+	TimeInRange  *float64       `json:"time_in_range_pct,omitempty"`   // yesterday, 70-180 mg/dL
+	Spikes       []GlucoseSpike `json:"spikes,omitempty"`              // yesterday's meals followed by a rise
+}
+
+// GlucoseSpike is a meal followed by a rise of at least GlucoseSpikeMgDl
+type GlucoseSpike struct {
+	MealTime string  `json:"meal_time"` // HH:MM
+	MealKcal float64 `json:"meal_kcal"`
+	PeakMgDl float64 `json:"peak_mg_dl"`
+	RiseMgDl float64 `json:"rise_mg_dl"`
+}
+
+// glucoseReading is one CGM sample; At is "YYYY-MM-DD HH:MM"
+type glucoseReading struct {
+	At   string
+	MgDl float64
+}
+
+// glucoseMeal is a logged meal, nearby dietary_energy rows merged
+type glucoseMeal struct {
+	At   string
+	Kcal float64
+}
+
+// queryGlucoseReadings returns blood_glucose readings from..to (inclusive)
+// oldest first, converting mmol/L rows to mg/dL
+func queryGlucoseReadings(db *sql.DB, from, to string) ([]glucoseReading, error) {
+	rows, err := db.Query(`
+		SELECT substr(local_ts(timestamp), 1, 16), value, COALESCE(unit, '')
+		FROM metrics
+		WHERE metric_name = 'blood_glucose'
+		AND local_ts(timestamp) >= ? AND local_ts(timestamp) < ?
+		ORDER BY local_ts(timestamp)
+	`, from, addDays(to, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readings []glucoseReading
+	for rows.Next() {
+		var r glucoseReading
+		var unit string
+		if err := rows.Scan(&r.At, &r.MgDl, &unit); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(strings.ToLower(unit), "mmol") {
+			r.MgDl = math.Round(r.MgDl * glucoseMmolToMgDl)
+		}
+		readings = append(readings, r)
+	}
+	return readings, rows.Err()
+}
+
+// queryGlucoseMeals returns meals from..to (inclusive) oldest first; rows
+// within GlucoseMealGapMinutes of the previous one are added to it
+func queryGlucoseMeals(db *sql.DB, from, to string) ([]glucoseMeal, error) {
+	rows, err := db.Query(`
+		SELECT substr(local_ts(timestamp), 1, 16), value
+		FROM metrics
+		WHERE metric_name = 'dietary_energy' AND value > 0
+		AND local_ts(timestamp) >= ? AND local_ts(timestamp) < ?
+		ORDER BY local_ts(timestamp)
+	`, from, addDays(to, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var meals []glucoseMeal
+	for rows.Next() {
+		var m glucoseMeal
+		if err := rows.Scan(&m.At, &m.Kcal); err != nil {
+			return nil, err
+		}
+		if n := len(meals); n > 0 && minutesBetween(meals[n-1].At, m.At) < GlucoseMealGapMinutes {
+			meals[n-1].Kcal += m.Kcal
+			continue
+		}
+		meals = append(meals, m)
+	}
+	return meals, rows.Err()
+}
+
+// minutesBetween is the minutes from a to b, both "YYYY-MM-DD HH:MM"
+func minutesBetween(a, b string) float64 {
+	ta, errA := time.Parse(glucoseTimeLayout, a)
+	tb, errB := time.Parse(glucoseTimeLayout, b)
+	if errA != nil || errB != nil {
+		return math.Inf(1)
+	}
+	return tb.Sub(ta).Minutes()
+}
+
+// queryGlucose reads yesterday's and today's readings and meals and
+// summarizes them; nil when there are no readings
+func queryGlucose(db *sql.DB, date string) (*GlucoseData, error) {
+	yesterday := addDays(date, -1)
+	readings, err := queryGlucoseReadings(db, yesterday, date)
+	if err != nil {
+		return nil, err
+	}
+	meals, err := queryGlucoseMeals(db, yesterday, date)
+	if err != nil {
+		return nil, err
+	}
+	return glucoseFor(readings, meals, date), nil
+}
+
+// glucoseFor summarizes readings and meals spanning yesterday and date
+func glucoseFor(readings []glucoseReading, meals []glucoseMeal, date string) *GlucoseData {
+	if len(readings) == 0 {
+		return nil
+	}
+	yesterday := addDays(date, -1)
+	g := &GlucoseData{}
+
+	var overnight, dayBefore []float64
+	fastingEnd := date + " " + GlucoseFastingEnd
+	for _, m := range meals {
+		if strings.HasPrefix(m.At, date) && m.At < fastingEnd {
+			fastingEnd = m.At
+			break
+		}
+	}
+	for _, r := range readings {
+		switch {
+		case strings.HasPrefix(r.At, yesterday):
+			dayBefore = append(dayBefore, r.MgDl)
+		case r.At < date+" "+GlucoseOvernightEnd:
+			overnight = append(overnight, r.MgDl)
+		}
+		if strings.HasPrefix(r.At, date) && r.At < fastingEnd {
+			fasting := r.MgDl
+			g.Fasting = &fasting
+		}
+	}
+	if len(overnight) > 0 {
+		avg := math.Round(mean(overnight))
+		g.OvernightAvg = &avg
+	}
+	if len(dayBefore) > 0 {
+		in := 0
+		for _, v := range dayBefore {
+			if v >= GlucoseRangeLow && v <= GlucoseRangeHigh {
+				in++
+			}
+		}
+		pct := math.Round(float64(in) / float64(len(dayBefore)) * 100)
+		g.TimeInRange = &pct
+	}
+
+	for _, m := range meals {
+		if !strings.HasPrefix(m.At, yesterday) {
+			continue
+		}
+		if spike, ok := mealSpike(readings, m); ok {
+			g.Spikes = append(g.Spikes, spike)
+		}
+	}
+	return g
+}
+
+// mealSpike compares the last reading at or before a meal with the peak in
+// the GlucoseSpikeMinutes after it
+func mealSpike(readings []glucoseReading, m glucoseMeal) (GlucoseSpike, bool) {
+	var before, peak *float64
+	for i := range readings {
+		r := &readings[i]
+		gap := minutesBetween(m.At, r.At)
+		switch {
+		case gap <= 0 && gap > -GlucoseSpikeMinutes:
+			before = &r.MgDl
+		case gap > 0 && gap <= GlucoseSpikeMinutes:
+			if peak == nil || r.MgDl > *peak {
+				peak = &r.MgDl
+			}
+		}
+	}
+	if before == nil || peak == nil || *peak-*before < GlucoseSpikeMgDl {
+		return GlucoseSpike{}, false
+	}
+	return GlucoseSpike{MealTime: m.At[11:], MealKcal: m.Kcal, PeakMgDl: *peak, RiseMgDl: *peak - *before}, true
+}
+
+// glucoseLines describes the glucose summary, one fact per line
+func glucoseLines(g *GlucoseData) []string {
+	if g == nil {
+		return nil
+	}
+	var lines []string
+	if g.Fasting != nil {
+		lines = append(lines, fmt.Sprintf("Fasting: %.0f mg/dL", *g.Fasting))
+	}
+	if g.OvernightAvg != nil {
+		lines = append(lines, fmt.Sprintf("Overnight average: %.0f mg/dL", *g.OvernightAvg))
+	}
+	if g.TimeInRange != nil {
+		lines = append(lines, fmt.Sprintf("Time in range yesterday: %.0f%%", *g.TimeInRange))
+	}
+	for _, s := range g.Spikes {
+		lines = append(lines, spikeValue(s))
+	}
+	return lines
+}
+
+// spikeValue formats a spike, e.g. "Spike after 12:30 meal (650 kcal): +48 to 172 mg/dL"
+func spikeValue(s GlucoseSpike) string {
+	return fmt.Sprintf("Spike after %s meal (%.0f kcal): +%.0f to %.0f mg/dL", s.MealTime, s.MealKcal, s.RiseMgDl, s.PeakMgDl)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== GLUCOSE TESTS ====================
+
+func TestQueryGlucose(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('blood_glucose', '2024-01-13 22:00:00 +0700', 300, 'mg/dL', 'Libre'),
+		('blood_glucose', '2024-01-14 12:15:00 +0700', 95, 'mg/dL', 'Libre'),
+		('blood_glucose', '2024-01-14 13:00:00 +0700', 150, 'mg/dL', 'Libre'),
+		('blood_glucose', '2024-01-14 13:45:00 +0700', 190, 'mg/dL', 'Libre'),
+		('blood_glucose', '2024-01-14 19:00:00 +0700', 5.5, 'mmol/L', 'Libre'),
+		('blood_glucose', '2024-01-14 20:00:00 +0700', 110, 'mg/dL', 'Libre'),
+		('blood_glucose', '2024-01-15 02:00:00 +0700', 90, 'mg/dL', 'Libre'),
+		('blood_glucose', '2024-01-15 04:00:00 +0700', 100, 'mg/dL', 'Libre'),
+		('blood_glucose', '2024-01-15 07:00:00 +0700', 92, 'mg/dL', 'Libre'),
+		('blood_glucose', '2024-01-15 08:30:00 +0700', 140, 'mg/dL', 'Libre'),
+		('dietary_energy', '2024-01-14 12:30:00 +0700', 500, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-14 12:45:00 +0700', 150, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-14 19:05:00 +0700', 700, 'kcal', 'briefing')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := queryGlucose(db, "2024-01-15")
+	if err != nil {
+		t.Fatalf("queryGlucose() error: %v", err)
+	}
+	if g == nil {
+		t.Fatal("queryGlucose() = nil, want readings")
+	}
+	// The 01-13 reading is out of the window; 5.5 mmol/L is 99 mg/dL
+	if g.TimeInRange == nil || *g.TimeInRange != 80 {
+		t.Errorf("TimeInRange = %v, want 80", g.TimeInRange)
+	}
+	if g.OvernightAvg == nil || *g.OvernightAvg != 95 {
+		t.Errorf("OvernightAvg = %v, want 95", g.OvernightAvg)
+	}
+	if g.Fasting == nil || *g.Fasting != 92 {
+		t.Errorf("Fasting = %v, want 92", g.Fasting)
+	}
+	// Lunch rows merge into one meal; dinner barely moved glucose
+	want := []GlucoseSpike{{MealTime: "12:30", MealKcal: 650, PeakMgDl: 190, RiseMgDl: 95}}
+	if !reflect.DeepEqual(g.Spikes, want) {
+		t.Errorf("Spikes = %+v, want %+v", g.Spikes, want)
+	}
+
+	if g, err := queryGlucose(db, "2024-02-01"); err != nil || g != nil {
+		t.Errorf("queryGlucose() with no readings = %+v, %v; want nil", g, err)
+	}
+}
+
+func TestGlucoseFastingBeforeBreakfast(t *testing.T) {
+	readings := []glucoseReading{{"2024-01-15 06:00", 88}, {"2024-01-15 07:00", 91}, {"2024-01-15 07:40", 150}}
+	meals := []glucoseMeal{{"2024-01-15 07:15", 400}}
+	g := glucoseFor(readings, meals, "2024-01-15")
+	if g.Fasting == nil || *g.Fasting != 91 {
+		t.Errorf("Fasting = %v, want 91 (before breakfast)", g.Fasting)
+	}
+	if g.TimeInRange != nil || g.Spikes != nil {
+		t.Errorf("got %+v, want no range or spikes without yesterday's readings", g)
+	}
+}
+
+func TestGlucoseRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-01-15", Glucose: &GlucoseData{
+		Fasting: ptr(92), OvernightAvg: ptr(95), TimeInRange: ptr(80),
+		Spikes: []GlucoseSpike{{MealTime: "12:30", MealKcal: 650, PeakMgDl: 190, RiseMgDl: 95}},
+	}}
+	spike := "Spike after 12:30 meal (650 kcal): +95 to 190 mg/dL"
+
+	md := MorningMarkdown(m)
+	for _, want := range []string{"## Glucose", "- Fasting: 92 mg/dL", "- Time in range yesterday: 80%", "- " + spike} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Glucose\n  Fasting: 92 mg/dL\n  Overnight average: 95 mg/dL\n") {
+		t.Errorf("text missing glucose lines:\n%s", text)
+	}
+
+	var section *pageSection
+	for _, sec := range morningPage(m).Sections {
+		if sec.Title == "Glucose" {
+			section = &sec
+		}
+	}
+	if section == nil || len(section.Items) != 1 || section.Items[0].Text != spike || !section.Items[0].Alert {
+		t.Errorf("page Glucose section = %+v, want the spike as an alert", section)
+	}
+
+	vars := morningRuleVars(m)
+	if vars["glucose.fasting"] != 92.0 || vars["glucose.spikes"] != 1.0 {
+		t.Errorf("rule vars = %v, %v; want 92, 1", vars["glucose.fasting"], vars["glucose.spikes"])
+	}
+	if md := MorningMarkdown(MorningBriefing{}); strings.Contains(md, "Glucose") {
+		t.Error("markdown shows Glucose without readings")
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// gmailListSize is the most unread messages counted per account
+const gmailListSize = 100
+
+// MailData is the important or starred mail that came in since yesterday evening
+type MailData struct {
+	Since    string        `json:"since"`  // yesterday's mail.since, local time
+	Unread   int           `json:"unread"` // across accounts, up to 100 each
+	Messages []MailMessage `json:"messages"`
+	More     int           `json:"more,omitempty"` // left out by mail.max_items
+}
+
+// MailMessage is one unread message
+type MailMessage struct {
+	Source   string `json:"source"` // the calendar account's label
+	From     string `json:"from"`   // the sender's name, or address without one
+	Subject  string `json:"subject"`
+	Received string `json:"received"` // local time, YYYY-MM-DD HH:MM
+	Starred  bool   `json:"starred,omitempty"`
+}
+
+// validateMail checks the start time and cap when mail is on
+func validateMail(cfg MailConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if _, err := parseClock(cfg.Since); err != nil {
+		return fmt.Errorf("mail.since: %w", err)
+	}
+	if cfg.MaxItems <= 0 {
+		return errors.New("mail.max_items must be positive")
+	}
+	return nil
+}
+
+// getMailData reads each calendar account's Gmail when mail.enabled; accounts
+// without a refresh token are skipped
+func getMailData(b *MorningBriefing, today string) {
+	cfg := settings.Mail
+	if !cfg.Enabled {
+		return
+	}
+	day, err := time.ParseInLocation("2006-01-02", today, time.Local)
+	if err != nil {
+		return
+	}
+	since, _ := parseClock(cfg.Since)
+	after := day.AddDate(0, 0, -1).Add(time.Duration(since) * time.Minute)
+
+	d := &MailData{Since: after.Format("2006-01-02 15:04")}
+	fetched := false
+	for _, account := range settings.Calendars {
+		messages, unread, err := fetchMail(account, settings.Google, after, cfg.MaxItems)
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("mail error (%s): %v", account.Account, err))
+			continue
+		}
+		if messages == nil {
+			continue // no refresh token
+		}
+		fetched = true
+		d.Unread += unread
+		d.Messages = append(d.Messages, messages...)
+	}
+	if !fetched {
+		return
+	}
+	slices.SortStableFunc(d.Messages, func(a, b MailMessage) int { return strings.Compare(b.Received, a.Received) })
+	if len(d.Messages) > cfg.MaxItems {
+		d.Messages = d.Messages[:cfg.MaxItems]
+	}
+	d.More = d.Unread - len(d.Messages)
+	if cfg.Redact {
+		for i := range d.Messages {
+			d.Messages[i].From, d.Messages[i].Subject = redactToken(d.Messages[i].From), redactToken(d.Messages[i].Subject)
+		}
+	}
+	b.Mail = d
+}
+
+// fetchMail lists the account's unread important or starred messages
+// received after a time, newest first, with the first maxItems read in full;
+// nil messages without a refresh token
+func fetchMail(account CalendarAccount, cfg GoogleConfig, after time.Time, maxItems int) ([]MailMessage, int, error) {
+	h := &http.Client{Timeout: calendarTimeout}
+	token, err := googleAccessToken(h, account, cfg)
+	if token == "" || err != nil {
+		return nil, 0, err
+	}
+	base := strings.TrimRight(cfg.GmailURL, "/") + "/users/me/messages"
+	var list struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	query := fmt.Sprintf("is:unread (is:important OR is:starred) after:%d", after.Unix())
+	if err := gmailGet(h, token, base+"?"+url.Values{"q": {query}, "maxResults": {fmt.Sprint(gmailListSize)}}.Encode(), &list); err != nil {
+		return nil, 0, err
+	}
+
+	messages := []MailMessage{}
+	for _, m := range list.Messages[:min(maxItems, len(list.Messages))] {
+		var msg struct {
+			LabelIDs     []string `json:"labelIds"`
+			InternalDate string   `json:"internalDate"` // ms since the epoch
+			Payload      struct {
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+			} `json:"payload"`
+		}
+		u := base + "/" + url.PathEscape(m.ID) + "?" + url.Values{"format": {"metadata"}, "metadataHeaders": {"From", "Subject"}}.Encode()
+		if err := gmailGet(h, token, u, &msg); err != nil {
+			return nil, 0, err
+		}
+		mm := MailMessage{Source: account.Source, Starred: slices.Contains(msg.LabelIDs, "STARRED")}
+		for _, header := range msg.Payload.Headers {
+			switch header.Name {
+			case "From":
+				mm.From = mailSender(header.Value)
+			case "Subject":
+				mm.Subject = header.Value
+			}
+		}
+		var ms int64
+		if _, err := fmt.Sscan(msg.InternalDate, &ms); err == nil {
+			mm.Received = time.UnixMilli(ms).In(time.Local).Format("2006-01-02 15:04")
+		}
+		messages = append(messages, mm)
+	}
+	return messages, len(list.Messages), nil
+}
+
+// mailSender is the display name in a From header, or the address without one
+func mailSender(from string) string {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return from
+	}
+	if addr.Name != "" {
+		return addr.Name
+	}
+	return addr.Address
+}
+
+func gmailGet(h *http.Client, token, u string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := h.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("gmail API status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("gmail API: invalid response: %w", err)
+	}
+	return nil
+}
+
+// mailLine formats a message, e.g. "08:12 Alice Smith: Contract for review (starred)"
+func mailLine(m MailMessage) string {
+	_, clock, _ := strings.Cut(m.Received, " ")
+	line := fmt.Sprintf("%s %s: %s", clock, m.From, m.Subject)
+	if m.Starred {
+		line += " (starred)"
+	}
+	return line
+}
+
+// mailLines lists the messages, ending with how many more were left out
+func mailLines(d *MailData) []string {
+	if d == nil {
+		return nil
+	}
+	var lines []string
+	for _, m := range d.Messages {
+		lines = append(lines, mailLine(m))
+	}
+	if d.More > 0 {
+		lines = append(lines, fmt.Sprintf("+%d more", d.More))
+	}
+	return lines
+}
+
+// mailSummary counts the unread, e.g. "4 important unread since 18:00 yesterday"
+func mailSummary(d *MailData) string {
+	clock := d.Since
+	if _, c, ok := strings.Cut(d.Since, " "); ok {
+		clock = c
+	}
+	return fmt.Sprintf("%d important unread since %s yesterday", d.Unread, clock)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== GMAIL TESTS ====================
+
+// fakeGmail serves a token and three unread messages, two of them listed in full
+func fakeGmail(t *testing.T, after time.Time) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			r.ParseForm()
+			if r.PostForm.Get("refresh_token") != "r" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token": "a"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer a" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/users/me/messages":
+			if want := "is:unread (is:important OR is:starred) after:" + fmt.Sprint(after.Unix()); r.URL.Query().Get("q") != want {
+				t.Errorf("q = %q, want %q", r.URL.Query().Get("q"), want)
+			}
+			w.Write([]byte(`{"messages": [{"id": "m1"}, {"id": "m2"}, {"id": "m3"}], "resultSizeEstimate": 3}`))
+		case "/users/me/messages/m1":
+			w.Write([]byte(`{"labelIds": ["UNREAD", "IMPORTANT"], "internalDate": "` + fmt.Sprint(after.Add(14*time.Hour).UnixMilli()) + `",
+				"payload": {"headers": [{"name": "From", "value": "Alice Smith <alice@example.com>"}, {"name": "Subject", "value": "Contract for review"}]}}`))
+		case "/users/me/messages/m2":
+			w.Write([]byte(`{"labelIds": ["UNREAD", "STARRED"], "internalDate": "` + fmt.Sprint(after.Add(3*time.Hour).UnixMilli()) + `",
+				"payload": {"headers": [{"name": "From", "value": "bank@example.com"}, {"name": "Subject", "value": "Statement ready"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetMailData(t *testing.T) {
+	after := time.Date(2024, 1, 14, 18, 0, 0, 0, time.Local)
+	ts := fakeGmail(t, after)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Google.TokenURL, settings.Google.GmailURL = ts.URL+"/token", ts.URL
+	settings.Calendars = []CalendarAccount{
+		{Account: "me@example.com", Source: "personal", RefreshToken: "r"},
+		{Account: "work@example.com", Source: "work"}, // gog only, skipped
+	}
+	settings.Mail = MailConfig{Enabled: true, Since: "18:00", MaxItems: 2}
+
+	b := &MorningBriefing{}
+	getMailData(b, "2024-01-15")
+	if b.Mail == nil || len(b.Errors) != 0 {
+		t.Fatalf("mail = %+v, errors %v", b.Mail, b.Errors)
+	}
+	want := []string{"08:00 Alice Smith: Contract for review", "21:00 bank@example.com: Statement ready (starred)", "+1 more"}
+	if got := mailLines(b.Mail); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("mailLines() = %q, want %q", got, want)
+	}
+	if got := mailSummary(b.Mail); got != "3 important unread since 18:00 yesterday" || b.Mail.Messages[0].Source != "personal" {
+		t.Errorf("mailSummary() = %q, mail %+v", got, b.Mail)
+	}
+
+	// mail.redact hashes senders and subjects
+	settings.Mail.Redact = true
+	b = &MorningBriefing{}
+	getMailData(b, "2024-01-15")
+	if out, _ := json.Marshal(b.Mail); b.Mail == nil || strings.Contains(string(out), "Alice") || strings.Contains(string(out), "Contract") {
+		t.Errorf("redacted mail = %s", out)
+	}
+
+	// A failed token refresh is reported per account
+	settings.Calendars[0].RefreshToken = "expired"
+	b = &MorningBriefing{}
+	getMailData(b, "2024-01-15")
+	if b.Mail != nil || len(b.Errors) != 1 || !strings.HasPrefix(b.Errors[0], "mail error (me@example.com): google token refresh status 400") {
+		t.Errorf("mail = %+v, errors %v", b.Mail, b.Errors)
+	}
+
+	// Off unless enabled
+	settings.Mail.Enabled = false
+	b = &MorningBriefing{}
+	getMailData(b, "2024-01-15")
+	if b.Mail != nil || len(b.Errors) != 0 {
+		t.Errorf("mail when disabled = %+v, %v", b.Mail, b.Errors)
+	}
+}
+
+func TestMailSender(t *testing.T) {
+	tests := map[string]string{
+		"Alice Smith <alice@example.com>": "Alice Smith",
+		"<bank@example.com>":              "bank@example.com",
+		"not an address":                  "not an address",
+	}
+	for in, want := range tests {
+		if got := mailSender(in); got != want {
+			t.Errorf("mailSender(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestValidateMail(t *testing.T) {
+	if err := validateMail(DefaultConfig().Mail); err != nil {
+		t.Errorf("default: %v", err)
+	}
+	if err := validateMail(MailConfig{Enabled: true, Since: "6pm", MaxItems: 5}); err == nil {
+		t.Error("expected an error for since 6pm")
+	}
+	if err := validateMail(MailConfig{Enabled: true, Since: "18:00"}); err == nil {
+		t.Error("expected an error for max_items 0")
+	}
+}
+
+func TestMailRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-01-15", Mail: &MailData{Since: "2024-01-14 18:00", Unread: 1,
+		Messages: []MailMessage{{Source: "work", From: "Alice Smith", Subject: "Contract for review", Received: "2024-01-15 08:00"}}}}
+	line := "08:00 Alice Smith: Contract for review"
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Mail\n\n1 important unread since 18:00 yesterday\n\n- "+line+"\n") {
+		t.Errorf("markdown missing mail:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Mail  1 important unread since 18:00 yesterday\n  "+line+"\n") {
+		t.Errorf("text missing mail:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Mail: 1 important unread since 18:00 yesterday\n- "+line+"\n") {
+		t.Errorf("prompt missing mail (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["mail.unread"] != 1.0 {
+		t.Errorf("mail.unread = %v", vars["mail.unread"])
+	}
+	r := RedactMorningBriefing(m)
+	if r.Mail.Messages[0].Subject == "Contract for review" || r.Mail.Messages[0].From == "Alice Smith" || m.Mail.Messages[0].From != "Alice Smith" {
+		t.Errorf("redacted mail = %+v, original %+v", r.Mail, m.Mail)
+	}
+}
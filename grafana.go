@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// grafanaTarget is a history column exposed to Grafana
+type grafanaTarget struct {
+	name  string
+	value func(HistoryDay) *float64
+}
+
+var grafanaTargets = []grafanaTarget{
+	{"sleep_hours", func(d HistoryDay) *float64 { return d.SleepHours }},
+	{"hrv_ms", func(d HistoryDay) *float64 { return d.HRV }},
+	{"resting_hr_bpm", func(d HistoryDay) *float64 { return d.RestingHR }},
+	{"readiness_score", func(d HistoryDay) *float64 { return intAsFloat(d.ReadinessScore) }},
+	{"energy_balance_kcal", func(d HistoryDay) *float64 { return intAsFloat(d.EnergyBalanceKcal) }},
+	{"protein_g", func(d HistoryDay) *float64 { return d.ProteinG }},
+	{"steps", func(d HistoryDay) *float64 { return intAsFloat(d.Steps) }},
+}
+
+// grafanaQuery is the simple-JSON datasource /query request body
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+// grafanaTimeSeries is one target's response: [value, unix ms] pairs
+type grafanaTimeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+func intAsFloat(v *int) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
+
+// handleGrafanaTest answers the datasource "Save & test" check
+func (s *server) handleGrafanaTest(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleGrafanaSearch lists the available targets
+func (s *server) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	targets := make([]string, len(grafanaTargets))
+	for i, g := range grafanaTargets {
+		targets[i] = g.name
+	}
+	writeJSON(w, http.StatusOK, targets)
+}
+
+// handleGrafanaQuery returns one time series per requested target, with a
+// point at local midnight for each stored day in the range
+func (s *server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var q grafanaQuery
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid query: " + err.Error()})
+		return
+	}
+	if q.Range.From.IsZero() || q.Range.To.IsZero() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "range.from and range.to are required"})
+		return
+	}
+
+	history, err := s.loadHistoryDays(q.Range.From.Local().Format("2006-01-02"), q.Range.To.Local().Format("2006-01-02"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	result := []grafanaTimeSeries{}
+	for _, t := range q.Targets {
+		i := slices.IndexFunc(grafanaTargets, func(g grafanaTarget) bool { return g.name == t.Target })
+		if i < 0 {
+			continue // unknown targets are skipped rather than failing the panel
+		}
+		series := grafanaTimeSeries{Target: t.Target, Datapoints: [][2]float64{}}
+		for _, d := range history {
+			v := grafanaTargets[i].value(d)
+			day, err := time.ParseInLocation("2006-01-02", d.Date, time.Local)
+			if v == nil || err != nil {
+				continue
+			}
+			series.Datapoints = append(series.Datapoints, [2]float64{*v, float64(day.UnixMilli())})
+		}
+		result = append(result, series)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== GRAFANA DATASOURCE TESTS ====================
+
+func TestGrafanaTestAndSearch(t *testing.T) {
+	s := newTestServer(MorningBriefing{})
+
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/grafana/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /grafana/ status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/grafana/search", strings.NewReader(`{"target":""}`)))
+	var targets []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &targets); err != nil {
+		t.Fatalf("search response: %v", err)
+	}
+	for _, want := range []string{"sleep_hours", "hrv_ms", "energy_balance_kcal", "readiness_score"} {
+		if !slices.Contains(targets, want) {
+			t.Errorf("search targets %v missing %q", targets, want)
+		}
+	}
+}
+
+func TestHandleGrafanaQuery(t *testing.T) {
+	s := newTestServer(MorningBriefing{})
+	s.historyPath = filepath.Join(t.TempDir(), "history.db")
+
+	db, err := openHistoryDB(s.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	morning := MorningBriefing{TargetDate: "2024-01-15", Sleep: SleepData{TotalHours: ptr(7.5)}, Classification: Classification{ReadinessScore: intPtr(82)}}
+	evening := EveningBriefing{TargetDate: "2024-01-16"}
+	evening.Energy.DeficitOrSurplusKcal = -350
+	for _, r := range []HistoryRecord{morningHistoryRecord(morning, HistorySourceLive), eveningHistoryRecord(evening, HistorySourceLive)} {
+		if _, err := saveHistoryRecord(db, r, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	body := `{
+		"range": {"from": "2024-01-10T00:00:00Z", "to": "2024-01-20T00:00:00Z"},
+		"targets": [{"target": "sleep_hours"}, {"target": "energy_balance_kcal"}, {"target": "bogus"}]
+	}`
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/grafana/query", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var series []grafanaTimeSeries
+	if err := json.Unmarshal(rec.Body.Bytes(), &series); err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 2 {
+		t.Fatalf("len(series) = %d, want 2 (unknown target skipped)", len(series))
+	}
+
+	day := func(date string) float64 {
+		d, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+		return float64(d.UnixMilli())
+	}
+	if got := series[0].Datapoints; series[0].Target != "sleep_hours" || len(got) != 1 || got[0] != [2]float64{7.5, day("2024-01-15")} {
+		t.Errorf("sleep_hours = %+v", series[0])
+	}
+	if got := series[1].Datapoints; len(got) != 1 || got[0] != [2]float64{-350, day("2024-01-16")} {
+		t.Errorf("energy_balance_kcal = %+v", series[1])
+	}
+}
+
+func TestHandleGrafanaQueryValidation(t *testing.T) {
+	s := newTestServer(MorningBriefing{})
+
+	for _, body := range []string{`not json`, `{"targets": [{"target": "hrv_ms"}]}`} {
+		rec := httptest.NewRecorder()
+		s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/grafana/query", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("query %q status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
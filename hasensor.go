@@ -0,0 +1,60 @@
+package main
+
+// HASensor is the payload for a Home Assistant RESTful sensor
+// State is the sleep quality; everything else lives under a flat attributes map
+// (json_attributes_path: "$.attributes"). Attribute keys are stable and missing
+// metrics are reported as null rather than omitted.
+type HASensor struct {
+	State      string             `json:"state"`
+	Attributes HASensorAttributes `json:"attributes"`
+}
+
+type HASensorAttributes struct {
+	GeneratedAt          string   `json:"generated_at"`
+	SleepQuality         string   `json:"sleep_quality"`
+	RecoveryStatus       string   `json:"recovery_status"`
+	MorningLoad          string   `json:"morning_load"`
+	Recommendation       string   `json:"recommendation"`
+	SleepTotalHours      *float64 `json:"sleep_total_hours"`
+	SleepDeepHours       *float64 `json:"sleep_deep_hours"`
+	SleepREMHours        *float64 `json:"sleep_rem_hours"`
+	RestingHR            *float64 `json:"resting_hr_bpm"`
+	HRV                  *float64 `json:"hrv_ms"`
+	SpO2                 *float64 `json:"spo2_pct"`
+	RespiratoryRate      *float64 `json:"respiratory_rate"`
+	MorningEventCount    int      `json:"morning_event_count"`
+	FirstEventTime       string   `json:"first_event_time"`
+	MedsDueCount         int      `json:"meds_due_count"`
+	MedsOverdueCount     int      `json:"meds_overdue_count"`
+	DaysSinceLastWorkout int      `json:"days_since_last_workout"`
+	WeeklyWorkoutCount   int      `json:"weekly_workout_count"`
+	ErrorCount           int      `json:"error_count"`
+}
+
+// NewHASensor flattens a morning briefing for Home Assistant
+func NewHASensor(b MorningBriefing) HASensor {
+	return HASensor{
+		State: b.Classification.SleepQuality,
+		Attributes: HASensorAttributes{
+			GeneratedAt:          b.GeneratedAt,
+			SleepQuality:         b.Classification.SleepQuality,
+			RecoveryStatus:       b.Classification.RecoveryStatus,
+			MorningLoad:          b.Classification.MorningLoad,
+			Recommendation:       b.Classification.Recommendation,
+			SleepTotalHours:      b.Sleep.TotalHours,
+			SleepDeepHours:       b.Sleep.DeepHours,
+			SleepREMHours:        b.Sleep.REMHours,
+			RestingHR:            b.Vitals.RestingHR,
+			HRV:                  b.Vitals.HRV,
+			SpO2:                 b.Vitals.SpO2,
+			RespiratoryRate:      b.Vitals.RespiratoryRate,
+			MorningEventCount:    b.Calendar.MorningCount,
+			FirstEventTime:       b.Calendar.FirstEventTime,
+			MedsDueCount:         len(b.Meds.DueToday),
+			MedsOverdueCount:     len(b.Meds.Overdue),
+			DaysSinceLastWorkout: b.Training.DaysSinceLast,
+			WeeklyWorkoutCount:   b.Training.WeeklyCount,
+			ErrorCount:           len(b.Errors),
+		},
+	}
+}
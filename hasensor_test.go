@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// ==================== HOME ASSISTANT SENSOR TESTS ====================
+
+func TestNewHASensor(t *testing.T) {
+	b := MorningBriefing{
+		GeneratedAt: "2024-01-15T07:30:00+07:00",
+		Sleep:       SleepData{TotalHours: ptr(7.5), DeepHours: ptr(1.2)},
+		Vitals:      VitalsData{HRV: ptr(45)},
+		Calendar:    CalendarData{MorningCount: 2, FirstEventTime: "09:00"},
+		Meds: MedsData{
+			DueToday: []MedTask{{Name: "Vitamin D"}, {Name: "Nexium"}},
+			Overdue:  []MedTask{{Name: "PrEP"}},
+		},
+		Classification: Classification{
+			SleepQuality:   "GOOD",
+			MorningLoad:    "LIGHT",
+			RecoveryStatus: "GOOD",
+			Recommendation: "Well rested. Attack the day.",
+		},
+	}
+
+	sensor := NewHASensor(b)
+	if sensor.State != "GOOD" {
+		t.Errorf("State = %q, want %q", sensor.State, "GOOD")
+	}
+	if sensor.Attributes.MedsDueCount != 2 {
+		t.Errorf("MedsDueCount = %d, want 2", sensor.Attributes.MedsDueCount)
+	}
+	if sensor.Attributes.MedsOverdueCount != 1 {
+		t.Errorf("MedsOverdueCount = %d, want 1", sensor.Attributes.MedsOverdueCount)
+	}
+	if sensor.Attributes.FirstEventTime != "09:00" {
+		t.Errorf("FirstEventTime = %q, want %q", sensor.Attributes.FirstEventTime, "09:00")
+	}
+}
+
+// Home Assistant templates rely on every attribute key being present
+func TestHASensorStableKeys(t *testing.T) {
+	output, err := json.Marshal(NewHASensor(MorningBriefing{}))
+	if err != nil {
+		t.Fatalf("Failed to marshal HASensor: %v", err)
+	}
+
+	var parsed struct {
+		State      string                     `json:"state"`
+		Attributes map[string]json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		t.Fatalf("Failed to unmarshal HASensor: %v", err)
+	}
+
+	for _, key := range []string{"sleep_total_hours", "hrv_ms", "resting_hr_bpm", "recovery_status", "meds_due_count"} {
+		raw, ok := parsed.Attributes[key]
+		if !ok {
+			t.Errorf("attributes missing key %q", key)
+		}
+		if key == "hrv_ms" && string(raw) != "null" {
+			t.Errorf("attributes[%q] = %s, want null when missing", key, raw)
+		}
+	}
+}
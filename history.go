@@ -0,0 +1,455 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// History row sources
+const (
+	HistorySourceLive     = "live"
+	HistorySourceBackfill = "backfill"
+)
+
+// HistoryRecord is one stored briefing: key scalars for querying plus the full JSON
+type HistoryRecord struct {
+	Date              string
+	Mode              string
+	GeneratedAt       string
+	Source            string
+	SleepHours        *float64
+	DeepHours         *float64
+	HRV               *float64
+	RestingHR         *float64
+	ReadinessScore    *int
+	SleepQuality      string
+	RecoveryStatus    string
+	MorningLoad       string
+	EnergyBalanceKcal *int
+	ConsumedKcal      *float64
+	ActiveKcal        *float64
+	ProteinG          *float64
+	Steps             *int
+	JSON              []byte
+}
+
+// getHistoryDBPath returns the history store location inside the data dir
+func getHistoryDBPath() string {
+	return filepath.Join(getDataDir(), "history.db")
+}
+
+// openHistoryDB opens (creating if needed) the history store
+func openHistoryDB(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("history dir error: %w", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("history open error: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS briefings (
+			date TEXT NOT NULL,
+			mode TEXT NOT NULL,
+			generated_at TEXT NOT NULL,
+			source TEXT NOT NULL,
+			sleep_hours REAL,
+			deep_hours REAL,
+			hrv_ms REAL,
+			resting_hr_bpm REAL,
+			readiness_score INTEGER,
+			sleep_quality TEXT,
+			recovery_status TEXT,
+			morning_load TEXT,
+			energy_balance_kcal INTEGER,
+			consumed_kcal REAL,
+			active_kcal REAL,
+			protein_g REAL,
+			steps INTEGER,
+			json TEXT NOT NULL,
+			PRIMARY KEY (date, mode)
+		)
+	`)
+	if err == nil {
+		err = createTagsTable(db)
+	}
+	if err == nil {
+		err = createMedLogTable(db)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("history schema error: %w", err)
+	}
+	return db, nil
+}
+
+// morningHistoryRecord extracts the stored columns from a morning briefing
+func morningHistoryRecord(b MorningBriefing, source string) HistoryRecord {
+	payload, _ := json.Marshal(b)
+	return HistoryRecord{
+		Date:           b.TargetDate,
+		Mode:           "morning",
+		GeneratedAt:    b.GeneratedAt,
+		Source:         source,
+		SleepHours:     b.Sleep.TotalHours,
+		DeepHours:      b.Sleep.DeepHours,
+		HRV:            b.Vitals.HRV,
+		RestingHR:      b.Vitals.RestingHR,
+		ReadinessScore: b.Classification.ReadinessScore,
+		SleepQuality:   b.Classification.SleepQuality,
+		RecoveryStatus: b.Classification.RecoveryStatus,
+		MorningLoad:    b.Classification.MorningLoad,
+		JSON:           payload,
+	}
+}
+
+// eveningHistoryRecord extracts the stored columns from an evening briefing
+func eveningHistoryRecord(b EveningBriefing, source string) HistoryRecord {
+	payload, _ := json.Marshal(b)
+	balance := b.Energy.DeficitOrSurplusKcal
+	steps := b.Activity.Steps
+	return HistoryRecord{
+		Date:              b.TargetDate,
+		Mode:              "evening",
+		GeneratedAt:       b.GeneratedAt,
+		Source:            source,
+		HRV:               nonZero(b.Recovery.HRVMS),
+		RestingHR:         nonZero(b.Recovery.RestingHRBPM),
+		SleepHours:        nonZero(b.Recovery.SleepLastNight.TotalHrs),
+		DeepHours:         nonZero(b.Recovery.SleepLastNight.DeepHrs),
+		EnergyBalanceKcal: &balance,
+		ConsumedKcal:      &b.Energy.ConsumedKcal,
+		ActiveKcal:        &b.Energy.ActiveKcal,
+		ProteinG:          &b.Protein.ConsumedG,
+		Steps:             &steps,
+		JSON:              payload,
+	}
+}
+
+// nonZero maps the evening briefing's zero-for-missing values to nil
+func nonZero(v float64) *float64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+// saveHistoryRecord stores a record, replacing an existing row for the same date
+// and mode only when replace is set. Returns whether a row was written.
+func saveHistoryRecord(db *sql.DB, r HistoryRecord, replace bool) (bool, error) {
+	conflict := "DO NOTHING"
+	if replace {
+		conflict = `DO UPDATE SET
+			generated_at = excluded.generated_at,
+			source = excluded.source,
+			sleep_hours = excluded.sleep_hours,
+			deep_hours = excluded.deep_hours,
+			hrv_ms = excluded.hrv_ms,
+			resting_hr_bpm = excluded.resting_hr_bpm,
+			readiness_score = excluded.readiness_score,
+			sleep_quality = excluded.sleep_quality,
+			recovery_status = excluded.recovery_status,
+			morning_load = excluded.morning_load,
+			energy_balance_kcal = excluded.energy_balance_kcal,
+			consumed_kcal = excluded.consumed_kcal,
+			active_kcal = excluded.active_kcal,
+			protein_g = excluded.protein_g,
+			steps = excluded.steps,
+			json = excluded.json`
+	}
+
+	query := `
+		INSERT INTO briefings (
+			date, mode, generated_at, source,
+			sleep_hours, deep_hours, hrv_ms, resting_hr_bpm, readiness_score,
+			sleep_quality, recovery_status, morning_load,
+			energy_balance_kcal, consumed_kcal, active_kcal, protein_g, steps, json
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(date, mode) ` + conflict
+
+	res, err := db.Exec(query,
+		r.Date, r.Mode, r.GeneratedAt, r.Source,
+		r.SleepHours, r.DeepHours, r.HRV, r.RestingHR, r.ReadinessScore,
+		nullString(r.SleepQuality), nullString(r.RecoveryStatus), nullString(r.MorningLoad),
+		r.EnergyBalanceKcal, r.ConsumedKcal, r.ActiveKcal, r.ProteinG, r.Steps, string(sealAtRest(r.JSON)))
+	if err != nil {
+		return false, fmt.Errorf("history save error: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// recordHistory stores a briefing from a live run, replacing an earlier run
+// (or a backfilled row) for the same date and mode
+func recordHistory(r HistoryRecord) error {
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = saveHistoryRecord(db, r, true)
+	return err
+}
+
+// loadHistoryJSON returns the stored briefing JSON for a date and mode,
+// decrypting it when it was written with encryption enabled
+func loadHistoryJSON(db *sql.DB, date, mode string) ([]byte, error) {
+	var stored string
+	err := db.QueryRow(`SELECT json FROM briefings WHERE date = ? AND mode = ?`, date, mode).Scan(&stored)
+	if err != nil {
+		return nil, fmt.Errorf("history load error: %w", err)
+	}
+	return openAtRest([]byte(stored))
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// HistoryDay merges one day's morning and evening rows for charting
+type HistoryDay struct {
+	Date              string   `json:"date"`
+	SleepHours        *float64 `json:"sleep_hours,omitempty"`
+	HRV               *float64 `json:"hrv_ms,omitempty"`
+	RestingHR         *float64 `json:"resting_hr_bpm,omitempty"`
+	ReadinessScore    *int     `json:"readiness_score,omitempty"`
+	EnergyBalanceKcal *int     `json:"energy_balance_kcal,omitempty"`
+	ProteinG          *float64 `json:"protein_g,omitempty"`
+	Steps             *int     `json:"steps,omitempty"`
+}
+
+// queryHistoryDays returns one entry per stored day in [from, to], oldest first.
+// Recovery values prefer the morning row and fall back to the evening one.
+func queryHistoryDays(db *sql.DB, from, to string) ([]HistoryDay, error) {
+	rows, err := db.Query(`
+		SELECT date,
+			COALESCE(MAX(CASE WHEN mode = 'morning' THEN sleep_hours END), MAX(CASE WHEN mode = 'evening' THEN sleep_hours END)),
+			COALESCE(MAX(CASE WHEN mode = 'morning' THEN hrv_ms END), MAX(CASE WHEN mode = 'evening' THEN hrv_ms END)),
+			COALESCE(MAX(CASE WHEN mode = 'morning' THEN resting_hr_bpm END), MAX(CASE WHEN mode = 'evening' THEN resting_hr_bpm END)),
+			MAX(CASE WHEN mode = 'morning' THEN readiness_score END),
+			MAX(CASE WHEN mode = 'evening' THEN energy_balance_kcal END),
+			MAX(CASE WHEN mode = 'evening' THEN protein_g END),
+			MAX(CASE WHEN mode = 'evening' THEN steps END)
+		FROM briefings
+		WHERE date >= ? AND date <= ?
+		GROUP BY date
+		ORDER BY date
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("history query error: %w", err)
+	}
+	defer rows.Close()
+
+	var days []HistoryDay
+	for rows.Next() {
+		var d HistoryDay
+		var sleep, hrv, rhr, protein sql.NullFloat64
+		var readiness, balance, steps sql.NullInt64
+		if err := rows.Scan(&d.Date, &sleep, &hrv, &rhr, &readiness, &balance, &protein, &steps); err != nil {
+			return days, err
+		}
+		d.SleepHours, d.HRV, d.RestingHR, d.ProteinG = nullFloat(sleep), nullFloat(hrv), nullFloat(rhr), nullFloat(protein)
+		d.ReadinessScore, d.EnergyBalanceKcal, d.Steps = nullInt(readiness), nullInt(balance), nullInt(steps)
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+func nullFloat(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
+
+func nullInt(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
+// HistoryFieldRow is one stored briefing's values for the requested fields;
+// fields missing from that day's briefing are absent
+type HistoryFieldRow struct {
+	Date   string
+	Values map[string]any
+}
+
+// MarshalJSON flattens the row to {"date": ..., "<field>": value, ...}
+func (r HistoryFieldRow) MarshalJSON() ([]byte, error) {
+	out := map[string]any{"date": r.Date}
+	for k, v := range r.Values {
+		out[k] = v
+	}
+	return json.Marshal(out)
+}
+
+// RunHistoryCommand handles `briefing history --since DATE --field PATH`
+func RunHistoryCommand(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	since := fs.String("since", "", "First date (YYYY-MM-DD)")
+	until := fs.String("until", time.Now().Format("2006-01-02"), "Last date (YYYY-MM-DD)")
+	mode := fs.String("mode", "morning", "Briefing mode: morning or evening")
+	field := fs.String("field", "", "Comma-separated JSON paths, e.g. sleep.total_hours,classification.readiness_score")
+	asJSON := fs.Bool("json", false, "Print a JSON array instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *since == "" || *field == "" {
+		return errors.New("usage: briefing history --since YYYY-MM-DD --field PATH[,PATH...] [--until YYYY-MM-DD] [--mode morning|evening] [--json]")
+	}
+	if *mode != "morning" && *mode != "evening" {
+		return fmt.Errorf("unknown mode %q (expected: morning, evening)", *mode)
+	}
+	if err := validateDateRange(*since, *until); err != nil {
+		return err
+	}
+	fields := strings.Split(*field, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := queryHistoryFields(db, *mode, *since, *until, fields)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if !anyHasField(rows, f) && len(rows) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: field %q not found in any %s briefing\n", f, *mode)
+		}
+	}
+
+	if *asJSON {
+		if rows == nil {
+			rows = []HistoryFieldRow{}
+		}
+		out, _ := json.MarshalIndent(rows, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	writeHistoryTable(os.Stdout, fields, rows)
+	return nil
+}
+
+// queryHistoryFields extracts the given dotted JSON paths from each stored
+// briefing of mode in [from, to], oldest first
+func queryHistoryFields(db *sql.DB, mode, from, to string, fields []string) ([]HistoryFieldRow, error) {
+	rows, err := db.Query(`SELECT date, json FROM briefings WHERE mode = ? AND date >= ? AND date <= ? ORDER BY date`, mode, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("history query error: %w", err)
+	}
+	defer rows.Close()
+
+	var out []HistoryFieldRow
+	for rows.Next() {
+		var date, stored string
+		if err := rows.Scan(&date, &stored); err != nil {
+			return nil, fmt.Errorf("history query error: %w", err)
+		}
+		data, err := openAtRest([]byte(stored))
+		if err != nil {
+			return nil, fmt.Errorf("history %s %s: %w", date, mode, err)
+		}
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("history %s %s: %w", date, mode, err)
+		}
+		row := HistoryFieldRow{Date: date, Values: map[string]any{}}
+		for _, f := range fields {
+			if v, ok := lookupJSONPath(doc, f); ok {
+				row.Values[f] = v
+			}
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// lookupJSONPath walks a dotted path through decoded JSON; numeric segments
+// index arrays (calendar.morning_events.0.summary)
+func lookupJSONPath(doc any, path string) (any, bool) {
+	cur := doc
+	for _, key := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, cur != nil
+}
+
+func anyHasField(rows []HistoryFieldRow, field string) bool {
+	for _, r := range rows {
+		if _, ok := r.Values[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHistoryTable prints one row per date with a column per field, "-" when missing
+func writeHistoryTable(w io.Writer, fields []string, rows []HistoryFieldRow) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "DATE\t%s\n", strings.Join(fields, "\t"))
+	for _, r := range rows {
+		cells := make([]string, len(fields))
+		for i, f := range fields {
+			cells[i] = formatHistoryValue(r.Values[f])
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", r.Date, strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+}
+
+func formatHistoryValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
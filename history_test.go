@@ -0,0 +1,268 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestHistoryDB creates an empty history store in a temp dir
+func newTestHistoryDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := openHistoryDB(filepath.Join(t.TempDir(), "state", "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// ==================== HISTORY STORE TESTS ====================
+
+func TestRecordHistory(t *testing.T) {
+	t.Setenv("BRIEFING_DATA_DIR", t.TempDir())
+
+	b := EveningBriefing{
+		GeneratedAt: "2024-01-15T21:00:00+07:00",
+		TargetDate:  "2024-01-15",
+		Energy:      EnergyData{DeficitOrSurplusKcal: -200},
+	}
+	if err := recordHistory(eveningHistoryRecord(b, HistorySourceLive)); err != nil {
+		t.Fatalf("recordHistory() error: %v", err)
+	}
+	// A later run the same evening replaces the first
+	b.GeneratedAt = "2024-01-15T22:30:00+07:00"
+	b.Energy.DeficitOrSurplusKcal = -350
+	if err := recordHistory(eveningHistoryRecord(b, HistorySourceLive)); err != nil {
+		t.Fatalf("recordHistory() error: %v", err)
+	}
+
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count, balance int
+	var source, generatedAt string
+	db.QueryRow(`SELECT COUNT(*), MAX(energy_balance_kcal), MAX(source), MAX(generated_at) FROM briefings`).Scan(&count, &balance, &source, &generatedAt)
+	if count != 1 || balance != -350 || source != HistorySourceLive || generatedAt != "2024-01-15T22:30:00+07:00" {
+		t.Errorf("rows = %d, balance %d, source %q, generated_at %q; want the latest live run only", count, balance, source, generatedAt)
+	}
+}
+
+func TestSaveHistoryRecord(t *testing.T) {
+	db := newTestHistoryDB(t)
+
+	b := MorningBriefing{
+		GeneratedAt: "2024-01-15T07:30:00+07:00",
+		TargetDate:  "2024-01-15",
+		Sleep:       SleepData{TotalHours: ptr(7.5)},
+		Vitals:      VitalsData{HRV: ptr(45)},
+		Classification: Classification{
+			SleepQuality:   "GOOD",
+			RecoveryStatus: "GOOD",
+			ReadinessScore: intPtr(90),
+		},
+	}
+
+	written, err := saveHistoryRecord(db, morningHistoryRecord(b, HistorySourceBackfill), false)
+	if err != nil {
+		t.Fatalf("saveHistoryRecord() error: %v", err)
+	}
+	if !written {
+		t.Error("saveHistoryRecord() written = false, want true")
+	}
+
+	// Without replace, the existing row wins
+	b.Sleep.TotalHours = ptr(5.0)
+	written, err = saveHistoryRecord(db, morningHistoryRecord(b, HistorySourceLive), false)
+	if err != nil {
+		t.Fatalf("saveHistoryRecord() error: %v", err)
+	}
+	if written {
+		t.Error("saveHistoryRecord() without replace overwrote existing row")
+	}
+
+	var hours float64
+	var source string
+	db.QueryRow(`SELECT sleep_hours, source FROM briefings WHERE date = '2024-01-15' AND mode = 'morning'`).Scan(&hours, &source)
+	if hours != 7.5 || source != HistorySourceBackfill {
+		t.Errorf("row = (%v, %q), want (7.5, %q)", hours, source, HistorySourceBackfill)
+	}
+
+	// With replace, the new row wins
+	if _, err := saveHistoryRecord(db, morningHistoryRecord(b, HistorySourceLive), true); err != nil {
+		t.Fatalf("saveHistoryRecord() error: %v", err)
+	}
+	db.QueryRow(`SELECT sleep_hours, source FROM briefings WHERE date = '2024-01-15' AND mode = 'morning'`).Scan(&hours, &source)
+	if hours != 5.0 || source != HistorySourceLive {
+		t.Errorf("row = (%v, %q), want (5, %q)", hours, source, HistorySourceLive)
+	}
+
+	// Full JSON is kept alongside the scalar columns
+	var quality string
+	db.QueryRow(`SELECT json_extract(json, '$.classification.sleep_quality') FROM briefings`).Scan(&quality)
+	if quality != "GOOD" {
+		t.Errorf("json sleep_quality = %q, want %q", quality, "GOOD")
+	}
+}
+
+func TestEveningHistoryRecord(t *testing.T) {
+	b := EveningBriefing{
+		TargetDate: "2024-01-15",
+		Energy:     EnergyData{DeficitOrSurplusKcal: -400, ConsumedKcal: 1850, ActiveKcal: 611},
+		Protein:    ProteinData{ConsumedG: 128},
+		Activity:   ActivityData{Steps: 8432},
+	}
+
+	r := eveningHistoryRecord(b, HistorySourceLive)
+	if r.Mode != "evening" {
+		t.Errorf("Mode = %q, want %q", r.Mode, "evening")
+	}
+	if r.EnergyBalanceKcal == nil || *r.EnergyBalanceKcal != -400 {
+		t.Errorf("EnergyBalanceKcal = %v, want -400", r.EnergyBalanceKcal)
+	}
+	if r.Steps == nil || *r.Steps != 8432 {
+		t.Errorf("Steps = %v, want 8432", r.Steps)
+	}
+	if r.HRV != nil {
+		t.Errorf("HRV = %v, want nil when not recorded", *r.HRV)
+	}
+}
+
+func TestQueryHistoryDays(t *testing.T) {
+	db := newTestHistoryDB(t)
+
+	morning := MorningBriefing{
+		TargetDate:     "2024-01-15",
+		Sleep:          SleepData{TotalHours: ptr(7.5)},
+		Vitals:         VitalsData{HRV: ptr(45)},
+		Classification: Classification{ReadinessScore: intPtr(80)},
+	}
+	evening := EveningBriefing{TargetDate: "2024-01-15"}
+	evening.Energy.DeficitOrSurplusKcal = -400
+	evening.Recovery.HRVMS = 50
+	evening.Recovery.RestingHRBPM = 55
+	eveningOnly := EveningBriefing{TargetDate: "2024-01-16"}
+	eveningOnly.Recovery.HRVMS = 38
+
+	for _, r := range []HistoryRecord{
+		morningHistoryRecord(morning, HistorySourceLive),
+		eveningHistoryRecord(evening, HistorySourceLive),
+		eveningHistoryRecord(eveningOnly, HistorySourceLive),
+	} {
+		if _, err := saveHistoryRecord(db, r, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	days, err := queryHistoryDays(db, "2024-01-01", "2024-01-31")
+	if err != nil {
+		t.Fatalf("queryHistoryDays() error: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("len(days) = %d, want 2", len(days))
+	}
+
+	d := days[0]
+	if d.Date != "2024-01-15" || d.SleepHours == nil || *d.SleepHours != 7.5 {
+		t.Errorf("day 0 = %+v, want 2024-01-15 with 7.5h sleep", d)
+	}
+	// Morning HRV wins over the evening copy; resting HR falls back to evening
+	if d.HRV == nil || *d.HRV != 45 {
+		t.Errorf("HRV = %v, want 45", d.HRV)
+	}
+	if d.RestingHR == nil || *d.RestingHR != 55 {
+		t.Errorf("RestingHR = %v, want 55", d.RestingHR)
+	}
+	if d.ReadinessScore == nil || *d.ReadinessScore != 80 || d.EnergyBalanceKcal == nil || *d.EnergyBalanceKcal != -400 {
+		t.Errorf("day 0 readiness/balance = %v/%v, want 80/-400", d.ReadinessScore, d.EnergyBalanceKcal)
+	}
+
+	if days[1].HRV == nil || *days[1].HRV != 38 || days[1].ReadinessScore != nil {
+		t.Errorf("day 1 = %+v, want evening-only HRV 38 and no readiness", days[1])
+	}
+
+	days, err = queryHistoryDays(db, "2024-02-01", "2024-02-29")
+	if err != nil || len(days) != 0 {
+		t.Errorf("queryHistoryDays() out of range = %v, %v, want none", days, err)
+	}
+}
+
+// ==================== HISTORY COMMAND TESTS ====================
+
+func TestLookupJSONPath(t *testing.T) {
+	var doc any
+	json.Unmarshal([]byte(`{"sleep":{"total_hours":7.5,"deep_hours":null},"calendar":{"morning_events":[{"summary":"Standup"}]},"tags":[]}`), &doc)
+
+	tests := []struct {
+		path   string
+		want   any
+		wantOK bool
+	}{
+		{"sleep.total_hours", 7.5, true},
+		{"sleep.deep_hours", nil, false},
+		{"sleep.rem_hours", nil, false},
+		{"calendar.morning_events.0.summary", "Standup", true},
+		{"calendar.morning_events.1.summary", nil, false},
+		{"calendar.morning_events.x", nil, false},
+		{"sleep.total_hours.more", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := lookupJSONPath(doc, tt.path)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("lookupJSONPath(%q) = %v, %v; want %v, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestQueryHistoryFields(t *testing.T) {
+	db := newTestHistoryDB(t)
+	for i, hours := range []float64{7.5, 5.0, 6.25} {
+		b := MorningBriefing{
+			GeneratedAt:    "2024-01-15T07:00:00Z",
+			TargetDate:     addDays("2024-01-15", i),
+			Sleep:          SleepData{TotalHours: ptr(hours)},
+			Classification: Classification{SleepQuality: []string{"GOOD", "POOR", "OK"}[i]},
+		}
+		if i == 1 {
+			b.Sleep.TotalHours = nil
+		}
+		if _, err := saveHistoryRecord(db, morningHistoryRecord(b, HistorySourceLive), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saveHistoryRecord(db, eveningHistoryRecord(EveningBriefing{TargetDate: "2024-01-16"}, HistorySourceLive), false)
+
+	fields := []string{"sleep.total_hours", "classification.sleep_quality"}
+	rows, err := queryHistoryFields(db, "morning", "2024-01-16", "2024-01-31", fields)
+	if err != nil {
+		t.Fatalf("queryHistoryFields() error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Date != "2024-01-16" || rows[1].Date != "2024-01-17" {
+		t.Fatalf("rows = %+v, want 2024-01-16 and 2024-01-17", rows)
+	}
+	if _, ok := rows[0].Values["sleep.total_hours"]; ok {
+		t.Errorf("missing sleep hours should be absent, got %v", rows[0].Values)
+	}
+	if rows[1].Values["sleep.total_hours"] != 6.25 || rows[1].Values["classification.sleep_quality"] != "OK" {
+		t.Errorf("row 2 values = %v", rows[1].Values)
+	}
+
+	out, _ := json.Marshal(rows)
+	want := `[{"classification.sleep_quality":"POOR","date":"2024-01-16"},{"classification.sleep_quality":"OK","date":"2024-01-17","sleep.total_hours":6.25}]`
+	if string(out) != want {
+		t.Errorf("JSON = %s, want %s", out, want)
+	}
+
+	var table strings.Builder
+	writeHistoryTable(&table, fields, rows)
+	wantTable := "DATE        sleep.total_hours  classification.sleep_quality\n" +
+		"2024-01-16  -                  POOR\n" +
+		"2024-01-17  6.25               OK\n"
+	if table.String() != wantTable {
+		t.Errorf("table =\n%s\nwant\n%s", table.String(), wantTable)
+	}
+}
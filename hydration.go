@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Hydration allowances on top of the baseline target
+const (
+	WorkoutSweatMlPerHour = 700 // Typical sweat loss during a strength/cardio session
+	ActiveEnergyMlPerKcal = 0.5 // Extra intake per active kcal outside the baseline
+	HeatMlPerDegree       = 150 // Extra intake per °C the day's high is above weather.hot_c
+)
+
+type HydrationData struct {
+	ConsumedMl   float64  `json:"consumed_ml"`
+	BaseTargetMl int      `json:"base_target_ml"`
+	TargetMl     int      `json:"target_ml"`
+	RemainingMl  float64  `json:"remaining_ml"`
+	OnTrack      bool     `json:"on_track"`
+	HighC        *float64 `json:"high_c,omitempty"` // today's forecast high at weather.location
+}
+
+// CalculateHydrationTarget scales the baseline water target by sweat losses
+// Adds WorkoutSweatMlPerHour per hour of training, ActiveEnergyMlPerKcal per active kcal,
+// and HeatMlPerDegree per degree of heatC (the high above weather.hot_c)
+func CalculateHydrationTarget(baseMl int, workoutMinutes, activeKcal, heatC float64) int {
+	target := float64(baseMl)
+	if workoutMinutes > 0 {
+		target += workoutMinutes / 60 * WorkoutSweatMlPerHour
+	}
+	if activeKcal > 0 {
+		target += activeKcal * ActiveEnergyMlPerKcal
+	}
+	if heatC > 0 {
+		target += heatC * HeatMlPerDegree
+	}
+	return int(target + 0.5) // Round to nearest int
+}
+
+// CalculateHydrationStatus calculates remaining water intake
+// Returns: remaining ml, whether on track (>=90% of target)
+func CalculateHydrationStatus(consumedMl float64, targetMl int) (float64, bool) {
+	remaining := float64(targetMl) - consumedMl
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, consumedMl >= float64(targetMl)*0.9
+}
+
+// parseWorkoutMinutes converts a Hevy duration string ("1h15m", "45m") into minutes
+func parseWorkoutMinutes(duration string) float64 {
+	if duration == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return 0
+	}
+	return d.Minutes()
+}
+
+// getEveningWeather records today's forecast high for the heat allowance
+// when weather.location is set
+func getEveningWeather(b *EveningBriefing, today string) {
+	cfg := settings.Weather
+	if cfg.Location == "" {
+		return
+	}
+	w, err := fetchWeather(cfg, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("weather error: %v", err))
+		return
+	}
+	b.Hydration.HighC = &w.HighC
+}
+
+// calculateEveningHydration fills in the sweat-adjusted target once
+// workout, active energy, and weather data have been collected
+func calculateEveningHydration(b *EveningBriefing) {
+	workoutMinutes := 0.0
+	if b.Activity.Workout != nil && b.Activity.Workout.Done {
+		workoutMinutes = parseWorkoutMinutes(b.Activity.Workout.Duration)
+	}
+	heatC := 0.0
+	if b.Hydration.HighC != nil {
+		heatC = *b.Hydration.HighC - settings.Weather.HotC
+	}
+
+	b.Hydration.TargetMl = CalculateHydrationTarget(b.Hydration.BaseTargetMl, workoutMinutes, b.Energy.ActiveKcal, heatC)
+	b.Hydration.RemainingMl, b.Hydration.OnTrack = CalculateHydrationStatus(b.Hydration.ConsumedMl, b.Hydration.TargetMl)
+}
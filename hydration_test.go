@@ -0,0 +1,161 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ==================== HYDRATION TARGET TESTS ====================
+
+func TestCalculateHydrationTarget(t *testing.T) {
+	tests := []struct {
+		name           string
+		baseMl         int
+		workoutMinutes float64
+		activeKcal     float64
+		heatC          float64
+		expected       int
+	}{
+		{
+			name:     "Rest day, no active energy",
+			baseMl:   2500,
+			expected: 2500,
+		},
+		{
+			name:           "One hour workout",
+			baseMl:         2500,
+			workoutMinutes: 60,
+			expected:       3200, // 2500 + 700
+		},
+		{
+			name:           "Workout plus active energy",
+			baseMl:         2500,
+			workoutMinutes: 45,
+			activeKcal:     600,
+			expected:       3325, // 2500 + 525 + 300
+		},
+		{
+			name:       "Negative active energy ignored",
+			baseMl:     2500,
+			activeKcal: -100,
+			expected:   2500,
+		},
+		{
+			name:     "Hot day",
+			baseMl:   2500,
+			heatC:    3,
+			expected: 2950, // 2500 + 3 * 150
+		},
+		{
+			name:     "Below the heat threshold",
+			baseMl:   2500,
+			heatC:    -4,
+			expected: 2500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculateHydrationTarget(tt.baseMl, tt.workoutMinutes, tt.activeKcal, tt.heatC)
+			if result != tt.expected {
+				t.Errorf("CalculateHydrationTarget() = %d, want %d", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalculateHydrationStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		consumed        float64
+		target          int
+		expectedRemain  float64
+		expectedOnTrack bool
+	}{
+		{"Under target", 1500, 3000, 1500, false},
+		{"Within 90%", 2750, 3000, 250, true},
+		{"Over target", 3500, 3000, 0, true},
+		{"Nothing logged", 0, 2500, 2500, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, onTrack := CalculateHydrationStatus(tt.consumed, tt.target)
+			if remaining != tt.expectedRemain {
+				t.Errorf("CalculateHydrationStatus() remaining = %.0f, want %.0f", remaining, tt.expectedRemain)
+			}
+			if onTrack != tt.expectedOnTrack {
+				t.Errorf("CalculateHydrationStatus() onTrack = %v, want %v", onTrack, tt.expectedOnTrack)
+			}
+		})
+	}
+}
+
+func TestParseWorkoutMinutes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1h15m", 75},
+		{"45m", 45},
+		{"32m", 32},
+		{"", 0},
+		{"garbage", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := parseWorkoutMinutes(tt.input); result != tt.expected {
+				t.Errorf("parseWorkoutMinutes(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalculateEveningHydration(t *testing.T) {
+	b := &EveningBriefing{
+		Energy:    EnergyData{ActiveKcal: 400},
+		Hydration: HydrationData{BaseTargetMl: 2500, ConsumedMl: 2000},
+		Activity: ActivityData{
+			Workout: &WorkoutInfo{Done: true, Title: "Arms", Duration: "30m"},
+		},
+	}
+
+	calculateEveningHydration(b)
+
+	// 2500 + 350 (30m sweat) + 200 (400 kcal)
+	if b.Hydration.TargetMl != 3050 {
+		t.Errorf("Hydration.TargetMl = %d, want %d", b.Hydration.TargetMl, 3050)
+	}
+	if b.Hydration.RemainingMl != 1050 {
+		t.Errorf("Hydration.RemainingMl = %.0f, want %d", b.Hydration.RemainingMl, 1050)
+	}
+	if b.Hydration.OnTrack {
+		t.Error("Hydration.OnTrack = true, want false")
+	}
+}
+
+func TestEveningHydrationHeat(t *testing.T) {
+	ts := fakeOpenMeteo(t, `{"time": ["2024-01-15"], "weather_code": [0], "temperature_2m_max": [35.4], "temperature_2m_min": [27.1]}`, `{}`)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Weather.Location = "13.75,100.5"
+	settings.Weather.URL = ts.URL + "/"
+	settings.Weather.HotC = 32
+
+	b := &EveningBriefing{Hydration: HydrationData{BaseTargetMl: 2500}}
+	getEveningWeather(b, "2024-01-15")
+	calculateEveningHydration(b)
+	// 2500 + 3.4 °C over hot_c * 150
+	if b.Hydration.HighC == nil || *b.Hydration.HighC != 35.4 || b.Hydration.TargetMl != 3010 || len(b.Errors) != 0 {
+		t.Errorf("hydration = %+v, errors %v, want a 3010 ml target for a 35.4 °C high", b.Hydration, b.Errors)
+	}
+
+	settings.Weather.Location = "0,0"
+	b = &EveningBriefing{Hydration: HydrationData{BaseTargetMl: 2500}}
+	getEveningWeather(b, "2024-01-15")
+	calculateEveningHydration(b)
+	if b.Hydration.HighC != nil || b.Hydration.TargetMl != 2500 || len(b.Errors) != 1 || !strings.HasPrefix(b.Errors[0], "weather error:") {
+		t.Errorf("hydration = %+v, errors %v, want the base target and a weather error", b.Hydration, b.Errors)
+	}
+}
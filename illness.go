@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+// Illness early-warning thresholds, each compared with the personal baseline
+const (
+	RespiratoryBaselineDays = 14
+	IllnessRespiratoryDelta = 1.0   // breaths/min above baseline
+	IllnessHRVDeviationPct  = -15.0 // % below the HRV baseline
+	IllnessTemperatureDelta = 0.5   // °C above the temperature baseline
+	IllnessHighSignals      = 2     // signals at or above this make the risk HIGH
+)
+
+// Illness risk levels
+const (
+	IllnessRiskLow      = "LOW"
+	IllnessRiskElevated = "ELEVATED"
+	IllnessRiskHigh     = "HIGH"
+)
+
+// Illness signals, in the order they are listed
+const (
+	SignalRHR         = "resting HR"
+	SignalRespiratory = "respiratory rate"
+	SignalHRV         = "HRV"
+	SignalTemperature = "temperature"
+)
+
+// applyRespiratoryBaseline sets the respiratory rate baseline and delta
+func applyRespiratoryBaseline(v *VitalsData, baseline *float64) {
+	v.RespiratoryRateBaseline = baseline
+	if v.RespiratoryRate == nil || baseline == nil {
+		return
+	}
+	delta := math.Round((*v.RespiratoryRate-*baseline)*10) / 10
+	v.RespiratoryRateDelta = &delta
+}
+
+// illnessSignals lists which vitals are off their baseline in the direction
+// illness pushes them, and whether any of them could be checked at all
+func illnessSignals(v VitalsData) (signals []string, checked bool) {
+	if v.RestingHRDelta != nil {
+		checked = true
+		if slices.Contains(v.Flags, FlagRHRElevated) {
+			signals = append(signals, SignalRHR)
+		}
+	}
+	if v.RespiratoryRateDelta != nil {
+		checked = true
+		if *v.RespiratoryRateDelta >= IllnessRespiratoryDelta {
+			signals = append(signals, SignalRespiratory)
+		}
+	}
+	if dev := hrvDeviation(v); dev != nil {
+		checked = true
+		if *dev <= IllnessHRVDeviationPct {
+			signals = append(signals, SignalHRV)
+		}
+	}
+	if v.TemperatureDeviation != nil {
+		checked = true
+		if *v.TemperatureDeviation >= IllnessTemperatureDelta {
+			signals = append(signals, SignalTemperature)
+		}
+	}
+	return signals, checked
+}
+
+// classifyIllness rates illness risk from the number of signals: none is LOW,
+// one ELEVATED, two or more HIGH. Empty when no vital has a baseline yet.
+func classifyIllness(v VitalsData) (string, []string) {
+	signals, checked := illnessSignals(v)
+	switch {
+	case !checked:
+		return "", nil
+	case len(signals) >= IllnessHighSignals:
+		return IllnessRiskHigh, signals
+	case len(signals) > 0:
+		return IllnessRiskElevated, signals
+	}
+	return IllnessRiskLow, nil
+}
+
+// illnessRecommendation replaces the usual advice when illness risk is HIGH
+func illnessRecommendation(signals []string) string {
+	return "Possible illness: " + joinSignals(signals) + " off your baseline. Rest, hydrate, and skip hard training today."
+}
+
+// joinSignals lists signals as "a, b, and c"
+func joinSignals(signals []string) string {
+	switch len(signals) {
+	case 0:
+		return ""
+	case 1:
+		return signals[0]
+	case 2:
+		return signals[0] + " and " + signals[1]
+	}
+	return strings.Join(signals[:len(signals)-1], ", ") + ", and " + signals[len(signals)-1]
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== ILLNESS EARLY-WARNING TESTS ====================
+
+func TestClassifyIllness(t *testing.T) {
+	elevatedRHR := VitalsData{RestingHR: ptr(62), RestingHRDelta: ptr(7), Flags: []string{FlagRHRElevated}}
+
+	tests := []struct {
+		name        string
+		vitals      VitalsData
+		wantRisk    string
+		wantSignals []string
+	}{
+		{"no baselines", VitalsData{RestingHR: ptr(60), HRV: ptr(40)}, "", nil},
+		{"all normal", VitalsData{RestingHRDelta: ptr(1), RespiratoryRateDelta: ptr(0.2), HRV: ptr(48), HRVBaseline: ptr(50)}, IllnessRiskLow, nil},
+		{"resting HR alone", elevatedRHR, IllnessRiskElevated, []string{SignalRHR}},
+		{"respiratory rate exactly 1 above", VitalsData{RespiratoryRateDelta: ptr(1)}, IllnessRiskElevated, []string{SignalRespiratory}},
+		{
+			"resting HR and HRV",
+			VitalsData{RestingHRDelta: ptr(7), Flags: []string{FlagRHRElevated}, HRV: ptr(40), HRVBaseline: ptr(50)},
+			IllnessRiskHigh, []string{SignalRHR, SignalHRV},
+		},
+		{
+			"everything off",
+			VitalsData{RestingHRDelta: ptr(7), Flags: []string{FlagRHRElevated}, RespiratoryRateDelta: ptr(1.8), HRV: ptr(30), HRVBaseline: ptr(50)},
+			IllnessRiskHigh, []string{SignalRHR, SignalRespiratory, SignalHRV},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk, signals := classifyIllness(tt.vitals)
+			if risk != tt.wantRisk || !reflect.DeepEqual(signals, tt.wantSignals) {
+				t.Errorf("classifyIllness() = %q %v, want %q %v", risk, signals, tt.wantRisk, tt.wantSignals)
+			}
+		})
+	}
+}
+
+func TestIllnessOverridesRecommendation(t *testing.T) {
+	b := &MorningBriefing{
+		Sleep: SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true, SleepDebtHours: ptr(5)},
+		Vitals: VitalsData{
+			RestingHR: ptr(62), RestingHRDelta: ptr(7), Flags: []string{FlagRHRElevated},
+			RespiratoryRate: ptr(16), RespiratoryRateDelta: ptr(1.5),
+		},
+	}
+	classify(b)
+	want := "Possible illness: resting HR and respiratory rate off your baseline. Rest, hydrate, and skip hard training today."
+	if b.Classification.IllnessRisk != IllnessRiskHigh || b.Classification.Recommendation != want {
+		t.Errorf("classify() = %q, %q; want HIGH, %q", b.Classification.IllnessRisk, b.Classification.Recommendation, want)
+	}
+
+	// A single signal is reported but leaves the usual advice
+	b = &MorningBriefing{
+		Sleep:  SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true},
+		Vitals: VitalsData{RespiratoryRateDelta: ptr(1.5)},
+	}
+	classify(b)
+	if b.Classification.IllnessRisk != IllnessRiskElevated || b.Classification.Recommendation != "Well rested. Attack the day." {
+		t.Errorf("classify() = %q, %q; want ELEVATED with the usual advice", b.Classification.IllnessRisk, b.Classification.Recommendation)
+	}
+	if text := MorningText(*b, textStyle{}); !strings.Contains(text, "Illness  respiratory rate  ELEVATED") {
+		t.Errorf("text missing illness line:\n%s", text)
+	}
+}
+
+func TestRespiratoryBaseline(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('respiratory_rate', '2024-01-12 04:00:00 +0700', 14, 'count/min', 'Apple Watch'),
+		('respiratory_rate', '2024-01-14 04:00:00 +0700', 15, 'count/min', 'Apple Watch'),
+		('respiratory_rate', '2024-01-15 04:00:00 +0700', 16, 'count/min', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &MorningBriefing{TargetDate: "2024-01-15"}
+	fillMorningHealthFromDB(b, db, "2024-01-15", nil)
+	if b.Vitals.RespiratoryRateBaseline == nil || *b.Vitals.RespiratoryRateBaseline != 14.5 {
+		t.Fatalf("RespiratoryRateBaseline = %v, want 14.5", b.Vitals.RespiratoryRateBaseline)
+	}
+	if b.Vitals.RespiratoryRateDelta == nil || *b.Vitals.RespiratoryRateDelta != 1.5 {
+		t.Errorf("RespiratoryRateDelta = %v, want 1.5", b.Vitals.RespiratoryRateDelta)
+	}
+}
+
+func TestJoinSignals(t *testing.T) {
+	tests := map[string][]string{
+		"":                                      nil,
+		"HRV":                                   {"HRV"},
+		"resting HR and HRV":                    {"resting HR", "HRV"},
+		"resting HR, respiratory rate, and HRV": {"resting HR", "respiratory rate", "HRV"},
+	}
+	for want, signals := range tests {
+		if got := joinSignals(signals); got != want {
+			t.Errorf("joinSignals(%q) = %q, want %q", signals, got, want)
+		}
+	}
+}
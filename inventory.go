@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// MedRefillDays is the reorder threshold when an item doesn't set refill_days
+const MedRefillDays = 7
+
+// Stock units
+const (
+	StockUnitPills = "pills"
+	StockUnitMg    = "mg"
+)
+
+// InventoryItem is one med's stock as counted on as_of. Pills are counted
+// directly; injections as vials of mg_per_vial, drawn dose mg at a time.
+type InventoryItem struct {
+	Name        string  `json:"name"`  // matched against med task names, case-insensitively
+	AsOf        string  `json:"as_of"` // YYYY-MM-DD; doses taken from this day on are subtracted
+	Pills       float64 `json:"pills"`
+	Vials       float64 `json:"vials"`
+	MgPerVial   float64 `json:"mg_per_vial"`
+	Dose        float64 `json:"dose"`          // pills or mg per dose, 1 pill when 0
+	DosesPerDay float64 `json:"doses_per_day"` // 1 when 0; 2.0/7 for twice a week
+	RefillDays  int     `json:"refill_days"`   // reorder below this many days, MedRefillDays when 0
+}
+
+// MedStock is an item's remaining supply
+type MedStock struct {
+	Name      string   `json:"name"`
+	Remaining float64  `json:"remaining"`
+	Unit      string   `json:"unit"`            // pills, mg
+	Vials     *float64 `json:"vials,omitempty"` // injections only
+	DaysLeft  int      `json:"days_left"`
+	Reorder   bool     `json:"reorder"`
+}
+
+// validateInventory checks each item has a name, count date, and some stock
+func validateInventory(items []InventoryItem) error {
+	for i, it := range items {
+		if it.Name == "" {
+			return fmt.Errorf("inventory[%d]: name is required", i)
+		}
+		if _, err := time.Parse("2006-01-02", it.AsOf); err != nil {
+			return fmt.Errorf("inventory[%d]: as_of must be YYYY-MM-DD", i)
+		}
+		if it.Pills < 0 || it.Vials < 0 || it.MgPerVial < 0 || it.Dose < 0 || it.DosesPerDay < 0 || it.RefillDays < 0 {
+			return fmt.Errorf("inventory[%d]: values must not be negative", i)
+		}
+		if (it.Pills > 0) == (it.Vials > 0) {
+			return fmt.Errorf("inventory[%d]: set either pills or vials", i)
+		}
+		if it.Vials > 0 && (it.MgPerVial == 0 || it.Dose == 0) {
+			return fmt.Errorf("inventory[%d]: vials need mg_per_vial and dose", i)
+		}
+	}
+	return nil
+}
+
+// takenSince counts doses of name taken on or after from; each matching task
+// counts, so morning and evening tasks for one med are two doses
+func takenSince(days []medLogDay, name, from string) int {
+	name = strings.ToLower(name)
+	taken := 0
+	for _, day := range days {
+		if day.Date < from {
+			continue
+		}
+		for _, r := range day.Results {
+			if r.Taken && strings.Contains(strings.ToLower(r.Name), name) {
+				taken++
+			}
+		}
+	}
+	return taken
+}
+
+// medStockFor subtracts the doses logged since the count from the stock
+func medStockFor(it InventoryItem, days []medLogDay) MedStock {
+	dose := it.Dose
+	if dose == 0 {
+		dose = 1
+	}
+	perDay := it.DosesPerDay
+	if perDay == 0 {
+		perDay = 1
+	}
+	refill := it.RefillDays
+	if refill == 0 {
+		refill = MedRefillDays
+	}
+
+	s := MedStock{Name: it.Name, Unit: StockUnitPills}
+	stock := it.Pills
+	if it.Vials > 0 {
+		s.Unit = StockUnitMg
+		stock = it.Vials * it.MgPerVial
+	}
+	s.Remaining = max(stock-float64(takenSince(days, it.Name, it.AsOf))*dose, 0)
+	if it.Vials > 0 {
+		vials := roundTo(s.Remaining/it.MgPerVial, 1)
+		s.Vials = &vials
+	}
+	s.DaysLeft = int(math.Floor(s.Remaining / (dose * perDay)))
+	s.Reorder = s.DaysLeft < refill
+	return s
+}
+
+// inventoryFor works out every configured item's supply from the med log
+func inventoryFor(items []InventoryItem, days []medLogDay) []MedStock {
+	var stock []MedStock
+	for _, it := range items {
+		stock = append(stock, medStockFor(it, days))
+	}
+	return stock
+}
+
+// reorderLine is a refill reminder, e.g. "Nexium runs out in 6 days — reorder"
+func reorderLine(s MedStock) string {
+	switch s.DaysLeft {
+	case 0:
+		return s.Name + " runs out today — reorder"
+	case 1:
+		return s.Name + " runs out tomorrow — reorder"
+	}
+	return fmt.Sprintf("%s runs out in %d days — reorder", s.Name, s.DaysLeft)
+}
+
+// reorderLines lists the reminders for items below their refill threshold
+func reorderLines(stock []MedStock) []string {
+	var lines []string
+	for _, s := range stock {
+		if s.Reorder {
+			lines = append(lines, reorderLine(s))
+		}
+	}
+	return lines
+}
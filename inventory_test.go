@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ==================== INVENTORY TESTS ====================
+
+func TestValidateInventory(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       []InventoryItem
+		expectError bool
+	}{
+		{"none", nil, false},
+		{"pills", []InventoryItem{{Name: "Nexium", AsOf: "2024-01-01", Pills: 28}}, false},
+		{"vials", []InventoryItem{{Name: "TB-500", AsOf: "2024-01-01", Vials: 2, MgPerVial: 10, Dose: 2.5}}, false},
+		{"missing name", []InventoryItem{{AsOf: "2024-01-01", Pills: 28}}, true},
+		{"bad date", []InventoryItem{{Name: "Nexium", AsOf: "Jan 1", Pills: 28}}, true},
+		{"no stock", []InventoryItem{{Name: "Nexium", AsOf: "2024-01-01"}}, true},
+		{"pills and vials", []InventoryItem{{Name: "Nexium", AsOf: "2024-01-01", Pills: 28, Vials: 1, MgPerVial: 10, Dose: 1}}, true},
+		{"vials without a dose", []InventoryItem{{Name: "TB-500", AsOf: "2024-01-01", Vials: 2, MgPerVial: 10}}, true},
+		{"negative refill", []InventoryItem{{Name: "Nexium", AsOf: "2024-01-01", Pills: 28, RefillDays: -1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInventory(tt.items)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateInventory() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestMedStockFor(t *testing.T) {
+	days := []medLogDay{
+		{"2023-12-31", []MedResult{{"Nexium 40mg", true}}},
+		{"2024-01-01", []MedResult{{"Nexium 40mg", true}, {"TB-500 injection", true}}},
+		{"2024-01-02", []MedResult{{"Nexium 40mg", false}}},
+		{"2024-01-03", []MedResult{{"nexium 40mg", true}}},
+		{"2024-01-04", []MedResult{{"Nexium 40mg", true}, {"TB-500 injection", true}}},
+	}
+
+	// The 12-31 dose was before the count and the 01-02 dose was missed
+	nexium := medStockFor(InventoryItem{Name: "Nexium", AsOf: "2024-01-01", Pills: 9}, days)
+	if nexium.Remaining != 6 || nexium.Unit != StockUnitPills || nexium.DaysLeft != 6 || !nexium.Reorder || nexium.Vials != nil {
+		t.Errorf("Nexium = %+v, want 6 pills, 6 days, reorder", nexium)
+	}
+	if got := reorderLine(nexium); got != "Nexium runs out in 6 days — reorder" {
+		t.Errorf("reorderLine() = %q", got)
+	}
+
+	// Twice a week at 2.5 mg leaves 15 mg for 21 days
+	tb := medStockFor(InventoryItem{Name: "TB-500", AsOf: "2024-01-01", Vials: 2, MgPerVial: 10, Dose: 2.5, DosesPerDay: 2.0 / 7}, days)
+	if tb.Remaining != 15 || tb.Unit != StockUnitMg || tb.Vials == nil || *tb.Vials != 1.5 || tb.DaysLeft != 21 || tb.Reorder {
+		t.Errorf("TB-500 = %+v, want 15 mg in 1.5 vials lasting 21 days", tb)
+	}
+
+	// Stock never goes below zero
+	out := medStockFor(InventoryItem{Name: "Nexium", AsOf: "2024-01-01", Pills: 2, RefillDays: 3}, days)
+	if out.Remaining != 0 || out.DaysLeft != 0 || reorderLine(out) != "Nexium runs out today — reorder" {
+		t.Errorf("empty stock = %+v, %q", out, reorderLine(out))
+	}
+}
+
+func TestMorningInventory(t *testing.T) {
+	t.Setenv("BRIEFING_DATA_DIR", t.TempDir())
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Inventory = []InventoryItem{
+		{Name: "Nexium", AsOf: "2024-01-13", Pills: 8},
+		{Name: "Vitamin D", AsOf: "2024-01-13", Pills: 90},
+	}
+	for _, date := range []string{"2024-01-13", "2024-01-14", "2024-01-15"} {
+		if err := recordMedLog(date, ProtocolsData{Completed: []string{"Nexium", "Vitamin D"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Today's evening hasn't run yet, so two doses are subtracted
+	b := &MorningBriefing{}
+	getMedCallouts(b, "2024-01-15")
+	if len(b.Meds.Inventory) != 2 || b.Meds.Inventory[0].Remaining != 6 || b.Meds.Inventory[1].Reorder {
+		t.Fatalf("Inventory = %+v, want Nexium at 6 and Vitamin D stocked", b.Meds.Inventory)
+	}
+	line := "Nexium runs out in 6 days — reorder"
+	if md := MorningMarkdown(*b); !strings.Contains(md, "- **"+line+"**\n") {
+		t.Errorf("markdown missing reorder reminder:\n%s", md)
+	}
+	if text := MorningText(*b, textStyle{}); !strings.Contains(text, "  "+line+"\n") {
+		t.Errorf("text missing reorder reminder:\n%s", text)
+	}
+	if vars := morningRuleVars(*b); vars["meds.reorder"] != 1.0 {
+		t.Errorf("meds.reorder = %v, want 1", vars["meds.reorder"])
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Jet lag planning
+const (
+	JetLagMinShiftHours   = 3   // smaller time differences get no plan
+	JetLagPrepDays        = 3   // days before departure the schedule starts moving
+	JetLagShiftPerDay     = 1.0 // hours bed and wake move each prep day
+	JetLagEastAdaptPerDay = 1.0 // hours the body clock catches up per day after flying east
+	JetLagWestAdaptPerDay = 1.5 // the same flying west, which is easier
+	JetLagMaxAfterDays    = 5   // days after the flight the plan runs for at most
+	JetLagLightHours      = 2   // length of the bright light window
+	JetLagCaffeineHours   = 8   // no caffeine within this many hours of bedtime
+)
+
+// Jet lag plan phases
+const (
+	JetLagBefore = "before" // at home, shifting toward the destination
+	JetLagTravel = "travel" // departure day, on destination time
+	JetLagAfter  = "after"  // at the destination
+)
+
+// JetLagPlan shifts sleep, light, and caffeine around a flight across
+// several time zones: bed and wake move an hour a day before it, and the
+// destination's usual schedule is kept after it
+type JetLagPlan struct {
+	Flight        string      `json:"flight"` // the trip's summary
+	Departs       string      `json:"departs"`
+	DestinationTZ string      `json:"destination_tz"`
+	ShiftHours    float64     `json:"shift_hours"` // destination minus home; positive is east
+	Direction     string      `json:"direction"`   // east, west
+	Days          []JetLagDay `json:"days"`
+}
+
+// JetLagDay is one day's schedule, in home time before the flight and
+// destination time from the departure day on
+type JetLagDay struct {
+	Date           string `json:"date"`
+	Phase          string `json:"phase"`   // before, travel, after
+	Bedtime        string `json:"bedtime"` // HH:MM
+	Wake           string `json:"wake"`    // HH:MM
+	Light          string `json:"light"`   // HH:MM-HH:MM of bright light, outdoors if possible
+	CaffeineCutoff string `json:"caffeine_cutoff"`
+}
+
+// jetLagPlan is the plan for the flight whose days cover today, from the
+// usual wake and bedtime in the supplements config; nil when none does
+func jetLagPlan(trips []Trip, today string, sched SupplementsConfig) *JetLagPlan {
+	wake, err1 := parseClock(sched.Wake)
+	bed, err2 := parseClock(sched.Bedtime)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	for _, t := range trips {
+		if t.Kind != TripFlight || t.DestinationTZ == "" {
+			continue
+		}
+		shift, ok := zoneShift(t.Date, t.DestinationTZ)
+		if !ok || math.Abs(shift) < JetLagMinShiftHours {
+			continue
+		}
+		plan := planJetLag(t, shift, wake, bed)
+		if plan.Days[0].Date <= today && today <= plan.Days[len(plan.Days)-1].Date {
+			return plan
+		}
+	}
+	return nil
+}
+
+// zoneShift is how many hours the zone is ahead of local time on date, taking
+// the shorter way round the world
+func zoneShift(date, zone string) (float64, bool) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return 0, false
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return 0, false
+	}
+	noon := day.Add(12 * time.Hour)
+	_, here := noon.Zone()
+	_, there := noon.In(loc).Zone()
+	shift := float64(there-here) / 3600
+	if shift > 12 {
+		shift -= 24
+	} else if shift <= -12 {
+		shift += 24
+	}
+	return shift, true
+}
+
+// planJetLag lays out the days: each prep day moves bed and wake another hour
+// (earlier going east), and the days after last until the remaining shift is
+// caught up
+func planJetLag(t Trip, shift float64, wake, bed int) *JetLagPlan {
+	east := shift > 0
+	plan := &JetLagPlan{Flight: t.Summary, Departs: t.Date, DestinationTZ: t.DestinationTZ, ShiftHours: shift, Direction: "west"}
+	if east {
+		plan.Direction = "east"
+	}
+	total := math.Abs(shift)
+
+	prepShift := 0.0
+	for k := JetLagPrepDays; k >= 1; k-- {
+		prepShift = math.Min(JetLagShiftPerDay*float64(JetLagPrepDays-k+1), total)
+		move := int(prepShift * 60)
+		if east {
+			move = -move
+		}
+		plan.Days = append(plan.Days, jetLagDay(addDays(t.Date, -k), JetLagBefore, wake+move, bed+move, east))
+	}
+	plan.Days = append(plan.Days, jetLagDay(t.Date, JetLagTravel, wake, bed, east))
+
+	rate := JetLagWestAdaptPerDay
+	if east {
+		rate = JetLagEastAdaptPerDay
+	}
+	after := min(int(math.Ceil((total-prepShift)/rate)), JetLagMaxAfterDays)
+	for i := 1; i <= after; i++ {
+		plan.Days = append(plan.Days, jetLagDay(addDays(t.Date, i), JetLagAfter, wake, bed, east))
+	}
+	return plan
+}
+
+// jetLagDay sets a day's light and caffeine around its wake and bedtime:
+// morning light pulls the body clock earlier (east), evening light pushes it
+// later (west)
+func jetLagDay(date, phase string, wake, bed int, east bool) JetLagDay {
+	lightFrom := bed - (JetLagLightHours+1)*60
+	if east {
+		lightFrom = wake
+	}
+	return JetLagDay{
+		Date:           date,
+		Phase:          phase,
+		Bedtime:        clockAt(bed),
+		Wake:           clockAt(wake),
+		Light:          clockAt(lightFrom) + "-" + clockAt(lightFrom+JetLagLightHours*60),
+		CaffeineCutoff: clockAt(bed - JetLagCaffeineHours*60),
+	}
+}
+
+// clockAt formats minutes from midnight as HH:MM, wrapping past either end
+func clockAt(minutes int) string {
+	m := (minutes%1440 + 1440) % 1440
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// jetLagShift is the size and direction of the shift, e.g. "6h east"
+func jetLagShift(p *JetLagPlan) string {
+	return strconv.FormatFloat(math.Abs(p.ShiftHours), 'f', -1, 64) + "h " + p.Direction
+}
+
+// jetLagHeadline describes the plan, e.g.
+// "6h east to Europe/Berlin for Flight to Frankfurt on Tue Jan 16"
+func jetLagHeadline(p *JetLagPlan, today string) string {
+	return fmt.Sprintf("%s to %s for %s %s", jetLagShift(p), p.DestinationTZ, p.Flight, tripDayOn(p.Departs, today))
+}
+
+// tripDayOn is tripDay with "on" before a weekday date
+func tripDayOn(date, today string) string {
+	day := tripDay(date, today)
+	if day == "today" || day == "tomorrow" {
+		return day
+	}
+	return "on " + day
+}
+
+// jetLagDayLine is a day's schedule, e.g.
+// "bed 22:00, wake 06:00, bright light 06:00-08:00, no caffeine after 14:00"
+func jetLagDayLine(d JetLagDay) string {
+	return fmt.Sprintf("bed %s, wake %s, bright light %s, no caffeine after %s", d.Bedtime, d.Wake, d.Light, d.CaffeineCutoff)
+}
+
+// jetLagLines lists the plan's days, e.g. "today (before): bed 22:00, ..."
+func jetLagLines(p *JetLagPlan, today string) []string {
+	if p == nil {
+		return nil
+	}
+	var lines []string
+	for _, d := range p.Days {
+		phase := d.Phase
+		switch d.Phase {
+		case JetLagTravel:
+			phase = "travel day, " + p.DestinationTZ + " time"
+		case JetLagAfter:
+			phase = p.DestinationTZ + " time"
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s): %s", tripDay(d.Date, today), phase, jetLagDayLine(d)))
+	}
+	return lines
+}
+
+// jetLagNote is appended to the recommendation with today's part of the
+// plan, e.g. " Jet lag plan for Flight to Frankfurt (6h east): bed 22:00, ..."
+func jetLagNote(p *JetLagPlan, today string) string {
+	if p == nil {
+		return ""
+	}
+	for _, d := range p.Days {
+		if d.Date != today {
+			continue
+		}
+		note := fmt.Sprintf(" Jet lag plan for %s (%s)", p.Flight, jetLagShift(p))
+		switch d.Phase {
+		case JetLagTravel:
+			note += ", on " + p.DestinationTZ + " time from today"
+		case JetLagAfter:
+			note += ", on " + p.DestinationTZ + " time"
+		}
+		return note + ": " + jetLagDayLine(d) + "."
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ==================== JET LAG TESTS ====================
+
+func TestZoneShift(t *testing.T) {
+	tests := []struct {
+		home, zone string
+		expected   float64
+	}{
+		{"Asia/Bangkok", "Europe/Berlin", -6},
+		{"Asia/Bangkok", "Asia/Tokyo", 2},
+		{"Asia/Bangkok", "Asia/Kolkata", -1.5},
+		{"Europe/London", "Pacific/Auckland", -11}, // 13 hours ahead is 11 behind the short way
+		{"Europe/Berlin", "America/New_York", -6},
+	}
+	for _, tt := range tests {
+		inZone(t, tt.home)
+		if got, ok := zoneShift("2024-01-16", tt.zone); !ok || got != tt.expected {
+			t.Errorf("zoneShift(%s to %s) = %v, %v, want %v", tt.home, tt.zone, got, ok, tt.expected)
+		}
+	}
+}
+
+func TestPlanJetLagWest(t *testing.T) {
+	inZone(t, "Asia/Bangkok")
+	trip := Trip{Kind: TripFlight, Summary: "Flight to Frankfurt", Date: "2024-01-16", DestinationTZ: "Europe/Berlin"}
+	plan := jetLagPlan([]Trip{trip}, "2024-01-15", DefaultConfig().Supplements) // wake 07:00, bed 23:00
+	if plan == nil {
+		t.Fatal("jetLagPlan() = nil, want a plan for 6 hours west")
+	}
+	if plan.ShiftHours != -6 || plan.Direction != "west" {
+		t.Errorf("shift = %v %s, want -6 west", plan.ShiftHours, plan.Direction)
+	}
+	want := []JetLagDay{
+		{"2024-01-13", JetLagBefore, "00:00", "08:00", "21:00-23:00", "16:00"},
+		{"2024-01-14", JetLagBefore, "01:00", "09:00", "22:00-00:00", "17:00"},
+		{"2024-01-15", JetLagBefore, "02:00", "10:00", "23:00-01:00", "18:00"},
+		{"2024-01-16", JetLagTravel, "23:00", "07:00", "20:00-22:00", "15:00"},
+		{"2024-01-17", JetLagAfter, "23:00", "07:00", "20:00-22:00", "15:00"},
+		{"2024-01-18", JetLagAfter, "23:00", "07:00", "20:00-22:00", "15:00"},
+	}
+	if len(plan.Days) != len(want) {
+		t.Fatalf("days = %+v, want %d", plan.Days, len(want))
+	}
+	for i, d := range plan.Days {
+		if d != want[i] {
+			t.Errorf("day %d = %+v, want %+v", i, d, want[i])
+		}
+	}
+}
+
+func TestPlanJetLagEast(t *testing.T) {
+	inZone(t, "Europe/Berlin")
+	trip := Trip{Kind: TripFlight, Summary: "TG 921", Date: "2024-01-16", DestinationTZ: "Asia/Bangkok"}
+	plan := jetLagPlan([]Trip{trip}, "2024-01-14", DefaultConfig().Supplements)
+	if plan == nil || plan.Direction != "east" || len(plan.Days) != 7 {
+		t.Fatalf("jetLagPlan() = %+v, want 3 days before, the flight, and 3 after", plan)
+	}
+	if d := plan.Days[1]; d.Bedtime != "21:00" || d.Wake != "05:00" || d.Light != "05:00-07:00" || d.CaffeineCutoff != "13:00" {
+		t.Errorf("second prep day = %+v, want bed and wake two hours earlier, light on waking", d)
+	}
+}
+
+func TestJetLagPlanCoverage(t *testing.T) {
+	inZone(t, "Asia/Bangkok")
+	sched := DefaultConfig().Supplements
+	berlin := Trip{Kind: TripFlight, Summary: "Flight to Frankfurt", Date: "2024-01-16", DestinationTZ: "Europe/Berlin"}
+	tests := []struct {
+		name  string
+		trips []Trip
+		today string
+		want  bool
+	}{
+		{"prep day", []Trip{berlin}, "2024-01-13", true},
+		{"too early", []Trip{berlin}, "2024-01-12", false},
+		{"last day after", []Trip{berlin}, "2024-01-18", true},
+		{"over", []Trip{berlin}, "2024-01-19", false},
+		{"small shift", []Trip{{Kind: TripFlight, Date: "2024-01-16", DestinationTZ: "Asia/Tokyo"}}, "2024-01-15", false},
+		{"unknown destination", []Trip{{Kind: TripFlight, Date: "2024-01-16"}}, "2024-01-15", false},
+		{"hotel", []Trip{{Kind: TripHotel, Date: "2024-01-16", DestinationTZ: "Europe/Berlin"}}, "2024-01-15", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jetLagPlan(tt.trips, tt.today, sched); (got != nil) != tt.want {
+				t.Errorf("jetLagPlan() = %+v, want a plan: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJetLagNote(t *testing.T) {
+	inZone(t, "Asia/Bangkok")
+	trip := Trip{Kind: TripFlight, Summary: "Flight to Frankfurt", Date: "2024-01-16", DestinationTZ: "Europe/Berlin"}
+	plan := jetLagPlan([]Trip{trip}, "2024-01-15", DefaultConfig().Supplements)
+	tests := []struct {
+		today    string
+		expected string
+	}{
+		{"2024-01-15", " Jet lag plan for Flight to Frankfurt (6h west): bed 02:00, wake 10:00, bright light 23:00-01:00, no caffeine after 18:00."},
+		{"2024-01-16", " Jet lag plan for Flight to Frankfurt (6h west), on Europe/Berlin time from today: bed 23:00, wake 07:00, bright light 20:00-22:00, no caffeine after 15:00."},
+		{"2024-01-17", " Jet lag plan for Flight to Frankfurt (6h west), on Europe/Berlin time: bed 23:00, wake 07:00, bright light 20:00-22:00, no caffeine after 15:00."},
+		{"2024-01-20", ""},
+	}
+	for _, tt := range tests {
+		if got := jetLagNote(plan, tt.today); got != tt.expected {
+			t.Errorf("jetLagNote(%s) = %q, want %q", tt.today, got, tt.expected)
+		}
+	}
+	if jetLagNote(nil, "2024-01-15") != "" {
+		t.Error("jetLagNote(nil) should be empty")
+	}
+}
+
+func TestMorningJetLag(t *testing.T) {
+	inZone(t, "Asia/Bangkok")
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Calendars = []CalendarAccount{{Account: "me@example.com", Source: "personal"}}
+	// Landed yesterday: past the travel window, still in the plan
+	fakeGog(t, `{"events": [
+		{"summary": "Flight to Frankfurt (LH 773)", "location": "BKK", "start": {"dateTime": "2024-01-16T07:40:00+07:00"}, "end": {"timeZone": "Europe/Berlin"}}
+	]}`)
+
+	b := &MorningBriefing{TargetDate: "2024-01-17", Sleep: SleepData{TotalHours: ptr(7.5), DataAvailable: true}}
+	getCalendarData(b, "2024-01-17")
+	if b.Travel != nil || b.JetLagPlan == nil || b.JetLagPlan.Departs != "2024-01-16" {
+		t.Fatalf("travel = %+v, plan = %+v, errors %v", b.Travel, b.JetLagPlan, b.Errors)
+	}
+	classify(b)
+	if !strings.HasSuffix(b.Classification.Recommendation, " Jet lag plan for Flight to Frankfurt (LH 773) (6h west), on Europe/Berlin time: bed 23:00, wake 07:00, bright light 20:00-22:00, no caffeine after 15:00.") {
+		t.Errorf("recommendation = %q", b.Classification.Recommendation)
+	}
+
+	m := *b
+	today := "today (Europe/Berlin time): bed 23:00, wake 07:00, bright light 20:00-22:00, no caffeine after 15:00"
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Jet Lag\n\n6h west to Europe/Berlin for Flight to Frankfurt (LH 773) on Tue Jan 16\n\n- Sat Jan 13 (before)") || !strings.Contains(md, "- "+today+"\n") {
+		t.Errorf("markdown missing the jet lag plan:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "  "+today+"\n") {
+		t.Errorf("text missing the jet lag plan:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Jet lag plan (6h west to Europe/Berlin for Flight to Frankfurt (LH 773) on Tue Jan 16):\n- Sat Jan 13") {
+		t.Errorf("prompt missing the jet lag plan (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["jetlag.shift_hours"] != -6.0 {
+		t.Errorf("jetlag.shift_hours = %v", vars["jetlag.shift_hours"])
+	}
+	if r := RedactMorningBriefing(m); r.JetLagPlan.Flight == m.JetLagPlan.Flight || r.JetLagPlan.Days[4].Bedtime != "23:00" {
+		t.Errorf("redacted plan = %+v", r.JetLagPlan)
+	}
+}
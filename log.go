@@ -0,0 +1,147 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// metricSource marks rows written by briefing itself in the metrics table
+const metricSource = "briefing"
+
+// RunLogCommand handles `briefing log <kind> [flags]`
+func RunLogCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: briefing log meal --kcal N --protein N [--at HH:MM]")
+	}
+
+	switch args[0] {
+	case "meal":
+		return runLogMeal(args[1:])
+	default:
+		return fmt.Errorf("unknown log type %q (expected: meal)", args[0])
+	}
+}
+
+func runLogMeal(args []string) error {
+	fs := flag.NewFlagSet("log meal", flag.ContinueOnError)
+	kcal := fs.Float64("kcal", 0, "Meal energy in kcal")
+	protein := fs.Float64("protein", 0, "Meal protein in grams")
+	at := fs.String("at", "", "Meal time as HH:MM today (default now)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *kcal <= 0 && *protein <= 0 {
+		return errors.New("at least one of --kcal or --protein is required")
+	}
+	if *kcal < 0 || *protein < 0 {
+		return errors.New("--kcal and --protein must not be negative")
+	}
+
+	// The config sets the zone --at is read in
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+	mealTime, err := parseMealTime(*at, time.Now())
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite", getHealthDBPath())
+	if err != nil {
+		return fmt.Errorf("sqlite open error: %w", err)
+	}
+	defer db.Close()
+
+	if err := insertMealMetrics(db, mealTime, *kcal, *protein); err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged meal at %s: %.0f kcal, %.0f g protein\n", mealTime.Format("15:04"), *kcal, *protein)
+	return nil
+}
+
+// parseMealTime resolves an optional HH:MM on the day of now
+func parseMealTime(at string, now time.Time) (time.Time, error) {
+	if at == "" {
+		return now.Truncate(time.Second), nil
+	}
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --at %q (expected HH:MM)", at)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+}
+
+// insertMealMetrics writes dietary_energy/protein rows for a single meal. A
+// meal logged at the same time as an earlier one (or a synced row) goes in
+// the next free second, so both count.
+func insertMealMetrics(db *sql.DB, at time.Time, kcal, protein float64) error {
+	at, err := freeMetricSecond(db, at, "dietary_energy", "protein")
+	if err != nil {
+		return err
+	}
+	if kcal > 0 {
+		if err := appendMetric(db, "dietary_energy", at, kcal, "kcal"); err != nil {
+			return fmt.Errorf("dietary_energy insert error: %w", err)
+		}
+	}
+	if protein > 0 {
+		if err := appendMetric(db, "protein", at, protein, "g"); err != nil {
+			return fmt.Errorf("protein insert error: %w", err)
+		}
+	}
+	return nil
+}
+
+// freeMetricSecond returns the first second from at on with no row for any
+// of names
+func freeMetricSecond(db *sql.DB, at time.Time, names ...string) (time.Time, error) {
+	query := `SELECT COUNT(*) FROM metrics WHERE timestamp = ? AND metric_name IN (?` + strings.Repeat(", ?", len(names)-1) + `)`
+	for range 60 {
+		args := []any{at.Format(metricTimestampLayout)}
+		for _, n := range names {
+			args = append(args, n)
+		}
+		var taken int
+		if err := db.QueryRow(query, args...).Scan(&taken); err != nil {
+			return at, fmt.Errorf("metrics query error: %w", err)
+		}
+		if taken == 0 {
+			return at, nil
+		}
+		at = at.Add(time.Second)
+	}
+	return at, fmt.Errorf("no free second in the minute after %s", at.Add(-time.Minute).Format("15:04:05"))
+}
+
+// appendMetric writes a single metric row tagged with the briefing source,
+// failing rather than replacing one already at that time
+func appendMetric(db *sql.DB, metricName string, at time.Time, value float64, unit string) error {
+	query := `
+		INSERT INTO metrics (file_date, metric_name, timestamp, value, unit, source)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.Exec(query, at.Format("2006-01-02"), metricName, at.Format(metricTimestampLayout), value, unit, metricSource)
+	return err
+}
+
+// insertMetric writes (or replaces) a single metric row tagged with the
+// briefing source, for derived metrics recomputed on every run
+func insertMetric(db *sql.DB, metricName string, at time.Time, value float64, unit string) error {
+	query := `
+		INSERT INTO metrics (file_date, metric_name, timestamp, value, unit, source)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(metric_name, timestamp) DO UPDATE SET
+			value = excluded.value,
+			unit = excluded.unit,
+			source = excluded.source,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	_, err := db.Exec(query, at.Format("2006-01-02"), metricName, at.Format(metricTimestampLayout), value, unit, metricSource)
+	return err
+}
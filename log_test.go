@@ -0,0 +1,177 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== MEAL LOGGING TESTS ====================
+
+func TestParseMealTime(t *testing.T) {
+	tz := time.FixedZone("ICT", 7*3600)
+	now := time.Date(2024, 1, 15, 13, 42, 17, 500, tz)
+
+	tests := []struct {
+		name        string
+		at          string
+		expected    string
+		expectError bool
+	}{
+		{"Default now", "", "2024-01-15 13:42:17 +0700", false},
+		{"Explicit time", "08:30", "2024-01-15 08:30:00 +0700", false},
+		{"Invalid time", "8.30am", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseMealTime(tt.at, now)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("parseMealTime(%q) expected error, got nil", tt.at)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMealTime(%q) unexpected error: %v", tt.at, err)
+			}
+			if got := result.Format(metricTimestampLayout); got != tt.expected {
+				t.Errorf("parseMealTime(%q) = %q, want %q", tt.at, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInsertMealMetrics(t *testing.T) {
+	db := newTestMetricsDB(t)
+	at := time.Date(2024, 1, 15, 12, 30, 0, 0, time.FixedZone("ICT", 7*3600))
+
+	if err := insertMealMetrics(db, at, 620, 42); err != nil {
+		t.Fatalf("insertMealMetrics error: %v", err)
+	}
+
+	// Logged rows feed the same day totals the evening briefing uses
+	kcal, err := queryDayTotal(db, "dietary_energy", "2024-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kcal != 620 {
+		t.Errorf("dietary_energy total = %v, want 620", kcal)
+	}
+	protein, err := queryDayTotal(db, "protein", "2024-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protein != 42 {
+		t.Errorf("protein total = %v, want 42", protein)
+	}
+
+	var source string
+	if err := db.QueryRow(`SELECT source FROM metrics WHERE metric_name = 'protein'`).Scan(&source); err != nil {
+		t.Fatal(err)
+	}
+	if source != "briefing" {
+		t.Errorf("source = %q, want %q", source, "briefing")
+	}
+
+	// A second meal at the same time adds to the first, a second later
+	if err := insertMealMetrics(db, at, 700, 0); err != nil {
+		t.Fatalf("insertMealMetrics error: %v", err)
+	}
+	kcal, _ = queryDayTotal(db, "dietary_energy", "2024-01-15")
+	if kcal != 1320 {
+		t.Errorf("dietary_energy total after second meal = %v, want 1320", kcal)
+	}
+	var ts string
+	if err := db.QueryRow(`SELECT timestamp FROM metrics WHERE metric_name = 'dietary_energy' AND value = 700`).Scan(&ts); err != nil {
+		t.Fatal(err)
+	}
+	if ts != "2024-01-15 12:30:01 +0700" {
+		t.Errorf("second meal timestamp = %q, want a second after the first", ts)
+	}
+
+	// A synced row at that time is left alone too
+	if _, err := db.Exec(`INSERT INTO metrics (file_date, metric_name, timestamp, value, unit, source) VALUES ('2024-01-15', 'protein', '2024-01-15 12:30:02 +0700', 30, 'g', 'Health Auto Export')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := insertMealMetrics(db, at, 0, 25); err != nil {
+		t.Fatalf("insertMealMetrics error: %v", err)
+	}
+	protein, _ = queryDayTotal(db, "protein", "2024-01-15")
+	if protein != 97 {
+		t.Errorf("protein total after synced row and third meal = %v, want 97", protein)
+	}
+}
+
+func TestAppendMetricConflict(t *testing.T) {
+	db := newTestMetricsDB(t)
+	at := time.Date(2024, 1, 15, 12, 30, 0, 0, time.FixedZone("ICT", 7*3600))
+	if err := appendMetric(db, "protein", at, 42, "g"); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendMetric(db, "protein", at, 30, "g"); err == nil {
+		t.Error("appendMetric() over an existing row = nil, want error")
+	}
+}
+
+func TestRunLogCommandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"No kind", nil},
+		{"Unknown kind", []string{"snack"}},
+		{"No values", []string{"meal"}},
+		{"Negative value", []string{"meal", "--kcal", "500", "--protein", "-5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RunLogCommand(tt.args); err == nil {
+				t.Errorf("RunLogCommand(%v) expected error, got nil", tt.args)
+			}
+		})
+	}
+}
+
+// useTestHealthDB points the config at a fresh metrics DB in zone, for
+// commands that load the config themselves
+func useTestHealthDB(t *testing.T, zone string) *sql.DB {
+	t.Helper()
+	withLocal(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+
+	db := newTestMetricsDB(t)
+	var seq int
+	var name, path string
+	if err := db.QueryRow("PRAGMA database_list").Scan(&seq, &name, &path); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(config, []byte(fmt.Sprintf(`{"timezone": %q, "health_db": %q}`, zone, path)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BRIEFING_CONFIG", config)
+	return db
+}
+
+func TestRunLogMealTimezone(t *testing.T) {
+	db := useTestHealthDB(t, "Asia/Tokyo")
+	time.Local = time.UTC
+
+	if err := RunLogCommand([]string{"meal", "--kcal", "500", "--at", "08:00"}); err != nil {
+		t.Fatalf("RunLogCommand() error: %v", err)
+	}
+	var ts string
+	if err := db.QueryRow(`SELECT timestamp FROM metrics WHERE metric_name = 'dietary_energy'`).Scan(&ts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(ts, " 08:00:00 +0900") {
+		t.Errorf("meal timestamp = %q, want 08:00 in the configured zone", ts)
+	}
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,15 +21,38 @@ import (
 
 // Output structure for LLM consumption
 type MorningBriefing struct {
-	GeneratedAt    string         `json:"generated_at"`
-	TargetDate     string         `json:"target_date"`
-	Sleep          SleepData      `json:"sleep"`
-	Vitals         VitalsData     `json:"vitals"`
-	Calendar       CalendarData   `json:"calendar"`
-	Meds           MedsData       `json:"meds"`
-	Training       TrainingData   `json:"training"`
-	Classification Classification `json:"classification"`
-	Errors         []string       `json:"errors,omitempty"`
+	GeneratedAt    string           `json:"generated_at"`
+	TargetDate     string           `json:"target_date"`
+	Sleep          SleepData        `json:"sleep"`
+	Vitals         VitalsData       `json:"vitals"`
+	Checkin        *CheckinData     `json:"checkin,omitempty"`
+	Glucose        *GlucoseData     `json:"glucose,omitempty"` // CGM readings, when blood_glucose is logged
+	Steps          *StepsData       `json:"steps,omitempty"`   // yesterday's steps against the goal
+	Alcohol        *AlcoholData     `json:"alcohol,omitempty"` // drinks logged yesterday
+	Fasting        *FastingData     `json:"fasting,omitempty"` // time since the last logged meal
+	Tags           []string         `json:"tags,omitempty"`    // life events covering today (travel, illness, deload)
+	Weather        *WeatherData     `json:"weather,omitempty"` // today's forecast for weather.location
+	AirQuality     *AirQualityData  `json:"air_quality,omitempty"`
+	Pollen         *PollenData      `json:"pollen,omitempty"`
+	Work           *WorkData        `json:"work,omitempty"` // pull requests from GitHub
+	Mail           *MailData        `json:"mail,omitempty"` // important unread Gmail
+	Slack          *SlackData       `json:"slack,omitempty"`
+	News           *NewsData        `json:"news,omitempty"` // headlines from news.feeds
+	Markets        *MarketsData     `json:"markets,omitempty"`
+	OnCall         *OnCallData      `json:"oncall,omitempty"` // today's PagerDuty or Opsgenie schedules
+	Calendar       CalendarData     `json:"calendar"`
+	Travel         *TravelData      `json:"travel,omitempty"`      // flights and hotel stays from today to trips.lookahead_days ahead
+	JetLagPlan     *JetLagPlan      `json:"jetlag_plan,omitempty"` // around a flight crossing JetLagMinShiftHours or more
+	Meds           MedsData         `json:"meds"`
+	Tasks          TasksData        `json:"tasks"`                 // overdue work outside meds
+	Focus          *FocusData       `json:"focus,omitempty"`       // priority tasks from focus.filter
+	Supplements    []SupplementDose `json:"supplements,omitempty"` // today's schedule from the supplements config
+	Training       TrainingData     `json:"training"`
+	Classification Classification   `json:"classification"`
+	Narrative      string           `json:"narrative,omitempty"` // LLM-written summary (--narrate)
+	Changes        []Change         `json:"changes,omitempty"`   // vs yesterday's stored briefing (--compare)
+	Alerts         []Alert          `json:"alerts,omitempty"`
+	Errors         []string         `json:"errors,omitempty"`
 }
 
 type TrainingData struct {
@@ -32,6 +60,9 @@ type TrainingData struct {
 	DaysSinceLast   int             `json:"days_since_last"`
 	RecentWorkouts  []WorkoutSummary `json:"recent_workouts,omitempty"`
 	WeeklyCount     int             `json:"weekly_count"`
+	VO2Max          *VO2MaxData     `json:"vo2_max,omitempty"` // Apple cardio fitness estimate
+	HRZones         *HRZones        `json:"hr_zones,omitempty"` // last 7 days of Apple workouts
+	Zone2           *Zone2Progress  `json:"zone2,omitempty"` // HRZones.Z2 against the weekly target
 }
 
 type WorkoutSummary struct {
@@ -43,52 +74,99 @@ type WorkoutSummary struct {
 }
 
 type SleepData struct {
-	TotalHours    *float64 `json:"total_hours,omitempty"`
-	DeepHours     *float64 `json:"deep_hours,omitempty"`
-	REMHours      *float64 `json:"rem_hours,omitempty"`
-	CoreHours     *float64 `json:"core_hours,omitempty"`
-	DataDate      string   `json:"data_date,omitempty"`
-	IsCurrentDay  bool     `json:"is_current_day"`
-	DataAvailable bool     `json:"data_available"`
+	TotalHours        *float64 `json:"total_hours,omitempty"`
+	DeepHours         *float64 `json:"deep_hours,omitempty"`
+	REMHours          *float64 `json:"rem_hours,omitempty"`
+	CoreHours         *float64 `json:"core_hours,omitempty"`
+	SleepDebtHours    *float64 `json:"sleep_debt_hours,omitempty"`     // shortfall vs the sleep target over the last SleepDebtNights
+	SleepDebt14dHours *float64 `json:"sleep_debt_14d_hours,omitempty"` // same over SleepDebtLongNights
+	BedtimeSDMinutes  *float64 `json:"bedtime_sd_min,omitempty"`       // spread of sleep start over SleepConsistencyNights
+	WakeSDMinutes     *float64 `json:"wake_sd_min,omitempty"`
+	ConsistencyScore  *int     `json:"consistency_score,omitempty"` // 0-100, 100 is the same times every night
+	Consistency       string   `json:"consistency,omitempty"`       // CONSISTENT or IRREGULAR
+	DataDate          string   `json:"data_date,omitempty"`
+	IsCurrentDay      bool     `json:"is_current_day"`
+	DataAvailable     bool     `json:"data_available"`
 }
 
 type VitalsData struct {
-	RestingHR       *float64 `json:"resting_hr_bpm,omitempty"`
-	HRV             *float64 `json:"hrv_ms,omitempty"`
-	SpO2            *float64 `json:"spo2_pct,omitempty"`
-	RespiratoryRate *float64 `json:"respiratory_rate,omitempty"`
+	RestingHR               *float64           `json:"resting_hr_bpm,omitempty"`
+	RestingHRBaseline       *float64           `json:"resting_hr_baseline_bpm,omitempty"` // RHRBaselineDays average before today
+	RestingHRDelta          *float64           `json:"resting_hr_delta,omitempty"`        // today minus the baseline
+	HRV                     *float64           `json:"hrv_ms,omitempty"`
+	HRVBaseline             *float64           `json:"hrv_baseline_ms,omitempty"`    // HRVBaselineDays average before today
+	HRVBaseline7d           *float64           `json:"hrv_baseline_7d_ms,omitempty"` // HRVShortBaselineDays average before today
+	HRVDeviation            *float64           `json:"hrv_deviation_pct,omitempty"`  // today vs the baseline, see hrvDeviation
+	SpO2                    *float64           `json:"spo2_pct,omitempty"`
+	RespiratoryRate         *float64           `json:"respiratory_rate,omitempty"`
+	RespiratoryRateBaseline *float64           `json:"respiratory_rate_baseline,omitempty"` // RespiratoryBaselineDays average before today
+	RespiratoryRateDelta    *float64           `json:"respiratory_rate_delta,omitempty"`
+	Temperature             *float64           `json:"temperature_c,omitempty"`          // sleeping wrist temperature, else body temperature
+	TemperatureBaseline     *float64           `json:"temperature_baseline_c,omitempty"` // TemperatureBaselineDays average of the same metric
+	TemperatureDeviation    *float64           `json:"temperature_deviation_c,omitempty"`
+	BloodPressure           *BloodPressureData `json:"blood_pressure,omitempty"`
+	Flags                   []string           `json:"flags,omitempty"` // e.g. RHR_ELEVATED
 }
 
 type CalendarData struct {
+	AllDayEvents    []AllDayEvent   `json:"all_day_events"`
 	MorningEvents   []CalendarEvent `json:"morning_events"`
 	AfternoonEvents []CalendarEvent `json:"afternoon_events"`
 	MorningCount    int             `json:"morning_count"`
 	FirstEventTime  string          `json:"first_event_time,omitempty"`
+	Conflicts       []EventConflict `json:"conflicts,omitempty"` // overlapping pairs across all accounts
+	BestWorkoutSlot *WorkoutSlot    `json:"best_workout_slot,omitempty"`
+	FocusHours      *float64        `json:"focus_hours_available,omitempty"` // free time in work hours, unset without calendars
+	LeaveBy         string          `json:"leave_by,omitempty"`              // for the first in-person event, with travel.home set
+	CommuteTo       string          `json:"commute_to,omitempty"`            // that event's summary
+	CommuteMinutes  *int            `json:"commute_minutes,omitempty"`
+	CommuteMode     string          `json:"commute_mode,omitempty"` // driving or transit
 }
 
 type CalendarEvent struct {
-	Time    string `json:"time"`
-	Summary string `json:"summary"`
-	Source  string `json:"source"` // personal or work
+	Time           string             `json:"time"`
+	EndTime        string             `json:"end_time,omitempty"` // 24:00 when it runs past midnight
+	Summary        string             `json:"summary"`
+	Source         string             `json:"source"` // personal or work
+	Location       string             `json:"location,omitempty"`
+	Response       string             `json:"response,omitempty"`       // your RSVP: accepted, tentative, declined, needsAction
+	Attendees      []CalendarAttendee `json:"attendees,omitempty"`      // everyone else invited
+	AttendeeCount  int                `json:"attendee_count,omitempty"` // people invited, you included
+	OneOnOne       bool               `json:"one_on_one,omitempty"`     // you and one other person
+	JoinURL        string             `json:"join_url,omitempty"`       // video call link
+	TravelMinutes  *int               `json:"travel_minutes,omitempty"` // from home, for in-person events
+	LeaveBy        string             `json:"leave_by,omitempty"`
+	EarlyDeparture bool               `json:"early_departure,omitempty"` // leave_by is before travel.warn_before
+	Ongoing        bool               `json:"ongoing,omitempty"`         // started before today; time is then 00:00
+	Until          string             `json:"until,omitempty"`           // last day of an ongoing event running past today
 }
 
 type MedsData struct {
-	DueToday  []MedTask `json:"due_today"`
-	Overdue   []MedTask `json:"overdue"`
-	Completed []MedTask `json:"completed"`
+	DueToday       []MedTask     `json:"due_today"`
+	Overdue        []MedTask     `json:"overdue"`
+	Completed      []MedTask     `json:"completed"`
+	MissedRecently []MedMisses   `json:"missed_recently,omitempty"` // missed repeatedly over the last week
+	Inventory      []MedStock    `json:"inventory,omitempty"`       // configured stock less logged doses
+	Cycles         []CycleStatus `json:"cycles,omitempty"`
+	Categories     MedCategories `json:"categories,omitempty"` // the tasks above by medication, supplement, injection
 }
 
 type MedTask struct {
-	Name    string `json:"name"`
-	DueTime string `json:"due_time,omitempty"`
-	DueDate string `json:"due_date"`
+	Name     string `json:"name"`
+	Category string `json:"category"` // medication, supplement, injection
+	DueTime  string `json:"due_time,omitempty"`
+	DueDate  string `json:"due_date"`
 }
 
 type Classification struct {
-	SleepQuality   string `json:"sleep_quality"`    // GOOD, OK, POOR, UNKNOWN
-	MorningLoad    string `json:"morning_load"`     // CLEAR, LIGHT, PACKED
-	RecoveryStatus string `json:"recovery_status"`  // GOOD, OK, POOR, UNKNOWN (based on HRV)
-	Recommendation string `json:"recommendation"`   // Brief advice
+	SleepQuality   string   `json:"sleep_quality"`             // GOOD, OK, POOR, UNKNOWN
+	MorningLoad    string   `json:"morning_load"`              // CLEAR, LIGHT, PACKED
+	TaskPressure   string   `json:"task_pressure"`             // OK, TASK_DEBT
+	RecoveryStatus string   `json:"recovery_status"`           // GOOD, OK, POOR, UNKNOWN (based on HRV)
+	ReadinessScore *int     `json:"readiness_score,omitempty"` // 0-100 from sleep, HRV, and check-in
+	IllnessRisk    string   `json:"illness_risk,omitempty"`    // LOW, ELEVATED, HIGH; empty without baselines
+	IllnessSignals []string `json:"illness_signals,omitempty"` // vitals off baseline, e.g. "resting HR"
+	Recommendation string   `json:"recommendation"`            // Brief advice
 }
 
 // Health ingest summary structure
@@ -102,18 +180,25 @@ type HealthSummary struct {
 
 // Todoist response structure
 type TodoistResponse struct {
-	Results []struct {
-		Content     string   `json:"content"`
-		Labels      []string `json:"labels"`
-		IsCompleted bool     `json:"is_completed"`
-		Due         *struct {
-			Date     string `json:"date"`
-			DateTime string `json:"datetime"`
-		} `json:"due"`
-	} `json:"results"`
-}
-
-// Calendar response from gog
+	Results []TodoistTask `json:"results"`
+}
+
+type TodoistTask struct {
+	ID          string      `json:"id"`
+	Content     string      `json:"content"`
+	Labels      []string    `json:"labels"`
+	ProjectID   string      `json:"project_id"`
+	Priority    int         `json:"priority"` // 4 is p1 (urgent), 1 is p4
+	IsCompleted bool        `json:"is_completed"`
+	Due         *TodoistDue `json:"due"`
+}
+
+type TodoistDue struct {
+	Date     string `json:"date"`
+	DateTime string `json:"datetime"`
+}
+
+// Calendar response from gog; events have the Calendar API's event shape
 type GogCalendarResponse struct {
 	Events []GogCalendarEvent `json:"events"`
 }
@@ -122,59 +207,362 @@ type GogCalendarEvent struct {
 	Start struct {
 		DateTime string `json:"dateTime"`
 		Date     string `json:"date"`
+		TimeZone string `json:"timeZone"` // IANA zone the event was booked in
 	} `json:"start"`
-	Summary string `json:"summary"`
+	End struct {
+		DateTime string `json:"dateTime"`
+		Date     string `json:"date"`     // all-day events: the day after the last
+		TimeZone string `json:"timeZone"` // a flight's arrival zone
+	} `json:"end"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Location    string `json:"location"`
+	Attendees   []struct {
+		Email          string `json:"email"`
+		DisplayName    string `json:"displayName"`
+		ResponseStatus string `json:"responseStatus"`
+		Self           bool   `json:"self"`
+		Resource       bool   `json:"resource"` // a room or equipment
+	} `json:"attendees"`
+	HangoutLink    string `json:"hangoutLink"`
+	ConferenceData *struct {
+		EntryPoints []struct {
+			EntryPointType string `json:"entryPointType"` // video, phone, sip, more
+			URI            string `json:"uri"`
+		} `json:"entryPoints"`
+	} `json:"conferenceData"`
 }
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "log":
+			runSubcommand(RunLogCommand, os.Args[2:])
+			return
+		case "serve":
+			runSubcommand(RunServeCommand, os.Args[2:])
+			return
+		case "backup":
+			runSubcommand(RunBackupCommand, os.Args[2:])
+			return
+		case "restore":
+			runSubcommand(RunRestoreCommand, os.Args[2:])
+			return
+		case "backfill":
+			runSubcommand(RunBackfillCommand, os.Args[2:])
+			return
+		case "check":
+			runSubcommand(RunCheckCommand, os.Args[2:])
+			return
+		case "prune":
+			runSubcommand(RunPruneCommand, os.Args[2:])
+			return
+		case "keygen":
+			runSubcommand(RunKeygenCommand, os.Args[2:])
+			return
+		case "decrypt":
+			runSubcommand(RunDecryptCommand, os.Args[2:])
+			return
+		case "report":
+			runSubcommand(RunReportCommand, os.Args[2:])
+			return
+		case "checkin":
+			runSubcommand(RunCheckinCommand, os.Args[2:])
+			return
+		case "tag":
+			runSubcommand(RunTagCommand, os.Args[2:])
+			return
+		case "daemon":
+			runSubcommand(RunDaemonCommand, os.Args[2:])
+			return
+		case "history":
+			runSubcommand(RunHistoryCommand, os.Args[2:])
+			return
+		case "done":
+			runSubcommand(RunDoneCommand, os.Args[2:])
+			return
+		}
+	}
+
 	// Parse CLI flags
-	morningFlag := flag.Bool("morning", false, "Run morning briefing (default)")
+	modeFlag := flag.String("mode", "", "Briefing mode: auto, morning, midday, evening, weekly (default auto)")
+	morningFlag := flag.Bool("morning", false, "Run morning briefing")
 	eveningFlag := flag.Bool("evening", false, "Run evening wrap-up")
+	weeklyFlag := flag.Bool("weekly", false, "Run weekly report")
+	formatFlag := flag.String("format", "json", "Output format: json, text, markdown, prompt, eink, card")
+	sizeFlag := flag.String("size", fmt.Sprintf("%dx%d", EinkDefaultWidth, EinkDefaultHeight), "Canvas size for --format=eink or card")
+	redactFlag := flag.Bool("redact", false, "Hash event summaries, med names, and emails in the output")
+	answersFlag := flag.String("answers", "", "Morning questionnaire answers: SLEEP_FEEL,SORENESS,MOTIVATION (1-10 each)")
+	compareFlag := flag.Bool("compare", false, "Add changes since yesterday's stored briefing (morning and evening)")
+	narrateFlag := flag.Bool("narrate", false, "Add an LLM-written narrative to the morning briefing (see narrate config)")
+	deliverFlag := flag.String("deliver", "", "Also send the briefing to these channels (comma-separated: email, telegram, slack, discord, ntfy, pushover)")
+	tzFlag := flag.String("tz", "", "Timezone for dates and times, e.g. Europe/Berlin (overrides the timezone config)")
 	flag.Parse()
 
-	mode, err := ParseMode(*morningFlag, *eveningFlag)
+	mode, err := ParseMode(*modeFlag, *morningFlag, *eveningFlag, *weeklyFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := applyTimezone(*tzFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	mode, err = ResolveMode(mode, time.Now(), cfg.AutoMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts, err := ParseRunOptions(mode, *formatFlag, *sizeFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Redact = *redactFlag
+	if *answersFlag != "" {
+		if mode != "morning" {
+			fmt.Fprintf(os.Stderr, "Error: --answers is only supported for the morning briefing\n")
+			os.Exit(1)
+		}
+		if _, err := ParseAnswers(*answersFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	opts.Answers = *answersFlag
+	if *narrateFlag && mode != "morning" {
+		fmt.Fprintf(os.Stderr, "Error: --narrate is only supported for the morning briefing\n")
+		os.Exit(1)
+	}
+	opts.Narrate = *narrateFlag
+	if *compareFlag && mode != "morning" && mode != "evening" {
+		fmt.Fprintf(os.Stderr, "Error: --compare is only supported for the morning and evening briefings\n")
+		os.Exit(1)
+	}
+	opts.Compare = *compareFlag
+	opts.Deliver, err = ParseDeliver(*deliverFlag)
+	if err == nil && len(opts.Deliver) > 0 && mode == "weekly" {
+		err = errors.New("--deliver is not supported for the weekly report")
+	}
+	for _, ch := range opts.Deliver {
+		if err == nil {
+			err = validateChannel(cfg.Delivery, ch)
+		}
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if mode == "evening" {
-		RunEveningBriefing()
+	switch mode {
+	case "midday":
+		RunMiddayBriefing(cfg, opts)
+		return
+	case "evening":
+		RunEveningBriefing(cfg, opts)
+		return
+	case "weekly":
+		RunWeeklyReport(opts)
 		return
 	}
 
-	// Default: morning briefing
-	RunMorningBriefing()
+	RunMorningBriefing(cfg, opts)
 }
 
-func RunMorningBriefing() {
-	now := time.Now()
+// FormatNone prints nothing; the daemon uses it so runs only dispatch
+const FormatNone = "none"
+
+// RunOptions holds output settings from CLI flags
+type RunOptions struct {
+	Format  string   // json, text, markdown, prompt, eink, card
+	Width   int      // eink/card canvas width
+	Height  int      // eink/card canvas height
+	Redact  bool     // hash personal strings in printed output
+	Answers string   // morning questionnaire answers (--answers)
+	Narrate bool     // add an LLM narrative (--narrate)
+	Compare bool     // add changes since yesterday (--compare)
+	Deliver []string // delivery channels (--deliver), overriding delivery.modes
+}
+
+// ParseRunOptions validates the output format for the selected mode
+func ParseRunOptions(mode, format, size string) (RunOptions, error) {
+	opts := RunOptions{Format: format}
+	switch format {
+	case "json":
+		return opts, nil
+	case "eink":
+		if mode != "morning" {
+			return opts, fmt.Errorf("--format=eink is only supported for the morning briefing")
+		}
+		w, h, err := ParseSize(size)
+		if err != nil {
+			return opts, err
+		}
+		opts.Width, opts.Height = w, h
+		return opts, nil
+	case "markdown", "text", "prompt":
+		if mode == "weekly" {
+			return opts, fmt.Errorf("--format=%s is not supported for the weekly report", format)
+		}
+		return opts, nil
+	case "card":
+		if mode != "weekly" {
+			return opts, fmt.Errorf("--format=card is only supported for the weekly report")
+		}
+		w, h, err := ParseSize(size)
+		if err != nil {
+			return opts, err
+		}
+		opts.Width, opts.Height = w, h
+		return opts, nil
+	default:
+		return opts, fmt.Errorf("unknown format %q (expected: json, text, markdown, prompt, eink, card)", format)
+	}
+}
+
+// printPrompt prints --format=prompt output, exiting on template errors
+func printPrompt(cfg PromptConfig, mode string, briefing any) {
+	prompt, err := RenderPrompt(cfg, mode, briefing)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(prompt)
+}
+
+// runSubcommand executes a subcommand and exits non-zero on error
+func runSubcommand(run func(args []string) error, args []string) {
+	if err := run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// RunMorningBriefing prints the morning briefing and publishes it if MQTT is configured
+func RunMorningBriefing(cfg Config, opts RunOptions) {
+	// Unattended daemon runs never prompt
+	if opts.Format != FormatNone {
+		if err := runQuestionnaire(cfg.Questionnaire, opts.Answers, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	briefing := BuildMorningBriefing(time.Now())
+
+	alerts, errs := EvaluateRules(cfg.Rules, morningRuleVars(briefing))
+	briefing.Alerts = alerts
+	for _, err := range errs {
+		briefing.Errors = append(briefing.Errors, fmt.Sprintf("rule error: %v", err))
+	}
+
+	if opts.Compare {
+		changes, err := compareFromHistory("morning", briefing.TargetDate, briefing, morningCompareMetrics)
+		if err != nil {
+			briefing.Errors = append(briefing.Errors, fmt.Sprintf("compare error: %v", err))
+		}
+		briefing.Changes = changes
+	}
+
+	if opts.Narrate || cfg.Narrate.Enabled {
+		narrative, err := Narrate(cfg.Narrate, briefing)
+		if err != nil {
+			briefing.Errors = append(briefing.Errors, fmt.Sprintf("narrate error: %v", err))
+		}
+		briefing.Narrative = narrative
+	}
+
+	// Redaction only affects what is printed; MQTT and webhooks stay private
+	printed := briefing
+	if opts.Redact {
+		printed = RedactMorningBriefing(briefing)
+	}
+
+	switch opts.Format {
+	case FormatNone:
+	case "eink":
+		if err := RenderEinkPNG(os.Stdout, printed, opts.Width, opts.Height); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "markdown":
+		fmt.Print(MorningMarkdown(printed))
+	case "text":
+		fmt.Print(MorningText(printed, textStyle{color: useColor(os.Stdout)}))
+	case "prompt":
+		printPrompt(cfg.Prompt, "morning", printed)
+	default:
+		output, _ := json.MarshalIndent(printed, "", "  ")
+		fmt.Println(string(output))
+	}
+
+	if cfg.MQTT.Broker != "" {
+		payload, _ := json.Marshal(briefing)
+		if err := PublishMQTT(cfg.MQTT, morningMQTTMessages(cfg.MQTT.TopicPrefix, briefing, payload)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	for _, err := range notifyWebhooks(cfg.Webhooks, getClassificationStatePath(), briefing) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, err := range dispatchAlerts(cfg, "morning", briefing.TargetDate, briefing.GeneratedAt, briefing.Alerts) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, err := range deliver(cfg.Delivery, deliveryChannelsFor(cfg.Delivery, "morning", opts.Deliver), morningDelivery(printed)) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if err := recordHistory(morningHistoryRecord(briefing, HistorySourceLive)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if cfg.DerivedMetrics.Enabled {
+		if err := writeDerivedMetrics(briefing.TargetDate, morningDerivedMetrics(briefing)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if cfg.Archive.Enabled {
+		if err := archiveBriefing(cfg.Archive, "morning", briefing.TargetDate, briefing, MorningMarkdown(briefing)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// BuildMorningBriefing collects all sources and classifies the morning
+func BuildMorningBriefing(now time.Time) MorningBriefing {
 	today := now.Format("2006-01-02")
-	
+
 	briefing := MorningBriefing{
 		GeneratedAt: now.Format(time.RFC3339),
 		TargetDate:  today,
 	}
 
-	// 1. Get health data (from health-ingest CLI and SQLite)
-	getHealthData(&briefing, today)
-	getHealthDataFromSQLite(&briefing, today)
-
-	// 2. Get calendar data (both personal and work)
-	getCalendarData(&briefing, today)
-
-	// 3. Get meds from Todoist
-	getMedsData(&briefing, today)
+	// Health, calendar, meds, and training from the enabled sources
+	fetchSources(context.Background(), &briefing, settings.Sources)
 
-	// 4. Get training data from Hevy
-	getTrainingData(&briefing, today)
+	// Repeatedly missed meds and remaining stock from the stored evening results
+	getMedCallouts(&briefing, today)
+	briefing.Meds.Cycles = cyclesFor(settings.Cycles, today)
+	briefing.Supplements = supplementScheduleFor(settings.Supplements, briefing.Calendar)
 
-	// 5. Classify and recommend
+	// Classify and recommend
 	classify(&briefing)
 
-	// Output JSON
-	output, _ := json.MarshalIndent(briefing, "", "  ")
-	fmt.Println(string(output))
+	return briefing
 }
 
 func getHealthData(b *MorningBriefing, today string) {
@@ -185,6 +573,7 @@ func getHealthData(b *MorningBriefing, today string) {
 		b.Errors = append(b.Errors, fmt.Sprintf("health-ingest error: %v", err))
 		return
 	}
+	dumpRaw("health-ingest-summary", output)
 
 	var summary HealthSummary
 	if err := json.Unmarshal(output, &summary); err != nil {
@@ -200,7 +589,7 @@ func getHealthData(b *MorningBriefing, today string) {
 		
 		// Parse timestamp and check if it's from today or yesterday (valid for last night's sleep)
 		// Sleep data timestamped at midnight belongs to the previous night
-		if strings.Contains(sleep.Timestamp, today) || strings.Contains(sleep.Timestamp, yesterday(today)) {
+		if ts := localTimestamp(sleep.Timestamp); strings.Contains(ts, today) || strings.Contains(ts, yesterday(today)) {
 			b.Sleep.IsCurrentDay = true
 		}
 	}
@@ -226,55 +615,82 @@ func getHealthData(b *MorningBriefing, today string) {
 }
 
 func getCalendarData(b *MorningBriefing, today string) {
-	// Personal calendar
-	getCalendarEvents(b, today, "jai@govindani.com", "personal")
-	
-	// Work calendar
-	getCalendarEvents(b, today, "jai.g@ewa-services.com", "work")
-
-	b.Calendar.MorningCount = len(b.Calendar.MorningEvents)
-	
-	if len(b.Calendar.MorningEvents) > 0 {
-		b.Calendar.FirstEventTime = b.Calendar.MorningEvents[0].Time
+	var timed []CalendarEvent
+	var trips []Trip
+	for _, c := range settings.Calendars {
+		accountTimed, accountTrips := getCalendarEvents(b, today, c)
+		timed = append(timed, accountTimed...)
+		trips = append(trips, accountTrips...)
+	}
+	sortTrips(trips)
+	b.Travel = upcomingTrips(trips, today, settings.Trips.LookaheadDays)
+	b.JetLagPlan = jetLagPlan(trips, today, settings.Supplements)
+	b.Calendar.BestWorkoutSlot = bestWorkoutSlot(timed, settings.TrainingHours)
+	if len(settings.Calendars) > 0 {
+		hours := focusHours(timed, settings.WorkHours)
+		b.Calendar.FocusHours = &hours
+	}
+	planTravel(b, settings.Travel)
+
+	b.Calendar.Conflicts = eventConflicts(append(append([]CalendarEvent{}, b.Calendar.MorningEvents...), b.Calendar.AfternoonEvents...))
+
+	// Ongoing events are the backdrop to the day, not part of the morning's load
+	for _, e := range b.Calendar.MorningEvents {
+		if e.Ongoing {
+			continue
+		}
+		if b.Calendar.MorningCount == 0 {
+			b.Calendar.FirstEventTime = e.Time
+		}
+		b.Calendar.MorningCount++
 	}
 }
 
-func getCalendarEvents(b *MorningBriefing, today, account, source string) {
-	cmd := exec.Command("gog", "calendar", "events", "--account="+account, "--json")
-	output, err := cmd.Output()
+// getCalendarEvents files the account's events for today into the briefing and
+// returns all today's timed events, evening included, and its trips from
+// JetLagMaxAfterDays ago to the end of the lookahead window
+func getCalendarEvents(b *MorningBriefing, today string, account CalendarAccount) ([]CalendarEvent, []Trip) {
+	source := account.Source
+	from := addDays(today, -JetLagMaxAfterDays)
+	events, err := calendarEvents(account, from, JetLagMaxAfterDays+1+settings.Trips.LookaheadDays, "calendar-"+source)
 	if err != nil {
 		b.Errors = append(b.Errors, fmt.Sprintf("calendar error (%s): %v", source, err))
-		return
+		return nil, nil
 	}
 
-	var resp GogCalendarResponse
-	if err := json.Unmarshal(output, &resp); err != nil {
-		b.Errors = append(b.Errors, fmt.Sprintf("calendar JSON parse error (%s): %v", source, err))
-		return
-	}
+	var timed []CalendarEvent
+	var trips []Trip
+
+	for _, e := range events {
+		if trip, ok := detectTrip(e, source, settings.Trips); ok {
+			trips = append(trips, trip)
+		}
 
-	for _, e := range resp.Events {
 		startTime := e.Start.DateTime
 		if startTime == "" {
-			continue // Skip all-day events
+			if event, ok := allDayEvent(e, today, source, settings.AllDay); ok {
+				b.Calendar.AllDayEvents = append(b.Calendar.AllDayEvents, event)
+			}
+			continue
 		}
 		
-		if !strings.HasPrefix(startTime, today) {
-			continue // Not today
-		}
-
-		// Parse time
-		t, err := time.Parse(time.RFC3339, startTime)
+		t, err := localTime(startTime)
 		if err != nil {
 			continue
 		}
+		ongoing, until := false, ""
+		if t.Format("2006-01-02") != today {
+			var ok bool
+			if t, until, ok = ongoingFrom(e, t, today); !ok {
+				continue // Not today
+			}
+			ongoing = true
+		}
 
 		hour := t.Hour()
-		event := CalendarEvent{
-			Time:    t.Format("15:04"),
-			Summary: e.Summary,
-			Source:  source,
-		}
+		event := calendarEvent(e, t, source)
+		event.Ongoing, event.Until = ongoing, until
+		timed = append(timed, event)
 
 		if hour < 12 {
 			b.Calendar.MorningEvents = append(b.Calendar.MorningEvents, event)
@@ -282,53 +698,47 @@ func getCalendarEvents(b *MorningBriefing, today, account, source string) {
 			b.Calendar.AfternoonEvents = append(b.Calendar.AfternoonEvents, event)
 		}
 	}
+	return timed, trips
 }
 
 func getMedsData(b *MorningBriefing, today string) {
-	cmd := exec.Command("td", "today", "--json")
-	output, err := cmd.Output()
+	tasks, err := todoistToday(today)
 	if err != nil {
 		b.Errors = append(b.Errors, fmt.Sprintf("todoist error: %v", err))
 		return
 	}
 
-	var resp TodoistResponse
-	if err := json.Unmarshal(output, &resp); err != nil {
-		b.Errors = append(b.Errors, fmt.Sprintf("todoist JSON parse error: %v", err))
-		return
-	}
-
-	for _, task := range resp.Results {
-		// Check if it's a med task
-		isMed := false
-		for _, label := range task.Labels {
-			if label == "💊Meds" || label == "💉" {
-				isMed = true
-				break
-			}
+	for _, task := range tasks {
+		if isAlcoholTask(task.Labels) && task.Due != nil && task.Due.Date == yesterday(today) {
+			noteAlcohol(b, 0, AlcoholSourceTodoist)
 		}
-		if !isMed {
+		if !isMedTask(task) {
 			continue
 		}
 
-		med := MedTask{Name: task.Content}
+		med := MedTask{Name: task.Content, Category: medCategory(settings.MedCategories, task)}
 		if task.Due != nil {
 			med.DueDate = task.Due.Date
 			if task.Due.DateTime != "" {
-				if t, err := time.Parse(time.RFC3339, task.Due.DateTime); err == nil {
+				if t, err := localTime(task.Due.DateTime); err == nil {
 					med.DueTime = t.Format("15:04")
 				}
 			}
 		}
 
+		bucket := b.Meds.Categories.bucket(med.Category)
 		if task.IsCompleted {
 			b.Meds.Completed = append(b.Meds.Completed, med)
+			bucket.Completed = append(bucket.Completed, med.Name)
 		} else if task.Due != nil && task.Due.Date < today {
 			b.Meds.Overdue = append(b.Meds.Overdue, med)
+			bucket.Overdue = append(bucket.Overdue, med.Name)
 		} else {
 			b.Meds.DueToday = append(b.Meds.DueToday, med)
+			bucket.Due = append(bucket.Due, med.Name)
 		}
 	}
+	b.Tasks = overdueTasksFor(tasks, today)
 }
 
 // Hevy workout response
@@ -342,17 +752,26 @@ type HevyWorkout struct {
 	} `json:"exercises"`
 }
 
-func getTrainingData(b *MorningBriefing, today string) {
+// fetchHevyWorkouts returns the most recent Hevy workouts, newest first
+func fetchHevyWorkouts() ([]HevyWorkout, error) {
 	cmd := exec.Command("mcporter", "call", "hevy.get-workouts", "page=1", "pageSize=10")
 	output, err := cmd.Output()
 	if err != nil {
-		b.Errors = append(b.Errors, fmt.Sprintf("hevy error: %v", err))
-		return
+		return nil, fmt.Errorf("hevy error: %v", err)
 	}
+	dumpRaw("hevy-workouts", output)
 
 	var workouts []HevyWorkout
 	if err := json.Unmarshal(output, &workouts); err != nil {
-		b.Errors = append(b.Errors, fmt.Sprintf("hevy JSON parse error: %v", err))
+		return nil, fmt.Errorf("hevy JSON parse error: %v", err)
+	}
+	return workouts, nil
+}
+
+func getTrainingData(b *MorningBriefing, today string) {
+	workouts, err := fetchHevyWorkouts()
+	if err != nil {
+		b.Errors = append(b.Errors, err.Error())
 		return
 	}
 
@@ -366,7 +785,7 @@ func getTrainingData(b *MorningBriefing, today string) {
 	weeklyCount := 0
 
 	for i, w := range workouts {
-		workoutDate, err := time.Parse(time.RFC3339, w.StartTime)
+		workoutDate, err := localTime(w.StartTime)
 		if err != nil {
 			continue
 		}
@@ -426,29 +845,27 @@ func classify(b *MorningBriefing) {
 	}
 
 	// Recovery status based on HRV
-	if b.Vitals.HRV == nil {
-		b.Classification.RecoveryStatus = "UNKNOWN"
-	} else {
-		hrv := *b.Vitals.HRV
-		switch {
-		case hrv <= 20:
-			b.Classification.RecoveryStatus = "POOR"
-		case hrv < 40:
-			b.Classification.RecoveryStatus = "OK"
-		default:
-			b.Classification.RecoveryStatus = "GOOD"
-		}
-	}
+	b.Classification.RecoveryStatus = alcoholRecovery(classifyRecovery(b.Vitals), b.Alcohol)
 
-	// Morning load
-	count := b.Calendar.MorningCount
-	switch {
-	case count == 0:
-		b.Classification.MorningLoad = "CLEAR"
-	case count <= 2:
-		b.Classification.MorningLoad = "LIGHT"
-	default:
-		b.Classification.MorningLoad = "PACKED"
+	// Morning load, from the events weighted by size when they're known
+	weight := float64(b.Calendar.MorningCount)
+	if len(b.Calendar.MorningEvents) > 0 {
+		weight = morningWeight(b.Calendar.MorningEvents)
+	}
+	b.Classification.MorningLoad = loadFor(weight)
+	b.Classification.TaskPressure = classifyTaskPressure(b.Tasks)
+	b.Classification.MorningLoad = taskDebtLoad(b.Classification.MorningLoad, b.Classification.TaskPressure)
+	b.Classification.MorningLoad = allDayLoad(b.Classification.MorningLoad, b.Calendar.AllDayEvents)
+	b.Classification.MorningLoad = onCallLoad(b.Classification.MorningLoad, b.OnCall)
+
+	// Readiness score (0-100)
+	b.Classification.ReadinessScore = CalculateReadinessScore(b.Sleep, b.Vitals, b.Checkin)
+
+	// Illness early warning overrides every other recommendation
+	b.Classification.IllnessRisk, b.Classification.IllnessSignals = classifyIllness(b.Vitals)
+	if b.Classification.IllnessRisk == IllnessRiskHigh {
+		b.Classification.Recommendation = illnessRecommendation(b.Classification.IllnessSignals) + pollenCauseNote(b.Pollen, b.Vitals, b.Classification.SleepQuality)
+		return
 	}
 
 	// Generate recommendation (now includes recovery status)
@@ -462,7 +879,12 @@ func classify(b *MorningBriefing) {
 			b.Classification.Recommendation = "Poor sleep + poor recovery (low HRV). Take it very easy today, prioritize rest and recovery."
 		} else {
 			b.Classification.Recommendation = fmt.Sprintf("HRV is low (%.0fms) indicating poor recovery. Consider lighter activity today.", *b.Vitals.HRV)
+			if dev := hrvDeviation(b.Vitals); dev != nil {
+				b.Classification.Recommendation = fmt.Sprintf("HRV is %.0f%% below your baseline (%.0fms) indicating poor recovery. Consider lighter activity today.", -*dev, *b.Vitals.HRV)
+			}
 		}
+		b.Classification.Recommendation += alcoholNote(b.Alcohol, recovery) + sleepDebtNote(b.Sleep) + morningLightNote(b.Weather, sleep) + vo2MaxNote(b.Training.VO2Max) +
+			indoorNote(b.AirQuality, b.Pollen) + pollenCauseNote(b.Pollen, b.Vitals, sleep) + onCallNote(b.OnCall, b.TargetDate) + conflictNote(b.Calendar.Conflicts) + travelNote(b.Calendar) + jetLagNote(b.JetLagPlan, b.TargetDate)
 		return
 	}
 
@@ -480,6 +902,40 @@ func classify(b *MorningBriefing) {
 	default:
 		b.Classification.Recommendation = "Sleep data unavailable. Check energy levels and adjust accordingly."
 	}
+	b.Classification.Recommendation += alcoholNote(b.Alcohol, recovery) + sleepDebtNote(b.Sleep) + morningLightNote(b.Weather, sleep) + vo2MaxNote(b.Training.VO2Max) + zoneNote(b.Training.HRZones, settings.User.Zone2TargetMin) + outdoorNote(b.Weather, b.AirQuality, b.Pollen) + pollenCauseNote(b.Pollen, b.Vitals, sleep) +
+		taskDebtNote(b.Tasks, b.Classification.TaskPressure) + slackNote(b.Slack, settings.Slack) + focusTimeNote(b.Calendar.FocusHours, settings.WorkHours) + onCallNote(b.OnCall, b.TargetDate) + conflictNote(b.Calendar.Conflicts) + travelNote(b.Calendar) + jetLagNote(b.JetLagPlan, b.TargetDate)
+}
+
+// isMedTask reports whether a Todoist task is a med/protocol task: it carries
+// one of med_labels, a label or its content matches one of med_patterns, or
+// it belongs to one of med_projects
+func isMedTask(task TodoistTask) bool {
+	if slices.ContainsFunc(task.Labels, func(l string) bool { return slices.Contains(settings.MedLabels, l) }) {
+		return true
+	}
+	if task.ProjectID != "" && slices.Contains(settings.MedProjects, task.ProjectID) {
+		return true
+	}
+	for _, p := range settings.MedPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			continue // rejected by validateMedMatching
+		}
+		if re.MatchString(task.Content) || slices.ContainsFunc(task.Labels, re.MatchString) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMedMatching checks that every med pattern is a valid regular expression
+func validateMedMatching(cfg Config) error {
+	for i, p := range cfg.MedPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("med_patterns[%d]: %w", i, err)
+		}
+	}
+	return nil
 }
 
 func yesterday(today string) string {
@@ -487,21 +943,152 @@ func yesterday(today string) string {
 	return t.AddDate(0, 0, -1).Format("2006-01-02")
 }
 
-// SQLite database path
+// SQLite database path (health_db in the config overrides)
 func getHealthDBPath() string {
+	if settings.HealthDB != "" {
+		return settings.HealthDB
+	}
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".health-ingest", "health.db")
 }
 
-// Query average HRV for a given date from SQLite
+// Query average HRV for a given date from SQLite (preferred source only)
 func queryAverageHRV(db *sql.DB, date string) (*float64, error) {
+	rank, rankArgs := sourceRankSQL("heart_rate_variability")
+	query := `
+		WITH ranked AS (
+			SELECT value, ` + rank + ` AS r FROM metrics
+			WHERE metric_name = 'heart_rate_variability'
+			AND local_ts(timestamp) LIKE ? || '%'
+		)
+		SELECT AVG(value) FROM ranked WHERE r = (SELECT MIN(r) FROM ranked)
+	`
+	var avg sql.NullFloat64
+	err := db.QueryRow(query, append(rankArgs, date)...).Scan(&avg)
+	if err != nil {
+		return nil, err
+	}
+	if !avg.Valid {
+		return nil, nil
+	}
+	return &avg.Float64, nil
+}
+
+// HRVBaselineDays is the look-back window for the personal HRV baseline
+const HRVBaselineDays = 30
+
+// HRVShortBaselineDays is the look-back window for the recent HRV trend
+const HRVShortBaselineDays = 7
+
+// Recovery thresholds as % deviation of today's HRV from the personal baseline
+const (
+	HRVDeviationOKPct   = -10.0 // at or below this recovery is OK
+	HRVDeviationPoorPct = -25.0 // at or below this recovery is POOR
+)
+
+// hrvDeviation is today's HRV as a % deviation from the 30-day baseline, or
+// from the 7-day one while there isn't 30 days of history
+func hrvDeviation(v VitalsData) *float64 {
+	baseline := v.HRVBaseline
+	if baseline == nil {
+		baseline = v.HRVBaseline7d
+	}
+	if v.HRV == nil || baseline == nil || *baseline <= 0 {
+		return nil
+	}
+	dev := math.Round((*v.HRV / *baseline - 1)*1000) / 10
+	return &dev
+}
+
+// classifyRecovery rates HRV against the personal baseline. A 7-day average
+// sagging below the 30-day one caps recovery at OK even when today looks
+// normal. Without any baseline yet it falls back to absolute thresholds.
+func classifyRecovery(v VitalsData) string {
+	if v.HRV == nil {
+		return "UNKNOWN"
+	}
+	dev := hrvDeviation(v)
+	if dev == nil {
+		switch hrv := *v.HRV; {
+		case hrv <= 20:
+			return "POOR"
+		case hrv < 40:
+			return "OK"
+		}
+		return "GOOD"
+	}
+	switch {
+	case *dev <= HRVDeviationPoorPct:
+		return "POOR"
+	case *dev <= HRVDeviationOKPct:
+		return "OK"
+	}
+	if v.HRVBaseline != nil && v.HRVBaseline7d != nil && *v.HRVBaseline > 0 &&
+		(*v.HRVBaseline7d / *v.HRVBaseline - 1)*100 <= HRVDeviationOKPct {
+		return "OK"
+	}
+	return "GOOD"
+}
+
+// queryHRVBaseline averages daily HRV means (preferred source per day) over the days before date,
+// skipping tagged days (illness, travel, deload)
+func queryHRVBaseline(db *sql.DB, date string, days int, exclude []LifeTag) (*float64, error) {
+	return queryDailyBaseline(db, "heart_rate_variability", date, days, exclude)
+}
+
+// RHRBaselineDays is the look-back window for the resting heart rate baseline
+const RHRBaselineDays = 14
+
+// RHRElevatedBPM is how far above baseline resting HR must be to flag it
+const RHRElevatedBPM = 5.0
+
+// FlagRHRElevated marks a resting HR more than RHRElevatedBPM above baseline
+const FlagRHRElevated = "RHR_ELEVATED"
+
+// rhrStatus is the resting HR row's status in the rendered formats
+func rhrStatus(v VitalsData) string {
+	if slices.Contains(v.Flags, FlagRHRElevated) {
+		return "ELEVATED"
+	}
+	return ""
+}
+
+// rhrDeltaValue formats the delta as " (+6 vs baseline)", empty without a baseline
+func rhrDeltaValue(v VitalsData) string {
+	if v.RestingHRDelta == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.0f vs baseline)", *v.RestingHRDelta)
+}
+
+// queryRHRBaseline averages daily resting HR over the days before date, skipping tagged days
+func queryRHRBaseline(db *sql.DB, date string, days int, exclude []LifeTag) (*float64, error) {
+	return queryDailyBaseline(db, "resting_heart_rate", date, days, exclude)
+}
+
+// queryDailyBaseline averages a metric's daily means (preferred source per day)
+// over the days before date, skipping tagged days
+func queryDailyBaseline(db *sql.DB, metric, date string, days int, exclude []LifeTag) (*float64, error) {
+	rank, rankArgs := sourceRankSQL(metric)
+	skip, skipArgs := tagExclusionSQL(exclude)
 	query := `
-		SELECT AVG(value) FROM metrics 
-		WHERE metric_name = 'heart_rate_variability' 
-		AND timestamp LIKE ? || '%'
+		SELECT AVG(daily) FROM (
+			SELECT AVG(value) AS daily FROM (
+				SELECT substr(local_ts(timestamp), 1, 10) AS day, value, r,
+					MIN(r) OVER (PARTITION BY substr(local_ts(timestamp), 1, 10)) AS best
+				FROM (
+					SELECT timestamp, value, ` + rank + ` AS r FROM metrics
+					WHERE metric_name = ?
+					AND local_ts(timestamp) >= ? AND local_ts(timestamp) < ?` + skip + `
+				)
+			)
+			WHERE r = best
+			GROUP BY day
+		)
 	`
+	args := append(append(rankArgs, metric, addDays(date, -days), date), skipArgs...)
 	var avg sql.NullFloat64
-	err := db.QueryRow(query, date).Scan(&avg)
+	err := db.QueryRow(query, args...).Scan(&avg)
 	if err != nil {
 		return nil, err
 	}
@@ -511,12 +1098,25 @@ func queryAverageHRV(db *sql.DB, date string) (*float64, error) {
 	return &avg.Float64, nil
 }
 
+// applyRHRBaseline sets the resting HR baseline and delta, flagging RHR_ELEVATED
+func applyRHRBaseline(v *VitalsData, baseline *float64) {
+	v.RestingHRBaseline = baseline
+	if v.RestingHR == nil || baseline == nil {
+		return
+	}
+	delta := math.Round((*v.RestingHR-*baseline)*10) / 10
+	v.RestingHRDelta = &delta
+	if delta > RHRElevatedBPM && !slices.Contains(v.Flags, FlagRHRElevated) {
+		v.Flags = append(v.Flags, FlagRHRElevated)
+	}
+}
+
 // Query sleep stages for a given date from SQLite
 func querySleepStages(db *sql.DB, date string) (deep, rem, core *float64, err error) {
 	query := `
 		SELECT metric_name, value FROM metrics 
 		WHERE metric_name IN ('sleep_deep', 'sleep_rem', 'sleep_core')
-		AND timestamp LIKE ? || '%'
+		AND local_ts(timestamp) LIKE ? || '%'
 	`
 	rows, err := db.Query(query, date)
 	if err != nil {
@@ -545,25 +1145,7 @@ func querySleepStages(db *sql.DB, date string) (deep, rem, core *float64, err er
 
 // Query latest respiratory rate for a given date from SQLite
 func queryLatestRespiratoryRate(db *sql.DB, date string) (*float64, error) {
-	query := `
-		SELECT value FROM metrics 
-		WHERE metric_name = 'respiratory_rate' 
-		AND timestamp LIKE ? || '%'
-		ORDER BY timestamp DESC 
-		LIMIT 1
-	`
-	var value sql.NullFloat64
-	err := db.QueryRow(query, date).Scan(&value)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
-	}
-	if !value.Valid {
-		return nil, nil
-	}
-	return &value.Float64, nil
+	return queryLatestValue(db, "respiratory_rate", date)
 }
 
 // Fetch additional metrics from SQLite database
@@ -576,6 +1158,18 @@ func getHealthDataFromSQLite(b *MorningBriefing, today string) {
 	}
 	defer db.Close()
 
+	tags, err := loadTags(addDays(today, -HRVBaselineDays), today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("tags error: %v", err))
+	}
+	fillMorningHealthFromDB(b, db, today, tags)
+}
+
+// fillMorningHealthFromDB reads HRV, sleep stages, and respiratory rate for a date
+// Tagged days are left out of the HRV baseline; tags covering today are listed.
+func fillMorningHealthFromDB(b *MorningBriefing, db *sql.DB, today string, tags []LifeTag) {
+	b.Tags = tagsOn(tags, today)
+
 	// Get average HRV for today
 	avgHRV, err := queryAverageHRV(db, today)
 	if err != nil {
@@ -584,6 +1178,27 @@ func getHealthDataFromSQLite(b *MorningBriefing, today string) {
 		b.Vitals.HRV = avgHRV
 	}
 
+	baseline, err := queryHRVBaseline(db, today, HRVBaselineDays, tags)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("HRV baseline query error: %v", err))
+	} else {
+		b.Vitals.HRVBaseline = baseline
+	}
+	recent, err := queryHRVBaseline(db, today, HRVShortBaselineDays, tags)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("HRV baseline query error: %v", err))
+	} else {
+		b.Vitals.HRVBaseline7d = recent
+	}
+	b.Vitals.HRVDeviation = hrvDeviation(b.Vitals)
+
+	rhrBaseline, err := queryRHRBaseline(db, today, RHRBaselineDays, tags)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("resting HR baseline query error: %v", err))
+	} else {
+		applyRHRBaseline(&b.Vitals, rhrBaseline)
+	}
+
 	// Get sleep stages
 	deep, rem, core, err := querySleepStages(db, today)
 	if err != nil {
@@ -600,6 +1215,27 @@ func getHealthDataFromSQLite(b *MorningBriefing, today string) {
 		}
 	}
 
+	target := settings.User.SleepTargetHours
+	debt, err := querySleepDebt(db, today, SleepDebtNights, target)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("sleep debt query error: %v", err))
+	} else {
+		b.Sleep.SleepDebtHours = debt
+	}
+	debt, err = querySleepDebt(db, today, SleepDebtLongNights, target)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("sleep debt query error: %v", err))
+	} else {
+		b.Sleep.SleepDebt14dHours = debt
+	}
+
+	sessions, err := querySleepSessions(db, today, SleepConsistencyNights)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("sleep consistency query error: %v", err))
+	} else {
+		applySleepConsistency(&b.Sleep, sessions)
+	}
+
 	// Get latest respiratory rate
 	rr, err := queryLatestRespiratoryRate(db, today)
 	if err != nil {
@@ -607,4 +1243,81 @@ func getHealthDataFromSQLite(b *MorningBriefing, today string) {
 	} else if rr != nil {
 		b.Vitals.RespiratoryRate = rr
 	}
+	rrBaseline, err := queryDailyBaseline(db, "respiratory_rate", today, RespiratoryBaselineDays, tags)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("respiratory rate baseline query error: %v", err))
+	} else {
+		applyRespiratoryBaseline(&b.Vitals, rrBaseline)
+	}
+
+	temp, tempBaseline, err := queryTemperature(db, today, TemperatureBaselineDays, tags)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("temperature query error: %v", err))
+	} else {
+		applyTemperature(&b.Vitals, temp, tempBaseline)
+	}
+
+	readings, err := queryBPReadings(db, addDays(today, -(BPRecentDays+BPPriorDays-1)), today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("blood pressure query error: %v", err))
+	} else {
+		applyBloodPressure(&b.Vitals, bloodPressureFor(readings, today, settings.BloodPressure))
+	}
+
+	vo2, err := queryDailyValues(db, "vo2_max", addDays(today, -(VO2MaxTrendDays-1)), today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("VO2max query error: %v", err))
+	} else {
+		b.Training.VO2Max = vo2MaxFor(vo2)
+	}
+
+	zones, err := queryHRZones(db, today, settings.User.MaxHeartRate())
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("HR zone query error: %v", err))
+	} else {
+		b.Training.HRZones = zones
+		b.Training.Zone2 = zone2ProgressFor(zones, settings.User.Zone2TargetMin)
+	}
+
+	steps, err := queryMorningSteps(db, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("steps query error: %v", err))
+	} else {
+		b.Steps = steps
+		if steps != nil && sedentary(steps.Avg7d, settings.User.SedentarySteps) {
+			b.Vitals.Flags = append(b.Vitals.Flags, FlagSedentary)
+		}
+	}
+
+	glucose, err := queryGlucose(db, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("glucose query error: %v", err))
+	} else {
+		b.Glucose = glucose
+	}
+
+	drinks, err := queryDayTotal(db, "number_of_alcoholic_beverages", yesterday(today))
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("alcohol query error: %v", err))
+	} else if drinks > 0 {
+		noteAlcohol(b, drinks, AlcoholSourceHealth)
+	}
+
+	// Time since the last meal, as of when the briefing was generated
+	if now, err := time.Parse(time.RFC3339, b.GeneratedAt); err == nil {
+		fasting, err := queryFasting(db, now)
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("fasting query error: %v", err))
+		} else {
+			b.Fasting = fasting
+		}
+	}
+
+	// Get today's subjective check-in
+	checkin, err := queryCheckin(db, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("check-in query error: %v", err))
+	} else {
+		b.Checkin = checkin
+	}
 }
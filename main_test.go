@@ -5,12 +5,22 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
 	_ "modernc.org/sqlite"
 )
 
+// TestMain runs the tests in +07:00, the offset the health.db fixtures are
+// written at, so their dates are local dates as they are for a real user
+func TestMain(m *testing.M) {
+	if err := applyTimezone("Asia/Bangkok"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
 // Test the yesterday() helper function
 func TestYesterday(t *testing.T) {
 	tests := []struct {
@@ -183,6 +193,7 @@ func TestTodoistResponseParsing(t *testing.T) {
 			{
 				"content": "Take vitamin D",
 				"labels": ["💊Meds"],
+				"project_id": "2203306141",
 				"is_completed": false,
 				"due": {"date": "2024-01-15", "datetime": "2024-01-15T08:00:00+07:00"}
 			},
@@ -222,6 +233,9 @@ func TestTodoistResponseParsing(t *testing.T) {
 	if !found {
 		t.Errorf("First task should have 💊Meds label")
 	}
+	if resp.Results[0].ProjectID != "2203306141" {
+		t.Errorf("ProjectID = %q, want 2203306141", resp.Results[0].ProjectID)
+	}
 
 	// Check second task is completed
 	if !resp.Results[1].IsCompleted {
@@ -370,6 +384,10 @@ func ptr(f float64) *float64 {
 	return &f
 }
 
+func intPtr(i int) *int {
+	return &i
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > 0 && len(substr) > 0 && findSubstring(s, substr)))
@@ -384,6 +402,35 @@ func findSubstring(s, substr string) bool {
 	return false
 }
 
+// newTestMetricsDB creates a temp health.db with the health-ingest metrics schema
+func newTestMetricsDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "health.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE metrics (
+			id INTEGER PRIMARY KEY,
+			file_date DATE,
+			metric_name TEXT,
+			timestamp TEXT,
+			value REAL,
+			unit TEXT,
+			source TEXT,
+			raw_json TEXT,
+			updated_at TEXT DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(metric_name, timestamp)
+		)
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
 // ==================== NEW TESTS FOR SQLITE METRICS ====================
 
 // Test VitalsData includes new fields (HRV, RespiratoryRate)
@@ -679,3 +726,200 @@ func TestMorningBriefingJSONOutputWithNewFields(t *testing.T) {
 		}
 	}
 }
+
+// Test output format validation
+func TestParseRunOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		format      string
+		size        string
+		expectError bool
+	}{
+		{"json morning", "morning", "json", "", false},
+		{"json evening", "evening", "json", "", false},
+		{"eink morning", "morning", "eink", "800x480", false},
+		{"eink evening unsupported", "evening", "eink", "800x480", true},
+		{"eink bad size", "morning", "eink", "big", true},
+		{"card weekly", "weekly", "card", "800x480", false},
+		{"card morning unsupported", "morning", "card", "800x480", true},
+		{"markdown morning", "morning", "markdown", "", false},
+		{"markdown midday", "midday", "markdown", "", false},
+		{"markdown evening", "evening", "markdown", "", false},
+		{"markdown weekly unsupported", "weekly", "markdown", "", true},
+		{"text morning", "morning", "text", "", false},
+		{"text evening", "evening", "text", "", false},
+		{"text weekly unsupported", "weekly", "text", "", true},
+		{"prompt midday", "midday", "prompt", "", false},
+		{"prompt weekly unsupported", "weekly", "prompt", "", true},
+		{"none is internal", "morning", "none", "", true},
+		{"unknown format", "morning", "xml", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseRunOptions(tt.mode, tt.format, tt.size)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseRunOptions() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRunOptions() unexpected error: %v", err)
+			}
+			if opts.Format != tt.format {
+				t.Errorf("Format = %q, want %q", opts.Format, tt.format)
+			}
+		})
+	}
+}
+
+// ==================== HRV BASELINE TESTS ====================
+
+func TestClassifyRecoveryAgainstBaseline(t *testing.T) {
+	tests := []struct {
+		name     string
+		vitals   VitalsData
+		expected string
+	}{
+		{"near baseline", VitalsData{HRV: ptr(48), HRVBaseline: ptr(50)}, "GOOD"},
+		{"low in absolute terms but normal for you", VitalsData{HRV: ptr(30), HRVBaseline: ptr(31)}, "GOOD"},
+		{"10% below baseline", VitalsData{HRV: ptr(45), HRVBaseline: ptr(50)}, "OK"},
+		{"high in absolute terms but well below baseline", VitalsData{HRV: ptr(70), HRVBaseline: ptr(100)}, "POOR"},
+		{"7-day trend sagging", VitalsData{HRV: ptr(50), HRVBaseline: ptr(50), HRVBaseline7d: ptr(44)}, "OK"},
+		{"only a 7-day baseline", VitalsData{HRV: ptr(36), HRVBaseline7d: ptr(50)}, "POOR"},
+		{"no baseline yet", VitalsData{HRV: ptr(35)}, "OK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyRecovery(tt.vitals); got != tt.expected {
+				t.Errorf("classifyRecovery() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHRVDeviation(t *testing.T) {
+	if dev := hrvDeviation(VitalsData{HRV: ptr(40), HRVBaseline: ptr(50), HRVBaseline7d: ptr(40)}); dev == nil || *dev != -20 {
+		t.Errorf("hrvDeviation() = %v, want -20 against the 30-day baseline", dev)
+	}
+	if dev := hrvDeviation(VitalsData{HRV: ptr(40)}); dev != nil {
+		t.Errorf("hrvDeviation() = %v, want nil without a baseline", *dev)
+	}
+
+	b := &MorningBriefing{
+		Sleep:  SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true},
+		Vitals: VitalsData{HRV: ptr(60), HRVBaseline: ptr(90)},
+	}
+	classify(b)
+	if want := "HRV is 33% below your baseline (60ms)"; !contains(b.Classification.Recommendation, want) {
+		t.Errorf("Recommendation = %q, want to contain %q", b.Classification.Recommendation, want)
+	}
+}
+
+func TestRHRBaseline(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit) VALUES
+		('resting_heart_rate', '2023-12-31 06:00:00 +0700', 80, 'bpm'),
+		('resting_heart_rate', '2024-01-10 06:00:00 +0700', 52, 'bpm'),
+		('resting_heart_rate', '2024-01-14 06:00:00 +0700', 54, 'bpm'),
+		('resting_heart_rate', '2024-01-15 06:00:00 +0700', 60, 'bpm')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2023-12-31 is outside the 14-day window and today is excluded
+	b := &MorningBriefing{TargetDate: "2024-01-15", Vitals: VitalsData{RestingHR: ptr(60)}}
+	fillMorningHealthFromDB(b, db, "2024-01-15", nil)
+	if b.Vitals.RestingHRBaseline == nil || *b.Vitals.RestingHRBaseline != 53 {
+		t.Fatalf("RestingHRBaseline = %v, want 53", b.Vitals.RestingHRBaseline)
+	}
+	if b.Vitals.RestingHRDelta == nil || *b.Vitals.RestingHRDelta != 7 {
+		t.Errorf("RestingHRDelta = %v, want 7", b.Vitals.RestingHRDelta)
+	}
+	if !slices.Contains(b.Vitals.Flags, FlagRHRElevated) {
+		t.Errorf("Flags = %v, want %s", b.Vitals.Flags, FlagRHRElevated)
+	}
+}
+
+func TestApplyRHRBaseline(t *testing.T) {
+	tests := []struct {
+		name      string
+		rhr       *float64
+		baseline  *float64
+		wantDelta *float64
+		elevated  bool
+	}{
+		{"at baseline", ptr(55), ptr(55), ptr(0), false},
+		{"exactly 5 above", ptr(60), ptr(55), ptr(5), false},
+		{"more than 5 above", ptr(60.4), ptr(55), ptr(5.4), true},
+		{"below baseline", ptr(50), ptr(55), ptr(-5), false},
+		{"no baseline", ptr(60), nil, nil, false},
+		{"no reading today", nil, ptr(55), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := VitalsData{RestingHR: tt.rhr}
+			applyRHRBaseline(&v, tt.baseline)
+			if (v.RestingHRDelta == nil) != (tt.wantDelta == nil) || (v.RestingHRDelta != nil && *v.RestingHRDelta != *tt.wantDelta) {
+				t.Errorf("RestingHRDelta = %v, want %v", v.RestingHRDelta, tt.wantDelta)
+			}
+			if got := slices.Contains(v.Flags, FlagRHRElevated); got != tt.elevated {
+				t.Errorf("elevated = %v, want %v (flags %v)", got, tt.elevated, v.Flags)
+			}
+		})
+	}
+}
+
+func TestQueryHRVBaseline(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit) VALUES
+		('heart_rate_variability', '2024-01-13 06:00:00 +0700', 40, 'ms'),
+		('heart_rate_variability', '2024-01-13 07:00:00 +0700', 60, 'ms'),
+		('heart_rate_variability', '2024-01-14 06:00:00 +0700', 70, 'ms'),
+		('heart_rate_variability', '2024-01-15 06:00:00 +0700', 10, 'ms'),
+		('heart_rate_variability', '2023-11-01 06:00:00 +0700', 99, 'ms')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Daily means are 50 and 70; today and days outside the window are excluded
+	baseline, err := queryHRVBaseline(db, "2024-01-15", 30, nil)
+	if err != nil {
+		t.Fatalf("queryHRVBaseline() error: %v", err)
+	}
+	if baseline == nil || *baseline != 60 {
+		t.Errorf("baseline = %v, want 60", baseline)
+	}
+
+	// The 7-day window only reaches back to 2024-01-08
+	baseline, err = queryHRVBaseline(db, "2024-01-15", HRVShortBaselineDays, nil)
+	if err != nil {
+		t.Fatalf("queryHRVBaseline() error: %v", err)
+	}
+	if baseline == nil || *baseline != 60 {
+		t.Errorf("7-day baseline = %v, want 60", baseline)
+	}
+	baseline, err = queryHRVBaseline(db, "2024-01-21", HRVShortBaselineDays, nil)
+	if err != nil {
+		t.Fatalf("queryHRVBaseline() error: %v", err)
+	}
+	if baseline == nil || *baseline != 40 {
+		t.Errorf("7-day baseline = %v, want 40 (only 01-14 and 01-15)", baseline)
+	}
+
+	baseline, err = queryHRVBaseline(db, "2023-06-01", 30, nil)
+	if err != nil {
+		t.Fatalf("queryHRVBaseline() error: %v", err)
+	}
+	if baseline != nil {
+		t.Errorf("baseline = %v, want nil with no history", *baseline)
+	}
+}
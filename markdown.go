@@ -0,0 +1,427 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mdValue formats an optional metric for Markdown, "–" when missing
+func mdValue(v *float64, format string) string {
+	if v == nil {
+		return "–"
+	}
+	return fmt.Sprintf(format, *v)
+}
+
+// mdList writes a bulleted list, or "None" when empty
+func mdList(b *strings.Builder, items []string) {
+	if len(items) == 0 {
+		b.WriteString("- None\n")
+		return
+	}
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
+
+// mdTable writes a table with a header row; cells are escaped for pipes
+func mdTable(b *strings.Builder, header []string, rows [][]string) {
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, c := range cells {
+			fmt.Fprintf(b, " %s |", strings.ReplaceAll(c, "|", `\|`))
+		}
+		b.WriteString("\n")
+	}
+	writeRow(header)
+	b.WriteString("|")
+	for range header {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+	for _, r := range rows {
+		writeRow(r)
+	}
+}
+
+func medNames(tasks []MedTask) []string {
+	names := make([]string, len(tasks))
+	for i, m := range tasks {
+		names[i] = m.Name
+		if m.DueTime != "" {
+			names[i] += " (" + m.DueTime + ")"
+		}
+	}
+	return names
+}
+
+// medLines lists overdue meds first, marked, then the ones due today
+func medLines(m MedsData) []string {
+	var lines []string
+	for _, name := range medNames(m.Overdue) {
+		lines = append(lines, "**Overdue:** "+name)
+	}
+	lines = append(lines, medNames(m.DueToday)...)
+	for _, miss := range m.MissedRecently {
+		lines = append(lines, "**"+medMissesLine(miss)+"**")
+	}
+	for _, line := range reorderLines(m.Inventory) {
+		lines = append(lines, "**"+line+"**")
+	}
+	return append(lines, mdCycleLines(m.Cycles)...)
+}
+
+// mdCycleLines formats cycles, bolding those about to change phase
+func mdCycleLines(cycles []CycleStatus) []string {
+	var lines []string
+	for _, c := range cycles {
+		if c.changingSoon() {
+			lines = append(lines, "**"+cycleLine(c)+"**")
+		} else {
+			lines = append(lines, cycleLine(c))
+		}
+	}
+	return lines
+}
+
+func eventLines(events []CalendarEvent) []string {
+	lines := make([]string, len(events))
+	for i, e := range events {
+		lines[i] = e.Time + " " + eventSummary(e)
+	}
+	return lines
+}
+
+// mdChanges writes the moved metrics since yesterday, when --compare was used
+func mdChanges(b *strings.Builder, changes []Change) {
+	if changes == nil {
+		return
+	}
+	b.WriteString("\n## Since Yesterday\n\n")
+	mdList(b, changeSummaries(changes))
+}
+
+func mdAlertsAndErrors(b *strings.Builder, alerts []Alert, errs []string) {
+	if len(alerts) > 0 {
+		b.WriteString("\n## Alerts\n\n")
+		for _, a := range alerts {
+			fmt.Fprintf(b, "- [%s] %s\n", a.Severity, a.Rule)
+		}
+	}
+	if len(errs) > 0 {
+		b.WriteString("\n## Errors\n\n")
+		mdList(b, errs)
+	}
+}
+
+// MorningMarkdown renders the morning briefing for --format=markdown and the archive
+func MorningMarkdown(m MorningBriefing) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Morning %s\n\n", m.TargetDate)
+	if m.Classification.Recommendation != "" {
+		fmt.Fprintf(&b, "> %s\n\n", m.Classification.Recommendation)
+	}
+	if m.Narrative != "" {
+		fmt.Fprintf(&b, "%s\n\n", m.Narrative)
+	}
+	if len(m.Tags) > 0 {
+		fmt.Fprintf(&b, "Tagged: %s\n\n", strings.Join(m.Tags, ", "))
+	}
+
+	readiness := "–"
+	if m.Classification.ReadinessScore != nil {
+		readiness = fmt.Sprintf("%d", *m.Classification.ReadinessScore)
+	}
+	b.WriteString("## Recovery\n\n")
+	rows := [][]string{
+		{"Readiness", readiness, ""},
+		{"Sleep", fmt.Sprintf("%s h (deep %s, REM %s)", mdValue(m.Sleep.TotalHours, "%.1f"), mdValue(m.Sleep.DeepHours, "%.1f"), mdValue(m.Sleep.REMHours, "%.1f")), m.Classification.SleepQuality},
+	}
+	if m.Sleep.SleepDebtHours != nil {
+		rows = append(rows, []string{"Sleep debt", sleepDebtValue(m.Sleep), ""})
+	}
+	if m.Sleep.ConsistencyScore != nil {
+		rows = append(rows, []string{"Consistency", consistencyValue(m.Sleep), m.Sleep.Consistency})
+	}
+	rows = append(rows,
+		[]string{"HRV", fmt.Sprintf("%s ms (baseline %s)", mdValue(m.Vitals.HRV, "%.0f"), mdValue(m.Vitals.HRVBaseline, "%.0f")), m.Classification.RecoveryStatus},
+		[]string{"Resting HR", mdValue(m.Vitals.RestingHR, "%.0f bpm") + rhrDeltaValue(m.Vitals), rhrStatus(m.Vitals)},
+		[]string{"SpO2", mdValue(m.Vitals.SpO2, "%.0f%%"), ""},
+		[]string{"Respiratory rate", mdValue(m.Vitals.RespiratoryRate, "%.1f/min"), ""},
+	)
+	if m.Vitals.Temperature != nil {
+		rows = append(rows, []string{"Temperature", temperatureValue(m.Vitals), ""})
+	}
+	if bp := m.Vitals.BloodPressure; bp != nil {
+		rows = append(rows, []string{"Blood pressure", bpValue(bp), bp.Status})
+	}
+	if m.Alcohol != nil {
+		rows = append(rows, []string{"Alcohol", alcoholValue(m.Alcohol), ""})
+	}
+	if m.Fasting != nil {
+		rows = append(rows, []string{"Fasting", fastingValue(m.Fasting), ""})
+	}
+	if len(m.Classification.IllnessSignals) > 0 {
+		rows = append(rows, []string{"Illness risk", joinSignals(m.Classification.IllnessSignals), m.Classification.IllnessRisk})
+	}
+	mdTable(&b, []string{"Metric", "Value", "Status"}, rows)
+	if m.Checkin != nil {
+		fmt.Fprintf(&b, "\nCheck-in: mood %s, energy %s, soreness %s, sleep feel %s, motivation %s\n",
+			mdValue(m.Checkin.Mood, "%.0f"), mdValue(m.Checkin.Energy, "%.0f"), mdValue(m.Checkin.Soreness, "%.0f"),
+			mdValue(m.Checkin.SleepFeel, "%.0f"), mdValue(m.Checkin.Motivation, "%.0f"))
+	}
+
+	if m.Glucose != nil {
+		b.WriteString("\n## Glucose\n\n")
+		mdList(&b, glucoseLines(m.Glucose))
+	}
+
+	if m.Weather != nil {
+		fmt.Fprintf(&b, "\n## Weather\n\n%s\n", weatherLine(m.Weather))
+		if line := sunLine(m.Weather); line != "" {
+			fmt.Fprintf(&b, "\n%s\n", line)
+		}
+	}
+
+	if m.AirQuality != nil {
+		fmt.Fprintf(&b, "\n## Air Quality (%s)\n\n%s\n", m.AirQuality.Classification, airQualityLine(m.AirQuality))
+	}
+
+	if m.Pollen != nil {
+		fmt.Fprintf(&b, "\n## Pollen (%s)\n\n%s\n", m.Pollen.Level, pollenLine(m.Pollen))
+	}
+
+	if len(m.Calendar.AllDayEvents) > 0 {
+		b.WriteString("\n## All Day\n\n")
+		mdList(&b, allDayLines(m.Calendar.AllDayEvents))
+	}
+
+	if m.Travel != nil {
+		b.WriteString("\n## Travel\n\n")
+		mdList(&b, tripLines(m.Travel.Trips, m.TargetDate))
+	}
+
+	if p := m.JetLagPlan; p != nil {
+		fmt.Fprintf(&b, "\n## Jet Lag\n\n%s\n\n", jetLagHeadline(p, m.TargetDate))
+		mdList(&b, jetLagLines(p, m.TargetDate))
+	}
+
+	fmt.Fprintf(&b, "\n## Calendar (%s)\n\n", m.Classification.MorningLoad)
+	mdList(&b, eventLines(append(append([]CalendarEvent{}, m.Calendar.MorningEvents...), m.Calendar.AfternoonEvents...)))
+	if len(m.Calendar.Conflicts) > 0 {
+		b.WriteString("\nConflicts:\n\n")
+		mdList(&b, conflictLines(m.Calendar.Conflicts))
+	}
+	if line := focusTimeLine(m.Calendar.FocusHours, settings.WorkHours); line != "" {
+		fmt.Fprintf(&b, "\n%s\n", line)
+	}
+	if line := commuteLine(m.Calendar); line != "" {
+		fmt.Fprintf(&b, "\n%s\n", line)
+	}
+	if line := overdueTasksLine(m.Tasks); line != "" {
+		fmt.Fprintf(&b, "\n%s\n", line)
+	}
+
+	if lines := onCallLines(m.OnCall, m.TargetDate); len(lines) > 0 {
+		b.WriteString("\n## On Call\n\n")
+		mdList(&b, lines)
+	}
+
+	if m.Focus != nil {
+		b.WriteString("\n## Focus\n\n")
+		mdList(&b, focusLines(m.Focus))
+	}
+
+	if m.Work != nil {
+		fmt.Fprintf(&b, "\n## Work\n\n%s\n", workLine(m.Work))
+		if lines := reviewLines(m.Work); len(lines) > 0 {
+			b.WriteString("\nReview requested:\n\n")
+			mdList(&b, lines)
+		}
+		if lines := failingLines(m.Work); len(lines) > 0 {
+			b.WriteString("\nFailing checks:\n\n")
+			mdList(&b, lines)
+		}
+	}
+
+	if m.Slack != nil {
+		b.WriteString("\n## Slack\n\n")
+		mdList(&b, slackLines(m.Slack))
+	}
+
+	if m.Mail != nil {
+		fmt.Fprintf(&b, "\n## Mail\n\n%s\n", mailSummary(m.Mail))
+		if lines := mailLines(m.Mail); len(lines) > 0 {
+			b.WriteString("\n")
+			mdList(&b, lines)
+		}
+	}
+
+	if lines := headlineLines(m.News); len(lines) > 0 {
+		b.WriteString("\n## News\n\n")
+		mdList(&b, lines)
+	}
+
+	if lines := quoteLines(m.Markets); len(lines) > 0 {
+		b.WriteString("\n## Markets\n\n")
+		mdList(&b, lines)
+	}
+
+	b.WriteString("\n## Meds\n\n")
+	mdList(&b, medLines(m.Meds))
+
+	if len(m.Supplements) > 0 {
+		b.WriteString("\n## Supplements\n\n")
+		mdList(&b, supplementLines(m.Supplements))
+	}
+
+	b.WriteString("\n## Training\n\n")
+	if m.Training.LastWorkout != nil {
+		fmt.Fprintf(&b, "- Last workout: %s (%s), %d days ago\n", m.Training.LastWorkout.Title, m.Training.LastWorkout.Date, m.Training.DaysSinceLast)
+	}
+	fmt.Fprintf(&b, "- Workouts this week: %d\n", m.Training.WeeklyCount)
+	if m.Calendar.BestWorkoutSlot != nil {
+		fmt.Fprintf(&b, "- Best workout slot: %s\n", workoutSlotValue(m.Calendar.BestWorkoutSlot))
+	}
+	if m.Steps != nil {
+		fmt.Fprintf(&b, "- Steps yesterday: %s\n", stepsValue(m.Steps.Yesterday, m.Steps.Goal, m.Steps.Avg7d))
+	}
+	if m.Training.VO2Max != nil {
+		fmt.Fprintf(&b, "- VO2max: %s\n", vo2MaxValue(m.Training.VO2Max))
+	}
+	if m.Training.HRZones != nil {
+		fmt.Fprintf(&b, "- HR zones this week: %s\n", zonesValue(m.Training.HRZones))
+	}
+	if m.Training.Zone2 != nil {
+		fmt.Fprintf(&b, "- Zone 2 target: %s\n", zone2Value(m.Training.Zone2))
+	}
+
+	mdChanges(&b, m.Changes)
+	mdAlertsAndErrors(&b, m.Alerts, m.Errors)
+	return b.String()
+}
+
+// MiddayMarkdown renders the midday check-in for --format=markdown and the archive
+func MiddayMarkdown(m MiddayBriefing) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Midday %s\n\n", m.TargetDate)
+	b.WriteString("## Intake So Far\n\n")
+	mdTable(&b, []string{"", "So far", "Target"}, [][]string{
+		{"Eaten", fmt.Sprintf("%.0f kcal", m.Energy.ConsumedKcal), ""},
+		{"Active", fmt.Sprintf("%.0f kcal", m.Energy.ActiveKcal), ""},
+		{"Protein", fmt.Sprintf("%.0f g", m.Protein.ConsumedG), fmt.Sprintf("%d g", m.Protein.TargetG)},
+		{"Water", fmt.Sprintf("%.0f ml", m.Hydration.ConsumedMl), fmt.Sprintf("%d ml", m.Hydration.TargetMl)},
+		{"Steps", fmt.Sprintf("%d", m.Steps), ""},
+	})
+	fmt.Fprintf(&b, "\nRoom left today: %s\n", budgetValue(m.Energy.RemainingKcalToMaintenance, m.Energy.RemainingKcalToGoalDeficit))
+
+	b.WriteString("\n## Upcoming\n\n")
+	mdList(&b, eventLines(m.UpcomingEvents))
+
+	b.WriteString("\n## Meds\n\n")
+	mdList(&b, medLines(m.Meds))
+
+	mdAlertsAndErrors(&b, m.Alerts, m.Errors)
+	return b.String()
+}
+
+// EveningMarkdown renders the evening wrap-up for --format=markdown and the archive
+func EveningMarkdown(e EveningBriefing) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Evening %s\n\n", e.TargetDate)
+	b.WriteString("## Energy\n\n")
+	fmt.Fprintf(&b, "Balance: **%+d kcal** (%s)\n\n", e.Energy.DeficitOrSurplusKcal, e.Energy.Status)
+	mdTable(&b, []string{"", "Today", "Target"}, [][]string{
+		{"Eaten", fmt.Sprintf("%.0f kcal", e.Energy.ConsumedKcal), ""},
+		{"Burned", fmt.Sprintf("%.0f kcal", e.Energy.TotalBurnedKcal), fmt.Sprintf("%s %d + active %.0f", bmrLabel(e.Energy), e.Energy.BMRKcal, e.Energy.ActiveKcal)},
+		{"Protein", fmt.Sprintf("%.0f g", e.Protein.ConsumedG), fmt.Sprintf("%d g", e.Protein.TargetG)},
+		{"Water", fmt.Sprintf("%.0f ml", e.Hydration.ConsumedMl), fmt.Sprintf("%d ml", e.Hydration.TargetMl)},
+	})
+	fmt.Fprintf(&b, "\nRoom left today: %s\n", budgetValue(e.Energy.RemainingKcalToMaintenance, e.Energy.RemainingKcalToGoalDeficit))
+	if e.Eating != nil {
+		fmt.Fprintf(&b, "\nEating window %s\n", eatingWindowValue(e.Eating))
+	}
+	if g := e.Energy.Goal; g != nil {
+		fmt.Fprintf(&b, "\nGoal weight: %s", goalValue(g))
+		if g.Pace != "" {
+			fmt.Fprintf(&b, " **%s**", g.Pace)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(e.Meals) > 0 {
+		b.WriteString("\n## Meals\n\n")
+		for _, m := range e.Meals {
+			fmt.Fprintf(&b, "- %s\n", mealValue(m))
+		}
+		if e.LateKcalPct != nil && *e.LateKcalPct > 0 {
+			fmt.Fprintf(&b, "- %d%% of calories after %s\n", *e.LateKcalPct, MealLateAfter)
+		}
+	}
+
+	if len(e.Micronutrients) > 0 {
+		b.WriteString("\n## Micronutrients\n\n")
+		for _, m := range e.Micronutrients {
+			fmt.Fprintf(&b, "- %s: %s", m.Label, micronutrientValue(m))
+			if m.Status != MicroStatusNormal {
+				fmt.Fprintf(&b, " **%s**", m.Status)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n## Activity\n\n")
+	fmt.Fprintf(&b, "- Steps: %s, stand hours: %d\n", activityStepsValue(e.Activity), e.Activity.StandHours)
+	fmt.Fprintf(&b, "- Rings: %s\n", ringsValue(e.Rings))
+	if status := eveningStepsStatus(e.Activity); status != "" {
+		fmt.Fprintf(&b, "- %s: 7-day average under %d steps\n", status, settings.User.SedentarySteps)
+	}
+	if e.Activity.Workout != nil && e.Activity.Workout.Done {
+		fmt.Fprintf(&b, "- Workout: %s (%s)\n", e.Activity.Workout.Title, e.Activity.Workout.Duration)
+	} else {
+		b.WriteString("- Workout: none\n")
+	}
+
+	if e.Weight != nil {
+		b.WriteString("\n## Weight\n\n")
+		fmt.Fprintf(&b, "- Current: %.1f kg (%s)\n", e.Weight.CurrentKg, e.Weight.Date)
+		fmt.Fprintf(&b, "- Trend: %.1f kg\n", e.Weight.TrendKg)
+		if e.Weight.ChangePerWeekKg != nil {
+			fmt.Fprintf(&b, "- Change: %+.2f kg/week\n", *e.Weight.ChangePerWeekKg)
+		}
+		if c := e.Weight.Composition; c != nil {
+			if c.BodyFatPct != nil {
+				fmt.Fprintf(&b, "- Body fat: %s\n", bodyFatValue(c))
+			}
+			if c.LeanMassKg != nil {
+				fmt.Fprintf(&b, "- Lean mass: %s\n", leanMassValue(c))
+			}
+		}
+		if line := compositionLine(e.Weight); line != "" {
+			fmt.Fprintf(&b, "- Change from: %s\n", line)
+		}
+	}
+
+	b.WriteString("\n## Protocols\n\n")
+	b.WriteString("Completed:\n\n")
+	mdList(&b, e.Protocols.Completed)
+	b.WriteString("\nMissed:\n\n")
+	mdList(&b, e.Protocols.Missed)
+	if len(e.Protocols.Cycles) > 0 {
+		b.WriteString("\nCycles:\n\n")
+		mdList(&b, mdCycleLines(e.Protocols.Cycles))
+	}
+
+	b.WriteString("\n## Tomorrow\n\n")
+	if e.Tomorrow.FirstEvent != nil {
+		fmt.Fprintf(&b, "- First event: %s %s\n", e.Tomorrow.FirstEvent.Time, e.Tomorrow.FirstEvent.Summary)
+	}
+	fmt.Fprintf(&b, "- Meds due: %d\n", len(e.Tomorrow.MedsDue))
+	for _, line := range packingLines(e.Travel, e.TargetDate) {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+
+	mdChanges(&b, e.Changes)
+	mdAlertsAndErrors(&b, e.Alerts, e.Errors)
+	return b.String()
+}
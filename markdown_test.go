@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ==================== MARKDOWN RENDERER TESTS ====================
+
+func TestMdTable(t *testing.T) {
+	var b strings.Builder
+	mdTable(&b, []string{"Metric", "Value"}, [][]string{{"HRV", "45 ms"}, {"Note", "a|b"}})
+	want := "| Metric | Value |\n|---|---|\n| HRV | 45 ms |\n| Note | a\\|b |\n"
+	if b.String() != want {
+		t.Errorf("mdTable() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestMorningMarkdown(t *testing.T) {
+	b := MorningBriefing{
+		TargetDate: "2024-01-15",
+		Sleep:      SleepData{TotalHours: ptr(7.5), DeepHours: ptr(1.2)},
+		Vitals:     VitalsData{HRV: ptr(45), RestingHR: ptr(52)},
+		Tags:       []string{TagTravel},
+		Calendar:   CalendarData{MorningEvents: []CalendarEvent{{Time: "09:00", Summary: "Standup"}}},
+		Meds: MedsData{
+			Overdue:  []MedTask{{Name: "Vitamin D", DueTime: "08:00"}},
+			DueToday: []MedTask{{Name: "Magnesium"}},
+		},
+		Classification: Classification{
+			SleepQuality:   "GOOD",
+			RecoveryStatus: "OK",
+			MorningLoad:    "LIGHT",
+			ReadinessScore: intPtr(80),
+			Recommendation: "Go easy today.",
+		},
+		Alerts: []Alert{{Rule: "Low HRV", Severity: SeverityHigh}},
+	}
+	md := MorningMarkdown(b)
+	for _, want := range []string{
+		"# Morning 2024-01-15",
+		"> Go easy today.",
+		"Tagged: travel",
+		"| Metric | Value | Status |",
+		"| Readiness | 80 |  |",
+		"| Sleep | 7.5 h (deep 1.2, REM –) | GOOD |",
+		"| HRV | 45 ms (baseline –) | OK |",
+		"| Resting HR | 52 bpm |  |",
+		"## Calendar (LIGHT)",
+		"- 09:00 Standup",
+		"- **Overdue:** Vitamin D (08:00)\n- Magnesium",
+		"- [high] Low HRV",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Sleep debt") || strings.Contains(md, "Check-in") {
+		t.Error("markdown should omit missing sleep debt and check-in")
+	}
+}
+
+func TestEveningMarkdown(t *testing.T) {
+	b := EveningBriefing{
+		TargetDate: "2024-01-15",
+		Energy:     EnergyData{DeficitOrSurplusKcal: -350, Status: "deficit", BMRKcal: 1636, ActiveKcal: 400, TotalBurnedKcal: 2036},
+		Protein:    ProteinData{ConsumedG: 120, TargetG: 152},
+		Protocols:  ProtocolsData{Completed: []string{"Creatine"}},
+	}
+	md := EveningMarkdown(b)
+	for _, want := range []string{
+		"# Evening 2024-01-15",
+		"Balance: **-350 kcal** (deficit)",
+		"| Burned | 2036 kcal | BMR 1636 + active 400 |",
+		"| Protein | 120 g | 152 g |",
+		"- Creatine",
+		"- Workout: none",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "## Errors") {
+		t.Error("markdown should omit empty errors section")
+	}
+}
+
+func TestMiddayMarkdown(t *testing.T) {
+	b := MiddayBriefing{
+		TargetDate:     "2024-01-15",
+		Steps:          5400,
+		UpcomingEvents: []CalendarEvent{{Time: "15:00", Summary: "Design review"}},
+	}
+	md := MiddayMarkdown(b)
+	for _, want := range []string{"# Midday 2024-01-15", "| Steps | 5400 |  |", "- 15:00 Design review"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// marketsTimeout bounds each quote request
+const marketsTimeout = 15 * time.Second
+
+// MarketsData is the watchlist's move since the previous close
+type MarketsData struct {
+	Quotes []Quote `json:"quotes"`
+}
+
+// Quote is one symbol's latest price against its previous close
+type Quote struct {
+	Symbol    string  `json:"symbol"`
+	Price     float64 `json:"price"`
+	Currency  string  `json:"currency,omitempty"`
+	ChangePct float64 `json:"change_pct"` // since the previous close
+}
+
+// validateMarkets checks that an enabled watchlist isn't empty
+func validateMarkets(cfg MarketsConfig) error {
+	if cfg.Enabled && len(cfg.Watchlist) == 0 {
+		return errors.New("markets.watchlist must not be empty when markets is enabled")
+	}
+	return nil
+}
+
+// getMarketsData quotes markets.watchlist when markets.enabled; a symbol
+// that fails is reported and the rest kept
+func getMarketsData(b *MorningBriefing, today string) {
+	cfg := settings.Markets
+	if !cfg.Enabled {
+		return
+	}
+	client := &http.Client{Timeout: marketsTimeout}
+	d := &MarketsData{Quotes: []Quote{}}
+	for _, symbol := range cfg.Watchlist {
+		q, err := fetchQuote(client, cfg.URL, symbol)
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("markets error (%s): %v", symbol, err))
+			continue
+		}
+		d.Quotes = append(d.Quotes, q)
+	}
+	if len(d.Quotes) > 0 {
+		b.Markets = d
+	}
+}
+
+// fetchQuote reads a symbol's price and previous close from Yahoo Finance's
+// chart endpoint; crypto pairs are symbols like BTC-USD
+func fetchQuote(client *http.Client, base, symbol string) (Quote, error) {
+	var resp struct {
+		Chart struct {
+			Result []struct {
+				Meta struct {
+					Symbol             string  `json:"symbol"`
+					Currency           string  `json:"currency"`
+					RegularMarketPrice float64 `json:"regularMarketPrice"`
+					ChartPreviousClose float64 `json:"chartPreviousClose"`
+				} `json:"meta"`
+			} `json:"result"`
+			Error *struct {
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"chart"`
+	}
+	u := strings.TrimRight(base, "/") + "/v8/finance/chart/" + url.PathEscape(symbol) + "?" + url.Values{"range": {"1d"}, "interval": {"1d"}}.Encode()
+	if err := travelGet(client, u, &resp); err != nil {
+		return Quote{}, err
+	}
+	if resp.Chart.Error != nil {
+		return Quote{}, errors.New(resp.Chart.Error.Description)
+	}
+	if len(resp.Chart.Result) == 0 || resp.Chart.Result[0].Meta.ChartPreviousClose == 0 {
+		return Quote{}, errors.New("no quote")
+	}
+	meta := resp.Chart.Result[0].Meta
+	return Quote{
+		Symbol:    symbol,
+		Price:     meta.RegularMarketPrice,
+		Currency:  meta.Currency,
+		ChangePct: (meta.RegularMarketPrice/meta.ChartPreviousClose - 1) * 100,
+	}, nil
+}
+
+// quoteVar is a symbol as a rule variable name: lower case, with anything but
+// letters and digits as underscores and leading ones dropped (^GSPC is gspc,
+// BTC-USD is btc_usd)
+func quoteVar(symbol string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(symbol))
+	return strings.TrimLeft(name, "_")
+}
+
+// quoteLine formats a quote, e.g. "AAPL 189.12 USD +1.2%"
+func quoteLine(q Quote) string {
+	line := fmt.Sprintf("%s %.2f", q.Symbol, q.Price)
+	if q.Currency != "" {
+		line += " " + q.Currency
+	}
+	return line + fmt.Sprintf(" %+.1f%%", q.ChangePct)
+}
+
+// quoteLines lists the watchlist
+func quoteLines(d *MarketsData) []string {
+	if d == nil {
+		return nil
+	}
+	lines := make([]string, len(d.Quotes))
+	for i, q := range d.Quotes {
+		lines[i] = quoteLine(q)
+	}
+	return lines
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== MARKETS TESTS ====================
+
+// fakeQuotes serves Yahoo Finance charts for AAPL and BTC-USD
+func fakeQuotes(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("range") != "1d" {
+			t.Errorf("chart query = %s", r.URL.RawQuery)
+		}
+		switch r.URL.Path {
+		case "/v8/finance/chart/AAPL":
+			w.Write([]byte(`{"chart": {"result": [{"meta": {"symbol": "AAPL", "currency": "USD", "regularMarketPrice": 189.12, "chartPreviousClose": 186.88}}], "error": null}}`))
+		case "/v8/finance/chart/BTC-USD":
+			w.Write([]byte(`{"chart": {"result": [{"meta": {"symbol": "BTC-USD", "currency": "USD", "regularMarketPrice": 41000, "chartPreviousClose": 42000}}], "error": null}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetMarketsData(t *testing.T) {
+	ts := fakeQuotes(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Markets = MarketsConfig{Enabled: true, Watchlist: []string{"AAPL", "NOPE", "BTC-USD"}, URL: ts.URL + "/"}
+
+	b := &MorningBriefing{}
+	getMarketsData(b, "2024-01-15")
+	want := []string{"AAPL 189.12 USD +1.2%", "BTC-USD 41000.00 USD -2.4%"}
+	if got := quoteLines(b.Markets); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("quoteLines() = %q, want %q", got, want)
+	}
+	if len(b.Errors) != 1 || b.Errors[0] != "markets error (NOPE): status 404" {
+		t.Errorf("errors = %v", b.Errors)
+	}
+
+	// Left out by default
+	settings.Markets = DefaultConfig().Markets
+	settings.Markets.Watchlist = []string{"AAPL"}
+	b = &MorningBriefing{}
+	getMarketsData(b, "2024-01-15")
+	if b.Markets != nil || len(b.Errors) != 0 {
+		t.Errorf("markets when not enabled = %+v, %v", b.Markets, b.Errors)
+	}
+}
+
+func TestQuoteVar(t *testing.T) {
+	tests := map[string]string{"AAPL": "aapl", "^GSPC": "gspc", "BTC-USD": "btc_usd", "BRK.B": "brk_b"}
+	for symbol, want := range tests {
+		if got := quoteVar(symbol); got != want {
+			t.Errorf("quoteVar(%q) = %q, want %q", symbol, got, want)
+		}
+	}
+}
+
+func TestValidateMarkets(t *testing.T) {
+	if err := validateMarkets(DefaultConfig().Markets); err != nil {
+		t.Errorf("default: %v", err)
+	}
+	if err := validateMarkets(MarketsConfig{Enabled: true}); err == nil {
+		t.Error("expected an error for an empty watchlist")
+	}
+}
+
+func TestMarketsRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-01-15", Markets: &MarketsData{Quotes: []Quote{
+		{Symbol: "^GSPC", Price: 4783.83, ChangePct: -0.37},
+		{Symbol: "BTC-USD", Price: 42000, Currency: "USD", ChangePct: 2.04},
+	}}}
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Markets\n\n- ^GSPC 4783.83 -0.4%\n- BTC-USD 42000.00 USD +2.0%\n") {
+		t.Errorf("markdown missing markets:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Markets\n  ^GSPC 4783.83 -0.4%\n") {
+		t.Errorf("text missing markets:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Markets: ^GSPC 4783.83 -0.4%; BTC-USD 42000.00 USD +2.0%\n") {
+		t.Errorf("prompt missing markets (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["markets.gspc"] != -0.37 || vars["markets.btc_usd"] != 2.04 {
+		t.Errorf("markets vars = %v, %v", vars["markets.gspc"], vars["markets.btc_usd"])
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Meal grouping settings
+const (
+	MealGapMinutes = 30      // entries closer than this belong to the same meal
+	MealLateAfter  = "20:00" // meals starting at or after this count as late
+)
+
+// Meal is a cluster of dietary_energy and protein entries
+type Meal struct {
+	Time     string  `json:"time"` // HH:MM of the first entry
+	Kcal     float64 `json:"kcal"`
+	ProteinG float64 `json:"protein_g"`
+}
+
+// mealEntry is one dietary_energy or protein row
+type mealEntry struct {
+	At     string // YYYY-MM-DD HH:MM
+	Metric string
+	Value  float64
+}
+
+// queryMeals groups the day's dietary_energy and protein entries into meals,
+// oldest first
+func queryMeals(db *sql.DB, date string) ([]Meal, error) {
+	rows, err := db.Query(`
+		SELECT substr(local_ts(timestamp), 1, 16), metric_name, value
+		FROM metrics
+		WHERE metric_name IN ('dietary_energy', 'protein') AND value > 0
+		AND local_ts(timestamp) LIKE ? || '%'
+		ORDER BY local_ts(timestamp)
+	`, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []mealEntry
+	for rows.Next() {
+		var e mealEntry
+		if err := rows.Scan(&e.At, &e.Metric, &e.Value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return groupMeals(entries), rows.Err()
+}
+
+// groupMeals starts a new meal whenever an entry comes MealGapMinutes or
+// more after the previous one
+func groupMeals(entries []mealEntry) []Meal {
+	var meals []Meal
+	prev := ""
+	for _, e := range entries {
+		if prev == "" || minutesBetween(prev, e.At) >= MealGapMinutes {
+			meals = append(meals, Meal{Time: e.At[11:]})
+		}
+		prev = e.At
+		m := &meals[len(meals)-1]
+		if e.Metric == "protein" {
+			m.ProteinG += e.Value
+		} else {
+			m.Kcal += e.Value
+		}
+	}
+	return meals
+}
+
+// lateKcalPct is the share of calories in meals starting at or after
+// MealLateAfter; nil without calories
+func lateKcalPct(meals []Meal) *int {
+	total, late := 0.0, 0.0
+	for _, m := range meals {
+		total += m.Kcal
+		if m.Time >= MealLateAfter {
+			late += m.Kcal
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	pct := int(math.Round(late / total * 100))
+	return &pct
+}
+
+// mealValue formats a meal, e.g. "08:10 400 kcal, 30 g protein"
+func mealValue(m Meal) string {
+	return fmt.Sprintf("%s %.0f kcal, %.0f g protein", m.Time, m.Kcal, m.ProteinG)
+}
+
+// mealsValue lists meal times and calories on one line, e.g.
+// "08:10 400, 13:00 650, 20:30 1200 kcal (53% after 20:00)"
+func mealsValue(meals []Meal, late *int) string {
+	parts := make([]string, len(meals))
+	for i, m := range meals {
+		parts[i] = fmt.Sprintf("%s %.0f", m.Time, m.Kcal)
+	}
+	value := strings.Join(parts, ", ") + " kcal"
+	if late != nil && *late > 0 {
+		value += fmt.Sprintf(" (%d%% after %s)", *late, MealLateAfter)
+	}
+	return value
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== MEAL TIMING TESTS ====================
+
+func TestGroupMeals(t *testing.T) {
+	entries := []mealEntry{
+		{"2024-01-15 08:10", "dietary_energy", 400},
+		{"2024-01-15 08:10", "protein", 30},
+		{"2024-01-15 08:35", "dietary_energy", 100},
+		{"2024-01-15 13:00", "dietary_energy", 650},
+		{"2024-01-15 13:30", "protein", 40},
+	}
+	want := []Meal{
+		{Time: "08:10", Kcal: 500, ProteinG: 30},
+		{Time: "13:00", Kcal: 650},
+		{Time: "13:30", ProteinG: 40},
+	}
+	if got := groupMeals(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("groupMeals() = %+v, want %+v", got, want)
+	}
+	if got := groupMeals(nil); got != nil {
+		t.Errorf("groupMeals(nil) = %+v, want nil", got)
+	}
+}
+
+func TestLateKcalPct(t *testing.T) {
+	meals := []Meal{{Time: "08:10", Kcal: 300}, {Time: "13:00", Kcal: 300}, {Time: "20:30", Kcal: 1400}}
+	if got := lateKcalPct(meals); got == nil || *got != 70 {
+		t.Errorf("lateKcalPct() = %v, want 70", got)
+	}
+	if got := lateKcalPct([]Meal{{Time: "20:30", ProteinG: 30}}); got != nil {
+		t.Errorf("lateKcalPct() without calories = %v, want nil", *got)
+	}
+}
+
+func TestEveningMeals(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('dietary_energy', '2024-01-15 08:10:00 +0700', 300, 'kcal', 'briefing'),
+		('protein', '2024-01-15 08:10:00 +0700', 25, 'g', 'briefing'),
+		('dietary_energy', '2024-01-15 13:00:00 +0700', 300, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-15 20:30:00 +0700', 1000, 'kcal', 'briefing'),
+		('dietary_energy', '2024-01-15 20:45:00 +0700', 400, 'kcal', 'briefing'),
+		('protein', '2024-01-15 20:45:00 +0700', 60, 'g', 'briefing')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if len(e.Meals) != 3 || e.Meals[2] != (Meal{Time: "20:30", Kcal: 1400, ProteinG: 60}) {
+		t.Fatalf("Meals = %+v, want three meals ending with 20:30", e.Meals)
+	}
+	md := EveningMarkdown(e)
+	for _, want := range []string{"## Meals", "- 08:10 300 kcal, 25 g protein\n", "- 70% of calories after 20:00\n"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if text := EveningText(e, textStyle{}); !strings.Contains(text, "Meals    08:10 300, 13:00 300, 20:30 1400 kcal (70% after 20:00)\n") {
+		t.Errorf("text missing meals line:\n%s", text)
+	}
+	if vars := eveningRuleVars(e); vars["meals.late_pct"] != 70.0 || vars["meals.count"] != 3.0 {
+		t.Errorf("rule vars = %v, %v; want 70, 3", vars["meals.late_pct"], vars["meals.count"])
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import "strconv"
+
+// Meeting weights for the morning load: a 1:1 is lighter than a plain event,
+// a big meeting heavier
+const (
+	MeetingLargeAttendees = 10  // people, you included, that make a meeting large
+	MeetingOneOnOneWeight = 0.5 // a 1:1 counts as half an event
+	MeetingLargeWeight    = 2.0 // a large meeting counts as two
+)
+
+// meetingSize counts the people at an event, you included, and whether it's
+// a 1:1; an event with no one else invited has no count
+func meetingSize(e CalendarEvent) (int, bool) {
+	if len(e.Attendees) == 0 {
+		return 0, false
+	}
+	return len(e.Attendees) + 1, len(e.Attendees) == 1
+}
+
+// meetingWeight is how much an event adds to the load
+func meetingWeight(e CalendarEvent) float64 {
+	switch {
+	case e.OneOnOne:
+		return MeetingOneOnOneWeight
+	case e.AttendeeCount >= MeetingLargeAttendees:
+		return MeetingLargeWeight
+	}
+	return 1
+}
+
+// morningWeight sums the weights of the morning's events, leaving out ones
+// ongoing from before today as MorningCount does
+func morningWeight(events []CalendarEvent) float64 {
+	weight := 0.0
+	for _, e := range events {
+		if !e.Ongoing {
+			weight += meetingWeight(e)
+		}
+	}
+	return weight
+}
+
+// loadFor maps the morning's weighted event count to CLEAR, LIGHT, or PACKED
+func loadFor(weight float64) string {
+	switch {
+	case weight == 0:
+		return "CLEAR"
+	case weight <= 2:
+		return "LIGHT"
+	}
+	return "PACKED"
+}
+
+// meetingDetail is shown after an event's summary: "1:1", or the head count
+// of a large meeting
+func meetingDetail(e CalendarEvent) string {
+	switch {
+	case e.OneOnOne:
+		return "1:1"
+	case e.AttendeeCount >= MeetingLargeAttendees:
+		return strconv.Itoa(e.AttendeeCount) + " people"
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+// ==================== MEETING SIZE TESTS ====================
+
+func people(n int) []CalendarAttendee {
+	attendees := make([]CalendarAttendee, n)
+	for i := range attendees {
+		attendees[i] = CalendarAttendee{Email: "p" + string(rune('a'+i)) + "@example.com"}
+	}
+	return attendees
+}
+
+func TestMeetingSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		others   int
+		count    int
+		oneOnOne bool
+	}{
+		{"alone", 0, 0, false},
+		{"1:1", 1, 2, true},
+		{"small group", 3, 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, oneOnOne := meetingSize(CalendarEvent{Attendees: people(tt.others)})
+			if count != tt.count || oneOnOne != tt.oneOnOne {
+				t.Errorf("meetingSize() = %d, %v, want %d, %v", count, oneOnOne, tt.count, tt.oneOnOne)
+			}
+		})
+	}
+}
+
+func TestMorningLoadWeighted(t *testing.T) {
+	coffee := CalendarEvent{Time: "08:30", Summary: "Coffee with Ana", AttendeeCount: 2, OneOnOne: true}
+	review := CalendarEvent{Time: "10:00", Summary: "Quarterly review", AttendeeCount: 20}
+	standup := CalendarEvent{Time: "09:00", Summary: "Standup", AttendeeCount: 6}
+	focus := CalendarEvent{Time: "09:30", Summary: "Focus"}
+	offsite := CalendarEvent{Time: "00:00", Summary: "Offsite", AttendeeCount: 30, Ongoing: true}
+	tests := []struct {
+		name     string
+		events   []CalendarEvent
+		weight   float64
+		expected string
+	}{
+		{"three 1:1s", []CalendarEvent{coffee, coffee, coffee}, 1.5, "LIGHT"},
+		{"review and standup", []CalendarEvent{review, standup}, 3, "PACKED"},
+		{"one review", []CalendarEvent{review}, 2, "LIGHT"},
+		{"three plain events", []CalendarEvent{standup, focus, standup}, 3, "PACKED"},
+		{"ongoing left out", []CalendarEvent{offsite, coffee}, 0.5, "LIGHT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := 0
+			for _, e := range tt.events {
+				if !e.Ongoing {
+					count++
+				}
+			}
+			b := &MorningBriefing{Calendar: CalendarData{MorningEvents: tt.events, MorningCount: count}}
+			if got := morningWeight(tt.events); got != tt.weight {
+				t.Errorf("morningWeight() = %v, want %v", got, tt.weight)
+			}
+			classify(b)
+			if b.Classification.MorningLoad != tt.expected {
+				t.Errorf("MorningLoad = %s, want %s", b.Classification.MorningLoad, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMeetingDetail(t *testing.T) {
+	tests := []struct {
+		event    CalendarEvent
+		expected string
+	}{
+		{CalendarEvent{Summary: "Coffee", AttendeeCount: 2, OneOnOne: true}, "Coffee (1:1)"},
+		{CalendarEvent{Summary: "All hands", AttendeeCount: 40, JoinURL: "https://zoom.us/j/1"}, "All hands (video call, 40 people)"},
+		{CalendarEvent{Summary: "Standup", AttendeeCount: 6}, "Standup"},
+	}
+	for _, tt := range tests {
+		if got := eventSummary(tt.event); got != tt.expected {
+			t.Errorf("eventSummary(%+v) = %q, want %q", tt.event, got, tt.expected)
+		}
+	}
+}
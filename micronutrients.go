@@ -0,0 +1,139 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Micronutrient statuses against the configured range
+const (
+	MicroStatusLow    = "LOW"
+	MicroStatusNormal = "NORMAL"
+	MicroStatusHigh   = "HIGH"
+)
+
+// MicronutrientConfig is one nutrient the evening sums and checks; Max 0 has no upper bound
+type MicronutrientConfig struct {
+	Metric       string  `json:"metric"` // health.db metric_name, e.g. sodium
+	Label        string  `json:"label"`
+	Unit         string  `json:"unit"`
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	SweatPerHour float64 `json:"sweat_per_hour"` // added to min per hour of today's workout
+}
+
+// defaultMicronutrients suit a lifter training in tropical heat
+var defaultMicronutrients = []MicronutrientConfig{
+	{Metric: "sodium", Label: "Sodium", Unit: "mg", Min: 1500, Max: 4000, SweatPerHour: 1000},
+	{Metric: "potassium", Label: "Potassium", Unit: "mg", Min: 3400, SweatPerHour: 200},
+	{Metric: "magnesium", Label: "Magnesium", Unit: "mg", Min: 400},
+}
+
+// MicronutrientData is the day's total of one nutrient against its range
+type MicronutrientData struct {
+	Metric string  `json:"metric"`
+	Label  string  `json:"label"`
+	Amount float64 `json:"amount"`
+	Unit   string  `json:"unit"`
+	Min    float64 `json:"min"`           // includes the sweat allowance on workout days
+	Max    float64 `json:"max,omitempty"` // 0 has no upper bound
+	Status string  `json:"status"`        // LOW, NORMAL, HIGH
+}
+
+// validateMicronutrients checks each nutrient has a metric and a sensible range
+func validateMicronutrients(micros []MicronutrientConfig) error {
+	for i, m := range micros {
+		if m.Metric == "" || m.Label == "" {
+			return fmt.Errorf("micronutrients[%d]: metric and label are required", i)
+		}
+		if m.Min < 0 || m.Max < 0 || m.SweatPerHour < 0 {
+			return fmt.Errorf("micronutrients[%d]: min, max, and sweat_per_hour must not be negative", i)
+		}
+		if m.Max > 0 && m.Max < m.Min {
+			return fmt.Errorf("micronutrients[%d]: max must not be below min", i)
+		}
+	}
+	return nil
+}
+
+// micronutrientFor checks amount against the range, raising the minimum by
+// the sweat allowance for workoutMinutes
+func micronutrientFor(cfg MicronutrientConfig, amount, workoutMinutes float64) MicronutrientData {
+	m := MicronutrientData{
+		Metric: cfg.Metric,
+		Label:  cfg.Label,
+		Amount: math.Round(amount),
+		Unit:   cfg.Unit,
+		Min:    math.Round(cfg.Min + cfg.SweatPerHour*workoutMinutes/60),
+		Max:    cfg.Max,
+		Status: MicroStatusNormal,
+	}
+	switch {
+	case m.Amount < m.Min:
+		m.Status = MicroStatusLow
+	case m.Max > 0 && m.Amount > m.Max:
+		m.Status = MicroStatusHigh
+	}
+	return m
+}
+
+// queryMicronutrients sums each configured nutrient for date, leaving out
+// nutrients with nothing logged; ranges are checked without a workout
+func queryMicronutrients(db *sql.DB, micros []MicronutrientConfig, date string) ([]MicronutrientData, error) {
+	var out []MicronutrientData
+	for _, cfg := range micros {
+		amount, err := queryDayTotal(db, cfg.Metric, date)
+		if err != nil {
+			return nil, err
+		}
+		if amount > 0 {
+			out = append(out, micronutrientFor(cfg, amount, 0))
+		}
+	}
+	return out, nil
+}
+
+// calculateEveningMicronutrients rechecks the ranges once today's workout is known
+func calculateEveningMicronutrients(b *EveningBriefing) {
+	workoutMinutes := 0.0
+	if b.Activity.Workout != nil && b.Activity.Workout.Done {
+		workoutMinutes = parseWorkoutMinutes(b.Activity.Workout.Duration)
+	}
+	for i, m := range b.Micronutrients {
+		for _, cfg := range settings.Micronutrients {
+			if cfg.Metric == m.Metric {
+				b.Micronutrients[i] = micronutrientFor(cfg, m.Amount, workoutMinutes)
+			}
+		}
+	}
+}
+
+// micronutrientRange formats the range, e.g. "1500-4000 mg" or "≥ 400 mg"
+func micronutrientRange(m MicronutrientData) string {
+	if m.Max > 0 {
+		return fmt.Sprintf("%.0f-%.0f %s", m.Min, m.Max, m.Unit)
+	}
+	return fmt.Sprintf("≥ %.0f %s", m.Min, m.Unit)
+}
+
+// micronutrientValue formats one nutrient, e.g. "2100 mg (1500-4000 mg)"
+func micronutrientValue(m MicronutrientData) string {
+	return fmt.Sprintf("%.0f %s (%s)", m.Amount, m.Unit, micronutrientRange(m))
+}
+
+// micronutrientsValue lists the out-of-range nutrients, e.g. "potassium 2100 mg LOW";
+// "all in range" when none are
+func micronutrientsValue(micros []MicronutrientData) string {
+	var flagged []string
+	for _, m := range micros {
+		if m.Status != MicroStatusNormal {
+			flagged = append(flagged, fmt.Sprintf("%s %.0f %s %s", strings.ToLower(m.Label), m.Amount, m.Unit, m.Status))
+		}
+	}
+	if len(flagged) == 0 {
+		return "all in range"
+	}
+	return strings.Join(flagged, ", ")
+}
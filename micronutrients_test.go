@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== MICRONUTRIENT TESTS ====================
+
+func TestValidateMicronutrients(t *testing.T) {
+	tests := []struct {
+		name        string
+		micros      []MicronutrientConfig
+		expectError bool
+	}{
+		{"default", DefaultConfig().Micronutrients, false},
+		{"none", nil, false},
+		{"missing metric", []MicronutrientConfig{{Label: "Zinc", Min: 11}}, true},
+		{"negative min", []MicronutrientConfig{{Metric: "zinc", Label: "Zinc", Min: -1}}, true},
+		{"max below min", []MicronutrientConfig{{Metric: "zinc", Label: "Zinc", Min: 11, Max: 5}}, true},
+		{"min only", []MicronutrientConfig{{Metric: "zinc", Label: "Zinc", Unit: "mg", Min: 11}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMicronutrients(tt.micros)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateMicronutrients() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestMicronutrientFor(t *testing.T) {
+	sodium := MicronutrientConfig{Metric: "sodium", Label: "Sodium", Unit: "mg", Min: 1500, Max: 4000, SweatPerHour: 1000}
+	tests := []struct {
+		name               string
+		amount, workoutMin float64
+		wantMin            float64
+		wantStatus         string
+	}{
+		{"in range", 2100, 0, 1500, MicroStatusNormal},
+		{"low", 1200, 0, 1500, MicroStatusLow},
+		{"high", 4500, 0, 1500, MicroStatusHigh},
+		{"low after a 90 minute workout", 2100, 90, 3000, MicroStatusLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := micronutrientFor(sodium, tt.amount, tt.workoutMin)
+			if m.Min != tt.wantMin || m.Status != tt.wantStatus {
+				t.Errorf("micronutrientFor() = %+v, want min %v status %s", m, tt.wantMin, tt.wantStatus)
+			}
+		})
+	}
+	if m := micronutrientFor(MicronutrientConfig{Min: 400}, 9000, 0); m.Status != MicroStatusNormal {
+		t.Errorf("micronutrientFor() without a max = %s, want NORMAL", m.Status)
+	}
+}
+
+func TestEveningMicronutrientsFromDB(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('sodium', '2024-01-15 08:00:00 +0700', 900, 'mg', 'MyFitnessPal'),
+		('sodium', '2024-01-15 13:00:00 +0700', 1300, 'mg', 'MyFitnessPal'),
+		('potassium', '2024-01-15 13:00:00 +0700', 2100, 'mg', 'MyFitnessPal'),
+		('potassium', '2024-01-14 13:00:00 +0700', 3000, 'mg', 'MyFitnessPal')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Magnesium wasn't logged, so it's left out rather than flagged
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if len(e.Micronutrients) != 2 {
+		t.Fatalf("Micronutrients = %+v, want sodium and potassium", e.Micronutrients)
+	}
+	if s := e.Micronutrients[0]; s.Amount != 2200 || s.Status != MicroStatusNormal {
+		t.Errorf("sodium = %+v, want 2200 NORMAL", s)
+	}
+	if p := e.Micronutrients[1]; p.Amount != 2100 || p.Status != MicroStatusLow {
+		t.Errorf("potassium = %+v, want 2100 LOW", p)
+	}
+
+	// A 90 minute session raises the sodium floor past the day's intake
+	e.Activity.Workout = &WorkoutInfo{Done: true, Title: "Legs", Duration: "1h30m"}
+	calculateEveningMicronutrients(&e)
+	if s := e.Micronutrients[0]; s.Min != 3000 || s.Status != MicroStatusLow {
+		t.Errorf("sodium after workout = %+v, want min 3000 LOW", s)
+	}
+
+	if md := EveningMarkdown(e); !strings.Contains(md, "## Micronutrients\n\n- Sodium: 2200 mg (3000-4000 mg) **LOW**\n- Potassium: 2100 mg (≥ 3700 mg) **LOW**\n") {
+		t.Errorf("markdown missing micronutrients:\n%s", md)
+	}
+	if text := EveningText(e, textStyle{}); !strings.Contains(text, "Micros   sodium 2200 mg LOW, potassium 2100 mg LOW\n") {
+		t.Errorf("text missing micronutrients line:\n%s", text)
+	}
+	vars := eveningRuleVars(e)
+	if vars["micros.sodium"] != 2200.0 || vars["micros.potassium.status"] != MicroStatusLow {
+		t.Errorf("rule vars = %v, %v; want 2200, LOW", vars["micros.sodium"], vars["micros.potassium.status"])
+	}
+}
+
+func TestMicronutrientsValue(t *testing.T) {
+	micros := []MicronutrientData{{Label: "Sodium", Amount: 2200, Unit: "mg", Status: MicroStatusNormal}}
+	if got := micronutrientsValue(micros); got != "all in range" {
+		t.Errorf("micronutrientsValue() = %q, want all in range", got)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// MiddayBriefing is a quick check-in on intake so far and the rest of the day
+type MiddayBriefing struct {
+	Mode           string          `json:"mode"`
+	GeneratedAt    string          `json:"generated_at"`
+	TargetDate     string          `json:"target_date"`
+	Energy         MiddayEnergy    `json:"energy"`
+	Protein        ProteinData     `json:"protein"`
+	Hydration      HydrationData   `json:"hydration"`
+	Steps          int             `json:"steps"`
+	UpcomingEvents []CalendarEvent `json:"upcoming_events"`
+	Meds           MedsData        `json:"meds"`
+	Alerts         []Alert         `json:"alerts,omitempty"`
+	Errors         []string        `json:"errors,omitempty"`
+}
+
+// MiddayEnergy is intake and burn so far today
+type MiddayEnergy struct {
+	ConsumedKcal               float64 `json:"consumed_kcal"`
+	ActiveKcal                 float64 `json:"active_kcal"`
+	ProjectedActiveKcal        float64 `json:"projected_active_kcal"`
+	RemainingKcalToMaintenance int     `json:"remaining_kcal_to_maintenance"`
+	RemainingKcalToGoalDeficit int     `json:"remaining_kcal_to_goal_deficit"`
+}
+
+// RunMiddayBriefing prints the midday check-in and publishes it if MQTT is configured
+func RunMiddayBriefing(cfg Config, opts RunOptions) {
+	briefing := BuildMiddayBriefing(time.Now())
+
+	alerts, errs := EvaluateRules(cfg.Rules, middayRuleVars(briefing))
+	briefing.Alerts = alerts
+	for _, err := range errs {
+		briefing.Errors = append(briefing.Errors, fmt.Sprintf("rule error: %v", err))
+	}
+
+	printed := briefing
+	if opts.Redact {
+		printed = RedactMiddayBriefing(briefing)
+	}
+	switch opts.Format {
+	case FormatNone:
+	case "markdown":
+		fmt.Print(MiddayMarkdown(printed))
+	case "text":
+		fmt.Print(MiddayText(printed, textStyle{color: useColor(os.Stdout)}))
+	case "prompt":
+		printPrompt(cfg.Prompt, "midday", printed)
+	default:
+		output, _ := json.MarshalIndent(printed, "", "  ")
+		fmt.Println(string(output))
+	}
+
+	if cfg.MQTT.Broker != "" {
+		payload, _ := json.Marshal(briefing)
+		messages := []MQTTMessage{{Topic: cfg.MQTT.TopicPrefix + "/midday", Payload: payload}}
+		if err := PublishMQTT(cfg.MQTT, messages); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	for _, err := range dispatchAlerts(cfg, "midday", briefing.TargetDate, briefing.GeneratedAt, briefing.Alerts) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, err := range deliver(cfg.Delivery, deliveryChannelsFor(cfg.Delivery, "midday", opts.Deliver), middayDelivery(printed)) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if cfg.Archive.Enabled {
+		if err := archiveBriefing(cfg.Archive, "midday", briefing.TargetDate, briefing, MiddayMarkdown(briefing)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// BuildMiddayBriefing collects intake so far (evening queries) and the
+// remaining calendar and meds (morning sources)
+func BuildMiddayBriefing(now time.Time) MiddayBriefing {
+	today := now.Format("2006-01-02")
+
+	b := MiddayBriefing{
+		Mode:           "midday",
+		GeneratedAt:    now.Format(time.RFC3339),
+		TargetDate:     today,
+		UpcomingEvents: []CalendarEvent{},
+	}
+
+	evening := newEveningBriefing(now, today)
+	getEveningHealthData(&evening, today, yesterday(today))
+	calculateEveningHydration(&evening)
+
+	morning := MorningBriefing{TargetDate: today}
+	fetchSources(context.Background(), &morning, settings.Sources, "calendar", "todoist")
+
+	fillMidday(&b, evening, morning, now.Format("15:04"))
+	return b
+}
+
+// fillMidday copies the relevant parts of the evening and morning data,
+// keeping only events that haven't started by the given HH:MM
+func fillMidday(b *MiddayBriefing, evening EveningBriefing, morning MorningBriefing, clock string) {
+	b.Energy = MiddayEnergy{
+		ConsumedKcal:               evening.Energy.ConsumedKcal,
+		ActiveKcal:                 evening.Energy.ActiveKcal,
+		ProjectedActiveKcal:        evening.Energy.ProjectedActiveKcal,
+		RemainingKcalToMaintenance: evening.Energy.RemainingKcalToMaintenance,
+		RemainingKcalToGoalDeficit: evening.Energy.RemainingKcalToGoalDeficit,
+	}
+	b.Protein = evening.Protein
+	b.Hydration = evening.Hydration
+	b.Steps = evening.Activity.Steps
+
+	for _, e := range append(append([]CalendarEvent{}, morning.Calendar.MorningEvents...), morning.Calendar.AfternoonEvents...) {
+		if e.Time >= clock || e.Ongoing && (e.EndTime == "" || e.EndTime > clock) {
+			b.UpcomingEvents = append(b.UpcomingEvents, e)
+		}
+	}
+	b.Meds = morning.Meds
+
+	b.Errors = append(b.Errors, evening.Errors...)
+	b.Errors = append(b.Errors, morning.Errors...)
+}
+
+// middayRuleVars exposes midday fields to rule expressions
+func middayRuleVars(b MiddayBriefing) map[string]any {
+	return map[string]any{
+		"energy.consumed":              b.Energy.ConsumedKcal,
+		"energy.active":                b.Energy.ActiveKcal,
+		"energy.remaining_maintenance": float64(b.Energy.RemainingKcalToMaintenance),
+		"energy.remaining_goal":        float64(b.Energy.RemainingKcalToGoalDeficit),
+		"protein.consumed":             b.Protein.ConsumedG,
+		"protein.remaining":            b.Protein.RemainingG,
+		"water.consumed":               b.Hydration.ConsumedMl,
+		"water.remaining":              b.Hydration.RemainingMl,
+		"steps":                        float64(b.Steps),
+		"events.upcoming":              float64(len(b.UpcomingEvents)),
+		"meds.due":                     float64(len(b.Meds.DueToday)),
+		"meds.overdue":                 float64(len(b.Meds.Overdue)),
+	}
+}
+
+// RedactMiddayBriefing returns a copy safe to share (see RedactMorningBriefing)
+func RedactMiddayBriefing(b MiddayBriefing) MiddayBriefing {
+	b.UpcomingEvents = redactEvents(b.UpcomingEvents)
+	b.Meds = redactMeds(b.Meds)
+	b.Errors = redactStrings(b.Errors, redactEmails)
+	return b
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== MIDDAY TESTS ====================
+
+func TestFillMidday(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 30, 0, 0, time.UTC)
+	evening := newEveningBriefing(now, "2024-01-15")
+	evening.Energy.ConsumedKcal = 650
+	evening.Energy.ActiveKcal = 210
+	evening.Protein.ConsumedG = 48
+	evening.Activity.Steps = 5400
+	evening.Errors = []string{"steps query error: boom"}
+
+	morning := MorningBriefing{
+		Calendar: CalendarData{
+			MorningEvents: []CalendarEvent{
+				{Time: "00:00", Summary: "Conference", Ongoing: true, EndTime: "24:00", Until: "2024-01-17"},
+				{Time: "00:00", Summary: "Night shift", Ongoing: true, EndTime: "06:00"},
+				{Time: "09:00", Summary: "Standup"},
+			},
+			AfternoonEvents: []CalendarEvent{{Time: "12:30", Summary: "Lunch"}, {Time: "15:00", Summary: "Review"}},
+		},
+		Meds:   MedsData{Overdue: []MedTask{{Name: "Vitamin D"}}},
+		Errors: []string{"todoist error: exit status 1"},
+	}
+
+	b := MiddayBriefing{UpcomingEvents: []CalendarEvent{}}
+	fillMidday(&b, evening, morning, "12:30")
+
+	if b.Energy.ConsumedKcal != 650 || b.Energy.ActiveKcal != 210 {
+		t.Errorf("energy = %+v", b.Energy)
+	}
+	if b.Protein.ConsumedG != 48 || b.Steps != 5400 {
+		t.Errorf("protein = %v, steps = %d", b.Protein.ConsumedG, b.Steps)
+	}
+	// Events already started are dropped, unless ongoing and still running; one starting now is kept
+	if len(b.UpcomingEvents) != 3 || b.UpcomingEvents[0].Summary != "Conference" || b.UpcomingEvents[1].Summary != "Lunch" {
+		t.Errorf("upcoming = %+v, want Conference, Lunch and Review", b.UpcomingEvents)
+	}
+	if len(b.Meds.Overdue) != 1 {
+		t.Errorf("overdue meds = %+v", b.Meds.Overdue)
+	}
+	if len(b.Errors) != 2 {
+		t.Errorf("errors = %v, want both sources' errors", b.Errors)
+	}
+}
+
+func TestMiddayRuleVars(t *testing.T) {
+	b := MiddayBriefing{Steps: 1200, UpcomingEvents: []CalendarEvent{{Time: "14:00"}}}
+	b.Protein.RemainingG = 100
+
+	vars := middayRuleVars(b)
+	if vars["steps"] != 1200.0 || vars["events.upcoming"] != 1.0 || vars["protein.remaining"] != 100.0 {
+		t.Errorf("vars = %v", vars)
+	}
+}
+
+func TestRedactMiddayBriefing(t *testing.T) {
+	b := MiddayBriefing{
+		UpcomingEvents: []CalendarEvent{{Time: "14:00", Summary: "Therapy"}},
+		Meds: MedsData{
+			DueToday:       []MedTask{{Name: "Sertraline"}},
+			MissedRecently: []MedMisses{{Name: "Lisinopril", Missed: 3}},
+			Inventory:      []MedStock{{Name: "Metformin", DaysLeft: 6}},
+			Cycles:         []CycleStatus{{Name: "Enclomiphene", Dose: "12.5mg"}},
+			Categories:     MedCategories{"medication": {Due: []string{"Sertraline"}, Completed: []string{"Atorvastatin"}}},
+		},
+	}
+	r := RedactMiddayBriefing(b)
+	out, _ := json.Marshal(r)
+	for _, secret := range []string{"Therapy", "Sertraline", "Lisinopril", "Metformin", "Enclomiphene", "12.5mg", "Atorvastatin"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("redacted output still contains %q: %s", secret, out)
+		}
+	}
+	if r.UpcomingEvents[0].Time != "14:00" {
+		t.Error("RedactMiddayBriefing() dropped event time")
+	}
+}
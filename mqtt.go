@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// MQTTMessage is a single topic/payload pair to publish
+type MQTTMessage struct {
+	Topic   string
+	Payload []byte
+}
+
+const mqttTimeout = 10 * time.Second
+
+// PublishMQTT connects to the configured broker and publishes messages at QoS 0
+// Implements the minimal subset of MQTT 3.1.1 needed: CONNECT, PUBLISH, DISCONNECT
+func PublishMQTT(cfg MQTTConfig, messages []MQTTMessage) error {
+	conn, err := net.DialTimeout("tcp", cfg.Broker, mqttTimeout)
+	if err != nil {
+		return fmt.Errorf("mqtt connect error: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(mqttTimeout))
+
+	if _, err := conn.Write(mqttConnectPacket(cfg)); err != nil {
+		return fmt.Errorf("mqtt connect error: %w", err)
+	}
+
+	// CONNACK: 0x20, length 2, session present, return code
+	ack := make([]byte, 4)
+	if _, err := io.ReadFull(conn, ack); err != nil {
+		return fmt.Errorf("mqtt connack error: %w", err)
+	}
+	if ack[0] != 0x20 || ack[1] != 0x02 {
+		return errors.New("mqtt connack error: unexpected packet")
+	}
+	if ack[3] != 0 {
+		return fmt.Errorf("mqtt connection refused (code %d)", ack[3])
+	}
+
+	for _, m := range messages {
+		if _, err := conn.Write(mqttPublishPacket(m.Topic, m.Payload, cfg.Retain)); err != nil {
+			return fmt.Errorf("mqtt publish error (%s): %w", m.Topic, err)
+		}
+	}
+
+	_, err = conn.Write([]byte{0xE0, 0x00}) // DISCONNECT
+	return err
+}
+
+func mqttConnectPacket(cfg MQTTConfig) []byte {
+	var body bytes.Buffer
+	body.Write(mqttString("MQTT"))
+	body.WriteByte(0x04) // Protocol level 3.1.1
+
+	flags := byte(0x02) // Clean session
+	if cfg.Username != "" {
+		flags |= 0x80
+	}
+	if cfg.Password != "" {
+		flags |= 0x40
+	}
+	body.WriteByte(flags)
+	body.Write([]byte{0x00, 0x3C}) // Keep alive 60s
+
+	body.Write(mqttString(cfg.ClientID))
+	if cfg.Username != "" {
+		body.Write(mqttString(cfg.Username))
+	}
+	if cfg.Password != "" {
+		body.Write(mqttString(cfg.Password))
+	}
+
+	return mqttPacket(0x10, body.Bytes())
+}
+
+func mqttPublishPacket(topic string, payload []byte, retain bool) []byte {
+	header := byte(0x30) // PUBLISH, QoS 0
+	if retain {
+		header |= 0x01
+	}
+	body := append(mqttString(topic), payload...)
+	return mqttPacket(header, body)
+}
+
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	packet = append(packet, mqttRemainingLength(len(body))...)
+	return append(packet, body...)
+}
+
+// mqttRemainingLength encodes the variable-length remaining length field
+func mqttRemainingLength(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+func mqttString(s string) []byte {
+	return append([]byte{byte(len(s) >> 8), byte(len(s))}, s...)
+}
+
+// morningMQTTMessages builds the full payload plus one topic per classification field
+func morningMQTTMessages(prefix string, b MorningBriefing, payload []byte) []MQTTMessage {
+	return []MQTTMessage{
+		{Topic: prefix + "/morning", Payload: payload},
+		{Topic: prefix + "/sleep_quality", Payload: []byte(b.Classification.SleepQuality)},
+		{Topic: prefix + "/morning_load", Payload: []byte(b.Classification.MorningLoad)},
+		{Topic: prefix + "/recovery_status", Payload: []byte(b.Classification.RecoveryStatus)},
+		{Topic: prefix + "/recommendation", Payload: []byte(b.Classification.Recommendation)},
+	}
+}
+
+// eveningMQTTMessages builds the full payload plus the energy status topic
+func eveningMQTTMessages(prefix string, b EveningBriefing, payload []byte) []MQTTMessage {
+	return []MQTTMessage{
+		{Topic: prefix + "/evening", Payload: payload},
+		{Topic: prefix + "/energy_status", Payload: []byte(b.Energy.Status)},
+	}
+}
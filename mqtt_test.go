@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+)
+
+// ==================== MQTT PUBLISHING TESTS ====================
+
+func TestMQTTRemainingLength(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7F}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xFF, 0x7F}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+	}
+
+	for _, tt := range tests {
+		result := mqttRemainingLength(tt.n)
+		if string(result) != string(tt.expected) {
+			t.Errorf("mqttRemainingLength(%d) = %x, want %x", tt.n, result, tt.expected)
+		}
+	}
+}
+
+// readMQTTPacket reads one packet from a fake broker connection
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return header, body, err
+}
+
+func TestPublishMQTT(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	type received struct {
+		header byte
+		topic  string
+		body   string
+	}
+	done := make(chan []received, 1)
+
+	go func() {
+		var got []received
+		defer func() { done <- got }()
+
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		header, _, err := readMQTTPacket(r)
+		if err != nil || header != 0x10 {
+			return
+		}
+		conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+
+		for {
+			header, body, err := readMQTTPacket(r)
+			if err != nil || header == 0xE0 {
+				return
+			}
+			topicLen := int(body[0])<<8 | int(body[1])
+			got = append(got, received{
+				header: header,
+				topic:  string(body[2 : 2+topicLen]),
+				body:   string(body[2+topicLen:]),
+			})
+		}
+	}()
+
+	b := MorningBriefing{
+		Classification: Classification{
+			SleepQuality:   "GOOD",
+			MorningLoad:    "LIGHT",
+			RecoveryStatus: "OK",
+			Recommendation: "Well rested. Attack the day.",
+		},
+	}
+	cfg := MQTTConfig{Broker: ln.Addr().String(), ClientID: "test", Retain: true}
+	messages := morningMQTTMessages("briefing", b, []byte(`{"ok":true}`))
+
+	if err := PublishMQTT(cfg, messages); err != nil {
+		t.Fatalf("PublishMQTT() error: %v", err)
+	}
+
+	got := <-done
+	if len(got) != len(messages) {
+		t.Fatalf("broker received %d messages, want %d", len(got), len(messages))
+	}
+
+	expected := map[string]string{
+		"briefing/morning":         `{"ok":true}`,
+		"briefing/sleep_quality":   "GOOD",
+		"briefing/morning_load":    "LIGHT",
+		"briefing/recovery_status": "OK",
+	}
+	for _, m := range got {
+		if m.header != 0x31 {
+			t.Errorf("topic %s header = %#x, want 0x31 (retained QoS 0)", m.topic, m.header)
+		}
+		if want, ok := expected[m.topic]; ok && m.body != want {
+			t.Errorf("topic %s payload = %q, want %q", m.topic, m.body, want)
+		}
+	}
+}
+
+func TestPublishMQTTConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		readMQTTPacket(bufio.NewReader(conn))
+		conn.Write([]byte{0x20, 0x02, 0x00, 0x05}) // Not authorized
+	}()
+
+	cfg := MQTTConfig{Broker: ln.Addr().String(), ClientID: "test"}
+	if err := PublishMQTT(cfg, nil); err == nil {
+		t.Error("PublishMQTT() expected error for refused connection, got nil")
+	}
+}
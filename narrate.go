@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Narration backends
+const (
+	NarrateOllama = "ollama" // local Ollama /api/chat
+	NarrateOpenAI = "openai" // any OpenAI-compatible /v1/chat/completions
+)
+
+// narrateTimeout allows for slow local models
+const narrateTimeout = 2 * time.Minute
+
+// narrationInstructions is the system prompt for the morning narrative
+const narrationInstructions = `You write a short spoken-style morning briefing from structured health and schedule data.
+Use 3-5 sentences of plain prose, no lists or headings. Lead with how recovered the person is and what that means for today,
+then mention the shape of the day and anything overdue. When an event has a join_url, say so ("first call at 09:00, link ready").
+Only state facts present in the data; never give medical advice.`
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   *bool         `json:"stream,omitempty"` // Ollama streams unless told not to
+}
+
+// chatResponse covers both backends: OpenAI fills Choices, Ollama fills Message
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Message chatMessage `json:"message"`
+	Error   any         `json:"error"`
+}
+
+func validateNarrate(cfg NarrateConfig) error {
+	switch cfg.Backend {
+	case NarrateOllama, NarrateOpenAI:
+	default:
+		return fmt.Errorf("narrate.backend: unknown backend %q (expected: ollama, openai)", cfg.Backend)
+	}
+	if cfg.Enabled && cfg.Model == "" {
+		return errors.New("narrate.model is required")
+	}
+	return nil
+}
+
+// narrationPrompt is the briefing as compact JSON, minus anything that is
+// not about the day (errors, a previous narrative)
+func narrationPrompt(b MorningBriefing) string {
+	b.Narrative = ""
+	b.Errors = nil
+	data, _ := json.Marshal(b)
+	return "Today's briefing data:\n" + string(data)
+}
+
+// Narrate asks the configured LLM backend for a short morning narrative
+func Narrate(cfg NarrateConfig, b MorningBriefing) (string, error) {
+	if cfg.Model == "" {
+		return "", errors.New("narrate.model is not set")
+	}
+	req := chatRequest{
+		Model: cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: narrationInstructions},
+			{Role: "user", Content: narrationPrompt(b)},
+		},
+	}
+	endpoint := strings.TrimRight(cfg.URL, "/")
+	switch cfg.Backend {
+	case NarrateOllama:
+		stream := false
+		req.Stream = &stream
+		endpoint += "/api/chat"
+	case NarrateOpenAI:
+		endpoint += "/v1/chat/completions"
+	default:
+		return "", fmt.Errorf("unknown backend %q", cfg.Backend)
+	}
+
+	body, _ := json.Marshal(req)
+	httpReq, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if cfg.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	}
+
+	client := &http.Client{Timeout: narrateTimeout}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("status %d: invalid response: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode >= 300 || result.Error != nil {
+		return "", fmt.Errorf("status %d: %v", resp.StatusCode, result.Error)
+	}
+
+	text := result.Message.Content
+	if len(result.Choices) > 0 {
+		text = result.Choices[0].Message.Content
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("empty narrative")
+	}
+	return text, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== NARRATION TESTS ====================
+
+func TestValidateNarrate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         NarrateConfig
+		expectError bool
+	}{
+		{"default", DefaultConfig().Narrate, false},
+		{"openai", NarrateConfig{Backend: "openai", Model: "gpt-4o-mini"}, false},
+		{"unknown backend", NarrateConfig{Backend: "bard"}, true},
+		{"enabled without model", NarrateConfig{Backend: "ollama", Enabled: true}, true},
+		{"enabled with model", NarrateConfig{Backend: "ollama", Enabled: true, Model: "llama3.2"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNarrate(tt.cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateNarrate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestNarrationPrompt(t *testing.T) {
+	b := MorningBriefing{
+		TargetDate:     "2024-01-15",
+		Narrative:      "old narrative",
+		Classification: Classification{Recommendation: "Take it easy"},
+		Errors:         []string{"todoist error: timeout"},
+	}
+	p := narrationPrompt(b)
+	if !strings.Contains(p, `"recommendation":"Take it easy"`) {
+		t.Errorf("prompt missing briefing data: %s", p)
+	}
+	if strings.Contains(p, "old narrative") || strings.Contains(p, "todoist error") {
+		t.Errorf("prompt includes narrative or errors: %s", p)
+	}
+}
+
+func TestNarrateBackends(t *testing.T) {
+	tests := []struct {
+		backend  string
+		path     string
+		response string
+	}{
+		{"ollama", "/api/chat", `{"message":{"role":"assistant","content":"  You slept well.\n"}}`},
+		{"openai", "/v1/chat/completions", `{"choices":[{"message":{"role":"assistant","content":"You slept well."}}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			var req chatRequest
+			var path, auth string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				auth = r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&req)
+				w.Write([]byte(tt.response))
+			}))
+			defer ts.Close()
+
+			cfg := NarrateConfig{Backend: tt.backend, URL: ts.URL + "/", Model: "test-model", APIKey: "sk-test"}
+			got, err := Narrate(cfg, MorningBriefing{TargetDate: "2024-01-15"})
+			if err != nil {
+				t.Fatalf("Narrate() error: %v", err)
+			}
+			if got != "You slept well." {
+				t.Errorf("Narrate() = %q", got)
+			}
+			if path != tt.path || auth != "Bearer sk-test" {
+				t.Errorf("request path/auth = %q, %q", path, auth)
+			}
+			if req.Model != "test-model" || len(req.Messages) != 2 || req.Messages[0].Role != "system" {
+				t.Errorf("request = %+v", req)
+			}
+			if (req.Stream != nil) != (tt.backend == "ollama") {
+				t.Errorf("stream = %v, want set only for ollama", req.Stream)
+			}
+		})
+	}
+}
+
+func TestNarrateErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"nope\" not found"}`))
+	}))
+	defer ts.Close()
+
+	if _, err := Narrate(NarrateConfig{Backend: "ollama", URL: ts.URL, Model: "nope"}, MorningBriefing{}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Narrate() error = %v, want backend error", err)
+	}
+	if _, err := Narrate(NarrateConfig{Backend: "ollama", URL: ts.URL}, MorningBriefing{}); err == nil {
+		t.Error("Narrate() without model = nil error")
+	}
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[]}`))
+	}))
+	defer empty.Close()
+	if _, err := Narrate(NarrateConfig{Backend: "openai", URL: empty.URL, Model: "m"}, MorningBriefing{}); err == nil {
+		t.Error("Narrate() with no choices = nil error")
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// newsTimeout bounds each feed request
+const newsTimeout = 15 * time.Second
+
+// NewsData is the morning's headlines from news.feeds
+type NewsData struct {
+	Headlines []Headline `json:"headlines"`
+}
+
+// Headline is one feed item
+type Headline struct {
+	Feed  string `json:"feed"`
+	Title string `json:"title"`
+	Link  string `json:"link,omitempty"`
+}
+
+// newsDocument is an RSS 2.0 or Atom feed; only the fields used are read
+type newsDocument struct {
+	XMLName xml.Name
+	Items   []struct {
+		Title string `xml:"title"`
+		Link  string `xml:"link"`
+	} `xml:"channel>item"` // RSS
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+	} `xml:"entry"` // Atom
+}
+
+// validateNews checks that every feed has a URL and the caps are positive
+func validateNews(cfg NewsConfig) error {
+	if len(cfg.Feeds) == 0 {
+		return nil
+	}
+	if cfg.MaxItems <= 0 || cfg.PerFeed <= 0 {
+		return errors.New("news.max_items and news.per_feed must be positive")
+	}
+	for i, f := range cfg.Feeds {
+		if f.URL == "" {
+			return fmt.Errorf("news.feeds[%d]: url is required", i)
+		}
+		if f.MaxItems < 0 {
+			return fmt.Errorf("news.feeds[%d]: max_items must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// getNewsData reads news.feeds in order, keeping each feed's first matching
+// items up to its cap and news.max_items in all
+func getNewsData(b *MorningBriefing, today string) {
+	cfg := settings.News
+	if len(cfg.Feeds) == 0 {
+		return
+	}
+	client := &http.Client{Timeout: newsTimeout}
+	d := &NewsData{Headlines: []Headline{}}
+	for _, f := range cfg.Feeds {
+		if len(d.Headlines) == cfg.MaxItems {
+			break
+		}
+		name := f.Name
+		if name == "" {
+			name = f.URL
+		}
+		headlines, err := fetchFeed(client, f.URL)
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("news error (%s): %v", name, err))
+			continue
+		}
+		limit := cfg.PerFeed
+		if f.MaxItems > 0 {
+			limit = f.MaxItems
+		}
+		kept := 0
+		for _, h := range headlines {
+			if kept == limit || len(d.Headlines) == cfg.MaxItems {
+				break
+			}
+			if !newsMatch(h.Title, f) {
+				continue
+			}
+			h.Feed = name
+			d.Headlines = append(d.Headlines, h)
+			kept++
+		}
+	}
+	b.News = d
+}
+
+// fetchFeed reads an RSS or Atom feed's items in the order given
+func fetchFeed(client *http.Client, u string) ([]Headline, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "briefing")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var doc newsDocument
+	if err := xml.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	var headlines []Headline
+	switch doc.XMLName.Local {
+	case "rss":
+		for _, item := range doc.Items {
+			headlines = append(headlines, Headline{Title: strings.TrimSpace(item.Title), Link: strings.TrimSpace(item.Link)})
+		}
+	case "feed":
+		for _, entry := range doc.Entries {
+			h := Headline{Title: strings.TrimSpace(entry.Title)}
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					h.Link = l.Href
+					break
+				}
+			}
+			headlines = append(headlines, h)
+		}
+	default:
+		return nil, fmt.Errorf("invalid feed: <%s> is neither RSS nor Atom", doc.XMLName.Local)
+	}
+	return headlines, nil
+}
+
+// newsMatch reports whether a title passes the feed's keyword filters: it
+// contains one of include (when given) and none of exclude, ignoring case
+func newsMatch(title string, f NewsFeed) bool {
+	if title == "" {
+		return false
+	}
+	lower := strings.ToLower(title)
+	for _, k := range f.Exclude {
+		if strings.Contains(lower, strings.ToLower(k)) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, k := range f.Include {
+		if strings.Contains(lower, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// headlineLines lists the headlines, e.g. "Rates held at 4% (BBC)"
+func headlineLines(d *NewsData) []string {
+	if d == nil {
+		return nil
+	}
+	lines := make([]string, len(d.Headlines))
+	for i, h := range d.Headlines {
+		lines[i] = fmt.Sprintf("%s (%s)", h.Title, h.Feed)
+	}
+	return lines
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== NEWS TESTS ====================
+
+const testRSS = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>BBC</title>
+<item><title>Rates held at 4%</title><link>https://bbc.example/1</link></item>
+<item><title> Football: late winner </title><link>https://bbc.example/2</link></item>
+<item><title>Markets rally on rate hopes</title><link>https://bbc.example/3</link></item>
+<item><title>Rate cut expected in spring</title><link>https://bbc.example/4</link></item>
+</channel></rss>`
+
+const testAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Go blog</title>
+<entry><title>Go 1.24 is released</title><link rel="alternate" href="https://go.example/1"/></entry>
+<entry><title>Range over func</title><link rel="self" href="https://go.example/self"/><link href="https://go.example/2"/></entry>
+</feed>`
+
+// fakeFeeds serves /rss, /atom, and a broken /html
+func fakeFeeds(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss":
+			w.Write([]byte(testRSS))
+		case "/atom":
+			w.Write([]byte(testAtom))
+		case "/html":
+			w.Write([]byte(`<html><body>moved</body></html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestFetchFeed(t *testing.T) {
+	ts := fakeFeeds(t)
+	client := &http.Client{}
+	rss, err := fetchFeed(client, ts.URL+"/rss")
+	if err != nil || len(rss) != 4 || rss[1] != (Headline{Title: "Football: late winner", Link: "https://bbc.example/2"}) {
+		t.Errorf("RSS = %+v, %v", rss, err)
+	}
+	atom, err := fetchFeed(client, ts.URL+"/atom")
+	if err != nil || len(atom) != 2 || atom[0].Link != "https://go.example/1" || atom[1].Link != "https://go.example/2" {
+		t.Errorf("Atom = %+v, %v", atom, err)
+	}
+	if _, err := fetchFeed(client, ts.URL+"/html"); err == nil || !strings.Contains(err.Error(), "neither RSS nor Atom") {
+		t.Errorf("HTML error = %v", err)
+	}
+	if _, err := fetchFeed(client, ts.URL+"/gone"); err == nil || err.Error() != "status 404" {
+		t.Errorf("missing feed error = %v", err)
+	}
+}
+
+func TestGetNewsData(t *testing.T) {
+	ts := fakeFeeds(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.News = NewsConfig{MaxItems: 4, PerFeed: 2, Feeds: []NewsFeed{
+		{Name: "BBC", URL: ts.URL + "/rss", Include: []string{"RATE"}, Exclude: []string{"markets"}},
+		{Name: "Broken", URL: ts.URL + "/gone"},
+		{URL: ts.URL + "/atom", MaxItems: 5},
+	}}
+
+	b := &MorningBriefing{}
+	getNewsData(b, "2024-01-15")
+	want := []string{"Rates held at 4% (BBC)", "Rate cut expected in spring (BBC)", "Go 1.24 is released (" + ts.URL + "/atom)", "Range over func (" + ts.URL + "/atom)"}
+	if got := headlineLines(b.News); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("headlineLines() = %q, want %q", got, want)
+	}
+	if len(b.Errors) != 1 || b.Errors[0] != "news error (Broken): status 404" {
+		t.Errorf("errors = %v", b.Errors)
+	}
+
+	// news.max_items caps the total
+	settings.News.MaxItems = 1
+	b = &MorningBriefing{}
+	getNewsData(b, "2024-01-15")
+	if len(b.News.Headlines) != 1 || len(b.Errors) != 0 {
+		t.Errorf("news = %+v, errors %v, want one headline and the rest unfetched", b.News, b.Errors)
+	}
+}
+
+func TestNewsMatch(t *testing.T) {
+	f := NewsFeed{Include: []string{"rate", "inflation"}, Exclude: []string{"opinion"}}
+	tests := map[string]bool{
+		"Rates held at 4%":               true,
+		"Inflation falls again":          true,
+		"Opinion: rate cuts are overdue": false,
+		"Football: late winner":          false,
+		"":                               false,
+	}
+	for title, want := range tests {
+		if got := newsMatch(title, f); got != want {
+			t.Errorf("newsMatch(%q) = %v, want %v", title, got, want)
+		}
+	}
+	if !newsMatch("Anything", NewsFeed{}) {
+		t.Error("expected every title to match without filters")
+	}
+}
+
+func TestValidateNews(t *testing.T) {
+	if err := validateNews(DefaultConfig().News); err != nil {
+		t.Errorf("default: %v", err)
+	}
+	feeds := []NewsFeed{{Name: "BBC", URL: "https://feeds.bbci.co.uk/news/rss.xml"}}
+	if err := validateNews(NewsConfig{Feeds: feeds, MaxItems: 10, PerFeed: 3}); err != nil {
+		t.Errorf("one feed: %v", err)
+	}
+	if err := validateNews(NewsConfig{Feeds: []NewsFeed{{Name: "BBC"}}, MaxItems: 10, PerFeed: 3}); err == nil {
+		t.Error("expected an error for a feed without a url")
+	}
+	if err := validateNews(NewsConfig{Feeds: feeds, MaxItems: 10}); err == nil {
+		t.Error("expected an error for per_feed 0")
+	}
+}
+
+func TestNewsRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-01-15", News: &NewsData{Headlines: []Headline{{Feed: "BBC", Title: "Rates held at 4%"}}}}
+	if md := MorningMarkdown(m); !strings.Contains(md, "## News\n\n- Rates held at 4% (BBC)\n") {
+		t.Errorf("markdown missing news:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "News\n  Rates held at 4% (BBC)\n") {
+		t.Errorf("text missing news:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Headlines:\n- Rates held at 4% (BBC)\n") {
+		t.Errorf("prompt missing news (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["news.count"] != 1.0 {
+		t.Errorf("news.count = %v", vars["news.count"])
+	}
+	if md := MorningMarkdown(MorningBriefing{News: &NewsData{}}); strings.Contains(md, "## News") {
+		t.Error("expected no News section without headlines")
+	}
+}
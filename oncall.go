@@ -0,0 +1,333 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// On-call providers
+const (
+	OnCallPagerDuty = "pagerduty"
+	OnCallOpsgenie  = "opsgenie"
+)
+
+// API bases used when oncall.url is empty
+const (
+	pagerDutyAPIURL = "https://api.pagerduty.com"
+	opsgenieAPIURL  = "https://api.opsgenie.com"
+)
+
+// onCallTimeout bounds each on-call API request
+const onCallTimeout = 15 * time.Second
+
+// OnCallData is who is on call today on your schedules
+type OnCallData struct {
+	OnCall    bool             `json:"on_call"` // you, on any of them
+	Schedules []OnCallSchedule `json:"schedules"`
+}
+
+// OnCallSchedule is one schedule's people on call today
+type OnCallSchedule struct {
+	Name   string   `json:"name"`
+	OnCall bool     `json:"on_call"`          // you
+	Until  string   `json:"until,omitempty"`  // your shift's end, YYYY-MM-DD HH:MM local
+	Others []string `json:"others,omitempty"` // everyone else on call today
+}
+
+// onCallEntry is a person on a schedule for part of today
+type onCallEntry struct {
+	Schedule, User string
+	Me             bool
+	End            time.Time
+}
+
+// validateOnCall checks the provider and who you are when on-call is on
+func validateOnCall(cfg OnCallConfig) error {
+	if cfg.APIKey == "" {
+		return nil
+	}
+	switch cfg.Provider {
+	case OnCallPagerDuty:
+	case OnCallOpsgenie:
+		if len(cfg.Schedules) == 0 {
+			return errors.New("oncall.schedules is required for opsgenie")
+		}
+	default:
+		return fmt.Errorf("oncall.provider: unknown provider %q (expected: pagerduty, opsgenie)", cfg.Provider)
+	}
+	if cfg.User == "" {
+		return errors.New("oncall.user is required")
+	}
+	return nil
+}
+
+// getOnCallData reads today's on-call schedules when oncall.api_key is set
+func getOnCallData(b *MorningBriefing, today string) {
+	cfg := settings.OnCall
+	if cfg.APIKey == "" {
+		return
+	}
+	day, err := time.ParseInLocation("2006-01-02", today, time.Local)
+	if err != nil {
+		return
+	}
+	client := &http.Client{Timeout: onCallTimeout}
+	var entries []onCallEntry
+	if cfg.Provider == OnCallOpsgenie {
+		entries, err = opsgenieOnCall(client, cfg, day)
+	} else {
+		entries, err = pagerDutyOnCall(client, cfg, day)
+	}
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("on-call error: %v", err))
+		return
+	}
+	b.OnCall = onCallSchedules(entries)
+}
+
+// onCallSchedules groups entries by schedule, in the order first seen, with
+// your latest end
+func onCallSchedules(entries []onCallEntry) *OnCallData {
+	d := &OnCallData{Schedules: []OnCallSchedule{}}
+	ends := map[string]time.Time{}
+	for _, e := range entries {
+		i := slices.IndexFunc(d.Schedules, func(s OnCallSchedule) bool { return s.Name == e.Schedule })
+		if i < 0 {
+			d.Schedules = append(d.Schedules, OnCallSchedule{Name: e.Schedule})
+			i = len(d.Schedules) - 1
+		}
+		s := &d.Schedules[i]
+		switch {
+		case e.Me:
+			s.OnCall, d.OnCall = true, true
+			if e.End.After(ends[s.Name]) {
+				ends[s.Name] = e.End
+				s.Until = e.End.In(time.Local).Format("2006-01-02 15:04")
+			}
+		case !slices.Contains(s.Others, e.User):
+			s.Others = append(s.Others, e.User)
+		}
+	}
+	return d
+}
+
+// pagerDutyOnCall lists today's on-calls on the schedules you're on, plus
+// oncall.schedules (schedule IDs)
+func pagerDutyOnCall(client *http.Client, cfg OnCallConfig, day time.Time) ([]onCallEntry, error) {
+	type oncall struct {
+		User struct {
+			ID      string `json:"id"`
+			Summary string `json:"summary"`
+		} `json:"user"`
+		Schedule *struct {
+			ID      string `json:"id"`
+			Summary string `json:"summary"`
+		} `json:"schedule"`
+		EscalationPolicy struct {
+			Summary string `json:"summary"`
+		} `json:"escalation_policy"`
+		End string `json:"end"` // null when permanent
+	}
+	list := func(params url.Values) ([]oncall, error) {
+		params.Set("since", day.Format(time.RFC3339))
+		params.Set("until", day.AddDate(0, 0, 1).Format(time.RFC3339))
+		var resp struct {
+			Oncalls []oncall `json:"oncalls"`
+		}
+		err := onCallGet(client, onCallBase(cfg)+"/oncalls?"+params.Encode(), "Token token="+cfg.APIKey, &resp)
+		return resp.Oncalls, err
+	}
+
+	mine, err := list(url.Values{"user_ids[]": {cfg.User}})
+	if err != nil {
+		return nil, err
+	}
+	schedules := slices.Clone(cfg.Schedules)
+	var entries []onCallEntry
+	for _, o := range mine {
+		if o.Schedule == nil {
+			// On an escalation policy directly, with no schedule to share
+			entries = append(entries, pagerDutyEntry(o.EscalationPolicy.Summary, o.User.Summary, true, o.End))
+		} else if !slices.Contains(schedules, o.Schedule.ID) {
+			schedules = append(schedules, o.Schedule.ID)
+		}
+	}
+	for _, id := range schedules {
+		all, err := list(url.Values{"schedule_ids[]": {id}})
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range all {
+			if o.Schedule != nil {
+				entries = append(entries, pagerDutyEntry(o.Schedule.Summary, o.User.Summary, o.User.ID == cfg.User, o.End))
+			}
+		}
+	}
+	return entries, nil
+}
+
+func pagerDutyEntry(schedule, user string, me bool, end string) onCallEntry {
+	e := onCallEntry{Schedule: schedule, User: user, Me: me}
+	e.End, _ = time.Parse(time.RFC3339, end)
+	return e
+}
+
+// opsgenieOnCall reads each of oncall.schedules' timeline for today; you are
+// oncall.user (your Opsgenie username, usually your email)
+func opsgenieOnCall(client *http.Client, cfg OnCallConfig, day time.Time) ([]onCallEntry, error) {
+	var entries []onCallEntry
+	for _, name := range cfg.Schedules {
+		var resp struct {
+			Data struct {
+				Parent struct {
+					Name string `json:"name"`
+				} `json:"_parent"`
+				FinalTimeline struct {
+					Rotations []struct {
+						Periods []struct {
+							StartDate time.Time `json:"startDate"`
+							EndDate   time.Time `json:"endDate"`
+							Recipient struct {
+								Type string `json:"type"`
+								Name string `json:"name"`
+							} `json:"recipient"`
+						} `json:"periods"`
+					} `json:"rotations"`
+				} `json:"finalTimeline"`
+			} `json:"data"`
+		}
+		u := onCallBase(cfg) + "/v2/schedules/" + url.PathEscape(name) + "/timeline?" + url.Values{
+			"identifierType": {"name"}, "interval": {"1"}, "intervalUnit": {"days"}, "date": {day.Format(time.RFC3339)},
+		}.Encode()
+		if err := onCallGet(client, u, "GenieKey "+cfg.APIKey, &resp); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		schedule := resp.Data.Parent.Name
+		if schedule == "" {
+			schedule = name
+		}
+		for _, r := range resp.Data.FinalTimeline.Rotations {
+			for _, p := range r.Periods {
+				if p.Recipient.Type != "user" || !p.EndDate.After(day) || !p.StartDate.Before(day.AddDate(0, 0, 1)) {
+					continue
+				}
+				me := strings.EqualFold(p.Recipient.Name, cfg.User)
+				entries = append(entries, onCallEntry{Schedule: schedule, User: p.Recipient.Name, Me: me, End: p.EndDate})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// onCallBase is oncall.url, or the provider's API
+func onCallBase(cfg OnCallConfig) string {
+	if cfg.URL != "" {
+		return strings.TrimRight(cfg.URL, "/")
+	}
+	if cfg.Provider == OnCallOpsgenie {
+		return opsgenieAPIURL
+	}
+	return pagerDutyAPIURL
+}
+
+func onCallGet(client *http.Client, u, auth string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("API status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// onCallUntil phrases a shift end against today: "18:00", "tomorrow 09:00",
+// or "Mon Jan 22 09:00"
+func onCallUntil(until, today string) string {
+	date, clock, ok := strings.Cut(until, " ")
+	switch {
+	case !ok:
+		return until
+	case date == today:
+		return clock
+	case date == addDays(today, 1):
+		return "tomorrow " + clock
+	}
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		return t.Format("Mon Jan 2") + " " + clock
+	}
+	return until
+}
+
+// onCallLine formats a schedule, e.g. "Platform primary: you until tomorrow
+// 09:00, with Alice Smith"
+func onCallLine(s OnCallSchedule, today string) string {
+	if !s.OnCall {
+		if len(s.Others) == 0 {
+			return s.Name + ": nobody"
+		}
+		return s.Name + ": " + strings.Join(s.Others, ", ")
+	}
+	line := s.Name + ": you"
+	if s.Until != "" {
+		line += " until " + onCallUntil(s.Until, today)
+	}
+	if len(s.Others) > 0 {
+		line += ", with " + strings.Join(s.Others, ", ")
+	}
+	return line
+}
+
+// onCallLines lists the schedules
+func onCallLines(d *OnCallData, today string) []string {
+	if d == nil {
+		return nil
+	}
+	lines := make([]string, len(d.Schedules))
+	for i, s := range d.Schedules {
+		lines[i] = onCallLine(s, today)
+	}
+	return lines
+}
+
+// onCallLoad raises the morning load a step while you're on call
+func onCallLoad(load string, d *OnCallData) string {
+	if d == nil || !d.OnCall {
+		return load
+	}
+	return raiseLoad(load)
+}
+
+// onCallNote is appended to the recommendation while you're on call: an
+// alert can land mid-workout, so keep training moderate and near your phone
+func onCallNote(d *OnCallData, today string) string {
+	if d == nil || !d.OnCall {
+		return ""
+	}
+	for _, s := range d.Schedules {
+		if s.OnCall {
+			until := ""
+			if s.Until != "" {
+				until = " until " + onCallUntil(s.Until, today)
+			}
+			return fmt.Sprintf(" On call (%s)%s: keep training moderate and close to your phone.", s.Name, until)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== ON-CALL TESTS ====================
+
+// fakePagerDuty has you (P1) on Platform until tomorrow 09:00 alongside
+// Alice, and directly on the DB escalation policy
+func fakePagerDuty(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oncalls" || r.Header.Get("Authorization") != "Token token=k" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		q := r.URL.Query()
+		if !strings.HasPrefix(q.Get("since"), "2024-01-15T00:00:00") || !strings.HasPrefix(q.Get("until"), "2024-01-16T00:00:00") {
+			t.Errorf("oncalls query = %s", r.URL.RawQuery)
+		}
+		switch {
+		case q.Get("user_ids[]") == "P1":
+			w.Write([]byte(`{"oncalls": [
+				{"user": {"id": "P1", "summary": "Jai"}, "schedule": {"id": "S1", "summary": "Platform"}, "end": "2024-01-16T09:00:00Z"},
+				{"user": {"id": "P1", "summary": "Jai"}, "schedule": null, "escalation_policy": {"summary": "DB"}, "end": null}]}`))
+		case q.Get("schedule_ids[]") == "S1":
+			w.Write([]byte(`{"oncalls": [
+				{"user": {"id": "P1", "summary": "Jai"}, "schedule": {"id": "S1", "summary": "Platform"}, "end": "2024-01-16T09:00:00Z"},
+				{"user": {"id": "P2", "summary": "Alice Smith"}, "schedule": {"id": "S1", "summary": "Platform"}, "end": "2024-01-16T09:00:00Z"}]}`))
+		case q.Get("schedule_ids[]") == "S2":
+			w.Write([]byte(`{"oncalls": [{"user": {"id": "P3", "summary": "Bob"}, "schedule": {"id": "S2", "summary": "Payments"}, "end": "2024-01-15T18:00:00Z"}]}`))
+		default:
+			w.Write([]byte(`{"oncalls": []}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetOnCallDataPagerDuty(t *testing.T) {
+	inZone(t, "UTC")
+	ts := fakePagerDuty(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.OnCall = OnCallConfig{Provider: OnCallPagerDuty, APIKey: "k", URL: ts.URL + "/", User: "P1", Schedules: []string{"S2"}}
+
+	b := &MorningBriefing{}
+	getOnCallData(b, "2024-01-15")
+	if b.OnCall == nil || !b.OnCall.OnCall || len(b.Errors) != 0 {
+		t.Fatalf("on call = %+v, errors %v", b.OnCall, b.Errors)
+	}
+	want := []string{"DB: you", "Payments: Bob", "Platform: you until tomorrow 09:00, with Alice Smith"}
+	if got := onCallLines(b.OnCall, "2024-01-15"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("onCallLines() = %q, want %q", got, want)
+	}
+
+	// A bad key is reported
+	settings.OnCall.APIKey = "wrong"
+	b = &MorningBriefing{}
+	getOnCallData(b, "2024-01-15")
+	if b.OnCall != nil || len(b.Errors) != 1 || b.Errors[0] != "on-call error: API status 401" {
+		t.Errorf("on call = %+v, errors %v", b.OnCall, b.Errors)
+	}
+
+	// Off without a key
+	settings.OnCall.APIKey = ""
+	b = &MorningBriefing{}
+	getOnCallData(b, "2024-01-15")
+	if b.OnCall != nil || len(b.Errors) != 0 {
+		t.Errorf("on call without a key = %+v, %v", b.OnCall, b.Errors)
+	}
+}
+
+func TestGetOnCallDataOpsgenie(t *testing.T) {
+	inZone(t, "UTC")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/schedules/sre/timeline" || r.Header.Get("Authorization") != "GenieKey k" || r.URL.Query().Get("identifierType") != "name" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {"_parent": {"name": "SRE"}, "finalTimeline": {"rotations": [{"periods": [
+			{"startDate": "2024-01-14T09:00:00Z", "endDate": "2024-01-15T09:00:00Z", "recipient": {"type": "user", "name": "alice@example.com"}},
+			{"startDate": "2024-01-15T09:00:00Z", "endDate": "2024-01-16T09:00:00Z", "recipient": {"type": "user", "name": "Jai@example.com"}},
+			{"startDate": "2024-01-16T09:00:00Z", "endDate": "2024-01-17T09:00:00Z", "recipient": {"type": "user", "name": "bob@example.com"}}]}]}}}`))
+	}))
+	t.Cleanup(ts.Close)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.OnCall = OnCallConfig{Provider: OnCallOpsgenie, APIKey: "k", URL: ts.URL, User: "jai@example.com", Schedules: []string{"sre"}}
+
+	b := &MorningBriefing{}
+	getOnCallData(b, "2024-01-15")
+	want := "SRE: you until tomorrow 09:00, with alice@example.com"
+	if got := onCallLines(b.OnCall, "2024-01-15"); len(got) != 1 || got[0] != want || len(b.Errors) != 0 {
+		t.Errorf("onCallLines() = %q, errors %v, want %q", got, b.Errors, want)
+	}
+
+	settings.OnCall.Schedules = []string{"missing"}
+	b = &MorningBriefing{}
+	getOnCallData(b, "2024-01-15")
+	if b.OnCall != nil || len(b.Errors) != 1 || b.Errors[0] != "on-call error: missing: API status 404" {
+		t.Errorf("on call = %+v, errors %v", b.OnCall, b.Errors)
+	}
+}
+
+func TestOnCallUntil(t *testing.T) {
+	tests := map[string]string{
+		"2024-01-15 18:00": "18:00",
+		"2024-01-16 09:00": "tomorrow 09:00",
+		"2024-01-22 09:00": "Mon Jan 22 09:00",
+	}
+	for until, want := range tests {
+		if got := onCallUntil(until, "2024-01-15"); got != want {
+			t.Errorf("onCallUntil(%q) = %q, want %q", until, got, want)
+		}
+	}
+}
+
+func TestValidateOnCall(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         OnCallConfig
+		expectError bool
+	}{
+		{"off", DefaultConfig().OnCall, false},
+		{"pagerduty", OnCallConfig{Provider: OnCallPagerDuty, APIKey: "k", User: "P1"}, false},
+		{"no user", OnCallConfig{Provider: OnCallPagerDuty, APIKey: "k"}, true},
+		{"opsgenie without schedules", OnCallConfig{Provider: OnCallOpsgenie, APIKey: "k", User: "me"}, true},
+		{"unknown provider", OnCallConfig{Provider: "victorops", APIKey: "k", User: "me"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateOnCall(tt.cfg); (err != nil) != tt.expectError {
+				t.Errorf("validateOnCall() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestOnCallClassify(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings = DefaultConfig()
+	onCall := &OnCallData{OnCall: true, Schedules: []OnCallSchedule{{Name: "Platform", OnCall: true, Until: "2024-01-16 09:00"}}}
+	b := &MorningBriefing{
+		TargetDate: "2024-01-15",
+		Sleep:      SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true},
+		OnCall:     onCall,
+	}
+	classify(b)
+	if b.Classification.MorningLoad != "LIGHT" {
+		t.Errorf("morning load = %s, want LIGHT (CLEAR raised while on call)", b.Classification.MorningLoad)
+	}
+	if !strings.Contains(b.Classification.Recommendation, " On call (Platform) until tomorrow 09:00: keep training moderate and close to your phone.") {
+		t.Errorf("recommendation = %q", b.Classification.Recommendation)
+	}
+
+	// Poor recovery keeps the note
+	b = &MorningBriefing{TargetDate: "2024-01-15", Vitals: VitalsData{HRV: ptr(30.0), HRVBaseline: ptr(50.0)}, OnCall: onCall}
+	classify(b)
+	if !strings.Contains(b.Classification.Recommendation, "On call (Platform)") {
+		t.Errorf("poor recovery recommendation = %q", b.Classification.Recommendation)
+	}
+
+	// Someone else's shift changes nothing
+	b = &MorningBriefing{TargetDate: "2024-01-15", OnCall: &OnCallData{Schedules: []OnCallSchedule{{Name: "Payments", Others: []string{"Bob"}}}}}
+	classify(b)
+	if b.Classification.MorningLoad != "CLEAR" || strings.Contains(b.Classification.Recommendation, "On call") {
+		t.Errorf("load %s, recommendation %q", b.Classification.MorningLoad, b.Classification.Recommendation)
+	}
+}
+
+func TestOnCallRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-01-15", OnCall: &OnCallData{OnCall: true, Schedules: []OnCallSchedule{
+		{Name: "Platform", OnCall: true, Until: "2024-01-15 18:00", Others: []string{"Alice Smith"}},
+	}}}
+	line := "Platform: you until 18:00, with Alice Smith"
+	if md := MorningMarkdown(m); !strings.Contains(md, "## On Call\n\n- "+line+"\n") {
+		t.Errorf("markdown missing on call:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "On call\n  "+line+"\n") {
+		t.Errorf("text missing on call:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "On call:\n- "+line+"\n") {
+		t.Errorf("prompt missing on call (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["oncall"] != true {
+		t.Errorf("oncall = %v", vars["oncall"])
+	}
+	if vars := morningRuleVars(MorningBriefing{}); vars["oncall"] != false {
+		t.Errorf("oncall without data = %v", vars["oncall"])
+	}
+	if r := RedactMorningBriefing(m); r.OnCall.Schedules[0].Others[0] == "Alice Smith" || m.OnCall.Schedules[0].Others[0] != "Alice Smith" {
+		t.Errorf("redacted on call = %+v", r.OnCall)
+	}
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// briefingPage is a briefing laid out as titled sections, shared by the
+// HTML email and chat deliveries
+type briefingPage struct {
+	Title    string
+	Lead     string
+	Sections []pageSection
+}
+
+type pageSection struct {
+	Title  string
+	Status string
+	Rows   []pageRow
+	Items  []pageItem
+	Empty  string // shown when there are no rows or items
+}
+
+type pageRow struct {
+	Label  string
+	Value  string
+	Status string
+}
+
+type pageItem struct {
+	Text  string
+	Alert bool
+}
+
+// sleepDebtValue formats the 7-night debt, adding the 14-night one when known
+func sleepDebtValue(s SleepData) string {
+	v := mdValue(s.SleepDebtHours, "%.1f h")
+	if s.SleepDebt14dHours != nil {
+		v += fmt.Sprintf(" (14 nights %.1f h)", *s.SleepDebt14dHours)
+	}
+	return v
+}
+
+// consistencyValue formats the sleep timing score and spreads, e.g. "82 (bed ±25 min, wake ±18 min)"
+func consistencyValue(s SleepData) string {
+	if s.ConsistencyScore == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%d (bed ±%.0f min, wake ±%.0f min)", *s.ConsistencyScore, *s.BedtimeSDMinutes, *s.WakeSDMinutes)
+}
+
+// temperatureValue formats the temperature with its deviation, e.g. "36.4 °C (+0.3 vs baseline)"
+func temperatureValue(v VitalsData) string {
+	value := mdValue(v.Temperature, "%.1f °C")
+	if v.TemperatureDeviation != nil {
+		value += fmt.Sprintf(" (%+.1f vs baseline)", *v.TemperatureDeviation)
+	}
+	return value
+}
+
+func pageEvents(events []CalendarEvent) []pageItem {
+	items := make([]pageItem, len(events))
+	for i, e := range events {
+		items[i] = pageItem{Text: e.Time + " " + eventSummary(e)}
+	}
+	return items
+}
+
+func pageMeds(m MedsData) []pageItem {
+	var items []pageItem
+	for _, name := range medNames(m.Overdue) {
+		items = append(items, pageItem{Text: name + " (overdue)", Alert: true})
+	}
+	for _, name := range medNames(m.DueToday) {
+		items = append(items, pageItem{Text: name})
+	}
+	for _, miss := range m.MissedRecently {
+		items = append(items, pageItem{Text: medMissesLine(miss), Alert: true})
+	}
+	for _, line := range reorderLines(m.Inventory) {
+		items = append(items, pageItem{Text: line, Alert: true})
+	}
+	return append(items, pageCycles(m.Cycles)...)
+}
+
+// pageCycles lists cycles, alerting on those about to change phase
+func pageCycles(cycles []CycleStatus) []pageItem {
+	var items []pageItem
+	for _, c := range cycles {
+		items = append(items, pageItem{Text: cycleLine(c), Alert: c.changingSoon()})
+	}
+	return items
+}
+
+// pageChanges lists moved metrics since yesterday, nil unless --compare was used
+func pageChanges(changes []Change) []pageSection {
+	if changes == nil {
+		return nil
+	}
+	var items []pageItem
+	for _, c := range changes {
+		if c.Trend == "same" {
+			continue
+		}
+		items = append(items, pageItem{Text: c.Summary, Alert: c.Trend == "worse"})
+	}
+	return []pageSection{{Title: "Since yesterday", Empty: "No changes", Items: items}}
+}
+
+// pageAlerts lists triggered rules and source errors, nil when there are none
+func pageAlerts(alerts []Alert, errs []string) []pageSection {
+	var items []pageItem
+	for _, a := range alerts {
+		items = append(items, pageItem{Text: fmt.Sprintf("[%s] %s", a.Severity, a.Rule), Alert: a.Severity == SeverityHigh})
+	}
+	for _, e := range errs {
+		items = append(items, pageItem{Text: e})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return []pageSection{{Title: "Alerts", Items: items}}
+}
+
+// morningPage lays out the morning briefing for the HTML email and chat deliveries
+func morningPage(m MorningBriefing) briefingPage {
+	readiness := "–"
+	if m.Classification.ReadinessScore != nil {
+		readiness = strconv.Itoa(*m.Classification.ReadinessScore)
+	}
+	recovery := pageSection{Title: "Recovery", Rows: []pageRow{
+		{Label: "Readiness", Value: readiness},
+		{Label: "Sleep", Value: mdValue(m.Sleep.TotalHours, "%.1f h"), Status: m.Classification.SleepQuality},
+		{Label: "HRV", Value: fmt.Sprintf("%s (baseline %s)", mdValue(m.Vitals.HRV, "%.0f ms"), mdValue(m.Vitals.HRVBaseline, "%.0f")), Status: m.Classification.RecoveryStatus},
+		{Label: "Resting HR", Value: mdValue(m.Vitals.RestingHR, "%.0f bpm") + rhrDeltaValue(m.Vitals), Status: rhrStatus(m.Vitals)},
+	}}
+	if m.Sleep.SleepDebtHours != nil {
+		recovery.Rows = append(recovery.Rows, pageRow{Label: "Sleep debt", Value: sleepDebtValue(m.Sleep)})
+	}
+	if m.Sleep.ConsistencyScore != nil {
+		recovery.Rows = append(recovery.Rows, pageRow{Label: "Consistency", Value: consistencyValue(m.Sleep), Status: m.Sleep.Consistency})
+	}
+	if m.Vitals.Temperature != nil {
+		recovery.Rows = append(recovery.Rows, pageRow{Label: "Temperature", Value: temperatureValue(m.Vitals)})
+	}
+	if bp := m.Vitals.BloodPressure; bp != nil {
+		recovery.Rows = append(recovery.Rows, pageRow{Label: "Blood pressure", Value: bpValue(bp), Status: bp.Status})
+	}
+	if m.Alcohol != nil {
+		recovery.Rows = append(recovery.Rows, pageRow{Label: "Alcohol", Value: alcoholValue(m.Alcohol)})
+	}
+	if m.Fasting != nil {
+		recovery.Rows = append(recovery.Rows, pageRow{Label: "Fasting", Value: fastingValue(m.Fasting)})
+	}
+	if len(m.Classification.IllnessSignals) > 0 {
+		recovery.Rows = append(recovery.Rows, pageRow{Label: "Illness risk", Value: joinSignals(m.Classification.IllnessSignals), Status: m.Classification.IllnessRisk})
+	}
+
+	sections := []pageSection{
+		recovery,
+		{Title: "Today", Status: m.Classification.MorningLoad, Empty: "Nothing scheduled",
+			Items: pageEvents(append(append([]CalendarEvent{}, m.Calendar.MorningEvents...), m.Calendar.AfternoonEvents...))},
+	}
+	for _, c := range m.Calendar.Conflicts {
+		sections[1].Items = append(sections[1].Items, pageItem{Text: "Conflict " + conflictLine(c), Alert: true})
+	}
+	if line := focusTimeLine(m.Calendar.FocusHours, settings.WorkHours); line != "" {
+		sections[1].Items = append(sections[1].Items, pageItem{Text: line})
+	}
+	if line := commuteLine(m.Calendar); line != "" {
+		sections[1].Items = append(sections[1].Items, pageItem{Text: line})
+	}
+	if line := overdueTasksLine(m.Tasks); line != "" {
+		sections[1].Items = append(sections[1].Items, pageItem{Text: line, Alert: m.Classification.TaskPressure == TaskPressureDebt})
+	}
+	if m.Weather != nil {
+		weather := pageSection{Title: "Weather", Items: []pageItem{{Text: weatherLine(m.Weather)}}}
+		if line := sunLine(m.Weather); line != "" {
+			weather.Items = append(weather.Items, pageItem{Text: line})
+		}
+		sections = append(sections, weather)
+	}
+	if aq := m.AirQuality; aq != nil {
+		sections = append(sections, pageSection{Title: "Air quality", Status: aq.Classification,
+			Items: []pageItem{{Text: airQualityLine(aq), Alert: airUnhealthy(aq, settings.AirQuality)}}})
+	}
+	if p := m.Pollen; p != nil {
+		sections = append(sections, pageSection{Title: "Pollen", Status: p.Level,
+			Items: []pageItem{{Text: pollenLine(p), Alert: p.Level == PollenHigh}}})
+	}
+	if len(m.Calendar.AllDayEvents) > 0 {
+		allDay := pageSection{Title: "All day"}
+		for _, e := range m.Calendar.AllDayEvents {
+			allDay.Items = append(allDay.Items, pageItem{Text: allDayLine(e), Alert: e.RaisesLoad})
+		}
+		sections = append(sections, allDay)
+	}
+	if m.Travel != nil {
+		travel := pageSection{Title: "Travel"}
+		for _, line := range tripLines(m.Travel.Trips, m.TargetDate) {
+			travel.Items = append(travel.Items, pageItem{Text: line})
+		}
+		sections = append(sections, travel)
+	}
+	if p := m.JetLagPlan; p != nil {
+		jetLag := pageSection{Title: "Jet lag", Items: []pageItem{{Text: jetLagHeadline(p, m.TargetDate)}}}
+		for _, line := range jetLagLines(p, m.TargetDate) {
+			jetLag.Items = append(jetLag.Items, pageItem{Text: line})
+		}
+		sections = append(sections, jetLag)
+	}
+	if lines := onCallLines(m.OnCall, m.TargetDate); len(lines) > 0 {
+		onCall := pageSection{Title: "On call"}
+		for i, line := range lines {
+			onCall.Items = append(onCall.Items, pageItem{Text: line, Alert: m.OnCall.Schedules[i].OnCall})
+		}
+		sections = append(sections, onCall)
+	}
+	if m.Focus != nil {
+		focus := pageSection{Title: "Focus"}
+		for _, t := range m.Focus.Tasks {
+			focus.Items = append(focus.Items, pageItem{Text: focusLine(t), Alert: t.Overdue})
+		}
+		if m.Focus.More > 0 {
+			focus.Items = append(focus.Items, pageItem{Text: fmt.Sprintf("+%d more", m.Focus.More)})
+		}
+		sections = append(sections, focus)
+	}
+	if m.Work != nil {
+		work := pageSection{Title: "Work", Items: []pageItem{{Text: workLine(m.Work)}}}
+		for _, line := range reviewLines(m.Work) {
+			work.Items = append(work.Items, pageItem{Text: "Review " + line})
+		}
+		for _, line := range failingLines(m.Work) {
+			work.Items = append(work.Items, pageItem{Text: "Failing " + line})
+		}
+		sections = append(sections, work)
+	}
+	if m.Slack != nil {
+		slack := pageSection{Title: "Slack"}
+		for _, w := range m.Slack.Workspaces {
+			slack.Items = append(slack.Items, pageItem{Text: slackLine(w), Alert: slackWarn(w, settings.Slack)})
+		}
+		sections = append(sections, slack)
+	}
+	if m.Mail != nil {
+		mailSection := pageSection{Title: "Mail", Items: []pageItem{{Text: mailSummary(m.Mail)}}}
+		for _, line := range mailLines(m.Mail) {
+			mailSection.Items = append(mailSection.Items, pageItem{Text: line})
+		}
+		sections = append(sections, mailSection)
+	}
+	if lines := headlineLines(m.News); len(lines) > 0 {
+		news := pageSection{Title: "News"}
+		for _, line := range lines {
+			news.Items = append(news.Items, pageItem{Text: line})
+		}
+		sections = append(sections, news)
+	}
+	if lines := quoteLines(m.Markets); len(lines) > 0 {
+		markets := pageSection{Title: "Markets"}
+		for _, line := range lines {
+			markets.Items = append(markets.Items, pageItem{Text: line})
+		}
+		sections = append(sections, markets)
+	}
+	sections = append(sections, pageSection{Title: "Meds", Empty: "None due", Items: pageMeds(m.Meds)})
+	if len(m.Supplements) > 0 {
+		supplements := pageSection{Title: "Supplements"}
+		for _, line := range supplementLines(m.Supplements) {
+			supplements.Items = append(supplements.Items, pageItem{Text: line})
+		}
+		sections = append(sections, supplements)
+	}
+	if g := m.Glucose; g != nil {
+		glucose := pageSection{Title: "Glucose", Rows: []pageRow{
+			{Label: "Fasting", Value: mdValue(g.Fasting, "%.0f mg/dL")},
+			{Label: "Overnight", Value: mdValue(g.OvernightAvg, "%.0f mg/dL")},
+			{Label: "In range yesterday", Value: mdValue(g.TimeInRange, "%.0f%%")},
+		}}
+		for _, spike := range g.Spikes {
+			glucose.Items = append(glucose.Items, pageItem{Text: spikeValue(spike), Alert: true})
+		}
+		sections = append(sections, glucose)
+	}
+	training := pageSection{Title: "Training"}
+	if m.Training.LastWorkout != nil {
+		training.Rows = append(training.Rows,
+			pageRow{Label: "Last workout", Value: fmt.Sprintf("%s, %d days ago", m.Training.LastWorkout.Title, m.Training.DaysSinceLast)},
+			pageRow{Label: "This week", Value: strconv.Itoa(m.Training.WeeklyCount)},
+		)
+	}
+	if m.Calendar.BestWorkoutSlot != nil {
+		training.Rows = append(training.Rows, pageRow{Label: "Workout slot", Value: workoutSlotValue(m.Calendar.BestWorkoutSlot)})
+	}
+	if m.Steps != nil {
+		training.Rows = append(training.Rows, pageRow{Label: "Steps yesterday", Value: stepsValue(m.Steps.Yesterday, m.Steps.Goal, m.Steps.Avg7d)})
+	}
+	if m.Training.VO2Max != nil {
+		training.Rows = append(training.Rows, pageRow{Label: "VO2max", Value: vo2MaxValue(m.Training.VO2Max)})
+	}
+	if m.Training.HRZones != nil {
+		training.Rows = append(training.Rows, pageRow{Label: "HR zones", Value: zonesValue(m.Training.HRZones)})
+	}
+	if m.Training.Zone2 != nil {
+		training.Rows = append(training.Rows, pageRow{Label: "Zone 2", Value: zone2Value(m.Training.Zone2)})
+	}
+	if len(training.Rows) > 0 {
+		sections = append(sections, training)
+	}
+	sections = append(sections, pageChanges(m.Changes)...)
+	sections = append(sections, pageAlerts(m.Alerts, m.Errors)...)
+
+	return briefingPage{Title: "Morning " + m.TargetDate, Lead: m.Classification.Recommendation, Sections: sections}
+}
+
+// middayPage lays out the midday check-in
+func middayPage(m MiddayBriefing) briefingPage {
+	sections := []pageSection{
+		{Title: "Intake so far", Rows: []pageRow{
+			{Label: "Eaten", Value: fmt.Sprintf("%.0f kcal", m.Energy.ConsumedKcal)},
+			{Label: "Active", Value: fmt.Sprintf("%.0f kcal", m.Energy.ActiveKcal)},
+			{Label: "Room left", Value: budgetValue(m.Energy.RemainingKcalToMaintenance, m.Energy.RemainingKcalToGoalDeficit)},
+			{Label: "Protein", Value: fmt.Sprintf("%.0f / %d g", m.Protein.ConsumedG, m.Protein.TargetG)},
+			{Label: "Water", Value: fmt.Sprintf("%.0f / %d ml", m.Hydration.ConsumedMl, m.Hydration.TargetMl)},
+			{Label: "Steps", Value: strconv.Itoa(m.Steps)},
+		}},
+		{Title: "Upcoming", Empty: "Nothing scheduled", Items: pageEvents(m.UpcomingEvents)},
+		{Title: "Meds", Empty: "None due", Items: pageMeds(m.Meds)},
+	}
+	sections = append(sections, pageAlerts(m.Alerts, m.Errors)...)
+	return briefingPage{Title: "Midday " + m.TargetDate, Sections: sections}
+}
+
+// eveningPage lays out the evening wrap-up
+func eveningPage(e EveningBriefing) briefingPage {
+	workout := "none"
+	if e.Activity.Workout != nil && e.Activity.Workout.Done {
+		workout = fmt.Sprintf("%s (%s)", e.Activity.Workout.Title, e.Activity.Workout.Duration)
+	}
+	var missed []pageItem
+	for _, name := range e.Protocols.Missed {
+		missed = append(missed, pageItem{Text: name, Alert: true})
+	}
+
+	sections := []pageSection{
+		{Title: "Energy", Rows: []pageRow{
+			{Label: "Balance", Value: fmt.Sprintf("%+d kcal (%s)", e.Energy.DeficitOrSurplusKcal, e.Energy.Status)},
+			{Label: "Eaten", Value: fmt.Sprintf("%.0f kcal", e.Energy.ConsumedKcal)},
+			{Label: "Burned", Value: fmt.Sprintf("%.0f kcal", e.Energy.TotalBurnedKcal)},
+			{Label: "Room left", Value: budgetValue(e.Energy.RemainingKcalToMaintenance, e.Energy.RemainingKcalToGoalDeficit)},
+			{Label: "Protein", Value: fmt.Sprintf("%.0f / %d g", e.Protein.ConsumedG, e.Protein.TargetG)},
+			{Label: "Water", Value: fmt.Sprintf("%.0f / %d ml", e.Hydration.ConsumedMl, e.Hydration.TargetMl)},
+		}},
+		{Title: "Activity", Rows: []pageRow{
+			{Label: "Steps", Value: activityStepsValue(e.Activity), Status: eveningStepsStatus(e.Activity)},
+			{Label: "Rings", Value: ringsValue(e.Rings)},
+			{Label: "Workout", Value: workout},
+		}},
+		{Title: "Missed protocols", Empty: "None", Items: missed},
+	}
+	if cycles := pageCycles(e.Protocols.Cycles); cycles != nil {
+		sections = append(sections, pageSection{Title: "Cycles", Items: cycles})
+	}
+	if e.Eating != nil {
+		sections[0].Rows = append(sections[0].Rows, pageRow{Label: "Eating window", Value: eatingWindowValue(e.Eating)})
+	}
+	if len(e.Meals) > 0 {
+		sections[0].Rows = append(sections[0].Rows, pageRow{Label: "Meals", Value: mealsValue(e.Meals, e.LateKcalPct)})
+	}
+	for _, m := range e.Micronutrients {
+		sections[0].Rows = append(sections[0].Rows, pageRow{Label: m.Label, Value: micronutrientValue(m), Status: m.Status})
+	}
+	if g := e.Energy.Goal; g != nil {
+		sections[0].Rows = append(sections[0].Rows, pageRow{Label: "Goal weight", Value: goalValue(g), Status: g.Pace})
+	}
+	if e.Weight != nil {
+		weight := pageSection{Title: "Weight", Rows: []pageRow{
+			{Label: "Current", Value: fmt.Sprintf("%.1f kg", e.Weight.CurrentKg)},
+			{Label: "Trend", Value: fmt.Sprintf("%.1f kg", e.Weight.TrendKg)},
+		}}
+		if e.Weight.ChangePerWeekKg != nil {
+			weight.Rows = append(weight.Rows, pageRow{Label: "Change", Value: fmt.Sprintf("%+.2f kg/week", *e.Weight.ChangePerWeekKg)})
+		}
+		if c := e.Weight.Composition; c != nil {
+			if c.BodyFatPct != nil {
+				weight.Rows = append(weight.Rows, pageRow{Label: "Body fat", Value: bodyFatValue(c)})
+			}
+			if c.LeanMassKg != nil {
+				weight.Rows = append(weight.Rows, pageRow{Label: "Lean mass", Value: leanMassValue(c)})
+			}
+		}
+		if line := compositionLine(e.Weight); line != "" {
+			weight.Rows = append(weight.Rows, pageRow{Label: "Change from", Value: line})
+		}
+		sections = append(sections, weight)
+	}
+	if e.Tomorrow.FirstEvent != nil {
+		sections = append(sections, pageSection{Title: "Tomorrow", Rows: []pageRow{
+			{Label: "First event", Value: e.Tomorrow.FirstEvent.Time + " " + e.Tomorrow.FirstEvent.Summary},
+			{Label: "Meds due", Value: strconv.Itoa(len(e.Tomorrow.MedsDue))},
+		}})
+	}
+	if lines := packingLines(e.Travel, e.TargetDate); lines != nil {
+		travel := pageSection{Title: "Travel"}
+		for _, line := range lines {
+			travel.Items = append(travel.Items, pageItem{Text: line, Alert: true}) // pushed to the phone
+		}
+		sections = append(sections, travel)
+	}
+	sections = append(sections, pageChanges(e.Changes)...)
+	sections = append(sections, pageAlerts(e.Alerts, e.Errors)...)
+	return briefingPage{Title: "Evening " + e.TargetDate, Sections: sections}
+}
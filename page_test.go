@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+// ==================== BRIEFING PAGE TESTS ====================
+
+func TestMorningPage(t *testing.T) {
+	b := MorningBriefing{
+		TargetDate: "2024-01-15",
+		Sleep:      SleepData{TotalHours: ptr(7.2), SleepDebtHours: ptr(1.5)},
+		Calendar:   CalendarData{MorningEvents: []CalendarEvent{{Time: "09:00", Summary: "Standup"}}},
+		Meds:       MedsData{Overdue: []MedTask{{Name: "Vitamin D"}}, DueToday: []MedTask{{Name: "Magnesium", DueTime: "21:00"}}},
+		Alerts:     []Alert{{Rule: "low-hrv", Severity: SeverityHigh}},
+		Classification: Classification{
+			SleepQuality:   "GOOD",
+			MorningLoad:    "LIGHT",
+			Recommendation: "Good to go",
+		},
+	}
+	p := morningPage(b)
+	if p.Title != "Morning 2024-01-15" || p.Lead != "Good to go" {
+		t.Errorf("title/lead = %q, %q", p.Title, p.Lead)
+	}
+
+	titles := make([]string, len(p.Sections))
+	for i, s := range p.Sections {
+		titles[i] = s.Title
+	}
+	want := []string{"Recovery", "Today", "Meds", "Alerts"}
+	if len(titles) != len(want) {
+		t.Fatalf("sections = %v, want %v", titles, want)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Errorf("section %d = %q, want %q", i, titles[i], want[i])
+		}
+	}
+
+	recovery := p.Sections[0]
+	if recovery.Rows[1].Value != "7.2 h" || recovery.Rows[1].Status != "GOOD" {
+		t.Errorf("sleep row = %+v", recovery.Rows[1])
+	}
+	if last := recovery.Rows[len(recovery.Rows)-1]; last.Label != "Sleep debt" || last.Value != "1.5 h" {
+		t.Errorf("last recovery row = %+v, want sleep debt", last)
+	}
+	if got := sleepDebtValue(SleepData{SleepDebtHours: ptr(1.5), SleepDebt14dHours: ptr(4)}); got != "1.5 h (14 nights 4.0 h)" {
+		t.Errorf("sleepDebtValue() = %q, want both windows", got)
+	}
+	if p.Sections[1].Status != "LIGHT" || p.Sections[1].Items[0].Text != "09:00 Standup" {
+		t.Errorf("today section = %+v", p.Sections[1])
+	}
+	meds := p.Sections[2].Items
+	if len(meds) != 2 || !meds[0].Alert || meds[0].Text != "Vitamin D (overdue)" || meds[1].Text != "Magnesium (21:00)" {
+		t.Errorf("meds = %+v", meds)
+	}
+	if !p.Sections[3].Items[0].Alert {
+		t.Error("high severity alert not marked")
+	}
+}
+
+func TestPageAlertsEmpty(t *testing.T) {
+	if got := pageAlerts(nil, nil); got != nil {
+		t.Errorf("pageAlerts(nil, nil) = %+v, want nil", got)
+	}
+}
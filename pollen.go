@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Pollen levels
+const (
+	PollenLow      = "LOW"
+	PollenModerate = "MODERATE"
+	PollenHigh     = "HIGH"
+)
+
+// pollenScale is each Open-Meteo pollen type's moderate and high counts in
+// grains/m³, from the National Allergy Bureau's tree, grass, and weed scales
+var pollenScale = []struct {
+	Name, Param    string
+	Moderate, High float64
+}{
+	{"alder", "alder_pollen", 15, 90},
+	{"birch", "birch_pollen", 15, 90},
+	{"olive", "olive_pollen", 15, 90},
+	{"grass", "grass_pollen", 5, 20},
+	{"mugwort", "mugwort_pollen", 10, 50},
+	{"ragweed", "ragweed_pollen", 10, 50},
+}
+
+// PollenData is today's pollen forecast for weather.location
+type PollenData struct {
+	Level string        `json:"level"` // the highest of types: LOW, MODERATE, HIGH
+	Types []PollenCount `json:"types"` // those in the air today, highest first
+}
+
+// PollenCount is one pollen type's peak for the day
+type PollenCount struct {
+	Name   string  `json:"name"`
+	Grains float64 `json:"grains"` // per m³, the day's highest hour
+	Level  string  `json:"level"`
+}
+
+// validatePollen checks that each configured type is one Open-Meteo reports
+func validatePollen(cfg PollenConfig) error {
+	for _, t := range cfg.Types {
+		if pollenType(t) < 0 {
+			return fmt.Errorf("pollen.types: unknown type %q (expected: alder, birch, olive, grass, mugwort, ragweed)", t)
+		}
+	}
+	return nil
+}
+
+// pollenType is name's index in pollenScale, or -1
+func pollenType(name string) int {
+	for i, s := range pollenScale {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// getPollenData fetches today's pollen from Open-Meteo when pollen.enabled
+// and weather.location are set
+func getPollenData(b *MorningBriefing, today string) {
+	cfg := settings.Pollen
+	if !cfg.Enabled || settings.Weather.Location == "" {
+		return
+	}
+	p, err := fetchPollen(cfg, settings.Weather.Location, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("pollen error: %v", err))
+		return
+	}
+	b.Pollen = p
+	if b.Weather != nil && p.Level == PollenHigh {
+		b.Weather.Training = WeatherIndoor // whatever the forecast
+	}
+}
+
+// fetchPollen asks Open-Meteo's air quality API for the day's hourly pollen
+// counts, which it only has for Europe
+func fetchPollen(cfg PollenConfig, location, date string) (*PollenData, error) {
+	client := &http.Client{Timeout: travelTimeout}
+	lat, lon, err := geocode(client, settings.Travel, location)
+	if err != nil {
+		return nil, err
+	}
+	var params []string
+	for _, s := range pollenScale {
+		if len(cfg.Types) == 0 || slices.Contains(cfg.Types, s.Name) {
+			params = append(params, s.Param)
+		}
+	}
+	var resp struct {
+		Hourly map[string]json.RawMessage `json:"hourly"` // and "time"
+	}
+	u := strings.TrimRight(cfg.URL, "/") + "/air-quality?" + url.Values{
+		"latitude":   {fmt.Sprintf("%f", lat)},
+		"longitude":  {fmt.Sprintf("%f", lon)},
+		"hourly":     {strings.Join(params, ",")},
+		"timezone":   {"auto"},
+		"start_date": {date},
+		"end_date":   {date},
+	}.Encode()
+	if err := travelGet(client, u, &resp); err != nil {
+		return nil, fmt.Errorf("forecast: %w", err)
+	}
+
+	p := &PollenData{Level: PollenLow}
+	found := false
+	for _, s := range pollenScale {
+		var values []*float64
+		if raw, ok := resp.Hourly[s.Param]; !ok || json.Unmarshal(raw, &values) != nil {
+			continue
+		}
+		peak, any := 0.0, false
+		for _, v := range values {
+			if v != nil {
+				peak, any = max(peak, *v), true
+			}
+		}
+		found = found || any
+		if peak <= 0 {
+			continue
+		}
+		level := PollenLow
+		switch {
+		case peak >= s.High:
+			level = PollenHigh
+		case peak >= s.Moderate:
+			level = PollenModerate
+		}
+		p.Types = append(p.Types, PollenCount{Name: s.Name, Grains: peak, Level: level})
+		if pollenRank(level) > pollenRank(p.Level) {
+			p.Level = level
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("forecast: no pollen data for this location")
+	}
+	slices.SortStableFunc(p.Types, func(a, b PollenCount) int {
+		if r := pollenRank(b.Level) - pollenRank(a.Level); r != 0 {
+			return r
+		}
+		switch {
+		case a.Grains > b.Grains:
+			return -1
+		case a.Grains < b.Grains:
+			return 1
+		}
+		return 0
+	})
+	return p, nil
+}
+
+// pollenRank orders levels
+func pollenRank(level string) int {
+	return slices.Index([]string{PollenLow, PollenModerate, PollenHigh}, level)
+}
+
+// pollenLine lists the types in the air, e.g.
+// "grass 120 grains/m³ (HIGH), birch 20 (MODERATE)"
+func pollenLine(p *PollenData) string {
+	if len(p.Types) == 0 {
+		return "None in the air"
+	}
+	parts := make([]string, len(p.Types))
+	for i, c := range p.Types {
+		parts[i] = fmt.Sprintf("%s %.0f (%s)", c.Name, c.Grains, c.Level)
+		if i == 0 {
+			parts[i] = fmt.Sprintf("%s %.0f grains/m³ (%s)", c.Name, c.Grains, c.Level)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pollenNote moves training indoors on a HIGH day
+func pollenNote(p *PollenData) string {
+	if p == nil || p.Level != PollenHigh || len(p.Types) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" High %s pollen (%.0f grains/m³): train indoors.", p.Types[0].Name, p.Types[0].Grains)
+}
+
+// pollenCauseNote points at a HIGH pollen day as a likely cause of a raised
+// respiratory rate or a poor night, rather than illness or overtraining
+func pollenCauseNote(p *PollenData, v VitalsData, sleepQuality string) string {
+	if p == nil || p.Level != PollenHigh {
+		return ""
+	}
+	var effects []string
+	if v.RespiratoryRateDelta != nil && *v.RespiratoryRateDelta >= IllnessRespiratoryDelta {
+		effects = append(effects, "the raised respiratory rate")
+	}
+	if sleepQuality == "POOR" {
+		effects = append(effects, "the poor sleep")
+	}
+	if len(effects) == 0 {
+		return ""
+	}
+	return " High pollen may explain " + joinSignals(effects) + "."
+}
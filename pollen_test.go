@@ -0,0 +1,186 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== POLLEN TESTS ====================
+
+// fakePollen serves hourly pollen counts for 2024-04-15
+func fakePollen(t *testing.T, hourly string) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/air-quality" || q.Get("latitude") != "52.520000" || q.Get("start_date") != "2024-04-15" || q.Get("end_date") != "2024-04-15" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"hourly": ` + hourly + `}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetPollenData(t *testing.T) {
+	ts := fakePollen(t, `{"time": ["2024-04-15T09:00", "2024-04-15T12:00", "2024-04-15T15:00"],
+		"alder_pollen": [0, 0, 0], "birch_pollen": [12.0, 40.5, 22.0], "grass_pollen": [8.0, 120.0, null],
+		"olive_pollen": [null, null, null], "mugwort_pollen": [0, 0, 0], "ragweed_pollen": [0, 1.0, 0]}`)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Weather.Location = "52.52,13.41"
+	settings.Pollen.Enabled = true
+	settings.Pollen.URL = ts.URL + "/"
+
+	b := &MorningBriefing{Weather: &WeatherData{HighC: 22, Training: WeatherOutdoor}}
+	getPollenData(b, "2024-04-15")
+	if b.Pollen == nil || len(b.Errors) != 0 {
+		t.Fatalf("pollen = %+v, errors %v", b.Pollen, b.Errors)
+	}
+	if got, want := pollenLine(b.Pollen), "grass 120 grains/m³ (HIGH), birch 40 (MODERATE), ragweed 1 (LOW)"; b.Pollen.Level != PollenHigh || got != want {
+		t.Errorf("pollen = %s %q, want HIGH %q", b.Pollen.Level, got, want)
+	}
+	if b.Weather.Training != WeatherIndoor {
+		t.Errorf("weather training = %s, want indoor on a high pollen day", b.Weather.Training)
+	}
+
+	// Outside Europe Open-Meteo has no pollen
+	ts = fakePollen(t, `{"time": ["2024-04-15T09:00"], "birch_pollen": [null], "grass_pollen": [null]}`)
+	settings.Pollen.URL = ts.URL
+	b = &MorningBriefing{}
+	getPollenData(b, "2024-04-15")
+	if b.Pollen != nil || len(b.Errors) != 1 || b.Errors[0] != "pollen error: forecast: no pollen data for this location" {
+		t.Errorf("pollen = %+v, errors %v", b.Pollen, b.Errors)
+	}
+
+	// Off unless enabled
+	settings.Pollen.Enabled = false
+	b = &MorningBriefing{}
+	getPollenData(b, "2024-04-15")
+	if b.Pollen != nil || len(b.Errors) != 0 {
+		t.Errorf("pollen when disabled = %+v, %v", b.Pollen, b.Errors)
+	}
+}
+
+func TestFetchPollenTypes(t *testing.T) {
+	var asked string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asked = r.URL.Query().Get("hourly")
+		w.Write([]byte(`{"hourly": {"birch_pollen": [95.0]}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	p, err := fetchPollen(PollenConfig{URL: ts.URL, Types: []string{"birch"}}, "52.52,13.41", "2024-04-15")
+	if err != nil || asked != "birch_pollen" {
+		t.Fatalf("asked for %q, err %v", asked, err)
+	}
+	if p.Level != PollenHigh || len(p.Types) != 1 || p.Types[0] != (PollenCount{Name: "birch", Grains: 95, Level: PollenHigh}) {
+		t.Errorf("pollen = %+v", p)
+	}
+}
+
+func TestPollenNotes(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings = DefaultConfig()
+	high := &PollenData{Level: PollenHigh, Types: []PollenCount{{Name: "grass", Grains: 120, Level: PollenHigh}}}
+	moderate := &PollenData{Level: PollenModerate, Types: []PollenCount{{Name: "birch", Grains: 40, Level: PollenModerate}}}
+	fine := &WeatherData{Conditions: "clear", HighC: 22}
+
+	if got, want := outdoorNote(fine, nil, high), " High grass pollen (120 grains/m³): train indoors."; got != want {
+		t.Errorf("outdoorNote(high) = %q, want %q", got, want)
+	}
+	if got, want := outdoorNote(fine, nil, moderate), " Good weather for an outdoor run."; got != want {
+		t.Errorf("outdoorNote(moderate) = %q, want %q", got, want)
+	}
+	unhealthy := &AirQualityData{AQI: 172, Classification: AirUnhealthy}
+	if got := outdoorNote(fine, unhealthy, high); !strings.HasPrefix(got, " Air quality is unhealthy") || strings.Contains(got, "pollen") {
+		t.Errorf("outdoorNote(unhealthy air) = %q, want the air quality note only", got)
+	}
+
+	raised := VitalsData{RespiratoryRateDelta: ptr(1.4)}
+	tests := []struct {
+		name     string
+		pollen   *PollenData
+		vitals   VitalsData
+		sleep    string
+		expected string
+	}{
+		{"no pollen", nil, raised, "POOR", ""},
+		{"moderate", moderate, raised, "POOR", ""},
+		{"nothing to explain", high, VitalsData{RespiratoryRateDelta: ptr(0.3)}, "GOOD", ""},
+		{"respiratory rate", high, raised, "GOOD", " High pollen may explain the raised respiratory rate."},
+		{"both", high, raised, "POOR", " High pollen may explain the raised respiratory rate and the poor sleep."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pollenCauseNote(tt.pollen, tt.vitals, tt.sleep); got != tt.expected {
+				t.Errorf("pollenCauseNote() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPollenRecommendation(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings = DefaultConfig()
+	b := &MorningBriefing{
+		Sleep:  SleepData{DataAvailable: true, IsCurrentDay: true, TotalHours: ptr(4.5)},
+		Pollen: &PollenData{Level: PollenHigh, Types: []PollenCount{{Name: "birch", Grains: 150, Level: PollenHigh}}},
+	}
+	classify(b)
+	if !strings.Contains(b.Classification.Recommendation, " High birch pollen (150 grains/m³): train indoors. High pollen may explain the poor sleep.") {
+		t.Errorf("recommendation = %q", b.Classification.Recommendation)
+	}
+}
+
+func TestPollenPoorRecoveryWithBadAir(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings = DefaultConfig()
+	b := &MorningBriefing{
+		Vitals:     VitalsData{HRV: ptr(30.0), HRVBaseline: ptr(50.0)},
+		AirQuality: &AirQualityData{AQI: 172, Classification: AirUnhealthy},
+		Pollen:     &PollenData{Level: PollenHigh, Types: []PollenCount{{Name: "grass", Grains: 120, Level: PollenHigh}}},
+	}
+	classify(b)
+	rec := b.Classification.Recommendation
+	if b.Classification.RecoveryStatus != "POOR" || strings.Count(rec, "train indoors") != 1 || !strings.Contains(rec, "Air quality is unhealthy (AQI 172)") {
+		t.Errorf("recovery %s, recommendation = %q, want only the air quality note", b.Classification.RecoveryStatus, rec)
+	}
+}
+
+func TestValidatePollen(t *testing.T) {
+	if err := validatePollen(DefaultConfig().Pollen); err != nil {
+		t.Errorf("default: %v", err)
+	}
+	if err := validatePollen(PollenConfig{Enabled: true, Types: []string{"grass", "birch"}}); err != nil {
+		t.Errorf("grass, birch: %v", err)
+	}
+	if err := validatePollen(PollenConfig{Enabled: true, Types: []string{"oak"}}); err == nil {
+		t.Error("expected an error for oak")
+	}
+}
+
+func TestPollenRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-04-15", Pollen: &PollenData{Level: PollenHigh,
+		Types: []PollenCount{{Name: "grass", Grains: 120, Level: PollenHigh}, {Name: "birch", Grains: 40, Level: PollenModerate}}}}
+	line := "grass 120 grains/m³ (HIGH), birch 40 (MODERATE)"
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Pollen (HIGH)\n\n"+line+"\n") {
+		t.Errorf("markdown missing pollen:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Pollen  HIGH  "+line+"\n") {
+		t.Errorf("text missing pollen:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Pollen: "+line+" (HIGH)\n") {
+		t.Errorf("prompt missing pollen (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["pollen"] != PollenHigh || vars["pollen.grass"] != 120.0 {
+		t.Errorf("pollen vars = %v, %v", vars["pollen"], vars["pollen.grass"])
+	}
+	if pollenLine(&PollenData{Level: PollenLow}) != "None in the air" {
+		t.Error("expected None in the air")
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// PromptDefaultMaxTokens is the default budget for the rendered prompt
+const PromptDefaultMaxTokens = 1500
+
+// promptCharsPerToken is the rough estimate used for budgeting; it avoids a
+// tokenizer dependency and errs toward shorter prompts
+const promptCharsPerToken = 4
+
+const promptTruncated = "\n[truncated to fit the token budget]\n"
+
+// promptData is what prompt templates render
+type promptData struct {
+	Mode     string
+	Briefing any    // MorningBriefing, MiddayBriefing, or EveningBriefing
+	Brief    bool   // set on the second pass when the full prompt is over budget
+	JSON     string // the briefing as compact JSON
+}
+
+var promptFuncs = template.FuncMap{
+	// val formats an optional metric, "unknown" when missing
+	"val": func(v *float64, format string) string {
+		if v == nil {
+			return "unknown"
+		}
+		return fmt.Sprintf(format, *v)
+	},
+	"join":      strings.Join,
+	"events":    eventLines,
+	"meds":      medLines,
+	"kinds":     medCategoriesLine,
+	"focus":     focusLines,
+	"overdue":   overdueTasksLine,
+	"allday":    allDayLines,
+	"conflicts": conflictLines,
+	"slot":      workoutSlotValue,
+	"focustime": focusTimeValue,
+	"commute":   commuteLine,
+	"weather":   weatherLine,
+	"air":       airQualityLine,
+	"sun":       sunLine,
+	"pollen":    pollenLine,
+	"work":      workLine,
+	"reviews":   reviewLines,
+	"failing":   failingLines,
+	"mail":      mailSummary,
+	"messages":  mailLines,
+	"slack":     slackLines,
+	"headlines": headlineLines,
+	"quotes":    quoteLines,
+	"oncall":    onCallLines,
+	"trips":     tripLines,
+	"packing":   packingLines,
+	"jetlag":    jetLagLines,
+	"shift":     jetLagHeadline,
+}
+
+// Built-in templates define "system" and "user"; templates from
+// prompt.templates replace them per mode
+var defaultPromptTemplates = map[string]string{
+	"morning": `{{define "system"}}You are a concise health and productivity coach. Using only the data provided, tell the user in a few sentences how recovered they are, how hard to push today, and what needs attention. Do not give medical advice.{{end}}
+{{define "user"}}{{with .Briefing}}Morning briefing for {{.TargetDate}}.
+Readiness: {{if .Classification.ReadinessScore}}{{.Classification.ReadinessScore}}/100{{else}}unknown{{end}}
+Sleep: {{val .Sleep.TotalHours "%.1f h"}} ({{.Classification.SleepQuality}}), deep {{val .Sleep.DeepHours "%.1f h"}}, REM {{val .Sleep.REMHours "%.1f h"}}
+HRV: {{val .Vitals.HRV "%.0f ms"}} vs baseline {{val .Vitals.HRVBaseline "%.0f ms"}} ({{.Classification.RecoveryStatus}}); resting HR {{val .Vitals.RestingHR "%.0f bpm"}}
+{{if .Tags}}Life events: {{join .Tags ", "}}
+{{end}}{{with .Weather}}Weather: {{weather .}} ({{.Training}} training)
+{{with sun .}}{{.}}
+{{end}}{{end}}{{with .AirQuality}}Air quality: {{air .}} ({{.Classification}})
+{{end}}{{with .Pollen}}Pollen: {{pollen .}} ({{.Level}})
+{{end}}Recommendation: {{.Classification.Recommendation}}
+Calendar ({{.Classification.MorningLoad}}): {{len .Calendar.MorningEvents}} morning, {{len .Calendar.AfternoonEvents}} afternoon events
+{{if not $.Brief}}{{range events .Calendar.MorningEvents}}- {{.}}
+{{end}}{{range events .Calendar.AfternoonEvents}}- {{.}}
+{{end}}{{end}}{{with .Calendar.FocusHours}}Focus time: {{focustime .}} within work hours
+{{end}}{{with commute .Calendar}}{{.}}
+{{end}}{{with conflicts .Calendar.Conflicts}}Conflicts:
+{{range .}}- {{.}}
+{{end}}{{end}}{{with allday .Calendar.AllDayEvents}}All day: {{join . "; "}}
+{{end}}{{with .Travel}}Travel: {{join (trips .Trips $.Briefing.TargetDate) "; "}}
+{{end}}{{with .JetLagPlan}}Jet lag plan ({{shift . $.Briefing.TargetDate}}):
+{{range jetlag . $.Briefing.TargetDate}}- {{.}}
+{{end}}{{end}}{{with overdue .Tasks}}{{.}} ({{$.Briefing.Classification.TaskPressure}})
+{{end}}{{with oncall .OnCall $.Briefing.TargetDate}}On call:
+{{range .}}- {{.}}
+{{end}}{{end}}{{with focus .Focus}}Priority tasks:
+{{range .}}- {{.}}
+{{end}}{{end}}{{with .Work}}Work: {{work .}}
+{{if not $.Brief}}{{range reviews .}}- review {{.}}
+{{end}}{{range failing .}}- failing {{.}}
+{{end}}{{end}}{{end}}{{with slack .Slack}}Slack:
+{{range .}}- {{.}}
+{{end}}{{end}}{{with .Mail}}Mail: {{mail .}}
+{{if not $.Brief}}{{range messages .}}- {{.}}
+{{end}}{{end}}{{end}}{{if not $.Brief}}{{with headlines .News}}Headlines:
+{{range .}}- {{.}}
+{{end}}{{end}}{{end}}{{with quotes .Markets}}Markets: {{join . "; "}}
+{{end}}Meds: {{len .Meds.Overdue}} overdue, {{len .Meds.DueToday}} due today
+{{with kinds .Meds.Categories}}By kind: {{.}}
+{{end}}{{if not $.Brief}}{{range meds .Meds}}- {{.}}
+{{end}}{{end}}{{if .Training.LastWorkout}}Last workout: {{.Training.LastWorkout.Title}}, {{.Training.DaysSinceLast}} days ago ({{.Training.WeeklyCount}} this week)
+{{end}}{{with .Calendar.BestWorkoutSlot}}Best workout slot: {{slot .}}
+{{end}}{{range .Alerts}}Alert [{{.Severity}}]: {{.Rule}}
+{{end}}{{end}}{{end}}`,
+
+	"midday": `{{define "system"}}You are a concise nutrition and habits coach. Using only the data provided, say in two or three sentences what the user should focus on for the rest of the day.{{end}}
+{{define "user"}}{{with .Briefing}}Midday check-in for {{.TargetDate}}.
+Eaten: {{printf "%.0f" .Energy.ConsumedKcal}} kcal, active {{printf "%.0f" .Energy.ActiveKcal}} kcal
+Protein: {{printf "%.0f" .Protein.ConsumedG}} of {{.Protein.TargetG}} g
+Water: {{printf "%.0f" .Hydration.ConsumedMl}} of {{.Hydration.TargetMl}} ml
+Steps: {{.Steps}}
+Upcoming: {{len .UpcomingEvents}} events
+{{if not $.Brief}}{{range events .UpcomingEvents}}- {{.}}
+{{end}}{{end}}Meds: {{len .Meds.Overdue}} overdue, {{len .Meds.DueToday}} due
+{{if not $.Brief}}{{range meds .Meds}}- {{.}}
+{{end}}{{end}}{{end}}{{end}}`,
+
+	"evening": `{{define "system"}}You are a concise health coach. Using only the data provided, summarize the day in a few sentences and suggest one thing to do before bed or tomorrow.{{end}}
+{{define "user"}}{{with .Briefing}}Evening wrap-up for {{.TargetDate}}.
+Energy: {{printf "%+d" .Energy.DeficitOrSurplusKcal}} kcal ({{.Energy.Status}}); eaten {{printf "%.0f" .Energy.ConsumedKcal}}, burned {{printf "%.0f" .Energy.TotalBurnedKcal}}
+Protein: {{printf "%.0f" .Protein.ConsumedG}} of {{.Protein.TargetG}} g
+Water: {{printf "%.0f" .Hydration.ConsumedMl}} of {{.Hydration.TargetMl}} ml
+Steps: {{.Activity.Steps}}{{if .Activity.Workout}}{{if .Activity.Workout.Done}}; workout {{.Activity.Workout.Title}} ({{.Activity.Workout.Duration}}){{end}}{{end}}
+Protocols: {{len .Protocols.Completed}} completed, {{len .Protocols.Missed}} missed
+{{with kinds .Protocols.Categories}}By kind: {{.}} (a missed medication or injection matters more than a missed supplement)
+{{end}}{{if not $.Brief}}{{range .Protocols.Missed}}- missed: {{.}}
+{{end}}{{end}}{{if .Tomorrow.FirstEvent}}Tomorrow starts {{.Tomorrow.FirstEvent.Time}} {{.Tomorrow.FirstEvent.Summary}}
+{{end}}{{range packing .Travel .TargetDate}}{{.}}
+{{end}}{{end}}{{end}}`,
+}
+
+var promptModes = []string{"morning", "midday", "evening"}
+
+func validatePrompt(cfg PromptConfig) error {
+	if cfg.MaxTokens < 0 {
+		return fmt.Errorf("prompt.max_tokens must not be negative")
+	}
+	for mode := range cfg.Templates {
+		if !slices.Contains(promptModes, mode) {
+			return fmt.Errorf("prompt.templates: unknown mode %q (expected: %s)", mode, strings.Join(promptModes, ", "))
+		}
+	}
+	return nil
+}
+
+// loadPromptTemplate parses the configured template for mode, or the
+// built-in one. Relative paths are resolved against the config directory.
+func loadPromptTemplate(cfg PromptConfig, mode string) (*template.Template, error) {
+	text := defaultPromptTemplates[mode]
+	if path := cfg.Templates[mode]; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(getConfigPath()), path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("prompt template error: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New(mode).Funcs(promptFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("prompt template error: %w", err)
+	}
+	for _, name := range []string{"system", "user"} {
+		if tmpl.Lookup(name) == nil {
+			return nil, fmt.Errorf("prompt template error: %s template must define %q", mode, name)
+		}
+	}
+	return tmpl, nil
+}
+
+func estimateTokens(s string) int {
+	return (len(s) + promptCharsPerToken - 1) / promptCharsPerToken
+}
+
+func renderPromptSection(tmpl *template.Template, name string, data promptData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.ExecuteTemplate(&b, name, data); err != nil {
+		return "", fmt.Errorf("prompt template error: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// RenderPrompt renders the system and user sections for a briefing within
+// the token budget: when the full prompt is too long it is re-rendered with
+// .Brief set, and if that is still too long the user section is cut at a
+// line boundary
+func RenderPrompt(cfg PromptConfig, mode string, briefing any) (string, error) {
+	tmpl, err := loadPromptTemplate(cfg, mode)
+	if err != nil {
+		return "", err
+	}
+	budget := cfg.MaxTokens
+	if budget == 0 {
+		budget = PromptDefaultMaxTokens
+	}
+	raw, _ := json.Marshal(briefing)
+	data := promptData{Mode: mode, Briefing: briefing, JSON: string(raw)}
+
+	var system, user string
+	for _, brief := range []bool{false, true} {
+		data.Brief = brief
+		if system, err = renderPromptSection(tmpl, "system", data); err != nil {
+			return "", err
+		}
+		if user, err = renderPromptSection(tmpl, "user", data); err != nil {
+			return "", err
+		}
+		if estimateTokens(system)+estimateTokens(user) <= budget {
+			return formatPrompt(system, user), nil
+		}
+	}
+
+	room := (budget-estimateTokens(system))*promptCharsPerToken - len(promptTruncated)
+	if room <= 0 {
+		return "", fmt.Errorf("prompt.max_tokens %d is too small for the %s system prompt", budget, mode)
+	}
+	chunks := splitLines(user, room)
+	return formatPrompt(system, strings.TrimRight(chunks[0], "\n")+promptTruncated), nil
+}
+
+func formatPrompt(system, user string) string {
+	return "### System\n\n" + system + "\n\n### User\n\n" + strings.TrimRight(user, "\n") + "\n"
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ==================== PROMPT TESTS ====================
+
+func TestRenderPromptDefaults(t *testing.T) {
+	tests := []struct {
+		mode     string
+		briefing any
+		want     []string
+	}{
+		{"morning", MorningBriefing{
+			TargetDate: "2024-01-15",
+			Sleep:      SleepData{TotalHours: ptr(6.2)},
+			Calendar:   CalendarData{MorningEvents: []CalendarEvent{{Time: "09:00", Summary: "Standup"}}},
+			Meds:       MedsData{Overdue: []MedTask{{Name: "Vitamin D"}}},
+			Classification: Classification{
+				SleepQuality:   "OK",
+				ReadinessScore: intPtr(64),
+				Recommendation: "Moderate day",
+			},
+		}, []string{"Readiness: 64/100", "Sleep: 6.2 h (OK)", "HRV: unknown", "- 09:00 Standup", "- **Overdue:** Vitamin D", "Recommendation: Moderate day"}},
+		{"midday", MiddayBriefing{TargetDate: "2024-01-15", Steps: 4200, Protein: ProteinData{ConsumedG: 60, TargetG: 140}}, []string{"Protein: 60 of 140 g", "Steps: 4200"}},
+		{"evening", EveningBriefing{TargetDate: "2024-01-15", Energy: EnergyData{DeficitOrSurplusKcal: -300, Status: "deficit"}, Protocols: ProtocolsData{Missed: []string{"Stretch"}}}, []string{"Energy: -300 kcal (deficit)", "- missed: Stretch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := RenderPrompt(PromptConfig{}, tt.mode, tt.briefing)
+			if err != nil {
+				t.Fatalf("RenderPrompt() error: %v", err)
+			}
+			if !strings.HasPrefix(got, "### System\n\nYou are") || !strings.Contains(got, "\n\n### User\n\n") {
+				t.Errorf("missing sections:\n%s", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("prompt missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPromptBudget(t *testing.T) {
+	var events []CalendarEvent
+	for range 40 {
+		events = append(events, CalendarEvent{Time: "10:00", Summary: "A fairly long meeting title to use up tokens"})
+	}
+	b := MorningBriefing{TargetDate: "2024-01-15", Calendar: CalendarData{AfternoonEvents: events}}
+
+	full, _ := RenderPrompt(PromptConfig{MaxTokens: 5000}, "morning", b)
+	if !strings.Contains(full, "- 10:00 A fairly long") {
+		t.Fatal("full prompt should list events")
+	}
+
+	// Over budget: the brief pass drops the event list but keeps the counts
+	brief, err := RenderPrompt(PromptConfig{MaxTokens: 250}, "morning", b)
+	if err != nil {
+		t.Fatalf("RenderPrompt() error: %v", err)
+	}
+	if strings.Contains(brief, "- 10:00") || !strings.Contains(brief, "40 afternoon events") {
+		t.Errorf("brief prompt should drop events but keep counts:\n%s", brief)
+	}
+	if estimateTokens(brief) > 250 {
+		t.Errorf("brief prompt is ~%d tokens, want <= 250", estimateTokens(brief))
+	}
+
+	// Still over budget: the user section is cut
+	cut, err := RenderPrompt(PromptConfig{MaxTokens: 100}, "morning", b)
+	if err != nil {
+		t.Fatalf("RenderPrompt() error: %v", err)
+	}
+	if !strings.Contains(cut, "[truncated to fit the token budget]") || estimateTokens(cut) > 100 {
+		t.Errorf("cut prompt (~%d tokens):\n%s", estimateTokens(cut), cut)
+	}
+
+	if _, err := RenderPrompt(PromptConfig{MaxTokens: 10}, "morning", b); err == nil {
+		t.Error("RenderPrompt() with a budget smaller than the system prompt = nil error")
+	}
+}
+
+func TestRenderPromptCustomTemplate(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("BRIEFING_CONFIG", filepath.Join(dir, "config.json"))
+	tmpl := `{{define "system"}}Be brief.{{end}}{{define "user"}}{{.Mode}} {{.Briefing.TargetDate}} {{.JSON}}{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "evening.tmpl"), []byte(tmpl), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := PromptConfig{Templates: map[string]string{"evening": "evening.tmpl"}}
+	got, err := RenderPrompt(cfg, "evening", EveningBriefing{TargetDate: "2024-01-15"})
+	if err != nil {
+		t.Fatalf("RenderPrompt() error: %v", err)
+	}
+	if !strings.Contains(got, "### System\n\nBe brief.") || !strings.Contains(got, `evening 2024-01-15 {"mode":`) {
+		t.Errorf("custom prompt:\n%s", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "bad.tmpl"), []byte(`{{define "user"}}x{{end}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Templates["evening"] = "bad.tmpl"
+	if _, err := RenderPrompt(cfg, "evening", EveningBriefing{}); err == nil || !strings.Contains(err.Error(), `"system"`) {
+		t.Errorf("RenderPrompt() without system template = %v", err)
+	}
+	cfg.Templates["evening"] = "missing.tmpl"
+	if _, err := RenderPrompt(cfg, "evening", EveningBriefing{}); err == nil {
+		t.Error("RenderPrompt() with missing file = nil error")
+	}
+}
+
+func TestValidatePrompt(t *testing.T) {
+	if err := validatePrompt(PromptConfig{MaxTokens: -1}); err == nil {
+		t.Error("negative max_tokens = nil error")
+	}
+	if err := validatePrompt(PromptConfig{Templates: map[string]string{"weekly": "w.tmpl"}}); err == nil {
+		t.Error("weekly template = nil error")
+	}
+	if err := validatePrompt(PromptConfig{Templates: map[string]string{"morning": "m.tmpl"}}); err != nil {
+		t.Errorf("validatePrompt() = %v", err)
+	}
+}
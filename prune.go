@@ -0,0 +1,142 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pruneInterval is how often serve applies the retention policy
+const pruneInterval = 24 * time.Hour
+
+// PruneStats summarizes a prune run
+type PruneStats struct {
+	HistoryRows int64
+	Dumps       int
+}
+
+// RunPruneCommand handles `briefing prune [--dry-run]`
+func RunPruneCommand(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "Report what would be removed without deleting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	stats, err := pruneData(cfg.Retention, getHistoryDBPath(), getDumpDir(), time.Now(), *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Pruned"
+	if *dryRun {
+		verb = "Would prune"
+	}
+	fmt.Printf("%s %d history rows, %d dumps\n", verb, stats.HistoryRows, stats.Dumps)
+	return nil
+}
+
+// pruneData applies the retention policy to the history store and dump dir.
+// Missing stores are skipped rather than created.
+func pruneData(r RetentionConfig, historyPath, dumpDir string, now time.Time, dryRun bool) (PruneStats, error) {
+	var stats PruneStats
+
+	if r.HistoryDays > 0 {
+		if _, err := os.Stat(historyPath); err == nil {
+			db, err := openHistoryDB(historyPath)
+			if err != nil {
+				return stats, err
+			}
+			defer db.Close()
+
+			cutoff := now.AddDate(0, 0, -r.HistoryDays).Format("2006-01-02")
+			stats.HistoryRows, err = pruneHistory(db, cutoff, dryRun)
+			if err != nil {
+				return stats, err
+			}
+		}
+	}
+
+	if r.DumpDays > 0 {
+		n, err := pruneDumps(dumpDir, now.AddDate(0, 0, -r.DumpDays), dryRun)
+		if err != nil {
+			return stats, err
+		}
+		stats.Dumps = n
+	}
+	return stats, nil
+}
+
+// pruneHistory deletes briefings dated before cutoff (YYYY-MM-DD)
+func pruneHistory(db *sql.DB, cutoff string, dryRun bool) (int64, error) {
+	if dryRun {
+		var n int64
+		err := db.QueryRow(`SELECT COUNT(*) FROM briefings WHERE date < ?`, cutoff).Scan(&n)
+		if err != nil {
+			return 0, fmt.Errorf("history prune error: %w", err)
+		}
+		return n, nil
+	}
+
+	res, err := db.Exec(`DELETE FROM briefings WHERE date < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("history prune error: %w", err)
+	}
+	if _, err := db.Exec(`DELETE FROM med_log WHERE date < ?`, cutoff); err != nil {
+		return 0, fmt.Errorf("med log prune error: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// pruneDumps removes dump files last modified before cutoff
+func pruneDumps(dir string, cutoff time.Time, dryRun bool) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("dump prune error: %w", err)
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+				return removed, fmt.Errorf("dump prune error: %w", err)
+			}
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// pruneLoop applies the retention policy now and then every pruneInterval
+func pruneLoop(r RetentionConfig) {
+	for {
+		stats, err := pruneData(r, getHistoryDBPath(), getDumpDir(), time.Now(), false)
+		if err != nil {
+			log.Printf("prune error: %v", err)
+		} else if stats.HistoryRows > 0 || stats.Dumps > 0 {
+			log.Printf("pruned %d history rows, %d dumps", stats.HistoryRows, stats.Dumps)
+		}
+		time.Sleep(pruneInterval)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ==================== RETENTION TESTS ====================
+
+func TestPruneHistory(t *testing.T) {
+	db := newTestHistoryDB(t)
+	for _, date := range []string{"2021-12-31", "2022-01-01", "2024-01-15"} {
+		r := HistoryRecord{Date: date, Mode: "morning", Source: HistorySourceLive, JSON: []byte("{}")}
+		if _, err := saveHistoryRecord(db, r, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := pruneHistory(db, "2022-01-01", true)
+	if err != nil || n != 1 {
+		t.Errorf("dry run = %d, %v; want 1, nil", n, err)
+	}
+
+	n, err = pruneHistory(db, "2022-01-01", false)
+	if err != nil || n != 1 {
+		t.Errorf("pruneHistory() = %d, %v; want 1, nil", n, err)
+	}
+
+	var remaining int
+	db.QueryRow(`SELECT COUNT(*) FROM briefings`).Scan(&remaining)
+	if remaining != 2 {
+		t.Errorf("remaining rows = %d, want 2", remaining)
+	}
+}
+
+func TestPruneDumps(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	files := map[string]time.Time{
+		"old.json":    now.AddDate(0, 0, -20),
+		"recent.json": now.AddDate(0, 0, -2),
+	}
+	for name, mtime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := pruneDumps(dir, now.AddDate(0, 0, -14), false)
+	if err != nil || n != 1 {
+		t.Fatalf("pruneDumps() = %d, %v; want 1, nil", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.json")); !os.IsNotExist(err) {
+		t.Error("old.json was not removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "recent.json")); err != nil {
+		t.Error("recent.json was removed")
+	}
+
+	// Missing directory is not an error
+	if n, err := pruneDumps(filepath.Join(dir, "missing"), now, false); err != nil || n != 0 {
+		t.Errorf("missing dir = %d, %v; want 0, nil", n, err)
+	}
+}
+
+func TestPruneDataSkipsMissingHistory(t *testing.T) {
+	dir := t.TempDir()
+	historyPath := filepath.Join(dir, "history.db")
+
+	stats, err := pruneData(DefaultConfig().Retention, historyPath, filepath.Join(dir, "dumps"), time.Now(), false)
+	if err != nil {
+		t.Fatalf("pruneData() error: %v", err)
+	}
+	if stats != (PruneStats{}) {
+		t.Errorf("stats = %+v, want zero", stats)
+	}
+	if _, err := os.Stat(historyPath); !os.IsNotExist(err) {
+		t.Error("pruneData() created a history store")
+	}
+}
+
+func TestPruneDataZeroKeepsForever(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := openHistoryDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := HistoryRecord{Date: "2001-01-01", Mode: "morning", Source: HistorySourceBackfill, JSON: []byte("{}")}
+	if _, err := saveHistoryRecord(db, r, false); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := pruneData(RetentionConfig{}, path, t.TempDir(), time.Now(), false)
+	if err != nil || stats.HistoryRows != 0 {
+		t.Errorf("pruneData() = %+v, %v; want nothing pruned", stats, err)
+	}
+
+	stats, err = pruneData(RetentionConfig{HistoryDays: 30}, path, t.TempDir(), time.Now(), false)
+	if err != nil || stats.HistoryRows != 1 {
+		t.Errorf("pruneData() = %+v, %v; want 1 row pruned", stats, err)
+	}
+}
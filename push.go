@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultNtfyServer is used when delivery.ntfy.server is empty
+const defaultNtfyServer = "https://ntfy.sh"
+
+// pushoverAPIURL is the Pushover messages endpoint (swapped out in tests)
+var pushoverAPIURL = "https://api.pushover.net/1/messages.json"
+
+// pushNotification is the short form of a briefing for a phone
+type pushNotification struct {
+	Title   string
+	Message string
+	Urgent  bool // something is flagged: overdue meds, high alerts
+}
+
+// pushFor reduces a delivery to its recommendation and critical flags,
+// falling back to the subject when there is neither
+func pushFor(d Delivery) pushNotification {
+	n := pushNotification{Title: d.Subject}
+	var lines []string
+	if d.Page.Lead != "" {
+		lines = append(lines, d.Page.Lead)
+	}
+	for _, sec := range d.Page.Sections {
+		for _, item := range sec.Items {
+			if item.Alert {
+				lines = append(lines, "⚠ "+item.Text)
+				n.Urgent = true
+			}
+		}
+	}
+	n.Message = strings.Join(lines, "\n")
+	if n.Message == "" {
+		n.Message = d.Subject
+	}
+	return n
+}
+
+func validateNtfy(cfg NtfyConfig) error {
+	if cfg.Topic == "" {
+		return errors.New("delivery.ntfy: topic is required")
+	}
+	return nil
+}
+
+func validatePushover(cfg PushoverConfig) error {
+	if cfg.Token == "" || cfg.User == "" {
+		return errors.New("delivery.pushover: token and user are required")
+	}
+	return nil
+}
+
+// sendNtfy publishes the push to an ntfy topic, high priority when flagged
+func sendNtfy(cfg NtfyConfig, d Delivery) error {
+	if err := validateNtfy(cfg); err != nil {
+		return err
+	}
+	server := cfg.Server
+	if server == "" {
+		server = defaultNtfyServer
+	}
+	n := pushFor(d)
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(server, "/")+"/"+url.PathEscape(cfg.Topic), strings.NewReader(n.Message))
+	if err != nil {
+		return errors.New("invalid server URL")
+	}
+	req.Header.Set("Title", n.Title)
+	req.Header.Set("Tags", d.Mode)
+	if n.Urgent {
+		req.Header.Set("Priority", "high")
+		req.Header.Set("Tags", d.Mode+",warning")
+	}
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+	return doDelivery(req)
+}
+
+// sendPushover sends the push through the Pushover API, high priority when flagged
+func sendPushover(cfg PushoverConfig, d Delivery) error {
+	if err := validatePushover(cfg); err != nil {
+		return err
+	}
+	n := pushFor(d)
+	form := url.Values{
+		"token":   {cfg.Token},
+		"user":    {cfg.User},
+		"title":   {n.Title},
+		"message": {n.Message},
+	}
+	if n.Urgent {
+		form.Set("priority", "1")
+	}
+	req, err := http.NewRequest(http.MethodPost, pushoverAPIURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doDelivery(req)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// ==================== PUSH TESTS ====================
+
+func TestPushFor(t *testing.T) {
+	morning := morningDelivery(MorningBriefing{
+		TargetDate:     "2024-01-15",
+		Meds:           MedsData{Overdue: []MedTask{{Name: "Vitamin D"}}, DueToday: []MedTask{{Name: "Magnesium"}}},
+		Alerts:         []Alert{{Rule: "low-hrv", Severity: SeverityHigh}, {Rule: "short-sleep", Severity: SeverityLow}},
+		Classification: Classification{Recommendation: "Take it easy"},
+	})
+	n := pushFor(morning)
+	if n.Title != "Morning briefing 2024-01-15" || !n.Urgent {
+		t.Errorf("title/urgent = %q, %v", n.Title, n.Urgent)
+	}
+	if want := "Take it easy\n⚠ Vitamin D (overdue)\n⚠ [high] low-hrv"; n.Message != want {
+		t.Errorf("Message = %q, want %q", n.Message, want)
+	}
+
+	evening := eveningDelivery(EveningBriefing{TargetDate: "2024-01-15", Energy: EnergyData{DeficitOrSurplusKcal: -250}})
+	n = pushFor(evening)
+	if n.Urgent || n.Message != "Evening wrap-up 2024-01-15: -250 kcal" {
+		t.Errorf("quiet evening push = %+v, want subject and normal priority", n)
+	}
+}
+
+func TestValidatePush(t *testing.T) {
+	if err := validateNtfy(NtfyConfig{}); err == nil {
+		t.Error("validateNtfy() without topic = nil, want error")
+	}
+	if err := validateNtfy(NtfyConfig{Topic: "briefing"}); err != nil {
+		t.Errorf("validateNtfy() = %v", err)
+	}
+	if err := validatePushover(PushoverConfig{Token: "app"}); err == nil {
+		t.Error("validatePushover() without user = nil, want error")
+	}
+	if err := validatePushover(PushoverConfig{Token: "app", User: "me"}); err != nil {
+		t.Errorf("validatePushover() = %v", err)
+	}
+}
+
+func TestSendNtfy(t *testing.T) {
+	var path, body string
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		header = r.Header
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer ts.Close()
+
+	d := morningDelivery(MorningBriefing{
+		TargetDate:     "2024-01-15",
+		Meds:           MedsData{Overdue: []MedTask{{Name: "Vitamin D"}}},
+		Classification: Classification{Recommendation: "Take it easy"},
+	})
+	if err := sendNtfy(NtfyConfig{Server: ts.URL + "/", Topic: "my-briefing", Token: "tk_123"}, d); err != nil {
+		t.Fatalf("sendNtfy() error: %v", err)
+	}
+	if path != "/my-briefing" || body != "Take it easy\n⚠ Vitamin D (overdue)" {
+		t.Errorf("path/body = %q, %q", path, body)
+	}
+	tests := map[string]string{
+		"Title":         "Morning briefing 2024-01-15",
+		"Priority":      "high",
+		"Tags":          "morning,warning",
+		"Authorization": "Bearer tk_123",
+	}
+	for k, want := range tests {
+		if got := header.Get(k); got != want {
+			t.Errorf("header %s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestSendPushover(t *testing.T) {
+	var form url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.Write([]byte(`{"status":1}`))
+	}))
+	defer ts.Close()
+	saved := pushoverAPIURL
+	pushoverAPIURL = ts.URL
+	t.Cleanup(func() { pushoverAPIURL = saved })
+
+	d := middayDelivery(MiddayBriefing{TargetDate: "2024-01-15"})
+	if err := sendPushover(PushoverConfig{Token: "app", User: "me"}, d); err != nil {
+		t.Fatalf("sendPushover() error: %v", err)
+	}
+	if form.Get("token") != "app" || form.Get("user") != "me" || form.Get("title") != "Midday check-in 2024-01-15" {
+		t.Errorf("form = %v", form)
+	}
+	if form.Get("priority") != "" {
+		t.Errorf("priority = %q, want default for an unflagged check-in", form.Get("priority"))
+	}
+}
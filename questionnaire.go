@@ -0,0 +1,94 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// questionnaireFields are the morning readiness questions, in --answers order
+var questionnaireFields = []checkinField{fieldSleepFeel, fieldSoreness, fieldMotivation}
+
+// ParseAnswers parses --answers as comma-separated 1-10 scores for sleep feel,
+// soreness, and motivation; an empty entry skips that question ("7,,8")
+func ParseAnswers(s string) (CheckinData, error) {
+	var c CheckinData
+	parts := strings.Split(s, ",")
+	if len(parts) != len(questionnaireFields) {
+		return c, fmt.Errorf("invalid --answers %q (expected SLEEP_FEEL,SORENESS,MOTIVATION, e.g. 7,3,8)", s)
+	}
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		f := questionnaireFields[i]
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return c, fmt.Errorf("invalid --answers %s %q (expected %d-%d)", f.flag, part, CheckinMin, CheckinMax)
+		}
+		if err := validateCheckinScore(f.flag, n); err != nil {
+			return c, err
+		}
+		v := float64(n)
+		*f.field(&c) = &v
+	}
+	return c, nil
+}
+
+// answeredQuestionnaire reports whether any questionnaire score was recorded on date
+func answeredQuestionnaire(db *sql.DB, date string) (bool, error) {
+	for _, f := range questionnaireFields {
+		v, err := queryLatestValue(db, f.metric, date)
+		if err != nil {
+			return false, err
+		}
+		if v != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// runQuestionnaire records the morning answers before the briefing is built.
+// --answers always records; otherwise, when enabled, the questions are asked
+// on stderr (keeping stdout JSON) on the day's first run in a terminal.
+func runQuestionnaire(cfg QuestionnaireConfig, answers string, now time.Time) error {
+	if answers == "" && (!cfg.Enabled || !isTerminal(os.Stdin)) {
+		return nil
+	}
+
+	db, err := sql.Open("sqlite", getHealthDBPath())
+	if err != nil {
+		return fmt.Errorf("sqlite open error: %w", err)
+	}
+	defer db.Close()
+
+	return recordQuestionnaire(db, answers, now, os.Stdin, os.Stderr)
+}
+
+// recordQuestionnaire stores parsed --answers, or prompts on in/out unless
+// the questions were already answered today
+func recordQuestionnaire(db *sql.DB, answers string, now time.Time, in io.Reader, out io.Writer) error {
+	var c CheckinData
+	var err error
+	if answers != "" {
+		if c, err = ParseAnswers(answers); err != nil {
+			return err
+		}
+	} else {
+		done, err := answeredQuestionnaire(db, now.Format("2006-01-02"))
+		if err != nil || done {
+			return err
+		}
+		fmt.Fprintln(out, "Morning check-in:")
+		if c, err = promptCheckin(in, out, questionnaireFields); err != nil {
+			return err
+		}
+	}
+	return insertCheckinMetrics(db, now.Truncate(time.Second), c)
+}
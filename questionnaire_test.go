@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== READINESS QUESTIONNAIRE TESTS ====================
+
+func TestParseAnswers(t *testing.T) {
+	tests := []struct {
+		input       string
+		sleepFeel   *float64
+		soreness    *float64
+		motivation  *float64
+		expectError bool
+	}{
+		{"7,3,8", ptr(7), ptr(3), ptr(8), false},
+		{" 7 , , 8 ", ptr(7), nil, ptr(8), false},
+		{",,", nil, nil, nil, false},
+		{"7,3", nil, nil, nil, true},
+		{"7,3,11", nil, nil, nil, true},
+		{"good,3,8", nil, nil, nil, true},
+	}
+
+	eq := func(a, b *float64) bool { return (a == nil && b == nil) || (a != nil && b != nil && *a == *b) }
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			c, err := ParseAnswers(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseAnswers(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAnswers(%q) unexpected error: %v", tt.input, err)
+			}
+			if !eq(c.SleepFeel, tt.sleepFeel) || !eq(c.Soreness, tt.soreness) || !eq(c.Motivation, tt.motivation) {
+				t.Errorf("ParseAnswers(%q) = %s", tt.input, formatCheckin(c))
+			}
+			if c.Mood != nil || c.Energy != nil {
+				t.Errorf("ParseAnswers(%q) set mood/energy", tt.input)
+			}
+		})
+	}
+}
+
+func TestRecordQuestionnaire(t *testing.T) {
+	db := newTestMetricsDB(t)
+	now := time.Date(2024, 1, 15, 7, 0, 0, 0, time.FixedZone("ICT", 7*3600))
+
+	// First run of the day prompts
+	var out strings.Builder
+	if err := recordQuestionnaire(db, "", now, strings.NewReader("6\n2\n9\n"), &out); err != nil {
+		t.Fatalf("recordQuestionnaire() error: %v", err)
+	}
+	if !strings.Contains(out.String(), "How rested do you feel") {
+		t.Errorf("expected the sleep question, got:\n%s", out.String())
+	}
+	c, err := queryCheckin(db, "2024-01-15")
+	if err != nil || c == nil {
+		t.Fatalf("queryCheckin() = %v, %v", c, err)
+	}
+	if *c.SleepFeel != 6 || *c.Soreness != 2 || *c.Motivation != 9 {
+		t.Errorf("answers = %s, want sleep-feel 6, soreness 2, motivation 9", formatCheckin(*c))
+	}
+
+	// Later runs the same day don't ask again
+	out.Reset()
+	if err := recordQuestionnaire(db, "", now.Add(time.Hour), strings.NewReader("1\n1\n1\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("questions asked twice in one day:\n%s", out.String())
+	}
+
+	// --answers always records
+	if err := recordQuestionnaire(db, "3,,", now.Add(2*time.Hour), nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	c, _ = queryCheckin(db, "2024-01-15")
+	if *c.SleepFeel != 3 || *c.Motivation != 9 {
+		t.Errorf("after --answers = %s, want sleep-feel 3, motivation 9", formatCheckin(*c))
+	}
+}
+
+func TestRunQuestionnaireDisabled(t *testing.T) {
+	// Without answers or config the questionnaire never touches the database
+	if err := runQuestionnaire(QuestionnaireConfig{}, "", time.Now()); err != nil {
+		t.Errorf("runQuestionnaire() = %v, want nil", err)
+	}
+}
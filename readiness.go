@@ -0,0 +1,63 @@
+package main
+
+// Readiness score reference points
+const (
+	ReadinessSleepTargetHours = 8.0  // Sleep at or above this scores 100
+	ReadinessHRVTargetMs      = 50.0 // HRV at or above this scores 100
+	ReadinessLowDeepPenalty   = 0.85 // Multiplier when deep sleep < 1h
+)
+
+// CalculateReadinessScore blends last night's sleep, HRV, and today's check-in into a 0-100 score
+// Each available component scores 0-100 and the result is their average.
+// Returns nil when no component has data.
+func CalculateReadinessScore(sleep SleepData, vitals VitalsData, checkin *CheckinData) *int {
+	var components []float64
+
+	if score := CalculateSleepScore(sleep); score != nil {
+		components = append(components, *score)
+	}
+
+	if vitals.HRV != nil {
+		components = append(components, clampScore(*vitals.HRV/ReadinessHRVTargetMs*100))
+	}
+
+	// Subjective state often leads the physiological data
+	if subjective := subjectiveScore(checkin); subjective != nil {
+		components = append(components, *subjective)
+	}
+
+	if len(components) == 0 {
+		return nil
+	}
+
+	total := 0.0
+	for _, c := range components {
+		total += c
+	}
+	score := int(total/float64(len(components)) + 0.5)
+	return &score
+}
+
+// CalculateSleepScore scores last night's sleep 0-100 against the target,
+// penalizing short deep sleep. Returns nil when last night has no data.
+func CalculateSleepScore(sleep SleepData) *float64 {
+	if !sleep.DataAvailable || !sleep.IsCurrentDay || sleep.TotalHours == nil {
+		return nil
+	}
+	score := clampScore(*sleep.TotalHours / ReadinessSleepTargetHours * 100)
+	if sleep.DeepHours != nil && *sleep.DeepHours < 1.0 {
+		score *= ReadinessLowDeepPenalty
+	}
+	return &score
+}
+
+// clampScore limits a score to 0-100
+func clampScore(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+)
+
+// ==================== READINESS SCORE TESTS ====================
+
+func TestCalculateReadinessScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		sleep    SleepData
+		hrv      *float64
+		checkin  *CheckinData
+		expected *int
+	}{
+		{
+			name:     "No data",
+			expected: nil,
+		},
+		{
+			name:     "Full sleep, strong HRV",
+			sleep:    SleepData{TotalHours: ptr(8.5), DeepHours: ptr(1.5), DataAvailable: true, IsCurrentDay: true},
+			hrv:      ptr(60),
+			expected: intPtr(100),
+		},
+		{
+			name:     "Six hours, moderate HRV",
+			sleep:    SleepData{TotalHours: ptr(6), DataAvailable: true, IsCurrentDay: true},
+			hrv:      ptr(40),
+			expected: intPtr(78), // (75 + 80) / 2
+		},
+		{
+			name:     "Low deep sleep penalty",
+			sleep:    SleepData{TotalHours: ptr(8), DeepHours: ptr(0.5), DataAvailable: true, IsCurrentDay: true},
+			expected: intPtr(85),
+		},
+		{
+			name:     "Stale sleep ignored, HRV only",
+			sleep:    SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: false},
+			hrv:      ptr(25),
+			expected: intPtr(50),
+		},
+		{
+			name:     "Check-in only",
+			checkin:  &CheckinData{Mood: ptr(10), Energy: ptr(1)},
+			expected: intPtr(50),
+		},
+		{
+			name:     "Check-in blended with sleep and HRV",
+			sleep:    SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true},
+			hrv:      ptr(50),
+			checkin:  &CheckinData{Soreness: ptr(10)},
+			expected: intPtr(67), // (100 + 100 + 0) / 3
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculateReadinessScore(tt.sleep, VitalsData{HRV: tt.hrv}, tt.checkin)
+			if tt.expected == nil {
+				if result != nil {
+					t.Errorf("CalculateReadinessScore() = %d, want nil", *result)
+				}
+				return
+			}
+			if result == nil || *result != *tt.expected {
+				t.Errorf("CalculateReadinessScore() = %v, want %d", result, *tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalculateSleepScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		sleep    SleepData
+		expected *float64
+	}{
+		{"No data", SleepData{}, nil},
+		{"Stale sleep", SleepData{TotalHours: ptr(8), DataAvailable: true}, nil},
+		{"Six hours", SleepData{TotalHours: ptr(6), DataAvailable: true, IsCurrentDay: true}, ptr(75)},
+		{"Capped at 100", SleepData{TotalHours: ptr(9.5), DataAvailable: true, IsCurrentDay: true}, ptr(100)},
+		{"Low deep sleep penalty", SleepData{TotalHours: ptr(8), DeepHours: ptr(0.5), DataAvailable: true, IsCurrentDay: true}, ptr(85)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculateSleepScore(tt.sleep)
+			if tt.expected == nil {
+				if result != nil {
+					t.Errorf("CalculateSleepScore() = %v, want nil", *result)
+				}
+				return
+			}
+			if result == nil || *result != *tt.expected {
+				t.Errorf("CalculateSleepScore() = %v, want %v", result, *tt.expected)
+			}
+		})
+	}
+}
+
+func TestClassifySetsReadinessScore(t *testing.T) {
+	b := &MorningBriefing{
+		Sleep:  SleepData{TotalHours: ptr(8), DataAvailable: true, IsCurrentDay: true},
+		Vitals: VitalsData{HRV: ptr(15)}, // Poor recovery returns early from recommendations
+	}
+	classify(b)
+	if b.Classification.ReadinessScore == nil {
+		t.Fatal("ReadinessScore = nil, want a score")
+	}
+	if *b.Classification.ReadinessScore != 65 {
+		t.Errorf("ReadinessScore = %d, want 65", *b.Classification.ReadinessScore)
+	}
+}
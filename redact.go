@@ -0,0 +1,306 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// emailPattern matches email addresses in free text (errors, summaries)
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// redactToken replaces s with a short stable hash so identical values
+// still match each other after redaction
+func redactToken(s string) string {
+	if s == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "redacted:" + hex.EncodeToString(sum[:4])
+}
+
+// redactEmails hashes every email address in s, leaving the rest intact
+func redactEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, redactToken)
+}
+
+func redactStrings(in []string, redact func(string) string) []string {
+	if in == nil {
+		return nil
+	}
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = redact(s)
+	}
+	return out
+}
+
+func redactEvents(in []CalendarEvent) []CalendarEvent {
+	if in == nil {
+		return nil
+	}
+	out := make([]CalendarEvent, len(in))
+	for i, e := range in {
+		out[i] = redactEvent(e)
+	}
+	return out
+}
+
+// redactEvent hashes the summary, location, and attendees and drops the call link
+func redactEvent(e CalendarEvent) CalendarEvent {
+	e.Summary = redactToken(e.Summary)
+	e.Location = redactToken(e.Location)
+	if e.JoinURL != "" {
+		e.JoinURL = "redacted"
+	}
+	if e.Attendees != nil {
+		attendees := make([]CalendarAttendee, len(e.Attendees))
+		for i, a := range e.Attendees {
+			attendees[i] = CalendarAttendee{Email: redactToken(a.Email), Name: redactToken(a.Name), Response: a.Response}
+		}
+		e.Attendees = attendees
+	}
+	return e
+}
+
+// redactTravel hashes the trips' summaries, flights, and places, keeping
+// dates and departure times
+func redactTravel(d *TravelData) *TravelData {
+	if d == nil {
+		return nil
+	}
+	out := *d
+	out.Trips = make([]Trip, len(d.Trips))
+	for i, t := range d.Trips {
+		for _, f := range []*string{&t.Summary, &t.Flight, &t.From, &t.To, &t.DestinationTZ} {
+			if *f != "" {
+				*f = redactToken(*f)
+			}
+		}
+		out.Trips[i] = t
+	}
+	return &out
+}
+
+// redactWork hashes the pull requests' repos, titles, and authors and drops
+// the links, keeping numbers and counts
+func redactWork(w *WorkData) *WorkData {
+	if w == nil {
+		return nil
+	}
+	out := *w
+	for _, list := range []*[]PullRequest{&out.ReviewRequests, &out.FailingChecks} {
+		prs := make([]PullRequest, len(*list))
+		for i, pr := range *list {
+			pr.Repo, pr.Title, pr.Author = redactToken(pr.Repo), redactToken(pr.Title), redactToken(pr.Author)
+			if pr.URL != "" {
+				pr.URL = "redacted"
+			}
+			prs[i] = pr
+		}
+		*list = prs
+	}
+	return &out
+}
+
+func redactMedTasks(in []MedTask) []MedTask {
+	if in == nil {
+		return nil
+	}
+	out := make([]MedTask, len(in))
+	for i, m := range in {
+		m.Name = redactToken(m.Name)
+		out[i] = m
+	}
+	return out
+}
+
+// redactCycles hashes each cycle's med name and dose, keeping the phases
+func redactCycles(in []CycleStatus) []CycleStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]CycleStatus, len(in))
+	for i, c := range in {
+		c.Name, c.Dose = redactToken(c.Name), redactToken(c.Dose)
+		out[i] = c
+	}
+	return out
+}
+
+// redactCategories hashes the med names in every category's lists
+func redactCategories(in MedCategories) MedCategories {
+	if in == nil {
+		return nil
+	}
+	out := make(MedCategories, len(in))
+	for category, c := range in {
+		if c == nil {
+			continue
+		}
+		out[category] = &MedCategoryData{
+			Due:       redactStrings(c.Due, redactToken),
+			Overdue:   redactStrings(c.Overdue, redactToken),
+			Completed: redactStrings(c.Completed, redactToken),
+			Missed:    redactStrings(c.Missed, redactToken),
+		}
+	}
+	return out
+}
+
+// redactMeds hashes every med name in the day's meds, keeping dates and counts
+func redactMeds(m MedsData) MedsData {
+	m.DueToday = redactMedTasks(m.DueToday)
+	m.Overdue = redactMedTasks(m.Overdue)
+	m.Completed = redactMedTasks(m.Completed)
+	if m.MissedRecently != nil {
+		missed := make([]MedMisses, len(m.MissedRecently))
+		for i, mm := range m.MissedRecently {
+			mm.Name = redactToken(mm.Name)
+			missed[i] = mm
+		}
+		m.MissedRecently = missed
+	}
+	if m.Inventory != nil {
+		stock := make([]MedStock, len(m.Inventory))
+		for i, s := range m.Inventory {
+			s.Name = redactToken(s.Name)
+			stock[i] = s
+		}
+		m.Inventory = stock
+	}
+	m.Cycles = redactCycles(m.Cycles)
+	m.Categories = redactCategories(m.Categories)
+	return m
+}
+
+// RedactMorningBriefing returns a copy safe to share: event details and
+// med, supplement, and task names are hashed and email addresses scrubbed,
+// while counts, times, metrics, and classifications are kept. The narrative
+// is free prose that may name events and meds, so it is dropped.
+func RedactMorningBriefing(b MorningBriefing) MorningBriefing {
+	in := b
+	b.Narrative = ""
+	if b.Calendar.AllDayEvents != nil {
+		allDay := make([]AllDayEvent, len(b.Calendar.AllDayEvents))
+		for i, e := range b.Calendar.AllDayEvents {
+			e.Summary = redactToken(e.Summary)
+			allDay[i] = e
+		}
+		b.Calendar.AllDayEvents = allDay
+	}
+	if b.Calendar.Conflicts != nil {
+		conflicts := make([]EventConflict, len(b.Calendar.Conflicts))
+		for i, c := range b.Calendar.Conflicts {
+			c.Events = redactEvents(c.Events)
+			conflicts[i] = c
+		}
+		b.Calendar.Conflicts = conflicts
+	}
+	b.Travel = redactTravel(b.Travel)
+	if b.JetLagPlan != nil {
+		plan := *b.JetLagPlan
+		plan.Flight = redactToken(plan.Flight)
+		plan.DestinationTZ = redactToken(plan.DestinationTZ)
+		b.JetLagPlan = &plan
+	}
+	b.Calendar.MorningEvents = redactEvents(b.Calendar.MorningEvents)
+	b.Calendar.AfternoonEvents = redactEvents(b.Calendar.AfternoonEvents)
+	b.Calendar.CommuteTo = redactToken(b.Calendar.CommuteTo)
+	b.Work = redactWork(b.Work)
+	if b.Focus != nil {
+		focus := FocusData{Tasks: make([]FocusTask, len(b.Focus.Tasks)), More: b.Focus.More}
+		for i, t := range b.Focus.Tasks {
+			t.Name = redactToken(t.Name)
+			focus.Tasks[i] = t
+		}
+		b.Focus = &focus
+	}
+	if b.Tasks.OldestOverdue != nil {
+		overdue := make([]OverdueTask, len(b.Tasks.OldestOverdue))
+		for i, t := range b.Tasks.OldestOverdue {
+			t.Name = redactToken(t.Name)
+			overdue[i] = t
+		}
+		b.Tasks.OldestOverdue = overdue
+	}
+	if b.OnCall != nil {
+		onCall := OnCallData{OnCall: b.OnCall.OnCall, Schedules: make([]OnCallSchedule, len(b.OnCall.Schedules))}
+		for i, s := range b.OnCall.Schedules {
+			s.Name = redactToken(s.Name)
+			s.Others = redactStrings(s.Others, redactToken)
+			onCall.Schedules[i] = s
+		}
+		b.OnCall = &onCall
+	}
+	if b.Slack != nil {
+		slack := SlackData{Workspaces: make([]SlackUnread, len(b.Slack.Workspaces))}
+		for i, w := range b.Slack.Workspaces {
+			channels := make([]SlackChannel, len(w.Channels))
+			for j, c := range w.Channels {
+				channels[j] = SlackChannel{Name: redactToken(c.Name), Mentions: c.Mentions}
+			}
+			w.Channels = channels
+			slack.Workspaces[i] = w
+		}
+		b.Slack = &slack
+	}
+	if b.Mail != nil {
+		mailData := *b.Mail
+		mailData.Messages = make([]MailMessage, len(b.Mail.Messages))
+		for i, m := range b.Mail.Messages {
+			m.From, m.Subject = redactToken(m.From), redactToken(m.Subject)
+			mailData.Messages[i] = m
+		}
+		b.Mail = &mailData
+	}
+	b.Meds = redactMeds(b.Meds)
+	if b.Supplements != nil {
+		supplements := make([]SupplementDose, len(b.Supplements))
+		for i, s := range b.Supplements {
+			s.Name, s.Note = redactToken(s.Name), redactToken(s.Note)
+			supplements[i] = s
+		}
+		b.Supplements = supplements
+	}
+	b.Errors = redactStrings(b.Errors, redactEmails)
+	b.Classification.Recommendation = redactRecommendation(in, b)
+	return b
+}
+
+// redactRecommendation rebuilds the notes in the recommendation that name
+// events, flights, channels, or schedules from the redacted briefing out
+func redactRecommendation(in, out MorningBriefing) string {
+	rec := in.Classification.Recommendation
+	for _, note := range [][2]string{
+		{conflictNote(in.Calendar.Conflicts), conflictNote(out.Calendar.Conflicts)},
+		{travelNote(in.Calendar), travelNote(out.Calendar)},
+		{jetLagNote(in.JetLagPlan, in.TargetDate), jetLagNote(out.JetLagPlan, out.TargetDate)},
+		{slackNote(in.Slack, settings.Slack), slackNote(out.Slack, settings.Slack)},
+		{onCallNote(in.OnCall, in.TargetDate), onCallNote(out.OnCall, out.TargetDate)},
+	} {
+		if note[0] != "" {
+			rec = strings.Replace(rec, note[0], note[1], 1)
+		}
+	}
+	return rec
+}
+
+// RedactEveningBriefing returns a copy safe to share (see RedactMorningBriefing)
+// Protocol names come from the same Todoist labels as meds and are hashed too.
+func RedactEveningBriefing(b EveningBriefing) EveningBriefing {
+	b.Protocols.Completed = redactStrings(b.Protocols.Completed, redactToken)
+	b.Protocols.Missed = redactStrings(b.Protocols.Missed, redactToken)
+	b.Protocols.Cycles = redactCycles(b.Protocols.Cycles)
+	b.Protocols.Categories = redactCategories(b.Protocols.Categories)
+	b.Tomorrow.MedsDue = redactStrings(b.Tomorrow.MedsDue, redactToken)
+	if b.Tomorrow.FirstEvent != nil {
+		event := *b.Tomorrow.FirstEvent
+		event.Summary = redactToken(event.Summary)
+		b.Tomorrow.FirstEvent = &event
+	}
+	b.Travel = redactTravel(b.Travel)
+	b.Errors = redactStrings(b.Errors, redactEmails)
+	return b
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== REDACTION TESTS ====================
+
+func TestRedactToken(t *testing.T) {
+	a := redactToken("Therapy with Dr. Smith")
+	if !strings.HasPrefix(a, "redacted:") || strings.Contains(a, "Smith") {
+		t.Errorf("redactToken() = %q, want hashed value", a)
+	}
+	if redactToken("Therapy with Dr. Smith") != a {
+		t.Error("redactToken() is not stable for identical input")
+	}
+	if redactToken("Standup") == a {
+		t.Error("redactToken() collides for different input")
+	}
+	if redactToken("") != "" {
+		t.Error("redactToken(\"\") should stay empty")
+	}
+}
+
+func TestRedactEmails(t *testing.T) {
+	got := redactEmails("calendar error (jai.g@ewa-services.com): exit status 1")
+	if strings.Contains(got, "@") {
+		t.Errorf("redactEmails() = %q, email not removed", got)
+	}
+	if !strings.HasPrefix(got, "calendar error (redacted:") || !strings.HasSuffix(got, "): exit status 1") {
+		t.Errorf("redactEmails() = %q, surrounding text not preserved", got)
+	}
+}
+
+func TestRedactMorningBriefing(t *testing.T) {
+	b := MorningBriefing{
+		Calendar: CalendarData{
+			MorningEvents: []CalendarEvent{{
+				Time: "09:00", Summary: "Therapy", Source: "personal", Location: "Harley Street", Response: "accepted",
+				Attendees: []CalendarAttendee{{Email: "dr@clinic.example", Name: "Dr Patel", Response: "accepted"}}, JoinURL: "https://meet.google.com/abc",
+			}},
+			MorningCount:   1,
+			FirstEventTime: "09:00",
+			LeaveBy:        "08:30",
+			CommuteTo:      "Therapy",
+		},
+		Meds: MedsData{
+			DueToday:       []MedTask{{Name: "Sertraline 50mg", DueDate: "2024-01-15"}},
+			Overdue:        []MedTask{},
+			MissedRecently: []MedMisses{{Name: "Lisinopril", Missed: 3, Days: 7}},
+			Inventory:      []MedStock{{Name: "Metformin", Remaining: 12, Unit: "pills", DaysLeft: 6, Reorder: true}},
+			Cycles:         []CycleStatus{{Name: "Enclomiphene", Phase: "on", Dose: "12.5mg", PhaseDay: 3}},
+			Categories:     MedCategories{"medication": {Due: []string{"Sertraline 50mg"}, Overdue: []string{"Atorvastatin"}}, "supplement": {Completed: []string{"Magnesium"}}},
+		},
+		Tasks:          TasksData{OverdueCount: 4, OldestOverdue: []OverdueTask{{Name: "File divorce papers", DueDate: "2024-01-02", DaysOverdue: 13}}},
+		Supplements:    []SupplementDose{{Time: "07:30", Name: "Iron", Note: "2 h before Zinc"}},
+		Classification: Classification{SleepQuality: "GOOD", MorningLoad: "LIGHT"},
+		Narrative:      "Therapy at nine, and your Sertraline is due.",
+		Errors:         []string{"calendar error (jai@govindani.com): exit status 1"},
+	}
+
+	r := RedactMorningBriefing(b)
+	out, _ := json.Marshal(r)
+	for _, secret := range []string{"Therapy", "Sertraline", "Lisinopril", "Metformin", "Enclomiphene", "12.5mg", "Iron", "Zinc", "Atorvastatin", "Magnesium", "divorce", "govindani.com", "narrative", "Harley", "clinic.example", "Patel", "meet.google.com"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("redacted output still contains %q: %s", secret, out)
+		}
+	}
+
+	// Structure and classifications are preserved
+	if len(r.Calendar.MorningEvents) != 1 || r.Calendar.MorningEvents[0].Time != "09:00" || r.Calendar.MorningEvents[0].Source != "personal" || r.Calendar.MorningEvents[0].Response != "accepted" {
+		t.Errorf("events = %+v, want time/source kept", r.Calendar.MorningEvents)
+	}
+	if r.Meds.DueToday[0].DueDate != "2024-01-15" {
+		t.Errorf("med due date = %q, want kept", r.Meds.DueToday[0].DueDate)
+	}
+	if r.Meds.MissedRecently[0].Missed != 3 || r.Meds.MissedRecently[0].Days != 7 {
+		t.Errorf("missed recently = %+v, want counts kept", r.Meds.MissedRecently)
+	}
+	if s := r.Meds.Inventory[0]; s.Remaining != 12 || s.DaysLeft != 6 || !s.Reorder {
+		t.Errorf("inventory = %+v, want stock kept", s)
+	}
+	if c := r.Meds.Cycles[0]; c.Phase != "on" || c.PhaseDay != 3 {
+		t.Errorf("cycle = %+v, want phase kept", c)
+	}
+	if len(r.Meds.Categories) != 2 || len(r.Meds.Categories["medication"].Overdue) != 1 {
+		t.Errorf("categories = %+v, want lists kept", r.Meds.Categories)
+	}
+	if r.Tasks.OldestOverdue[0].DaysOverdue != 13 || r.Tasks.OverdueCount != 4 {
+		t.Errorf("tasks = %+v, want counts kept", r.Tasks)
+	}
+	if r.Supplements[0].Time != "07:30" {
+		t.Errorf("supplement time = %q, want kept", r.Supplements[0].Time)
+	}
+	if r.Meds.Overdue == nil {
+		t.Error("empty med list became nil")
+	}
+	if !reflect.DeepEqual(r.Classification, b.Classification) {
+		t.Errorf("classification changed: %+v", r.Classification)
+	}
+
+	// The original is untouched
+	if b.Calendar.MorningEvents[0].Summary != "Therapy" || b.Meds.DueToday[0].Name != "Sertraline 50mg" || b.Meds.Categories["supplement"].Completed[0] != "Magnesium" {
+		t.Error("RedactMorningBriefing() modified its input")
+	}
+}
+
+func TestRedactEveningBriefing(t *testing.T) {
+	b := EveningBriefing{
+		Protocols: ProtocolsData{
+			Completed: []string{"Creatine"}, Missed: []string{"Sertraline"},
+			Cycles:     []CycleStatus{{Name: "Enclomiphene", Phase: "off", Dose: "12.5mg"}},
+			Categories: MedCategories{"injection": {Missed: []string{"Semaglutide"}}, "supplement": {Completed: []string{"Magnesium"}}},
+		},
+		Tomorrow: TomorrowData{
+			FirstEvent: &EventInfo{Time: "08:30", Summary: "Cardiologist"},
+			MedsDue:    []string{"Ozempic"},
+		},
+	}
+
+	r := RedactEveningBriefing(b)
+	out, _ := json.Marshal(r)
+	for _, secret := range []string{"Creatine", "Sertraline", "Enclomiphene", "12.5mg", "Semaglutide", "Magnesium", "Cardiologist", "Ozempic"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("redacted output still contains %q: %s", secret, out)
+		}
+	}
+	if r.Tomorrow.FirstEvent.Time != "08:30" {
+		t.Errorf("first event time = %q, want kept", r.Tomorrow.FirstEvent.Time)
+	}
+	if b.Tomorrow.FirstEvent.Summary != "Cardiologist" {
+		t.Error("RedactEveningBriefing() modified its input")
+	}
+}
+
+// fillSecrets sets every string reachable from v to a marker naming its
+// path, giving each slice one element and each map one key
+func fillSecrets(v reflect.Value, path string, depth int) {
+	if depth > 8 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() {
+				fillSecrets(v.Field(i), path+"."+f.Name, depth+1)
+			}
+		}
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillSecrets(v.Elem(), path, depth+1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillSecrets(v.Index(0), path, depth+1)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		v.Set(reflect.MakeMap(v.Type()))
+		e := reflect.New(v.Type().Elem()).Elem()
+		fillSecrets(e, path, depth+1)
+		v.SetMapIndex(reflect.ValueOf("key").Convert(v.Type().Key()), e)
+	case reflect.String:
+		v.SetString("SECRET" + path + ";")
+	}
+}
+
+// filledMorning is a morning briefing with every field set by fillSecrets
+func filledMorning() MorningBriefing {
+	var b MorningBriefing
+	fillSecrets(reflect.ValueOf(&b).Elem(), "", 0)
+	// A conflict is always a pair
+	b.Calendar.Conflicts[0].Events = append(b.Calendar.Conflicts[0].Events, b.Calendar.Conflicts[0].Events[0])
+	return b
+}
+
+// leakedSecrets lists the paths whose marker survived into out
+func leakedSecrets(out []byte) map[string]bool {
+	leaked := map[string]bool{}
+	for _, s := range strings.Split(string(out), "SECRET")[1:] {
+		leaked[s[:strings.Index(s, ";")]] = true
+	}
+	return leaked
+}
+
+func TestRedactRecommendation(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Slack.WarnMentions = 10
+	forty := 40
+	tests := []struct {
+		name    string
+		b       MorningBriefing
+		note    func(MorningBriefing) string
+		secrets []string
+	}{
+		{
+			name: "conflict",
+			b: MorningBriefing{Calendar: CalendarData{Conflicts: []EventConflict{{
+				Start: "10:00", End: "10:30", Events: []CalendarEvent{{Time: "10:00", Summary: "Therapy"}, {Time: "10:00", Summary: "Divorce mediation"}},
+			}}}},
+			note:    func(b MorningBriefing) string { return conflictNote(b.Calendar.Conflicts) },
+			secrets: []string{"Therapy", "Divorce"},
+		},
+		{
+			name: "travel",
+			b: MorningBriefing{Calendar: CalendarData{MorningEvents: []CalendarEvent{{
+				Time: "09:00", Summary: "Fertility clinic", Location: "Harley Street", TravelMinutes: &forty, LeaveBy: "08:10", EarlyDeparture: true,
+			}}}},
+			note:    func(b MorningBriefing) string { return travelNote(b.Calendar) },
+			secrets: []string{"Fertility", "Harley"},
+		},
+		{
+			name: "jet lag",
+			b: MorningBriefing{TargetDate: "2024-01-16", JetLagPlan: &JetLagPlan{
+				Flight: "Flight to Frankfurt", Departs: "2024-01-16", DestinationTZ: "Europe/Berlin", ShiftHours: 6, Direction: "east",
+				Days: []JetLagDay{{Date: "2024-01-16", Phase: JetLagTravel, Bedtime: "22:00", Wake: "06:00", Light: "07:00-09:00", CaffeineCutoff: "13:00"}},
+			}},
+			note:    func(b MorningBriefing) string { return jetLagNote(b.JetLagPlan, b.TargetDate) },
+			secrets: []string{"Frankfurt", "Berlin"},
+		},
+		{
+			name: "slack",
+			b: MorningBriefing{Slack: &SlackData{Workspaces: []SlackUnread{
+				{Name: "work", Mentions: 12, Channels: []SlackChannel{{Name: "#layoffs-planning", Mentions: 12}}},
+			}}},
+			note:    func(b MorningBriefing) string { return slackNote(b.Slack, settings.Slack) },
+			secrets: []string{"layoffs"},
+		},
+		{
+			name: "on call",
+			b: MorningBriefing{TargetDate: "2024-01-16", OnCall: &OnCallData{OnCall: true, Schedules: []OnCallSchedule{
+				{Name: "Acquisition due diligence", OnCall: true, Until: "2024-01-17 09:00"},
+			}}},
+			note:    func(b MorningBriefing) string { return onCallNote(b.OnCall, b.TargetDate) },
+			secrets: []string{"Acquisition"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.b.Classification.Recommendation = "Normal day." + tt.note(tt.b)
+			r := RedactMorningBriefing(tt.b)
+			for _, secret := range tt.secrets {
+				if strings.Contains(r.Classification.Recommendation, secret) {
+					t.Errorf("recommendation still contains %q: %s", secret, r.Classification.Recommendation)
+				}
+			}
+			if want := "Normal day." + tt.note(r); r.Classification.Recommendation != want {
+				t.Errorf("recommendation = %q, want %q", r.Classification.Recommendation, want)
+			}
+		})
+	}
+}
+
+// TestRedactLeavesNoNames fills every string in each briefing and fails on
+// any that survives redaction unless listed here as carrying no names. A
+// new field holding event, med, task, or people names fails until it's
+// redacted (or, if it's safe, added below).
+func TestRedactLeavesNoNames(t *testing.T) {
+	allowed := []string{
+		// Times, dates, units, statuses, and classifications
+		"Time", "Date", "DueDate", "DueTime", "Start", "End", "EndTime", "Departs", "Since", "Until", "Received",
+		"GeneratedAt", "TargetDate", "DataDate", "LeaveBy", "FirstEventTime", "Wake", "Bedtime", "CaffeineCutoff",
+		"LastMealAt", "MealTime", "FirstMeal", "LastMeal", "Sunrise", "Sunset", "UVFrom", "UVUntil", "NextChange",
+		"ETA", "DeficitETA", "Duration", "Unit", "Currency", "Category", "Kind", "Source", "Sources", "Response",
+		"Status", "Severity", "Level", "Phase", "NextPhase", "Priority", "Direction", "Trend", "Training", "Light",
+		"Consistency", "Conditions", "MainPollutant", "CommuteMode", "Mode", "Pace", "ChangeFrom", "EstimateFrom",
+		"BMRSource", "ID", "Symbol", "When", "Notify", "Rule", "Metric", "Flags", "Tags",
+		"Classification", "SleepQuality", "RecoveryStatus", "MorningLoad", "TaskPressure", "IllnessRisk",
+		"IllnessSignals", "Recommendation",
+		// Metric labels and change summaries ("HRV +8 ms")
+		"Changes.Label", "Changes.Summary", "Micronutrients.Label",
+		// Public or configured labels: the city for weather.location, news
+		// headlines, pollen types, workspace names, workouts
+		"City", "News.Headlines.Feed", "News.Headlines.Title", "News.Headlines.Link", "Pollen.Types.Name",
+		"Slack.Workspaces.Name", "Training.LastWorkout.Title",
+		"Training.LastWorkout.Exercises", "Training.RecentWorkouts.Title", "Training.RecentWorkouts.Exercises",
+		"Activity.Workout.Title",
+		// Only email addresses are scrubbed from errors
+		"Errors",
+	}
+	safe := func(path string) bool {
+		for _, a := range allowed {
+			if path == "."+a || strings.HasSuffix(path, "."+a) {
+				return true
+			}
+		}
+		return false
+	}
+
+	morning := filledMorning()
+	var midday MiddayBriefing
+	fillSecrets(reflect.ValueOf(&midday).Elem(), "", 0)
+	var evening EveningBriefing
+	fillSecrets(reflect.ValueOf(&evening).Elem(), "", 0)
+	for name, b := range map[string]any{
+		"morning": RedactMorningBriefing(morning),
+		"midday":  RedactMiddayBriefing(midday),
+		"evening": RedactEveningBriefing(evening),
+	} {
+		out, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for path := range leakedSecrets(out) {
+			if !safe(path) {
+				t.Errorf("%s: %s survives redaction", name, path)
+			}
+		}
+	}
+}
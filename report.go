@@ -0,0 +1,494 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Anomaly thresholds for the clinical report
+const (
+	AnomalyRestingHRRatio = 1.10 // resting HR this far above the period mean
+	AnomalyHRVRatio       = 0.70 // HRV this far below the period mean
+	AnomalySpO2Pct        = 92.0 // SpO2 below this
+	AnomalyShortSleepHrs  = 5.0  // sleep below this
+)
+
+// ClinicalReport summarizes a date range for a medical appointment
+type ClinicalReport struct {
+	GeneratedAt string            `json:"generated_at"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Vitals      []VitalRange      `json:"vitals"`
+	Weight      *WeightTrajectory `json:"weight,omitempty"`
+	Sleep       *SleepAverages    `json:"sleep,omitempty"`
+	Adherence   *MedAdherence     `json:"medication_adherence,omitempty"`
+	Anomalies   []Anomaly         `json:"anomalies"`
+	Tags        []LifeTag         `json:"tags,omitempty"` // tagged periods overlapping the range
+	Errors      []string          `json:"errors,omitempty"`
+}
+
+type VitalRange struct {
+	Name string  `json:"name"`
+	Unit string  `json:"unit"`
+	Days int     `json:"days"`
+	Min  float64 `json:"min"`
+	Avg  float64 `json:"avg"`
+	Max  float64 `json:"max"`
+}
+
+type WeightTrajectory struct {
+	Readings int     `json:"readings"`
+	StartKg  float64 `json:"start_kg"`
+	EndKg    float64 `json:"end_kg"`
+	ChangeKg float64 `json:"change_kg"`
+	MinKg    float64 `json:"min_kg"`
+	MaxKg    float64 `json:"max_kg"`
+}
+
+type SleepAverages struct {
+	Nights        int      `json:"nights"`
+	AvgTotalHours float64  `json:"avg_total_hours"`
+	AvgDeepHours  *float64 `json:"avg_deep_hours,omitempty"`
+	AvgREMHours   *float64 `json:"avg_rem_hours,omitempty"`
+	NightsUnder6h int      `json:"nights_under_6h"`
+}
+
+type MedAdherence struct {
+	Days       int        `json:"days"`
+	Taken      int        `json:"taken"`
+	Missed     int        `json:"missed"`
+	Percent    float64    `json:"percent"`
+	MostMissed []string   `json:"most_missed,omitempty"`
+	PerMed     []MedStats `json:"per_med,omitempty"` // from the med log, with current streaks
+}
+
+type Anomaly struct {
+	Date        string   `json:"date"`
+	Metric      string   `json:"metric"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"` // life events covering the day
+}
+
+// dailyValue is one day's aggregated metric value
+type dailyValue struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+}
+
+// reportVitals are the ranges listed in the clinical report
+var reportVitals = []struct {
+	metric, name, unit string
+}{
+	{"resting_heart_rate", "Resting heart rate", "bpm"},
+	{"heart_rate_variability", "Heart rate variability", "ms"},
+	{"blood_oxygen_saturation", "Blood oxygen (SpO2)", "%"},
+	{"respiratory_rate", "Respiratory rate", "breaths/min"},
+}
+
+// RunReportCommand handles `briefing report --clinical --from YYYY-MM-DD [--to YYYY-MM-DD] [--format markdown|json]`
+func RunReportCommand(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	clinical := fs.Bool("clinical", false, "Clinician summary (vitals, weight, sleep, adherence, anomalies)")
+	from := fs.String("from", "", "First date (YYYY-MM-DD)")
+	to := fs.String("to", time.Now().Format("2006-01-02"), "Last date (YYYY-MM-DD)")
+	format := fs.String("format", "markdown", "Output format: markdown, json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*clinical {
+		return errors.New("usage: briefing report --clinical --from YYYY-MM-DD [--to YYYY-MM-DD] [--format markdown|json]")
+	}
+	if *from == "" {
+		return errors.New("--from is required")
+	}
+	if err := validateDateRange(*from, *to); err != nil {
+		return err
+	}
+	if *format != "markdown" && *format != "json" {
+		return fmt.Errorf("unknown format %q (expected: markdown, json)", *format)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+
+	metricsDB, err := sql.Open("sqlite", getHealthDBPath())
+	if err != nil {
+		return fmt.Errorf("sqlite open error: %w", err)
+	}
+	defer metricsDB.Close()
+
+	tags, err := loadTags(*from, *to)
+	if err != nil {
+		return err
+	}
+	report := BuildClinicalReport(metricsDB, *from, *to, time.Now(), tags)
+
+	adherence, err := loadMedAdherence(*from, *to)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("adherence error: %v", err))
+	}
+	report.Adherence = adherence
+
+	if *format == "json" {
+		output, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(output))
+		return nil
+	}
+	return RenderClinicalMarkdown(os.Stdout, report)
+}
+
+// BuildClinicalReport aggregates health metrics for an inclusive date range
+// Tagged days count toward ranges but not toward the means anomalies are judged against.
+func BuildClinicalReport(db *sql.DB, from, to string, now time.Time, tags []LifeTag) ClinicalReport {
+	r := ClinicalReport{
+		GeneratedAt: now.Format(time.RFC3339),
+		From:        from,
+		To:          to,
+		Vitals:      []VitalRange{},
+		Anomalies:   []Anomaly{},
+		Tags:        tags,
+	}
+
+	series := map[string][]dailyValue{}
+	load := func(metric string) []dailyValue {
+		values, err := queryDailyValues(db, metric, from, to)
+		if err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s query error: %v", metric, err))
+		}
+		series[metric] = values
+		return values
+	}
+
+	for _, v := range reportVitals {
+		values := load(v.metric)
+		if len(values) == 0 {
+			continue
+		}
+		min, avg, max := summarize(values)
+		r.Vitals = append(r.Vitals, VitalRange{
+			Name: v.name,
+			Unit: v.unit,
+			Days: len(values),
+			Min:  roundTo(min, 1),
+			Avg:  roundTo(avg, 1),
+			Max:  roundTo(max, 1),
+		})
+	}
+
+	if weights := load("weight_body_mass"); len(weights) > 0 {
+		min, _, max := summarize(weights)
+		start, end := weights[0].Value, weights[len(weights)-1].Value
+		r.Weight = &WeightTrajectory{
+			Readings: len(weights),
+			StartKg:  roundTo(start, 1),
+			EndKg:    roundTo(end, 1),
+			ChangeKg: roundTo(end-start, 1),
+			MinKg:    roundTo(min, 1),
+			MaxKg:    roundTo(max, 1),
+		}
+	}
+
+	if totals := load("sleep_total"); len(totals) > 0 {
+		_, avg, _ := summarize(totals)
+		sleep := &SleepAverages{Nights: len(totals), AvgTotalHours: roundTo(avg, 2)}
+		for _, v := range totals {
+			if v.Value < 6 {
+				sleep.NightsUnder6h++
+			}
+		}
+		if deep := load("sleep_deep"); len(deep) > 0 {
+			_, avg, _ := summarize(deep)
+			avg = roundTo(avg, 2)
+			sleep.AvgDeepHours = &avg
+		}
+		if rem := load("sleep_rem"); len(rem) > 0 {
+			_, avg, _ := summarize(rem)
+			avg = roundTo(avg, 2)
+			sleep.AvgREMHours = &avg
+		}
+		r.Sleep = sleep
+	}
+
+	r.Anomalies = DetectAnomalies(series, tags)
+	return r
+}
+
+// queryDailyValues returns one averaged value per day (preferred source only)
+// for an inclusive date range, oldest first
+func queryDailyValues(db *sql.DB, metric, from, to string) ([]dailyValue, error) {
+	rank, rankArgs := sourceRankSQL(metric)
+	query := `
+		SELECT day, AVG(value) FROM (
+			SELECT substr(local_ts(timestamp), 1, 10) AS day, value, r,
+				MIN(r) OVER (PARTITION BY substr(local_ts(timestamp), 1, 10)) AS best
+			FROM (
+				SELECT timestamp, value, ` + rank + ` AS r FROM metrics
+				WHERE metric_name = ?
+				AND local_ts(timestamp) >= ? AND local_ts(timestamp) < ?
+			)
+		)
+		WHERE r = best
+		GROUP BY day
+		ORDER BY day
+	`
+	args := append(rankArgs, metric, from, addDays(to, 1))
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []dailyValue
+	for rows.Next() {
+		var v dailyValue
+		if err := rows.Scan(&v.Date, &v.Value); err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// summarize returns min, mean, and max of a non-empty series
+func summarize(values []dailyValue) (min, avg, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	sum := 0.0
+	for _, v := range values {
+		min = math.Min(min, v.Value)
+		max = math.Max(max, v.Value)
+		sum += v.Value
+	}
+	return min, sum / float64(len(values)), max
+}
+
+// DetectAnomalies flags days that stand out against the period or fixed limits
+// Period means leave out tagged days so a week of flu doesn't mask itself.
+func DetectAnomalies(series map[string][]dailyValue, tags []LifeTag) []Anomaly {
+	anomalies := []Anomaly{}
+	periodMean := func(values []dailyValue) float64 {
+		if untagged := untaggedValues(values, tags); len(untagged) > 0 {
+			values = untagged
+		}
+		_, mean, _ := summarize(values)
+		return mean
+	}
+
+	if values := series["resting_heart_rate"]; len(values) > 0 {
+		mean := periodMean(values)
+		for _, v := range values {
+			if v.Value >= mean*AnomalyRestingHRRatio {
+				anomalies = append(anomalies, Anomaly{Date: v.Date, Metric: "resting_heart_rate",
+					Description: fmt.Sprintf("Resting HR %.0f bpm (%.0f%% above period mean %.0f)", v.Value, (v.Value/mean-1)*100, mean)})
+			}
+		}
+	}
+	if values := series["heart_rate_variability"]; len(values) > 0 {
+		mean := periodMean(values)
+		for _, v := range values {
+			if v.Value <= mean*AnomalyHRVRatio {
+				anomalies = append(anomalies, Anomaly{Date: v.Date, Metric: "heart_rate_variability",
+					Description: fmt.Sprintf("HRV %.0f ms (%.0f%% below period mean %.0f)", v.Value, (1-v.Value/mean)*100, mean)})
+			}
+		}
+	}
+	for _, v := range series["blood_oxygen_saturation"] {
+		if v.Value < AnomalySpO2Pct {
+			anomalies = append(anomalies, Anomaly{Date: v.Date, Metric: "blood_oxygen_saturation",
+				Description: fmt.Sprintf("SpO2 %.0f%% (below %.0f%%)", v.Value, AnomalySpO2Pct)})
+		}
+	}
+	for _, v := range series["sleep_total"] {
+		if v.Value < AnomalyShortSleepHrs {
+			anomalies = append(anomalies, Anomaly{Date: v.Date, Metric: "sleep_total",
+				Description: fmt.Sprintf("Slept %.1fh (under %.0fh)", v.Value, AnomalyShortSleepHrs)})
+		}
+	}
+
+	for i := range anomalies {
+		anomalies[i].Tags = tagsOn(tags, anomalies[i].Date)
+	}
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Date < anomalies[j].Date })
+	return anomalies
+}
+
+// loadMedAdherence reads adherence from the history store, returning nil
+// without error when no history has been recorded yet
+func loadMedAdherence(from, to string) (*MedAdherence, error) {
+	if _, err := os.Stat(getHistoryDBPath()); err != nil {
+		return nil, nil
+	}
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return queryMedAdherence(db, from, to)
+}
+
+// queryMedAdherence totals completed vs missed protocols from stored evening briefings
+func queryMedAdherence(db *sql.DB, from, to string) (*MedAdherence, error) {
+	rows, err := db.Query(`SELECT json FROM briefings WHERE mode = 'evening' AND date >= ? AND date <= ? ORDER BY date`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	a := &MedAdherence{}
+	missedCounts := map[string]int{}
+	for rows.Next() {
+		var stored string
+		if err := rows.Scan(&stored); err != nil {
+			return nil, err
+		}
+		data, err := openAtRest([]byte(stored))
+		if err != nil {
+			return nil, err
+		}
+		var b EveningBriefing
+		if err := json.Unmarshal(data, &b); err != nil {
+			continue
+		}
+		if len(b.Protocols.Completed)+len(b.Protocols.Missed) == 0 {
+			continue
+		}
+		a.Days++
+		a.Taken += len(b.Protocols.Completed)
+		a.Missed += len(b.Protocols.Missed)
+		for _, name := range b.Protocols.Missed {
+			missedCounts[name]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if a.Days == 0 {
+		return nil, nil
+	}
+
+	a.Percent = roundTo(float64(a.Taken)/float64(a.Taken+a.Missed)*100, 1)
+	for name := range missedCounts {
+		a.MostMissed = append(a.MostMissed, name)
+	}
+	sort.Slice(a.MostMissed, func(i, j int) bool {
+		ci, cj := missedCounts[a.MostMissed[i]], missedCounts[a.MostMissed[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return a.MostMissed[i] < a.MostMissed[j]
+	})
+	if len(a.MostMissed) > 3 {
+		a.MostMissed = a.MostMissed[:3]
+	}
+
+	days, err := queryMedLog(db, to)
+	if err != nil {
+		return nil, err
+	}
+	a.PerMed = medStatsFor(days, from, to)
+	return a, nil
+}
+
+// RenderClinicalMarkdown writes the report as Markdown for printing or sharing
+func RenderClinicalMarkdown(w io.Writer, r ClinicalReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Health Summary: %s to %s\n\n", r.From, r.To)
+
+	b.WriteString("## Vitals\n\n")
+	if len(r.Vitals) == 0 {
+		b.WriteString("No vitals recorded.\n\n")
+	} else {
+		b.WriteString("| Measure | Days | Min | Average | Max |\n|---|---|---|---|---|\n")
+		for _, v := range r.Vitals {
+			fmt.Fprintf(&b, "| %s (%s) | %d | %.1f | %.1f | %.1f |\n", v.Name, v.Unit, v.Days, v.Min, v.Avg, v.Max)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Weight\n\n")
+	if r.Weight == nil {
+		b.WriteString("No weight recorded.\n\n")
+	} else {
+		fmt.Fprintf(&b, "%.1f kg → %.1f kg (%+.1f kg) over %d readings; range %.1f–%.1f kg.\n\n",
+			r.Weight.StartKg, r.Weight.EndKg, r.Weight.ChangeKg, r.Weight.Readings, r.Weight.MinKg, r.Weight.MaxKg)
+	}
+
+	b.WriteString("## Sleep\n\n")
+	if r.Sleep == nil {
+		b.WriteString("No sleep recorded.\n\n")
+	} else {
+		fmt.Fprintf(&b, "- Nights recorded: %d\n- Average total: %.1f h\n", r.Sleep.Nights, r.Sleep.AvgTotalHours)
+		if r.Sleep.AvgDeepHours != nil {
+			fmt.Fprintf(&b, "- Average deep: %.1f h\n", *r.Sleep.AvgDeepHours)
+		}
+		if r.Sleep.AvgREMHours != nil {
+			fmt.Fprintf(&b, "- Average REM: %.1f h\n", *r.Sleep.AvgREMHours)
+		}
+		fmt.Fprintf(&b, "- Nights under 6 h: %d\n\n", r.Sleep.NightsUnder6h)
+	}
+
+	b.WriteString("## Medication Adherence\n\n")
+	if r.Adherence == nil {
+		b.WriteString("No adherence history.\n\n")
+	} else {
+		fmt.Fprintf(&b, "%.1f%% of doses taken (%d taken, %d missed over %d days).\n",
+			r.Adherence.Percent, r.Adherence.Taken, r.Adherence.Missed, r.Adherence.Days)
+		if len(r.Adherence.MostMissed) > 0 {
+			fmt.Fprintf(&b, "Most often missed: %s.\n", strings.Join(r.Adherence.MostMissed, ", "))
+		}
+		if len(r.Adherence.PerMed) > 0 {
+			b.WriteString("\n")
+			for _, s := range r.Adherence.PerMed {
+				fmt.Fprintf(&b, "- %s\n", medStatsLine(s))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Tags) > 0 {
+		b.WriteString("## Tagged Periods\n\n")
+		for _, t := range r.Tags {
+			fmt.Fprintf(&b, "- %s to %s: %s", t.From, t.To, t.Kind)
+			if t.Note != "" {
+				fmt.Fprintf(&b, " (%s)", t.Note)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Flagged Days\n\n")
+	if len(r.Anomalies) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, a := range r.Anomalies {
+		fmt.Fprintf(&b, "- %s: %s", a.Date, a.Description)
+		if len(a.Tags) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(a.Tags, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Errors) > 0 {
+		b.WriteString("\n## Data Issues\n\n")
+		for _, e := range r.Errors {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
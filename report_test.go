@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== CLINICAL REPORT TESTS ====================
+
+func TestBuildClinicalReport(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('resting_heart_rate', '2024-01-01 06:00:00 +0700', 50, 'bpm', 'Apple Watch'),
+		('resting_heart_rate', '2024-01-01 06:00:01 +0700', 70, 'bpm', 'iPhone'),
+		('resting_heart_rate', '2024-01-02 06:00:00 +0700', 52, 'bpm', 'Apple Watch'),
+		('resting_heart_rate', '2024-01-03 06:00:00 +0700', 60, 'bpm', 'Apple Watch'),
+		('resting_heart_rate', '2024-01-09 06:00:00 +0700', 99, 'bpm', 'Apple Watch'),
+		('weight_body_mass', '2024-01-01 07:00:00 +0700', 80.0, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-02 07:00:00 +0700', 81.0, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-03 07:00:00 +0700', 79.2, 'kg', 'Scale'),
+		('sleep_total', '2024-01-01 06:00:00 +0700', 7.5, 'hr', 'Apple Watch'),
+		('sleep_total', '2024-01-02 06:00:00 +0700', 4.5, 'hr', 'Apple Watch'),
+		('sleep_deep', '2024-01-01 06:00:00 +0700', 1.0, 'hr', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := BuildClinicalReport(db, "2024-01-01", "2024-01-03", time.Now(), nil)
+
+	if len(r.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+
+	// iPhone reading is ignored on days the Watch reported; 2024-01-09 is out of range
+	if len(r.Vitals) != 1 {
+		t.Fatalf("len(Vitals) = %d, want 1", len(r.Vitals))
+	}
+	rhr := r.Vitals[0]
+	if rhr.Days != 3 || rhr.Min != 50 || rhr.Max != 60 || rhr.Avg != 54 {
+		t.Errorf("resting HR = %+v, want 3 days 50/54/60", rhr)
+	}
+
+	if r.Weight == nil {
+		t.Fatal("Weight = nil")
+	}
+	if r.Weight.StartKg != 80 || r.Weight.EndKg != 79.2 || r.Weight.ChangeKg != -0.8 || r.Weight.MaxKg != 81 {
+		t.Errorf("Weight = %+v", *r.Weight)
+	}
+
+	if r.Sleep == nil {
+		t.Fatal("Sleep = nil")
+	}
+	if r.Sleep.Nights != 2 || r.Sleep.AvgTotalHours != 6 || r.Sleep.NightsUnder6h != 1 {
+		t.Errorf("Sleep = %+v", *r.Sleep)
+	}
+	if r.Sleep.AvgDeepHours == nil || *r.Sleep.AvgDeepHours != 1 {
+		t.Errorf("AvgDeepHours = %v, want 1", r.Sleep.AvgDeepHours)
+	}
+	if r.Sleep.AvgREMHours != nil {
+		t.Errorf("AvgREMHours = %v, want nil", *r.Sleep.AvgREMHours)
+	}
+
+	// 60 bpm is 11% above the 54 mean; 4.5h sleep is under 5h
+	want := []string{"2024-01-02 sleep_total", "2024-01-03 resting_heart_rate"}
+	if len(r.Anomalies) != len(want) {
+		t.Fatalf("Anomalies = %+v, want %v", r.Anomalies, want)
+	}
+	for i, a := range r.Anomalies {
+		if got := a.Date + " " + a.Metric; got != want[i] {
+			t.Errorf("Anomalies[%d] = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	tests := []struct {
+		name   string
+		series map[string][]dailyValue
+		want   []string // metric names, in order
+	}{
+		{
+			name:   "empty",
+			series: map[string][]dailyValue{},
+			want:   nil,
+		},
+		{
+			name: "HRV dip",
+			series: map[string][]dailyValue{
+				"heart_rate_variability": {{"2024-01-01", 60}, {"2024-01-02", 60}, {"2024-01-03", 30}},
+			},
+			want: []string{"heart_rate_variability"},
+		},
+		{
+			name: "low SpO2",
+			series: map[string][]dailyValue{
+				"blood_oxygen_saturation": {{"2024-01-01", 97}, {"2024-01-02", 91}},
+			},
+			want: []string{"blood_oxygen_saturation"},
+		},
+		{
+			name: "steady vitals",
+			series: map[string][]dailyValue{
+				"resting_heart_rate":     {{"2024-01-01", 55}, {"2024-01-02", 57}},
+				"heart_rate_variability": {{"2024-01-01", 45}, {"2024-01-02", 40}},
+				"sleep_total":            {{"2024-01-01", 7}, {"2024-01-02", 5}},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectAnomalies(tt.series, nil)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DetectAnomalies() = %+v, want %v", got, tt.want)
+			}
+			for i, a := range got {
+				if a.Metric != tt.want[i] {
+					t.Errorf("anomaly %d metric = %q, want %q", i, a.Metric, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestQueryMedAdherence(t *testing.T) {
+	db := newTestHistoryDB(t)
+
+	save := func(date string, completed, missed []string) {
+		b := EveningBriefing{Protocols: ProtocolsData{Completed: completed, Missed: missed}}
+		data, _ := json.Marshal(b)
+		_, err := saveHistoryRecord(db, HistoryRecord{
+			Date: date, Mode: "evening", GeneratedAt: date + "T21:00:00Z", Source: "live", JSON: data,
+		}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	save("2024-01-01", []string{"Metformin", "Vitamin D"}, nil)
+	save("2024-01-02", []string{"Vitamin D"}, []string{"Metformin"})
+	save("2024-01-03", nil, nil)
+	save("2024-02-01", nil, []string{"Vitamin D"})
+	if err := saveMedLog(db, "2024-01-02", medResults(ProtocolsData{Completed: []string{"Vitamin D"}, Missed: []string{"Metformin"}})); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := queryMedAdherence(db, "2024-01-01", "2024-01-31")
+	if err != nil {
+		t.Fatalf("queryMedAdherence() error: %v", err)
+	}
+	if a == nil {
+		t.Fatal("adherence = nil")
+	}
+	// Days without any protocols don't count
+	if a.Days != 2 || a.Taken != 3 || a.Missed != 1 || a.Percent != 75 {
+		t.Errorf("adherence = %+v, want 2 days, 3 taken, 1 missed, 75%%", *a)
+	}
+	if len(a.MostMissed) != 1 || a.MostMissed[0] != "Metformin" {
+		t.Errorf("MostMissed = %v, want [Metformin]", a.MostMissed)
+	}
+	if len(a.PerMed) != 2 || a.PerMed[0].Name != "Metformin" || a.PerMed[0].Missed != 1 || a.PerMed[1].Streak != 1 {
+		t.Errorf("PerMed = %+v, want Metformin missed and Vitamin D on a 1-day streak", a.PerMed)
+	}
+
+	a, err = queryMedAdherence(db, "2023-01-01", "2023-12-31")
+	if err != nil {
+		t.Fatalf("queryMedAdherence() error: %v", err)
+	}
+	if a != nil {
+		t.Errorf("adherence = %+v, want nil with no history", *a)
+	}
+}
+
+func TestRenderClinicalMarkdown(t *testing.T) {
+	deep := 1.2
+	r := ClinicalReport{
+		From:      "2024-01-01",
+		To:        "2024-03-31",
+		Vitals:    []VitalRange{{Name: "Resting heart rate", Unit: "bpm", Days: 90, Min: 48, Avg: 53.2, Max: 61}},
+		Weight:    &WeightTrajectory{Readings: 40, StartKg: 82, EndKg: 79.5, ChangeKg: -2.5, MinKg: 79.1, MaxKg: 82.3},
+		Sleep:     &SleepAverages{Nights: 88, AvgTotalHours: 7.1, AvgDeepHours: &deep, NightsUnder6h: 4},
+		Anomalies: []Anomaly{{Date: "2024-02-10", Metric: "sleep_total", Description: "Slept 4.2h (under 5h)"}},
+	}
+
+	var out strings.Builder
+	if err := RenderClinicalMarkdown(&out, r); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		"# Health Summary: 2024-01-01 to 2024-03-31",
+		"| Resting heart rate (bpm) | 90 | 48.0 | 53.2 | 61.0 |",
+		"82.0 kg → 79.5 kg (-2.5 kg)",
+		"- Average deep: 1.2 h",
+		"No adherence history.",
+		"- 2024-02-10: Slept 4.2h (under 5h)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown missing %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Average REM") {
+		t.Error("markdown should omit missing REM average")
+	}
+}
+
+func TestRunReportCommandValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no report type", []string{"--from", "2024-01-01"}},
+		{"missing from", []string{"--clinical"}},
+		{"bad range", []string{"--clinical", "--from", "2024-02-01", "--to", "2024-01-01"}},
+		{"bad format", []string{"--clinical", "--from", "2024-01-01", "--format", "pdf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RunReportCommand(tt.args); err == nil {
+				t.Error("RunReportCommand() = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ActivityRings is the day's Apple Move, Exercise, and Stand progress
+type ActivityRings struct {
+	MoveKcal       float64 `json:"move_kcal"`
+	MoveGoalKcal   int     `json:"move_goal_kcal"`
+	MovePct        int     `json:"move_pct"`
+	ExerciseMin    float64 `json:"exercise_min"`
+	ExerciseGoal   int     `json:"exercise_goal_min"`
+	ExercisePct    int     `json:"exercise_pct"`
+	StandHours     int     `json:"stand_hours"`
+	StandGoalHours int     `json:"stand_goal_hours"`
+	StandPct       int     `json:"stand_pct"`
+	Closed         bool    `json:"closed"` // all three at 100% or more
+}
+
+// validateRings checks the ring goals
+func validateRings(cfg RingsConfig) error {
+	if cfg.MoveKcal <= 0 || cfg.ExerciseMin <= 0 || cfg.StandHours <= 0 {
+		return errors.New("rings: move_kcal, exercise_min, and stand_hours must be positive")
+	}
+	return nil
+}
+
+// activityRingsFor measures the day's totals against the goals
+func activityRingsFor(moveKcal, exerciseMin float64, standHours int, goals RingsConfig) ActivityRings {
+	r := ActivityRings{
+		MoveKcal:       moveKcal,
+		MoveGoalKcal:   goals.MoveKcal,
+		MovePct:        ringPct(moveKcal, goals.MoveKcal),
+		ExerciseMin:    exerciseMin,
+		ExerciseGoal:   goals.ExerciseMin,
+		ExercisePct:    ringPct(exerciseMin, goals.ExerciseMin),
+		StandHours:     standHours,
+		StandGoalHours: goals.StandHours,
+		StandPct:       ringPct(float64(standHours), goals.StandHours),
+	}
+	r.Closed = r.MovePct >= 100 && r.ExercisePct >= 100 && r.StandPct >= 100
+	return r
+}
+
+// ringPct is value as a whole percentage of goal
+func ringPct(value float64, goal int) int {
+	if goal <= 0 {
+		return 0
+	}
+	return int(math.Round(value / float64(goal) * 100))
+}
+
+// ringsValue formats the rings, e.g. "move 84%, exercise 117%, stand 83%"
+func ringsValue(r ActivityRings) string {
+	value := fmt.Sprintf("move %d%%, exercise %d%%, stand %d%%", r.MovePct, r.ExercisePct, r.StandPct)
+	if r.Closed {
+		value += " (closed)"
+	}
+	return value
+}
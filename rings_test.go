@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== ACTIVITY RINGS TESTS ====================
+
+func TestActivityRingsFor(t *testing.T) {
+	goals := DefaultConfig().Rings
+	tests := []struct {
+		name                              string
+		move, exercise                    float64
+		stand                             int
+		wantMove, wantExercise, wantStand int
+		wantClosed                        bool
+	}{
+		{"nothing yet", 0, 0, 0, 0, 0, 0, false},
+		{"partway", 420, 35, 10, 84, 117, 83, false},
+		{"exactly closed", 500, 30, 12, 100, 100, 100, true},
+		{"overachieved", 900, 75, 14, 180, 250, 117, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := activityRingsFor(tt.move, tt.exercise, tt.stand, goals)
+			if r.MovePct != tt.wantMove || r.ExercisePct != tt.wantExercise || r.StandPct != tt.wantStand || r.Closed != tt.wantClosed {
+				t.Errorf("activityRingsFor() = %+v, want %d/%d/%d closed %v", r, tt.wantMove, tt.wantExercise, tt.wantStand, tt.wantClosed)
+			}
+		})
+	}
+}
+
+func TestEveningRingsFromDB(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('active_energy', '2024-01-15 10:00:00 +0700', 300, 'kcal', 'Apple Watch'),
+		('active_energy', '2024-01-15 18:00:00 +0700', 250, 'kcal', 'Apple Watch'),
+		('exercise_minutes', '2024-01-15 18:00:00 +0700', 32, 'min', 'Apple Watch'),
+		('stand_hours', '2024-01-15 12:00:00 +0700', 12, 'hr', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	want := ActivityRings{
+		MoveKcal: 550, MoveGoalKcal: 500, MovePct: 110,
+		ExerciseMin: 32, ExerciseGoal: 30, ExercisePct: 107,
+		StandHours: 12, StandGoalHours: 12, StandPct: 100,
+		Closed: true,
+	}
+	if e.Rings != want {
+		t.Errorf("Rings = %+v, want %+v", e.Rings, want)
+	}
+	if text := EveningText(e, textStyle{}); !strings.Contains(text, "Rings    move 110%, exercise 107%, stand 100% (closed)\n") {
+		t.Errorf("text missing rings line:\n%s", text)
+	}
+	if vars := eveningRuleVars(e); vars["rings_closed"] != true || vars["rings.exercise_pct"] != 107.0 {
+		t.Errorf("rule vars = %v, %v; want closed at 107%%", vars["rings_closed"], vars["rings.exercise_pct"])
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Rule severities
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// Alert delivery channels
+const (
+	NotifyWebhook  = "webhook"  // POST to every configured webhook URL
+	NotifyMQTT     = "mqtt"     // publish to <prefix>/alert
+	NotifyNtfy     = "ntfy"     // push to delivery.ntfy
+	NotifyPushover = "pushover" // push to delivery.pushover
+)
+
+var ruleSeverities = []string{SeverityLow, SeverityMedium, SeverityHigh}
+var ruleChannels = []string{NotifyWebhook, NotifyMQTT, NotifyNtfy, NotifyPushover}
+
+// Alert is a rule that matched on this run
+type Alert struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	When     string `json:"when"`
+	Notify   string `json:"notify,omitempty"`
+}
+
+// AlertEvent is the JSON posted to webhooks when a rule matches
+type AlertEvent struct {
+	Event       string `json:"event"`
+	Mode        string `json:"mode"`
+	Date        string `json:"date"`
+	GeneratedAt string `json:"generated_at"`
+	Alert
+}
+
+// validateRules checks rule expressions, severities, and channels up front
+// so a typo in the config fails loudly instead of silently never firing
+func validateRules(rules []RuleConfig) error {
+	for i, r := range rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if r.When == "" {
+			return fmt.Errorf("rule %s: missing when", name)
+		}
+		if _, err := ParseExpr(r.When); err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+		if r.Severity != "" && !slices.Contains(ruleSeverities, r.Severity) {
+			return fmt.Errorf("rule %s: unknown severity %q", name, r.Severity)
+		}
+		if r.Notify != "" && !slices.Contains(ruleChannels, r.Notify) {
+			return fmt.Errorf("rule %s: unknown notify channel %q", name, r.Notify)
+		}
+	}
+	return nil
+}
+
+// EvaluateRules returns an alert for every rule whose condition holds.
+// Rules that reference missing data don't fire and aren't errors.
+func EvaluateRules(rules []RuleConfig, vars map[string]any) ([]Alert, []error) {
+	var alerts []Alert
+	var errs []error
+	for _, r := range rules {
+		node, err := ParseExpr(r.When)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", r.Name, err))
+			continue
+		}
+		matched, err := EvalBool(node, vars)
+		if errors.Is(err, errMissingVariable) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", r.Name, err))
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		severity := r.Severity
+		if severity == "" {
+			severity = SeverityMedium
+		}
+		name := r.Name
+		if name == "" {
+			name = r.When
+		}
+		alerts = append(alerts, Alert{Rule: name, Severity: severity, When: r.When, Notify: r.Notify})
+	}
+	return alerts, errs
+}
+
+// floatVar unwraps optional metrics so missing values stay missing
+func floatVar(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// zeroMissingVar treats the evening briefing's zero-for-missing values as missing
+func zeroMissingVar(v float64) any {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+// morningRuleVars exposes morning briefing fields to rule expressions
+func morningRuleVars(b MorningBriefing) map[string]any {
+	vars := map[string]any{
+		"sleep.total":              floatVar(b.Sleep.TotalHours),
+		"sleep.deep":               floatVar(b.Sleep.DeepHours),
+		"sleep.rem":                floatVar(b.Sleep.REMHours),
+		"sleep.core":               floatVar(b.Sleep.CoreHours),
+		"sleep.debt":               floatVar(b.Sleep.SleepDebtHours),
+		"hrv":                      floatVar(b.Vitals.HRV),
+		"hrv_baseline":             floatVar(b.Vitals.HRVBaseline),
+		"hrv_baseline_7d":          floatVar(b.Vitals.HRVBaseline7d),
+		"hrv_deviation":            floatVar(b.Vitals.HRVDeviation),
+		"rhr":                      floatVar(b.Vitals.RestingHR),
+		"rhr_baseline":             floatVar(b.Vitals.RestingHRBaseline),
+		"rhr_delta":                floatVar(b.Vitals.RestingHRDelta),
+		"spo2":                     floatVar(b.Vitals.SpO2),
+		"respiratory_rate":         floatVar(b.Vitals.RespiratoryRate),
+		"respiratory_rate_delta":   floatVar(b.Vitals.RespiratoryRateDelta),
+		"temperature":              floatVar(b.Vitals.Temperature),
+		"temperature_deviation":    floatVar(b.Vitals.TemperatureDeviation),
+		"sleep_quality":            b.Classification.SleepQuality,
+		"recovery_status":          b.Classification.RecoveryStatus,
+		"morning_load":             b.Classification.MorningLoad,
+		"task_pressure":            b.Classification.TaskPressure,
+		"tasks.overdue":            float64(b.Tasks.OverdueCount),
+		"sleep_consistency":        b.Sleep.Consistency,
+		"illness_risk":             b.Classification.IllnessRisk,
+		"calendar.morning_count":   float64(b.Calendar.MorningCount),
+		"calendar.morning_weight":  morningWeight(b.Calendar.MorningEvents),
+		"calendar.all_day_count":   float64(len(b.Calendar.AllDayEvents)),
+		"calendar.conflicts":       float64(len(b.Calendar.Conflicts)),
+		"travel.early_departures":  float64(earlyDepartures(b.Calendar)),
+		"travel.trips":             0.0,
+		"jetlag.shift_hours":       0.0,
+		"meds.due":                 float64(len(b.Meds.DueToday)),
+		"meds.overdue":             float64(len(b.Meds.Overdue)),
+		"meds.missed_recently":     float64(len(b.Meds.MissedRecently)),
+		"meds.reorder":             float64(len(reorderLines(b.Meds.Inventory))),
+		"cycles.changing_soon":     float64(cyclesChangingSoon(b.Meds.Cycles)),
+		"training.days_since_last": float64(b.Training.DaysSinceLast),
+		"training.weekly_count":    float64(b.Training.WeeklyCount),
+	}
+	vars["focus.count"] = 0.0
+	if f := b.Focus; f != nil {
+		vars["focus.count"] = float64(len(f.Tasks) + f.More)
+	}
+	if w := b.Work; w != nil {
+		vars["github.review_requests"] = float64(len(w.ReviewRequests) + w.MoreReviews)
+		vars["github.failing_checks"] = float64(len(w.FailingChecks) + w.MoreFailing)
+	}
+	if s := b.Slack; s != nil {
+		dms, mentions := 0, 0
+		for _, w := range s.Workspaces {
+			dms += w.UnreadDMs
+			mentions += w.Mentions
+		}
+		vars["slack.unread_dms"] = float64(dms)
+		vars["slack.mentions"] = float64(mentions)
+	}
+	vars["oncall"] = b.OnCall != nil && b.OnCall.OnCall
+	if m := b.Markets; m != nil {
+		for _, q := range m.Quotes {
+			vars["markets."+quoteVar(q.Symbol)] = q.ChangePct
+		}
+	}
+	if n := b.News; n != nil {
+		vars["news.count"] = float64(len(n.Headlines))
+	}
+	if m := b.Mail; m != nil {
+		vars["mail.unread"] = float64(m.Unread)
+	}
+	if b.Travel != nil {
+		vars["travel.trips"] = float64(len(b.Travel.Trips))
+	}
+	if b.JetLagPlan != nil {
+		vars["jetlag.shift_hours"] = b.JetLagPlan.ShiftHours
+	}
+	for _, c := range medCategories {
+		vars["meds.overdue."+c] = float64(len(b.Meds.Categories.of(c).Overdue))
+	}
+	if b.Classification.ReadinessScore != nil {
+		vars["readiness"] = float64(*b.Classification.ReadinessScore)
+	}
+	if b.Sleep.ConsistencyScore != nil {
+		vars["sleep.consistency"] = float64(*b.Sleep.ConsistencyScore)
+	}
+	if bp := b.Vitals.BloodPressure; bp != nil {
+		vars["bp.systolic"] = bp.Systolic
+		vars["bp.diastolic"] = bp.Diastolic
+		vars["bp_status"] = bp.Status
+		vars["bp_trend"] = bp.Trend
+	}
+	vars["alcohol"] = b.Alcohol != nil
+	if f := b.Fasting; f != nil {
+		vars["fasting_hours"] = f.Hours
+	}
+	if a := b.Alcohol; a != nil {
+		vars["alcohol.drinks"] = a.Drinks
+	}
+	if s := b.Steps; s != nil {
+		vars["steps.yesterday"] = float64(s.Yesterday)
+		vars["steps.goal_pct"] = float64(s.GoalPct)
+		vars["steps.avg_7d"] = floatVar(s.Avg7d)
+	}
+	if v := b.Training.VO2Max; v != nil {
+		vars["vo2_max"] = v.Value
+		vars["vo2_max_trend"] = v.Trend
+	}
+	if z := b.Training.HRZones; z != nil {
+		vars["zones.z1"] = z.Z1
+		vars["zones.z2"] = z.Z2
+		vars["zones.z3"] = z.Z3
+		vars["zones.z4"] = z.Z4
+		vars["zones.z5"] = z.Z5
+	}
+	if p := b.Training.Zone2; p != nil {
+		vars["zone2.pct"] = float64(p.Pct)
+	}
+	if h := b.Calendar.FocusHours; h != nil {
+		vars["calendar.focus_hours"] = *h
+	}
+	if m := b.Calendar.CommuteMinutes; m != nil {
+		vars["calendar.commute_minutes"] = float64(*m)
+	}
+	if s := b.Calendar.BestWorkoutSlot; s != nil {
+		vars["workout_slot.minutes"] = float64(s.Minutes)
+	}
+	if w := b.Weather; w != nil {
+		vars["weather.high"] = w.HighC
+		vars["weather.low"] = w.LowC
+		vars["weather.rain_chance"] = float64(w.RainChance)
+		vars["weather.humidity"] = float64(w.Humidity)
+		vars["weather.training"] = w.Training
+		vars["weather.uv_index"] = w.UVIndex
+	}
+	if aq := b.AirQuality; aq != nil {
+		vars["air.aqi"] = float64(aq.AQI)
+		vars["air_quality"] = aq.Classification
+	}
+	if p := b.Pollen; p != nil {
+		vars["pollen"] = p.Level
+		for _, c := range p.Types {
+			vars["pollen."+c.Name] = c.Grains
+		}
+	}
+	if g := b.Glucose; g != nil {
+		vars["glucose.fasting"] = floatVar(g.Fasting)
+		vars["glucose.overnight"] = floatVar(g.OvernightAvg)
+		vars["glucose.time_in_range"] = floatVar(g.TimeInRange)
+		vars["glucose.spikes"] = float64(len(g.Spikes))
+	}
+	if b.Checkin != nil {
+		vars["checkin.mood"] = floatVar(b.Checkin.Mood)
+		vars["checkin.energy"] = floatVar(b.Checkin.Energy)
+		vars["checkin.soreness"] = floatVar(b.Checkin.Soreness)
+		vars["checkin.sleep_feel"] = floatVar(b.Checkin.SleepFeel)
+		vars["checkin.motivation"] = floatVar(b.Checkin.Motivation)
+	}
+	return vars
+}
+
+// eveningRuleVars exposes evening briefing fields to rule expressions
+func eveningRuleVars(b EveningBriefing) map[string]any {
+	vars := map[string]any{
+		"energy.balance":               float64(b.Energy.DeficitOrSurplusKcal),
+		"energy.consumed":              b.Energy.ConsumedKcal,
+		"energy.active":                b.Energy.ActiveKcal,
+		"energy.remaining_maintenance": float64(b.Energy.RemainingKcalToMaintenance),
+		"energy.remaining_goal":        float64(b.Energy.RemainingKcalToGoalDeficit),
+		"energy_status":                b.Energy.Status,
+		"protein.consumed":             b.Protein.ConsumedG,
+		"protein.remaining":            b.Protein.RemainingG,
+		"water.consumed":               float64(b.Hydration.ConsumedMl),
+		"water.remaining":              float64(b.Hydration.RemainingMl),
+		"steps":                        float64(b.Activity.Steps),
+		"steps.goal_pct":               float64(b.Activity.StepGoalPct),
+		"steps.avg_7d":                 zeroMissingVar(float64(b.Activity.Steps7dAvg)),
+		"stand_hours":                  float64(b.Activity.StandHours),
+		"rings.move_pct":               float64(b.Rings.MovePct),
+		"rings.exercise_pct":           float64(b.Rings.ExercisePct),
+		"rings.stand_pct":              float64(b.Rings.StandPct),
+		"rings_closed":                 b.Rings.Closed,
+		"hrv":                          zeroMissingVar(b.Recovery.HRVMS),
+		"rhr":                          zeroMissingVar(b.Recovery.RestingHRBPM),
+		"sleep.total":                  zeroMissingVar(b.Recovery.SleepLastNight.TotalHrs),
+		"sleep.deep":                   zeroMissingVar(b.Recovery.SleepLastNight.DeepHrs),
+		"protocols.missed":             float64(len(b.Protocols.Missed)),
+		"cycles.changing_soon":         float64(cyclesChangingSoon(b.Protocols.Cycles)),
+		"workout.done":                 b.Activity.Workout != nil && b.Activity.Workout.Done,
+		"travel.pack_tonight":          b.Travel != nil && b.Travel.PackingReminder,
+	}
+	if w := b.Weight; w != nil {
+		vars["weight.current"] = w.CurrentKg
+		vars["weight.trend"] = w.TrendKg
+		vars["weight.change_week"] = floatVar(w.ChangePerWeekKg)
+		if c := w.Composition; c != nil {
+			vars["weight.body_fat_pct"] = floatVar(c.BodyFatPct)
+			vars["weight.lean_kg"] = floatVar(c.LeanMassKg)
+			vars["weight.fat_change_week"] = floatVar(c.FatChangePerWeekKg)
+			vars["weight.change_from"] = c.ChangeFrom
+		}
+	}
+	for _, c := range medCategories {
+		vars["protocols.missed."+c] = float64(len(b.Protocols.Categories.of(c).Missed))
+	}
+	vars["meals.count"] = float64(len(b.Meals))
+	if b.LateKcalPct != nil {
+		vars["meals.late_pct"] = float64(*b.LateKcalPct)
+	}
+	for _, m := range b.Micronutrients {
+		vars["micros."+m.Metric] = m.Amount
+		vars["micros."+m.Metric+".status"] = m.Status
+	}
+	if w := b.Eating; w != nil {
+		vars["eating_window.hours"] = w.Hours
+		vars["eating_window.last_meal"] = w.LastMeal
+	}
+	if g := b.Energy.Goal; g != nil {
+		vars["goal.remaining"] = g.RemainingKg
+		vars["goal.pace"] = g.Pace
+	}
+	return vars
+}
+
+// alertPush lays an alert out for the push senders: the rule as the title
+// and its condition as the message, sent at high priority for high severity
+func alertPush(e AlertEvent) Delivery {
+	page := briefingPage{Title: e.Rule, Lead: e.When}
+	if e.Severity == SeverityHigh {
+		page.Sections = []pageSection{{Items: []pageItem{{Text: "high severity", Alert: true}}}}
+	}
+	return Delivery{Mode: e.Mode, Date: e.Date, Subject: "Alert: " + e.Rule, Page: page}
+}
+
+// dispatchAlerts delivers alerts over their configured channels
+func dispatchAlerts(cfg Config, mode, date, generatedAt string, alerts []Alert) []error {
+	var errs []error
+	var mqttMessages []MQTTMessage
+	for _, alert := range alerts {
+		event := AlertEvent{
+			Event:       "rule_triggered",
+			Mode:        mode,
+			Date:        date,
+			GeneratedAt: generatedAt,
+			Alert:       alert,
+		}
+		switch alert.Notify {
+		case NotifyWebhook:
+			for _, wh := range cfg.Webhooks {
+				if err := postJSON(wh.URL, event); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		case NotifyMQTT:
+			payload, _ := json.Marshal(event)
+			mqttMessages = append(mqttMessages, MQTTMessage{
+				Topic:   cfg.MQTT.TopicPrefix + "/alert",
+				Payload: payload,
+			})
+		case NotifyNtfy:
+			if err := sendNtfy(cfg.Delivery.Ntfy, alertPush(event)); err != nil {
+				errs = append(errs, fmt.Errorf("ntfy alert error: %w", err))
+			}
+		case NotifyPushover:
+			if err := sendPushover(cfg.Delivery.Pushover, alertPush(event)); err != nil {
+				errs = append(errs, fmt.Errorf("pushover alert error: %w", err))
+			}
+		}
+	}
+
+	if len(mqttMessages) > 0 && cfg.MQTT.Broker != "" {
+		// Alerts are events, not state: never retain them
+		mqttCfg := cfg.MQTT
+		mqttCfg.Retain = false
+		if err := PublishMQTT(mqttCfg, mqttMessages); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
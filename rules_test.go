@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== RULE EVALUATION TESTS ====================
+
+func TestEvaluateRules(t *testing.T) {
+	b := MorningBriefing{
+		Sleep:  SleepData{TotalHours: ptr(5.2)},
+		Vitals: VitalsData{HRV: ptr(30.0), HRVBaseline: ptr(50.0)},
+		Classification: Classification{
+			SleepQuality:   "POOR",
+			RecoveryStatus: "POOR",
+		},
+	}
+
+	rules := []RuleConfig{
+		{Name: "crashed", When: "hrv < hrv_baseline * 0.7 and sleep.total < 6", Notify: NotifyWebhook, Severity: SeverityHigh},
+		{Name: "fine", When: "sleep.total > 7"},
+		{When: "sleep_quality == 'POOR'"},
+		{Name: "no spo2", When: "spo2 < 92"}, // missing metric: doesn't fire
+	}
+
+	alerts, errs := EvaluateRules(rules, morningRuleVars(b))
+	if len(errs) != 0 {
+		t.Fatalf("EvaluateRules() errors: %v", errs)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("len(alerts) = %d, want 2: %+v", len(alerts), alerts)
+	}
+	if alerts[0].Rule != "crashed" || alerts[0].Severity != SeverityHigh || alerts[0].Notify != NotifyWebhook {
+		t.Errorf("alerts[0] = %+v", alerts[0])
+	}
+	// Unnamed rules use their expression; severity defaults to medium
+	if alerts[1].Rule != "sleep_quality == 'POOR'" || alerts[1].Severity != SeverityMedium {
+		t.Errorf("alerts[1] = %+v", alerts[1])
+	}
+}
+
+func TestEvaluateRulesReportsErrors(t *testing.T) {
+	rules := []RuleConfig{
+		{Name: "type", When: "recovery_status > 3"},
+		{Name: "syntax", When: "hrv <"},
+	}
+	vars := map[string]any{"recovery_status": "POOR", "hrv": 40.0}
+
+	alerts, errs := EvaluateRules(rules, vars)
+	if len(alerts) != 0 {
+		t.Errorf("len(alerts) = %d, want 0", len(alerts))
+	}
+	if len(errs) != 2 {
+		t.Errorf("len(errs) = %d, want 2", len(errs))
+	}
+}
+
+func TestEveningRuleVars(t *testing.T) {
+	b := newEveningBriefing(time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC), "2024-01-15")
+	b.Energy.DeficitOrSurplusKcal = -600
+	b.Protein.RemainingG = 40
+	b.Protocols.Missed = []string{"Creatine"}
+
+	vars := eveningRuleVars(b)
+	if vars["energy.balance"] != -600.0 {
+		t.Errorf("energy.balance = %v, want -600", vars["energy.balance"])
+	}
+	if vars["protocols.missed"] != 1.0 {
+		t.Errorf("protocols.missed = %v, want 1", vars["protocols.missed"])
+	}
+	// Zero HRV means no data, not zero HRV
+	if vars["hrv"] != nil {
+		t.Errorf("hrv = %v, want nil", vars["hrv"])
+	}
+	if vars["workout.done"] != false {
+		t.Errorf("workout.done = %v, want false", vars["workout.done"])
+	}
+}
+
+// ==================== RULE VALIDATION TESTS ====================
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    RuleConfig
+		wantErr string
+	}{
+		{"valid", RuleConfig{When: "hrv < 30", Notify: NotifyMQTT, Severity: SeverityLow}, ""},
+		{"ntfy", RuleConfig{When: "hrv < 30", Notify: "ntfy"}, ""},
+		{"pushover", RuleConfig{When: "hrv < 30", Notify: "pushover"}, ""},
+		{"missing when", RuleConfig{Name: "empty"}, "missing when"},
+		{"bad expression", RuleConfig{When: "hrv <<"}, "rule #1"},
+		{"bad severity", RuleConfig{When: "hrv < 30", Severity: "urgent"}, "unknown severity"},
+		{"bad channel", RuleConfig{When: "hrv < 30", Notify: "carrier-pigeon"}, "unknown notify channel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRules([]RuleConfig{tt.rule})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateRules() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateRules() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRejectsInvalidRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"rules": [{"name": "typo", "when": "hrv < < 30"}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig() expected error for invalid rule, got nil")
+	}
+}
+
+// ==================== ALERT DISPATCH TESTS ====================
+
+func TestDispatchAlertsWebhook(t *testing.T) {
+	var received []AlertEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event AlertEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode error: %v", err)
+		}
+		received = append(received, event)
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Webhooks = []WebhookConfig{{URL: srv.URL}}
+	alerts := []Alert{
+		{Rule: "crashed", Severity: SeverityHigh, When: "hrv < 20", Notify: NotifyWebhook},
+		{Rule: "quiet", Severity: SeverityLow, When: "steps < 100"}, // briefing-only
+	}
+
+	errs := dispatchAlerts(cfg, "morning", "2024-01-15", "2024-01-15T07:00:00Z", alerts)
+	if len(errs) != 0 {
+		t.Fatalf("dispatchAlerts() errors: %v", errs)
+	}
+	if len(received) != 1 {
+		t.Fatalf("received %d events, want 1", len(received))
+	}
+	got := received[0]
+	if got.Event != "rule_triggered" || got.Rule != "crashed" || got.Mode != "morning" || got.Date != "2024-01-15" {
+		t.Errorf("event = %+v", got)
+	}
+}
+
+func TestDispatchAlertsPush(t *testing.T) {
+	var ntfyTitle, ntfyPriority, ntfyBody string
+	ntfy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ntfyTitle, ntfyPriority = r.Header.Get("Title"), r.Header.Get("Priority")
+		b, _ := io.ReadAll(r.Body)
+		ntfyBody = string(b)
+	}))
+	defer ntfy.Close()
+	var form url.Values
+	pushover := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+	}))
+	defer pushover.Close()
+	saved := pushoverAPIURL
+	pushoverAPIURL = pushover.URL
+	t.Cleanup(func() { pushoverAPIURL = saved })
+
+	cfg := DefaultConfig()
+	cfg.Delivery.Ntfy = NtfyConfig{Server: ntfy.URL, Topic: "alerts"}
+	cfg.Delivery.Pushover = PushoverConfig{Token: "app", User: "me"}
+	alerts := []Alert{
+		{Rule: "crashed", Severity: SeverityHigh, When: "hrv < 20", Notify: NotifyNtfy},
+		{Rule: "protein gap", Severity: SeverityMedium, When: "protein.remaining > 50", Notify: NotifyPushover},
+	}
+
+	if errs := dispatchAlerts(cfg, "morning", "2024-01-15", "2024-01-15T07:00:00Z", alerts); len(errs) != 0 {
+		t.Fatalf("dispatchAlerts() errors: %v", errs)
+	}
+	if ntfyTitle != "Alert: crashed" || ntfyPriority != "high" || ntfyBody != "hrv < 20\n⚠ high severity" {
+		t.Errorf("ntfy = %q, %q, %q", ntfyTitle, ntfyPriority, ntfyBody)
+	}
+	if form.Get("title") != "Alert: protein gap" || form.Get("message") != "protein.remaining > 50" || form.Get("priority") != "" {
+		t.Errorf("pushover form = %v", form)
+	}
+
+	// An unconfigured channel is reported
+	cfg.Delivery.Ntfy = NtfyConfig{}
+	if errs := dispatchAlerts(cfg, "morning", "2024-01-15", "2024-01-15T07:00:00Z", alerts[:1]); len(errs) != 1 || !strings.Contains(errs[0].Error(), "ntfy alert error") {
+		t.Errorf("dispatchAlerts() errors = %v, want ntfy alert error", errs)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// server exposes briefings over HTTP
+type server struct {
+	cfg         Config
+	morning     *briefingCache[MorningBriefing]
+	evening     *briefingCache[EveningBriefing]
+	historyPath string
+}
+
+// RunServeCommand handles `briefing serve [--listen addr]`
+func RunServeCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", ":8700", "Address to listen on")
+	cacheTTL := fs.Duration("cache", 5*time.Minute, "How long a generated briefing is reused")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+
+	s := &server{
+		cfg:         cfg,
+		morning:     newBriefingCache(*cacheTTL, BuildMorningBriefing),
+		evening:     newBriefingCache(*cacheTTL, BuildEveningBriefing),
+		historyPath: getHistoryDBPath(),
+	}
+
+	go pruneLoop(cfg.Retention)
+
+	log.Printf("briefing server listening on %s", *listen)
+	return http.ListenAndServe(*listen, s.routes())
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleDashboard)
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /briefing/morning", s.handleMorning)
+	mux.HandleFunc("GET /briefing/evening", s.handleEvening)
+	mux.HandleFunc("GET /api/briefing", s.handleBriefing)
+	mux.HandleFunc("GET /api/history", s.handleHistory)
+	mux.HandleFunc("POST /api/regenerate", s.handleRegenerate)
+	mux.HandleFunc("GET /grafana/{$}", s.handleGrafanaTest)
+	mux.HandleFunc("POST /grafana/search", s.handleGrafanaSearch)
+	mux.HandleFunc("POST /grafana/query", s.handleGrafanaQuery)
+	mux.HandleFunc("GET /ha/sensor", s.handleHASensor)
+	mux.HandleFunc("GET /glance", s.handleGlance)
+	return mux
+}
+
+// handleHealthz reports that the server is up without building a briefing
+func (s *server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) handleMorning(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(s.morning.ttl.Seconds())))
+	writeJSON(w, http.StatusOK, s.morning.Get(time.Now()))
+}
+
+func (s *server) handleEvening(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(s.evening.ttl.Seconds())))
+	writeJSON(w, http.StatusOK, s.evening.Get(time.Now()))
+}
+
+func (s *server) handleHASensor(w http.ResponseWriter, r *http.Request) {
+	b := s.morning.Get(time.Now())
+	writeJSON(w, http.StatusOK, NewHASensor(b))
+}
+
+func (s *server) handleGlance(w http.ResponseWriter, r *http.Request) {
+	b := s.morning.Get(time.Now())
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(s.morning.ttl.Seconds())))
+	writeJSON(w, http.StatusOK, NewGlance(b))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestServer returns a server whose briefing builders return fixed data
+func newTestServer(b MorningBriefing) *server {
+	return &server{
+		cfg:     DefaultConfig(),
+		morning: newBriefingCache(time.Minute, func(time.Time) MorningBriefing { return b }),
+		evening: newBriefingCache(time.Minute, func(time.Time) EveningBriefing { return EveningBriefing{Mode: "evening"} }),
+	}
+}
+
+// ==================== SERVER ENDPOINT TESTS ====================
+
+func TestHandleHASensor(t *testing.T) {
+	s := newTestServer(MorningBriefing{
+		Classification: Classification{SleepQuality: "POOR", RecoveryStatus: "OK"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ha/sensor", nil)
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var sensor HASensor
+	if err := json.Unmarshal(rec.Body.Bytes(), &sensor); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if sensor.State != "POOR" {
+		t.Errorf("State = %q, want %q", sensor.State, "POOR")
+	}
+	if sensor.Attributes.RecoveryStatus != "OK" {
+		t.Errorf("Attributes.RecoveryStatus = %q, want %q", sensor.Attributes.RecoveryStatus, "OK")
+	}
+}
+
+func TestHandleBriefingEndpoints(t *testing.T) {
+	s := newTestServer(MorningBriefing{TargetDate: "2024-01-15", Classification: Classification{ReadinessScore: intPtr(72)}})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/healthz", `"status":"ok"`},
+		{"/briefing/morning", `"readiness_score":72`},
+		{"/briefing/evening", `"mode":"evening"`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		rec := httptest.NewRecorder()
+		s.routes().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s status = %d, want %d", tt.path, rec.Code, http.StatusOK)
+		}
+		if !contains(rec.Body.String(), tt.want) {
+			t.Errorf("%s body = %s, want it to contain %s", tt.path, rec.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestHandleBriefingUsesCache(t *testing.T) {
+	builds := 0
+	s := newTestServer(MorningBriefing{})
+	s.evening = newBriefingCache(time.Minute, func(time.Time) EveningBriefing {
+		builds++
+		return EveningBriefing{}
+	})
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/briefing/evening", nil))
+		if cc := rec.Header().Get("Cache-Control"); cc != "max-age=60" {
+			t.Errorf("Cache-Control = %q, want max-age=60", cc)
+		}
+	}
+	if builds != 1 {
+		t.Errorf("evening built %d times, want 1", builds)
+	}
+}
+
+func TestHandleHASensorRejectsPost(t *testing.T) {
+	s := newTestServer(MorningBriefing{})
+
+	req := httptest.NewRequest(http.MethodPost, "/ha/sensor", nil)
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandleGlance(t *testing.T) {
+	s := newTestServer(MorningBriefing{
+		Calendar: CalendarData{
+			MorningEvents: []CalendarEvent{{Time: "09:00", Summary: "Team standup"}},
+		},
+		Meds: MedsData{DueToday: []MedTask{{Name: "Nexium"}}},
+		Classification: Classification{
+			SleepQuality:   "GOOD",
+			ReadinessScore: intPtr(82),
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/glance", nil)
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec.Body.Len() > 1024 {
+		t.Errorf("glance payload = %d bytes, want <= 1024", rec.Body.Len())
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "max-age=60" {
+		t.Errorf("Cache-Control = %q, want %q", cc, "max-age=60")
+	}
+
+	var g Glance
+	if err := json.Unmarshal(rec.Body.Bytes(), &g); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if g.ReadinessScore == nil || *g.ReadinessScore != 82 {
+		t.Errorf("ReadinessScore = %v, want 82", g.ReadinessScore)
+	}
+	if g.FirstEvent != "09:00 Team standup" {
+		t.Errorf("FirstEvent = %q, want %q", g.FirstEvent, "09:00 Team standup")
+	}
+	if g.MedsDue != 1 {
+		t.Errorf("MedsDue = %d, want 1", g.MedsDue)
+	}
+}
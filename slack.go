@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// slackMaxText is Block Kit's limit on a section's text
+const slackMaxText = 3000
+
+// slackStatusEmoji marks classifications, since Block Kit text has no color
+var slackStatusEmoji = map[string]string{
+	"GOOD": ":large_green_circle:", "CLEAR": ":large_green_circle:",
+	"OK": ":large_yellow_circle:", "LIGHT": ":large_yellow_circle:",
+	"POOR": ":red_circle:", "PACKED": ":red_circle:",
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"` // notification fallback
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func validateSlack(cfg SlackConfig) error {
+	if cfg.WebhookURL == "" {
+		return errors.New("delivery.slack: webhook_url is required")
+	}
+	return nil
+}
+
+// slackEscape escapes the characters Slack treats as markup
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func slackStatus(status string) string {
+	if status == "" {
+		return ""
+	}
+	if emoji, ok := slackStatusEmoji[status]; ok {
+		return " " + emoji + " " + status
+	}
+	return " " + status
+}
+
+func slackSection(text string) slackBlock {
+	if len(text) > slackMaxText {
+		text = text[:slackMaxText-1] + "…"
+	}
+	return slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}}
+}
+
+// slackBlocks lays out the page as a header, the recommendation, and one
+// section per page section (sleep and recovery, calendar, meds, ...)
+func slackBlocks(p briefingPage) []slackBlock {
+	blocks := []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: p.Title}}}
+	if p.Lead != "" {
+		blocks = append(blocks, slackSection("> "+slackEscape(p.Lead)))
+	}
+	for _, sec := range p.Sections {
+		var b strings.Builder
+		b.WriteString("*" + slackEscape(sec.Title) + "*" + slackStatus(sec.Status))
+		for _, r := range sec.Rows {
+			b.WriteString("\n" + slackEscape(r.Label) + ": *" + slackEscape(r.Value) + "*" + slackStatus(r.Status))
+		}
+		for _, item := range sec.Items {
+			if item.Alert {
+				b.WriteString("\n• :warning: " + slackEscape(item.Text))
+			} else {
+				b.WriteString("\n• " + slackEscape(item.Text))
+			}
+		}
+		if len(sec.Rows) == 0 && len(sec.Items) == 0 && sec.Empty != "" {
+			b.WriteString("\n_" + slackEscape(sec.Empty) + "_")
+		}
+		blocks = append(blocks, slackBlock{Type: "divider"}, slackSection(b.String()))
+	}
+	return blocks
+}
+
+// sendSlack posts the briefing to a Slack incoming webhook
+func sendSlack(cfg SlackConfig, d Delivery) error {
+	if err := validateSlack(cfg); err != nil {
+		return err
+	}
+	return postDelivery(cfg.WebhookURL, slackMessage{Text: d.Subject, Blocks: slackBlocks(d.Page)})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== SLACK TESTS ====================
+
+func TestSlackBlocks(t *testing.T) {
+	p := briefingPage{
+		Title: "Morning 2024-01-15",
+		Lead:  "Take it <easy>",
+		Sections: []pageSection{
+			{Title: "Recovery", Rows: []pageRow{{Label: "Sleep", Value: "5.5 h", Status: "POOR"}}},
+			{Title: "Today", Status: "CLEAR", Empty: "Nothing scheduled"},
+			{Title: "Meds", Items: []pageItem{{Text: "Vitamin D (overdue)", Alert: true}, {Text: "Magnesium"}}},
+		},
+	}
+	blocks := slackBlocks(p)
+	if len(blocks) != 8 {
+		t.Fatalf("got %d blocks, want header, lead, and 3 divider+section pairs", len(blocks))
+	}
+	if blocks[0].Type != "header" || blocks[0].Text.Text != "Morning 2024-01-15" {
+		t.Errorf("header = %+v", blocks[0])
+	}
+	if blocks[1].Text.Text != "> Take it &lt;easy&gt;" {
+		t.Errorf("lead = %q", blocks[1].Text.Text)
+	}
+	tests := []struct {
+		block int
+		want  string
+	}{
+		{3, "*Recovery*\nSleep: *5.5 h* :red_circle: POOR"},
+		{5, "*Today* :large_green_circle: CLEAR\n_Nothing scheduled_"},
+		{7, "*Meds*\n• :warning: Vitamin D (overdue)\n• Magnesium"},
+	}
+	for _, tt := range tests {
+		if blocks[tt.block-1].Type != "divider" {
+			t.Errorf("block %d = %q, want divider", tt.block-1, blocks[tt.block-1].Type)
+		}
+		if got := blocks[tt.block].Text.Text; got != tt.want {
+			t.Errorf("block %d = %q, want %q", tt.block, got, tt.want)
+		}
+	}
+}
+
+func TestSlackSectionTruncates(t *testing.T) {
+	b := slackSection(strings.Repeat("x", slackMaxText+10))
+	if len(b.Text.Text) > slackMaxText+len("…") {
+		t.Errorf("section text is %d bytes, want at most %d", len(b.Text.Text), slackMaxText)
+	}
+}
+
+func TestSendSlack(t *testing.T) {
+	var got slackMessage
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer ts.Close()
+
+	d := morningDelivery(MorningBriefing{TargetDate: "2024-01-15"})
+	if err := sendSlack(SlackConfig{WebhookURL: ts.URL}, d); err != nil {
+		t.Fatalf("sendSlack() error: %v", err)
+	}
+	if got.Text != d.Subject || len(got.Blocks) == 0 || got.Blocks[0].Text.Text != "Morning 2024-01-15" {
+		t.Errorf("message = %+v", got)
+	}
+
+	if err := sendSlack(SlackConfig{}, d); err == nil {
+		t.Error("sendSlack() without webhook_url = nil, want error")
+	}
+}
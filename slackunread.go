@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// slackTimeout bounds each Slack Web API request
+const slackTimeout = 15 * time.Second
+
+// slackHistoryLimit is the most unread messages checked for mentions per channel
+const slackHistoryLimit = 100
+
+// SlackData is what's unread for you in each Slack workspace
+type SlackData struct {
+	Workspaces []SlackUnread `json:"workspaces"`
+}
+
+// SlackUnread is one workspace's unread direct messages and mentions
+type SlackUnread struct {
+	Name      string         `json:"name"`
+	UnreadDMs int            `json:"unread_dms"` // across DMs and group DMs
+	Mentions  int            `json:"mentions"`   // of you, in unread channel messages
+	Channels  []SlackChannel `json:"channels,omitempty"`
+}
+
+// SlackChannel is a channel with unread mentions of you, most first
+type SlackChannel struct {
+	Name     string `json:"name"`
+	Mentions int    `json:"mentions"`
+}
+
+// validateSlackUnread checks that every workspace has a name and token, and
+// the warning threshold
+func validateSlackUnread(cfg SlackUnreadConfig) error {
+	for i, w := range cfg.Workspaces {
+		if w.Name == "" || w.Token == "" {
+			return fmt.Errorf("slack.workspaces[%d]: name and token are required", i)
+		}
+	}
+	if cfg.WarnMentions < 0 {
+		return errors.New("slack.warn_mentions must not be negative")
+	}
+	return nil
+}
+
+// getSlackData reads each of slack.workspaces; a failing workspace is
+// reported and the others kept
+func getSlackData(b *MorningBriefing, today string) {
+	cfg := settings.Slack
+	if len(cfg.Workspaces) == 0 {
+		return
+	}
+	client := &http.Client{Timeout: slackTimeout}
+	d := &SlackData{}
+	for _, w := range cfg.Workspaces {
+		unread, err := fetchSlackUnread(client, cfg.URL, w)
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("slack error (%s): %v", w.Name, err))
+			continue
+		}
+		d.Workspaces = append(d.Workspaces, unread)
+	}
+	if len(d.Workspaces) > 0 {
+		b.Slack = d
+	}
+}
+
+// fetchSlackUnread goes through the conversations you're in: a DM's unread
+// count is taken as is, and a channel's unread messages are searched for
+// mentions of you
+func fetchSlackUnread(client *http.Client, base string, w SlackWorkspace) (SlackUnread, error) {
+	s := slackClient{http: client, base: strings.TrimRight(base, "/"), token: w.Token}
+	unread := SlackUnread{Name: w.Name}
+	var auth struct {
+		UserID string `json:"user_id"`
+	}
+	if err := s.get("auth.test", nil, &auth); err != nil {
+		return unread, err
+	}
+	mention := "<@" + auth.UserID + ">"
+
+	params := url.Values{"types": {"public_channel,private_channel,mpim,im"}, "exclude_archived": {"true"}, "limit": {"200"}}
+	for {
+		var page struct {
+			Channels []struct {
+				ID string `json:"id"`
+			} `json:"channels"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		if err := s.get("users.conversations", params, &page); err != nil {
+			return unread, err
+		}
+		for _, c := range page.Channels {
+			var info struct {
+				Channel struct {
+					Name               string `json:"name"`
+					IsIM               bool   `json:"is_im"`
+					IsMPIM             bool   `json:"is_mpim"`
+					LastRead           string `json:"last_read"`
+					UnreadCountDisplay int    `json:"unread_count_display"`
+				} `json:"channel"`
+			}
+			if err := s.get("conversations.info", url.Values{"channel": {c.ID}}, &info); err != nil {
+				return unread, err
+			}
+			ch := info.Channel
+			if ch.UnreadCountDisplay == 0 {
+				continue
+			}
+			if ch.IsIM || ch.IsMPIM {
+				unread.UnreadDMs += ch.UnreadCountDisplay
+				continue
+			}
+			var history struct {
+				Messages []struct {
+					Text string `json:"text"`
+				} `json:"messages"`
+			}
+			if err := s.get("conversations.history", url.Values{"channel": {c.ID}, "oldest": {ch.LastRead}, "limit": {fmt.Sprint(slackHistoryLimit)}}, &history); err != nil {
+				return unread, err
+			}
+			n := 0
+			for _, m := range history.Messages {
+				if strings.Contains(m.Text, mention) {
+					n++
+				}
+			}
+			if n > 0 {
+				unread.Mentions += n
+				unread.Channels = append(unread.Channels, SlackChannel{Name: "#" + ch.Name, Mentions: n})
+			}
+		}
+		if page.ResponseMetadata.NextCursor == "" {
+			break
+		}
+		params.Set("cursor", page.ResponseMetadata.NextCursor)
+	}
+	slices.SortStableFunc(unread.Channels, func(a, b SlackChannel) int { return b.Mentions - a.Mentions })
+	return unread, nil
+}
+
+// slackClient calls the Slack Web API with a user token
+type slackClient struct {
+	http  *http.Client
+	base  string
+	token string
+}
+
+func (s slackClient) get(method string, params url.Values, out any) error {
+	req, err := http.NewRequest(http.MethodGet, s.base+"/"+method+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack API status %d", resp.StatusCode)
+	}
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("slack API: invalid response: %w", err)
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil || !status.OK {
+		return fmt.Errorf("slack API %s: %s", method, status.Error)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// slackLine sums up a workspace, e.g. "acme: 3 unread DMs, 12 mentions (#incidents 9, #eng 3)"
+func slackLine(w SlackUnread) string {
+	dms := "DMs"
+	if w.UnreadDMs == 1 {
+		dms = "DM"
+	}
+	mentions := "mentions"
+	if w.Mentions == 1 {
+		mentions = "mention"
+	}
+	line := fmt.Sprintf("%s: %d unread %s, %d %s", w.Name, w.UnreadDMs, dms, w.Mentions, mentions)
+	if len(w.Channels) > 0 {
+		channels := make([]string, len(w.Channels))
+		for i, c := range w.Channels {
+			channels[i] = fmt.Sprintf("%s %d", c.Name, c.Mentions)
+		}
+		line += " (" + strings.Join(channels, ", ") + ")"
+	}
+	return line
+}
+
+// slackLines lists the workspaces
+func slackLines(d *SlackData) []string {
+	if d == nil {
+		return nil
+	}
+	lines := make([]string, len(d.Workspaces))
+	for i, w := range d.Workspaces {
+		lines[i] = slackLine(w)
+	}
+	return lines
+}
+
+// slackWarn reports whether one of the workspace's channels has
+// slack.warn_mentions or more unread mentions
+func slackWarn(w SlackUnread, cfg SlackUnreadConfig) bool {
+	return cfg.WarnMentions > 0 && len(w.Channels) > 0 && w.Channels[0].Mentions >= cfg.WarnMentions
+}
+
+// slackBusiest is the channel with the most unread mentions across
+// workspaces, and its workspace
+func slackBusiest(d *SlackData) (SlackChannel, string) {
+	var top SlackChannel
+	var workspace string
+	if d == nil {
+		return top, workspace
+	}
+	for _, w := range d.Workspaces {
+		if len(w.Channels) > 0 && w.Channels[0].Mentions > top.Mentions {
+			top, workspace = w.Channels[0], w.Name
+		}
+	}
+	return top, workspace
+}
+
+// slackNote is appended to the recommendation when a channel has
+// slack.warn_mentions or more unread mentions
+func slackNote(d *SlackData, cfg SlackUnreadConfig) string {
+	top, workspace := slackBusiest(d)
+	if cfg.WarnMentions == 0 || top.Mentions < cfg.WarnMentions {
+		return ""
+	}
+	where := top.Name
+	if len(d.Workspaces) > 1 {
+		where += " (" + workspace + ")"
+	}
+	return fmt.Sprintf(" %d unread mentions in %s.", top.Mentions, where)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== SLACK UNREAD TESTS ====================
+
+// fakeSlack serves a workspace for token t where you (U1) have an unread DM,
+// a channel with two unread mentions, and a read channel
+func fakeSlack(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))
+			return
+		}
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/auth.test":
+			w.Write([]byte(`{"ok": true, "user_id": "U1"}`))
+		case "/users.conversations":
+			if q.Get("cursor") == "" {
+				w.Write([]byte(`{"ok": true, "channels": [{"id": "D1"}, {"id": "C1"}], "response_metadata": {"next_cursor": "n2"}}`))
+				return
+			}
+			w.Write([]byte(`{"ok": true, "channels": [{"id": "C2"}], "response_metadata": {"next_cursor": ""}}`))
+		case "/conversations.info":
+			switch q.Get("channel") {
+			case "D1":
+				w.Write([]byte(`{"ok": true, "channel": {"is_im": true, "unread_count_display": 3}}`))
+			case "C1":
+				w.Write([]byte(`{"ok": true, "channel": {"name": "incidents", "last_read": "1700000000.000100", "unread_count_display": 40}}`))
+			default:
+				w.Write([]byte(`{"ok": true, "channel": {"name": "random", "unread_count_display": 0}}`))
+			}
+		case "/conversations.history":
+			if q.Get("channel") != "C1" || q.Get("oldest") != "1700000000.000100" {
+				t.Errorf("history query = %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"ok": true, "messages": [{"text": "<@U1> db is down"}, {"text": "paging <@U1> again"}, {"text": "<@U2> fyi"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetSlackData(t *testing.T) {
+	ts := fakeSlack(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Slack = SlackUnreadConfig{URL: ts.URL + "/", WarnMentions: 2, Workspaces: []SlackWorkspace{
+		{Name: "acme", Token: "t"},
+		{Name: "oss", Token: "revoked"},
+	}}
+
+	b := &MorningBriefing{}
+	getSlackData(b, "2024-01-15")
+	if b.Slack == nil || len(b.Slack.Workspaces) != 1 {
+		t.Fatalf("slack = %+v, errors %v", b.Slack, b.Errors)
+	}
+	if got, want := slackLine(b.Slack.Workspaces[0]), "acme: 3 unread DMs, 2 mentions (#incidents 2)"; got != want {
+		t.Errorf("slackLine() = %q, want %q", got, want)
+	}
+	if len(b.Errors) != 1 || b.Errors[0] != "slack error (oss): slack API auth.test: invalid_auth" {
+		t.Errorf("errors = %v", b.Errors)
+	}
+	if got := slackNote(b.Slack, settings.Slack); got != " 2 unread mentions in #incidents." {
+		t.Errorf("slackNote() = %q", got)
+	}
+
+	// Off without workspaces
+	settings.Slack.Workspaces = nil
+	b = &MorningBriefing{}
+	getSlackData(b, "2024-01-15")
+	if b.Slack != nil || len(b.Errors) != 0 {
+		t.Errorf("slack without workspaces = %+v, %v", b.Slack, b.Errors)
+	}
+}
+
+func TestSlackNote(t *testing.T) {
+	d := &SlackData{Workspaces: []SlackUnread{
+		{Name: "acme", Mentions: 12, Channels: []SlackChannel{{Name: "#incidents", Mentions: 9}, {Name: "#eng", Mentions: 3}}},
+		{Name: "oss", Mentions: 4, Channels: []SlackChannel{{Name: "#help", Mentions: 4}}},
+	}}
+	tests := []struct {
+		name     string
+		warn     int
+		expected string
+	}{
+		{"over the threshold", 5, " 9 unread mentions in #incidents (acme)."},
+		{"under it", 10, ""},
+		{"never", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slackNote(d, SlackUnreadConfig{WarnMentions: tt.warn}); got != tt.expected {
+				t.Errorf("slackNote() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+	if slackNote(nil, SlackUnreadConfig{WarnMentions: 5}) != "" {
+		t.Error("expected no note without Slack")
+	}
+}
+
+func TestValidateSlackUnread(t *testing.T) {
+	if err := validateSlackUnread(DefaultConfig().Slack); err != nil {
+		t.Errorf("default: %v", err)
+	}
+	if err := validateSlackUnread(SlackUnreadConfig{Workspaces: []SlackWorkspace{{Name: "acme"}}}); err == nil {
+		t.Error("expected an error for a workspace without a token")
+	}
+	if err := validateSlackUnread(SlackUnreadConfig{WarnMentions: -1}); err == nil {
+		t.Error("expected an error for a negative warn_mentions")
+	}
+}
+
+func TestSlackRendering(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings = DefaultConfig()
+	m := MorningBriefing{TargetDate: "2024-01-15", Slack: &SlackData{Workspaces: []SlackUnread{
+		{Name: "acme", UnreadDMs: 1, Mentions: 12, Channels: []SlackChannel{{Name: "#incidents", Mentions: 12}}},
+	}}}
+	line := "acme: 1 unread DM, 12 mentions (#incidents 12)"
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Slack\n\n- "+line+"\n") {
+		t.Errorf("markdown missing slack:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Slack\n  "+line+"\n") {
+		t.Errorf("text missing slack:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Slack:\n- "+line+"\n") {
+		t.Errorf("prompt missing slack (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["slack.mentions"] != 12.0 || vars["slack.unread_dms"] != 1.0 {
+		t.Errorf("slack vars = %v, %v", vars["slack.mentions"], vars["slack.unread_dms"])
+	}
+	if r := RedactMorningBriefing(m); r.Slack.Workspaces[0].Channels[0].Name == "#incidents" || m.Slack.Workspaces[0].Channels[0].Name != "#incidents" {
+		t.Errorf("redacted slack = %+v", r.Slack)
+	}
+
+	classify(&m)
+	if !strings.Contains(m.Classification.Recommendation, " 12 unread mentions in #incidents.") {
+		t.Errorf("recommendation = %q", m.Classification.Recommendation)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Source fills part of the morning briefing from one integration. Problems
+// the source can describe itself go into b.Errors; a returned error is
+// recorded as "<name> error: ...".
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context, b *MorningBriefing) error
+}
+
+// registeredSources run in registration order, so sources that refine
+// earlier data (the health DB after health-ingest) register later
+var registeredSources []Source
+
+// RegisterSource adds a source to the registry; names must be unique
+func RegisterSource(s Source) {
+	if sourceByName(s.Name()) != nil {
+		panic(fmt.Sprintf("source %q registered twice", s.Name()))
+	}
+	registeredSources = append(registeredSources, s)
+}
+
+func sourceByName(name string) Source {
+	for _, s := range registeredSources {
+		if s.Name() == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func sourceNames() []string {
+	names := make([]string, len(registeredSources))
+	for i, s := range registeredSources {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// fillSource adapts one of the built-in fill functions to Source
+type fillSource struct {
+	name string
+	fill func(b *MorningBriefing, today string)
+}
+
+func (s fillSource) Name() string { return s.name }
+
+func (s fillSource) Fetch(ctx context.Context, b *MorningBriefing) error {
+	s.fill(b, b.TargetDate)
+	return nil
+}
+
+func init() {
+	RegisterSource(fillSource{"health-ingest", getHealthData})
+	RegisterSource(fillSource{"health-db", getHealthDataFromSQLite})
+	RegisterSource(fillSource{"calendar", getCalendarData})
+	RegisterSource(fillSource{"todoist", getMedsData})
+	RegisterSource(fillSource{"focus", getFocusData})
+	RegisterSource(fillSource{"hevy", getTrainingData})
+	RegisterSource(fillSource{"weather", getWeatherData})
+	RegisterSource(fillSource{"air-quality", getAirQualityData})
+	RegisterSource(fillSource{"pollen", getPollenData})
+	RegisterSource(fillSource{"github", getWorkData})
+	RegisterSource(fillSource{"gmail", getMailData})
+	RegisterSource(fillSource{"slack", getSlackData})
+	RegisterSource(fillSource{"news", getNewsData})
+	RegisterSource(fillSource{"markets", getMarketsData})
+	RegisterSource(fillSource{"oncall", getOnCallData})
+}
+
+// validateSources checks that every disabled source exists
+func validateSources(cfg SourcesConfig) error {
+	for _, name := range cfg.Disabled {
+		if sourceByName(name) == nil {
+			return fmt.Errorf("sources.disabled: unknown source %q (expected: %s)", name, strings.Join(sourceNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// fetchSources runs every enabled source into b, or only the named ones when given
+func fetchSources(ctx context.Context, b *MorningBriefing, cfg SourcesConfig, only ...string) {
+	for _, s := range registeredSources {
+		if slices.Contains(cfg.Disabled, s.Name()) || (len(only) > 0 && !slices.Contains(only, s.Name())) {
+			continue
+		}
+		if err := s.Fetch(ctx, b); err != nil {
+			b.Errors = append(b.Errors, fmt.Sprintf("%s error: %v", s.Name(), err))
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// fakeSource appends its name to ran and returns a fixed error
+type fakeSource struct {
+	name string
+	err  error
+	ran  *[]string
+}
+
+func (s fakeSource) Name() string { return s.name }
+
+func (s fakeSource) Fetch(ctx context.Context, b *MorningBriefing) error {
+	if s.ran != nil {
+		*s.ran = append(*s.ran, s.name)
+	}
+	return s.err
+}
+
+// withSources swaps the registry for the duration of a test
+func withSources(t *testing.T, sources ...Source) {
+	t.Helper()
+	saved := registeredSources
+	t.Cleanup(func() { registeredSources = saved })
+	registeredSources = nil
+	for _, s := range sources {
+		RegisterSource(s)
+	}
+}
+
+// ==================== SOURCE REGISTRY TESTS ====================
+
+func TestBuiltinSourcesRegistered(t *testing.T) {
+	want := []string{"health-ingest", "health-db", "calendar", "todoist", "focus", "hevy", "weather", "air-quality", "pollen", "github", "gmail", "slack", "news", "markets", "oncall"}
+	if got := sourceNames(); !slices.Equal(got, want) {
+		t.Errorf("sourceNames() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterSourceDuplicatePanics(t *testing.T) {
+	withSources(t, fakeSource{name: "a"})
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterSource() with a duplicate name did not panic")
+		}
+	}()
+	RegisterSource(fakeSource{name: "a"})
+}
+
+func TestFetchSources(t *testing.T) {
+	var ran []string
+	withSources(t,
+		fakeSource{name: "a", ran: &ran},
+		fakeSource{name: "b", err: errors.New("offline"), ran: &ran},
+		fakeSource{name: "c", ran: &ran},
+	)
+
+	tests := []struct {
+		name       string
+		disabled   []string
+		only       []string
+		wantRun    []string
+		wantErrors int
+	}{
+		{"all in order", nil, nil, []string{"a", "b", "c"}, 1},
+		{"disabled skipped", []string{"b"}, nil, []string{"a", "c"}, 0},
+		{"only named", nil, []string{"c", "a"}, []string{"a", "c"}, 0},
+		{"disabled wins over only", []string{"a"}, []string{"a"}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			var b MorningBriefing
+			fetchSources(context.Background(), &b, SourcesConfig{Disabled: tt.disabled}, tt.only...)
+			if !slices.Equal(ran, tt.wantRun) {
+				t.Errorf("ran %v, want %v", ran, tt.wantRun)
+			}
+			if len(b.Errors) != tt.wantErrors {
+				t.Errorf("Errors = %v, want %d", b.Errors, tt.wantErrors)
+			}
+			if tt.wantErrors > 0 && b.Errors[0] != "b error: offline" {
+				t.Errorf("Errors[0] = %q, want %q", b.Errors[0], "b error: offline")
+			}
+		})
+	}
+}
+
+func TestValidateSources(t *testing.T) {
+	if err := validateSources(SourcesConfig{Disabled: []string{"hevy", "todoist"}}); err != nil {
+		t.Errorf("validateSources() unexpected error: %v", err)
+	}
+	if err := validateSources(SourcesConfig{Disabled: []string{"strava"}}); err == nil {
+		t.Error("validateSources() = nil, want error for unknown source")
+	}
+}
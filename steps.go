@@ -0,0 +1,99 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// StepsAverageDays is the trailing window for the steps average
+const StepsAverageDays = 7
+
+// FlagSedentary marks a trailing steps average below user.sedentary_steps
+const FlagSedentary = "SEDENTARY"
+
+// StepsData is yesterday's steps against the goal, for the morning briefing
+type StepsData struct {
+	Yesterday int      `json:"yesterday"`
+	Goal      int      `json:"goal"`
+	GoalPct   int      `json:"goal_pct"`
+	Avg7d     *float64 `json:"avg_7d,omitempty"` // 7 days ending yesterday, days with steps only
+}
+
+// queryStepsAverage averages the daily step totals over the days ending on
+// date (inclusive), skipping days without steps; nil when none have any
+func queryStepsAverage(db *sql.DB, date string, days int) (*float64, error) {
+	sum, counted := 0.0, 0
+	for i := 0; i < days; i++ {
+		steps, err := queryDayTotal(db, "steps", addDays(date, -i))
+		if err != nil {
+			return nil, err
+		}
+		if steps > 0 {
+			sum += steps
+			counted++
+		}
+	}
+	if counted == 0 {
+		return nil, nil
+	}
+	avg := math.Round(sum / float64(counted))
+	return &avg, nil
+}
+
+// stepGoalPct is steps as a whole percentage of goal
+func stepGoalPct(steps, goal int) int {
+	if goal <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(steps) / float64(goal) * 100))
+}
+
+// sedentary reports whether a trailing average is below the threshold
+func sedentary(avg *float64, threshold int) bool {
+	return avg != nil && *avg < float64(threshold)
+}
+
+// stepsValue formats steps against the goal, e.g. "8432 / 10000 (84%), 7-day avg 9120"
+func stepsValue(steps, goal int, avg *float64) string {
+	value := fmt.Sprintf("%d / %d (%d%%)", steps, goal, stepGoalPct(steps, goal))
+	if avg != nil {
+		value += fmt.Sprintf(", 7-day avg %.0f", *avg)
+	}
+	return value
+}
+
+// queryMorningSteps reads yesterday's steps and the trailing average ending
+// yesterday; nil when yesterday has no steps
+func queryMorningSteps(db *sql.DB, today string) (*StepsData, error) {
+	yesterday := addDays(today, -1)
+	steps, err := queryDayTotal(db, "steps", yesterday)
+	if err != nil || steps == 0 {
+		return nil, err
+	}
+	avg, err := queryStepsAverage(db, yesterday, StepsAverageDays)
+	if err != nil {
+		return nil, err
+	}
+	goal := settings.User.StepGoal
+	return &StepsData{Yesterday: int(steps), Goal: goal, GoalPct: stepGoalPct(int(steps), goal), Avg7d: avg}, nil
+}
+
+// activityStepsValue formats the evening's steps, whose average is 0 when missing
+func activityStepsValue(a ActivityData) string {
+	var avg *float64
+	if a.Steps7dAvg > 0 {
+		v := float64(a.Steps7dAvg)
+		avg = &v
+	}
+	return stepsValue(a.Steps, a.StepGoal, avg)
+}
+
+// eveningStepsStatus is SEDENTARY when the evening flagged it
+func eveningStepsStatus(a ActivityData) string {
+	if slices.Contains(a.Flags, FlagSedentary) {
+		return FlagSedentary
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== STEPS TESTS ====================
+
+func TestQueryStepsAverage(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('steps', '2024-01-07 12:00:00 +0700', 20000, 'count', 'iPhone'),
+		('steps', '2024-01-09 09:00:00 +0700', 2000, 'count', 'iPhone'),
+		('steps', '2024-01-09 18:00:00 +0700', 2500, 'count', 'iPhone'),
+		('steps', '2024-01-12 12:00:00 +0700', 3500, 'count', 'iPhone'),
+		('steps', '2024-01-14 12:00:00 +0700', 4000, 'count', 'iPhone'),
+		('steps', '2024-01-15 12:00:00 +0700', 12000, 'count', 'iPhone')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 01-08..01-14: days without steps are skipped, 01-07 and 01-15 are outside
+	avg, err := queryStepsAverage(db, "2024-01-14", StepsAverageDays)
+	if err != nil {
+		t.Fatalf("queryStepsAverage() error: %v", err)
+	}
+	if avg == nil || *avg != 4000 {
+		t.Errorf("queryStepsAverage() = %v, want 4000", avg)
+	}
+	if avg, err := queryStepsAverage(db, "2024-02-01", StepsAverageDays); err != nil || avg != nil {
+		t.Errorf("queryStepsAverage() with no steps = %v, %v; want nil", avg, err)
+	}
+
+	// The morning reports yesterday and flags the low average
+	b := &MorningBriefing{TargetDate: "2024-01-15"}
+	fillMorningHealthFromDB(b, db, "2024-01-15", nil)
+	want := StepsData{Yesterday: 4000, Goal: UserStepGoal, GoalPct: 40, Avg7d: ptr(4000)}
+	if b.Steps == nil || b.Steps.Yesterday != want.Yesterday || b.Steps.GoalPct != want.GoalPct || *b.Steps.Avg7d != *want.Avg7d {
+		t.Errorf("Steps = %+v, want %+v", b.Steps, want)
+	}
+	if !slices.Contains(b.Vitals.Flags, FlagSedentary) {
+		t.Errorf("Flags = %v, want %s", b.Vitals.Flags, FlagSedentary)
+	}
+	if md := MorningMarkdown(*b); !strings.Contains(md, "- Steps yesterday: 4000 / 10000 (40%), 7-day avg 4000\n") {
+		t.Errorf("markdown missing steps:\n%s", md)
+	}
+
+	// The evening compares today with the week before it
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if e.Activity.Steps != 12000 || e.Activity.StepGoalPct != 120 || e.Activity.Steps7dAvg != 4000 {
+		t.Errorf("Activity = %+v, want 12000 steps at 120%% against a 4000 average", e.Activity)
+	}
+	if !slices.Equal(e.Activity.Flags, []string{FlagSedentary}) {
+		t.Errorf("Activity.Flags = %v, want [SEDENTARY]", e.Activity.Flags)
+	}
+	if text := EveningText(e, textStyle{}); !strings.Contains(text, "Steps    12000 / 10000 (120%), 7-day avg 4000  SEDENTARY\n") {
+		t.Errorf("text missing steps line:\n%s", text)
+	}
+}
+
+func TestStepGoalPct(t *testing.T) {
+	tests := []struct {
+		steps, goal, want int
+	}{
+		{8432, 10000, 84},
+		{0, 10000, 0},
+		{15000, 10000, 150},
+		{5000, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := stepGoalPct(tt.steps, tt.goal); got != tt.want {
+			t.Errorf("stepGoalPct(%d, %d) = %d, want %d", tt.steps, tt.goal, got, tt.want)
+		}
+	}
+}
+
+func TestSedentary(t *testing.T) {
+	if sedentary(nil, 5000) {
+		t.Error("sedentary(nil) = true, want false without data")
+	}
+	if !sedentary(ptr(4999), 5000) || sedentary(ptr(5000), 5000) {
+		t.Error("sedentary() should flag averages strictly below the threshold")
+	}
+}
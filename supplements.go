@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Supplement timings
+const (
+	SupplementAM          = "am"           // on waking, or with the first meal when taken with food
+	SupplementPM          = "pm"           // at bedtime, or with dinner when taken with food
+	SupplementPreWorkout  = "pre_workout"  // before today's workout, like am on rest days
+	SupplementPostWorkout = "post_workout" // after today's workout, like am on rest days
+)
+
+// SupplementPreWorkoutMinutes is how long before a workout pre-workout doses go
+const SupplementPreWorkoutMinutes = 30
+
+// SupplementWorkoutMinutes is the assumed workout length; calendar events carry
+// only a start time, so post-workout doses go this long after it
+const SupplementWorkoutMinutes = 60
+
+// SupplementsConfig is the day's anchor times and the supplements to schedule around them
+type SupplementsConfig struct {
+	Wake            string             `json:"wake"`             // HH:MM
+	FirstMeal       string             `json:"first_meal"`       // HH:MM
+	Dinner          string             `json:"dinner"`           // HH:MM
+	Bedtime         string             `json:"bedtime"`          // HH:MM
+	WorkoutKeywords []string           `json:"workout_keywords"` // calendar summaries containing one mark a workout
+	Items           []SupplementConfig `json:"items"`
+}
+
+// SupplementConfig is one supplement's timing rule
+type SupplementConfig struct {
+	Name         string   `json:"name"`
+	Timing       string   `json:"timing"` // am, pm, pre_workout, post_workout
+	WithFood     bool     `json:"with_food"`
+	ApartFrom    []string `json:"apart_from"`    // supplements to keep spacing_hours away from, e.g. zinc and iron
+	SpacingHours float64  `json:"spacing_hours"` // minimum gap to apart_from
+}
+
+// SupplementDose is one entry in today's schedule
+type SupplementDose struct {
+	Time string `json:"time"` // HH:MM
+	Name string `json:"name"`
+	Note string `json:"note"` // when and why, e.g. "with first meal", "2 h after Iron"
+}
+
+// validateSupplements checks the anchor times and each supplement's rule
+func validateSupplements(cfg SupplementsConfig) error {
+	for key, clock := range map[string]string{"wake": cfg.Wake, "first_meal": cfg.FirstMeal, "dinner": cfg.Dinner, "bedtime": cfg.Bedtime} {
+		if _, err := parseClock(clock); err != nil {
+			return fmt.Errorf("supplements.%s: %w", key, err)
+		}
+	}
+	for i, s := range cfg.Items {
+		if s.Name == "" {
+			return fmt.Errorf("supplements.items[%d]: name is required", i)
+		}
+		switch s.Timing {
+		case SupplementAM, SupplementPM, SupplementPreWorkout, SupplementPostWorkout:
+		default:
+			return fmt.Errorf("supplements.items[%d]: timing must be am, pm, pre_workout, or post_workout", i)
+		}
+		if s.SpacingHours < 0 || (len(s.ApartFrom) > 0 && s.SpacingHours == 0) {
+			return fmt.Errorf("supplements.items[%d]: apart_from needs a positive spacing_hours", i)
+		}
+	}
+	return nil
+}
+
+// workoutEvent is today's first calendar event that looks like a workout, nil on a rest day
+func workoutEvent(cfg SupplementsConfig, cal CalendarData) *CalendarEvent {
+	for _, e := range append(append([]CalendarEvent{}, cal.MorningEvents...), cal.AfternoonEvents...) {
+		summary := strings.ToLower(e.Summary)
+		for _, k := range cfg.WorkoutKeywords {
+			if strings.Contains(summary, strings.ToLower(k)) {
+				return &e
+			}
+		}
+	}
+	return nil
+}
+
+// supplementSlot places a supplement by its timing rule, in minutes after midnight
+func supplementSlot(cfg SupplementsConfig, s SupplementConfig, workout *CalendarEvent) (int, string) {
+	clock := func(hhmm string) int {
+		m, _ := parseClock(hhmm)
+		return m
+	}
+	if workout != nil {
+		start := clock(workout.Time)
+		switch s.Timing {
+		case SupplementPreWorkout:
+			return max(start-SupplementPreWorkoutMinutes, 0), "before " + workout.Summary
+		case SupplementPostWorkout:
+			return start + SupplementWorkoutMinutes, "after " + workout.Summary
+		}
+	}
+	if s.Timing == SupplementPM {
+		if s.WithFood {
+			return clock(cfg.Dinner), "with dinner"
+		}
+		return clock(cfg.Bedtime), "at bedtime"
+	}
+	if s.WithFood {
+		return clock(cfg.FirstMeal), "with first meal"
+	}
+	return clock(cfg.Wake), "on waking"
+}
+
+// keptApart reports whether either supplement asks to be spaced from the
+// other, and by how many minutes
+func keptApart(a, b SupplementConfig) (int, bool) {
+	lists := func(s SupplementConfig, name string) bool {
+		for _, n := range s.ApartFrom {
+			if strings.EqualFold(n, name) {
+				return true
+			}
+		}
+		return false
+	}
+	gap := 0.0
+	if lists(a, b.Name) {
+		gap = a.SpacingHours
+	}
+	if lists(b, a.Name) {
+		gap = max(gap, b.SpacingHours)
+	}
+	return int(gap * 60), gap > 0
+}
+
+// supplementScheduleFor lays out today's supplements around the anchor times
+// and the day's workout. Supplements are placed in config order; one that
+// lands too close to an earlier one it must be kept apart from moves to
+// after it.
+func supplementScheduleFor(cfg SupplementsConfig, cal CalendarData) []SupplementDose {
+	if len(cfg.Items) == 0 {
+		return nil
+	}
+	workout := workoutEvent(cfg, cal)
+	at := make([]int, len(cfg.Items))
+	doses := make([]SupplementDose, len(cfg.Items))
+	for i, s := range cfg.Items {
+		at[i], doses[i].Note = supplementSlot(cfg, s, workout)
+		// Each move is later than the last, so this settles within len(items) passes
+		for moved := true; moved; {
+			moved = false
+			for j := range i {
+				gap, apart := keptApart(s, cfg.Items[j])
+				if !apart || abs(at[i]-at[j]) >= gap {
+					continue
+				}
+				at[i] = at[j] + gap
+				doses[i].Note = fmt.Sprintf("%g h after %s", float64(gap)/60, cfg.Items[j].Name)
+				moved = true
+			}
+		}
+		doses[i].Name = s.Name
+		doses[i].Time = fmt.Sprintf("%02d:%02d", at[i]/60%24, at[i]%60)
+	}
+	sort.SliceStable(doses, func(a, b int) bool { return doses[a].Time < doses[b].Time })
+	return doses
+}
+
+// supplementLine formats a dose, e.g. "12:00 Vitamin D3 (with first meal)"
+func supplementLine(d SupplementDose) string {
+	return fmt.Sprintf("%s %s (%s)", d.Time, d.Name, d.Note)
+}
+
+func supplementLines(doses []SupplementDose) []string {
+	lines := make([]string, len(doses))
+	for i, d := range doses {
+		lines[i] = supplementLine(d)
+	}
+	return lines
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== SUPPLEMENT TESTS ====================
+
+func testSupplements() SupplementsConfig {
+	cfg := DefaultConfig().Supplements
+	cfg.Items = []SupplementConfig{
+		{Name: "Vitamin D3", Timing: SupplementAM, WithFood: true},
+		{Name: "Iron", Timing: SupplementAM},
+		{Name: "Zinc", Timing: SupplementAM, ApartFrom: []string{"iron", "Calcium"}, SpacingHours: 3},
+		{Name: "Calcium", Timing: SupplementAM},
+		{Name: "Creatine", Timing: SupplementPreWorkout},
+		{Name: "Magnesium", Timing: SupplementPM},
+	}
+	// Iron asks for the gap, so Calcium moves even though it lists nothing
+	cfg.Items[1].ApartFrom, cfg.Items[1].SpacingHours = []string{"Calcium"}, 4
+	return cfg
+}
+
+func TestValidateSupplements(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*SupplementsConfig)
+		expectError bool
+	}{
+		{"defaults", func(c *SupplementsConfig) { c.Items = nil }, false},
+		{"items", func(c *SupplementsConfig) {}, false},
+		{"bad anchor time", func(c *SupplementsConfig) { c.Dinner = "7pm" }, true},
+		{"missing name", func(c *SupplementsConfig) { c.Items[0].Name = "" }, true},
+		{"unknown timing", func(c *SupplementsConfig) { c.Items[0].Timing = "noon" }, true},
+		{"apart without spacing", func(c *SupplementsConfig) { c.Items[2].SpacingHours = 0 }, true},
+		{"negative spacing", func(c *SupplementsConfig) { c.Items[0].SpacingHours = -1 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testSupplements()
+			tt.modify(&cfg)
+			err := validateSupplements(cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateSupplements() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestSupplementScheduleFor(t *testing.T) {
+	cfg := testSupplements()
+	gymDay := CalendarData{
+		MorningEvents:   []CalendarEvent{{Time: "09:00", Summary: "Standup"}},
+		AfternoonEvents: []CalendarEvent{{Time: "17:30", Summary: "Gym - legs"}},
+	}
+
+	// Zinc moves off Iron, then Calcium off Iron and again off Zinc
+	got := supplementLines(supplementScheduleFor(cfg, gymDay))
+	want := []string{
+		"07:00 Iron (on waking)",
+		"10:00 Zinc (3 h after Iron)",
+		"12:00 Vitamin D3 (with first meal)",
+		"13:00 Calcium (3 h after Zinc)",
+		"17:00 Creatine (before Gym - legs)",
+		"23:00 Magnesium (at bedtime)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gym day schedule = %q, want %q", got, want)
+	}
+
+	// Without a workout, pre-workout doses are taken like am ones
+	rest := supplementScheduleFor(cfg, CalendarData{MorningEvents: gymDay.MorningEvents})
+	if line := supplementLine(rest[1]); line != "07:00 Creatine (on waking)" {
+		t.Errorf("rest day creatine = %q", line)
+	}
+
+	post := SupplementsConfig{WorkoutKeywords: cfg.WorkoutKeywords, Items: []SupplementConfig{{Name: "Whey", Timing: SupplementPostWorkout}, {Name: "Fish oil", Timing: SupplementPM, WithFood: true}}}
+	post.Dinner = "19:30"
+	if got := supplementLines(supplementScheduleFor(post, gymDay)); !reflect.DeepEqual(got, []string{"18:30 Whey (after Gym - legs)", "19:30 Fish oil (with dinner)"}) {
+		t.Errorf("post-workout schedule = %q", got)
+	}
+
+	if doses := supplementScheduleFor(DefaultConfig().Supplements, gymDay); doses != nil {
+		t.Errorf("schedule without supplements = %+v, want none", doses)
+	}
+}
+
+func TestSupplementRendering(t *testing.T) {
+	m := MorningBriefing{Supplements: supplementScheduleFor(testSupplements(), CalendarData{})}
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Supplements\n\n- 07:00 Iron (on waking)\n- 07:00 Creatine (on waking)\n") {
+		t.Errorf("markdown missing supplements:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Supplements\n  07:00  Iron (on waking)\n") {
+		t.Errorf("text missing supplements:\n%s", text)
+	}
+	if md := MorningMarkdown(MorningBriefing{}); strings.Contains(md, "Supplements") {
+		t.Errorf("markdown lists supplements when none are configured:\n%s", md)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Life-event tag kinds
+const (
+	TagTravel  = "travel"
+	TagIllness = "illness"
+	TagDeload  = "deload"
+)
+
+var tagKinds = []string{TagTravel, TagIllness, TagDeload}
+
+// LifeTag marks an inclusive date range whose data shouldn't shape baselines
+type LifeTag struct {
+	ID   int64  `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+	Note string `json:"note,omitempty"`
+}
+
+// RunTagCommand handles:
+//
+//	briefing tag --from YYYY-MM-DD [--to YYYY-MM-DD] [--note TEXT] travel|illness|deload
+//	briefing tag --list
+//	briefing tag --remove ID
+func RunTagCommand(args []string) error {
+	fs := flag.NewFlagSet("tag", flag.ContinueOnError)
+	from := fs.String("from", "", "First tagged date (YYYY-MM-DD)")
+	to := fs.String("to", "", "Last tagged date (default --from)")
+	note := fs.String("note", "", "Optional note")
+	list := fs.Bool("list", false, "List tags")
+	remove := fs.Int64("remove", 0, "Remove the tag with this ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := setupEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch {
+	case *list:
+		tags, err := listTags(db, "", "")
+		if err != nil {
+			return err
+		}
+		for _, t := range tags {
+			fmt.Printf("%d\t%s\t%s\t%s\t%s\n", t.ID, t.From, t.To, t.Kind, t.Note)
+		}
+		return nil
+	case *remove != 0:
+		return deleteTag(db, *remove)
+	}
+
+	if fs.NArg() != 1 || *from == "" {
+		return errors.New("usage: briefing tag --from YYYY-MM-DD [--to YYYY-MM-DD] [--note TEXT] travel|illness|deload")
+	}
+	if *to == "" {
+		*to = *from
+	}
+	t := LifeTag{From: *from, To: *to, Kind: fs.Arg(0), Note: *note}
+	if err := validateTag(t); err != nil {
+		return err
+	}
+
+	id, err := saveTag(db, t)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Tagged %s to %s as %s (id %d)\n", t.From, t.To, t.Kind, id)
+	return nil
+}
+
+func validateTag(t LifeTag) error {
+	if !slices.Contains(tagKinds, t.Kind) {
+		return fmt.Errorf("unknown tag %q (expected: travel, illness, deload)", t.Kind)
+	}
+	return validateDateRange(t.From, t.To)
+}
+
+// createTagsTable adds the tags table to the history store
+func createTagsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY,
+			from_date TEXT NOT NULL,
+			to_date TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+// saveTag stores a tag and returns its ID. The note is free text ("flu",
+// "Berlin offsite"), so it is sealed like the briefing JSON; the dates and
+// kind stay plain for baseline queries.
+func saveTag(db *sql.DB, t LifeTag) (int64, error) {
+	note := t.Note
+	if note != "" {
+		note = string(sealAtRest([]byte(note)))
+	}
+	res, err := db.Exec(`INSERT INTO tags (from_date, to_date, kind, note) VALUES (?, ?, ?, ?)`, t.From, t.To, t.Kind, note)
+	if err != nil {
+		return 0, fmt.Errorf("tag save error: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// deleteTag removes a tag by ID
+func deleteTag(db *sql.DB, id int64) error {
+	res, err := db.Exec(`DELETE FROM tags WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("tag delete error: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no tag with id %d", id)
+	}
+	return nil
+}
+
+// listTags returns tags overlapping [from, to], oldest first; empty bounds are open
+func listTags(db *sql.DB, from, to string) ([]LifeTag, error) {
+	query := `SELECT id, from_date, to_date, kind, note FROM tags WHERE 1 = 1`
+	var args []any
+	if from != "" {
+		query += ` AND to_date >= ?`
+		args = append(args, from)
+	}
+	if to != "" {
+		query += ` AND from_date <= ?`
+		args = append(args, to)
+	}
+	query += ` ORDER BY from_date, id`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("tag query error: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []LifeTag
+	for rows.Next() {
+		var t LifeTag
+		var note string
+		if err := rows.Scan(&t.ID, &t.From, &t.To, &t.Kind, &note); err != nil {
+			return tags, err
+		}
+		plain, err := openAtRest([]byte(note))
+		if err != nil {
+			return tags, fmt.Errorf("tag note error: %w", err)
+		}
+		t.Note = string(plain)
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// loadTags reads tags overlapping [from, to] from the history store,
+// returning none without error when no history has been recorded yet
+func loadTags(from, to string) ([]LifeTag, error) {
+	if _, err := os.Stat(getHistoryDBPath()); err != nil {
+		return nil, nil
+	}
+	db, err := openHistoryDB(getHistoryDBPath())
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return listTags(db, from, to)
+}
+
+// tagsOn returns the kinds of tags covering date
+func tagsOn(tags []LifeTag, date string) []string {
+	var kinds []string
+	for _, t := range tags {
+		if date >= t.From && date <= t.To && !slices.Contains(kinds, t.Kind) {
+			kinds = append(kinds, t.Kind)
+		}
+	}
+	return kinds
+}
+
+// tagExclusionSQL returns a WHERE fragment dropping metric rows on tagged
+// days, and its bind arguments. Empty when there are no tags.
+func tagExclusionSQL(tags []LifeTag) (string, []any) {
+	var clause string
+	var args []any
+	for _, t := range tags {
+		clause += ` AND substr(local_ts(timestamp), 1, 10) NOT BETWEEN ? AND ?`
+		args = append(args, t.From, t.To)
+	}
+	return clause, args
+}
+
+// untaggedValues drops days covered by a tag
+func untaggedValues(values []dailyValue, tags []LifeTag) []dailyValue {
+	if len(tags) == 0 {
+		return values
+	}
+	var kept []dailyValue
+	for _, v := range values {
+		if len(tagsOn(tags, v.Date)) == 0 {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ==================== LIFE-EVENT TAG TESTS ====================
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		tag         LifeTag
+		expectError bool
+	}{
+		{"illness", LifeTag{From: "2024-01-10", To: "2024-01-16", Kind: TagIllness}, false},
+		{"single day", LifeTag{From: "2024-01-10", To: "2024-01-10", Kind: TagTravel}, false},
+		{"unknown kind", LifeTag{From: "2024-01-10", To: "2024-01-16", Kind: "vacation"}, true},
+		{"reversed range", LifeTag{From: "2024-01-16", To: "2024-01-10", Kind: TagDeload}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTag(tt.tag)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateTag() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestTagStore(t *testing.T) {
+	db := newTestHistoryDB(t)
+
+	flu, err := saveTag(db, LifeTag{From: "2024-01-10", To: "2024-01-16", Kind: TagIllness, Note: "flu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := saveTag(db, LifeTag{From: "2024-03-01", To: "2024-03-05", Kind: TagTravel}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := listTags(db, "", "")
+	if err != nil {
+		t.Fatalf("listTags() error: %v", err)
+	}
+	if len(all) != 2 || all[0].Note != "flu" {
+		t.Fatalf("listTags() = %+v, want flu then travel", all)
+	}
+
+	// Ranges overlapping either edge are included
+	overlap, err := listTags(db, "2024-01-16", "2024-02-28")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlap) != 1 || overlap[0].Kind != TagIllness {
+		t.Errorf("listTags(overlap) = %+v, want the illness tag", overlap)
+	}
+
+	if err := deleteTag(db, flu); err != nil {
+		t.Fatalf("deleteTag() error: %v", err)
+	}
+	if err := deleteTag(db, flu); err == nil {
+		t.Error("deleteTag() of a missing tag should error")
+	}
+	all, _ = listTags(db, "", "")
+	if len(all) != 1 {
+		t.Errorf("tags after delete = %d, want 1", len(all))
+	}
+}
+
+func TestTagNoteEncrypted(t *testing.T) {
+	db := newTestHistoryDB(t)
+	if _, err := saveTag(db, LifeTag{From: "2024-01-10", To: "2024-01-16", Kind: TagIllness, Note: "flu"}); err != nil {
+		t.Fatal(err)
+	}
+	enableTestEncryption(t)
+	if _, err := saveTag(db, LifeTag{From: "2024-03-01", To: "2024-03-05", Kind: TagTravel, Note: "Berlin offsite"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored string
+	if err := db.QueryRow(`SELECT note FROM tags WHERE kind = ?`, TagTravel).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, sealedPrefix) || strings.Contains(stored, "Berlin") {
+		t.Errorf("stored note = %q, want sealed", stored)
+	}
+
+	// Notes saved before encryption was enabled stay readable
+	tags, err := listTags(db, "", "")
+	if err != nil {
+		t.Fatalf("listTags() error: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Note != "flu" || tags[1].Note != "Berlin offsite" {
+		t.Errorf("listTags() = %+v, want both notes in plain text", tags)
+	}
+}
+
+func TestTagsOn(t *testing.T) {
+	tags := []LifeTag{
+		{From: "2024-01-10", To: "2024-01-16", Kind: TagIllness},
+		{From: "2024-01-14", To: "2024-01-20", Kind: TagTravel},
+		{From: "2024-01-15", To: "2024-01-15", Kind: TagIllness},
+	}
+	if got := strings.Join(tagsOn(tags, "2024-01-15"), ","); got != "illness,travel" {
+		t.Errorf("tagsOn(2024-01-15) = %q, want illness,travel", got)
+	}
+	if got := tagsOn(tags, "2024-01-21"); got != nil {
+		t.Errorf("tagsOn(2024-01-21) = %v, want nil", got)
+	}
+}
+
+func TestHRVBaselineExcludesTaggedDays(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit) VALUES
+		('heart_rate_variability', '2024-01-10 06:00:00 +0700', 60, 'ms'),
+		('heart_rate_variability', '2024-01-12 06:00:00 +0700', 20, 'ms'),
+		('heart_rate_variability', '2024-01-13 06:00:00 +0700', 24, 'ms'),
+		('heart_rate_variability', '2024-01-14 06:00:00 +0700', 50, 'ms')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flu := []LifeTag{{From: "2024-01-12", To: "2024-01-13", Kind: TagIllness}}
+	baseline, err := queryHRVBaseline(db, "2024-01-15", 30, flu)
+	if err != nil {
+		t.Fatalf("queryHRVBaseline() error: %v", err)
+	}
+	if baseline == nil || *baseline != 55 {
+		t.Errorf("baseline = %v, want 55 with flu days excluded", baseline)
+	}
+
+	baseline, _ = queryHRVBaseline(db, "2024-01-15", 30, nil)
+	if baseline == nil || *baseline != 38.5 {
+		t.Errorf("untagged baseline = %v, want 38.5", baseline)
+	}
+}
+
+func TestDetectAnomaliesWithTags(t *testing.T) {
+	series := map[string][]dailyValue{
+		"resting_heart_rate": {
+			{"2024-01-01", 50}, {"2024-01-02", 50},
+			{"2024-01-03", 58}, {"2024-01-04", 60}, // illness
+		},
+	}
+	flu := []LifeTag{{From: "2024-01-03", To: "2024-01-04", Kind: TagIllness}}
+
+	// Untagged, the sick days inflate the mean (54.5) and only 60 stands out
+	if got := DetectAnomalies(series, nil); len(got) != 1 {
+		t.Errorf("untagged anomalies = %+v, want 1", got)
+	}
+
+	// Tagged, both sick days are judged against the healthy mean and annotated
+	got := DetectAnomalies(series, flu)
+	if len(got) != 2 {
+		t.Fatalf("tagged anomalies = %+v, want 2", got)
+	}
+	for _, a := range got {
+		if len(a.Tags) != 1 || a.Tags[0] != TagIllness {
+			t.Errorf("anomaly %s tags = %v, want [illness]", a.Date, a.Tags)
+		}
+	}
+}
+
+func TestRenderClinicalMarkdownTags(t *testing.T) {
+	r := ClinicalReport{
+		From:      "2024-01-01",
+		To:        "2024-01-31",
+		Tags:      []LifeTag{{From: "2024-01-10", To: "2024-01-16", Kind: TagIllness, Note: "flu"}},
+		Anomalies: []Anomaly{{Date: "2024-01-12", Metric: "sleep_total", Description: "Slept 4.0h (under 5h)", Tags: []string{TagIllness}}},
+	}
+	var out strings.Builder
+	if err := RenderClinicalMarkdown(&out, r); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Tagged Periods",
+		"- 2024-01-10 to 2024-01-16: illness (flu)",
+		"- 2024-01-12: Slept 4.0h (under 5h) [illness]",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("markdown missing %q\n%s", want, out.String())
+		}
+	}
+}
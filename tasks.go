@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TaskDebtOverdue is the number of overdue non-med tasks that counts as task debt
+const TaskDebtOverdue = 5
+
+// TaskDebtListSize is how many of the oldest overdue tasks the briefing names
+const TaskDebtListSize = 3
+
+// Task pressure statuses
+const (
+	TaskPressureOK   = "OK"
+	TaskPressureDebt = "TASK_DEBT"
+)
+
+// TasksData is the pile of overdue Todoist work outside meds
+type TasksData struct {
+	OverdueCount  int           `json:"overdue_task_count"`
+	OldestOverdue []OverdueTask `json:"oldest_overdue,omitempty"` // up to TaskDebtListSize, oldest first
+}
+
+// OverdueTask is one overdue task and how long it has waited
+type OverdueTask struct {
+	Name        string `json:"name"`
+	DueDate     string `json:"due_date"`
+	DaysOverdue int    `json:"days_overdue"`
+}
+
+// overdueTasksFor counts the open non-med tasks due before today and keeps the oldest
+func overdueTasksFor(tasks []TodoistTask, today string) TasksData {
+	var overdue []OverdueTask
+	for _, t := range tasks {
+		if t.IsCompleted || t.Due == nil || t.Due.Date >= today || isMedTask(t) {
+			continue
+		}
+		overdue = append(overdue, OverdueTask{Name: t.Content, DueDate: t.Due.Date, DaysOverdue: daysBetween(t.Due.Date, today)})
+	}
+	sort.SliceStable(overdue, func(i, j int) bool { return overdue[i].DueDate < overdue[j].DueDate })
+	d := TasksData{OverdueCount: len(overdue)}
+	if len(overdue) > 0 {
+		d.OldestOverdue = overdue[:min(len(overdue), TaskDebtListSize)]
+	}
+	return d
+}
+
+// classifyTaskPressure is TASK_DEBT from TaskDebtOverdue overdue tasks on
+func classifyTaskPressure(t TasksData) string {
+	if t.OverdueCount >= TaskDebtOverdue {
+		return TaskPressureDebt
+	}
+	return TaskPressureOK
+}
+
+// taskDebtLoad raises the morning load a step under task debt, since the
+// overdue pile competes with the calendar for the same hours
+func taskDebtLoad(load, pressure string) string {
+	if pressure != TaskPressureDebt {
+		return load
+	}
+	return raiseLoad(load)
+}
+
+// raiseLoad is the next morning load up: CLEAR to LIGHT, LIGHT to PACKED
+func raiseLoad(load string) string {
+	switch load {
+	case "CLEAR":
+		return "LIGHT"
+	case "LIGHT":
+		return "PACKED"
+	}
+	return load
+}
+
+// taskDebtNote is appended to the recommendation under task debt
+func taskDebtNote(t TasksData, pressure string) string {
+	if pressure != TaskPressureDebt {
+		return ""
+	}
+	return fmt.Sprintf(" %d overdue tasks: clear one early and reschedule the rest.", t.OverdueCount)
+}
+
+// overdueTasksLine summarizes the pile, e.g.
+// "7 overdue tasks, oldest: File taxes (9 days), Renew passport (6 days)"
+func overdueTasksLine(t TasksData) string {
+	if t.OverdueCount == 0 {
+		return ""
+	}
+	noun := "tasks"
+	if t.OverdueCount == 1 {
+		noun = "task"
+	}
+	oldest := make([]string, len(t.OldestOverdue))
+	for i, o := range t.OldestOverdue {
+		unit := "days"
+		if o.DaysOverdue == 1 {
+			unit = "day"
+		}
+		oldest[i] = fmt.Sprintf("%s (%d %s)", o.Name, o.DaysOverdue, unit)
+	}
+	return fmt.Sprintf("%d overdue %s, oldest: %s", t.OverdueCount, noun, strings.Join(oldest, ", "))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== TASK DEBT TESTS ====================
+
+func overdueTasks(n int) []TodoistTask {
+	tasks := make([]TodoistTask, n)
+	for i := range tasks {
+		tasks[i] = TodoistTask{Content: "Task", Due: &TodoistDue{Date: "2024-01-14"}}
+	}
+	return tasks
+}
+
+func TestOverdueTasksFor(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.MedLabels = []string{"💊Meds"}
+
+	tasks := []TodoistTask{
+		{Content: "Call bank", Due: &TodoistDue{Date: "2024-01-11"}},
+		{Content: "Nexium", Labels: []string{"💊Meds"}, Due: &TodoistDue{Date: "2024-01-01"}},
+		{Content: "File taxes", Due: &TodoistDue{Date: "2024-01-06"}},
+		{Content: "Standup notes", Due: &TodoistDue{Date: "2024-01-15"}},
+		{Content: "Water plants", Due: &TodoistDue{Date: "2024-01-14"}},
+		{Content: "Renew passport", Due: &TodoistDue{Date: "2024-01-09"}},
+		{Content: "Done already", IsCompleted: true, Due: &TodoistDue{Date: "2024-01-02"}},
+		{Content: "Someday"},
+	}
+	got := overdueTasksFor(tasks, "2024-01-15")
+	want := TasksData{OverdueCount: 4, OldestOverdue: []OverdueTask{
+		{Name: "File taxes", DueDate: "2024-01-06", DaysOverdue: 9},
+		{Name: "Renew passport", DueDate: "2024-01-09", DaysOverdue: 6},
+		{Name: "Call bank", DueDate: "2024-01-11", DaysOverdue: 4},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("overdueTasksFor() = %+v, want %+v", got, want)
+	}
+	if line := overdueTasksLine(got); line != "4 overdue tasks, oldest: File taxes (9 days), Renew passport (6 days), Call bank (4 days)" {
+		t.Errorf("overdueTasksLine() = %q", line)
+	}
+	one := overdueTasksFor(tasks[4:5], "2024-01-15")
+	if line := overdueTasksLine(one); line != "1 overdue task, oldest: Water plants (1 day)" {
+		t.Errorf("overdueTasksLine() = %q", line)
+	}
+	if got := overdueTasksFor(nil, "2024-01-15"); got.OverdueCount != 0 || got.OldestOverdue != nil || overdueTasksLine(got) != "" {
+		t.Errorf("overdueTasksFor(nil) = %+v", got)
+	}
+}
+
+func TestTaskDebtClassification(t *testing.T) {
+	tests := []struct {
+		name         string
+		overdue      int
+		morning      int
+		wantPressure string
+		wantLoad     string
+	}{
+		{"a few overdue", TaskDebtOverdue - 1, 0, TaskPressureOK, "CLEAR"},
+		{"clear morning", TaskDebtOverdue, 0, TaskPressureDebt, "LIGHT"},
+		{"light morning", TaskDebtOverdue, 2, TaskPressureDebt, "PACKED"},
+		{"already packed", TaskDebtOverdue + 10, 4, TaskPressureDebt, "PACKED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &MorningBriefing{
+				Sleep:    SleepData{TotalHours: ptr(7.5), DataAvailable: true},
+				Calendar: CalendarData{MorningCount: tt.morning},
+				Tasks:    overdueTasksFor(overdueTasks(tt.overdue), "2024-01-15"),
+			}
+			classify(b)
+			if b.Classification.TaskPressure != tt.wantPressure || b.Classification.MorningLoad != tt.wantLoad {
+				t.Errorf("task_pressure = %s, morning_load = %s, want %s, %s", b.Classification.TaskPressure, b.Classification.MorningLoad, tt.wantPressure, tt.wantLoad)
+			}
+			note := strings.Contains(b.Classification.Recommendation, "overdue tasks: clear one early")
+			if note != (tt.wantPressure == TaskPressureDebt) {
+				t.Errorf("recommendation = %q", b.Classification.Recommendation)
+			}
+		})
+	}
+}
+
+func TestTaskDebtRendering(t *testing.T) {
+	tasks := overdueTasksFor(overdueTasks(6), "2024-01-15")
+	m := MorningBriefing{Tasks: tasks, Classification: Classification{TaskPressure: TaskPressureDebt}}
+	line := "6 overdue tasks, oldest: Task (1 day), Task (1 day), Task (1 day)"
+	if md := MorningMarkdown(m); !strings.Contains(md, "\n"+line+"\n") {
+		t.Errorf("markdown missing overdue tasks:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "  nothing scheduled\n  "+line+"\n") {
+		t.Errorf("text missing overdue tasks:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, line+" (TASK_DEBT)\n") {
+		t.Errorf("prompt missing overdue tasks (%v):\n%s", err, prompt)
+	}
+	vars := morningRuleVars(m)
+	if vars["tasks.overdue"] != 6.0 || vars["task_pressure"] != TaskPressureDebt {
+		t.Errorf("tasks.overdue = %v, task_pressure = %v", vars["tasks.overdue"], vars["task_pressure"])
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"database/sql"
+	"math"
+)
+
+// Adaptive TDEE settings
+const (
+	AdaptiveTDEEDays     = 28  // trailing window of intake and weight change
+	AdaptiveTDEEMinDays  = 21  // days in the window that need intake logged
+	AdaptiveTDEEMaxDrift = 0.5 // largest share the baseline may differ from Mifflin-St Jeor by
+)
+
+// Where the evening's resting burn comes from
+const (
+	BMRSourceAdaptive = "adaptive"
+	BMRSourceFormula  = "mifflin_st_jeor"
+)
+
+// tdeeEstimate is the energy the trailing intake and weight trend imply is
+// burned per day, in total and without the average active energy
+type tdeeEstimate struct {
+	TDEE     int
+	Baseline int
+}
+
+// queryAdaptiveTDEE estimates TDEE over the AdaptiveTDEEDays ending on end:
+// average intake minus the weight trend's change as stored energy. Nil when
+// fewer than AdaptiveTDEEMinDays have intake or the trend does not cover the
+// window with a reading in its last week.
+func queryAdaptiveTDEE(db *sql.DB, end string) (*tdeeEstimate, error) {
+	start := addDays(end, -(AdaptiveTDEEDays - 1))
+	intake, active, logged := 0.0, 0.0, 0
+	for day := start; day <= end; day = addDays(day, 1) {
+		kcal, err := queryDayTotal(db, "dietary_energy", day)
+		if err != nil {
+			return nil, err
+		}
+		if kcal == 0 {
+			continue
+		}
+		burned, err := queryDayTotal(db, "active_energy", day)
+		if err != nil {
+			return nil, err
+		}
+		intake += kcal
+		active += burned
+		logged++
+	}
+	if logged < AdaptiveTDEEMinDays {
+		return nil, nil
+	}
+
+	weights, err := queryDailyValues(db, "weight_body_mass", addDays(start, -WeightLookbackDays), end)
+	if err != nil {
+		return nil, err
+	}
+	if len(weights) == 0 || weights[len(weights)-1].Date < addDays(end, -6) {
+		return nil, nil
+	}
+	trend := weightEMA(weights)
+	before, after := trendOn(trend, addDays(start, -1)), trendOn(trend, end)
+	if before == nil || after == nil {
+		return nil, nil
+	}
+
+	tdee := intake/float64(logged) - (*after-*before)*KcalPerKgFat/AdaptiveTDEEDays
+	return &tdeeEstimate{
+		TDEE:     int(math.Round(tdee)),
+		Baseline: int(math.Round(tdee - active/float64(logged))),
+	}, nil
+}
+
+// adaptiveBMR picks the estimate's baseline over the formula BMR unless it is
+// missing or implausibly far from it (usually patchy food logging)
+func adaptiveBMR(est *tdeeEstimate, formula int) (int, string) {
+	if est == nil || math.Abs(float64(est.Baseline-formula)) > AdaptiveTDEEMaxDrift*float64(formula) {
+		return formula, BMRSourceFormula
+	}
+	return est.Baseline, BMRSourceAdaptive
+}
+
+// bmrLabel names the resting burn in the evening's Burned row
+func bmrLabel(e EnergyData) string {
+	if e.BMRSource == BMRSourceAdaptive {
+		return "adaptive BMR"
+	}
+	return "BMR"
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== ADAPTIVE TDEE TESTS ====================
+
+// insertTDEEHistory logs intake and active energy for days days ending on end,
+// and a weight losing 0.05 kg a day from six weeks before them
+func insertTDEEHistory(t *testing.T, db *sql.DB, end string, days int) {
+	t.Helper()
+	insert := `INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES (?, ?, ?, ?, ?)`
+	start := addDays(end, -(AdaptiveTDEEDays - 1))
+	for i, day := 0, addDays(start, -WeightLookbackDays); day <= end; i, day = i+1, addDays(day, 1) {
+		if _, err := db.Exec(insert, "weight_body_mass", day+" 07:00:00 +0700", 85-0.05*float64(i), "kg", "Scale"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range days {
+		day := addDays(end, -i)
+		if _, err := db.Exec(insert, "dietary_energy", day+" 12:00:00 +0700", 2200, "kcal", "briefing"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(insert, "active_energy", day+" 12:00:00 +0700", 400, "kcal", "Apple Watch"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestQueryAdaptiveTDEE(t *testing.T) {
+	db := newTestMetricsDB(t)
+	insertTDEEHistory(t, db, "2024-01-14", AdaptiveTDEEDays)
+
+	// Losing 1.4 kg over 28 days is 385 kcal a day below the 2200 eaten
+	est, err := queryAdaptiveTDEE(db, "2024-01-14")
+	if err != nil {
+		t.Fatalf("queryAdaptiveTDEE() error: %v", err)
+	}
+	if est == nil || *est != (tdeeEstimate{TDEE: 2585, Baseline: 2185}) {
+		t.Errorf("queryAdaptiveTDEE() = %+v, want TDEE 2585, baseline 2185", est)
+	}
+
+	// Weeks later the latest weigh-in is too old
+	if est, err := queryAdaptiveTDEE(db, "2024-02-01"); err != nil || est != nil {
+		t.Errorf("queryAdaptiveTDEE() without recent weights = %+v, %v; want nil", est, err)
+	}
+}
+
+func TestQueryAdaptiveTDEEInsufficientLogging(t *testing.T) {
+	db := newTestMetricsDB(t)
+	insertTDEEHistory(t, db, "2024-01-14", AdaptiveTDEEMinDays-1)
+	if est, err := queryAdaptiveTDEE(db, "2024-01-14"); err != nil || est != nil {
+		t.Errorf("queryAdaptiveTDEE() with %d logged days = %+v, %v; want nil", AdaptiveTDEEMinDays-1, est, err)
+	}
+}
+
+func TestAdaptiveBMR(t *testing.T) {
+	tests := []struct {
+		name       string
+		est        *tdeeEstimate
+		wantKcal   int
+		wantSource string
+	}{
+		{"no estimate", nil, 1636, BMRSourceFormula},
+		{"plausible", &tdeeEstimate{TDEE: 2585, Baseline: 2185}, 2185, BMRSourceAdaptive},
+		{"patchy logging", &tdeeEstimate{TDEE: 900, Baseline: 500}, 1636, BMRSourceFormula},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kcal, source := adaptiveBMR(tt.est, 1636)
+			if kcal != tt.wantKcal || source != tt.wantSource {
+				t.Errorf("adaptiveBMR() = %d, %s; want %d, %s", kcal, source, tt.wantKcal, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestEveningAdaptiveTDEE(t *testing.T) {
+	db := newTestMetricsDB(t)
+	insertTDEEHistory(t, db, "2024-01-14", AdaptiveTDEEDays)
+
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if e.Energy.BMRSource != BMRSourceAdaptive || e.Energy.BMRKcal != 2185 || e.Energy.TDEEKcal == nil || *e.Energy.TDEEKcal != 2585 {
+		t.Fatalf("Energy = %+v, want the adaptive 2185 baseline", e.Energy)
+	}
+	if md := EveningMarkdown(e); !strings.Contains(md, "adaptive BMR 2185 + active 0") {
+		t.Errorf("markdown missing adaptive BMR:\n%s", md)
+	}
+
+	// Without history the formula stays
+	e = newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, newTestMetricsDB(t), "2024-01-15", "2024-01-14")
+	if e.Energy.BMRSource != BMRSourceFormula || e.Energy.BMRKcal != UserBMRKcal || e.Energy.TDEEKcal != nil {
+		t.Errorf("Energy = %+v, want the Mifflin-St Jeor %d", e.Energy, UserBMRKcal)
+	}
+}
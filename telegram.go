@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// telegramAPIBase is the Bot API endpoint (swapped out in tests)
+var telegramAPIBase = "https://api.telegram.org"
+
+// telegramMaxMessage is the Bot API's limit on message length
+const telegramMaxMessage = 4096
+
+// Telegram message formats
+const (
+	TelegramText     = "text"
+	TelegramMarkdown = "markdown"
+)
+
+type telegramMessage struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func validateTelegram(cfg TelegramConfig) error {
+	if cfg.BotToken == "" || cfg.ChatID == "" {
+		return errors.New("delivery.telegram: bot_token and chat_id are required")
+	}
+	switch cfg.Format {
+	case "", TelegramText, TelegramMarkdown:
+		return nil
+	}
+	return fmt.Errorf("delivery.telegram: unknown format %q (expected: text, markdown)", cfg.Format)
+}
+
+// telegramMessages renders d for the chat. Text is sent as a <pre> block so
+// columns stay aligned; Markdown is sent as-is (Telegram can't render its
+// headings or tables) for pasting into notes. Long briefings are split on
+// line boundaries.
+func telegramMessages(cfg TelegramConfig, d Delivery) []telegramMessage {
+	if cfg.Format == TelegramMarkdown {
+		var msgs []telegramMessage
+		for _, chunk := range splitLines(d.Markdown, telegramMaxMessage) {
+			msgs = append(msgs, telegramMessage{ChatID: cfg.ChatID, Text: chunk})
+		}
+		return msgs
+	}
+	var msgs []telegramMessage
+	// Leave room for the <pre> tags and escaping
+	for _, chunk := range splitLines(d.Text, telegramMaxMessage/2) {
+		msgs = append(msgs, telegramMessage{ChatID: cfg.ChatID, Text: "<pre>" + html.EscapeString(chunk) + "</pre>", ParseMode: "HTML"})
+	}
+	return msgs
+}
+
+// splitLines breaks s into chunks of at most limit bytes, cutting between
+// lines where possible
+func splitLines(s string, limit int) []string {
+	s = strings.TrimRight(s, "\n")
+	var chunks []string
+	var cur strings.Builder
+	for _, line := range strings.SplitAfter(s, "\n") {
+		for len(line) > limit {
+			if cur.Len() > 0 {
+				chunks = append(chunks, cur.String())
+				cur.Reset()
+			}
+			chunks = append(chunks, line[:limit])
+			line = line[limit:]
+		}
+		if cur.Len()+len(line) > limit {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(line)
+	}
+	if cur.Len() > 0 {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+// sendTelegram posts the briefing to the configured chat via the Bot API
+func sendTelegram(cfg TelegramConfig, d Delivery) error {
+	if err := validateTelegram(cfg); err != nil {
+		return err
+	}
+	// The token is part of the URL, so errors never include it
+	url := telegramAPIBase + "/bot" + cfg.BotToken + "/sendMessage"
+	client := &http.Client{Timeout: webhookTimeout}
+	for _, msg := range telegramMessages(cfg, d) {
+		body, _ := json.Marshal(msg)
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return errors.New("request to Telegram failed")
+		}
+		var result telegramResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode >= 300 || !result.OK {
+			return fmt.Errorf("status %d: %s", resp.StatusCode, result.Description)
+		}
+	}
+	return nil
+}
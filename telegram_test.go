@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== TELEGRAM TESTS ====================
+
+func TestValidateTelegram(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         TelegramConfig
+		expectError bool
+	}{
+		{"complete", TelegramConfig{BotToken: "123:abc", ChatID: "42"}, false},
+		{"markdown", TelegramConfig{BotToken: "123:abc", ChatID: "@me", Format: "markdown"}, false},
+		{"no token", TelegramConfig{ChatID: "42"}, true},
+		{"no chat", TelegramConfig{BotToken: "123:abc"}, true},
+		{"bad format", TelegramConfig{BotToken: "123:abc", ChatID: "42", Format: "html"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTelegram(tt.cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateTelegram() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		input string
+		limit int
+		want  []string
+	}{
+		{"", 10, nil},
+		{"short\n", 10, []string{"short"}},
+		{"aaaa\nbbbb\ncccc", 10, []string{"aaaa\nbbbb\n", "cccc"}},
+		{"abcdefghijkl", 5, []string{"abcde", "fghij", "kl"}},
+	}
+	for _, tt := range tests {
+		got := splitLines(tt.input, tt.limit)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("splitLines(%q, %d) = %q, want %q", tt.input, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestTelegramMessages(t *testing.T) {
+	d := Delivery{Text: "Sleep  5.5 h <POOR>\n", Markdown: "# Morning\n"}
+
+	text := telegramMessages(TelegramConfig{ChatID: "42"}, d)
+	if len(text) != 1 || text[0].ParseMode != "HTML" || text[0].Text != "<pre>Sleep  5.5 h &lt;POOR&gt;</pre>" {
+		t.Errorf("text messages = %+v", text)
+	}
+
+	md := telegramMessages(TelegramConfig{ChatID: "42", Format: "markdown"}, d)
+	if len(md) != 1 || md[0].ParseMode != "" || md[0].Text != "# Morning" {
+		t.Errorf("markdown messages = %+v", md)
+	}
+}
+
+func TestSendTelegram(t *testing.T) {
+	var got telegramMessage
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+	saved := telegramAPIBase
+	telegramAPIBase = ts.URL
+	t.Cleanup(func() { telegramAPIBase = saved })
+
+	cfg := TelegramConfig{BotToken: "123:abc", ChatID: "42"}
+	if err := sendTelegram(cfg, Delivery{Text: "Morning 2024-01-15\n"}); err != nil {
+		t.Fatalf("sendTelegram() error: %v", err)
+	}
+	if path != "/bot123:abc/sendMessage" {
+		t.Errorf("path = %q", path)
+	}
+	if got.ChatID != "42" || !strings.Contains(got.Text, "Morning 2024-01-15") {
+		t.Errorf("message = %+v", got)
+	}
+}
+
+func TestSendTelegramAPIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+	}))
+	defer ts.Close()
+	saved := telegramAPIBase
+	telegramAPIBase = ts.URL
+	t.Cleanup(func() { telegramAPIBase = saved })
+
+	err := sendTelegram(TelegramConfig{BotToken: "123:secret", ChatID: "42"}, Delivery{Text: "x"})
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("sendTelegram() error = %v, want API description", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the bot token: %v", err)
+	}
+}
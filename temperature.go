@@ -0,0 +1,42 @@
+package main
+
+import (
+	"database/sql"
+	"math"
+)
+
+// TemperatureBaselineDays is the look-back window for the temperature baseline
+const TemperatureBaselineDays = 30
+
+// temperatureMetrics are tried in order; the first with a reading today is
+// used, and its baseline comes from the same metric so a thermometer reading
+// is never compared with wrist temperature
+var temperatureMetrics = []string{"apple_sleeping_wrist_temperature", "body_temperature"}
+
+// queryTemperature returns today's temperature (°C) and its baseline over
+// the days before date, nil when neither metric has a reading today
+func queryTemperature(db *sql.DB, date string, days int, exclude []LifeTag) (value, baseline *float64, err error) {
+	for _, metric := range temperatureMetrics {
+		value, err = queryLatestValue(db, metric, date)
+		if err != nil {
+			return nil, nil, err
+		}
+		if value == nil {
+			continue
+		}
+		baseline, err = queryDailyBaseline(db, metric, date, days, exclude)
+		return value, baseline, err
+	}
+	return nil, nil, nil
+}
+
+// applyTemperature sets the temperature, baseline, and deviation
+func applyTemperature(v *VitalsData, value, baseline *float64) {
+	v.Temperature = value
+	v.TemperatureBaseline = baseline
+	if value == nil || baseline == nil {
+		return
+	}
+	dev := math.Round((*value-*baseline)*100) / 100
+	v.TemperatureDeviation = &dev
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ==================== TEMPERATURE TESTS ====================
+
+func TestQueryTemperature(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('apple_sleeping_wrist_temperature', '2024-01-13 03:00:00 +0700', 35.9, 'degC', 'Apple Watch'),
+		('apple_sleeping_wrist_temperature', '2024-01-14 03:00:00 +0700', 36.1, 'degC', 'Apple Watch'),
+		('apple_sleeping_wrist_temperature', '2024-01-15 03:00:00 +0700', 36.6, 'degC', 'Apple Watch'),
+		('body_temperature', '2024-01-10 08:00:00 +0700', 37.0, 'degC', 'Health'),
+		('body_temperature', '2024-01-16 08:00:00 +0700', 37.8, 'degC', 'Health')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wrist temperature wins when it has a reading; its baseline ignores body temperature
+	value, baseline, err := queryTemperature(db, "2024-01-15", TemperatureBaselineDays, nil)
+	if err != nil {
+		t.Fatalf("queryTemperature() error: %v", err)
+	}
+	if value == nil || *value != 36.6 || baseline == nil || *baseline != 36 {
+		t.Errorf("queryTemperature() = %v, %v; want 36.6 against 36", value, baseline)
+	}
+
+	// Without a wrist reading, the thermometer is compared with its own history
+	value, baseline, err = queryTemperature(db, "2024-01-16", TemperatureBaselineDays, nil)
+	if err != nil {
+		t.Fatalf("queryTemperature() error: %v", err)
+	}
+	if value == nil || *value != 37.8 || baseline == nil || *baseline != 37 {
+		t.Errorf("queryTemperature() = %v, %v; want 37.8 against 37", value, baseline)
+	}
+
+	value, baseline, err = queryTemperature(db, "2024-02-01", TemperatureBaselineDays, nil)
+	if err != nil || value != nil || baseline != nil {
+		t.Errorf("queryTemperature() with no reading = %v, %v, %v; want nil", value, baseline, err)
+	}
+}
+
+func TestTemperatureIllnessSignal(t *testing.T) {
+	var v VitalsData
+	applyTemperature(&v, ptr(36.6), ptr(36.04))
+	if v.TemperatureDeviation == nil || *v.TemperatureDeviation != 0.56 {
+		t.Fatalf("TemperatureDeviation = %v, want 0.56", v.TemperatureDeviation)
+	}
+	if risk, signals := classifyIllness(v); risk != IllnessRiskElevated || !slices.Equal(signals, []string{SignalTemperature}) {
+		t.Errorf("classifyIllness() = %q %v, want ELEVATED from temperature", risk, signals)
+	}
+
+	var noBaseline VitalsData
+	applyTemperature(&noBaseline, ptr(36.3), nil)
+	if noBaseline.Temperature == nil || noBaseline.TemperatureDeviation != nil {
+		t.Errorf("without a baseline got %+v, want the reading and no deviation", noBaseline)
+	}
+
+	m := MorningBriefing{Vitals: VitalsData{Temperature: ptr(36.4), TemperatureDeviation: ptr(0.3)}}
+	if got := MorningText(m, textStyle{}); !strings.Contains(got, "Temp     36.4 °C (+0.3 vs baseline)\n") {
+		t.Errorf("text missing temperature line:\n%s", got)
+	}
+}
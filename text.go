@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ANSI styles for --format=text
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiDim    = "\033[2m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+)
+
+// textStyle paints terminal output, or leaves it plain when color is off
+type textStyle struct {
+	color bool
+}
+
+func (s textStyle) paint(code, text string) string {
+	if !s.color || code == "" || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// status paints a classification by how good it is
+func (s textStyle) status(v string) string {
+	switch v {
+	case "GOOD", "CLEAR", "CONSISTENT", "NORMAL", "ON PACE", "AT GOAL":
+		return s.paint(ansiGreen, v)
+	case "OK", "LIGHT", "ELEVATED", "IRREGULAR", "LOW", "SEDENTARY", "BEHIND PACE", "MODERATE", "SENSITIVE":
+		return s.paint(ansiYellow, v)
+	case "POOR", "PACKED", "HIGH", "UNHEALTHY", "VERY UNHEALTHY", "HAZARDOUS":
+		return s.paint(ansiRed, v)
+	}
+	return s.paint(ansiDim, v)
+}
+
+// heading paints a section title
+func (s textStyle) heading(title string) string {
+	return s.paint(ansiBold, title)
+}
+
+// useColor reports whether output to f should be colorized (NO_COLOR disables)
+func useColor(f *os.File) bool {
+	return os.Getenv("NO_COLOR") == "" && isTerminal(f)
+}
+
+// textValue formats an optional metric, "-" when missing
+func textValue(v *float64, format string) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf(format, *v)
+}
+
+func textAlertsAndErrors(b *strings.Builder, s textStyle, alerts []Alert, errs []string) {
+	for _, a := range alerts {
+		code := ansiYellow
+		if a.Severity == SeverityHigh {
+			code = ansiRed
+		}
+		fmt.Fprintf(b, "%s %s\n", s.paint(code, "! "+a.Severity), a.Rule)
+	}
+	for _, e := range errs {
+		fmt.Fprintf(b, "%s\n", s.paint(ansiDim, "error: "+e))
+	}
+}
+
+func textMeds(b *strings.Builder, s textStyle, m MedsData) {
+	if len(m.Overdue) == 0 && len(m.DueToday) == 0 {
+		b.WriteString("  none due\n")
+	}
+	for _, name := range medNames(m.Overdue) {
+		fmt.Fprintf(b, "  %s\n", s.paint(ansiRed, name+" (overdue)"))
+	}
+	for _, name := range medNames(m.DueToday) {
+		fmt.Fprintf(b, "  %s\n", name)
+	}
+	for _, miss := range m.MissedRecently {
+		fmt.Fprintf(b, "  %s\n", s.paint(ansiYellow, medMissesLine(miss)))
+	}
+	for _, line := range reorderLines(m.Inventory) {
+		fmt.Fprintf(b, "  %s\n", s.paint(ansiYellow, line))
+	}
+	textCycles(b, s, m.Cycles)
+}
+
+// textCycles lists cycles, those about to change phase in yellow
+func textCycles(b *strings.Builder, s textStyle, cycles []CycleStatus) {
+	for _, c := range cycles {
+		line := cycleLine(c)
+		if c.changingSoon() {
+			line = s.paint(ansiYellow, line)
+		}
+		fmt.Fprintf(b, "  %s\n", line)
+	}
+}
+
+func textEvents(b *strings.Builder, s textStyle, events []CalendarEvent) {
+	if len(events) == 0 {
+		b.WriteString("  nothing scheduled\n")
+		return
+	}
+	for _, e := range events {
+		fmt.Fprintf(b, "  %s  %s\n", s.paint(ansiBold, e.Time), eventSummary(e))
+	}
+}
+
+// MorningText renders a compact terminal summary of the morning briefing
+func MorningText(m MorningBriefing, s textStyle) string {
+	var b strings.Builder
+	readiness := "-"
+	if m.Classification.ReadinessScore != nil {
+		readiness = fmt.Sprintf("%d", *m.Classification.ReadinessScore)
+	}
+	fmt.Fprintf(&b, "%s  readiness %s\n", s.heading("Morning "+m.TargetDate), s.paint(ansiBold, readiness))
+	if m.Classification.Recommendation != "" {
+		fmt.Fprintf(&b, "%s\n", m.Classification.Recommendation)
+	}
+	if m.Narrative != "" {
+		fmt.Fprintf(&b, "\n%s\n", m.Narrative)
+	}
+	if len(m.Tags) > 0 {
+		fmt.Fprintf(&b, "%s\n", s.paint(ansiDim, "tagged: "+strings.Join(m.Tags, ", ")))
+	}
+
+	fmt.Fprintf(&b, "\nSleep    %s h (deep %s, REM %s)  %s\n",
+		textValue(m.Sleep.TotalHours, "%.1f"), textValue(m.Sleep.DeepHours, "%.1f"), textValue(m.Sleep.REMHours, "%.1f"),
+		s.status(m.Classification.SleepQuality))
+	if m.Sleep.ConsistencyScore != nil {
+		fmt.Fprintf(&b, "Rhythm   %s  %s\n", consistencyValue(m.Sleep), s.status(m.Sleep.Consistency))
+	}
+	fmt.Fprintf(&b, "HRV      %s ms (baseline %s)  %s\n",
+		textValue(m.Vitals.HRV, "%.0f"), textValue(m.Vitals.HRVBaseline, "%.0f"), s.status(m.Classification.RecoveryStatus))
+	fmt.Fprintf(&b, "RHR      %s bpm%s", textValue(m.Vitals.RestingHR, "%.0f"), rhrDeltaValue(m.Vitals))
+	if status := rhrStatus(m.Vitals); status != "" {
+		fmt.Fprintf(&b, "  %s", s.status(status))
+	}
+	b.WriteString("\n")
+	if m.Vitals.Temperature != nil {
+		fmt.Fprintf(&b, "Temp     %s\n", temperatureValue(m.Vitals))
+	}
+	if bp := m.Vitals.BloodPressure; bp != nil {
+		fmt.Fprintf(&b, "BP       %s  %s\n", bpValue(bp), s.status(bp.Status))
+	}
+	if m.Alcohol != nil {
+		fmt.Fprintf(&b, "Alcohol  %s\n", alcoholValue(m.Alcohol))
+	}
+	if m.Fasting != nil {
+		fmt.Fprintf(&b, "Fasting  %s\n", fastingValue(m.Fasting))
+	}
+	if len(m.Classification.IllnessSignals) > 0 {
+		fmt.Fprintf(&b, "Illness  %s  %s\n", joinSignals(m.Classification.IllnessSignals), s.status(m.Classification.IllnessRisk))
+	}
+
+	if lines := glucoseLines(m.Glucose); len(lines) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("Glucose"))
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if m.Weather != nil {
+		fmt.Fprintf(&b, "\n%s  %s\n", s.heading("Weather"), weatherLine(m.Weather))
+		if line := sunLine(m.Weather); line != "" {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	if m.AirQuality != nil {
+		fmt.Fprintf(&b, "%s  %s  %s\n", s.heading("Air"), s.status(m.AirQuality.Classification), airQualityLine(m.AirQuality))
+	}
+	if m.Pollen != nil {
+		fmt.Fprintf(&b, "%s  %s  %s\n", s.heading("Pollen"), s.status(m.Pollen.Level), pollenLine(m.Pollen))
+	}
+
+	if len(m.Calendar.AllDayEvents) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("All day"))
+		for _, e := range m.Calendar.AllDayEvents {
+			line := allDayLine(e)
+			if e.RaisesLoad {
+				line = s.paint(ansiYellow, line)
+			}
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if m.Travel != nil {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("Travel"))
+		for _, line := range tripLines(m.Travel.Trips, m.TargetDate) {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if p := m.JetLagPlan; p != nil {
+		fmt.Fprintf(&b, "\n%s  %s\n", s.heading("Jet lag"), jetLagHeadline(p, m.TargetDate))
+		for i, line := range jetLagLines(p, m.TargetDate) {
+			if p.Days[i].Date == m.TargetDate {
+				line = s.paint(ansiBold, line)
+			}
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n%s  %s\n", s.heading("Agenda"), s.status(m.Classification.MorningLoad))
+	textEvents(&b, s, append(append([]CalendarEvent{}, m.Calendar.MorningEvents...), m.Calendar.AfternoonEvents...))
+	for _, c := range m.Calendar.Conflicts {
+		fmt.Fprintf(&b, "  %s\n", s.paint(ansiRed, "conflict "+conflictLine(c)))
+	}
+	if line := focusTimeLine(m.Calendar.FocusHours, settings.WorkHours); line != "" {
+		if lowFocusTime(m.Calendar.FocusHours, settings.WorkHours) {
+			line = s.paint(ansiYellow, line)
+		}
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	if line := commuteLine(m.Calendar); line != "" {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	if line := overdueTasksLine(m.Tasks); line != "" {
+		if m.Classification.TaskPressure == TaskPressureDebt {
+			line = s.paint(ansiYellow, line)
+		}
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	if lines := onCallLines(m.OnCall, m.TargetDate); len(lines) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("On call"))
+		for i, line := range lines {
+			if m.OnCall.Schedules[i].OnCall {
+				line = s.paint(ansiYellow, line)
+			}
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if m.Focus != nil {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("Focus"))
+		for _, t := range m.Focus.Tasks {
+			line := focusLine(t)
+			if t.Overdue {
+				line = s.paint(ansiRed, line)
+			}
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+		if m.Focus.More > 0 {
+			fmt.Fprintf(&b, "  %s\n", s.paint(ansiDim, fmt.Sprintf("+%d more", m.Focus.More)))
+		}
+	}
+
+	if m.Work != nil {
+		fmt.Fprintf(&b, "\n%s  %s\n", s.heading("Work"), workLine(m.Work))
+		for _, line := range reviewLines(m.Work) {
+			fmt.Fprintf(&b, "  review %s\n", line)
+		}
+		for _, line := range failingLines(m.Work) {
+			fmt.Fprintf(&b, "  %s\n", s.paint(ansiRed, "failing "+line))
+		}
+	}
+
+	if m.Slack != nil {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("Slack"))
+		for _, w := range m.Slack.Workspaces {
+			line := slackLine(w)
+			if slackWarn(w, settings.Slack) {
+				line = s.paint(ansiYellow, line)
+			}
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if m.Mail != nil {
+		fmt.Fprintf(&b, "\n%s  %s\n", s.heading("Mail"), mailSummary(m.Mail))
+		for _, line := range mailLines(m.Mail) {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if lines := headlineLines(m.News); len(lines) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("News"))
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if m.Markets != nil {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("Markets"))
+		for _, q := range m.Markets.Quotes {
+			color := ansiGreen
+			if q.ChangePct < 0 {
+				color = ansiRed
+			}
+			fmt.Fprintf(&b, "  %s\n", s.paint(color, quoteLine(q)))
+		}
+	}
+
+	fmt.Fprintf(&b, "\n%s\n", s.heading("Meds"))
+	textMeds(&b, s, m.Meds)
+
+	if len(m.Supplements) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("Supplements"))
+		for _, d := range m.Supplements {
+			fmt.Fprintf(&b, "  %s  %s (%s)\n", s.paint(ansiBold, d.Time), d.Name, d.Note)
+		}
+	}
+
+	if m.Training.LastWorkout != nil {
+		fmt.Fprintf(&b, "\nLast workout: %s, %d days ago (%d this week)\n", m.Training.LastWorkout.Title, m.Training.DaysSinceLast, m.Training.WeeklyCount)
+	}
+	if m.Calendar.BestWorkoutSlot != nil {
+		fmt.Fprintf(&b, "Workout slot: %s\n", workoutSlotValue(m.Calendar.BestWorkoutSlot))
+	}
+	if m.Steps != nil {
+		fmt.Fprintf(&b, "Steps yesterday: %s\n", stepsValue(m.Steps.Yesterday, m.Steps.Goal, m.Steps.Avg7d))
+	}
+	if m.Training.VO2Max != nil {
+		fmt.Fprintf(&b, "VO2max: %s\n", vo2MaxValue(m.Training.VO2Max))
+	}
+	if m.Training.HRZones != nil {
+		fmt.Fprintf(&b, "HR zones: %s\n", zonesValue(m.Training.HRZones))
+	}
+	if m.Training.Zone2 != nil {
+		fmt.Fprintf(&b, "Zone 2: %s\n", zone2Value(m.Training.Zone2))
+	}
+	if line := changesLine(m.Changes); line != "" {
+		fmt.Fprintf(&b, "\nSince yesterday: %s\n", line)
+	}
+
+	if len(m.Alerts) > 0 || len(m.Errors) > 0 {
+		b.WriteString("\n")
+		textAlertsAndErrors(&b, s, m.Alerts, m.Errors)
+	}
+	return b.String()
+}
+
+// MiddayText renders a compact terminal summary of the midday check-in
+func MiddayText(m MiddayBriefing, s textStyle) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", s.heading("Midday "+m.TargetDate))
+	fmt.Fprintf(&b, "Eaten    %.0f kcal (active %.0f)\n", m.Energy.ConsumedKcal, m.Energy.ActiveKcal)
+	fmt.Fprintf(&b, "Left     %s\n", budgetValue(m.Energy.RemainingKcalToMaintenance, m.Energy.RemainingKcalToGoalDeficit))
+	fmt.Fprintf(&b, "Protein  %.0f / %d g\n", m.Protein.ConsumedG, m.Protein.TargetG)
+	fmt.Fprintf(&b, "Water    %.0f / %d ml\n", m.Hydration.ConsumedMl, m.Hydration.TargetMl)
+	fmt.Fprintf(&b, "Steps    %d\n", m.Steps)
+
+	fmt.Fprintf(&b, "\n%s\n", s.heading("Upcoming"))
+	textEvents(&b, s, m.UpcomingEvents)
+
+	fmt.Fprintf(&b, "\n%s\n", s.heading("Meds"))
+	textMeds(&b, s, m.Meds)
+
+	if len(m.Alerts) > 0 || len(m.Errors) > 0 {
+		b.WriteString("\n")
+		textAlertsAndErrors(&b, s, m.Alerts, m.Errors)
+	}
+	return b.String()
+}
+
+// EveningText renders a compact terminal summary of the evening wrap-up
+func EveningText(e EveningBriefing, s textStyle) string {
+	var b strings.Builder
+	balanceColor := ansiYellow
+	switch e.Energy.Status {
+	case "deficit":
+		balanceColor = ansiGreen
+	case "surplus":
+		balanceColor = ansiRed
+	}
+	fmt.Fprintf(&b, "%s  %s\n\n", s.heading("Evening "+e.TargetDate),
+		s.paint(balanceColor, fmt.Sprintf("%+d kcal %s", e.Energy.DeficitOrSurplusKcal, e.Energy.Status)))
+	fmt.Fprintf(&b, "Eaten    %.0f kcal, burned %.0f kcal\n", e.Energy.ConsumedKcal, e.Energy.TotalBurnedKcal)
+	fmt.Fprintf(&b, "Left     %s\n", budgetValue(e.Energy.RemainingKcalToMaintenance, e.Energy.RemainingKcalToGoalDeficit))
+	if e.Eating != nil {
+		fmt.Fprintf(&b, "Window   %s\n", eatingWindowValue(e.Eating))
+	}
+	if len(e.Meals) > 0 {
+		fmt.Fprintf(&b, "Meals    %s\n", mealsValue(e.Meals, e.LateKcalPct))
+	}
+	if len(e.Micronutrients) > 0 {
+		fmt.Fprintf(&b, "Micros   %s\n", micronutrientsValue(e.Micronutrients))
+	}
+	if g := e.Energy.Goal; g != nil {
+		fmt.Fprintf(&b, "Goal     %s", goalValue(g))
+		if g.Pace != "" {
+			fmt.Fprintf(&b, "  %s", s.status(g.Pace))
+		}
+		b.WriteString("\n")
+	}
+
+	protein := fmt.Sprintf("%.0f / %d g", e.Protein.ConsumedG, e.Protein.TargetG)
+	if !e.Protein.OnTrack {
+		protein = s.paint(ansiYellow, protein)
+	}
+	fmt.Fprintf(&b, "Protein  %s\n", protein)
+	fmt.Fprintf(&b, "Water    %.0f / %d ml\n", e.Hydration.ConsumedMl, e.Hydration.TargetMl)
+	fmt.Fprintf(&b, "Steps    %s", activityStepsValue(e.Activity))
+	if status := eveningStepsStatus(e.Activity); status != "" {
+		fmt.Fprintf(&b, "  %s", s.status(status))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Rings    %s\n", ringsValue(e.Rings))
+	if e.Weight != nil {
+		fmt.Fprintf(&b, "Weight   %s\n", weightValue(e.Weight))
+		if line := compositionLine(e.Weight); line != "" {
+			fmt.Fprintf(&b, "         %s\n", line)
+		}
+	}
+	if e.Activity.Workout != nil && e.Activity.Workout.Done {
+		fmt.Fprintf(&b, "Workout  %s (%s)\n", e.Activity.Workout.Title, e.Activity.Workout.Duration)
+	}
+
+	if len(e.Protocols.Missed) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("Missed"))
+		for _, name := range e.Protocols.Missed {
+			fmt.Fprintf(&b, "  %s\n", s.paint(ansiRed, name))
+		}
+	}
+	if len(e.Protocols.Cycles) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", s.heading("Cycles"))
+		textCycles(&b, s, e.Protocols.Cycles)
+	}
+
+	if e.Tomorrow.FirstEvent != nil {
+		fmt.Fprintf(&b, "\nTomorrow starts %s %s\n", s.paint(ansiBold, e.Tomorrow.FirstEvent.Time), e.Tomorrow.FirstEvent.Summary)
+	}
+	for _, line := range packingLines(e.Travel, e.TargetDate) {
+		fmt.Fprintf(&b, "%s\n", s.paint(ansiYellow, line))
+	}
+	if line := changesLine(e.Changes); line != "" {
+		fmt.Fprintf(&b, "\nSince yesterday: %s\n", line)
+	}
+
+	if len(e.Alerts) > 0 || len(e.Errors) > 0 {
+		b.WriteString("\n")
+		textAlertsAndErrors(&b, s, e.Alerts, e.Errors)
+	}
+	return b.String()
+}
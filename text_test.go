@@ -0,0 +1,113 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ==================== TERMINAL TEXT TESTS ====================
+
+func TestTextStylePaint(t *testing.T) {
+	plain := textStyle{}
+	if got := plain.status("POOR"); got != "POOR" {
+		t.Errorf("plain status = %q, want no escapes", got)
+	}
+
+	color := textStyle{color: true}
+	tests := []struct {
+		value string
+		code  string
+	}{
+		{"GOOD", ansiGreen},
+		{"LIGHT", ansiYellow},
+		{"POOR", ansiRed},
+		{"UNKNOWN", ansiDim},
+	}
+	for _, tt := range tests {
+		if got, want := color.status(tt.value), tt.code+tt.value+ansiReset; got != want {
+			t.Errorf("status(%q) = %q, want %q", tt.value, got, want)
+		}
+	}
+	if got := color.paint(ansiRed, ""); got != "" {
+		t.Errorf("paint of empty text = %q, want empty", got)
+	}
+}
+
+func TestMorningTextRHRElevated(t *testing.T) {
+	b := MorningBriefing{Vitals: VitalsData{RestingHR: ptr(61), RestingHRDelta: ptr(6.2), Flags: []string{FlagRHRElevated}}}
+	if got := MorningText(b, textStyle{}); !strings.Contains(got, "RHR      61 bpm (+6 vs baseline)  ELEVATED\n") {
+		t.Errorf("text missing elevated RHR line:\n%s", got)
+	}
+	if got := MorningText(MorningBriefing{Vitals: VitalsData{RestingHR: ptr(55)}}, textStyle{}); !strings.Contains(got, "RHR      55 bpm\n") {
+		t.Errorf("text RHR line without a baseline:\n%s", got)
+	}
+}
+
+func TestMorningText(t *testing.T) {
+	b := MorningBriefing{
+		TargetDate: "2024-01-15",
+		Sleep:      SleepData{TotalHours: ptr(5.5)},
+		Calendar:   CalendarData{MorningEvents: []CalendarEvent{{Time: "09:00", Summary: "Standup"}}},
+		Meds:       MedsData{Overdue: []MedTask{{Name: "Vitamin D"}}, DueToday: []MedTask{{Name: "Magnesium", DueTime: "21:00"}}},
+		Classification: Classification{
+			SleepQuality:   "POOR",
+			MorningLoad:    "LIGHT",
+			ReadinessScore: intPtr(55),
+		},
+	}
+
+	plain := MorningText(b, textStyle{})
+	for _, want := range []string{
+		"Morning 2024-01-15  readiness 55",
+		"Sleep    5.5 h (deep -, REM -)  POOR",
+		"Agenda  LIGHT",
+		"  09:00  Standup",
+		"  Vitamin D (overdue)",
+		"  Magnesium (21:00)",
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("text missing %q\n%s", want, plain)
+		}
+	}
+	if strings.Contains(plain, "\033[") {
+		t.Error("plain text contains ANSI escapes")
+	}
+
+	colored := MorningText(b, textStyle{color: true})
+	for _, want := range []string{ansiRed + "POOR" + ansiReset, ansiRed + "Vitamin D (overdue)" + ansiReset} {
+		if !strings.Contains(colored, want) {
+			t.Errorf("colored text missing %q", want)
+		}
+	}
+}
+
+func TestEveningText(t *testing.T) {
+	b := EveningBriefing{
+		TargetDate: "2024-01-15",
+		Energy:     EnergyData{DeficitOrSurplusKcal: 250, Status: "surplus"},
+		Protein:    ProteinData{ConsumedG: 90, TargetG: 152},
+		Protocols:  ProtocolsData{Missed: []string{"Creatine"}},
+		Tomorrow:   TomorrowData{FirstEvent: &EventInfo{Time: "08:30", Summary: "Dentist"}},
+	}
+	plain := EveningText(b, textStyle{})
+	for _, want := range []string{"Evening 2024-01-15  +250 kcal surplus", "Protein  90 / 152 g", "Missed", "  Creatine", "Tomorrow starts 08:30 Dentist"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("text missing %q\n%s", want, plain)
+		}
+	}
+
+	colored := EveningText(b, textStyle{color: true})
+	if !strings.Contains(colored, ansiRed+"+250 kcal surplus"+ansiReset) {
+		t.Errorf("surplus not shown in red:\n%s", colored)
+	}
+}
+
+func TestMiddayText(t *testing.T) {
+	b := MiddayBriefing{TargetDate: "2024-01-15", Steps: 4200}
+	plain := MiddayText(b, textStyle{})
+	for _, want := range []string{"Midday 2024-01-15", "Steps    4200", "  nothing scheduled", "  none due"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("text missing %q\n%s", want, plain)
+		}
+	}
+}
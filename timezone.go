@@ -0,0 +1,69 @@
+package main
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// local_ts(timestamp) is available in every health.db query: metrics are
+// stamped at the writer's own offset, so days and hours are picked on the
+// timestamp moved into the local zone, not on its text
+func init() {
+	sqlite.MustRegisterScalarFunction("local_ts", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		ts, ok := args[0].(string)
+		if !ok {
+			return args[0], nil
+		}
+		return localTimestamp(ts), nil
+	})
+}
+
+// applyTimezone makes name (an IANA zone such as Asia/Bangkok) the local
+// zone for every date the briefing works out; empty keeps the system zone
+func applyTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("unknown timezone %q", name)
+	}
+	time.Local = loc
+	return nil
+}
+
+// validateTimezone checks the timezone setting names a known zone
+func validateTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("timezone: unknown zone %q", name)
+	}
+	return nil
+}
+
+// localTime parses an RFC 3339 timestamp from an external source and moves it
+// into the local zone, so an event booked at +07:00 lands on the right local
+// date and hour wherever it was created
+func localTime(ts string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return t, err
+	}
+	return t.In(time.Local), nil
+}
+
+// localTimestamp rewrites a metrics timestamp (metricTimestampLayout) as
+// "YYYY-MM-DD HH:MM:SS" in the local zone, so its date and hour substrings
+// are local. Anything else, such as a bare date, is returned unchanged.
+func localTimestamp(ts string) string {
+	t, err := time.Parse(metricTimestampLayout, ts)
+	if err != nil {
+		return ts
+	}
+	return t.In(time.Local).Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ==================== TIMEZONE TESTS ====================
+
+// withLocal restores the local zone after a test changes it
+func withLocal(t *testing.T) {
+	saved := time.Local
+	t.Cleanup(func() { time.Local = saved })
+}
+
+// inZone sets the local zone for a test
+func inZone(t *testing.T, zone string) {
+	withLocal(t)
+	if err := applyTimezone(zone); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyTimezone(t *testing.T) {
+	withLocal(t)
+	time.Local = time.UTC
+
+	if err := applyTimezone(""); err != nil || time.Local != time.UTC {
+		t.Errorf("applyTimezone(\"\") = %v, local %v, want the zone left alone", err, time.Local)
+	}
+	if err := applyTimezone("Europe/Berlin"); err != nil || time.Local.String() != "Europe/Berlin" {
+		t.Errorf("applyTimezone(Europe/Berlin) = %v, local %v", err, time.Local)
+	}
+	if err := applyTimezone("Mars/Olympus_Mons"); err == nil || time.Local.String() != "Europe/Berlin" {
+		t.Errorf("applyTimezone(unknown) = %v, local %v, want an error and the zone kept", err, time.Local)
+	}
+}
+
+func TestLocalTime(t *testing.T) {
+	withLocal(t)
+	if err := applyTimezone("Europe/Berlin"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 05:00 in Bangkok is still the evening before in Berlin
+	got, err := localTime("2024-01-16T05:00:00+07:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Format("2006-01-02 15:04") != "2024-01-15 23:00" {
+		t.Errorf("localTime() = %s, want 2024-01-15 23:00 Berlin time", got.Format("2006-01-02 15:04"))
+	}
+	if _, err := localTime("2024-01-16 05:00"); err == nil {
+		t.Error("localTime() expected an error for a non-RFC 3339 timestamp")
+	}
+}
+
+func TestWorkoutsCountedInLocalDate(t *testing.T) {
+	withLocal(t)
+	workouts := []HevyWorkout{{StartTime: "2024-01-16T05:00:00+07:00"}}
+
+	if err := applyTimezone("Asia/Bangkok"); err != nil {
+		t.Fatal(err)
+	}
+	if got := CountWorkoutsInRange(workouts, "2024-01-16", "2024-01-16"); got != 1 {
+		t.Errorf("Bangkok: CountWorkoutsInRange() = %d, want 1", got)
+	}
+	if err := applyTimezone("Europe/Berlin"); err != nil {
+		t.Fatal(err)
+	}
+	if got := CountWorkoutsInRange(workouts, "2024-01-15", "2024-01-15"); got != 1 {
+		t.Errorf("Berlin: CountWorkoutsInRange() = %d, want the workout on the 15th", got)
+	}
+}
+
+func TestValidateTimezone(t *testing.T) {
+	for _, name := range []string{"", "UTC", "America/New_York"} {
+		if err := validateTimezone(name); err != nil {
+			t.Errorf("validateTimezone(%q) error: %v", name, err)
+		}
+	}
+	if err := validateTimezone("EST5"); err == nil {
+		t.Error("validateTimezone(EST5) expected an error")
+	}
+}
+
+func TestLocalTimestamp(t *testing.T) {
+	inZone(t, "Europe/Berlin")
+	tests := map[string]string{
+		"2024-01-15 06:00:00 +0700": "2024-01-15 00:00:00",
+		"2024-01-15 01:00:00 +0200": "2024-01-15 00:00:00",
+		"2024-01-14 23:30:00 +0000": "2024-01-15 00:30:00",
+		"2024-01-15":                "2024-01-15", // not a metrics timestamp: unchanged
+	}
+	for ts, want := range tests {
+		if got := localTimestamp(ts); got != want {
+			t.Errorf("localTimestamp(%q) = %q, want %q", ts, got, want)
+		}
+	}
+}
+
+// TestMetricDaysFollowTimezone checks health.db days are local days whatever
+// offset each row was written at
+func TestMetricDaysFollowTimezone(t *testing.T) {
+	db := newTestMetricsDB(t)
+	// The first two are 23:00 UTC on the 14th, the third 10:00 UTC on the 15th
+	for _, r := range []struct {
+		metric, ts string
+		value      float64
+	}{
+		{"heart_rate_variability", "2024-01-15 06:00:00 +0700", 40},
+		{"heart_rate_variability", "2024-01-15 01:00:00 +0200", 50},
+		{"heart_rate_variability", "2024-01-15 12:00:00 +0200", 60},
+		{"step_count", "2024-01-15 06:00:00 +0700", 1000},
+		{"step_count", "2024-01-15 01:00:00 +0200", 2000},
+		{"step_count", "2024-01-15 12:00:00 +0200", 3000},
+	} {
+		if _, err := db.Exec(`INSERT INTO metrics (metric_name, timestamp, value) VALUES (?, ?, ?)`, r.metric, r.ts, r.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		zone       string
+		hrv, steps float64
+	}{
+		{"Asia/Bangkok", 50, 6000}, // 06:00, 06:00, and 17:00 on the 15th
+		{"UTC", 60, 3000},          // only 10:00 is on the 15th
+	}
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			inZone(t, tt.zone)
+			hrv, err := queryAverageHRV(db, "2024-01-15")
+			if err != nil || hrv == nil || *hrv != tt.hrv {
+				t.Errorf("queryAverageHRV() = %v, %v, want %v", hrv, err, tt.hrv)
+			}
+			steps, err := queryDayTotal(db, "step_count", "2024-01-15")
+			if err != nil || steps != tt.steps {
+				t.Errorf("queryDayTotal() = %v, %v, want %v", steps, err, tt.steps)
+			}
+		})
+	}
+}
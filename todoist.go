@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// TodoistTodayFilter is the query behind `td today`: what's due today plus anything overdue
+const TodoistTodayFilter = "today | overdue"
+
+// todoistTimeout bounds each Todoist API request
+const todoistTimeout = 15 * time.Second
+
+// todoistPageSize is the most tasks the API returns per page
+const todoistPageSize = 200
+
+// todoistClient talks to the Todoist API directly
+type todoistClient struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+// todoistAPITask is a task as the API returns it
+type todoistAPITask struct {
+	ID        string   `json:"id"`
+	Content   string   `json:"content"`
+	Labels    []string `json:"labels"`
+	ProjectID string   `json:"project_id"`
+	Priority  int      `json:"priority"`
+	Checked   bool     `json:"checked"`
+	Due       *struct {
+		Date string `json:"date"` // YYYY-MM-DD, or with a time (floating or RFC 3339)
+	} `json:"due"`
+}
+
+// todoistPage is one page of a task listing; filters fill Results, completed
+// listings fill Items
+type todoistPage struct {
+	Results    []todoistAPITask `json:"results"`
+	Items      []todoistAPITask `json:"items"`
+	NextCursor *string          `json:"next_cursor"`
+}
+
+// newTodoistClient returns the API client, or nil when no token is
+// configured and td should be used
+func newTodoistClient(cfg TodoistConfig) (*todoistClient, error) {
+	token := cfg.APIToken
+	if token == "" && cfg.KeychainService != "" {
+		var err error
+		if token, err = keychainSecret(cfg.KeychainService); err != nil {
+			return nil, fmt.Errorf("keychain lookup for %q failed: %w", cfg.KeychainService, err)
+		}
+	}
+	if token == "" {
+		return nil, nil
+	}
+	return &todoistClient{base: strings.TrimRight(cfg.URL, "/"), token: token, http: &http.Client{Timeout: todoistTimeout}}, nil
+}
+
+// todoistToday lists today's and overdue tasks, including those completed
+// today, through the API when configured and td otherwise
+func todoistToday(today string) ([]TodoistTask, error) {
+	return todoistWithFallback(func(c *todoistClient) ([]TodoistTask, error) {
+		open, err := c.filter(TodoistTodayFilter)
+		if err != nil {
+			return nil, err
+		}
+		done, err := c.completedOn(today)
+		if err != nil {
+			return nil, err
+		}
+		return append(open, done...), nil
+	}, "todoist-today", "today", "--json")
+}
+
+// todoistFilter lists the open tasks matching a Todoist filter query
+func todoistFilter(query, dump string) ([]TodoistTask, error) {
+	return todoistWithFallback(func(c *todoistClient) ([]TodoistTask, error) {
+		return c.filter(query)
+	}, dump, "filter", query, "--json")
+}
+
+// todoistComplete marks a task done
+func todoistComplete(id string) error {
+	c, err := newTodoistClient(settings.Todoist)
+	if c != nil {
+		if err = c.close(id); err == nil {
+			return nil
+		}
+	}
+	out, tdErr := exec.Command("td", "done", id).CombinedOutput()
+	if tdErr == nil {
+		return nil
+	}
+	tdErr = fmt.Errorf("%v: %s", tdErr, strings.TrimSpace(string(out)))
+	if err != nil {
+		return fmt.Errorf("%w (td fallback: %v)", err, tdErr)
+	}
+	return tdErr
+}
+
+// todoistWithFallback runs the API call when a token is configured, falling
+// back to td with args when there is none or the API fails
+func todoistWithFallback(api func(*todoistClient) ([]TodoistTask, error), dump string, args ...string) ([]TodoistTask, error) {
+	c, err := newTodoistClient(settings.Todoist)
+	if c != nil {
+		var tasks []TodoistTask
+		if tasks, err = api(c); err == nil {
+			return tasks, nil
+		}
+	}
+	tasks, tdErr := tdTasks(dump, args...)
+	if tdErr == nil {
+		return tasks, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w (td fallback: %v)", err, tdErr)
+	}
+	return nil, tdErr
+}
+
+// tdTasks runs the td CLI and parses its task listing
+func tdTasks(dump string, args ...string) ([]TodoistTask, error) {
+	output, err := exec.Command("td", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	dumpRaw(dump, output)
+
+	var resp TodoistResponse
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+	return resp.Results, nil
+}
+
+// filter lists the open tasks matching query, following every page
+func (c *todoistClient) filter(query string) ([]TodoistTask, error) {
+	return c.list("/tasks/filter", url.Values{"query": {query}})
+}
+
+// completedOn lists the tasks completed during date (local time)
+func (c *todoistClient) completedOn(date string) ([]TodoistTask, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.list("/tasks/completed/by_completion_date", url.Values{
+		"since": {day.UTC().Format(time.RFC3339)},
+		"until": {day.AddDate(0, 0, 1).UTC().Format(time.RFC3339)},
+	})
+	for i := range tasks {
+		tasks[i].IsCompleted = true
+	}
+	return tasks, err
+}
+
+func (c *todoistClient) list(path string, params url.Values) ([]TodoistTask, error) {
+	var tasks []TodoistTask
+	params.Set("limit", fmt.Sprint(todoistPageSize))
+	for {
+		var page todoistPage
+		if err := c.do(http.MethodGet, path+"?"+params.Encode(), &page); err != nil {
+			return nil, err
+		}
+		for _, t := range append(page.Results, page.Items...) {
+			tasks = append(tasks, t.task())
+		}
+		if page.NextCursor == nil || *page.NextCursor == "" {
+			return tasks, nil
+		}
+		params.Set("cursor", *page.NextCursor)
+	}
+}
+
+// close completes a task
+func (c *todoistClient) close(id string) error {
+	return c.do(http.MethodPost, "/tasks/"+url.PathEscape(id)+"/close", nil)
+}
+
+func (c *todoistClient) do(method, path string, out any) error {
+	req, err := http.NewRequest(method, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("todoist API status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("todoist API: invalid response: %w", err)
+	}
+	return nil
+}
+
+// task converts an API task to the shape td prints
+func (t todoistAPITask) task() TodoistTask {
+	task := TodoistTask{ID: t.ID, Content: t.Content, Labels: t.Labels, ProjectID: t.ProjectID, Priority: t.Priority, IsCompleted: t.Checked}
+	if t.Due == nil {
+		return task
+	}
+	task.Due = &TodoistDue{Date: t.Due.Date}
+	if len(t.Due.Date) <= len("2006-01-02") {
+		return task
+	}
+	at, err := time.Parse(time.RFC3339, t.Due.Date)
+	if err != nil {
+		// Floating times are local wherever the user is
+		if at, err = time.ParseInLocation("2006-01-02T15:04:05", t.Due.Date, time.Local); err != nil {
+			task.Due.Date = t.Due.Date[:len("2006-01-02")]
+			return task
+		}
+	}
+	at = at.In(time.Local)
+	task.Due.Date = at.Format("2006-01-02")
+	task.Due.DateTime = at.Format(time.RFC3339)
+	return task
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== TODOIST CLIENT TESTS ====================
+
+// fakeTodoistAPI serves two pages of today's tasks, one completed task, and
+// task closing, recording the paths it was asked for
+func fakeTodoistAPI(t *testing.T) (*httptest.Server, *[]string) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/tasks/filter" && r.URL.Query().Get("cursor") == "":
+			if r.URL.Query().Get("query") != TodoistTodayFilter {
+				t.Errorf("filter query = %q", r.URL.Query().Get("query"))
+			}
+			w.Write([]byte(`{"results": [{"id": "1", "content": "Nexium", "labels": ["💊Meds"], "priority": 4, "due": {"date": "2024-01-15"}}], "next_cursor": "p2"}`))
+		case r.URL.Path == "/tasks/filter":
+			w.Write([]byte(`{"results": [{"id": "2", "content": "PrEP", "project_id": "77", "due": {"date": "2024-01-15T08:30:00"}}], "next_cursor": null}`))
+		case r.URL.Path == "/tasks/completed/by_completion_date":
+			if r.URL.Query().Get("since") == "" || r.URL.Query().Get("until") == "" {
+				t.Errorf("completed listing without a range: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"items": [{"id": "3", "content": "Vitamin D", "labels": ["💊Meds"], "due": {"date": "2024-01-15"}}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/tasks/2/close":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &calls
+}
+
+// fakeTD puts a td on PATH that prints output and fails when output is empty
+func fakeTD(t *testing.T, output string) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s' '" + output + "'\n"
+	if output == "" {
+		script = "#!/bin/sh\necho 'td: offline' >&2\nexit 1\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "td"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestTodoistTodayAPI(t *testing.T) {
+	ts, calls := fakeTodoistAPI(t)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Todoist = TodoistConfig{APIToken: "secret", URL: ts.URL + "/"}
+
+	tasks, err := todoistToday("2024-01-15")
+	if err != nil {
+		t.Fatalf("todoistToday() error: %v", err)
+	}
+	local, _ := time.ParseInLocation("2006-01-02T15:04:05", "2024-01-15T08:30:00", time.Local)
+	want := []TodoistTask{
+		{ID: "1", Content: "Nexium", Labels: []string{"💊Meds"}, Priority: 4, Due: &TodoistDue{Date: "2024-01-15"}},
+		{ID: "2", Content: "PrEP", ProjectID: "77", Due: &TodoistDue{Date: "2024-01-15", DateTime: local.Format(time.RFC3339)}},
+		{ID: "3", Content: "Vitamin D", Labels: []string{"💊Meds"}, IsCompleted: true, Due: &TodoistDue{Date: "2024-01-15"}},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("todoistToday() = %+v, want %+v", tasks, want)
+	}
+
+	if err := todoistComplete("2"); err != nil {
+		t.Errorf("todoistComplete() error: %v", err)
+	}
+	if got := strings.Join(*calls, ", "); got != "GET /tasks/filter, GET /tasks/filter, GET /tasks/completed/by_completion_date, POST /tasks/2/close" {
+		t.Errorf("API calls = %s", got)
+	}
+}
+
+func TestTodoistFallsBackToTD(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	fakeTD(t, `{"results": [{"id": "9", "content": "Nexium", "labels": ["💊Meds"], "is_completed": true}]}`)
+
+	// Without a token td is used directly
+	tasks, err := todoistFilter("p1", "todoist-focus")
+	if err != nil || len(tasks) != 1 || tasks[0].ID != "9" || !tasks[0].IsCompleted {
+		t.Errorf("todoistFilter() via td = %+v, %v", tasks, err)
+	}
+
+	// A failing API falls back to td too
+	ts, _ := fakeTodoistAPI(t)
+	settings.Todoist = TodoistConfig{APIToken: "wrong", URL: ts.URL}
+	if tasks, err := todoistToday("2024-01-15"); err != nil || len(tasks) != 1 {
+		t.Errorf("todoistToday() after an API failure = %+v, %v", tasks, err)
+	}
+
+	// With both down, the error names both
+	fakeTD(t, "")
+	_, err = todoistToday("2024-01-15")
+	if err == nil || !strings.Contains(err.Error(), "todoist API status 401") || !strings.Contains(err.Error(), "td fallback") {
+		t.Errorf("todoistToday() with both failing error = %v", err)
+	}
+	if err := todoistComplete("1"); err == nil || !strings.Contains(err.Error(), "td: offline") {
+		t.Errorf("todoistComplete() with both failing error = %v", err)
+	}
+}
+
+func TestNewTodoistClientWithoutToken(t *testing.T) {
+	c, err := newTodoistClient(DefaultConfig().Todoist)
+	if c != nil || err != nil {
+		t.Errorf("newTodoistClient() without a token = %v, %v, want td", c, err)
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Routing providers
+const (
+	RouteOSRM   = "osrm"   // OSRM routing, addresses geocoded with Nominatim
+	RouteGoogle = "google" // Google Distance Matrix, addresses as given
+)
+
+// Travel modes
+const (
+	TravelDriving = "driving"
+	TravelTransit = "transit" // google only
+)
+
+// travelTimeout bounds each geocoding and routing request
+const travelTimeout = 15 * time.Second
+
+// validateTravel checks the provider and warning time when travel is on
+func validateTravel(cfg TravelConfig) error {
+	if cfg.Home == "" {
+		return nil
+	}
+	switch cfg.Provider {
+	case RouteOSRM:
+	case RouteGoogle:
+		if cfg.GoogleAPIKey == "" {
+			return errors.New("travel.google_api_key is required for the google provider")
+		}
+	default:
+		return fmt.Errorf("travel.provider: unknown provider %q (expected: osrm, google)", cfg.Provider)
+	}
+	switch cfg.Mode {
+	case TravelDriving:
+	case TravelTransit:
+		if cfg.Provider != RouteGoogle {
+			return errors.New("travel.mode: transit needs the google provider")
+		}
+	default:
+		return fmt.Errorf("travel.mode: unknown mode %q (expected: driving, transit)", cfg.Mode)
+	}
+	if _, err := parseClock(cfg.WarnBefore); err != nil {
+		return fmt.Errorf("travel.warn_before: %w", err)
+	}
+	if cfg.BufferMinutes < 0 {
+		return errors.New("travel.buffer_minutes must not be negative")
+	}
+	return nil
+}
+
+// inPerson reports whether an event needs travel: it has a place and no call
+// link, and you aren't there already from before today
+func inPerson(e CalendarEvent) bool {
+	return e.Location != "" && e.JoinURL == "" && !e.Ongoing
+}
+
+// planTravel works out how long each in-person event today is from home and
+// when to leave, marking departures before travel.warn_before, and sets the
+// calendar's commute to the first of them
+func planTravel(b *MorningBriefing, cfg TravelConfig) {
+	if cfg.Home == "" {
+		return
+	}
+	minutes := map[string]int{} // one route per distinct location
+	for _, events := range [][]CalendarEvent{b.Calendar.MorningEvents, b.Calendar.AfternoonEvents} {
+		for i := range events {
+			e := &events[i]
+			if !inPerson(*e) {
+				continue
+			}
+			m, ok := minutes[e.Location]
+			if !ok {
+				var err error
+				if m, err = travelMinutes(cfg, cfg.Home, e.Location); err != nil {
+					b.Errors = append(b.Errors, fmt.Sprintf("travel error (%s): %v", e.Summary, err))
+					continue
+				}
+				minutes[e.Location] = m
+			}
+			leaveTravel(e, m, cfg)
+		}
+	}
+	for _, e := range append(append([]CalendarEvent{}, b.Calendar.MorningEvents...), b.Calendar.AfternoonEvents...) {
+		if inPerson(e) {
+			if e.LeaveBy != "" {
+				b.Calendar.LeaveBy, b.Calendar.CommuteTo, b.Calendar.CommuteMinutes = e.LeaveBy, e.Summary, e.TravelMinutes
+				b.Calendar.CommuteMode = cfg.Mode
+			}
+			break
+		}
+	}
+}
+
+// commuteLine is when to leave for the first in-person event, e.g.
+// "Leave by 08:20 for Dentist (35 min by car)"
+func commuteLine(cal CalendarData) string {
+	if cal.LeaveBy == "" || cal.CommuteMinutes == nil {
+		return ""
+	}
+	by := "by car"
+	if cal.CommuteMode == TravelTransit {
+		by = "by transit"
+	}
+	return fmt.Sprintf("Leave by %s for %s (%d min %s)", cal.LeaveBy, cal.CommuteTo, *cal.CommuteMinutes, by)
+}
+
+// leaveTravel sets the event's travel time and departure, with the buffer
+func leaveTravel(e *CalendarEvent, minutes int, cfg TravelConfig) {
+	start, err := parseClock(e.Time)
+	if err != nil {
+		return
+	}
+	leave := max(start-minutes-cfg.BufferMinutes, 0)
+	warn, _ := parseClock(cfg.WarnBefore)
+	e.TravelMinutes = &minutes
+	e.LeaveBy = fmt.Sprintf("%02d:%02d", leave/60, leave%60)
+	e.EarlyDeparture = leave < warn
+}
+
+// travelNote is appended to the recommendation for the earliest departure
+// before travel.warn_before
+func travelNote(cal CalendarData) string {
+	var first *CalendarEvent
+	for _, events := range [][]CalendarEvent{cal.MorningEvents, cal.AfternoonEvents} {
+		for i, e := range events {
+			if e.EarlyDeparture && (first == nil || e.LeaveBy < first.LeaveBy) {
+				first = &events[i]
+			}
+		}
+	}
+	if first == nil {
+		return ""
+	}
+	return fmt.Sprintf(" Leave by %s for %s (%d min away).", first.LeaveBy, first.Summary, *first.TravelMinutes)
+}
+
+// earlyDepartures counts the events to leave for before travel.warn_before
+func earlyDepartures(cal CalendarData) int {
+	n := 0
+	for _, e := range append(append([]CalendarEvent{}, cal.MorningEvents...), cal.AfternoonEvents...) {
+		if e.EarlyDeparture {
+			n++
+		}
+	}
+	return n
+}
+
+// travelMinutes is the time from one place to another by travel.mode, in
+// current traffic with Google
+func travelMinutes(cfg TravelConfig, from, to string) (int, error) {
+	client := &http.Client{Timeout: travelTimeout}
+	if cfg.Provider == RouteGoogle {
+		return googleMinutes(client, cfg, from, to)
+	}
+	fromLat, fromLon, err := geocode(client, cfg, from)
+	if err != nil {
+		return 0, err
+	}
+	toLat, toLon, err := geocode(client, cfg, to)
+	if err != nil {
+		return 0, err
+	}
+	return osrmMinutes(client, cfg, fromLat, fromLon, toLat, toLon)
+}
+
+// geocode finds a place's coordinates; "lat,lon" is taken as is
+func geocode(client *http.Client, cfg TravelConfig, place string) (float64, float64, error) {
+	if lat, lon, ok := parseCoordinates(place); ok {
+		return lat, lon, nil
+	}
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	u := strings.TrimRight(cfg.GeocodeURL, "/") + "/search?" + url.Values{"q": {place}, "format": {"json"}, "limit": {"1"}}.Encode()
+	if err := travelGet(client, u, &results); err != nil {
+		return 0, 0, fmt.Errorf("geocoding %q: %w", place, err)
+	}
+	if len(results) == 0 {
+		return 0, 0, fmt.Errorf("geocoding %q: no match", place)
+	}
+	lat, err1 := strconv.ParseFloat(results[0].Lat, 64)
+	lon, err2 := strconv.ParseFloat(results[0].Lon, 64)
+	if err := errors.Join(err1, err2); err != nil {
+		return 0, 0, fmt.Errorf("geocoding %q: %w", place, err)
+	}
+	return lat, lon, nil
+}
+
+// parseCoordinates reads "lat,lon"
+func parseCoordinates(s string) (float64, float64, bool) {
+	latStr, lonStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// osrmMinutes asks OSRM for the driving time between two points
+func osrmMinutes(client *http.Client, cfg TravelConfig, fromLat, fromLon, toLat, toLon float64) (int, error) {
+	var resp struct {
+		Code   string `json:"code"`
+		Routes []struct {
+			Duration float64 `json:"duration"` // seconds
+		} `json:"routes"`
+	}
+	u := fmt.Sprintf("%s/route/v1/driving/%f,%f;%f,%f?overview=false", strings.TrimRight(cfg.OSRMURL, "/"), fromLon, fromLat, toLon, toLat)
+	if err := travelGet(client, u, &resp); err != nil {
+		return 0, fmt.Errorf("routing: %w", err)
+	}
+	if resp.Code != "Ok" || len(resp.Routes) == 0 {
+		return 0, fmt.Errorf("routing: no route (%s)", resp.Code)
+	}
+	return int(resp.Routes[0].Duration/60 + 0.5), nil
+}
+
+// googleMinutes asks the Distance Matrix API for the travel time leaving now,
+// which for driving includes the traffic
+func googleMinutes(client *http.Client, cfg TravelConfig, from, to string) (int, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Rows   []struct {
+			Elements []struct {
+				Status   string `json:"status"`
+				Duration struct {
+					Value int `json:"value"` // seconds
+				} `json:"duration"`
+				DurationInTraffic *struct {
+					Value int `json:"value"`
+				} `json:"duration_in_traffic"` // driving only
+			} `json:"elements"`
+		} `json:"rows"`
+	}
+	u := strings.TrimRight(cfg.GoogleURL, "/") + "/distancematrix/json?" + url.Values{
+		"origins": {from}, "destinations": {to}, "mode": {cfg.Mode}, "departure_time": {"now"}, "key": {cfg.GoogleAPIKey},
+	}.Encode()
+	if err := travelGet(client, u, &resp); err != nil {
+		return 0, fmt.Errorf("routing: %w", err)
+	}
+	if resp.Status != "OK" || len(resp.Rows) == 0 || len(resp.Rows[0].Elements) == 0 || resp.Rows[0].Elements[0].Status != "OK" {
+		return 0, fmt.Errorf("routing: no route (%s)", resp.Status)
+	}
+	el := resp.Rows[0].Elements[0]
+	seconds := el.Duration.Value
+	if el.DurationInTraffic != nil {
+		seconds = el.DurationInTraffic.Value
+	}
+	return (seconds + 30) / 60, nil
+}
+
+func travelGet(client *http.Client, u string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "briefing") // Nominatim's usage policy asks for one
+	resp, err := client.Do(req)
+	if err != nil {
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			return ue.Err // the URL may carry an API key
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
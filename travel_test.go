@@ -0,0 +1,243 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== TRAVEL TIME TESTS ====================
+
+// fakeRouting serves Nominatim search, OSRM routes, and the Distance Matrix
+func fakeRouting(t *testing.T) (*httptest.Server, *int) {
+	routes := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/search":
+			if r.Header.Get("User-Agent") == "" {
+				t.Error("geocoding request without a User-Agent")
+			}
+			switch r.URL.Query().Get("q") {
+			case "Harley Street, London":
+				w.Write([]byte(`[{"lat": "51.5206", "lon": "-0.1478"}]`))
+			default:
+				w.Write([]byte(`[]`))
+			}
+		case strings.HasPrefix(r.URL.Path, "/route/v1/driving/"):
+			routes++
+			if r.URL.Path != "/route/v1/driving/-0.100000,51.500000;-0.147800,51.520600" {
+				t.Errorf("OSRM path = %s", r.URL.Path)
+			}
+			w.Write([]byte(`{"code": "Ok", "routes": [{"duration": 1490.3}]}`))
+		case r.URL.Path == "/distancematrix/json":
+			q := r.URL.Query()
+			if q.Get("key") != "k" || q.Get("origins") != "51.5,-0.1" || q.Get("departure_time") != "now" {
+				w.Write([]byte(`{"status": "REQUEST_DENIED"}`))
+				return
+			}
+			if q.Get("mode") == TravelTransit {
+				w.Write([]byte(`{"status": "OK", "rows": [{"elements": [{"status": "OK", "duration": {"value": 2700}}]}]}`))
+				return
+			}
+			w.Write([]byte(`{"status": "OK", "rows": [{"elements": [{"status": "OK", "duration": {"value": 2100}, "duration_in_traffic": {"value": 2590}}]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &routes
+}
+
+func testTravelConfig(url string) TravelConfig {
+	cfg := DefaultConfig().Travel
+	cfg.Home = "51.5,-0.1"
+	cfg.OSRMURL, cfg.GeocodeURL, cfg.GoogleURL = url, url+"/", url
+	return cfg
+}
+
+func TestPlanTravel(t *testing.T) {
+	ts, routes := fakeRouting(t)
+	cfg := testTravelConfig(ts.URL)
+	b := &MorningBriefing{Calendar: CalendarData{
+		MorningEvents: []CalendarEvent{
+			{Time: "08:00", Summary: "Dentist", Location: "Harley Street, London"},
+			{Time: "09:00", Summary: "Standup", Location: "Room 4", JoinURL: "https://meet.google.com/abc"},
+			{Time: "10:00", Summary: "Focus"},
+		},
+		AfternoonEvents: []CalendarEvent{
+			{Time: "14:00", Summary: "Follow-up", Location: "Harley Street, London"},
+			{Time: "16:00", Summary: "Drinks", Location: "Nowhere Land"},
+		},
+	}}
+	planTravel(b, cfg)
+
+	dentist, followUp := b.Calendar.MorningEvents[0], b.Calendar.AfternoonEvents[0]
+	if dentist.TravelMinutes == nil || *dentist.TravelMinutes != 25 || dentist.LeaveBy != "07:25" || !dentist.EarlyDeparture {
+		t.Errorf("dentist = %+v, want 25 min away, leave by 07:25 (early)", dentist)
+	}
+	if followUp.LeaveBy != "13:25" || followUp.EarlyDeparture {
+		t.Errorf("follow-up = %+v, want leave by 13:25", followUp)
+	}
+	if *routes != 1 {
+		t.Errorf("routed %d times, want once per location", *routes)
+	}
+	if b.Calendar.MorningEvents[1].LeaveBy != "" || b.Calendar.MorningEvents[2].LeaveBy != "" {
+		t.Error("video calls and events without a location need no travel")
+	}
+	if len(b.Errors) != 1 || !strings.Contains(b.Errors[0], `travel error (Drinks): geocoding "Nowhere Land": no match`) {
+		t.Errorf("errors = %v", b.Errors)
+	}
+
+	if got := travelNote(b.Calendar); got != " Leave by 07:25 for Dentist (25 min away)." {
+		t.Errorf("travelNote() = %q", got)
+	}
+	if got := eventSummary(dentist); got != "Dentist (Harley Street, London, leave by 07:25)" {
+		t.Errorf("eventSummary() = %q", got)
+	}
+	if vars := morningRuleVars(*b); vars["travel.early_departures"] != 1.0 || vars["calendar.commute_minutes"] != 25.0 {
+		t.Errorf("travel.early_departures = %v, calendar.commute_minutes = %v", vars["travel.early_departures"], vars["calendar.commute_minutes"])
+	}
+
+	// The commute is to the first in-person event
+	if got := commuteLine(b.Calendar); b.Calendar.LeaveBy != "07:25" || got != "Leave by 07:25 for Dentist (25 min by car)" {
+		t.Errorf("calendar leave_by = %q, commuteLine() = %q", b.Calendar.LeaveBy, got)
+	}
+}
+
+func TestPlanTravelCommute(t *testing.T) {
+	ts, _ := fakeRouting(t)
+	cfg := testTravelConfig(ts.URL)
+	cfg.Provider, cfg.GoogleAPIKey, cfg.Mode = RouteGoogle, "k", TravelTransit
+	b := &MorningBriefing{Calendar: CalendarData{
+		MorningEvents: []CalendarEvent{
+			{Time: "09:00", Summary: "Standup", JoinURL: "https://meet.google.com/abc"},
+			{Time: "11:00", Summary: "Client lunch", Location: "Harley Street, London"},
+		},
+	}}
+	planTravel(b, cfg)
+	want := "Leave by 10:05 for Client lunch (45 min by transit)"
+	if got := commuteLine(b.Calendar); b.Calendar.LeaveBy != "10:05" || got != want {
+		t.Errorf("commuteLine() = %q, want %q", got, want)
+	}
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings = DefaultConfig()
+	if md := MorningMarkdown(*b); !strings.Contains(md, "\n"+want+"\n") {
+		t.Errorf("markdown missing the commute:\n%s", md)
+	}
+	if text := MorningText(*b, textStyle{}); !strings.Contains(text, "  "+want+"\n") {
+		t.Errorf("text missing the commute:\n%s", text)
+	}
+
+	// No commute when the first in-person event can't be routed
+	b = &MorningBriefing{Calendar: CalendarData{MorningEvents: []CalendarEvent{
+		{Time: "08:00", Summary: "Drinks", Location: "Nowhere Land"},
+		{Time: "11:00", Summary: "Client lunch", Location: "Harley Street, London"},
+	}}}
+	planTravel(b, testTravelConfig(ts.URL))
+	if b.Calendar.AfternoonEvents != nil || b.Calendar.MorningEvents[1].LeaveBy == "" || b.Calendar.LeaveBy != "" || commuteLine(b.Calendar) != "" {
+		t.Errorf("calendar = %+v, want no commute", b.Calendar)
+	}
+}
+
+func TestPlanTravelDisabled(t *testing.T) {
+	b := &MorningBriefing{Calendar: CalendarData{MorningEvents: []CalendarEvent{{Time: "08:00", Summary: "Dentist", Location: "Harley Street"}}}}
+	planTravel(b, DefaultConfig().Travel)
+	if b.Calendar.MorningEvents[0].TravelMinutes != nil || len(b.Errors) > 0 {
+		t.Errorf("planTravel() without a home = %+v, %v", b.Calendar.MorningEvents[0], b.Errors)
+	}
+}
+
+func TestTravelMinutesGoogle(t *testing.T) {
+	ts, _ := fakeRouting(t)
+	cfg := testTravelConfig(ts.URL)
+	cfg.Provider, cfg.GoogleAPIKey = RouteGoogle, "k"
+	if m, err := travelMinutes(cfg, cfg.Home, "Harley Street, London"); err != nil || m != 43 {
+		t.Errorf("travelMinutes() = %d, %v, want 43 in traffic", m, err)
+	}
+
+	cfg.GoogleAPIKey = "wrong"
+	if _, err := travelMinutes(cfg, cfg.Home, "Harley Street, London"); err == nil || !strings.Contains(err.Error(), "REQUEST_DENIED") {
+		t.Errorf("travelMinutes() with a bad key error = %v", err)
+	}
+
+	// Connection errors leave the key out
+	cfg.GoogleURL, cfg.GoogleAPIKey = "http://127.0.0.1:1", "secret-key"
+	if _, err := travelMinutes(cfg, cfg.Home, "Harley Street, London"); err == nil || strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("travelMinutes() error = %v, want one without the key", err)
+	}
+}
+
+func TestLeaveTravel(t *testing.T) {
+	cfg := DefaultConfig().Travel // warn before 08:00, 10 min buffer
+	tests := []struct {
+		time      string
+		minutes   int
+		wantLeave string
+		wantEarly bool
+	}{
+		{"09:00", 40, "08:10", false},
+		{"08:30", 20, "08:00", false},
+		{"08:30", 21, "07:59", true},
+		{"00:20", 30, "00:00", true},
+	}
+	for _, tt := range tests {
+		e := CalendarEvent{Time: tt.time}
+		leaveTravel(&e, tt.minutes, cfg)
+		if e.LeaveBy != tt.wantLeave || e.EarlyDeparture != tt.wantEarly || *e.TravelMinutes != tt.minutes {
+			t.Errorf("leaveTravel(%s, %d) = %s early=%v, want %s early=%v", tt.time, tt.minutes, e.LeaveBy, e.EarlyDeparture, tt.wantLeave, tt.wantEarly)
+		}
+	}
+}
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		in  string
+		ok  bool
+		lat float64
+	}{
+		{"51.5,-0.1", true, 51.5},
+		{" 13.75 , 100.5 ", true, 13.75},
+		{"10 Downing Street, London", false, 0},
+		{"91,0", false, 0},
+	}
+	for _, tt := range tests {
+		lat, _, ok := parseCoordinates(tt.in)
+		if ok != tt.ok || lat != tt.lat {
+			t.Errorf("parseCoordinates(%q) = %v, %v, want %v, %v", tt.in, lat, ok, tt.lat, tt.ok)
+		}
+	}
+}
+
+func TestValidateTravel(t *testing.T) {
+	on := func(f func(*TravelConfig)) TravelConfig {
+		cfg := DefaultConfig().Travel
+		cfg.Home = "51.5,-0.1"
+		f(&cfg)
+		return cfg
+	}
+	tests := []struct {
+		name        string
+		cfg         TravelConfig
+		expectError bool
+	}{
+		{"off by default", DefaultConfig().Travel, false},
+		{"osrm", on(func(*TravelConfig) {}), false},
+		{"google without a key", on(func(c *TravelConfig) { c.Provider = RouteGoogle }), true},
+		{"google", on(func(c *TravelConfig) { c.Provider, c.GoogleAPIKey = RouteGoogle, "k" }), false},
+		{"unknown provider", on(func(c *TravelConfig) { c.Provider = "here" }), true},
+		{"bad warn_before", on(func(c *TravelConfig) { c.WarnBefore = "8am" }), true},
+		{"negative buffer", on(func(c *TravelConfig) { c.BufferMinutes = -5 }), true},
+		{"transit with osrm", on(func(c *TravelConfig) { c.Mode = TravelTransit }), true},
+		{"transit", on(func(c *TravelConfig) { c.Provider, c.GoogleAPIKey, c.Mode = RouteGoogle, "k", TravelTransit }), false},
+		{"unknown mode", on(func(c *TravelConfig) { c.Mode = "cycling" }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTravel(tt.cfg); (err != nil) != tt.expectError {
+				t.Errorf("validateTravel() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
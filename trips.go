@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Trip kinds
+const (
+	TripFlight = "flight"
+	TripHotel  = "hotel"
+)
+
+// TripsMaxLookahead caps trips.lookahead_days
+const TripsMaxLookahead = 14
+
+// airportZones are the IANA zones of busy airports, for flights whose
+// calendar event doesn't carry the destination zone; trips.airports adds more
+var airportZones = map[string]string{
+	"AMS": "Europe/Amsterdam", "ARN": "Europe/Stockholm", "ATH": "Europe/Athens", "ATL": "America/New_York",
+	"BCN": "Europe/Madrid", "BER": "Europe/Berlin", "BKK": "Asia/Bangkok", "BOS": "America/New_York",
+	"BRU": "Europe/Brussels", "CDG": "Europe/Paris", "CGK": "Asia/Jakarta", "CNX": "Asia/Bangkok",
+	"CPH": "Europe/Copenhagen", "DEL": "Asia/Kolkata", "DEN": "America/Denver", "DFW": "America/Chicago",
+	"DMK": "Asia/Bangkok", "DOH": "Asia/Qatar", "DPS": "Asia/Makassar", "DUB": "Europe/Dublin",
+	"DXB": "Asia/Dubai", "FCO": "Europe/Rome", "FRA": "Europe/Berlin", "GVA": "Europe/Zurich",
+	"HEL": "Europe/Helsinki", "HKG": "Asia/Hong_Kong", "HKT": "Asia/Bangkok", "HND": "Asia/Tokyo",
+	"IAD": "America/New_York", "ICN": "Asia/Seoul", "IST": "Europe/Istanbul", "JFK": "America/New_York",
+	"KUL": "Asia/Kuala_Lumpur", "LAX": "America/Los_Angeles", "LHR": "Europe/London", "LGW": "Europe/London",
+	"LIS": "Europe/Lisbon", "MAD": "Europe/Madrid", "MEL": "Australia/Melbourne", "MEX": "America/Mexico_City",
+	"MIA": "America/New_York", "MUC": "Europe/Berlin", "MXP": "Europe/Rome", "NRT": "Asia/Tokyo",
+	"ORD": "America/Chicago", "OSL": "Europe/Oslo", "PEK": "Asia/Shanghai", "PRG": "Europe/Prague",
+	"PVG": "Asia/Shanghai", "SEA": "America/Los_Angeles", "SFO": "America/Los_Angeles", "SGN": "Asia/Ho_Chi_Minh",
+	"SIN": "Asia/Singapore", "SYD": "Australia/Sydney", "TPE": "Asia/Taipei", "VIE": "Europe/Vienna",
+	"WAW": "Europe/Warsaw", "YVR": "America/Vancouver", "YYZ": "America/Toronto", "ZRH": "Europe/Zurich",
+}
+
+var (
+	// flightWord marks an event as a flight on its own: "Flight to Berlin", "✈ BKK"
+	flightWord = regexp.MustCompile(`(?i)\bflight\b|✈`)
+	// flightTo is the destination in "Flight to Frankfurt (LH 773)"
+	flightTo = regexp.MustCompile(`(?i)\bflight to ([^(),;\n]+)`)
+	// flightNumber is an airline code and number: "LH 773", "TG921"
+	flightNumber = regexp.MustCompile(`\b([A-Z]{2}|[A-Z][0-9]|[0-9][A-Z]) ?([0-9]{1,4})\b`)
+	// flightRoute is a pair of airport codes: "BKK → FRA", "BKK-FRA", "BKK to FRA"
+	flightRoute = regexp.MustCompile(`\b([A-Z]{3}) ?(?:→|->|-|–|>|to) ?([A-Z]{3})\b`)
+	// airportCode is a standalone three-letter code
+	airportCode = regexp.MustCompile(`\b[A-Z]{3}\b`)
+	// hotelWord marks an event as a stay
+	hotelWord = regexp.MustCompile(`(?i)\b(hotel|hostel|airbnb|ryokan|resort|check-?in|stay at)\b`)
+)
+
+// Trip is a flight or hotel stay found in the calendar
+type Trip struct {
+	Kind          string `json:"kind"` // flight, hotel
+	Summary       string `json:"summary"`
+	Source        string `json:"source"`
+	Date          string `json:"date"`                     // departure or check-in day
+	Departs       string `json:"departs,omitempty"`        // HH:MM local time, flights
+	Flight        string `json:"flight,omitempty"`         // e.g. "LH 773"
+	From          string `json:"from,omitempty"`           // airport code
+	To            string `json:"to,omitempty"`             // airport code, or the place after "Flight to"
+	DestinationTZ string `json:"destination_tz,omitempty"` // IANA zone on arrival
+	Until         string `json:"until,omitempty"`          // last day of a stay spanning several
+}
+
+// TravelData lists the trips in the lookahead window (morning) or starting
+// tomorrow (evening)
+type TravelData struct {
+	Trips           []Trip `json:"trips"`
+	PackingReminder bool   `json:"packing_reminder,omitempty"` // evening: a trip starts tomorrow
+}
+
+// validateTrips checks the lookahead and that each airport names a known zone
+func validateTrips(cfg TripsConfig) error {
+	if cfg.LookaheadDays < 0 || cfg.LookaheadDays > TripsMaxLookahead {
+		return fmt.Errorf("trips.lookahead_days must be between 0 and %d", TripsMaxLookahead)
+	}
+	for code, zone := range cfg.Airports {
+		if !airportCode.MatchString(code) || len(code) != 3 {
+			return fmt.Errorf("trips.airports: %q is not a three-letter airport code", code)
+		}
+		if _, err := time.LoadLocation(zone); err != nil {
+			return fmt.Errorf("trips.airports.%s: unknown zone %q", code, zone)
+		}
+	}
+	return nil
+}
+
+// airportZone is the zone of an airport code, the config first
+func airportZone(code string, cfg TripsConfig) string {
+	if zone, ok := cfg.Airports[code]; ok {
+		return zone
+	}
+	return airportZones[code]
+}
+
+// knownAirport finds the first code in text with a known zone
+func knownAirport(text string, cfg TripsConfig) string {
+	for _, code := range airportCode.FindAllString(text, -1) {
+		if airportZone(code, cfg) != "" {
+			return code
+		}
+	}
+	return ""
+}
+
+// knownRoute finds the first route in text whose codes both have known
+// zones, so "CEO - ALL hands" or "API to SDK migration" isn't a flight
+func knownRoute(text string, cfg TripsConfig) []string {
+	for _, m := range flightRoute.FindAllStringSubmatch(text, -1) {
+		if airportZone(m[1], cfg) != "" && airportZone(m[2], cfg) != "" {
+			return m
+		}
+	}
+	return nil
+}
+
+// detectTrip recognizes a flight (the word flight, a route of airport codes,
+// or a flight number next to a known airport) or a hotel stay
+func detectTrip(e GogCalendarEvent, source string, cfg TripsConfig) (Trip, bool) {
+	trip := Trip{Summary: e.Summary, Source: source}
+	route := knownRoute(e.Summary, cfg)
+	number := flightNumber.FindStringSubmatch(e.Summary)
+	switch {
+	case flightWord.MatchString(e.Summary) || route != nil ||
+		(number != nil && knownAirport(e.Summary+" "+e.Location, cfg) != ""):
+		trip.Kind = TripFlight
+	case hotelWord.MatchString(e.Summary):
+		trip.Kind = TripHotel
+	default:
+		return Trip{}, false
+	}
+
+	if e.Start.DateTime != "" {
+		start, err := localTime(e.Start.DateTime)
+		if err != nil {
+			return Trip{}, false
+		}
+		trip.Date = start.Format("2006-01-02")
+		if trip.Kind == TripFlight {
+			trip.Departs = start.Format("15:04")
+		}
+		if end, err := localTime(e.End.DateTime); err == nil && end.Format("2006-01-02") > trip.Date {
+			trip.Until = end.Format("2006-01-02")
+		}
+	} else {
+		trip.Date = e.Start.Date
+		if e.End.Date > trip.Date {
+			if last := addDays(e.End.Date, -1); last > trip.Date {
+				trip.Until = last
+			}
+		}
+	}
+	if trip.Date == "" {
+		return Trip{}, false
+	}
+
+	if trip.Kind == TripHotel {
+		trip.DestinationTZ = e.Start.TimeZone
+		return trip, true
+	}
+	trip.Until = "" // an overnight flight is still one departure
+	if number != nil {
+		trip.Flight = number[1] + " " + number[2]
+	}
+	switch {
+	case route != nil:
+		trip.From, trip.To = route[1], route[2]
+	default:
+		trip.From = knownAirport(e.Location, cfg)
+		if m := flightTo.FindStringSubmatch(e.Summary); m != nil {
+			trip.To = strings.TrimSpace(m[1])
+		}
+	}
+	// Google puts the arrival zone on the end of flights it adds from email
+	trip.DestinationTZ = e.End.TimeZone
+	if trip.DestinationTZ == "" {
+		trip.DestinationTZ = airportZone(trip.To, cfg)
+	}
+	return trip, true
+}
+
+// tripInWindow reports whether a trip departs, or a stay runs, between today
+// and days later
+func tripInWindow(t Trip, today string, days int) bool {
+	last := addDays(today, days)
+	if t.Date > last {
+		return false
+	}
+	return t.Date >= today || (t.Kind == TripHotel && t.Until >= today)
+}
+
+// upcomingTrips keeps the trips in the lookahead window, nil when there are none
+func upcomingTrips(trips []Trip, today string, days int) *TravelData {
+	var upcoming []Trip
+	for _, t := range trips {
+		if tripInWindow(t, today, days) {
+			upcoming = append(upcoming, t)
+		}
+	}
+	if upcoming == nil {
+		return nil
+	}
+	return &TravelData{Trips: upcoming}
+}
+
+// sortTrips orders trips by day and departure
+func sortTrips(trips []Trip) {
+	sort.SliceStable(trips, func(i, j int) bool {
+		if trips[i].Date != trips[j].Date {
+			return trips[i].Date < trips[j].Date
+		}
+		return trips[i].Departs < trips[j].Departs
+	})
+}
+
+// tripDay names a date relative to today: "today", "tomorrow", "Wed Jan 17"
+func tripDay(date, today string) string {
+	switch date {
+	case today:
+		return "today"
+	case addDays(today, 1):
+		return "tomorrow"
+	}
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		return t.Format("Mon Jan 2")
+	}
+	return date
+}
+
+// tripLine formats a trip, leaving out details the summary already has, e.g.
+// "tomorrow 07:40 Flight to Frankfurt (LH 773) (BKK → Frankfurt, Europe/Berlin)"
+func tripLine(t Trip, today string) string {
+	line := tripDay(t.Date, today)
+	if t.Departs != "" {
+		line += " " + t.Departs
+	}
+	line += " " + t.Summary
+
+	var details []string
+	add := func(d string) {
+		if d != "" && !strings.Contains(t.Summary, d) {
+			details = append(details, d)
+		}
+	}
+	add(t.Flight)
+	if t.From != "" && t.To != "" {
+		if !strings.Contains(t.Summary, t.From) || !strings.Contains(t.Summary, t.To) {
+			details = append(details, t.From+" → "+t.To)
+		}
+	} else {
+		add(t.To)
+	}
+	add(t.DestinationTZ)
+	if t.Until != "" {
+		if u, err := time.Parse("2006-01-02", t.Until); err == nil {
+			details = append(details, "until "+u.Format("Mon Jan 2"))
+		}
+	}
+	if len(details) == 0 {
+		return line
+	}
+	return line + " (" + strings.Join(details, ", ") + ")"
+}
+
+// tripLines lists the trips
+func tripLines(trips []Trip, today string) []string {
+	var lines []string
+	for _, t := range trips {
+		lines = append(lines, tripLine(t, today))
+	}
+	return lines
+}
+
+// packingLines are the evening's reminders to pack for tomorrow's trips
+func packingLines(d *TravelData, today string) []string {
+	if d == nil || !d.PackingReminder {
+		return nil
+	}
+	var lines []string
+	for _, line := range tripLines(d.Trips, today) {
+		lines = append(lines, "Pack tonight: "+line)
+	}
+	return lines
+}
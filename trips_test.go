@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// ==================== TRIP DETECTION TESTS ====================
+
+func tripGog(t *testing.T, data string) GogCalendarEvent {
+	var e GogCalendarEvent
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestDetectTrip(t *testing.T) {
+	inZone(t, "Asia/Bangkok")
+	cfg := TripsConfig{Airports: map[string]string{"KBV": "Asia/Bangkok"}}
+	tests := []struct {
+		name     string
+		data     string
+		expected *Trip
+	}{
+		{"flight from email", `{"summary": "Flight to Frankfurt (LH 773)", "location": "Bangkok BKK",
+			"start": {"dateTime": "2024-01-16T00:40:00Z", "timeZone": "Asia/Bangkok"},
+			"end": {"dateTime": "2024-01-16T12:30:00Z", "timeZone": "Europe/Berlin"}}`,
+			&Trip{Kind: TripFlight, Summary: "Flight to Frankfurt (LH 773)", Source: "personal", Date: "2024-01-16", Departs: "07:40",
+				Flight: "LH 773", From: "BKK", To: "Frankfurt", DestinationTZ: "Europe/Berlin"}},
+		{"route", `{"summary": "TG 642 BKK → NRT", "start": {"dateTime": "2024-01-17T23:45:00+07:00"}, "end": {"dateTime": "2024-01-18T07:45:00+09:00"}}`,
+			&Trip{Kind: TripFlight, Summary: "TG 642 BKK → NRT", Source: "personal", Date: "2024-01-17", Departs: "23:45",
+				Flight: "TG 642", From: "BKK", To: "NRT", DestinationTZ: "Asia/Tokyo"}},
+		{"configured airport", `{"summary": "BKK-KBV", "start": {"dateTime": "2024-01-16T09:00:00+07:00"}}`,
+			&Trip{Kind: TripFlight, Summary: "BKK-KBV", Source: "personal", Date: "2024-01-16", Departs: "09:00",
+				From: "BKK", To: "KBV", DestinationTZ: "Asia/Bangkok"}},
+		{"flight number at an airport", `{"summary": "LH773", "location": "FRA Terminal 1", "start": {"dateTime": "2024-01-16T13:00:00+07:00"}}`,
+			&Trip{Kind: TripFlight, Summary: "LH773", Source: "personal", Date: "2024-01-16", Departs: "13:00", Flight: "LH 773", From: "FRA"}},
+		{"hotel stay", `{"summary": "Hotel Adlon", "start": {"date": "2024-01-16"}, "end": {"date": "2024-01-19"}}`,
+			&Trip{Kind: TripHotel, Summary: "Hotel Adlon", Source: "personal", Date: "2024-01-16", Until: "2024-01-18"}},
+		{"timed check-in", `{"summary": "Check-in: Park Hyatt", "start": {"dateTime": "2024-01-18T15:00:00+09:00", "timeZone": "Asia/Tokyo"}, "end": {"dateTime": "2024-01-20T11:00:00+09:00"}}`,
+			&Trip{Kind: TripHotel, Summary: "Check-in: Park Hyatt", Source: "personal", Date: "2024-01-18", Until: "2024-01-20", DestinationTZ: "Asia/Tokyo"}},
+		{"quarter planning", `{"summary": "Q3 2024 planning", "location": "Room 4", "start": {"dateTime": "2024-01-16T09:00:00+07:00"}}`, nil},
+		{"acronyms", `{"summary": "API review with the CEO", "start": {"dateTime": "2024-01-16T09:00:00+07:00"}}`, nil},
+		{"acronyms with a dash", `{"summary": "CEO - ALL hands", "start": {"dateTime": "2024-01-16T09:00:00+07:00"}}`, nil},
+		{"hyphenated team", `{"summary": "SRE-OPS sync", "start": {"dateTime": "2024-01-16T09:00:00+07:00"}}`, nil},
+		{"acronyms with to", `{"summary": "API to SDK migration review", "start": {"dateTime": "2024-01-16T09:00:00+07:00"}}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := detectTrip(tripGog(t, tt.data), "personal", cfg)
+			if ok != (tt.expected != nil) {
+				t.Fatalf("detectTrip() ok = %v (%+v), want %v", ok, got, tt.expected != nil)
+			}
+			if ok && got != *tt.expected {
+				t.Errorf("detectTrip() = %+v, want %+v", got, *tt.expected)
+			}
+		})
+	}
+}
+
+func TestTripInWindow(t *testing.T) {
+	tests := []struct {
+		trip     Trip
+		expected bool
+	}{
+		{Trip{Kind: TripFlight, Date: "2024-01-15"}, true},
+		{Trip{Kind: TripFlight, Date: "2024-01-18"}, true},
+		{Trip{Kind: TripFlight, Date: "2024-01-19"}, false},
+		{Trip{Kind: TripFlight, Date: "2024-01-14"}, false},
+		{Trip{Kind: TripHotel, Date: "2024-01-12", Until: "2024-01-16"}, true},
+		{Trip{Kind: TripHotel, Date: "2024-01-12", Until: "2024-01-14"}, false},
+	}
+	for _, tt := range tests {
+		if got := tripInWindow(tt.trip, "2024-01-15", 3); got != tt.expected {
+			t.Errorf("tripInWindow(%+v) = %v, want %v", tt.trip, got, tt.expected)
+		}
+	}
+}
+
+func TestTripLine(t *testing.T) {
+	tests := []struct {
+		trip     Trip
+		expected string
+	}{
+		{Trip{Summary: "Flight to Frankfurt (LH 773)", Date: "2024-01-16", Departs: "07:40", Flight: "LH 773", From: "BKK", To: "Frankfurt", DestinationTZ: "Europe/Berlin"},
+			"tomorrow 07:40 Flight to Frankfurt (LH 773) (BKK → Frankfurt, Europe/Berlin)"},
+		{Trip{Summary: "TG 642 BKK → NRT", Date: "2024-01-17", Departs: "23:45", Flight: "TG 642", From: "BKK", To: "NRT", DestinationTZ: "Asia/Tokyo"},
+			"Wed Jan 17 23:45 TG 642 BKK → NRT (Asia/Tokyo)"},
+		{Trip{Summary: "Hotel Adlon", Date: "2024-01-15", Until: "2024-01-18"}, "today Hotel Adlon (until Thu Jan 18)"},
+	}
+	for _, tt := range tests {
+		if got := tripLine(tt.trip, "2024-01-15"); got != tt.expected {
+			t.Errorf("tripLine() = %q, want %q", got, tt.expected)
+		}
+	}
+}
+
+func TestMorningTravel(t *testing.T) {
+	inZone(t, "UTC")
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Calendars = []CalendarAccount{{Account: "me@example.com", Source: "personal"}}
+	fakeGog(t, `{"events": [
+		{"summary": "Standup", "start": {"dateTime": "2024-01-15T09:00:00Z"}},
+		{"summary": "Flight to Tokyo (TG 642)", "location": "BKK", "start": {"dateTime": "2024-01-17T16:45:00Z"}},
+		{"summary": "Hotel Okura", "start": {"date": "2024-01-18"}, "end": {"date": "2024-01-21"}},
+		{"summary": "Flight home", "start": {"dateTime": "2024-01-21T10:00:00Z"}}
+	]}`)
+
+	b := &MorningBriefing{TargetDate: "2024-01-15"}
+	getCalendarData(b, "2024-01-15")
+	if len(b.Calendar.MorningEvents) != 1 || b.Travel == nil || len(b.Travel.Trips) != 2 {
+		t.Fatalf("getCalendarData() calendar = %+v, travel = %+v, errors %v", b.Calendar, b.Travel, b.Errors)
+	}
+	if b.Travel.Trips[0].Flight != "TG 642" || b.Travel.Trips[1].Kind != TripHotel {
+		t.Errorf("trips = %+v, want the flight then the hotel, the flight home past the window", b.Travel.Trips)
+	}
+
+	m := *b
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Travel\n\n- Wed Jan 17 16:45 Flight to Tokyo (TG 642) (BKK → Tokyo)\n- Thu Jan 18 Hotel Okura (until Sat Jan 20)\n") {
+		t.Errorf("markdown missing travel:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Travel\n  Wed Jan 17 16:45 Flight to Tokyo") {
+		t.Errorf("text missing travel:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Travel: Wed Jan 17 16:45 Flight to Tokyo (TG 642) (BKK → Tokyo); Thu Jan 18 Hotel Okura") {
+		t.Errorf("prompt missing travel (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["travel.trips"] != 2.0 {
+		t.Errorf("travel.trips = %v", vars["travel.trips"])
+	}
+	if r := RedactMorningBriefing(m); r.Travel.Trips[0].Summary == m.Travel.Trips[0].Summary || r.Travel.Trips[0].From == "BKK" || r.Travel.Trips[0].Departs != "16:45" {
+		t.Errorf("redacted trips = %+v", r.Travel.Trips)
+	}
+	if m.Travel.Trips[0].From != "BKK" {
+		t.Error("redaction changed the original briefing")
+	}
+}
+
+func TestPackingReminder(t *testing.T) {
+	inZone(t, "UTC")
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Calendars = []CalendarAccount{{Account: "me@example.com", Source: "personal"}}
+	fakeGog(t, `{"events": [
+		{"summary": "Flight to Frankfurt (LH 773)", "location": "BKK", "start": {"dateTime": "2024-01-16T07:40:00Z"}, "end": {"timeZone": "Europe/Berlin"}},
+		{"summary": "Flight home", "start": {"dateTime": "2024-01-20T10:00:00Z"}}
+	]}`)
+
+	e := &EveningBriefing{TargetDate: "2024-01-15"}
+	getTomorrowCalendar(e, "2024-01-16")
+	if e.Travel == nil || !e.Travel.PackingReminder || len(e.Travel.Trips) != 1 {
+		t.Fatalf("travel = %+v, want a packing reminder for tomorrow's flight only", e.Travel)
+	}
+
+	want := "Pack tonight: tomorrow 07:40 Flight to Frankfurt (LH 773) (BKK → Frankfurt, Europe/Berlin)"
+	if md := EveningMarkdown(*e); !strings.Contains(md, "- "+want+"\n") {
+		t.Errorf("markdown missing the packing reminder:\n%s", md)
+	}
+	if text := EveningText(*e, textStyle{}); !strings.Contains(text, want+"\n") {
+		t.Errorf("text missing the packing reminder:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "evening", *e); err != nil || !strings.Contains(prompt, want+"\n") {
+		t.Errorf("prompt missing the packing reminder (%v):\n%s", err, prompt)
+	}
+	if n := pushFor(Delivery{Subject: "Evening", Page: eveningPage(*e)}); !n.Urgent || !strings.Contains(n.Message, want) {
+		t.Errorf("push = %+v, want the reminder flagged", n)
+	}
+	if vars := eveningRuleVars(*e); vars["travel.pack_tonight"] != true {
+		t.Errorf("travel.pack_tonight = %v", vars["travel.pack_tonight"])
+	}
+	if r := RedactEveningBriefing(*e); strings.Contains(r.Travel.Trips[0].Summary, "Frankfurt") {
+		t.Errorf("redacted trips = %+v", r.Travel.Trips)
+	}
+
+	// No trip tomorrow, no reminder
+	empty := EveningBriefing{TargetDate: "2024-01-15"}
+	if packingLines(empty.Travel, empty.TargetDate) != nil || eveningRuleVars(empty)["travel.pack_tonight"] != false {
+		t.Error("packing reminder without a trip")
+	}
+}
+
+func TestValidateTrips(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         TripsConfig
+		expectError bool
+	}{
+		{"default", DefaultConfig().Trips, false},
+		{"airport", TripsConfig{LookaheadDays: 3, Airports: map[string]string{"KBV": "Asia/Bangkok"}}, false},
+		{"negative lookahead", TripsConfig{LookaheadDays: -1}, true},
+		{"lookahead too far", TripsConfig{LookaheadDays: TripsMaxLookahead + 1}, true},
+		{"lowercase code", TripsConfig{Airports: map[string]string{"kbv": "Asia/Bangkok"}}, true},
+		{"unknown zone", TripsConfig{Airports: map[string]string{"KBV": "Asia/Krabi"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTrips(tt.cfg); (err != nil) != tt.expectError {
+				t.Errorf("validateTrips() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
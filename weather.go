@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Where the weather suits training
+const (
+	WeatherOutdoor = "outdoor"
+	WeatherIndoor  = "indoor"
+)
+
+// UVSunscreen is the UV index from which sunscreen is advised (WHO)
+const UVSunscreen = 3
+
+// MorningLightHours after sunrise is when daylight best anchors the body clock
+const MorningLightHours = 2
+
+// WeatherData is today's forecast for weather.location
+type WeatherData struct {
+	Conditions string  `json:"conditions"` // from the WMO weather code, e.g. "rain showers"
+	HighC      float64 `json:"high_c"`
+	LowC       float64 `json:"low_c"`
+	RainChance int     `json:"rain_chance"`        // highest hourly chance of rain, %
+	Humidity   int     `json:"humidity"`           // daily mean relative humidity, %
+	UVIndex    float64 `json:"uv_index"`           // the day's highest
+	UVFrom     string  `json:"uv_from,omitempty"`  // HH:MM the UV index reaches UVSunscreen
+	UVUntil    string  `json:"uv_until,omitempty"` // HH:MM it drops below again
+	Sunrise    string  `json:"sunrise,omitempty"`  // HH:MM local time at the location
+	Sunset     string  `json:"sunset,omitempty"`
+	Training   string  `json:"training"` // outdoor, indoor
+}
+
+// validateWeather checks the thresholds when a location is set
+func validateWeather(cfg WeatherConfig) error {
+	if cfg.Location == "" {
+		return nil
+	}
+	if cfg.RainChance < 0 || cfg.RainChance > 100 {
+		return fmt.Errorf("weather.rain_chance must be between 0 and 100")
+	}
+	if cfg.ColdC >= cfg.HotC {
+		return fmt.Errorf("weather.cold_c must be below hot_c")
+	}
+	return nil
+}
+
+// getWeatherData fetches today's forecast from Open-Meteo when
+// weather.location is set
+func getWeatherData(b *MorningBriefing, today string) {
+	cfg := settings.Weather
+	if cfg.Location == "" {
+		return
+	}
+	w, err := fetchWeather(cfg, today)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("weather error: %v", err))
+		return
+	}
+	b.Weather = w
+}
+
+// fetchWeather asks Open-Meteo for the day's forecast at the location,
+// geocoding an address the way travel does
+func fetchWeather(cfg WeatherConfig, date string) (*WeatherData, error) {
+	client := &http.Client{Timeout: travelTimeout}
+	lat, lon, err := geocode(client, settings.Travel, cfg.Location)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Daily struct {
+			WeatherCode []*int     `json:"weather_code"`
+			High        []*float64 `json:"temperature_2m_max"`
+			Low         []*float64 `json:"temperature_2m_min"`
+			RainChance  []*float64 `json:"precipitation_probability_max"`
+			Humidity    []*float64 `json:"relative_humidity_2m_mean"`
+			UVIndex     []*float64 `json:"uv_index_max"`
+			Sunrise     []string   `json:"sunrise"` // e.g. 2024-01-15T06:41
+			Sunset      []string   `json:"sunset"`
+		} `json:"daily"`
+		Hourly struct {
+			Time    []string   `json:"time"` // local, e.g. 2024-01-15T09:00
+			UVIndex []*float64 `json:"uv_index"`
+		} `json:"hourly"`
+	}
+	u := strings.TrimRight(cfg.URL, "/") + "/forecast?" + url.Values{
+		"latitude":   {fmt.Sprintf("%f", lat)},
+		"longitude":  {fmt.Sprintf("%f", lon)},
+		"daily":      {"weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max,relative_humidity_2m_mean,uv_index_max,sunrise,sunset"},
+		"hourly":     {"uv_index"},
+		"timezone":   {"auto"},
+		"start_date": {date},
+		"end_date":   {date},
+	}.Encode()
+	if err := travelGet(client, u, &resp); err != nil {
+		return nil, fmt.Errorf("forecast: %w", err)
+	}
+	d := resp.Daily
+	if len(d.High) == 0 || d.High[0] == nil || len(d.Low) == 0 || d.Low[0] == nil {
+		return nil, fmt.Errorf("forecast: no data for %s", date)
+	}
+	w := &WeatherData{HighC: *d.High[0], LowC: *d.Low[0]}
+	if len(d.WeatherCode) > 0 && d.WeatherCode[0] != nil {
+		w.Conditions = weatherConditions(*d.WeatherCode[0])
+	}
+	if len(d.RainChance) > 0 && d.RainChance[0] != nil {
+		w.RainChance = int(math.Round(*d.RainChance[0]))
+	}
+	if len(d.Humidity) > 0 && d.Humidity[0] != nil {
+		w.Humidity = int(math.Round(*d.Humidity[0]))
+	}
+	if len(d.UVIndex) > 0 && d.UVIndex[0] != nil {
+		w.UVIndex = *d.UVIndex[0]
+	}
+	w.UVFrom, w.UVUntil = sunscreenWindow(resp.Hourly.Time, resp.Hourly.UVIndex)
+	if len(d.Sunrise) > 0 && len(d.Sunset) > 0 {
+		w.Sunrise, w.Sunset = clockOf(d.Sunrise[0]), clockOf(d.Sunset[0])
+	}
+	w.Training, _ = weatherAdvice(w, cfg)
+	return w, nil
+}
+
+// sunscreenWindow is the first and last hour of the day the UV index is at
+// UVSunscreen or above, the last one's end
+func sunscreenWindow(times []string, uv []*float64) (string, string) {
+	from, until := "", ""
+	for i, ts := range times {
+		if i >= len(uv) || uv[i] == nil || *uv[i] < UVSunscreen {
+			continue
+		}
+		t, err := time.Parse("2006-01-02T15:04", ts)
+		if err != nil {
+			continue
+		}
+		if from == "" {
+			from = t.Format("15:04")
+		}
+		until = t.Add(time.Hour).Format("15:04")
+	}
+	if until == "00:00" {
+		until = "24:00"
+	}
+	return from, until
+}
+
+// clockOf is the HH:MM of an Open-Meteo local timestamp, empty when it
+// doesn't parse (no sunrise in polar winter)
+func clockOf(ts string) string {
+	t, err := time.Parse("2006-01-02T15:04", ts)
+	if err != nil {
+		return ""
+	}
+	return t.Format("15:04")
+}
+
+// uvCategory names a UV index on the WHO scale, which rounds it first
+func uvCategory(uv float64) string {
+	uv = math.Round(uv)
+	switch {
+	case uv < 3:
+		return "low"
+	case uv < 6:
+		return "moderate"
+	case uv < 8:
+		return "high"
+	case uv < 11:
+		return "very high"
+	}
+	return "extreme"
+}
+
+// uvValue formats the UV peak, e.g. "UV 11 (extreme, sunscreen 09:00-16:00)"
+func uvValue(w *WeatherData) string {
+	value := fmt.Sprintf("UV %.0f (%s", w.UVIndex, uvCategory(w.UVIndex))
+	if w.UVFrom != "" {
+		value += ", sunscreen " + w.UVFrom + "-" + w.UVUntil
+	}
+	return value + ")"
+}
+
+// weatherConditions names a WMO weather code
+func weatherConditions(code int) string {
+	switch {
+	case code == 0:
+		return "clear"
+	case code <= 2:
+		return "partly cloudy"
+	case code == 3:
+		return "overcast"
+	case code == 45 || code == 48:
+		return "fog"
+	case code >= 51 && code <= 57:
+		return "drizzle"
+	case code >= 61 && code <= 67:
+		return "rain"
+	case code >= 71 && code <= 77:
+		return "snow"
+	case code >= 80 && code <= 82:
+		return "rain showers"
+	case code == 85 || code == 86:
+		return "snow showers"
+	case code >= 95:
+		return "thunderstorms"
+	}
+	return "unknown"
+}
+
+// weatherAdvice decides between an outdoor session and the gym, with the
+// reason when it's the gym
+func weatherAdvice(w *WeatherData, cfg WeatherConfig) (string, string) {
+	switch {
+	case w.Conditions == "thunderstorms":
+		return WeatherIndoor, "Thunderstorms forecast"
+	case w.RainChance >= cfg.RainChance:
+		return WeatherIndoor, fmt.Sprintf("Rain likely (%d%%)", w.RainChance)
+	case w.HighC >= cfg.HotC:
+		return WeatherIndoor, fmt.Sprintf("Hot today (%.0f°C)", w.HighC)
+	case w.HighC <= cfg.ColdC:
+		return WeatherIndoor, fmt.Sprintf("Cold all day (max %.0f°C)", w.HighC)
+	}
+	return WeatherOutdoor, ""
+}
+
+// weatherLine summarizes the forecast, e.g.
+// "Rain showers, 26-33°C, 70% chance of rain, humidity 78%"
+func weatherLine(w *WeatherData) string {
+	conditions := w.Conditions
+	if conditions != "" {
+		conditions = strings.ToUpper(conditions[:1]) + conditions[1:] + ", "
+	}
+	line := fmt.Sprintf("%s%.0f-%.0f°C, %d%% chance of rain, humidity %d%%", conditions, w.LowC, w.HighC, w.RainChance, w.Humidity)
+	if w.UVIndex > 0 {
+		line += ", " + uvValue(w)
+	}
+	return line
+}
+
+// sunLine gives the day's light, e.g. "Sunrise 06:41, sunset 18:02"
+func sunLine(w *WeatherData) string {
+	if w.Sunrise == "" || w.Sunset == "" {
+		return ""
+	}
+	return "Sunrise " + w.Sunrise + ", sunset " + w.Sunset
+}
+
+// morningLightNote is appended to the recommendation after a poor night:
+// daylight soon after sunrise helps reset the body clock
+func morningLightNote(w *WeatherData, sleepQuality string) string {
+	if w == nil || w.Sunrise == "" || sleepQuality != "POOR" {
+		return ""
+	}
+	sunrise, err := parseClock(w.Sunrise)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" Get 10 min of sunlight before %s (sunrise %s) to reset your body clock.", clockAt(sunrise+MorningLightHours*60), w.Sunrise)
+}
+
+// sunNote is appended after the training advice when sunscreen is needed:
+// an outdoor session is steered outside the window
+func sunNote(w *WeatherData) string {
+	if w == nil || w.UVIndex < UVSunscreen || w.UVFrom == "" {
+		return ""
+	}
+	advice := "wear sunscreen outside"
+	if w.Training == WeatherOutdoor {
+		advice = "run before or after, or wear sunscreen"
+	}
+	return fmt.Sprintf(" UV up to %.0f (%s) %s-%s: %s.", w.UVIndex, uvCategory(w.UVIndex), w.UVFrom, w.UVUntil, advice)
+}
+
+// weatherNote is appended to the recommendation: where to train today
+func weatherNote(w *WeatherData, cfg WeatherConfig) string {
+	if w == nil {
+		return ""
+	}
+	training, reason := weatherAdvice(w, cfg)
+	if training == WeatherIndoor {
+		return " " + reason + ": train in the gym."
+	}
+	return " Good weather for an outdoor run."
+}
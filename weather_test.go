@@ -0,0 +1,230 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== WEATHER TESTS ====================
+
+// fakeOpenMeteo serves a one-day forecast for Bangkok
+func fakeOpenMeteo(t *testing.T, daily, hourly string) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/forecast" || q.Get("latitude") != "13.750000" || q.Get("longitude") != "100.500000" ||
+			q.Get("start_date") != "2024-01-15" || q.Get("end_date") != "2024-01-15" || q.Get("hourly") != "uv_index" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"daily": ` + daily + `, "hourly": ` + hourly + `}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetWeatherData(t *testing.T) {
+	ts := fakeOpenMeteo(t, `{"time": ["2024-01-15"], "weather_code": [80], "temperature_2m_max": [33.2], "temperature_2m_min": [25.6],
+		"precipitation_probability_max": [70], "relative_humidity_2m_mean": [77.6], "uv_index_max": [10.6],
+		"sunrise": ["2024-01-15T06:41"], "sunset": ["2024-01-15T18:02"]}`,
+		`{"time": ["2024-01-15T08:00", "2024-01-15T09:00", "2024-01-15T12:00", "2024-01-15T15:00", "2024-01-15T16:00"], "uv_index": [1.5, 3.2, 10.6, 4.1, 2.0]}`)
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Weather.Location = "13.75,100.5"
+	settings.Weather.URL = ts.URL + "/"
+
+	b := &MorningBriefing{TargetDate: "2024-01-15"}
+	getWeatherData(b, "2024-01-15")
+	want := WeatherData{Conditions: "rain showers", HighC: 33.2, LowC: 25.6, RainChance: 70, Humidity: 78,
+		UVIndex: 10.6, UVFrom: "09:00", UVUntil: "16:00", Sunrise: "06:41", Sunset: "18:02", Training: WeatherIndoor}
+	if b.Weather == nil || *b.Weather != want || len(b.Errors) != 0 {
+		t.Fatalf("weather = %+v, errors %v, want %+v", b.Weather, b.Errors, want)
+	}
+
+	settings.Weather.Location = "0,0"
+	b = &MorningBriefing{TargetDate: "2024-01-15"}
+	getWeatherData(b, "2024-01-15")
+	if b.Weather != nil || len(b.Errors) != 1 || !strings.HasPrefix(b.Errors[0], "weather error: forecast: status 400") {
+		t.Errorf("weather = %+v, errors %v", b.Weather, b.Errors)
+	}
+
+	settings.Weather.Location = ""
+	b = &MorningBriefing{TargetDate: "2024-01-15"}
+	getWeatherData(b, "2024-01-15")
+	if b.Weather != nil || len(b.Errors) != 0 {
+		t.Errorf("weather without a location = %+v, %v", b.Weather, b.Errors)
+	}
+}
+
+func TestFetchWeatherMissingData(t *testing.T) {
+	ts := fakeOpenMeteo(t, `{"time": ["2024-01-15"], "temperature_2m_max": [null], "temperature_2m_min": [null]}`, `{}`)
+	cfg := DefaultConfig().Weather
+	cfg.Location, cfg.URL = "13.75,100.5", ts.URL
+	if _, err := fetchWeather(cfg, "2024-01-15"); err == nil || !strings.Contains(err.Error(), "no data") {
+		t.Errorf("fetchWeather() error = %v, want no data", err)
+	}
+}
+
+func TestWeatherNote(t *testing.T) {
+	cfg := DefaultConfig().Weather // rain 50%, hot 32°C, cold 0°C
+	tests := []struct {
+		name     string
+		weather  *WeatherData
+		expected string
+	}{
+		{"none", nil, ""},
+		{"fine", &WeatherData{Conditions: "partly cloudy", HighC: 24, RainChance: 10}, " Good weather for an outdoor run."},
+		{"rain", &WeatherData{Conditions: "rain", HighC: 24, RainChance: 80}, " Rain likely (80%): train in the gym."},
+		{"storms", &WeatherData{Conditions: "thunderstorms", HighC: 24, RainChance: 40}, " Thunderstorms forecast: train in the gym."},
+		{"hot", &WeatherData{Conditions: "clear", HighC: 35.4, RainChance: 0}, " Hot today (35°C): train in the gym."},
+		{"cold", &WeatherData{Conditions: "snow", HighC: -3, RainChance: 20}, " Cold all day (max -3°C): train in the gym."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weatherNote(tt.weather, cfg); got != tt.expected {
+				t.Errorf("weatherNote() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSunscreenWindow(t *testing.T) {
+	uv := func(vs ...float64) []*float64 {
+		out := make([]*float64, len(vs))
+		for i := range vs {
+			out[i] = &vs[i]
+		}
+		return out
+	}
+	hours := []string{"2024-01-15T10:00", "2024-01-15T11:00", "2024-01-15T12:00", "2024-01-15T23:00"}
+	tests := []struct {
+		name        string
+		uv          []*float64
+		from, until string
+	}{
+		{"midday", uv(2, 5, 3, 0), "11:00", "13:00"},
+		{"never", uv(1, 2, 2.9, 0), "", ""},
+		{"last hour of the day", uv(0, 0, 0, 3), "23:00", "24:00"},
+		{"missing readings", []*float64{nil, nil, ptr(4.0)}, "12:00", "13:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if from, until := sunscreenWindow(hours, tt.uv); from != tt.from || until != tt.until {
+				t.Errorf("sunscreenWindow() = %q, %q, want %q, %q", from, until, tt.from, tt.until)
+			}
+		})
+	}
+}
+
+func TestSunNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		weather  *WeatherData
+		expected string
+	}{
+		{"none", nil, ""},
+		{"low", &WeatherData{UVIndex: 2, Training: WeatherOutdoor}, ""},
+		{"outdoor", &WeatherData{UVIndex: 10.6, UVFrom: "09:00", UVUntil: "16:00", Training: WeatherOutdoor}, " UV up to 11 (extreme) 09:00-16:00: run before or after, or wear sunscreen."},
+		{"indoor", &WeatherData{UVIndex: 6, UVFrom: "11:00", UVUntil: "14:00", Training: WeatherIndoor}, " UV up to 6 (high) 11:00-14:00: wear sunscreen outside."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sunNote(tt.weather); got != tt.expected {
+				t.Errorf("sunNote() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+	if got := uvCategory(7.6); got != "very high" {
+		t.Errorf("uvCategory(7.6) = %q, want very high", got)
+	}
+}
+
+func TestMorningLightNote(t *testing.T) {
+	w := &WeatherData{Sunrise: "06:41", Sunset: "18:02"}
+	tests := []struct {
+		name     string
+		weather  *WeatherData
+		sleep    string
+		expected string
+	}{
+		{"poor night", w, "POOR", " Get 10 min of sunlight before 08:41 (sunrise 06:41) to reset your body clock."},
+		{"good night", w, "GOOD", ""},
+		{"no weather", nil, "POOR", ""},
+		{"no sunrise", &WeatherData{}, "POOR", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := morningLightNote(tt.weather, tt.sleep); got != tt.expected {
+				t.Errorf("morningLightNote() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+	b := &MorningBriefing{Sleep: SleepData{TotalHours: ptr(4.5), DataAvailable: true, IsCurrentDay: true}, Weather: w}
+	classify(b)
+	if b.Classification.SleepQuality != "POOR" || !strings.Contains(b.Classification.Recommendation, " Get 10 min of sunlight before 08:41") {
+		t.Errorf("sleep %s, recommendation = %q", b.Classification.SleepQuality, b.Classification.Recommendation)
+	}
+	if got := clockOf("not a time"); got != "" {
+		t.Errorf("clockOf() = %q, want empty", got)
+	}
+}
+
+func TestWeatherConditions(t *testing.T) {
+	tests := map[int]string{0: "clear", 2: "partly cloudy", 3: "overcast", 45: "fog", 53: "drizzle", 63: "rain", 75: "snow", 81: "rain showers", 86: "snow showers", 95: "thunderstorms", 20: "unknown"}
+	for code, want := range tests {
+		if got := weatherConditions(code); got != want {
+			t.Errorf("weatherConditions(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestValidateWeather(t *testing.T) {
+	on := func(f func(*WeatherConfig)) WeatherConfig {
+		cfg := DefaultConfig().Weather
+		cfg.Location = "13.75,100.5"
+		f(&cfg)
+		return cfg
+	}
+	tests := []struct {
+		name        string
+		cfg         WeatherConfig
+		expectError bool
+	}{
+		{"off", DefaultConfig().Weather, false},
+		{"default", on(func(*WeatherConfig) {}), false},
+		{"rain over 100", on(func(c *WeatherConfig) { c.RainChance = 120 }), true},
+		{"cold above hot", on(func(c *WeatherConfig) { c.ColdC = 35 }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWeather(tt.cfg); (err != nil) != tt.expectError {
+				t.Errorf("validateWeather() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestWeatherRendering(t *testing.T) {
+	m := MorningBriefing{TargetDate: "2024-01-15", Weather: &WeatherData{Conditions: "rain showers", HighC: 33.2, LowC: 25.6, RainChance: 70, Humidity: 78,
+		UVIndex: 10.6, UVFrom: "09:00", UVUntil: "16:00", Sunrise: "06:41", Sunset: "18:02", Training: WeatherIndoor}}
+	line := "Rain showers, 26-33°C, 70% chance of rain, humidity 78%, UV 11 (extreme, sunscreen 09:00-16:00)"
+	if md := MorningMarkdown(m); !strings.Contains(md, "## Weather\n\n"+line+"\n\nSunrise 06:41, sunset 18:02\n") {
+		t.Errorf("markdown missing weather:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Weather  "+line+"\n  Sunrise 06:41, sunset 18:02\n") {
+		t.Errorf("text missing weather:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Weather: "+line+" (indoor training)\nSunrise 06:41, sunset 18:02\n") {
+		t.Errorf("prompt missing weather (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["weather.rain_chance"] != 70.0 || vars["weather.training"] != WeatherIndoor {
+		t.Errorf("weather vars = %v, %v", vars["weather.rain_chance"], vars["weather.training"])
+	}
+
+	b := &MorningBriefing{Sleep: SleepData{TotalHours: ptr(8.0), DataAvailable: true}, Weather: m.Weather}
+	classify(b)
+	if !strings.HasSuffix(b.Classification.Recommendation, " Rain likely (70%): train in the gym. UV up to 11 (extreme) 09:00-16:00: wear sunscreen outside.") {
+		t.Errorf("recommendation = %q", b.Classification.Recommendation)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultWebhookTriggers fire when a classification enters a bad state
+var DefaultWebhookTriggers = []string{
+	"recovery_status=POOR",
+	"sleep_quality=POOR",
+}
+
+const webhookTimeout = 10 * time.Second
+
+// ClassificationEvent is the compact JSON posted to webhooks
+type ClassificationEvent struct {
+	Event          string `json:"event"`
+	Field          string `json:"field"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Date           string `json:"date"`
+	GeneratedAt    string `json:"generated_at"`
+	ReadinessScore *int   `json:"readiness_score,omitempty"`
+	Recommendation string `json:"recommendation"`
+}
+
+// classificationValues returns the trigger-able fields of a morning briefing
+func classificationValues(b MorningBriefing) map[string]string {
+	return map[string]string{
+		"sleep_quality":   b.Classification.SleepQuality,
+		"recovery_status": b.Classification.RecoveryStatus,
+		"morning_load":    b.Classification.MorningLoad,
+		"illness_risk":    b.Classification.IllnessRisk,
+	}
+}
+
+// DetectTransitions returns events for triggers ("field=VALUE") whose field
+// has just entered VALUE. Unknown previous values count as a change.
+func DetectTransitions(prev, curr map[string]string, triggers []string) []ClassificationEvent {
+	var events []ClassificationEvent
+	for _, trigger := range triggers {
+		field, value, ok := strings.Cut(trigger, "=")
+		if !ok {
+			continue
+		}
+		if curr[field] == value && prev[field] != value {
+			events = append(events, ClassificationEvent{
+				Event: "classification_changed",
+				Field: field,
+				From:  prev[field],
+				To:    value,
+			})
+		}
+	}
+	return events
+}
+
+// getClassificationStatePath returns where the last-seen classifications are kept
+func getClassificationStatePath() string {
+	return filepath.Join(getDataDir(), "classification-state.json")
+}
+
+// loadClassificationState reads the last values each webhook was notified
+// of, by URL. A state file from before webhooks were tracked separately
+// holds one set of values, which every webhook starts from.
+func loadClassificationState(path string, webhooks []WebhookConfig) (map[string]map[string]string, error) {
+	state := map[string]map[string]string{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err == nil {
+		return state, nil
+	}
+	var shared map[string]string
+	if err := json.Unmarshal(data, &shared); err != nil {
+		return state, err
+	}
+	for _, wh := range webhooks {
+		state[wh.URL] = shared
+	}
+	return state, nil
+}
+
+// nextClassificationState records current values, keeping the last known
+// value for fields that are UNKNOWN today
+func nextClassificationState(prev, curr map[string]string) map[string]string {
+	next := map[string]string{}
+	for k, v := range prev {
+		next[k] = v
+	}
+	for k, v := range curr {
+		if v != "" && v != "UNKNOWN" {
+			next[k] = v
+		}
+	}
+	return next
+}
+
+func saveClassificationState(path string, state map[string]map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, _ := json.MarshalIndent(state, "", "  ")
+	return os.WriteFile(path, data, 0o600)
+}
+
+// PostWebhook sends a single event as JSON
+func PostWebhook(url string, event ClassificationEvent) error {
+	return postJSON(url, event)
+}
+
+// postJSON posts v as a JSON body and treats non-2xx responses as errors
+func postJSON(url string, v any) error {
+	body, _ := json.Marshal(v)
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook error (%s): %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook error (%s): status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// notifyWebhooks fires configured webhooks for classification transitions
+// and updates the stored state. Each webhook's state only advances once all
+// of its events are delivered, so a failed one is retried on the next run.
+// Returns any delivery errors.
+func notifyWebhooks(webhooks []WebhookConfig, statePath string, b MorningBriefing) []error {
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	state, err := loadClassificationState(statePath, webhooks)
+	if err != nil {
+		return []error{fmt.Errorf("webhook state error: %w", err)}
+	}
+	curr := classificationValues(b)
+
+	var errs []error
+	for _, wh := range webhooks {
+		triggers := wh.Triggers
+		if len(triggers) == 0 {
+			triggers = DefaultWebhookTriggers
+		}
+		prev := state[wh.URL]
+		delivered := true
+		for _, event := range DetectTransitions(prev, curr, triggers) {
+			event.Date = b.TargetDate
+			event.GeneratedAt = b.GeneratedAt
+			event.ReadinessScore = b.Classification.ReadinessScore
+			event.Recommendation = b.Classification.Recommendation
+			if err := PostWebhook(wh.URL, event); err != nil {
+				errs = append(errs, err)
+				delivered = false
+			}
+		}
+		if delivered {
+			state[wh.URL] = nextClassificationState(prev, curr)
+		}
+	}
+
+	if err := saveClassificationState(statePath, state); err != nil {
+		errs = append(errs, fmt.Errorf("webhook state error: %w", err))
+	}
+	return errs
+}
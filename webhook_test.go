@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// ==================== WEBHOOK TESTS ====================
+
+func TestDetectTransitions(t *testing.T) {
+	triggers := []string{"recovery_status=POOR", "sleep_quality=POOR", "malformed"}
+
+	tests := []struct {
+		name     string
+		prev     map[string]string
+		curr     map[string]string
+		expected []string // fields that fire
+	}{
+		{
+			name:     "Recovery drops to POOR",
+			prev:     map[string]string{"recovery_status": "OK", "sleep_quality": "GOOD"},
+			curr:     map[string]string{"recovery_status": "POOR", "sleep_quality": "GOOD"},
+			expected: []string{"recovery_status"},
+		},
+		{
+			name:     "Still POOR does not re-fire",
+			prev:     map[string]string{"recovery_status": "POOR"},
+			curr:     map[string]string{"recovery_status": "POOR"},
+			expected: nil,
+		},
+		{
+			name:     "First run with no state",
+			prev:     map[string]string{},
+			curr:     map[string]string{"recovery_status": "POOR", "sleep_quality": "POOR"},
+			expected: []string{"recovery_status", "sleep_quality"},
+		},
+		{
+			name:     "Recovering does not fire",
+			prev:     map[string]string{"recovery_status": "POOR"},
+			curr:     map[string]string{"recovery_status": "GOOD"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := DetectTransitions(tt.prev, tt.curr, triggers)
+			if len(events) != len(tt.expected) {
+				t.Fatalf("DetectTransitions() = %+v, want fields %v", events, tt.expected)
+			}
+			for i, e := range events {
+				if e.Field != tt.expected[i] {
+					t.Errorf("events[%d].Field = %q, want %q", i, e.Field, tt.expected[i])
+				}
+				if e.From != tt.prev[e.Field] {
+					t.Errorf("events[%d].From = %q, want %q", i, e.From, tt.prev[e.Field])
+				}
+			}
+		})
+	}
+}
+
+func TestDetectTransitionsIllnessRisk(t *testing.T) {
+	triggers := []string{"illness_risk=HIGH"}
+	prev := classificationValues(MorningBriefing{Classification: Classification{IllnessRisk: "LOW"}})
+	curr := classificationValues(MorningBriefing{Classification: Classification{IllnessRisk: "HIGH"}})
+
+	events := DetectTransitions(prev, curr, triggers)
+	if len(events) != 1 || events[0].Field != "illness_risk" || events[0].From != "LOW" || events[0].To != "HIGH" {
+		t.Errorf("DetectTransitions() = %+v, want illness_risk LOW -> HIGH", events)
+	}
+	if events := DetectTransitions(curr, curr, triggers); len(events) != 0 {
+		t.Errorf("DetectTransitions() re-fired: %+v", events)
+	}
+}
+
+func TestNotifyWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	var received []ClassificationEvent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e ClassificationEvent
+		json.NewDecoder(r.Body).Decode(&e)
+		mu.Lock()
+		received = append(received, e)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	statePath := filepath.Join(t.TempDir(), "classification-state.json")
+	webhooks := []WebhookConfig{{URL: ts.URL, Triggers: []string{"recovery_status=POOR"}}}
+
+	day := func(date, recovery string) MorningBriefing {
+		return MorningBriefing{
+			TargetDate: date,
+			Classification: Classification{
+				RecoveryStatus: recovery,
+				Recommendation: "Consider lighter activity today.",
+			},
+		}
+	}
+
+	for _, b := range []MorningBriefing{
+		day("2024-01-13", "GOOD"),
+		day("2024-01-14", "POOR"),    // fires
+		day("2024-01-15", "UNKNOWN"), // missing data keeps POOR as last known
+		day("2024-01-16", "POOR"),    // no re-fire
+	} {
+		if errs := notifyWebhooks(webhooks, statePath, b); len(errs) > 0 {
+			t.Fatalf("notifyWebhooks(%s) errors: %v", b.TargetDate, errs)
+		}
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("received %d events, want 1: %+v", len(received), received)
+	}
+	e := received[0]
+	if e.Date != "2024-01-14" || e.From != "GOOD" || e.To != "POOR" {
+		t.Errorf("event = %+v, want 2024-01-14 GOOD -> POOR", e)
+	}
+	if e.Recommendation == "" {
+		t.Error("event missing recommendation")
+	}
+}
+
+func TestNotifyWebhooksRetriesFailed(t *testing.T) {
+	var mu sync.Mutex
+	var fail bool
+	received := map[string]int{}
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if name == "flaky" && fail {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			received[name]++
+		}
+	}
+	flaky := httptest.NewServer(handler("flaky"))
+	defer flaky.Close()
+	steady := httptest.NewServer(handler("steady"))
+	defer steady.Close()
+
+	statePath := filepath.Join(t.TempDir(), "classification-state.json")
+	webhooks := []WebhookConfig{{URL: flaky.URL}, {URL: steady.URL}}
+	poor := MorningBriefing{TargetDate: "2024-01-14", Classification: Classification{RecoveryStatus: "POOR"}}
+
+	fail = true
+	if errs := notifyWebhooks(webhooks, statePath, poor); len(errs) != 1 {
+		t.Fatalf("notifyWebhooks() errors = %v, want the 500", errs)
+	}
+	fail = false
+	poor.TargetDate = "2024-01-15"
+	if errs := notifyWebhooks(webhooks, statePath, poor); len(errs) > 0 {
+		t.Fatalf("notifyWebhooks() errors: %v", errs)
+	}
+	if received["flaky"] != 1 || received["steady"] != 1 {
+		t.Errorf("received = %v, want the failed event retried once and the delivered one not repeated", received)
+	}
+}
+
+func TestLoadClassificationStateShared(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "classification-state.json")
+	if err := os.WriteFile(statePath, []byte(`{"recovery_status": "POOR"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	webhooks := []WebhookConfig{{URL: "https://a.example"}, {URL: "https://b.example"}}
+	state, err := loadClassificationState(statePath, webhooks)
+	if err != nil {
+		t.Fatalf("loadClassificationState() error: %v", err)
+	}
+	for _, wh := range webhooks {
+		if state[wh.URL]["recovery_status"] != "POOR" {
+			t.Errorf("state[%s] = %v, want the shared values", wh.URL, state[wh.URL])
+		}
+	}
+}
+
+func TestPostWebhookErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	if err := PostWebhook(ts.URL, ClassificationEvent{}); err == nil {
+		t.Error("PostWebhook() expected error for 500 response, got nil")
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// metricTimestampLayout is the timestamp format health-ingest writes to the metrics table
+const metricTimestampLayout = "2006-01-02 15:04:05 -0700"
+
+// WeeklyReport is the output structure for the weekly review
+type WeeklyReport struct {
+	Mode         string           `json:"mode"`
+	GeneratedAt  string           `json:"generated_at"`
+	WeekStart    string           `json:"week_start"`
+	WeekEnd      string           `json:"week_end"`
+	EatingWindow EatingWindowData `json:"eating_window"`
+	Trends       WeeklyTrends     `json:"trends"`
+	Workouts     *int             `json:"workouts,omitempty"`
+	Zone2        *Zone2Progress   `json:"zone2,omitempty"` // Zone 2 minutes from Apple workouts vs the weekly target
+	Adherence    *MedAdherence    `json:"medication_adherence,omitempty"`
+	Tags         []LifeTag        `json:"tags,omitempty"`
+	Errors       []string         `json:"errors,omitempty"`
+}
+
+// WeeklyTrends holds one value per day that has data, oldest first
+type WeeklyTrends struct {
+	SleepHours []dailyValue `json:"sleep_hours"`
+	HRV        []dailyValue `json:"hrv_ms"`
+	WeightKg   []dailyValue `json:"weight_kg"`
+}
+
+type EatingWindowData struct {
+	WindowStart    string      `json:"window_start"`
+	WindowEnd      string      `json:"window_end"`
+	DaysLogged     int         `json:"days_logged"`
+	DaysCompliant  int         `json:"days_compliant"`
+	AvgWindowHours float64     `json:"avg_window_hours"`
+	Days           []EatingDay `json:"days"`
+}
+
+type EatingDay struct {
+	Date        string  `json:"date"`
+	FirstMeal   string  `json:"first_meal"`
+	LastMeal    string  `json:"last_meal"`
+	WindowHours float64 `json:"window_hours"`
+	Compliant   bool    `json:"compliant"`
+}
+
+// RunWeeklyReport generates the weekly review output
+func RunWeeklyReport(opts RunOptions) {
+	now := time.Now()
+	today := now.Format("2006-01-02")
+
+	report := WeeklyReport{
+		Mode:        "weekly",
+		GeneratedAt: now.Format(time.RFC3339),
+		WeekStart:   addDays(today, -6),
+		WeekEnd:     today,
+		EatingWindow: EatingWindowData{
+			WindowStart: settings.EatingWindow.Start,
+			WindowEnd:   settings.EatingWindow.End,
+			Days:        []EatingDay{},
+		},
+	}
+
+	getWeeklyHealthData(&report)
+	getWeeklyWorkouts(&report)
+
+	tags, err := loadTags(report.WeekStart, report.WeekEnd)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("tags error: %v", err))
+	}
+	report.Tags = tags
+
+	adherence, err := loadMedAdherence(report.WeekStart, report.WeekEnd)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("adherence error: %v", err))
+	}
+	report.Adherence = adherence
+
+	if opts.Format == "card" {
+		if err := RenderWeeklyCardPNG(os.Stdout, report, opts.Width, opts.Height); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Output JSON
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+}
+
+func getWeeklyHealthData(r *WeeklyReport) {
+	dbPath := getHealthDBPath()
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("sqlite open error: %v", err))
+		return
+	}
+	defer db.Close()
+
+	// Eating window per day
+	for date := r.WeekStart; date <= r.WeekEnd; date = addDays(date, 1) {
+		first, last, err := queryMealTimeRange(db, date)
+		if err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("eating window query error (%s): %v", date, err))
+			continue
+		}
+		if first == nil {
+			continue // Nothing logged
+		}
+		r.EatingWindow.Days = append(r.EatingWindow.Days,
+			EvaluateEatingDay(date, *first, *last, r.EatingWindow.WindowStart, r.EatingWindow.WindowEnd))
+	}
+
+	r.EatingWindow.DaysLogged, r.EatingWindow.DaysCompliant, r.EatingWindow.AvgWindowHours =
+		SummarizeEatingWindow(r.EatingWindow.Days)
+
+	getWeeklyTrends(db, r)
+	getWeeklyZone2(db, r)
+}
+
+// getWeeklyZone2 sets the week's Zone 2 minutes against the target
+func getWeeklyZone2(db *sql.DB, r *WeeklyReport) {
+	zones, err := queryHRZones(db, r.WeekEnd, settings.User.MaxHeartRate())
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("HR zone query error: %v", err))
+		return
+	}
+	r.Zone2 = zone2ProgressFor(zones, settings.User.Zone2TargetMin)
+}
+
+// getWeeklyTrends loads the daily series drawn as sparklines on the weekly card
+func getWeeklyTrends(db *sql.DB, r *WeeklyReport) {
+	series := []struct {
+		metric string
+		dest   *[]dailyValue
+	}{
+		{"sleep_total", &r.Trends.SleepHours},
+		{"heart_rate_variability", &r.Trends.HRV},
+		{"weight_body_mass", &r.Trends.WeightKg},
+	}
+	for _, s := range series {
+		values, err := queryDailyValues(db, s.metric, r.WeekStart, r.WeekEnd)
+		if err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s query error: %v", s.metric, err))
+		}
+		for i := range values {
+			values[i].Value = roundTo(values[i].Value, 1)
+		}
+		*s.dest = append([]dailyValue{}, values...)
+	}
+}
+
+// getWeeklyWorkouts counts Hevy workouts that started within the week
+func getWeeklyWorkouts(r *WeeklyReport) {
+	workouts, err := fetchHevyWorkouts()
+	if err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		return
+	}
+	count := CountWorkoutsInRange(workouts, r.WeekStart, r.WeekEnd)
+	r.Workouts = &count
+}
+
+// CountWorkoutsInRange counts workouts whose start date falls in [from, to]
+func CountWorkoutsInRange(workouts []HevyWorkout, from, to string) int {
+	count := 0
+	for _, w := range workouts {
+		t, err := localTime(w.StartTime)
+		if err != nil {
+			continue
+		}
+		if date := t.Format("2006-01-02"); date >= from && date <= to {
+			count++
+		}
+	}
+	return count
+}
+
+// Query first and last dietary_energy entries for a given date from SQLite
+func queryMealTimeRange(db *sql.DB, date string) (first, last *time.Time, err error) {
+	query := `
+		SELECT timestamp FROM metrics
+		WHERE metric_name = 'dietary_energy'
+		AND value > 0
+		AND local_ts(timestamp) LIKE ? || '%'
+		ORDER BY local_ts(timestamp)
+	`
+	rows, err := db.Query(query, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ts string
+		if err := rows.Scan(&ts); err != nil {
+			continue
+		}
+		t, err := time.Parse(metricTimestampLayout, ts)
+		if err != nil {
+			continue
+		}
+		t = t.In(time.Local)
+		if first == nil {
+			first = &t
+		}
+		last = &t
+	}
+	return first, last, rows.Err()
+}
+
+// validateEatingWindow checks the window is two clock times, start first
+func validateEatingWindow(cfg EatingWindowConfig) error {
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return fmt.Errorf("eating_window.start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return fmt.Errorf("eating_window.end: %w", err)
+	}
+	if end <= start {
+		return errors.New("eating_window.end must be after start")
+	}
+	return nil
+}
+
+// EvaluateEatingDay checks whether all meals fell inside the configured window
+// Window bounds are "HH:MM" in the meal's own timezone
+func EvaluateEatingDay(date string, first, last time.Time, windowStart, windowEnd string) EatingDay {
+	day := EatingDay{
+		Date:        date,
+		FirstMeal:   first.Format("15:04"),
+		LastMeal:    last.Format("15:04"),
+		WindowHours: roundTo(last.Sub(first).Hours(), 1),
+	}
+	day.Compliant = day.FirstMeal >= windowStart && day.LastMeal <= windowEnd
+	return day
+}
+
+// SummarizeEatingWindow aggregates daily results
+// Returns: days logged, days compliant, average window length in hours
+func SummarizeEatingWindow(days []EatingDay) (int, int, float64) {
+	if len(days) == 0 {
+		return 0, 0, 0
+	}
+	compliant := 0
+	total := 0.0
+	for _, d := range days {
+		if d.Compliant {
+			compliant++
+		}
+		total += d.WindowHours
+	}
+	return len(days), compliant, roundTo(total/float64(len(days)), 1)
+}
+
+// roundTo rounds a value to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow10(places)
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ==================== EATING WINDOW TESTS ====================
+
+func TestEvaluateEatingDay(t *testing.T) {
+	tz := time.FixedZone("ICT", 7*3600)
+	tests := []struct {
+		name          string
+		first         time.Time
+		last          time.Time
+		expectedHours float64
+		expectedOK    bool
+	}{
+		{
+			name:          "Inside window",
+			first:         time.Date(2024, 1, 15, 12, 30, 0, 0, tz),
+			last:          time.Date(2024, 1, 15, 19, 45, 0, 0, tz),
+			expectedHours: 7.3,
+			expectedOK:    true,
+		},
+		{
+			name:          "Exactly on bounds",
+			first:         time.Date(2024, 1, 15, 12, 0, 0, 0, tz),
+			last:          time.Date(2024, 1, 15, 20, 0, 0, 0, tz),
+			expectedHours: 8,
+			expectedOK:    true,
+		},
+		{
+			name:          "Early breakfast",
+			first:         time.Date(2024, 1, 15, 8, 0, 0, 0, tz),
+			last:          time.Date(2024, 1, 15, 19, 0, 0, 0, tz),
+			expectedHours: 11,
+			expectedOK:    false,
+		},
+		{
+			name:          "Late snack",
+			first:         time.Date(2024, 1, 15, 13, 0, 0, 0, tz),
+			last:          time.Date(2024, 1, 15, 22, 15, 0, 0, tz),
+			expectedHours: 9.3,
+			expectedOK:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day := EvaluateEatingDay("2024-01-15", tt.first, tt.last, "12:00", "20:00")
+			if day.WindowHours != tt.expectedHours {
+				t.Errorf("WindowHours = %v, want %v", day.WindowHours, tt.expectedHours)
+			}
+			if day.Compliant != tt.expectedOK {
+				t.Errorf("Compliant = %v, want %v", day.Compliant, tt.expectedOK)
+			}
+		})
+	}
+}
+
+func TestSummarizeEatingWindow(t *testing.T) {
+	days := []EatingDay{
+		{Date: "2024-01-13", WindowHours: 7, Compliant: true},
+		{Date: "2024-01-14", WindowHours: 10, Compliant: false},
+		{Date: "2024-01-15", WindowHours: 8, Compliant: true},
+	}
+
+	logged, compliant, avg := SummarizeEatingWindow(days)
+	if logged != 3 {
+		t.Errorf("logged = %d, want 3", logged)
+	}
+	if compliant != 2 {
+		t.Errorf("compliant = %d, want 2", compliant)
+	}
+	if avg != 8.3 {
+		t.Errorf("avg = %v, want 8.3", avg)
+	}
+
+	logged, compliant, avg = SummarizeEatingWindow(nil)
+	if logged != 0 || compliant != 0 || avg != 0 {
+		t.Errorf("SummarizeEatingWindow(nil) = %d, %d, %v, want zeros", logged, compliant, avg)
+	}
+}
+
+func TestQueryMealTimeRange(t *testing.T) {
+	db := newTestMetricsDB(t)
+
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit) VALUES
+		('dietary_energy', '2024-01-15 12:15:00 +0700', 650, 'kcal'),
+		('dietary_energy', '2024-01-15 19:40:00 +0700', 820, 'kcal'),
+		('dietary_energy', '2024-01-15 23:00:00 +0700', 0, 'kcal'),
+		('protein', '2024-01-15 07:00:00 +0700', 30, 'g')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, last, err := queryMealTimeRange(db, "2024-01-15")
+	if err != nil {
+		t.Fatalf("queryMealTimeRange error: %v", err)
+	}
+	if first == nil || first.Format("15:04") != "12:15" {
+		t.Errorf("first = %v, want 12:15", first)
+	}
+	if last == nil || last.Format("15:04") != "19:40" {
+		t.Errorf("last = %v, want 19:40 (zero-kcal rows ignored)", last)
+	}
+
+	first, _, err = queryMealTimeRange(db, "2024-01-16")
+	if err != nil {
+		t.Fatalf("queryMealTimeRange error: %v", err)
+	}
+	if first != nil {
+		t.Errorf("first = %v, want nil for day without meals", first)
+	}
+}
+
+// ==================== WEEKLY CARD DATA TESTS ====================
+
+func TestGetWeeklyTrends(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('sleep_total', '2024-01-09 06:00:00 +0700', 7.23, 'hr', 'Apple Watch'),
+		('sleep_total', '2024-01-08 06:00:00 +0700', 9.0, 'hr', 'Apple Watch'),
+		('heart_rate_variability', '2024-01-10 06:00:00 +0700', 40, 'ms', 'Apple Watch'),
+		('heart_rate_variability', '2024-01-10 07:00:00 +0700', 50, 'ms', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := WeeklyReport{WeekStart: "2024-01-09", WeekEnd: "2024-01-15"}
+	getWeeklyTrends(db, &r)
+
+	if len(r.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	if len(r.Trends.SleepHours) != 1 || r.Trends.SleepHours[0].Value != 7.2 {
+		t.Errorf("SleepHours = %v, want one day of 7.2", r.Trends.SleepHours)
+	}
+	if len(r.Trends.HRV) != 1 || r.Trends.HRV[0].Value != 45 {
+		t.Errorf("HRV = %v, want one day averaging 45", r.Trends.HRV)
+	}
+	if r.Trends.WeightKg == nil || len(r.Trends.WeightKg) != 0 {
+		t.Errorf("WeightKg = %v, want empty (not nil)", r.Trends.WeightKg)
+	}
+}
+
+func TestGetWeeklyZone2(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source, raw_json) VALUES
+		('workout', '2024-01-12 07:00:00 +0700', 30, 'min', 'Apple Watch', '{"start": "2024-01-12 07:00:00 +0700", "end": "2024-01-12 07:30:00 +0700"}'),
+		('heart_rate', '2024-01-12 07:00:00 +0700', 110, 'bpm', 'Apple Watch', NULL),
+		('heart_rate', '2024-01-12 07:05:00 +0700', 112, 'bpm', 'Apple Watch', NULL),
+		('heart_rate', '2024-01-12 07:10:00 +0700', 112, 'bpm', 'Apple Watch', NULL)
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.User.Age = 40
+	settings.User.Zone2TargetMin = 100
+
+	r := WeeklyReport{WeekStart: "2024-01-09", WeekEnd: "2024-01-15"}
+	getWeeklyZone2(db, &r)
+	if r.Zone2 == nil || *r.Zone2 != (Zone2Progress{Minutes: 10, TargetMinutes: 100, Pct: 10}) {
+		t.Errorf("Zone2 = %+v, want 10 of 100 min", r.Zone2)
+	}
+}
+
+func TestCountWorkoutsInRange(t *testing.T) {
+	workouts := []HevyWorkout{
+		{StartTime: "2024-01-15T18:00:00+07:00"},
+		{StartTime: "2024-01-12T07:00:00+07:00"},
+		{StartTime: "2024-01-09T07:00:00+07:00"},
+		{StartTime: "2024-01-08T07:00:00+07:00"},
+		{StartTime: "not a time"},
+	}
+	if got := CountWorkoutsInRange(workouts, "2024-01-09", "2024-01-15"); got != 3 {
+		t.Errorf("CountWorkoutsInRange() = %d, want 3", got)
+	}
+}
+
+func TestValidateEatingWindow(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         EatingWindowConfig
+		expectError bool
+	}{
+		{"default", DefaultConfig().EatingWindow, false},
+		{"18:6", EatingWindowConfig{Start: "10:00", End: "16:00"}, false},
+		{"bad start", EatingWindowConfig{Start: "noon", End: "20:00"}, true},
+		{"missing end", EatingWindowConfig{Start: "12:00"}, true},
+		{"end before start", EatingWindowConfig{Start: "20:00", End: "12:00"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateEatingWindow(tt.cfg); (err != nil) != tt.expectError {
+				t.Errorf("validateEatingWindow() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
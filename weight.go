@@ -0,0 +1,199 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Weight trend settings
+const (
+	WeightEMADays      = 7  // EMA span; the smoothing factor is 2/(span+1)
+	WeightLookbackDays = 42 // readings loaded to warm the EMA up
+)
+
+// WeightData is the latest scale reading and its smoothed trend
+type WeightData struct {
+	CurrentKg       float64          `json:"current_kg"` // latest raw reading
+	Date            string           `json:"date"`
+	TrendKg         float64          `json:"trend_kg"`                     // 7-day EMA
+	ChangePerWeekKg *float64         `json:"change_per_week_kg,omitempty"` // trend now minus a week ago
+	Composition     *BodyComposition `json:"composition,omitempty"`
+}
+
+// weightEMA smooths daily readings into a trend, oldest first. Days without
+// a reading decay the previous trend as if the same weight had been logged.
+func weightEMA(values []dailyValue) []dailyValue {
+	alpha := 2.0 / (WeightEMADays + 1)
+	trend := make([]dailyValue, 0, len(values))
+	for i, v := range values {
+		if i == 0 {
+			trend = append(trend, v)
+			continue
+		}
+		prev := trend[i-1]
+		gap := daysBetween(prev.Date, v.Date)
+		a := 1 - math.Pow(1-alpha, float64(max(gap, 1)))
+		trend = append(trend, dailyValue{Date: v.Date, Value: prev.Value + a*(v.Value-prev.Value)})
+	}
+	return trend
+}
+
+// daysBetween is the number of days from a to b
+func daysBetween(a, b string) int {
+	ta, errA := time.Parse("2006-01-02", a)
+	tb, errB := time.Parse("2006-01-02", b)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return int(tb.Sub(ta).Hours() / 24)
+}
+
+// trendOn is the last trend value on or before date, nil when none
+func trendOn(trend []dailyValue, date string) *float64 {
+	var found *float64
+	for i := range trend {
+		if trend[i].Date > date {
+			break
+		}
+		found = &trend[i].Value
+	}
+	return found
+}
+
+// weeklyTrend smooths non-empty values and returns the latest trend and the
+// trend a week before the latest reading (nil when there is none)
+func weeklyTrend(values []dailyValue) (latest float64, weekAgo *float64) {
+	trend := weightEMA(values)
+	return trend[len(trend)-1].Value, trendOn(trend, addDays(values[len(values)-1].Date, -7))
+}
+
+// weightFor summarizes daily readings; nil without any
+func weightFor(values []dailyValue) *WeightData {
+	if len(values) == 0 {
+		return nil
+	}
+	trend, prior := weeklyTrend(values)
+	latest := values[len(values)-1]
+	w := &WeightData{CurrentKg: roundTo(latest.Value, 1), Date: latest.Date, TrendKg: roundTo(trend, 1)}
+	if prior != nil {
+		change := roundTo(trend-*prior, 2)
+		w.ChangePerWeekKg = &change
+	}
+	return w
+}
+
+// queryWeight reads weight_body_mass over the lookback window ending on date
+func queryWeight(db *sql.DB, date string) (*WeightData, error) {
+	values, err := queryDailyValues(db, "weight_body_mass", addDays(date, -(WeightLookbackDays-1)), date)
+	if err != nil {
+		return nil, err
+	}
+	return weightFor(values), nil
+}
+
+// queryCurrentWeight is the latest weight_body_mass reading in the lookback
+// window ending on date, nil without one
+func queryCurrentWeight(db *sql.DB, date string) (*float64, error) {
+	values, err := queryDailyValues(db, "weight_body_mass", addDays(date, -(WeightLookbackDays-1)), date)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	current := roundTo(values[len(values)-1].Value, 1)
+	return &current, nil
+}
+
+// weightValue formats the reading with its trend, e.g. "80.4 kg (trend 80.9, -0.35 kg/week)"
+func weightValue(w *WeightData) string {
+	if w == nil {
+		return "–"
+	}
+	value := fmt.Sprintf("%.1f kg (trend %.1f", w.CurrentKg, w.TrendKg)
+	if w.ChangePerWeekKg != nil {
+		value += fmt.Sprintf(", %+.2f kg/week", *w.ChangePerWeekKg)
+	}
+	return value + ")"
+}
+
+// Goal weight settings
+const (
+	GoalReachedKg    = 0.2  // trend within this of the goal counts as AT GOAL
+	GoalPaceShare    = 0.75 // share of the deficit's predicted rate that is ON PACE
+	GoalETAMaxDays   = 730  // projections further out are left off
+	GoalStatusAt     = "AT GOAL"
+	GoalStatusOnPace = "ON PACE"
+	GoalStatusBehind = "BEHIND PACE"
+)
+
+// GoalWeightData projects when the weight trend reaches user.goal_weight_kg
+type GoalWeightData struct {
+	GoalKg      float64 `json:"goal_kg"`
+	RemainingKg float64 `json:"remaining_kg"`               // goal minus trend
+	ETA         string  `json:"goal_eta,omitempty"`         // from the trend's weekly change
+	DeficitETA  string  `json:"goal_eta_deficit,omitempty"` // from the average energy balance
+	Pace        string  `json:"pace,omitempty"`             // AT GOAL, ON PACE, BEHIND PACE
+}
+
+// goalWeightFor compares the trend with goal. The weekly change and the
+// average energy balance (kcal/day) each give an ETA; the trend is ON PACE
+// when it moves toward the goal at GoalPaceShare of the rate the balance
+// predicts, or at all when the balance predicts none. Nil without a goal.
+func goalWeightFor(w *WeightData, goal float64, balance *float64, today string) *GoalWeightData {
+	if w == nil || goal <= 0 {
+		return nil
+	}
+	g := &GoalWeightData{GoalKg: goal, RemainingKg: roundTo(goal-w.TrendKg, 1)}
+	if math.Abs(goal-w.TrendKg) < GoalReachedKg {
+		g.Pace = GoalStatusAt
+		return g
+	}
+	toward := 1.0 // losing or gaining toward the goal counts as progress
+	if goal < w.TrendKg {
+		toward = -1
+	}
+	remaining := math.Abs(goal - w.TrendKg)
+
+	var expected float64
+	if balance != nil {
+		expected = *balance * 7 / KcalPerKgFat * toward
+		g.DeficitETA = goalETA(today, remaining, expected)
+	}
+	if w.ChangePerWeekKg == nil {
+		return g
+	}
+	actual := *w.ChangePerWeekKg * toward
+	g.ETA = goalETA(today, remaining, actual)
+	switch {
+	case expected > 0 && actual >= GoalPaceShare*expected, expected <= 0 && actual > 0:
+		g.Pace = GoalStatusOnPace
+	default:
+		g.Pace = GoalStatusBehind
+	}
+	return g
+}
+
+// goalETA is the date remaining kg is covered at perWeek kg/week; empty when
+// not moving toward it or further out than GoalETAMaxDays
+func goalETA(today string, remaining, perWeek float64) string {
+	if perWeek <= 0 {
+		return ""
+	}
+	days := int(math.Ceil(remaining / perWeek * 7))
+	if days > GoalETAMaxDays {
+		return ""
+	}
+	return addDays(today, days)
+}
+
+// goalValue formats the projection, e.g. "75.0 kg, 5.4 kg to go, ETA 2024-03-20 (energy balance projects 2024-03-10)"
+func goalValue(g *GoalWeightData) string {
+	value := fmt.Sprintf("%.1f kg, %.1f kg to go", g.GoalKg, math.Abs(g.RemainingKg))
+	if g.ETA != "" {
+		value += ", ETA " + g.ETA
+	}
+	if g.DeficitETA != "" {
+		value += fmt.Sprintf(" (energy balance projects %s)", g.DeficitETA)
+	}
+	return value
+}
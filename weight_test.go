@@ -0,0 +1,153 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== WEIGHT TESTS ====================
+
+func TestWeightEMA(t *testing.T) {
+	trend := weightEMA([]dailyValue{{"2024-01-01", 80}, {"2024-01-02", 82}, {"2024-01-04", 82.5}})
+	// alpha 0.25; the two-day gap weighs the new reading as 1 - 0.75^2
+	want := []float64{80, 80.5, 81.375}
+	if len(trend) != len(want) {
+		t.Fatalf("weightEMA() = %v, want %d points", trend, len(want))
+	}
+	for i, w := range want {
+		if roundTo(trend[i].Value, 4) != w {
+			t.Errorf("trend[%d] = %v, want %v", i, trend[i].Value, w)
+		}
+	}
+}
+
+func TestWeightFor(t *testing.T) {
+	if w := weightFor(nil); w != nil {
+		t.Errorf("weightFor(nil) = %+v, want nil", w)
+	}
+
+	// Without a reading a week back there is no weekly change yet
+	w := weightFor([]dailyValue{{"2024-01-14", 80.2}, {"2024-01-15", 80.6}})
+	if w == nil || w.CurrentKg != 80.6 || w.TrendKg != 80.3 || w.ChangePerWeekKg != nil {
+		t.Errorf("weightFor() = %+v, want 80.6 trending 80.3 without a change", w)
+	}
+
+	var losing []dailyValue
+	for i := range 15 {
+		losing = append(losing, dailyValue{addDays("2024-01-01", i), 82 - 0.1*float64(i)})
+	}
+	w = weightFor(losing)
+	if w == nil || w.ChangePerWeekKg == nil || *w.ChangePerWeekKg >= 0 {
+		t.Fatalf("weightFor() = %+v, want a weekly loss", w)
+	}
+	if *w.ChangePerWeekKg < -0.7 || *w.ChangePerWeekKg > -0.6 {
+		t.Errorf("ChangePerWeekKg = %v, want about -0.65", *w.ChangePerWeekKg)
+	}
+}
+
+func TestEveningWeight(t *testing.T) {
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('weight_body_mass', '2024-01-01 07:00:00 +0700', 90, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-08 07:00:00 +0700', 81, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-15 07:00:00 +0700', 80, 'kg', 'Scale')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if e.Weight == nil || e.Weight.CurrentKg != 80 || e.Weight.Date != "2024-01-15" || e.Weight.ChangePerWeekKg == nil {
+		t.Fatalf("Weight = %+v, want today's 80 kg with a weekly change", e.Weight)
+	}
+	md := EveningMarkdown(e)
+	for _, want := range []string{"## Weight", "- Current: 80.0 kg (2024-01-15)", "kg/week"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if vars := eveningRuleVars(e); vars["weight.current"] != 80.0 {
+		t.Errorf("weight.current = %v, want 80", vars["weight.current"])
+	}
+}
+
+// ==================== GOAL WEIGHT TESTS ====================
+
+func TestGoalWeightFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		w       *WeightData
+		goal    float64
+		balance *float64
+		want    GoalWeightData
+	}{
+		{"on pace", &WeightData{TrendKg: 80, ChangePerWeekKg: ptr(-0.5)}, 75, ptr(-500),
+			GoalWeightData{GoalKg: 75, RemainingKg: -5, ETA: "2024-03-25", DeficitETA: "2024-04-01", Pace: GoalStatusOnPace}},
+		{"behind the deficit", &WeightData{TrendKg: 80, ChangePerWeekKg: ptr(-0.1)}, 75, ptr(-500),
+			GoalWeightData{GoalKg: 75, RemainingKg: -5, ETA: "2024-12-30", DeficitETA: "2024-04-01", Pace: GoalStatusBehind}},
+		{"moving away", &WeightData{TrendKg: 80, ChangePerWeekKg: ptr(0.2)}, 75, nil,
+			GoalWeightData{GoalKg: 75, RemainingKg: -5, Pace: GoalStatusBehind}},
+		{"gaining toward the goal", &WeightData{TrendKg: 60, ChangePerWeekKg: ptr(0.25)}, 65, ptr(300),
+			GoalWeightData{GoalKg: 65, RemainingKg: 5, ETA: "2024-06-03", DeficitETA: "2024-05-23", Pace: GoalStatusOnPace}},
+		{"no weekly change yet", &WeightData{TrendKg: 80}, 75, ptr(-500),
+			GoalWeightData{GoalKg: 75, RemainingKg: -5, DeficitETA: "2024-04-01"}},
+		{"at goal", &WeightData{TrendKg: 75.1, ChangePerWeekKg: ptr(-0.3)}, 75, ptr(-500),
+			GoalWeightData{GoalKg: 75, RemainingKg: -0.1, Pace: GoalStatusAt}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := goalWeightFor(tt.w, tt.goal, tt.balance, "2024-01-15")
+			if g == nil || *g != tt.want {
+				t.Errorf("goalWeightFor() = %+v, want %+v", g, tt.want)
+			}
+		})
+	}
+	if g := goalWeightFor(&WeightData{TrendKg: 80}, 0, nil, "2024-01-15"); g != nil {
+		t.Errorf("goalWeightFor() without a goal = %+v, want nil", g)
+	}
+}
+
+func TestEveningGoalWeight(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.User.GoalWeightKg = 75
+
+	db := newTestMetricsDB(t)
+	_, err := db.Exec(`
+		INSERT INTO metrics (metric_name, timestamp, value, unit, source) VALUES
+		('weight_body_mass', '2024-01-01 07:00:00 +0700', 82, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-08 07:00:00 +0700', 81.2, 'kg', 'Scale'),
+		('weight_body_mass', '2024-01-15 07:00:00 +0700', 80.4, 'kg', 'Scale'),
+		('dietary_energy', '2024-01-14 12:00:00 +0700', 1500, 'kcal', 'briefing'),
+		('active_energy', '2024-01-14 12:00:00 +0700', 364, 'kcal', 'Apple Watch')
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	g := e.Energy.Goal
+	if g == nil || g.GoalKg != 75 || g.Pace != GoalStatusOnPace || g.ETA == "" || g.DeficitETA == "" {
+		t.Fatalf("Energy.Goal = %+v, want ON PACE with both ETAs", g)
+	}
+	if md := EveningMarkdown(e); !strings.Contains(md, "Goal weight: 75.0 kg, ") || !strings.Contains(md, "**ON PACE**") {
+		t.Errorf("markdown missing goal weight:\n%s", md)
+	}
+	if text := EveningText(e, textStyle{}); !strings.Contains(text, "Goal     75.0 kg, ") {
+		t.Errorf("text missing goal line:\n%s", text)
+	}
+	if vars := eveningRuleVars(e); vars["goal.pace"] != GoalStatusOnPace {
+		t.Errorf("goal.pace = %v, want %s", vars["goal.pace"], GoalStatusOnPace)
+	}
+
+	settings.User.GoalWeightKg = 0
+	e = newEveningBriefing(time.Now(), "2024-01-15")
+	fillEveningHealthFromDB(&e, db, "2024-01-15", "2024-01-14")
+	if e.Energy.Goal != nil {
+		t.Errorf("Energy.Goal without a goal = %+v, want nil", e.Energy.Goal)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// untimedEventMinutes is how long an event without an end time is assumed to block
+const untimedEventMinutes = 30
+
+// WorkoutSlot is the largest free block in today's training hours
+type WorkoutSlot struct {
+	Start   string `json:"start"` // HH:MM
+	End     string `json:"end"`
+	Minutes int    `json:"minutes"`
+}
+
+// validateTrainingHours checks the window and minimum block
+func validateTrainingHours(cfg TrainingHoursConfig) error {
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return fmt.Errorf("training_hours.start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return fmt.Errorf("training_hours.end: %w", err)
+	}
+	if end <= start {
+		return errors.New("training_hours.end must be after start")
+	}
+	if cfg.MinMinutes <= 0 {
+		return errors.New("training_hours.min_minutes must be positive")
+	}
+	return nil
+}
+
+// eventMinutes is an event's span in minutes after midnight
+func eventMinutes(e CalendarEvent) (int, int) {
+	start, _ := parseClock(e.Time)
+	if e.EndTime == "24:00" {
+		return start, 24 * 60
+	}
+	end, err := parseClock(e.EndTime)
+	if err != nil || end <= start {
+		end = start + untimedEventMinutes
+	}
+	return start, end
+}
+
+// span is a block of minutes after midnight
+type span struct{ start, end int }
+
+// freeBlocks lists the gaps between from and until around events, declined
+// ones aside
+func freeBlocks(events []CalendarEvent, from, until int) []span {
+	var busy []span
+	for _, e := range events {
+		if e.Response == "declined" {
+			continue
+		}
+		start, end := eventMinutes(e)
+		busy = append(busy, span{start, end})
+	}
+	sort.Slice(busy, func(i, j int) bool { return busy[i].start < busy[j].start })
+
+	var free []span
+	next := from
+	for _, b := range append(busy, span{until, until}) {
+		if end := min(b.start, until); end > next {
+			free = append(free, span{next, end})
+		}
+		next = max(next, b.end)
+		if next >= until {
+			break
+		}
+	}
+	return free
+}
+
+// bestWorkoutSlot finds the largest free block within the training hours
+// around today's events from every account, declined ones aside. Nil when
+// no block reaches cfg.MinMinutes.
+func bestWorkoutSlot(events []CalendarEvent, cfg TrainingHoursConfig) *WorkoutSlot {
+	from, _ := parseClock(cfg.Start)
+	until, _ := parseClock(cfg.End)
+
+	best := span{}
+	for _, f := range freeBlocks(events, from, until) {
+		if f.end-f.start > best.end-best.start {
+			best = f
+		}
+	}
+	if best.end-best.start < cfg.MinMinutes {
+		return nil
+	}
+	return &WorkoutSlot{Start: clockAt(best.start), End: clockAt(best.end), Minutes: best.end - best.start}
+}
+
+// workoutSlotValue formats a slot, e.g. "12:00-14:30 (2h30 free)"
+func workoutSlotValue(s *WorkoutSlot) string {
+	if s.Minutes%60 == 0 {
+		return fmt.Sprintf("%s-%s (%dh free)", s.Start, s.End, s.Minutes/60)
+	}
+	if s.Minutes < 60 {
+		return fmt.Sprintf("%s-%s (%d min free)", s.Start, s.End, s.Minutes)
+	}
+	return fmt.Sprintf("%s-%s (%dh%02d free)", s.Start, s.End, s.Minutes/60, s.Minutes%60)
+}
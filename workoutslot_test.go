@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== WORKOUT SLOT TESTS ====================
+
+func TestBestWorkoutSlot(t *testing.T) {
+	cfg := DefaultConfig().TrainingHours // 06:00-21:00, 45 min
+	ev := func(start, end string) CalendarEvent { return CalendarEvent{Time: start, EndTime: end} }
+	tests := []struct {
+		name     string
+		events   []CalendarEvent
+		expected *WorkoutSlot
+	}{
+		{"empty day", nil, &WorkoutSlot{Start: "06:00", End: "21:00", Minutes: 900}},
+		{"largest gap between events", []CalendarEvent{ev("07:00", "09:00"), ev("11:00", "12:00"), ev("14:30", "20:00")},
+			&WorkoutSlot{Start: "12:00", End: "14:30", Minutes: 150}},
+		{"overlapping events across accounts", []CalendarEvent{ev("13:00", "20:30"), ev("06:00", "10:00"), ev("09:00", "11:00")},
+			&WorkoutSlot{Start: "11:00", End: "13:00", Minutes: 120}},
+		{"events outside the window", []CalendarEvent{ev("05:00", "06:30"), ev("20:00", "23:00"), ev("09:00", "18:30")},
+			&WorkoutSlot{Start: "06:30", End: "09:00", Minutes: 150}},
+		{"evening block", []CalendarEvent{ev("06:00", "18:00")}, &WorkoutSlot{Start: "18:00", End: "21:00", Minutes: 180}},
+		{"no end time blocks half an hour", []CalendarEvent{ev("06:00", "13:00"), {Time: "13:00"}, ev("14:30", "21:00")},
+			&WorkoutSlot{Start: "13:30", End: "14:30", Minutes: 60}},
+		{"declined events are free", []CalendarEvent{ev("06:00", "12:00"), {Time: "12:00", EndTime: "21:00", Response: "declined"}},
+			&WorkoutSlot{Start: "12:00", End: "21:00", Minutes: 540}},
+		{"runs past midnight", []CalendarEvent{ev("06:00", "19:00"), ev("20:00", "24:00")}, &WorkoutSlot{Start: "19:00", End: "20:00", Minutes: 60}},
+		{"gaps under min_minutes", []CalendarEvent{ev("06:00", "12:00"), ev("12:30", "21:00")}, nil},
+		{"fully booked", []CalendarEvent{ev("06:00", "21:00")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bestWorkoutSlot(tt.events, cfg); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("bestWorkoutSlot() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateTrainingHours(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         TrainingHoursConfig
+		expectError bool
+	}{
+		{"default", DefaultConfig().TrainingHours, false},
+		{"bad start", TrainingHoursConfig{Start: "6am", End: "21:00", MinMinutes: 45}, true},
+		{"end before start", TrainingHoursConfig{Start: "21:00", End: "06:00", MinMinutes: 45}, true},
+		{"zero minimum", TrainingHoursConfig{Start: "06:00", End: "21:00"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTrainingHours(tt.cfg); (err != nil) != tt.expectError {
+				t.Errorf("validateTrainingHours() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestWorkoutSlotRendering(t *testing.T) {
+	tests := []struct {
+		slot     WorkoutSlot
+		expected string
+	}{
+		{WorkoutSlot{Start: "12:00", End: "14:00", Minutes: 120}, "12:00-14:00 (2h free)"},
+		{WorkoutSlot{Start: "12:00", End: "14:30", Minutes: 150}, "12:00-14:30 (2h30 free)"},
+		{WorkoutSlot{Start: "12:00", End: "12:45", Minutes: 45}, "12:00-12:45 (45 min free)"},
+	}
+	for _, tt := range tests {
+		if got := workoutSlotValue(&tt.slot); got != tt.expected {
+			t.Errorf("workoutSlotValue(%+v) = %q, want %q", tt.slot, got, tt.expected)
+		}
+	}
+
+	m := MorningBriefing{Calendar: CalendarData{BestWorkoutSlot: &WorkoutSlot{Start: "12:00", End: "14:30", Minutes: 150}}}
+	if md := MorningMarkdown(m); !strings.Contains(md, "- Best workout slot: 12:00-14:30 (2h30 free)\n") {
+		t.Errorf("markdown missing workout slot:\n%s", md)
+	}
+	if text := MorningText(m, textStyle{}); !strings.Contains(text, "Workout slot: 12:00-14:30 (2h30 free)\n") {
+		t.Errorf("text missing workout slot:\n%s", text)
+	}
+	if prompt, err := RenderPrompt(PromptConfig{}, "morning", m); err != nil || !strings.Contains(prompt, "Best workout slot: 12:00-14:30 (2h30 free)\n") {
+		t.Errorf("prompt missing workout slot (%v):\n%s", err, prompt)
+	}
+	if vars := morningRuleVars(m); vars["workout_slot.minutes"] != 150.0 {
+		t.Errorf("workout_slot.minutes = %v", vars["workout_slot.minutes"])
+	}
+}